@@ -127,3 +127,32 @@ func TestDnsHashCollision(t *testing.T) {
 	// We're just verifying the command works and produces output
 	assert.StringNotEmpty(result.Stdout, "config output should not be empty")
 }
+
+// TestDnsDaemonResolvesFixture tests that DNS records exist for a fixture's
+// services, using an isolated daemon instead of the system one
+func TestDnsDaemonResolvesFixture(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping e2e test in short mode")
+	}
+
+	f := framework.New(t).WithFixture("simple-app")
+	defer f.Cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+
+	assert := framework.Assert(t)
+
+	result := f.RunSpaceCmd(ctx, "up")
+	assert.CmdSucceeds(result, "space up should succeed")
+	if !result.Success() {
+		return
+	}
+
+	daemon := f.StartDNSDaemon()
+
+	ips := assert.DNSRecordExists(daemon, f.DNSHostname("web"), "web should resolve")
+	t.Logf("web resolves to %v", ips)
+
+	assert.DNSRecordMissing(daemon, f.DNSHostname("missing"), "unknown services should not resolve")
+}
@@ -0,0 +1,179 @@
+package framework
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	mdns "github.com/miekg/dns"
+)
+
+// dnsDomain is the base domain served by test daemons
+const dnsDomain = "space.local"
+
+// DNSDaemon is an isolated DNS server for a single test. It listens on a
+// random port and never touches the resolver, the state file or the
+// control socket of a daemon the developer may be running.
+type DNSDaemon struct {
+	t      *testing.T
+	server *dns.Server
+	addr   string
+}
+
+// StartDNSDaemon starts an isolated DNS daemon serving the fixture's
+// containers. It is stopped by Cleanup, before the fixture is removed.
+func (f *E2EFramework) StartDNSDaemon() *DNSDaemon {
+	f.t.Helper()
+
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		addr, err := freeUDPAddr()
+		if err != nil {
+			f.t.Fatalf("Failed to find a free port for the DNS daemon: %v", err)
+		}
+
+		logger := testLogger{t: f.t}
+		server, err := dns.NewServer(dns.Config{
+			Addr:       addr,
+			Domain:     dnsDomain,
+			WorkDir:    f.workDir,
+			UseHashing: true,
+			CacheTTL:   time.Second,
+			Docker:     dns.NewSimpleDockerClient(logger),
+			Logger:     logger,
+		})
+		if err != nil {
+			f.t.Fatalf("Failed to create DNS daemon: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = server.Start(ctx)
+		cancel()
+		if err != nil {
+			// Another process grabbed the port in between
+			lastErr = err
+			continue
+		}
+
+		d := &DNSDaemon{t: f.t, server: server, addr: addr}
+		f.cleanup = append(f.cleanup, d.Stop)
+		return d
+	}
+
+	f.t.Fatalf("Failed to start DNS daemon: %v", lastErr)
+	return nil
+}
+
+// Addr returns the address the daemon listens on
+func (d *DNSDaemon) Addr() string {
+	return d.addr
+}
+
+// Stop shuts the daemon down; calling it more than once is safe
+func (d *DNSDaemon) Stop() {
+	if err := d.server.Stop(); err != nil {
+		d.t.Logf("Failed to stop DNS daemon: %v", err)
+	}
+}
+
+// Lookup queries the daemon for the A records of hostname
+func (d *DNSDaemon) Lookup(ctx context.Context, hostname string) ([]string, error) {
+	m := new(mdns.Msg)
+	m.SetQuestion(mdns.Fqdn(hostname), mdns.TypeA)
+
+	c := &mdns.Client{Timeout: 2 * time.Second}
+	resp, _, err := c.ExchangeContext(ctx, m, d.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []string
+	for _, rr := range resp.Answer {
+		if a, ok := rr.(*mdns.A); ok {
+			ips = append(ips, a.A.String())
+		}
+	}
+	return ips, nil
+}
+
+// DNSHostname returns the hashed hostname of a service in the fixture
+func (f *E2EFramework) DNSHostname(service string) string {
+	return dns.GenerateHashedDomainName(service, f.workDir, dnsDomain)
+}
+
+// DNSRecordExists asserts that the daemon answers hostname with at least one
+// address, retrying for a few seconds while containers come up
+func (a *Assertions) DNSRecordExists(d *DNSDaemon, hostname string, msgAndArgs ...interface{}) []string {
+	a.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	var lastErr error
+	for {
+		ips, err := d.Lookup(ctx, hostname)
+		if err == nil && len(ips) > 0 {
+			return ips
+		}
+		lastErr = err
+
+		select {
+		case <-ctx.Done():
+			msg := formatMsgAndArgs(msgAndArgs...)
+			if lastErr != nil {
+				a.t.Errorf("%sExpected DNS record for %s on %s: %v", msg, hostname, d.Addr(), lastErr)
+			} else {
+				a.t.Errorf("%sExpected DNS record for %s on %s, got no answers", msg, hostname, d.Addr())
+			}
+			return nil
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// DNSRecordMissing asserts that the daemon has no address for hostname
+func (a *Assertions) DNSRecordMissing(d *DNSDaemon, hostname string, msgAndArgs ...interface{}) {
+	a.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ips, err := d.Lookup(ctx, hostname)
+	if err != nil {
+		a.t.Errorf("%sFailed to query %s for %s: %v", formatMsgAndArgs(msgAndArgs...), d.Addr(), hostname, err)
+		return
+	}
+	if len(ips) > 0 {
+		a.t.Errorf("%sExpected no DNS record for %s, got %v", formatMsgAndArgs(msgAndArgs...), hostname, ips)
+	}
+}
+
+// freeUDPAddr returns a loopback address with a currently unused UDP port
+func freeUDPAddr() (string, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().String(), nil
+}
+
+// testLogger sends DNS daemon logs to the test log
+type testLogger struct {
+	t *testing.T
+}
+
+func (l testLogger) Info(msg string, fields ...interface{})  { l.log("INFO", msg, fields) }
+func (l testLogger) Warn(msg string, fields ...interface{})  { l.log("WARN", msg, fields) }
+func (l testLogger) Error(msg string, fields ...interface{}) { l.log("ERROR", msg, fields) }
+func (l testLogger) Debug(msg string, fields ...interface{}) {}
+
+func (l testLogger) log(level, msg string, fields []interface{}) {
+	if len(fields) > 0 {
+		msg += fmt.Sprintf(" %v", fields)
+	}
+	l.t.Logf("[dns %s] %s", level, msg)
+}
@@ -12,6 +12,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/happy-sdk/space-cli/pkg/spacetest"
 )
 
 // E2EFramework provides utilities for e2e testing
@@ -82,6 +84,11 @@ func (f *E2EFramework) WithFixture(name string) *E2EFramework {
 
 // Cleanup runs all cleanup functions
 func (f *E2EFramework) Cleanup() {
+	// Attach service logs to failing tests while the containers still exist
+	if f.workDir != "" && f.t.Failed() {
+		f.DumpAllLogs(f.t)
+	}
+
 	// Always try to bring down containers first
 	if f.workDir != "" {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -199,6 +206,42 @@ func (f *E2EFramework) DockerComposePS(ctx context.Context, projectName string)
 	return string(output), err
 }
 
+// CollectLogs returns the recent compose logs and docker inspect output of a
+// service in the fixture's project
+func (f *E2EFramework) CollectLogs(service string) string {
+	f.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	project, err := spacetest.Discover(ctx, f.workDir)
+	if err != nil {
+		return fmt.Sprintf("could not collect logs of %s: %v", service, err)
+	}
+
+	logs, err := project.CollectLogs(ctx, service)
+	if err != nil {
+		logs += fmt.Sprintf("could not collect logs of %s: %v", service, err)
+	}
+	return logs
+}
+
+// DumpAllLogs writes the logs of every service in the fixture's project to
+// the test log. Cleanup calls it automatically when the test failed.
+func (f *E2EFramework) DumpAllLogs(t *testing.T) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	project, err := spacetest.Discover(ctx, f.workDir)
+	if err != nil {
+		t.Logf("No service logs to dump: %v", err)
+		return
+	}
+	project.DumpAllLogs(t)
+}
+
 // ServiceURL represents a service URL configuration
 type ServiceURL struct {
 	LocalhostURL   string // e.g., http://localhost:8080
@@ -23,7 +23,11 @@ type Context struct {
 
 func main() {
 	var ctx Context
-	if err := json.NewDecoder(os.Stdin).Decode(&ctx); err != nil {
+	data, err := os.ReadFile(os.Getenv("SPACE_CONTEXT_FILE"))
+	if err == nil {
+		err = json.Unmarshal(data, &ctx)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error reading context: %v\n", err)
 		os.Exit(1)
 	}
@@ -5,11 +5,12 @@ Usage: space run services
 """
 
 import json
-import sys
+import os
 
 def main():
-    # Read context from stdin
-    context = json.load(sys.stdin)
+    # Read context from the file space run points at
+    with open(os.environ["SPACE_CONTEXT_FILE"]) as f:
+        context = json.load(f)
 
     print(f"Project: {context['project_name']}")
     print(f"Hash: {context['hash']}")
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/events"
+)
+
+// Crash-loop defaults: a service failing this often within the window is
+// reported once, then again only after the window has passed
+const (
+	DefaultCrashThreshold = 3
+	DefaultCrashWindow    = 2 * time.Minute
+)
+
+// CrashLoop is a service detected as crash looping
+type CrashLoop struct {
+	Project  string
+	WorkDir  string
+	Service  string
+	Crashes  int
+	Window   time.Duration
+	ExitCode string
+}
+
+// String describes the crash loop for notifications
+func (c CrashLoop) String() string {
+	return fmt.Sprintf("%s/%s exited %d times in %s (last exit code %s)",
+		c.Project, c.Service, c.Crashes, c.Window, c.ExitCode)
+}
+
+// CrashLoopDetector counts failed container exits per service
+type CrashLoopDetector struct {
+	Threshold int
+	Window    time.Duration
+
+	mu       sync.Mutex
+	crashes  map[string][]time.Time
+	reported map[string]time.Time
+}
+
+// NewCrashLoopDetector creates a detector with the default threshold and window
+func NewCrashLoopDetector() *CrashLoopDetector {
+	return &CrashLoopDetector{
+		Threshold: DefaultCrashThreshold,
+		Window:    DefaultCrashWindow,
+		crashes:   make(map[string][]time.Time),
+		reported:  make(map[string]time.Time),
+	}
+}
+
+// Observe records a lifecycle event and returns the crash loop it completes.
+// Only stops with a non-zero exit code count as crashes.
+func (d *CrashLoopDetector) Observe(e events.Event) (CrashLoop, bool) {
+	if e.Type != events.TypeServiceStop || e.Service == "" {
+		return CrashLoop{}, false
+	}
+	code, _ := e.Data["exit_code"].(string)
+	if code == "" || code == "0" {
+		return CrashLoop{}, false
+	}
+
+	now := e.Time
+	if now.IsZero() {
+		now = time.Now()
+	}
+	key := e.Project + "/" + e.Service
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// Keep the crashes inside the window
+	recent := d.crashes[key][:0]
+	for _, t := range d.crashes[key] {
+		if now.Sub(t) < d.Window {
+			recent = append(recent, t)
+		}
+	}
+	recent = append(recent, now)
+	d.crashes[key] = recent
+
+	if len(recent) < d.Threshold {
+		return CrashLoop{}, false
+	}
+	if last, ok := d.reported[key]; ok && now.Sub(last) < d.Window {
+		return CrashLoop{}, false
+	}
+	d.reported[key] = now
+
+	return CrashLoop{
+		Project:  e.Project,
+		WorkDir:  e.WorkDir,
+		Service:  e.Service,
+		Crashes:  len(recent),
+		Window:   d.Window,
+		ExitCode: code,
+	}, true
+}
+
+// CrashLoopTask watches the bus for crash loops and notifies about them
+func CrashLoopTask(bus *events.Bus, detector *CrashLoopDetector, notify Notifier) Task {
+	return NewTask("crash-loop", func(ctx context.Context) error {
+		ch, cancel := bus.Subscribe(events.Filter{Types: []string{events.TypeServiceStop}})
+		defer cancel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case e := <-ch:
+				if loop, ok := detector.Observe(e); ok {
+					notify("Crash loop: "+loop.Service, loop.String())
+				}
+			}
+		}
+	})
+}
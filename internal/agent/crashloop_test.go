@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/events"
+)
+
+func stopEvent(service, exitCode string, at time.Time) events.Event {
+	return events.Event{
+		Type:    events.TypeServiceStop,
+		Time:    at,
+		Project: "shop",
+		Service: service,
+		Data:    map[string]interface{}{"exit_code": exitCode},
+	}
+}
+
+func TestCrashLoopDetector(t *testing.T) {
+	d := NewCrashLoopDetector()
+	start := time.Now()
+
+	// Clean exits and other services do not count
+	for i, e := range []events.Event{
+		stopEvent("api", "1", start),
+		stopEvent("api", "0", start.Add(time.Second)),
+		stopEvent("web", "1", start.Add(2*time.Second)),
+		stopEvent("api", "137", start.Add(3*time.Second)),
+	} {
+		if _, ok := d.Observe(e); ok {
+			t.Fatalf("event %d reported a crash loop", i)
+		}
+	}
+
+	loop, ok := d.Observe(stopEvent("api", "1", start.Add(4*time.Second)))
+	if !ok {
+		t.Fatal("third crash within the window not reported")
+	}
+	if loop.Service != "api" || loop.Crashes != 3 || loop.ExitCode != "1" {
+		t.Errorf("Observe() = %+v", loop)
+	}
+
+	// Reported once per window
+	if _, ok := d.Observe(stopEvent("api", "1", start.Add(5*time.Second))); ok {
+		t.Error("crash loop reported twice within the window")
+	}
+
+	// Crashes spread out over more than the window are not a loop
+	d = NewCrashLoopDetector()
+	for i := 0; i < 5; i++ {
+		if _, ok := d.Observe(stopEvent("api", "1", start.Add(time.Duration(i)*DefaultCrashWindow))); ok {
+			t.Fatalf("crash %d spread over windows reported as a loop", i)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package agent
+
+import "github.com/happy-sdk/space-cli/pkg/xplat"
+
+// Notifier tells the user about something the agent did or noticed
+type Notifier func(title, message string)
+
+// LogNotifier writes notifications to the agent log
+func LogNotifier(logger Logger) Notifier {
+	return func(title, message string) {
+		logger.Warn(title, "message", message)
+	}
+}
+
+// DesktopNotifier logs notifications and shows them as desktop
+// notifications where the platform supports it
+func DesktopNotifier(logger Logger) Notifier {
+	log := LogNotifier(logger)
+	return func(title, message string) {
+		log(title, message)
+		if err := xplat.Notify("space: "+title, message); err != nil {
+			logger.Info("Desktop notification failed", "error", err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package agent runs the per-machine background responsibilities of space
+// (DNS, lifecycle events, project TTLs, crash-loop detection) as supervised
+// tasks in a single process
+package agent
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Task states
+const (
+	StateRunning    = "running"
+	StateRestarting = "restarting"
+	StateDone       = "done"
+)
+
+// Task is a background responsibility. Run blocks until ctx is done; a
+// returned error makes the supervisor restart it after a backoff.
+type Task interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+// taskFunc adapts a function to Task
+type taskFunc struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+func (t taskFunc) Name() string                  { return t.name }
+func (t taskFunc) Run(ctx context.Context) error { return t.run(ctx) }
+
+// NewTask creates a task from a function
+func NewTask(name string, run func(ctx context.Context) error) Task {
+	return taskFunc{name: name, run: run}
+}
+
+// TaskStatus describes a supervised task
+type TaskStatus struct {
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"last_error,omitempty"`
+	Since     time.Time `json:"since"`
+}
+
+// Logger is the logging interface the agent uses
+type Logger interface {
+	Info(msg string, fields ...interface{})
+	Warn(msg string, fields ...interface{})
+}
+
+// Backoff bounds between restarts of a failing task
+var (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// Supervisor runs tasks and restarts the ones that fail
+type Supervisor struct {
+	logger Logger
+
+	mu    sync.Mutex
+	tasks map[string]*TaskStatus
+	wg    sync.WaitGroup
+}
+
+// NewSupervisor creates a supervisor
+func NewSupervisor(logger Logger) *Supervisor {
+	return &Supervisor{logger: logger, tasks: make(map[string]*TaskStatus)}
+}
+
+// Go starts task in the background until ctx is done. A task that returns
+// nil is done; one that fails is restarted with exponential backoff.
+func (s *Supervisor) Go(ctx context.Context, task Task) {
+	s.update(task.Name(), func(st *TaskStatus) {
+		st.State = StateRunning
+	})
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		backoff := minBackoff
+		for {
+			started := time.Now()
+			err := task.Run(ctx)
+			if err == nil || ctx.Err() != nil {
+				s.update(task.Name(), func(st *TaskStatus) { st.State = StateDone })
+				return
+			}
+
+			// A task that ran for a while before failing starts over
+			if time.Since(started) > maxBackoff {
+				backoff = minBackoff
+			}
+
+			s.logger.Warn("Task failed, restarting", "task", task.Name(), "error", err, "in", backoff)
+			s.update(task.Name(), func(st *TaskStatus) {
+				st.State = StateRestarting
+				st.Restarts++
+				st.LastError = err.Error()
+			})
+
+			select {
+			case <-ctx.Done():
+				s.update(task.Name(), func(st *TaskStatus) { st.State = StateDone })
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+			s.update(task.Name(), func(st *TaskStatus) { st.State = StateRunning })
+		}
+	}()
+}
+
+// Wait blocks until every task has returned
+func (s *Supervisor) Wait() {
+	s.wg.Wait()
+}
+
+// Status returns the status of every task, sorted by name
+func (s *Supervisor) Status() []TaskStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]TaskStatus, 0, len(s.tasks))
+	for _, st := range s.tasks {
+		statuses = append(statuses, *st)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// update changes the status of a task, recording when its state changed
+func (s *Supervisor) update(name string, fn func(st *TaskStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st, ok := s.tasks[name]
+	if !ok {
+		st = &TaskStatus{Name: name}
+		s.tasks[name] = st
+	}
+	state := st.State
+	fn(st)
+	if st.State != state {
+		st.Since = time.Now()
+	}
+}
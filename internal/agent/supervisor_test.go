@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string, ...interface{}) {}
+func (nopLogger) Warn(string, ...interface{}) {}
+
+func TestSupervisorRestartsFailingTask(t *testing.T) {
+	defer func(min, max time.Duration) { minBackoff, maxBackoff = min, max }(minBackoff, maxBackoff)
+	minBackoff, maxBackoff = time.Millisecond, 4*time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	running := make(chan struct{})
+	var runs int32
+	s := NewSupervisor(nopLogger{})
+	s.Go(ctx, NewTask("flaky", func(ctx context.Context) error {
+		if atomic.AddInt32(&runs, 1) < 3 {
+			return errors.New("boom")
+		}
+		close(running)
+		<-ctx.Done()
+		return nil
+	}))
+
+	select {
+	case <-running:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("task ran %d times, want 3", atomic.LoadInt32(&runs))
+	}
+
+	status := s.Status()
+	if len(status) != 1 || status[0].Name != "flaky" || status[0].Restarts != 2 || status[0].LastError != "boom" {
+		t.Errorf("Status() = %+v, want flaky with 2 restarts", status)
+	}
+
+	cancel()
+	s.Wait()
+	if got := s.Status()[0].State; got != StateDone {
+		t.Errorf("state after cancel = %q, want %q", got, StateDone)
+	}
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ttlCheckInterval is how often leases are checked for expiry
+var ttlCheckInterval = 30 * time.Second
+
+// Lease keeps a project running until it expires
+type Lease struct {
+	Project string    `json:"project"`
+	WorkDir string    `json:"work_dir"`
+	TTL     string    `json:"ttl"`
+	Expires time.Time `json:"expires"`
+}
+
+// Leases tracks the projects registered with a TTL
+type Leases struct {
+	mu     sync.Mutex
+	leases map[string]Lease
+}
+
+// NewLeases creates an empty lease table
+func NewLeases() *Leases {
+	return &Leases{leases: make(map[string]Lease)}
+}
+
+// Register starts or renews the lease of a project
+func (l *Leases) Register(project, workDir string, ttl time.Duration, now time.Time) Lease {
+	lease := Lease{
+		Project: project,
+		WorkDir: workDir,
+		TTL:     ttl.String(),
+		Expires: now.Add(ttl),
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.leases[project] = lease
+	return lease
+}
+
+// Release drops the lease of a project
+func (l *Leases) Release(project string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.leases, project)
+}
+
+// List returns the active leases, soonest to expire first
+func (l *Leases) List() []Lease {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	list := make([]Lease, 0, len(l.leases))
+	for _, lease := range l.leases {
+		list = append(list, lease)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Expires.Before(list[j].Expires) })
+	return list
+}
+
+// Expire removes and returns the leases that expired at now
+func (l *Leases) Expire(now time.Time) []Lease {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var expired []Lease
+	for project, lease := range l.leases {
+		if !now.Before(lease.Expires) {
+			expired = append(expired, lease)
+			delete(l.leases, project)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].Project < expired[j].Project })
+	return expired
+}
+
+// TTLTask shuts down projects whose lease expired
+func TTLTask(leases *Leases, shutdown func(ctx context.Context, lease Lease) error, notify Notifier) Task {
+	return NewTask("ttl", func(ctx context.Context) error {
+		ticker := time.NewTicker(ttlCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case now := <-ticker.C:
+				for _, lease := range leases.Expire(now) {
+					if err := shutdown(ctx, lease); err != nil {
+						notify("Auto-shutdown failed: "+lease.Project, err.Error())
+						continue
+					}
+					notify("Stopped "+lease.Project, "TTL of "+lease.TTL+" expired")
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeases(t *testing.T) {
+	l := NewLeases()
+	now := time.Now()
+
+	l.Register("shop", "/src/shop", time.Hour, now)
+	l.Register("blog", "/src/blog", 2*time.Hour, now)
+	l.Register("docs", "/src/docs", time.Hour, now)
+	l.Release("docs")
+
+	if got := l.List(); len(got) != 2 || got[0].Project != "shop" || got[1].Project != "blog" {
+		t.Fatalf("List() = %+v, want shop then blog", got)
+	}
+
+	if expired := l.Expire(now.Add(30 * time.Minute)); len(expired) != 0 {
+		t.Errorf("Expire() before TTL = %+v", expired)
+	}
+
+	// Renewing pushes the expiry out
+	l.Register("shop", "/src/shop", time.Hour, now.Add(30*time.Minute))
+	if expired := l.Expire(now.Add(time.Hour)); len(expired) != 0 {
+		t.Errorf("Expire() after renewal = %+v", expired)
+	}
+
+	expired := l.Expire(now.Add(2 * time.Hour))
+	if len(expired) != 2 || expired[0].Project != "blog" || expired[1].Project != "shop" {
+		t.Errorf("Expire() = %+v, want blog and shop", expired)
+	}
+	if got := l.List(); len(got) != 0 {
+		t.Errorf("List() after expiry = %+v", got)
+	}
+}
@@ -0,0 +1,365 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/agent"
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/internal/events"
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/internal/statedir"
+	"github.com/spf13/cobra"
+)
+
+// Control socket ops served by the agent
+const (
+	agentStatusOp   = "agent.status"
+	agentRegisterOp = "agent.register"
+	agentReleaseOp  = "agent.release"
+	agentStopOp     = "agent.stop"
+)
+
+// agentStatus is the reply to agent.status
+type agentStatus struct {
+	Build   BuildInfo          `json:"build"`
+	PID     int                `json:"pid"`
+	Address string             `json:"address"`
+	Domains []string           `json:"domains"`
+	Started time.Time          `json:"started"`
+	Tasks   []agent.TaskStatus `json:"tasks"`
+	Leases  []agent.Lease      `json:"leases"`
+}
+
+// newAgentdCommand creates the agentd command
+func newAgentdCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agentd",
+		Short: "Run the background agent",
+		Long: `Run space agentd in the foreground. There is one agent per machine; it owns
+the DNS server and the control socket, and supervises background tasks:
+
+  docker-events  publishes container start/stop events to subscribers
+  crash-loop     notifies when a service keeps exiting with an error
+  ttl            stops projects whose agent.ttl expired
+
+'space up' starts the agent in the background when it is not running. Its
+log is written to agentd.log in the per-user temp directory
+($XDG_RUNTIME_DIR/space, $TMPDIR/space-<uid>, or $SPACE_STATE_DIR/tmp).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runAgent()
+		},
+	}
+
+	enableDebugListen(cmd)
+
+	cmd.AddCommand(newAgentdStatusCommand())
+	cmd.AddCommand(newAgentdStopCommand())
+
+	return cmd
+}
+
+func newAgentdStatusCommand() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the agent's tasks and project leases",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var status agentStatus
+			err := callAgent(agentStatusOp, nil, func(data json.RawMessage) error {
+				return json.Unmarshal(data, &status)
+			})
+			if err != nil {
+				return fmt.Errorf("space agentd is not running: %w", err)
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(status)
+			}
+
+			fmt.Fprintln(console, "✅ space agentd is running")
+			fmt.Fprintf(console, "   Version:      %s\n", status.Build)
+			fmt.Fprintf(console, "   PID:          %d\n", status.PID)
+			fmt.Fprintf(console, "   DNS:          %s (*.%s)\n", status.Address, strings.Join(status.Domains, ", *."))
+			fmt.Fprintf(console, "   Uptime:       %s\n", time.Since(status.Started).Round(time.Second))
+			if build := currentBuild(); status.Build.Version != "" && status.Build != build {
+				fmt.Fprintf(console, "⚠️  The agent runs space %s, this is %s\n", status.Build, build)
+				tipf("Run 'space agentd stop'; the next 'space up' starts the current build")
+			}
+			fmt.Fprintln(console)
+
+			w := newTable(console)
+			fmt.Fprintln(w, "TASK\tSTATE\tRESTARTS\tLAST ERROR")
+			fmt.Fprintln(w, "----\t-----\t--------\t----------")
+			for _, t := range status.Tasks {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", t.Name, t.State, t.Restarts, t.LastError)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			if len(status.Leases) > 0 {
+				fmt.Fprintln(console)
+				fmt.Fprintln(console, "⏲️  Auto-shutdown:")
+				for _, lease := range status.Leases {
+					fmt.Fprintf(console, "   • %s in %s (%s)\n", lease.Project, time.Until(lease.Expires).Round(time.Minute), lease.WorkDir)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+
+	return cmd
+}
+
+func newAgentdStopCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the agent and its DNS server",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := stopAgent(); err != nil {
+				fmt.Fprintln(console, "ℹ️  space agentd is not running")
+				return nil
+			}
+			fmt.Fprintln(console, "✅ space agentd stopped")
+			return nil
+		},
+	}
+}
+
+// runAgent starts the DNS server, control socket and supervised tasks and
+// blocks until interrupted or stopped over the socket
+func runAgent() error {
+	if isDNSServerRunning() {
+		if state, err := loadDNSState(); err == nil {
+			fmt.Fprintf(console, "ℹ️  space agentd is already running (DNS on %s)\n", state.Address)
+			return nil
+		}
+		fmt.Fprintln(console, "ℹ️  space agentd is already running")
+		return nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fmt.Fprintln(console, "🌐 Starting space-dns-daemon...")
+	// Empty project name means search all projects
+	if err := startDNSServer(ctx, ""); err != nil {
+		return fmt.Errorf("failed to start DNS daemon: %w", err)
+	}
+	defer stopAgentDNS()
+
+	// The state file may not have been written, so report what the server
+	// actually bound
+	daemonCfg := daemonConfig(workDirOrEmpty())
+	state := &DNSState{Address: globalDNSServer.Addr(), Domains: daemonCfg.Network.BaseDomains()}
+	fmt.Fprintf(console, "✅ DNS daemon started on %s\n", state.Address)
+
+	logger := dns.NewStdLogger()
+	notify := agent.LogNotifier(logger)
+	if daemonCfg.Agent.Notify {
+		notify = agent.DesktopNotifier(logger)
+	}
+
+	supervisor := agent.NewSupervisor(logger)
+	leases := agent.NewLeases()
+
+	states := events.NewStates()
+	dashboard := newUIDashboard(ctx, states)
+	bus, err := startControlServer(ctx, map[string]events.Handler{
+		dnsTraceOp:      dnsTraceHandler(globalDNSServer),
+		dnsRecordsOp:    dnsRecordsHandler(globalDNSServer),
+		dnsCacheListOp:  dnsCacheListHandler(globalDNSServer),
+		dnsCacheFlushOp: dnsCacheFlushHandler(globalDNSServer),
+		agentStatusOp:   agentStatusHandler(supervisor, leases),
+		agentRegisterOp: agentRegisterHandler(leases),
+		agentReleaseOp:  agentReleaseHandler(leases),
+		agentStopOp:     agentStopHandler(cancel),
+		uiStartOp:       dashboard.startHandler(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start control socket: %w", err)
+	}
+	fmt.Fprintf(console, "📡 Control socket: %s\n", events.SocketPath())
+	dashboard.setBus(bus)
+
+	supervisor.Go(ctx, agent.NewTask("docker-events", func(ctx context.Context) error {
+		// Projects seeded before a restart of the stream missed events
+		states.Reset()
+		return events.WatchDocker(ctx, bus, states)
+	}))
+	supervisor.Go(ctx, agent.CrashLoopTask(bus, agent.NewCrashLoopDetector(), notify))
+	supervisor.Go(ctx, agent.TTLTask(leases, shutdownLeasedProject, notify))
+
+	bus.Publish(events.Event{
+		Type: string(hooks.OnDNSReady),
+		Data: map[string]interface{}{"address": state.Address},
+	})
+
+	fmt.Fprintln(console, "🔄 space agentd is running... (Press Ctrl+C to stop)")
+	fmt.Fprintln(console)
+	fmt.Fprintf(console, "💡 Containers will be accessible at: *.%s\n", strings.Join(state.BaseDomains(), ", *."))
+	fmt.Fprintln(console)
+
+	<-ctx.Done()
+	fmt.Fprintln(console, "🛑 Stopping space agentd...")
+	supervisor.Wait()
+	return nil
+}
+
+// stopAgentDNS stops the DNS server of this process and forgets its state.
+// The resolver files stay so the next agent is picked up without sudo.
+func stopAgentDNS() {
+	if globalDNSServer != nil {
+		if err := globalDNSServer.Stop(); err != nil {
+			fmt.Fprintf(console, "⚠️  Failed to stop DNS daemon: %v\n", err)
+		}
+		globalDNSServer = nil
+	}
+	if err := removeDNSState(); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(console, "⚠️  Failed to remove DNS state: %v\n", err)
+	}
+}
+
+// shutdownLeasedProject runs 'space down' for a project whose TTL expired
+func shutdownLeasedProject(ctx context.Context, lease agent.Lease) error {
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, composeStopTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, execPath, "down", "--workdir", lease.WorkDir)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("space down in %s: %w", lease.WorkDir, timeoutError(ctx, "space down", composeStopTimeout, err))
+	}
+	return nil
+}
+
+func agentStatusHandler(supervisor *agent.Supervisor, leases *agent.Leases) events.Handler {
+	return func(ctx context.Context, req events.Request, send func(data interface{}) error) error {
+		state, err := loadDNSState()
+		if err != nil {
+			return fmt.Errorf("failed to load DNS state: %w", err)
+		}
+		return send(agentStatus{
+			Build:   currentBuild(),
+			PID:     os.Getpid(),
+			Address: state.Address,
+			Domains: state.BaseDomains(),
+			Started: state.StartTime,
+			Tasks:   supervisor.Status(),
+			Leases:  leases.List(),
+		})
+	}
+}
+
+func agentRegisterHandler(leases *agent.Leases) events.Handler {
+	return func(ctx context.Context, req events.Request, send func(data interface{}) error) error {
+		project, workDir := req.Args["project"], req.Args["work_dir"]
+		if project == "" || workDir == "" {
+			return fmt.Errorf("register requires project and work_dir")
+		}
+		ttl, err := time.ParseDuration(req.Args["ttl"])
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("invalid ttl %q", req.Args["ttl"])
+		}
+		return send(leases.Register(project, workDir, ttl, time.Now()))
+	}
+}
+
+func agentReleaseHandler(leases *agent.Leases) events.Handler {
+	return func(ctx context.Context, req events.Request, send func(data interface{}) error) error {
+		leases.Release(req.Args["project"])
+		return nil
+	}
+}
+
+func agentStopHandler(cancel context.CancelFunc) events.Handler {
+	return func(ctx context.Context, req events.Request, send func(data interface{}) error) error {
+		// Reply before the socket goes away
+		time.AfterFunc(100*time.Millisecond, cancel)
+		return nil
+	}
+}
+
+// callAgent runs an op on the agent's control socket
+func callAgent(op string, args map[string]string, fn func(data json.RawMessage) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if fn == nil {
+		fn = func(json.RawMessage) error { return nil }
+	}
+	return events.Call(ctx, events.SocketPath(), events.Request{Op: op, Args: args}, fn)
+}
+
+// stopAgent asks the running agent to stop and waits until it has released
+// the DNS state
+func stopAgent() error {
+	if err := callAgent(agentStopOp, nil, nil); err != nil {
+		return err
+	}
+	for i := 0; i < 50 && isDNSServerRunning(); i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	return nil
+}
+
+// registerProjectTTL asks the agent to stop the project after ttl. It is
+// best effort: without a running agent nothing happens.
+func registerProjectTTL(projectName, workDir string, ttl time.Duration) {
+	err := callAgent(agentRegisterOp, map[string]string{
+		"project":  projectName,
+		"work_dir": workDir,
+		"ttl":      ttl.String(),
+	}, nil)
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  Could not schedule auto-shutdown: %v\n", err)
+		return
+	}
+	fmt.Fprintf(console, "⏲️  Auto-shutdown in %s (agent.ttl), 'space up' again to renew\n", ttl)
+}
+
+// releaseProjectTTL cancels the project's auto-shutdown, if any
+func releaseProjectTTL(projectName string) {
+	_ = callAgent(agentReleaseOp, map[string]string{"project": projectName}, nil)
+}
+
+// agentLogPath returns the log file of the background agent
+func agentLogPath() (string, error) {
+	dir, err := statedir.TempDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "agentd.log"), nil
+}
+
+// workDirOrEmpty returns the absolute working directory, or "" when it
+// cannot be determined
+func workDirOrEmpty() string {
+	workDir, err := filepath.Abs(Workdir)
+	if err != nil {
+		return ""
+	}
+	return workDir
+}
@@ -0,0 +1,259 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+// Image architecture states reported by space arch
+const (
+	archNative    = "native"
+	archEmulated  = "emulated"
+	archMismatch  = "mismatch"
+	archNotPulled = "not pulled"
+)
+
+// ServiceArch is the architecture audit of one service
+type ServiceArch struct {
+	Service       string   `json:"service"`
+	Image         string   `json:"image,omitempty"`
+	ImagePlatform string   `json:"image_platform,omitempty"`
+	Platform      string   `json:"platform,omitempty"`
+	RunsAs        string   `json:"runs_as"`
+	Published     []string `json:"published,omitempty"`
+	Status        string   `json:"status"`
+	Suggestion    string   `json:"suggestion,omitempty"`
+}
+
+// publishedPlatforms returns the platforms a registry publishes an image
+// for, or nil when it can't tell (a single-platform image, or no access)
+var publishedPlatforms = func(ctx context.Context, image string) []string {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "docker", "manifest", "inspect", image).Output()
+	if err != nil {
+		return nil
+	}
+
+	var index struct {
+		Manifests []struct {
+			Platform struct {
+				OS           string `json:"os"`
+				Architecture string `json:"architecture"`
+				Variant      string `json:"variant"`
+			} `json:"platform"`
+		} `json:"manifests"`
+	}
+	if err := json.Unmarshal(out, &index); err != nil {
+		return nil
+	}
+
+	var platforms []string
+	for _, m := range index.Manifests {
+		// Attestation manifests are listed as unknown/unknown
+		if m.Platform.OS == "" || m.Platform.OS == "unknown" {
+			continue
+		}
+		platform := m.Platform.OS + "/" + m.Platform.Architecture
+		if m.Platform.Variant != "" {
+			platform += "/" + m.Platform.Variant
+		}
+		platforms = append(platforms, platform)
+	}
+	return platforms
+}
+
+func newArchCommand() *cobra.Command {
+	var jsonOutput bool
+	var registry bool
+
+	cmd := &cobra.Command{
+		Use:   "arch [services...]",
+		Short: "Audit the image architecture of services",
+		Long: `List the platform each service's image is built for against the
+architecture docker runs natively, flagging containers that run emulated
+(with Rosetta on OrbStack, QEMU otherwise), which are slow, and images whose
+platform doesn't match the one the service is pinned to.
+
+With --registry the registry is asked which platforms each image is published
+for, so the suggestions can point at a native build when there is one.
+Platforms are pinned with "platform" on a service or under provider in
+.space.yml.`,
+		Example: `  space arch
+  space arch --registry
+  space arch api --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			c, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+			services, err := c.cfg.ExpandGroups(args)
+			if err != nil {
+				return err
+			}
+			if err := checkServiceNames(c.cfg, services, c.services); err != nil {
+				return err
+			}
+
+			project, err := loadComposeProject(c.workDir, c.cfg)
+			if err != nil {
+				return fmt.Errorf("failed to load compose files: %w", err)
+			}
+
+			providerType, err := provider.NewDetector().Detect(ctx)
+			if err != nil {
+				providerType = provider.ProviderGeneric
+			}
+			host := dockerPlatform(ctx)
+			platforms := planPlatforms(c.cfg, project, providerConfigName(providerType))
+
+			built := func(image string) string { return imagePlatform(ctx, image) }
+			var published func(string) []string
+			if registry {
+				published = func(image string) []string { return publishedPlatforms(ctx, image) }
+			}
+			audit := auditArch(project, platforms, services, host, built, published)
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(audit)
+			}
+
+			fmt.Fprintf(console, "🖥️  Native platform: %s (%s)\n\n", host, providerType.Description())
+			w := newTable(console)
+			fmt.Fprintln(w, "SERVICE\tIMAGE\tBUILT FOR\tRUNS AS\tSTATUS")
+			fmt.Fprintln(w, "-------\t-----\t---------\t-------\t------")
+			emulated := 0
+			for _, a := range audit {
+				image, builtFor := a.Image, a.ImagePlatform
+				if image == "" {
+					image = "(build)"
+				}
+				if builtFor == "" {
+					builtFor = "-"
+				}
+				status := a.Status
+				if status == archEmulated {
+					status += " (" + emulatorName(providerType) + ")"
+					emulated++
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.Service, image, builtFor, a.RunsAs, status)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+
+			var suggestions []string
+			for _, a := range audit {
+				if a.Suggestion != "" {
+					suggestions = append(suggestions, fmt.Sprintf("   • %s: %s", a.Service, a.Suggestion))
+				}
+			}
+			if len(suggestions) > 0 {
+				fmt.Fprintln(console)
+				fmt.Fprintln(console, "💡 Suggestions:")
+				fmt.Fprintln(console, strings.Join(suggestions, "\n"))
+			}
+			if emulated > 0 && !registry {
+				tipf("Run 'space arch --registry' to check whether native builds are published")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&registry, "registry", false, "Ask the registry which platforms each image is published for")
+
+	return cmd
+}
+
+// emulatorName names what runs foreign-architecture containers on a provider
+func emulatorName(p provider.Provider) string {
+	if p == provider.ProviderOrbStack {
+		return "Rosetta"
+	}
+	return "QEMU"
+}
+
+// auditArch compares the image platform of services (all when services is
+// empty) with the platform they run as and the native one. platforms holds
+// the platforms space pins (see planPlatforms); published, when set, returns
+// the platforms a registry publishes an image for.
+func auditArch(project *compose.Project, platforms map[string]string, services []string, host string, built func(image string) string, published func(image string) []string) []ServiceArch {
+	if len(services) == 0 {
+		services = project.ServiceNames()
+	}
+
+	audit := make([]ServiceArch, 0, len(services))
+	for _, name := range services {
+		a := ServiceArch{Service: name, Image: project.Image(name), Platform: platforms[name]}
+		if a.Platform == "" {
+			a.Platform, _ = project.Services()[name]["platform"].(string)
+		}
+		if a.Image != "" {
+			a.ImagePlatform = built(a.Image)
+			if published != nil {
+				a.Published = published(a.Image)
+			}
+		}
+
+		// Without a pinned platform, docker runs a pulled image as it is
+		// built and pulls or builds anything else natively
+		a.RunsAs = a.Platform
+		if a.RunsAs == "" {
+			a.RunsAs = a.ImagePlatform
+		}
+		if a.RunsAs == "" {
+			a.RunsAs = host
+		}
+
+		nativeBuild := publishesArch(a.Published, platformArch(host))
+		switch {
+		case a.ImagePlatform != "" && a.Platform != "" && platformArch(a.ImagePlatform) != platformArch(a.Platform):
+			a.Status = archMismatch
+			a.Suggestion = fmt.Sprintf("the pulled image is %s; run 'docker pull --platform %s %s' or change the platform", a.ImagePlatform, a.Platform, a.Image)
+		case platformArch(a.RunsAs) != platformArch(host):
+			a.Status = archEmulated
+			switch {
+			case nativeBuild && a.Platform != "":
+				a.Suggestion = fmt.Sprintf("a %s build is published; drop the platform pin to run it natively", host)
+			case nativeBuild:
+				a.Suggestion = fmt.Sprintf("a %s build is published; run 'docker pull --platform %s %s' to replace the emulated one", host, host, a.Image)
+			case a.Platform == "":
+				a.Suggestion = fmt.Sprintf("set platform: %s on the service so pulls don't fail on a missing %s build", a.RunsAs, platformArch(host))
+			}
+		case a.Image != "" && a.ImagePlatform == "":
+			a.Status = archNotPulled
+			if a.Published != nil && !nativeBuild && a.Platform == "" {
+				a.Suggestion = fmt.Sprintf("no %s build is published; set platform: %s on the service", platformArch(host), a.Published[0])
+			}
+		default:
+			a.Status = archNative
+		}
+		audit = append(audit, a)
+	}
+	return audit
+}
+
+// publishesArch reports whether platforms include one for arch
+func publishesArch(platforms []string, arch string) bool {
+	for _, platform := range platforms {
+		if platformArch(platform) == arch {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+)
+
+func TestAuditArch(t *testing.T) {
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api":      map[string]interface{}{"build": "."},
+			"db":       map[string]interface{}{"image": "postgres:16"},
+			"legacy":   map[string]interface{}{"image": "legacy:1"},
+			"pinned":   map[string]interface{}{"image": "postgres:16", "platform": "linux/amd64"},
+			"stale":    map[string]interface{}{"image": "stale:1"},
+			"unpulled": map[string]interface{}{"image": "amd64-only:1"},
+		},
+	}}
+	built := map[string]string{
+		"postgres:16": "linux/arm64",
+		"legacy:1":    "linux/amd64",
+		"stale:1":     "linux/amd64",
+	}
+	published := map[string][]string{
+		"postgres:16":  {"linux/amd64", "linux/arm64/v8"},
+		"stale:1":      {"linux/amd64", "linux/arm64"},
+		"amd64-only:1": {"linux/amd64"},
+	}
+	platforms := map[string]string{"stale": "linux/arm64"}
+
+	audit := auditArch(project, platforms, nil, "linux/arm64",
+		func(image string) string { return built[image] },
+		func(image string) []string { return published[image] })
+
+	want := map[string]struct{ status, runsAs, suggestion string }{
+		"api":      {archNative, "linux/arm64", ""},
+		"db":       {archNative, "linux/arm64", ""},
+		"legacy":   {archEmulated, "linux/amd64", "set platform: linux/amd64 on the service so pulls don't fail on a missing arm64 build"},
+		"pinned":   {archMismatch, "linux/amd64", "the pulled image is linux/arm64; run 'docker pull --platform linux/amd64 postgres:16' or change the platform"},
+		"stale":    {archMismatch, "linux/arm64", "the pulled image is linux/amd64; run 'docker pull --platform linux/arm64 stale:1' or change the platform"},
+		"unpulled": {archNotPulled, "linux/arm64", "no arm64 build is published; set platform: linux/amd64 on the service"},
+	}
+	if len(audit) != len(want) {
+		t.Fatalf("auditArch() = %+v", audit)
+	}
+	for _, a := range audit {
+		w := want[a.Service]
+		if a.Status != w.status || a.RunsAs != w.runsAs || a.Suggestion != w.suggestion {
+			t.Errorf("%s = %s as %s (%q), want %s as %s (%q)", a.Service, a.Status, a.RunsAs, a.Suggestion, w.status, w.runsAs, w.suggestion)
+		}
+	}
+
+	// Pinned to amd64 while a native build is published
+	built["postgres:16"] = "linux/amd64"
+	audit = auditArch(project, nil, []string{"pinned"}, "linux/arm64",
+		func(image string) string { return built[image] },
+		func(image string) []string { return published[image] })
+	if audit[0].Status != archEmulated || audit[0].Suggestion != "a linux/arm64 build is published; drop the platform pin to run it natively" {
+		t.Errorf("pinned = %+v", audit[0])
+	}
+}
@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// vmMemoryShare is the largest share of host memory a VM may request
+// before the host itself is likely to start swapping
+const vmMemoryShare = 0.75
+
+// machineResources are the CPUs and memory of the host or the docker engine;
+// zero values are unknown
+type machineResources struct {
+	CPUs   int
+	Memory int64
+}
+
+// hostResources returns the CPUs and physical memory of this machine; a
+// variable so tests can stub it
+var hostResources = func(ctx context.Context) machineResources {
+	host := machineResources{CPUs: runtime.NumCPU()}
+
+	switch runtime.GOOS {
+	case "darwin":
+		ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+		defer cancel()
+		if out, err := exec.CommandContext(ctx, "sysctl", "-n", "hw.memsize").Output(); err == nil {
+			host.Memory, _ = strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+		}
+	case "linux":
+		if f, err := os.Open("/proc/meminfo"); err == nil {
+			host.Memory = memTotal(f)
+			f.Close()
+		}
+	}
+	return host
+}
+
+// engineResources returns the CPUs and memory available to containers, which
+// on Docker Desktop and OrbStack are those of their VM; a variable so tests
+// can stub it
+var engineResources = func(ctx context.Context) machineResources {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "docker", "info", "--format", "{{.NCPU}} {{.MemTotal}}").Output()
+	if err != nil {
+		return machineResources{}
+	}
+	var engine machineResources
+	_, _ = fmt.Sscan(string(out), &engine.CPUs, &engine.Memory)
+	return engine
+}
+
+// memTotal reads MemTotal from /proc/meminfo, in bytes
+func memTotal(f *os.File) int64 {
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, _ := strconv.ParseInt(fields[1], 10, 64)
+			return kb << 10
+		}
+	}
+	return 0
+}
+
+// budgetWarnings compares the VM settings and the declared resources of the
+// services about to start with the machine. It refuses what the machine
+// can't provide: a VM larger than the host and reservations adding up to
+// more than containers get. Limits are caps that stacks overcommit as a
+// matter of course, so limits adding up to more only warn.
+func budgetWarnings(cfg *config.Config, project *compose.Project, services []string, host, engine machineResources) (refuse, warn []string) {
+	if cfg.VM.Enabled {
+		if memory, err := compose.ParseMemory(cfg.VM.Memory); err == nil && host.Memory > 0 && float64(memory) > vmMemoryShare*float64(host.Memory) {
+			refuse = append(refuse, fmt.Sprintf("vm.memory requests %s, but the host has %s", formatBytes(memory), formatBytes(host.Memory)))
+		}
+		if host.CPUs > 0 && cfg.VM.CPUs > host.CPUs {
+			refuse = append(refuse, fmt.Sprintf("vm.cpus requests %d CPUs, but the host has %d", cfg.VM.CPUs, host.CPUs))
+		}
+	}
+
+	if project == nil {
+		return refuse, warn
+	}
+	if len(services) == 0 {
+		services = project.ServiceNames()
+	}
+
+	var memory, reservedMemory int64
+	var cpus, reservedCPUs float64
+	for _, service := range services {
+		res := project.Resources(service)
+		memory += res.Memory * int64(res.Replicas)
+		cpus += res.CPUs * float64(res.Replicas)
+		reservedMemory += res.MemoryReservation * int64(res.Replicas)
+		reservedCPUs += res.CPUReservation * float64(res.Replicas)
+	}
+
+	// Containers share the engine's VM where there is one, else the host
+	available, where := engine, "docker"
+	if available.Memory == 0 {
+		available, where = host, "the host"
+	}
+	if available.Memory > 0 {
+		if reservedMemory > available.Memory {
+			refuse = append(refuse, fmt.Sprintf("service memory reservations add up to %s, but %s has %s", formatBytes(reservedMemory), where, formatBytes(available.Memory)))
+		} else if memory > available.Memory {
+			warn = append(warn, fmt.Sprintf("service memory limits add up to %s, but %s has %s", formatBytes(memory), where, formatBytes(available.Memory)))
+		}
+	}
+	if available.CPUs > 0 {
+		if reservedCPUs > float64(available.CPUs) {
+			refuse = append(refuse, fmt.Sprintf("service CPU reservations add up to %g, but %s has %d CPUs", reservedCPUs, where, available.CPUs))
+		} else if cpus > float64(available.CPUs) {
+			warn = append(warn, fmt.Sprintf("service CPU limits add up to %g, but %s has %d CPUs", cpus, where, available.CPUs))
+		}
+	}
+	return refuse, warn
+}
+
+// checkBudget warns when the services' limits add up to more than the
+// machine, and refuses to start a stack that cannot fit as declared
+func checkBudget(ctx context.Context, cfg *config.Config, project *compose.Project, services []string) error {
+	refuse, warn := budgetWarnings(cfg, project, services, hostResources(ctx), engineResources(ctx))
+
+	if len(warn) > 0 {
+		fmt.Fprintln(console, "⚠️  The services may compete for the machine:")
+		for _, w := range warn {
+			fmt.Fprintf(console, "   • %s\n", w)
+		}
+	}
+	if len(refuse) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(console, "⚠️  This stack is likely to exceed the machine:")
+	for _, w := range refuse {
+		fmt.Fprintf(console, "   • %s\n", w)
+	}
+	return fmt.Errorf("not enough CPU or memory; lower the VM settings or reservations, or pass --force to start anyway")
+}
+
+// formatBytes renders a size in GiB, or MiB below 1 GiB
+func formatBytes(n int64) string {
+	if n < 1<<30 {
+		return fmt.Sprintf("%d MiB", n>>20)
+	}
+	return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+}
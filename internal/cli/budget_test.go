@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestBudgetWarnings(t *testing.T) {
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api": map[string]interface{}{"mem_limit": "3g", "cpus": 2, "scale": 2},
+			"db":  map[string]interface{}{"mem_limit": "2g"},
+			"web": map[string]interface{}{},
+			"search": map[string]interface{}{"deploy": map[string]interface{}{
+				"resources": map[string]interface{}{"reservations": map[string]interface{}{"memory": "5g", "cpus": "3"}},
+			}},
+		},
+	}}
+	laptop := machineResources{CPUs: 8, Memory: 8 << 30}
+	dockerVM := machineResources{CPUs: 4, Memory: 6 << 30}
+
+	cfg := config.Defaults()
+	cfg.VM.Enabled = true
+	cfg.VM.Memory = "8GB"
+	cfg.VM.CPUs = 10
+
+	refuse, warn := budgetWarnings(cfg, project, []string{"api", "db", "web"}, laptop, dockerVM)
+	want := []string{
+		"vm.memory requests 8.0 GiB, but the host has 8.0 GiB",
+		"vm.cpus requests 10 CPUs, but the host has 8",
+	}
+	if strings.Join(refuse, "\n") != strings.Join(want, "\n") {
+		t.Errorf("refused:\n%s\nwant:\n%s", strings.Join(refuse, "\n"), strings.Join(want, "\n"))
+	}
+	// Limits are caps, so overcommitting them only warns
+	if len(warn) != 1 || warn[0] != "service memory limits add up to 8.0 GiB, but docker has 6.0 GiB" {
+		t.Errorf("warnings = %v", warn)
+	}
+
+	// Only the services about to start count; without an engine the host does
+	cfg.VM.Enabled = false
+	if refuse, warn := budgetWarnings(cfg, project, []string{"db", "web"}, laptop, machineResources{}); len(refuse)+len(warn) != 0 {
+		t.Errorf("unexpected refusals %v and warnings %v", refuse, warn)
+	}
+	refuse, warn = budgetWarnings(cfg, project, []string{"api"}, machineResources{CPUs: 2, Memory: 4 << 30}, machineResources{})
+	if len(refuse) != 0 || len(warn) != 2 || !strings.Contains(warn[1], "CPU limits add up to 4, but the host has 2 CPUs") {
+		t.Errorf("refused %v, warnings %v", refuse, warn)
+	}
+
+	// Reservations must fit
+	refuse, _ = budgetWarnings(cfg, project, []string{"search"}, machineResources{CPUs: 2, Memory: 4 << 30}, machineResources{})
+	want = []string{
+		"service memory reservations add up to 5.0 GiB, but the host has 4.0 GiB",
+		"service CPU reservations add up to 3, but the host has 2 CPUs",
+	}
+	if strings.Join(refuse, "\n") != strings.Join(want, "\n") {
+		t.Errorf("refused:\n%s\nwant:\n%s", strings.Join(refuse, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestMemTotal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "meminfo")
+	if err := os.WriteFile(path, []byte("MemTotal:       16318412 kB\nMemFree:         1024 kB\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if got := memTotal(f); got != 16318412<<10 {
+		t.Errorf("memTotal = %d", got)
+	}
+}
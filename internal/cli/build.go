@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// BuildInfo identifies the exact build of space, so a bug report or agent
+// can be matched to the code it ran
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	Go        string `json:"go"`
+}
+
+// String formats the build as "<version> (<commit>, built <time>)"
+func (b BuildInfo) String() string {
+	s := b.Version
+	switch {
+	case b.Commit != "" && b.BuildTime != "":
+		s += fmt.Sprintf(" (%s, built %s)", b.Commit, b.BuildTime)
+	case b.Commit != "":
+		s += fmt.Sprintf(" (%s)", b.Commit)
+	}
+	return s
+}
+
+// currentBuild returns the build of the running binary. Commit and time
+// come from the linker flags 'make build' sets; binaries built otherwise, as
+// with go install, fall back to what Go recorded from the checkout.
+func currentBuild() BuildInfo {
+	build := BuildInfo{
+		Version:   Version,
+		Commit:    GitCommit,
+		BuildTime: BuildTime,
+		Go:        runtime.Version(),
+	}
+	if build.Commit != "" && build.BuildTime != "" {
+		return build
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return build
+	}
+	return withVCSInfo(build, info.Settings)
+}
+
+// withVCSInfo fills in the commit and time build is missing from the vcs
+// settings Go records, marking commits built with uncommitted changes
+func withVCSInfo(build BuildInfo, settings []debug.BuildSetting) BuildInfo {
+	var revision, modified string
+	for _, setting := range settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.time":
+			if build.BuildTime == "" {
+				build.BuildTime = setting.Value
+			}
+		case "vcs.modified":
+			modified = setting.Value
+		}
+	}
+
+	if build.Commit == "" && revision != "" {
+		build.Commit = revision[:min(len(revision), 7)]
+		if modified == "true" {
+			build.Commit += "-dirty"
+		}
+	}
+	return build
+}
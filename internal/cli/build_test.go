@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"encoding/json"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestWithVCSInfo(t *testing.T) {
+	settings := []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "53befa4c0ffee0123456789"},
+		{Key: "vcs.time", Value: "2026-10-01T12:00:00Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}
+
+	build := withVCSInfo(BuildInfo{Version: "0.6.1"}, settings)
+	if build.Commit != "53befa4-dirty" {
+		t.Errorf("commit = %q, want 53befa4-dirty", build.Commit)
+	}
+	if build.BuildTime != "2026-10-01T12:00:00Z" {
+		t.Errorf("build time = %q", build.BuildTime)
+	}
+
+	// Linker flags win over the recorded checkout
+	build = withVCSInfo(BuildInfo{Version: "0.6.1", Commit: "abc1234", BuildTime: "2026-10-02T08:00:00Z"}, settings)
+	if build.Commit != "abc1234" || build.BuildTime != "2026-10-02T08:00:00Z" {
+		t.Errorf("injected build info was replaced: %+v", build)
+	}
+}
+
+func TestBuildInfoString(t *testing.T) {
+	tests := []struct {
+		build BuildInfo
+		want  string
+	}{
+		{BuildInfo{Version: "dev"}, "dev"},
+		{BuildInfo{Version: "0.6.1", Commit: "abc1234"}, "0.6.1 (abc1234)"},
+		{BuildInfo{Version: "0.6.1", Commit: "abc1234", BuildTime: "2026-10-02T08:00:00Z"}, "0.6.1 (abc1234, built 2026-10-02T08:00:00Z)"},
+	}
+	for _, tt := range tests {
+		if got := tt.build.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestVersionReportJSON(t *testing.T) {
+	report := VersionReport{
+		BuildInfo: BuildInfo{Version: "0.6.1", Commit: "abc1234", BuildTime: "2026-10-02T08:00:00Z", Go: "go1.25.0"},
+		OS:        "darwin",
+		Arch:      "arm64",
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"version":"0.6.1"`, `"commit":"abc1234"`, `"build_time":"2026-10-02T08:00:00Z"`, `"go":"go1.25.0"`, `"os":"darwin"`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("report %s lacks %s", data, field)
+		}
+	}
+}
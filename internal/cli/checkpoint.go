@@ -0,0 +1,634 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// checkpointComposeFile is the generated override running the images of
+	// a restored checkpoint
+	checkpointComposeFile = ".space-checkpoint-compose.yml"
+
+	// checkpointManifestFile describes a checkpoint inside its directory
+	checkpointManifestFile = "checkpoint.json"
+
+	// checkpointHelperImage archives and restores volume contents
+	checkpointHelperImage = "busybox:latest"
+)
+
+// checkpointName matches names usable as docker image tags
+var checkpointName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+
+// checkpointManifest describes a stack snapshot
+type checkpointManifest struct {
+	Name    string    `json:"name"`
+	Project string    `json:"project"`
+	Created time.Time `json:"created"`
+
+	// Images maps each service to the image its container was committed to
+	Images map[string]string `json:"images"`
+
+	// Volumes are the project volumes archived as <name>.tar.gz
+	Volumes []checkpointVolume `json:"volumes"`
+
+	// Files are the config and compose files saved, relative to the
+	// working directory
+	Files []string `json:"files"`
+}
+
+// checkpointVolume is a compose volume saved in a checkpoint
+type checkpointVolume struct {
+	Name string `json:"name"`
+
+	// Key is the volume's name in the compose file
+	Key string `json:"key"`
+}
+
+// checkpointDir returns the directory holding a checkpoint
+func checkpointDir(workDir, name string) string {
+	return filepath.Join(stateDir(workDir), "checkpoints", name)
+}
+
+// checkpointImage returns the image a service's container is committed to
+func checkpointImage(project, service, name string) string {
+	return "space-checkpoint/" + strings.ToLower(project+"-"+service) + ":" + name
+}
+
+// newCheckpointCommand creates the checkpoint command
+func newCheckpointCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "checkpoint",
+		Short: "Save and restore snapshots of the running stack",
+		Long: `Checkpoints capture a whole demo environment on this machine: the
+filesystem of every service container (docker commit), the contents of the
+project's volumes, and the config, compose files and state space keeps.
+
+Take one before destructive testing and restore it afterwards. Checkpoints
+are stored in .space/state/checkpoints and can be large.`,
+	}
+
+	cmd.AddCommand(newCheckpointCreateCommand())
+	cmd.AddCommand(newCheckpointRestoreCommand())
+	cmd.AddCommand(newCheckpointListCommand())
+	cmd.AddCommand(newCheckpointDeleteCommand())
+	cmd.AddCommand(newCheckpointResetCommand())
+
+	return cmd
+}
+
+func newCheckpointCreateCommand() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Snapshot the containers, volumes and config of the project",
+		Long: `Snapshot the project. Running containers are stopped while their
+filesystems and volumes are copied, so the snapshot is consistent, and are
+started again afterwards.`,
+		Example: `  space checkpoint create before-migration
+  space checkpoint create demo --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			name := args[0]
+			if !checkpointName.MatchString(name) {
+				return fmt.Errorf("invalid checkpoint name %q: use letters, digits, '.', '_' and '-'", name)
+			}
+
+			sc, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			dir := checkpointDir(sc.workDir, name)
+			if _, err := os.Stat(dir); err == nil {
+				if !force {
+					return fmt.Errorf("checkpoint %q exists; use --force to replace it", name)
+				}
+				if err := os.RemoveAll(dir); err != nil {
+					return err
+				}
+			}
+
+			containers, err := projectContainers(ctx, sc.projectName, true)
+			if err != nil {
+				return err
+			}
+			if len(containers) == 0 {
+				return fmt.Errorf("project %s has no containers; start it with 'space up' first", sc.projectName)
+			}
+
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+			manifest, err := createCheckpoint(ctx, sc.workDir, sc.cfg, sc.projectName, name, containers)
+			if err != nil {
+				_ = os.RemoveAll(dir)
+				return err
+			}
+
+			fmt.Fprintf(console, "📸 Checkpoint %s saved: %d image(s), %d volume(s), %d file(s)\n",
+				name, len(manifest.Images), len(manifest.Volumes), len(manifest.Files))
+			tipf("Restore it with 'space checkpoint restore %s'", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Replace an existing checkpoint with the same name")
+
+	return cmd
+}
+
+// createCheckpoint commits the containers, archives the volumes and copies
+// the files of the project into the checkpoint directory
+func createCheckpoint(ctx context.Context, workDir string, cfg *config.Config, projectName, name string, containers []dns.Container) (*checkpointManifest, error) {
+	dir := checkpointDir(workDir, name)
+	manifest := &checkpointManifest{
+		Name:    name,
+		Project: projectName,
+		Created: time.Now(),
+		Images:  make(map[string]string),
+	}
+
+	running, err := projectContainers(ctx, projectName, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(running) > 0 {
+		names := containerNames(running)
+		fmt.Fprintf(console, "⏸️  Stopping %d container(s) for a consistent snapshot\n", len(names))
+		if err := runDocker(ctx, append([]string{"stop"}, names...)...); err != nil {
+			return nil, err
+		}
+		defer func() {
+			fmt.Fprintf(console, "▶️  Starting %d container(s) again\n", len(names))
+			if err := runDocker(ctx, append([]string{"start"}, names...)...); err != nil {
+				fmt.Fprintf(console, "⚠️  Failed to start containers: %v\n", err)
+			}
+		}()
+	}
+
+	// Replicas share their service's image, so the lowest one is committed
+	for _, c := range checkpointContainers(containers) {
+		image := checkpointImage(projectName, c.Service, name)
+		fmt.Fprintf(console, "💾 Committing %s → %s\n", c.Name, image)
+		if err := runDocker(ctx, "commit", c.Name, image); err != nil {
+			return nil, err
+		}
+		manifest.Images[c.Service] = image
+	}
+
+	volumes, err := projectVolumes(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range volumes {
+		fmt.Fprintf(console, "📦 Archiving volume %s\n", v.Name)
+		err := runDocker(ctx, "run", "--rm",
+			"-v", v.Name+":/volume:ro",
+			"-v", dir+":/backup",
+			checkpointHelperImage,
+			"tar", "czf", "/backup/"+v.Name+".tar.gz", "-C", "/volume", ".")
+		if err != nil {
+			return nil, err
+		}
+	}
+	manifest.Volumes = volumes
+
+	manifest.Files = checkpointFiles(workDir, cfg)
+	if err := copyCheckpointFiles(workDir, filepath.Join(dir, "files"), manifest.Files); err != nil {
+		return nil, fmt.Errorf("failed to save files: %w", err)
+	}
+
+	if err := saveCheckpointManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func newCheckpointRestoreCommand() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "restore <name>",
+		Short: "Replace the project with a checkpoint and start it",
+		Long: `Take the project down, put back the config, compose files and state
+saved in the checkpoint, replace the contents of its volumes, and start the
+services from the committed images.
+
+The images stay in use for later 'space up' runs until 'space checkpoint
+reset'. Since the current volumes are lost, restore asks for confirmation
+first unless --yes is passed.`,
+		Example: `  space checkpoint restore before-migration
+  space checkpoint restore before-migration --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			name := args[0]
+
+			sc, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			dir := checkpointDir(sc.workDir, name)
+			manifest, err := loadCheckpointManifest(dir)
+			if err != nil {
+				return fmt.Errorf("checkpoint %q not found (see 'space checkpoint list')", name)
+			}
+			if manifest.Project != sc.projectName {
+				return fmt.Errorf("checkpoint %q belongs to project %s, not %s", name, manifest.Project, sc.projectName)
+			}
+			for _, image := range manifest.Images {
+				if err := runDocker(ctx, "image", "inspect", image); err != nil {
+					return fmt.Errorf("image %s of checkpoint %q is missing", image, name)
+				}
+			}
+
+			action := fmt.Sprintf("restore checkpoint %s over the containers, files and %d volume(s)", name, len(manifest.Volumes))
+			if err := confirmDestructive(action, sc.projectName, sc.workDir, yes); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			fmt.Fprintln(console)
+
+			down := newDownCommand()
+			if err := down.RunE(down, nil); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(console, "📂 Restoring %d file(s)\n", len(manifest.Files))
+			if err := copyCheckpointFiles(filepath.Join(dir, "files"), sc.workDir, manifest.Files); err != nil {
+				return fmt.Errorf("failed to restore files: %w", err)
+			}
+
+			for _, v := range manifest.Volumes {
+				fmt.Fprintf(console, "📦 Restoring volume %s\n", v.Name)
+				if err := restoreVolume(ctx, dir, manifest.Project, v); err != nil {
+					return err
+				}
+			}
+
+			if _, err := writeCheckpointOverride(sc.workDir, manifest); err != nil {
+				return err
+			}
+			fmt.Fprintf(console, "📸 Restored checkpoint %s from %s\n", name, manifest.Created.Format(time.RFC1123))
+			fmt.Fprintln(console)
+
+			up := newUpCommand()
+			return up.RunE(up, nil)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Restore without asking for confirmation")
+
+	return cmd
+}
+
+// restoreVolume recreates a volume with the compose labels and unpacks its
+// archive into it
+func restoreVolume(ctx context.Context, dir, projectName string, v checkpointVolume) error {
+	_ = runDocker(ctx, "volume", "rm", "--force", v.Name)
+	err := runDocker(ctx, "volume", "create",
+		"--label", "com.docker.compose.project="+projectName,
+		"--label", "com.docker.compose.volume="+v.Key,
+		v.Name)
+	if err != nil {
+		return err
+	}
+	return runDocker(ctx, "run", "--rm",
+		"-v", v.Name+":/volume",
+		"-v", dir+":/backup:ro",
+		checkpointHelperImage,
+		"tar", "xzf", "/backup/"+v.Name+".tar.gz", "-C", "/volume")
+}
+
+func newCheckpointListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the checkpoints of the project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sc, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			checkpoints := listCheckpoints(sc.workDir)
+			if len(checkpoints) == 0 {
+				fmt.Fprintln(console, "No checkpoints")
+				tipf("Create one with 'space checkpoint create <name>'")
+				return nil
+			}
+
+			w := newTable(console)
+			fmt.Fprintln(w, "NAME\tCREATED\tIMAGES\tVOLUMES")
+			fmt.Fprintln(w, "----\t-------\t------\t-------")
+			for _, m := range checkpoints {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\n", m.Name, m.Created.Format("2006-01-02 15:04"), len(m.Images), len(m.Volumes))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newCheckpointDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a checkpoint and its images",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			name := args[0]
+
+			sc, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			dir := checkpointDir(sc.workDir, name)
+			manifest, err := loadCheckpointManifest(dir)
+			if err != nil {
+				return fmt.Errorf("checkpoint %q not found (see 'space checkpoint list')", name)
+			}
+			for _, image := range manifest.Images {
+				if err := runDocker(ctx, "rmi", image); err != nil {
+					fmt.Fprintf(console, "⚠️  Failed to remove image %s: %v\n", image, err)
+				}
+			}
+			if err := os.RemoveAll(dir); err != nil {
+				return err
+			}
+			fmt.Fprintf(console, "🗑️  Deleted checkpoint %s\n", name)
+			return nil
+		},
+	}
+}
+
+func newCheckpointResetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reset",
+		Short: "Go back to the compose images after a restore",
+		Long: `Stop using the images of a restored checkpoint. The next 'space up'
+recreates the services from the images in the compose files; volumes and
+files are left as they are.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sc, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			path := filepath.Join(sc.workDir, checkpointComposeFile)
+			switch inspectGenerated(path) {
+			case generatedMissing:
+				fmt.Fprintln(console, "✅ No checkpoint images in use")
+				return nil
+			case generatedForeign:
+				return fmt.Errorf("%s was not generated by space; remove it yourself", checkpointComposeFile)
+			}
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			fmt.Fprintln(console, "✅ Checkpoint images dropped")
+			tipf("Run 'space up' to recreate the services from the compose images")
+			return nil
+		},
+	}
+}
+
+// checkpointOverride returns the compose override running the committed
+// images of a checkpoint
+func checkpointOverride(manifest *checkpointManifest) ([]byte, error) {
+	services := make(map[string]interface{}, len(manifest.Images))
+	for service, image := range manifest.Images {
+		services[service] = map[string]interface{}{"image": image}
+	}
+	return yaml.Marshal(map[string]interface{}{"services": services})
+}
+
+// writeCheckpointOverride writes the override used by 'space up' after a
+// restore
+func writeCheckpointOverride(workDir string, manifest *checkpointManifest) (string, error) {
+	data, err := checkpointOverride(manifest)
+	if err != nil {
+		return "", err
+	}
+	header := fmt.Sprintf("# Auto-generated by 'space checkpoint restore %s'; 'space checkpoint reset' removes it\n", manifest.Name)
+	path := filepath.Join(workDir, checkpointComposeFile)
+	if _, err := writeGenerated(path, header, data); err != nil {
+		return "", fmt.Errorf("failed to write checkpoint override: %w", err)
+	}
+	return path, nil
+}
+
+// checkpointOverrideFile returns the override of a restored checkpoint, or ""
+func checkpointOverrideFile(workDir string) string {
+	path := filepath.Join(workDir, checkpointComposeFile)
+	if inspectGenerated(path) != generatedCurrent {
+		return ""
+	}
+	return path
+}
+
+// checkpointContainers returns the lowest replica of each compose service
+func checkpointContainers(containers []dns.Container) []dns.Container {
+	first := make(map[string]dns.Container)
+	for _, c := range containers {
+		if c.Service == "" {
+			continue
+		}
+		if prev, ok := first[c.Service]; !ok || c.Number < prev.Number {
+			first[c.Service] = c
+		}
+	}
+
+	picked := make([]dns.Container, 0, len(first))
+	for _, c := range first {
+		picked = append(picked, c)
+	}
+	sort.Slice(picked, func(i, j int) bool { return picked[i].Service < picked[j].Service })
+	return picked
+}
+
+// containerNames returns the names of containers
+func containerNames(containers []dns.Container) []string {
+	names := make([]string, 0, len(containers))
+	for _, c := range containers {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// checkpointFiles returns the existing config, compose and state files of
+// the project, relative to the working directory
+func checkpointFiles(workDir string, cfg *config.Config) []string {
+	candidates := []string{config.ConfigFileName, config.AlternateConfigFileName, ".env"}
+	candidates = append(candidates, composeFilesFor(workDir, cfg)...)
+	if entries, err := os.ReadDir(stateDir(workDir)); err == nil {
+		for _, entry := range entries {
+			if entry.Type().IsRegular() {
+				candidates = append(candidates, filepath.Join(stateDir(workDir), entry.Name()))
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, file := range candidates {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workDir, file)
+		}
+		rel, err := filepath.Rel(workDir, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || seen[rel] {
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		seen[rel] = true
+		files = append(files, rel)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// copyCheckpointFiles copies files, relative paths, from one root to another
+func copyCheckpointFiles(from, to string, files []string) error {
+	for _, file := range files {
+		src := filepath.Join(from, file)
+		info, err := os.Stat(src)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(src)
+		if err != nil {
+			return err
+		}
+
+		dst := filepath.Join(to, file)
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dst, data, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveCheckpointManifest writes the manifest into a checkpoint directory
+func saveCheckpointManifest(dir string, manifest *checkpointManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, checkpointManifestFile), data, 0644)
+}
+
+// loadCheckpointManifest reads the manifest of a checkpoint directory
+func loadCheckpointManifest(dir string) (*checkpointManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, checkpointManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	var manifest checkpointManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// listCheckpoints returns the checkpoints of a project, oldest first
+func listCheckpoints(workDir string) []*checkpointManifest {
+	entries, err := os.ReadDir(filepath.Join(stateDir(workDir), "checkpoints"))
+	if err != nil {
+		return nil
+	}
+
+	var checkpoints []*checkpointManifest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if manifest, err := loadCheckpointManifest(checkpointDir(workDir, entry.Name())); err == nil {
+			checkpoints = append(checkpoints, manifest)
+		}
+	}
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i].Created.Before(checkpoints[j].Created) })
+	return checkpoints
+}
+
+// projectContainers lists the compose containers of a project; all includes
+// stopped ones
+func projectContainers(ctx context.Context, projectName string, all bool) ([]dns.Container, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	args := []string{"ps", "--filter", "label=com.docker.compose.project=" + projectName, "--format", dns.PsFormat}
+	if all {
+		args = append(args, "--all")
+	}
+	out, err := exec.CommandContext(ctx, "docker", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", timeoutError(ctx, "docker ps", dockerQueryTimeout, err))
+	}
+	return dns.ParseContainers(string(out)), nil
+}
+
+// projectVolumes lists the compose volumes of a project
+func projectVolumes(ctx context.Context, projectName string) ([]checkpointVolume, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "docker", "volume", "ls",
+		"--filter", "label=com.docker.compose.project="+projectName,
+		"--format", `{{.Name}}|{{.Label "com.docker.compose.volume"}}`).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volumes: %w", timeoutError(ctx, "docker volume ls", dockerQueryTimeout, err))
+	}
+	return parseVolumes(string(out)), nil
+}
+
+// parseVolumes parses the name|key lines printed by projectVolumes
+func parseVolumes(output string) []checkpointVolume {
+	var volumes []checkpointVolume
+	for _, line := range strings.Split(output, "\n") {
+		name, key, _ := strings.Cut(strings.TrimSpace(line), "|")
+		if name == "" {
+			continue
+		}
+		if key == "" {
+			key = name
+		}
+		volumes = append(volumes, checkpointVolume{Name: name, Key: key})
+	}
+	return volumes
+}
+
+// runDocker runs a docker command, returning its output in the error
+func runDocker(ctx context.Context, args ...string) error {
+	out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("docker %s: %s", args[0], msg)
+		}
+		return fmt.Errorf("docker %s: %w", args[0], err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestCheckpointName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"before-migration": true,
+		"Demo_1.2":         true,
+		"-flag":            false,
+		".hidden":          false,
+		"a/b":              false,
+		"":                 false,
+	} {
+		if got := checkpointName.MatchString(name); got != valid {
+			t.Errorf("checkpointName(%q) = %v, want %v", name, got, valid)
+		}
+	}
+
+	if got := checkpointImage("shop", "API", "demo"); got != "space-checkpoint/shop-api:demo" {
+		t.Errorf("checkpointImage = %q", got)
+	}
+}
+
+func TestCheckpointContainers(t *testing.T) {
+	containers := []dns.Container{
+		{Name: "shop-api-2", Service: "api", Number: 2},
+		{Name: "shop-api-1", Service: "api", Number: 1},
+		{Name: "shop-db-1", Service: "db", Number: 1},
+		{Name: "standalone", Number: 1},
+	}
+
+	var names []string
+	for _, c := range checkpointContainers(containers) {
+		names = append(names, c.Name)
+	}
+	if want := []string{"shop-api-1", "shop-db-1"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("checkpointContainers = %v, want %v", names, want)
+	}
+}
+
+func TestParseVolumes(t *testing.T) {
+	got := parseVolumes("shop_pgdata|pgdata\nexternal|\n\n")
+	want := []checkpointVolume{{Name: "shop_pgdata", Key: "pgdata"}, {Name: "external", Key: "external"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseVolumes = %v, want %v", got, want)
+	}
+}
+
+func TestCheckpointFiles(t *testing.T) {
+	workDir := t.TempDir()
+	for _, file := range []string{".space.yaml", "docker-compose.yml", ".space/state/up.json", ".space/state/checkpoints/old/checkpoint.json"} {
+		path := filepath.Join(workDir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files := checkpointFiles(workDir, config.Defaults())
+	want := []string{".space.yaml", ".space/state/up.json", "docker-compose.yml"}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("checkpointFiles = %v, want %v", files, want)
+	}
+
+	saved := t.TempDir()
+	if err := copyCheckpointFiles(workDir, saved, files); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(saved, ".space/state/up.json"))
+	if err != nil || string(data) != ".space/state/up.json" {
+		t.Errorf("copied up.json = %q, %v", data, err)
+	}
+}
+
+func TestCheckpointManifestAndOverride(t *testing.T) {
+	workDir := t.TempDir()
+	manifest := &checkpointManifest{
+		Name:    "demo",
+		Project: "shop",
+		Created: time.Now().Truncate(time.Second),
+		Images:  map[string]string{"api": "space-checkpoint/shop-api:demo"},
+		Volumes: []checkpointVolume{{Name: "shop_pgdata", Key: "pgdata"}},
+	}
+
+	dir := checkpointDir(workDir, "demo")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveCheckpointManifest(dir, manifest); err != nil {
+		t.Fatal(err)
+	}
+	list := listCheckpoints(workDir)
+	if len(list) != 1 || list[0].Name != "demo" || !list[0].Created.Equal(manifest.Created) {
+		t.Fatalf("listCheckpoints = %+v", list)
+	}
+
+	if checkpointOverrideFile(workDir) != "" {
+		t.Fatal("override present before restore")
+	}
+	path, err := writeCheckpointOverride(workDir, manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpointOverrideFile(workDir) != path {
+		t.Errorf("checkpointOverrideFile = %q, want %q", checkpointOverrideFile(workDir), path)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "image: space-checkpoint/shop-api:demo") {
+		t.Errorf("override:\n%s", data)
+	}
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// composeFilesFor returns the compose files for the project, including
+// detected override files unless the config opts out
+func composeFilesFor(workDir string, cfg *config.Config) []string {
+	return compose.ResolveFiles(workDir, cfg.Project.ComposeFiles, !cfg.Project.IgnoreOverride)
+}
+
+// loadComposeProject loads the merged compose model for the project
+func loadComposeProject(workDir string, cfg *config.Config) (*compose.Project, error) {
+	project, err := compose.Load(workDir, composeFilesFor(workDir, cfg))
+	if err != nil {
+		return nil, err
+	}
+	project.ProjectDir = composeProjectDir(workDir, cfg)
+	return project, nil
+}
+
+// composeProjectDir returns the directory relative paths in the compose
+// files resolve against: project.compose_project_directory, or the
+// directory of the first compose file like plain docker compose
+func composeProjectDir(workDir string, cfg *config.Config) string {
+	if dir := cfg.Project.ComposeProjectDirectory; dir != "" {
+		if filepath.IsAbs(dir) {
+			return filepath.Clean(dir)
+		}
+		return filepath.Join(workDir, dir)
+	}
+	return compose.DefaultProjectDir(workDir, composeFilesFor(workDir, cfg))
+}
+
+// composeCommand returns the docker compose command line for the project
+// with the given files. The project directory is always passed, since the
+// generated files written to the working directory would otherwise move it.
+func composeCommand(workDir string, cfg *config.Config, files []string, projectName string) []string {
+	composeCmd := []string{"docker", "compose", "--project-directory", composeProjectDir(workDir, cfg)}
+	for _, file := range files {
+		composeCmd = append(composeCmd, "-f", file)
+	}
+	return append(composeCmd, "-p", projectName)
+}
+
+// applyComposeServices adds services found in the compose model that are not
+// described in .space.yaml, so URL, port and hook computations see the same
+// set of services docker compose will start. It also fills in the protocol of
+// well-known TCP services (databases, caches, brokers) that don't declare one,
+// and registers the databases of services running database images.
+func applyComposeServices(cfg *config.Config, project *compose.Project) {
+	if project != nil {
+		for name := range project.Services() {
+			if _, ok := cfg.Services[name]; ok {
+				continue
+			}
+
+			ports := project.ContainerPorts(name)
+			if len(ports) == 0 {
+				continue
+			}
+
+			svc := config.ServiceConfig{Port: ports[0]}
+			for _, mapping := range project.Ports(name) {
+				if mapping.Target == ports[0] && mapping.Published > 0 {
+					svc.ExternalPort = mapping.Published
+					break
+				}
+			}
+
+			if cfg.Services == nil {
+				cfg.Services = make(map[string]config.ServiceConfig)
+			}
+			cfg.Services[name] = svc
+		}
+	}
+
+	applyObservabilityServices(cfg)
+	applyMailService(cfg)
+
+	if project != nil && !cfg.Project.IgnoreDatabaseImages {
+		cfg.Databases = append(cfg.Databases, detectDatabases(cfg, project)...)
+	}
+
+	// Databases declare their type explicitly
+	dbTypes := make(map[string]string)
+	for _, db := range cfg.Databases {
+		if db.Service != "" && db.Type != "" {
+			dbTypes[db.Service] = db.Type
+		}
+	}
+
+	for name, svc := range cfg.Services {
+		if svc.Protocol != "" && svc.EffectiveProtocol() != config.ProtocolTCP {
+			continue
+		}
+
+		if svc.Scheme == "" {
+			svc.Scheme = config.DetectTCPScheme(dbTypes[name])
+			if svc.Scheme == "" && project != nil {
+				svc.Scheme = config.DetectTCPScheme(project.Image(name))
+			}
+		}
+		if svc.Protocol == "" && svc.Scheme == "" {
+			continue
+		}
+
+		svc.Protocol = config.ProtocolTCP
+		cfg.Services[name] = svc
+	}
+}
+
+// resolveServiceArgs expands group names in the service arguments of a
+// command. With a project, the services they depend on are added, so a
+// subset such as a group can be started or restarted on its own.
+func resolveServiceArgs(cfg *config.Config, project *compose.Project, args []string) ([]string, error) {
+	services, err := cfg.ExpandGroups(args)
+	if err != nil {
+		return nil, err
+	}
+	if project != nil {
+		if err := checkServiceNames(cfg, services, project.ServiceNames()); err != nil {
+			return nil, err
+		}
+		services = project.WithDependencies(services)
+	}
+	return services, nil
+}
+
+// checkServiceNames fails on names that are not services of the project,
+// before compose does at the end of a long output, suggesting close matches
+// among the services and groups. Without known services nothing is checked.
+func checkServiceNames(cfg *config.Config, names, known []string) error {
+	if len(known) == 0 {
+		return nil
+	}
+
+	candidates := append([]string(nil), known...)
+	for group := range cfg.Groups {
+		candidates = append(candidates, group)
+	}
+	sort.Strings(candidates)
+
+	for _, name := range names {
+		if containsString(known, name) {
+			continue
+		}
+		if suggestions := suggestNames(name, candidates); len(suggestions) > 0 {
+			return fmt.Errorf("unknown service %q; did you mean %s?", name, strings.Join(suggestions, " or "))
+		}
+		return fmt.Errorf("unknown service %q; services: %s", name, strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// maxSuggestions caps the close matches offered for a mistyped name
+const maxSuggestions = 3
+
+// suggestNames returns the candidates close to name: those within two
+// edits, or containing its characters in order (api for api-server), closest
+// first
+func suggestNames(name string, candidates []string) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+	var matches []match
+	for _, c := range candidates {
+		d := editDistance(strings.ToLower(name), strings.ToLower(c))
+		if d <= 2 || fuzzyMatch(strings.ToLower(c), strings.ToLower(name)) {
+			matches = append(matches, match{c, d})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+
+	var names []string
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		names = append(names, matches[i].name)
+	}
+	return names
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
+
+// detectDatabases returns the databases of compose services running a known
+// database image that have no configured database
+func detectDatabases(cfg *config.Config, project *compose.Project) []config.DatabaseConfig {
+	var detected []config.DatabaseConfig
+	for _, name := range project.ServiceNames() {
+		if _, ok := cfg.DatabaseFor(name); ok {
+			continue
+		}
+
+		env := make(map[string]string)
+		for key, value := range project.Environment(name) {
+			if value != nil {
+				env[key] = fmt.Sprint(value)
+			}
+		}
+
+		db, ok := config.DetectDatabase(name, project.Image(name), env)
+		if !ok {
+			continue
+		}
+		redact.Add(db.Password)
+		detected = append(detected, db)
+	}
+	return detected
+}
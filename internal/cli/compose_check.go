@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"gopkg.in/yaml.v3"
+)
+
+// composeConfigProblem runs docker compose config with the global flags and
+// files of a compose command line. It returns what compose reports when the
+// merged configuration is invalid, or an error when compose could not run.
+var composeConfigProblem = func(ctx context.Context, workDir string, args []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], append(append([]string(nil), args[1:]...), "config", "--quiet")...)
+	cmd.Dir = workDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && ctx.Err() == nil {
+		if problem := strings.TrimSpace(stderr.String()); problem != "" {
+			return problem, nil
+		}
+	}
+	return "", timeoutError(ctx, "docker compose config", dockerQueryTimeout, err)
+}
+
+// checkDNSOverride validates the final compose file set before up, so an
+// invalid one fails before any container is touched. When the same set with
+// the original compose files in place of the DNS override is valid, the
+// rewrite is to blame and what it changed is printed as a diff.
+func checkDNSOverride(ctx context.Context, workDir string, composeCmd []string, overrideFile string, composeFiles []string) error {
+	problem, err := composeConfigProblem(ctx, workDir, composeCmd)
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  Could not validate the compose configuration: %v\n", err)
+		return nil
+	}
+	if problem == "" {
+		return nil
+	}
+
+	fmt.Fprintln(console, "❌ docker compose rejects the configuration:")
+	printIndented(problem)
+
+	if original, err := composeConfigProblem(ctx, workDir, withoutOverride(composeCmd, overrideFile, composeFiles)); err != nil || original != "" {
+		return fmt.Errorf("invalid compose configuration (also without the DNS mode override)")
+	}
+
+	if diff, err := dnsOverrideDiff(workDir, composeFiles, overrideFile); err == nil && diff != "" {
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "🔍 The DNS mode override changed the compose files as follows:")
+		printIndented(diff)
+	}
+	return fmt.Errorf("the DNS mode override makes the compose configuration invalid")
+}
+
+// withoutOverride returns a compose command line with the DNS override
+// replaced by the compose files it was generated from
+func withoutOverride(composeCmd []string, overrideFile string, composeFiles []string) []string {
+	args := make([]string, 0, len(composeCmd)+2*len(composeFiles))
+	for i := 0; i < len(composeCmd); i++ {
+		if composeCmd[i] == "-f" && i+1 < len(composeCmd) && composeCmd[i+1] == overrideFile {
+			for _, file := range composeFiles {
+				args = append(args, "-f", file)
+			}
+			i++
+			continue
+		}
+		args = append(args, composeCmd[i])
+	}
+	return args
+}
+
+// dnsOverrideDiff compares the merged compose files with the DNS override
+// rendered from them; both are marshaled the same way, so only the
+// rewritten parts differ
+func dnsOverrideDiff(workDir string, composeFiles []string, overrideFile string) (string, error) {
+	project, err := compose.Load(workDir, composeFiles)
+	if err != nil {
+		return "", err
+	}
+	before, err := yaml.Marshal(project.Data)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(overrideFile)
+	if err != nil {
+		return "", err
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", err
+	}
+	after, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+
+	return lineDiff(string(before), string(after), 2), nil
+}
+
+// lineDiff returns the changed lines of b against a, prefixed with - and +,
+// with radius unchanged lines around each change and "..." between hunks
+func lineDiff(a, b string, radius int) string {
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+
+	// lcs[i][j] is the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte
+		text string
+	}
+	var lines []line
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			lines = append(lines, line{' ', x[i]})
+			i++
+			j++
+		case i < len(x) && (j == len(y) || lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, line{'-', x[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', y[j]})
+			j++
+		}
+	}
+
+	// Keep the changes and the context around them
+	keep := make([]bool, len(lines))
+	for n, l := range lines {
+		if l.op == ' ' {
+			continue
+		}
+		for k := max(0, n-radius); k <= min(len(lines)-1, n+radius); k++ {
+			keep[k] = true
+		}
+	}
+
+	var out strings.Builder
+	for n, l := range lines {
+		if !keep[n] {
+			continue
+		}
+		if n > 0 && !keep[n-1] && out.Len() > 0 {
+			out.WriteString("...\n")
+		}
+		fmt.Fprintf(&out, "%c %s\n", l.op, l.text)
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// printIndented prints text indented under a console heading
+func printIndented(text string) {
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Fprintf(console, "   %s\n", line)
+	}
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+)
+
+func TestLineDiff(t *testing.T) {
+	a := "services:\n  api:\n    image: api:1\n    ports:\n      - 8080:80\n  db:\n    image: postgres:16\n    volumes: []\n    restart: always\n"
+	b := "services:\n  api:\n    expose:\n      - \"80\"\n    image: api:1\n  db:\n    image: postgres:16\n    volumes: []\n    restart: always\n"
+
+	want := `  services:
+    api:
++     expose:
++       - "80"
+      image: api:1
+-     ports:
+-       - 8080:80
+    db:
+      image: postgres:16`
+	if got := lineDiff(a, b, 2); got != want {
+		t.Errorf("lineDiff() =\n%s\nwant\n%s", got, want)
+	}
+	if got := lineDiff(a, a, 2); got != "" {
+		t.Errorf("lineDiff(a, a) = %q", got)
+	}
+}
+
+func TestWithoutOverride(t *testing.T) {
+	composeCmd := []string{"docker", "compose", "--project-directory", "/src", "-f", "/src/.space-dns-compose.yml", "-f", "/src/.space-health-compose.yml", "-p", "shop"}
+	got := withoutOverride(composeCmd, "/src/.space-dns-compose.yml", []string{"docker-compose.yml", "docker-compose.override.yml"})
+	want := []string{"docker", "compose", "--project-directory", "/src", "-f", "docker-compose.yml", "-f", "docker-compose.override.yml", "-f", "/src/.space-health-compose.yml", "-p", "shop"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("withoutOverride() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckDNSOverride(t *testing.T) {
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, "docker-compose.yml"), []byte("services:\n  api:\n    image: api:1\n    ports: [\"8080:80\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	project, err := compose.Load(workDir, []string{"docker-compose.yml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, originalConsole := composeConfigProblem, console
+	defer func() { composeConfigProblem, console = original, originalConsole }()
+	console = io.Discard
+
+	overrideFile, err := createDNSModeCompose(workDir, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	composeCmd := []string{"docker", "compose", "-f", overrideFile, "-p", "shop"}
+
+	tests := []struct {
+		name         string
+		invalid      func(args []string) bool
+		wantErr      string
+		wantPrinted  string
+		wantNoPrints string
+	}{
+		{"valid", func([]string) bool { return false }, "", "", "rejects"},
+		{"rewrite breaks it", func(args []string) bool { return strings.Contains(strings.Join(args, " "), dnsComposeFile) },
+			"the DNS mode override makes", "-         ports:", ""},
+		{"invalid anyway", func([]string) bool { return true }, "also without the DNS mode override", "rejects", "ports:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			composeConfigProblem = func(_ context.Context, _ string, args []string) (string, error) {
+				if tt.invalid(args) {
+					return "services.api.expose must be a list", nil
+				}
+				return "", nil
+			}
+
+			var buf bytes.Buffer
+			console = &buf
+			err := checkDNSOverride(context.Background(), workDir, composeCmd, overrideFile, []string{"docker-compose.yml"})
+			out := buf.String()
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+			if tt.wantPrinted != "" && !strings.Contains(out, tt.wantPrinted) {
+				t.Errorf("output missing %q:\n%s", tt.wantPrinted, out)
+			}
+			if tt.wantNoPrints != "" && strings.Contains(out, tt.wantNoPrints) {
+				t.Errorf("output has %q:\n%s", tt.wantNoPrints, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestComposeProjectDir(t *testing.T) {
+	workDir := t.TempDir()
+	cfg := config.Defaults()
+
+	if got := composeProjectDir(workDir, cfg); got != workDir {
+		t.Errorf("default project directory = %s, want %s", got, workDir)
+	}
+
+	cfg.Project.ComposeFiles = []string{"deploy/compose/dev.yml"}
+	if got, want := composeProjectDir(workDir, cfg), filepath.Join(workDir, "deploy", "compose"); got != want {
+		t.Errorf("project directory = %s, want the first file's directory %s", got, want)
+	}
+
+	cfg.Project.ComposeProjectDirectory = "deploy"
+	if got, want := composeProjectDir(workDir, cfg), filepath.Join(workDir, "deploy"); got != want {
+		t.Errorf("configured project directory = %s, want %s", got, want)
+	}
+
+	args := composeCommand(workDir, cfg, []string{"deploy/compose/dev.yml"}, "shop")
+	want := []string{"docker", "compose", "--project-directory", filepath.Join(workDir, "deploy"), "-f", "deploy/compose/dev.yml", "-p", "shop"}
+	if len(args) != len(want) {
+		t.Fatalf("composeCommand() = %v, want %v", args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("composeCommand() = %v, want %v", args, want)
+			break
+		}
+	}
+}
+
+func TestCheckServiceNames(t *testing.T) {
+	cfg := &config.Config{Groups: map[string][]string{"backend": {"api-server", "postgres"}}}
+	known := []string{"api-server", "postgres", "redis", "web"}
+
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"api-server", "web"}, ""},
+		{[]string{"api"}, `unknown service "api"; did you mean api-server?`},
+		{[]string{"postgress"}, `unknown service "postgress"; did you mean postgres?`},
+		{[]string{"backnd"}, `unknown service "backnd"; did you mean backend?`},
+		{[]string{"worker"}, `unknown service "worker"; services: api-server, postgres, redis, web`},
+	}
+	for _, tc := range tests {
+		err := checkServiceNames(cfg, tc.names, known)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tc.want {
+			t.Errorf("checkServiceNames(%v) = %q, want %q", tc.names, got, tc.want)
+		}
+	}
+
+	if err := checkServiceNames(cfg, []string{"anything"}, nil); err != nil {
+		t.Errorf("without known services: %v", err)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"", "web", 3},
+		{"postgres", "postgress", 1},
+		{"redis", "reddit", 2},
+		{"api", "api", 0},
+	} {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
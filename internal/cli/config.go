@@ -2,9 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sync"
 
+	"github.com/happy-sdk/space-cli/internal/redact"
 	"github.com/happy-sdk/space-cli/pkg/config"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -19,10 +22,49 @@ func newConfigCommand() *cobra.Command {
 
 	cmd.AddCommand(newConfigShowCommand())
 	cmd.AddCommand(newConfigValidateCommand())
+	cmd.AddCommand(newConfigRenderCommand())
+	cmd.AddCommand(newConfigDocsCommand())
+	cmd.AddCommand(newConfigSyncCommand())
 
 	return cmd
 }
 
+// stdinConfig is the project config read from standard input with
+// --config -, read once since a command may create several loaders
+var stdinConfig struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// newConfigLoader creates a config loader for workDir, reading the project
+// config from --config when it is given
+func newConfigLoader(workDir string) (*config.Loader, error) {
+	loader, err := config.NewLoader(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ConfigFile {
+	case "":
+	case config.StdinConfigFile:
+		stdinConfig.once.Do(func() {
+			stdinConfig.data, stdinConfig.err = io.ReadAll(os.Stdin)
+		})
+		if stdinConfig.err != nil {
+			return nil, fmt.Errorf("failed to read config from standard input: %w", stdinConfig.err)
+		}
+		loader.UseConfigData(stdinConfig.data)
+	default:
+		path, err := filepath.Abs(ConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve config file: %w", err)
+		}
+		loader.UseConfigFile(path)
+	}
+	return loader, nil
+}
+
 func newConfigShowCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "show",
@@ -46,7 +88,7 @@ func newConfigShowCommand() *cobra.Command {
 			}
 
 			// Create loader
-			loader, err := config.NewLoader(workDir)
+			loader, err := newConfigLoader(workDir)
 			if err != nil {
 				return fmt.Errorf("failed to create config loader: %w", err)
 			}
@@ -57,6 +99,8 @@ func newConfigShowCommand() *cobra.Command {
 				return fmt.Errorf("failed to load configuration: %w", err)
 			}
 
+			registerSensitiveValues(cfg)
+
 			// Marshal to YAML for display
 			data, err := yaml.Marshal(cfg)
 			if err != nil {
@@ -64,11 +108,15 @@ func newConfigShowCommand() *cobra.Command {
 			}
 
 			// Print configuration
-			fmt.Println("# Merged Configuration")
-			fmt.Println("# Sources: defaults → global (~/.config/space/config.yaml) → project (.space.yaml)")
-			fmt.Println("# Working directory:", workDir)
-			fmt.Println()
-			fmt.Print(string(data))
+			fmt.Fprintln(console, "# Merged Configuration")
+			project := ".space.yaml"
+			if ConfigFile != "" {
+				project = ConfigFile
+			}
+			fmt.Fprintf(console, "# Sources: defaults → global (~/.config/space/config.yaml) → project (%s)\n", project)
+			fmt.Fprintln(console, "# Working directory:", workDir)
+			fmt.Fprintln(console)
+			fmt.Fprint(console, redact.String(string(data)))
 
 			return nil
 		},
@@ -98,7 +146,7 @@ func newConfigValidateCommand() *cobra.Command {
 			}
 
 			// Create loader
-			loader, err := config.NewLoader(workDir)
+			loader, err := newConfigLoader(workDir)
 			if err != nil {
 				return fmt.Errorf("failed to create config loader: %w", err)
 			}
@@ -111,15 +159,15 @@ func newConfigValidateCommand() *cobra.Command {
 
 			// Validate
 			if err := cfg.Validate(); err != nil {
-				fmt.Println("❌ Configuration validation failed:")
-				fmt.Println(err)
+				fmt.Fprintln(console, "❌ Configuration validation failed:")
+				fmt.Fprintln(console, err)
 				return err
 			}
 
-			fmt.Println("✅ Configuration is valid")
-			fmt.Println("Working directory:", workDir)
-			fmt.Println("Project:", cfg.Project.Name)
-			fmt.Println("Services:", len(cfg.Services))
+			fmt.Fprintln(console, "✅ Configuration is valid")
+			fmt.Fprintln(console, "Working directory:", workDir)
+			fmt.Fprintln(console, "Project:", cfg.Project.Name)
+			fmt.Fprintln(console, "Services:", len(cfg.Services))
 
 			return nil
 		},
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newConfigDocsCommand() *cobra.Command {
+	var (
+		format string
+		output string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate configuration reference",
+		Long: `Render the configuration schema with types, defaults and descriptions.
+
+The reference is generated from the configuration structs, so it always
+matches the running binary. Use --format man to produce a space.yaml(5)
+manual page.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			docs, err := config.Reference()
+			if err != nil {
+				return fmt.Errorf("failed to build configuration reference: %w", err)
+			}
+
+			var render func(io.Writer, []config.FieldDoc) error
+			switch format {
+			case "markdown", "md":
+				render = renderConfigMarkdown
+			case "man":
+				render = renderConfigMan
+			default:
+				return fmt.Errorf("unknown format %q (use markdown or man)", format)
+			}
+
+			if output == "" {
+				return render(os.Stdout, docs)
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			defer f.Close()
+
+			if err := render(f, docs); err != nil {
+				return err
+			}
+			fmt.Fprintf(console, "📝 Wrote configuration reference to %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "markdown", "Output format (markdown, man)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write to file instead of stdout")
+
+	return cmd
+}
+
+// groupBySection splits the reference by top-level key, keeping order
+func groupBySection(docs []config.FieldDoc) ([]string, map[string][]config.FieldDoc) {
+	var order []string
+	sections := make(map[string][]config.FieldDoc)
+	for _, doc := range docs {
+		section := doc.Section()
+		if _, ok := sections[section]; !ok {
+			order = append(order, section)
+		}
+		sections[section] = append(sections[section], doc)
+	}
+	return order, sections
+}
+
+// renderConfigMarkdown writes the reference as one table per section
+func renderConfigMarkdown(w io.Writer, docs []config.FieldDoc) error {
+	var b strings.Builder
+	b.WriteString("# Configuration Reference\n\n")
+	b.WriteString("Settings for `.space.yaml` and `~/.config/space/config.yaml`.\n")
+
+	order, sections := groupBySection(docs)
+	for _, section := range order {
+		fmt.Fprintf(&b, "\n## %s\n\n", section)
+		b.WriteString("| Key | Type | Default | Description |\n")
+		b.WriteString("|-----|------|---------|-------------|\n")
+		for _, doc := range sections[section] {
+			def := ""
+			if doc.Default != "" {
+				def = "`" + doc.Default + "`"
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n",
+				doc.Key, doc.Type, def, strings.ReplaceAll(doc.Description, "|", `\|`))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// renderConfigMan writes the reference as a space.yaml(5) roff page
+func renderConfigMan(w io.Writer, docs []config.FieldDoc) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH SPACE.YAML 5 %q \"space-cli\"\n", time.Now().Format("2006-01-02"))
+	b.WriteString(".SH NAME\nspace.yaml \\- space-cli configuration\n")
+	b.WriteString(".SH DESCRIPTION\nSettings for\n.I .space.yaml\nand\n.IR ~/.config/space/config.yaml .\n")
+
+	order, sections := groupBySection(docs)
+	for _, section := range order {
+		fmt.Fprintf(&b, ".SH %s\n", strings.ToUpper(section))
+		for _, doc := range sections[section] {
+			fmt.Fprintf(&b, ".TP\n.B %s\n", roffEscape(doc.Key))
+			line := "(" + doc.Type
+			if doc.Default != "" {
+				line += ", default " + doc.Default
+			}
+			line += ")"
+			if doc.Description != "" {
+				line += " " + doc.Description
+			}
+			b.WriteString(roffEscape(line) + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// roffEscape escapes backslashes and leading control characters
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestRenderConfigDocs(t *testing.T) {
+	docs := []config.FieldDoc{
+		{Key: "project", Type: "object"},
+		{Key: "project.name", Type: "string", Description: "Name is the project name"},
+		{Key: "network.dns_hashing", Type: "bool", Default: "true", Description: "Use a | b"},
+	}
+
+	var md bytes.Buffer
+	if err := renderConfigMarkdown(&md, docs); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"## project\n",
+		"## network\n",
+		"| `project.name` | string |  | Name is the project name |",
+		"| `network.dns_hashing` | bool | `true` | Use a \\| b |",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+
+	var man bytes.Buffer
+	if err := renderConfigMan(&man, docs); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		".TH SPACE.YAML 5",
+		".SH NETWORK\n",
+		".TP\n.B network.dns_hashing\n(bool, default true) Use a | b\n",
+	} {
+		if !strings.Contains(man.String(), want) {
+			t.Errorf("man page missing %q:\n%s", want, man.String())
+		}
+	}
+}
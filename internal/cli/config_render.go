@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/provider"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/internal/secrets"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func newConfigRenderCommand() *cobra.Command {
+	var dnsMode string
+	var noLint bool
+	var strict bool
+
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Print the compose configuration space up would run",
+		Long: `Print the fully resolved compose model 'space up' would hand to docker
+compose: the configured compose files and overrides merged, plus everything
+space adds on top (DNS mode port removal, CORS origins, synthesized
+healthchecks, service environment, timezone mount and secret files).
+
+Secret values are never resolved; references show as <secret:NAME>.
+
+The model is linted for services without a healthcheck, images without a
+pinned tag and bind mounts of world-writable host paths. Warnings go to
+stderr so the output can be piped; --strict turns them into a failure.`,
+		Example: `  space config render
+  space config render --dns=off > resolved.yml
+  space config render --strict`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			workDir, err := filepath.Abs(Workdir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+
+			loader, err := newConfigLoader(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to create config loader: %w", err)
+			}
+			cfg, err := loader.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+			registerSensitiveValues(cfg)
+
+			project, err := loadComposeProject(workDir, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to load compose files: %w", err)
+			}
+			applyComposeServices(cfg, project)
+
+			var useDNS bool
+			switch dnsMode {
+			case "on":
+				useDNS = true
+			case "off":
+			case "auto":
+				providerType, err := provider.NewDetector().Detect(ctx)
+				useDNS = err == nil && providerType.SupportsContainerDNS()
+			default:
+				return fmt.Errorf("invalid --dns %q, expected auto, on or off", dnsMode)
+			}
+
+			projectName := generateProjectName(cfg, workDir)
+			if err := renderComposeModel(ctx, cfg, project, projectName, workDir, useDNS); err != nil {
+				return err
+			}
+
+			if err := writeComposeModel(os.Stdout, project, useDNS); err != nil {
+				return err
+			}
+
+			if noLint {
+				return nil
+			}
+			findings := project.Lint(func(image string) bool { return imageHasHealthcheck(ctx, image) })
+			printLintFindings(os.Stderr, findings)
+			if strict && len(findings) > 0 {
+				return fmt.Errorf("%d lint warning(s)", len(findings))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dnsMode, "dns", "auto", "Render DNS mode: auto (detect provider), on or off")
+	cmd.Flags().BoolVar(&noLint, "no-lint", false, "Skip lint warnings")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Exit with an error when there are lint warnings")
+
+	return cmd
+}
+
+// maskedSecrets resolves secret references to placeholders, so rendering
+// never reaches a secret store
+type maskedSecrets struct{}
+
+func (maskedSecrets) Resolve(ctx context.Context, name string, secret config.SecretConfig) (string, error) {
+	return "<" + secretPrefix + name + ">", nil
+}
+
+// renderComposeModel applies the overrides 'space up' adds to the compose
+// files onto project, in the same order
+func renderComposeModel(ctx context.Context, cfg *config.Config, project *compose.Project, projectName, workDir string, useDNS bool) error {
+	if useDNS {
+		applyCORSEnv(project, cfg, projectName, workDir, useDNS)
+		removePortBindings(project)
+	}
+
+	if services := synthesizeHealthchecks(ctx, cfg, project); len(services) > 0 {
+		if err := project.Merge(map[string]interface{}{"services": services}); err != nil {
+			return err
+		}
+	}
+
+	services, _, err := environmentOverride(ctx, cfg, project, maskedSecrets{})
+	if err != nil {
+		return fmt.Errorf("failed to render service environment: %w", err)
+	}
+	if len(services) > 0 {
+		if err := project.Merge(map[string]interface{}{"services": services}); err != nil {
+			return err
+		}
+	}
+
+	if len(cfg.Secrets) > 0 {
+		override := secrets.Preview(secretsPath(projectName), cfg.Secrets, project.ServiceNames())
+		if len(override) > 0 {
+			if err := project.Merge(map[string]interface{}{"services": override}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeComposeModel prints the rendered model as YAML with a header naming
+// its sources
+func writeComposeModel(out io.Writer, project *compose.Project, useDNS bool) error {
+	data, err := yaml.Marshal(project.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal compose model: %w", err)
+	}
+
+	mode := "port bindings"
+	if useDNS {
+		mode = "DNS"
+	}
+
+	w := redact.NewWriter(out)
+	fmt.Fprintf(w, "# Resolved compose model (%s mode)\n", mode)
+	fmt.Fprintf(w, "# Compose files: %s\n", strings.Join(project.Files, ", "))
+	fmt.Fprintln(w)
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// printLintFindings prints lint warnings, one per line
+func printLintFindings(out io.Writer, findings []compose.Finding) {
+	if len(findings) == 0 {
+		fmt.Fprintln(out, "✅ No lint warnings")
+		return
+	}
+	fmt.Fprintf(out, "⚠️  %d lint warning(s):\n", len(findings))
+	for _, f := range findings {
+		fmt.Fprintf(out, "   • %s\n", f)
+	}
+}
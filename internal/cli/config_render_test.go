@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestRenderComposeModel(t *testing.T) {
+	original := imageHasHealthcheck
+	imageHasHealthcheck = func(ctx context.Context, image string) bool { return false }
+	defer func() { imageHasHealthcheck = original }()
+
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"api": {
+				Port:        8080,
+				HealthCheck: &config.HealthCheckConfig{Enabled: true, Endpoint: "/health"},
+				Environment: map[string]string{"TOKEN": "Bearer ${secret:API_TOKEN}"},
+			},
+		},
+		Secrets: map[string]config.SecretConfig{
+			"API_TOKEN": {Source: "keychain"},
+			"tls":       {Source: "env", File: "/run/secrets/tls.key", Services: []string{"api"}},
+		},
+	}
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api": map[string]interface{}{
+				"image": "api:1.0",
+				"ports": []interface{}{"8080:8080"},
+			},
+		},
+	}}
+
+	if err := renderComposeModel(context.Background(), cfg, project, "demo", t.TempDir(), true); err != nil {
+		t.Fatalf("renderComposeModel() error = %v", err)
+	}
+
+	api := project.Services()["api"]
+	if _, ok := api["ports"]; ok {
+		t.Errorf("ports not removed in DNS mode: %v", api["ports"])
+	}
+	if !project.HasHealthcheck("api") {
+		t.Error("healthcheck not merged")
+	}
+
+	env, _ := api["environment"].(map[string]interface{})
+	if env["TOKEN"] != "Bearer <secret:API_TOKEN>" {
+		t.Errorf("TOKEN = %v, want masked secret", env["TOKEN"])
+	}
+
+	wantVolume := filepath.Join(secretsPath("demo"), "tls") + ":/run/secrets/tls.key:ro"
+	found := false
+	volumes, _ := api["volumes"].([]interface{})
+	for _, v := range volumes {
+		if v == wantVolume {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("volumes = %v, want %s", volumes, wantVolume)
+	}
+
+	var out bytes.Buffer
+	if err := writeComposeModel(&out, project, true); err != nil {
+		t.Fatalf("writeComposeModel() error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "# Resolved compose model (DNS mode)") {
+		t.Errorf("unexpected header:\n%s", out.String())
+	}
+}
@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// syncChange is one edit proposed by 'space config sync'
+type syncChange struct {
+	// Kind is "add", "remove" or "port"
+	Kind    string
+	Service string
+	Port    int
+
+	// ExternalPort is the published port of an added service
+	ExternalPort int
+
+	// OldPort is the configured port a "port" change replaces
+	OldPort int
+}
+
+// String describes the change for the plan and prompts
+func (c syncChange) String() string {
+	switch c.Kind {
+	case "add":
+		return fmt.Sprintf("add %s (port %d)", c.Service, c.Port)
+	case "remove":
+		return fmt.Sprintf("remove %s (not in compose)", c.Service)
+	default:
+		return fmt.Sprintf("change %s port %d → %d", c.Service, c.OldPort, c.Port)
+	}
+}
+
+// apply applies the change to the YAML of a config file
+func (c syncChange) apply(data []byte) ([]byte, error) {
+	switch c.Kind {
+	case "add":
+		return config.AddService(data, c.Service, config.ServiceConfig{Port: c.Port, ExternalPort: c.ExternalPort})
+	case "remove":
+		out, removed, err := config.RemoveService(data, c.Service)
+		if err == nil && !removed {
+			err = fmt.Errorf("%s is not configured in the project config file", c.Service)
+		}
+		return out, err
+	default:
+		return config.SetServicePort(data, c.Service, c.Port)
+	}
+}
+
+// planConfigSync compares the configured services with the compose model:
+// compose services with ports missing from the config are added, configured
+// services compose doesn't define are removed, and configured ports compose
+// doesn't expose are updated
+func planConfigSync(cfg *config.Config, project *compose.Project) []syncChange {
+	var changes []syncChange
+
+	for _, name := range project.ServiceNames() {
+		ports := project.ContainerPorts(name)
+		if len(ports) == 0 {
+			continue
+		}
+
+		svc, ok := cfg.Services[name]
+		if !ok {
+			change := syncChange{Kind: "add", Service: name, Port: ports[0]}
+			for _, mapping := range project.Ports(name) {
+				if mapping.Target == ports[0] && mapping.Published > 0 && mapping.Published != ports[0] {
+					change.ExternalPort = mapping.Published
+					break
+				}
+			}
+			changes = append(changes, change)
+			continue
+		}
+
+		if svc.Port == 0 {
+			continue
+		}
+		exposed := false
+		for _, port := range ports {
+			exposed = exposed || port == svc.Port
+		}
+		if !exposed {
+			changes = append(changes, syncChange{Kind: "port", Service: name, Port: ports[0], OldPort: svc.Port})
+		}
+	}
+
+	for _, name := range sortedServiceNames(cfg) {
+		if _, ok := project.Services()[name]; !ok {
+			changes = append(changes, syncChange{Kind: "remove", Service: name})
+		}
+	}
+	return changes
+}
+
+// newConfigSyncCommand creates the config sync command
+func newConfigSyncCommand() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync .space.yaml services with the compose file",
+		Long: `Compare the services of .space.yaml with the compose model and propose
+adding services and ports compose defines, and removing services it no
+longer defines. Each change is confirmed interactively unless --yes is given.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get working directory
+			workDir := Workdir
+			if workDir == "." {
+				var err error
+				workDir, err = os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get working directory: %w", err)
+				}
+			}
+
+			// Make absolute
+			workDir, err := filepath.Abs(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+
+			// Create loader
+			loader, err := newConfigLoader(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to create config loader: %w", err)
+			}
+
+			// Load configuration
+			cfg, err := loader.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+
+			project, err := loadComposeProject(workDir, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to load compose files: %w", err)
+			}
+
+			changes := planConfigSync(cfg, project)
+			if len(changes) == 0 {
+				fmt.Fprintln(console, "✅ .space.yaml is in sync with compose")
+				return nil
+			}
+
+			var confirm func(syncChange) (bool, error)
+			switch {
+			case yes:
+				confirm = func(syncChange) (bool, error) { return true, nil }
+			case isInteractive():
+				confirm = confirmSyncChange(os.Stdin)
+			default:
+				fmt.Fprintln(console, "📋 Proposed changes:")
+				for _, change := range changes {
+					fmt.Fprintf(console, "   • %s\n", change)
+				}
+				tipf("Run 'space config sync --yes' to apply them")
+				return nil
+			}
+
+			file, data, err := editConfigFile(workDir, func(data []byte) ([]byte, error) {
+				return applySyncChanges(data, changes, confirm)
+			})
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(file, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+			fmt.Fprintf(console, "✅ Updated %s\n", filepath.Base(file))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Apply all proposed changes without asking")
+
+	return cmd
+}
+
+// applySyncChanges applies the confirmed changes to the YAML of a config
+// file. Changes that don't apply, such as removing a service configured
+// globally, are reported and skipped.
+func applySyncChanges(data []byte, changes []syncChange, confirm func(syncChange) (bool, error)) ([]byte, error) {
+	for _, change := range changes {
+		ok, err := confirm(change)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		out, err := change.apply(data)
+		if err != nil {
+			fmt.Fprintf(console, "⚠️  Skipping %s: %v\n", change, err)
+			continue
+		}
+		data = out
+		fmt.Fprintf(console, "   ✓ %s\n", change)
+	}
+	return data, nil
+}
+
+// confirmSyncChange asks whether to apply each change, defaulting to yes
+func confirmSyncChange(in io.Reader) func(syncChange) (bool, error) {
+	ask := promptWithDefault(in)
+	return func(change syncChange) (bool, error) {
+		answer, err := ask(fmt.Sprintf("Apply: %s?", change), "Y/n")
+		if err != nil {
+			return false, err
+		}
+		return answer == "" || strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes"), nil
+	}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestPlanConfigSync(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"api":    {Port: 6060},
+			"web":    {Port: 3000},
+			"legacy": {Port: 9000},
+		},
+	}
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api":    map[string]interface{}{"ports": []interface{}{"8080"}},
+			"web":    map[string]interface{}{"ports": []interface{}{"3000:3000"}},
+			"db":     map[string]interface{}{"image": "postgres:16", "ports": []interface{}{"15432:5432"}},
+			"worker": map[string]interface{}{"image": "worker"},
+		},
+	}}
+
+	want := []syncChange{
+		{Kind: "port", Service: "api", Port: 8080, OldPort: 6060},
+		{Kind: "add", Service: "db", Port: 5432, ExternalPort: 15432},
+		{Kind: "remove", Service: "legacy"},
+	}
+	if got := planConfigSync(cfg, project); !reflect.DeepEqual(got, want) {
+		t.Errorf("planConfigSync() = %+v, want %+v", got, want)
+	}
+}
+
+func TestApplySyncChanges(t *testing.T) {
+	input := "# Shop\nservices:\n  api:\n    port: 6060 # dev server\n  legacy:\n    port: 9000\n"
+	changes := []syncChange{
+		{Kind: "port", Service: "api", Port: 8080, OldPort: 6060},
+		{Kind: "add", Service: "db", Port: 5432, ExternalPort: 15432},
+		{Kind: "remove", Service: "legacy"},
+		{Kind: "remove", Service: "global"},
+	}
+
+	// Decline the removal of legacy
+	confirm := func(change syncChange) (bool, error) {
+		return change.Service != "legacy", nil
+	}
+	out, err := applySyncChanges([]byte(input), changes, confirm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"# Shop", "port: 8080 # dev server", "  db:\n    port: 5432\n    external_port: 15432\n", "  legacy:\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestConfirmSyncChange(t *testing.T) {
+	confirm := confirmSyncChange(strings.NewReader("\nn\nyes\n"))
+	change := syncChange{Kind: "remove", Service: "legacy"}
+	for i, want := range []bool{true, false, true} {
+		got, err := confirm(change)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("answer %d = %v, want %v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// defaultCORSEnv is the variable that receives allowed origins by default
+const defaultCORSEnv = "CORS_ALLOWED_ORIGINS"
+
+// corsOrigins returns the browser origins of all http services, followed by
+// any extra origins, without duplicates
+func corsOrigins(cfg *config.Config, projectName, workDir string, useDNS bool, extra []string) []string {
+	seen := make(map[string]bool)
+	var origins []string
+	add := func(origin string) {
+		if origin != "" && !seen[origin] {
+			seen[origin] = true
+			origins = append(origins, origin)
+		}
+	}
+
+	for _, name := range sortedServiceNames(cfg) {
+		if cfg.Services[name].EffectiveProtocol() != config.ProtocolHTTP {
+			continue
+		}
+		if _, port := serviceAddress(cfg, name, workDir, useDNS); port == 0 {
+			continue
+		}
+
+		u, err := url.Parse(serviceURL(cfg, name, projectName, workDir, useDNS))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			continue
+		}
+		add(u.Scheme + "://" + u.Host)
+	}
+
+	for _, origin := range extra {
+		add(strings.TrimSuffix(origin, "/"))
+	}
+
+	return origins
+}
+
+// applyCORSEnv injects allowed origins into the environment of services that
+// configure cors, appending to values already set in the compose files.
+// It returns the names of the services that were updated.
+func applyCORSEnv(project *compose.Project, cfg *config.Config, projectName, workDir string, useDNS bool) []string {
+	var updated []string
+	for _, name := range sortedServiceNames(cfg) {
+		cors := cfg.Services[name].CORS
+		if cors == nil {
+			continue
+		}
+		if _, ok := project.Services()[name]; !ok {
+			continue
+		}
+
+		envNames := cors.Env
+		if len(envNames) == 0 {
+			envNames = []string{defaultCORSEnv}
+		}
+		separator := cors.Separator
+		if separator == "" {
+			separator = ","
+		}
+
+		origins := corsOrigins(cfg, projectName, workDir, useDNS, cors.Origins)
+		if len(origins) == 0 {
+			continue
+		}
+
+		env := project.Environment(name)
+		for _, envName := range envNames {
+			values := origins
+			if existing, ok := env[envName]; ok && existing != nil {
+				values = mergeOrigins(strings.Split(fmt.Sprint(existing), separator), origins)
+			}
+			project.SetEnvironment(name, envName, strings.Join(values, separator))
+		}
+		updated = append(updated, name)
+	}
+	return updated
+}
+
+// mergeOrigins appends origins not already present in existing
+func mergeOrigins(existing, origins []string) []string {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, origin := range append(existing, origins...) {
+		origin = strings.TrimSpace(origin)
+		if origin != "" && !seen[origin] {
+			seen[origin] = true
+			merged = append(merged, origin)
+		}
+	}
+	return merged
+}
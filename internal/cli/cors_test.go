@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestApplyCORSEnv(t *testing.T) {
+	workDir := "/home/user/shop"
+	cfg := &config.Config{
+		Network: config.NetworkConfig{DNSHashing: true},
+		Services: map[string]config.ServiceConfig{
+			"web": {Port: 3000},
+			"api": {Port: 8080, CORS: &config.CORSConfig{Origins: []string{"https://staging.example.com/"}}},
+			"admin": {Port: 8081, CORS: &config.CORSConfig{
+				Env:       []string{"ALLOWED_ORIGINS"},
+				Separator: " ",
+			}},
+			"db": {Port: 5432, Protocol: "tcp"},
+		},
+	}
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"web":   map[string]interface{}{},
+			"api":   map[string]interface{}{"environment": []interface{}{"CORS_ALLOWED_ORIGINS=http://localhost:5173"}},
+			"admin": nil,
+			"db":    map[string]interface{}{},
+		},
+	}}
+
+	updated := applyCORSEnv(project, cfg, "shop", workDir, true)
+	if len(updated) != 2 || updated[0] != "admin" || updated[1] != "api" {
+		t.Fatalf("updated = %v, want [admin api]", updated)
+	}
+
+	admin := "http://" + generateDNSDomain("admin", workDir) + ":8081"
+	api := "http://" + generateDNSDomain("api", workDir) + ":8080"
+	web := "http://" + generateDNSDomain("web", workDir) + ":3000"
+
+	wantAPI := "http://localhost:5173," + admin + "," + api + "," + web + ",https://staging.example.com"
+	if got := project.Environment("api")["CORS_ALLOWED_ORIGINS"]; got != wantAPI {
+		t.Errorf("api CORS_ALLOWED_ORIGINS = %v, want %s", got, wantAPI)
+	}
+
+	wantAdmin := admin + " " + api + " " + web
+	if got := project.Environment("admin")["ALLOWED_ORIGINS"]; got != wantAdmin {
+		t.Errorf("admin ALLOWED_ORIGINS = %v, want %s", got, wantAdmin)
+	}
+
+	if env := project.Environment("web"); len(env) != 0 {
+		t.Errorf("web environment = %v, want empty", env)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 
 	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/internal/hooks"
 	"github.com/happy-sdk/space-cli/pkg/config"
 	"github.com/spf13/cobra"
 )
@@ -33,29 +35,72 @@ type CustomServiceInfo struct {
 	URL          string `json:"url"`
 }
 
-// findCustomCommand looks for a custom command in .space/commands/
+// commandExtensions are the extensions custom commands are found with, in
+// order of preference; "" is an executable without one
+var commandExtensions = []string{"", ".sh", ".py", ".js", ".ts", ".go", ".rb", ".pl"}
+
+// findCustomCommand looks for a custom command in .space/commands/. A
+// variant for this platform, such as setup.darwin.sh, wins over setup.sh;
+// commands for other platforms are skipped, by name or by their os= and
+// arch= metadata.
 func findCustomCommand(workDir, cmdName string) (string, error) {
 	commandsDir := filepath.Join(workDir, ".space", "commands")
 
-	// Check if commands directory exists
-	if _, err := os.Stat(commandsDir); os.IsNotExist(err) {
+	entries, err := os.ReadDir(commandsDir)
+	if err != nil {
 		return "", nil
 	}
 
-	// Look for command with various extensions
-	extensions := []string{"", ".sh", ".py", ".js", ".ts", ".go", ".rb", ".pl"}
+	best, bestRank := "", -1
+	var mismatch string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || (name != cmdName && hooks.StripPlatform(name) != cmdName) {
+			continue
+		}
 
-	for _, ext := range extensions {
-		cmdPath := filepath.Join(commandsDir, cmdName+ext)
-		if info, err := os.Stat(cmdPath); err == nil {
-			// Check if it's executable (for extensionless files) or has known extension
-			if ext != "" || (info.Mode()&0111 != 0) {
-				return cmdPath, nil
-			}
+		ext := commandExt(name)
+		extIndex := slices.Index(commandExtensions, ext)
+		if extIndex < 0 {
+			continue
+		}
+		// Extensionless files must be executable
+		if info, err := entry.Info(); err != nil || (ext == "" && info.Mode()&0111 == 0) {
+			continue
+		}
+
+		path := filepath.Join(commandsDir, name)
+		meta, err := hooks.ReadScriptMeta(path)
+		if err != nil {
+			return "", err
+		}
+		if reason := hooks.PlatformMismatch(path, meta); reason != "" {
+			mismatch = reason
+			continue
+		}
+
+		// Platform variants first, then by extension
+		oses, arches := hooks.NamePlatform(name)
+		rank := (len(oses)+len(arches)+len(meta.OS)+len(meta.Arch))*len(commandExtensions) + len(commandExtensions) - extIndex
+		if rank > bestRank {
+			best, bestRank = path, rank
 		}
 	}
 
-	return "", nil
+	if best == "" && mismatch != "" {
+		return "", fmt.Errorf("command %q is %s", cmdName, mismatch)
+	}
+	return best, nil
+}
+
+// commandExt returns the extension of a command file name, ignoring a
+// trailing platform part (setup.darwin has none)
+func commandExt(name string) string {
+	ext := filepath.Ext(name)
+	if oses, arches := hooks.NamePlatform(ext); len(oses) > 0 || len(arches) > 0 {
+		return ""
+	}
+	return ext
 }
 
 // getInterpreter returns the interpreter and args for a given file
@@ -93,10 +138,16 @@ func getInterpreter(filePath string) (string, []string) {
 	}
 }
 
-// runCustomCommand executes a custom command
-func runCustomCommand(cmdPath, workDir string, args []string) error {
+// contextStdinFlag makes 'space run' pipe the JSON context into stdin
+const contextStdinFlag = "--context-stdin"
+
+// runCustomCommand executes a custom command. The JSON context is written to
+// a temporary file named by SPACE_CONTEXT_FILE (and set in SPACE_CONTEXT_JSON)
+// so stdin is forwarded to the command untouched; with contextStdin, or
+// commands.context_stdin in the config, the context is piped to stdin instead.
+func runCustomCommand(cmdPath, workDir string, args []string, contextStdin bool) error {
 	// Load config for service info
-	loader, err := config.NewLoader(workDir)
+	loader, err := newConfigLoader(workDir)
 	if err != nil {
 		return fmt.Errorf("failed to create config loader: %w", err)
 	}
@@ -109,13 +160,14 @@ func runCustomCommand(cmdPath, workDir string, args []string) error {
 		WorkDir:     workDir,
 		ProjectName: filepath.Base(workDir),
 		Hash:        hash,
-		BaseDomain:  "space.local",
+		BaseDomain:  config.DefaultBaseDomain,
 		Command:     filepath.Base(cmdPath),
 		Args:        args,
 		Services:    make(map[string]CustomServiceInfo),
 	}
 
 	if cfg != nil {
+		ctx.BaseDomain = cfg.Network.BaseDomain()
 		if cfg.Project.Name != "" {
 			ctx.ProjectName = cfg.Project.Name
 		}
@@ -125,7 +177,7 @@ func runCustomCommand(cmdPath, workDir string, args []string) error {
 				Name:         name,
 				DNSName:      dnsName,
 				InternalPort: svc.Port,
-				URL:          fmt.Sprintf("http://%s:%d", dnsName, svc.Port),
+				URL:          renderServiceURL(cfg, name, ctx.ProjectName, dnsName, svc.Port),
 			}
 		}
 	}
@@ -135,10 +187,29 @@ func runCustomCommand(cmdPath, workDir string, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal context: %w", err)
 	}
+	compactJSON, err := json.Marshal(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal context: %w", err)
+	}
+
+	contextFile, err := os.CreateTemp("", "space-context-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create context file: %w", err)
+	}
+	defer os.Remove(contextFile.Name())
+	_, err = contextFile.Write(contextJSON)
+	if closeErr := contextFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write context file: %w", err)
+	}
 
 	// Build environment
 	env := os.Environ()
 	env = append(env,
+		"SPACE_CONTEXT_FILE="+contextFile.Name(),
+		"SPACE_CONTEXT_JSON="+string(compactJSON),
 		"SPACE_WORKDIR="+ctx.WorkDir,
 		"SPACE_PROJECT_NAME="+ctx.ProjectName,
 		"SPACE_HASH="+ctx.Hash,
@@ -172,6 +243,14 @@ func runCustomCommand(cmdPath, workDir string, args []string) error {
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
+	if cfg != nil && cfg.Commands.ContextStdin {
+		contextStdin = true
+	}
+	if !contextStdin {
+		cmd.Stdin = os.Stdin
+		return cmd.Run()
+	}
+
 	// Pass context via stdin
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
@@ -213,8 +292,13 @@ func listCustomCommands(workDir string) []string {
 			continue
 		}
 
-		// Remove extension to get command name
-		cmdName := strings.TrimSuffix(name, filepath.Ext(name))
+		// Commands for other platforms are not available here
+		if hooks.PlatformMismatch(name, hooks.ScriptMeta{}) != "" {
+			continue
+		}
+
+		// Remove the platform and extension to get the command name
+		cmdName := hooks.StripPlatform(name)
 
 		if !seen[cmdName] {
 			seen[cmdName] = true
@@ -243,11 +327,23 @@ Commands can be written in any language:
 
 Commands receive context via:
   - Environment variables (SPACE_WORKDIR, SPACE_HASH, SPACE_SERVICE_*, etc.)
-  - JSON on stdin with full project context
+  - The full project context as JSON in the file named by SPACE_CONTEXT_FILE
+    and in SPACE_CONTEXT_JSON
+
+stdin is forwarded to the command, so data can be piped in. Commands written
+for the old protocol, which read the JSON context from stdin, keep working
+with --context-stdin before the command name or commands.context_stdin: true
+in .space.yaml.
+
+Platform variants such as setup.darwin.sh and setup.linux.sh run as "setup";
+the one matching this machine is preferred over a generic setup.sh. Scripts
+can also declare "# space: os=darwin arch=arm64" in their leading comments.
 
 Example:
   space run db-seed
-  space run deploy --env staging`,
+  space run deploy --env staging
+  cat data.csv | space run import
+  space run --context-stdin legacy-report`,
 		Args:               cobra.MinimumNArgs(1),
 		DisableFlagParsing: true, // Pass all flags to the custom command
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -261,6 +357,14 @@ Example:
 			}
 			workDir, _ = filepath.Abs(workDir)
 
+			contextStdin := args[0] == contextStdinFlag
+			if contextStdin {
+				args = args[1:]
+				if len(args) == 0 {
+					return fmt.Errorf("missing command after %s", contextStdinFlag)
+				}
+			}
+
 			cmdName := args[0]
 			cmdArgs := args[1:]
 
@@ -279,7 +383,7 @@ Example:
 				return fmt.Errorf("command %q not found\n\nAvailable commands:\n  %s", cmdName, strings.Join(available, "\n  "))
 			}
 
-			return runCustomCommand(cmdPath, workDir, cmdArgs)
+			return runCustomCommand(cmdPath, workDir, cmdArgs, contextStdin)
 		},
 	}
 
@@ -299,14 +403,15 @@ Example:
 			workDir, _ = filepath.Abs(workDir)
 
 			commands := listCustomCommands(workDir)
-			if len(commands) == 0 {
-				fmt.Println("No custom commands found.")
-				fmt.Println("\nCreate commands in .space/commands/")
-				fmt.Println("Supported: .sh, .py, .js, .ts, .go, .rb")
+			projectCommands := daemonConfig(workDir).Commands.Custom
+			if len(commands) == 0 && len(projectCommands) == 0 {
+				fmt.Fprintln(console, "No custom commands found.")
+				fmt.Fprintln(console, "\nCreate commands in .space/commands/")
+				fmt.Fprintln(console, "Supported: .sh, .py, .js, .ts, .go, .rb")
 				return nil
 			}
 
-			fmt.Println("Available custom commands:")
+			fmt.Fprintln(console, "Available custom commands:")
 			for _, c := range commands {
 				cmdPath, _ := findCustomCommand(workDir, c)
 				ext := filepath.Ext(cmdPath)
@@ -315,7 +420,21 @@ Example:
 				} else {
 					ext = ext[1:] // Remove leading dot
 				}
-				fmt.Printf("  %s (%s)\n", c, ext)
+				fmt.Fprintf(console, "  %s (%s)\n", c, ext)
+			}
+
+			// commands.custom entries run as 'space <name>'
+			names := make([]string, 0, len(projectCommands))
+			for name := range projectCommands {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				where := "host"
+				if svc := projectCommands[name].Service; svc != "" {
+					where = "in " + svc
+				}
+				fmt.Fprintf(console, "  %s (.space.yaml, %s): space %s\n", name, where, name)
 			}
 			return nil
 		},
@@ -348,8 +467,8 @@ func HandleUnknownCommand(args []string) bool {
 	}
 
 	// Found a custom command, execute it
-	if err := runCustomCommand(cmdPath, workDir, args[1:]); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	if err := runCustomCommand(cmdPath, workDir, args[1:], false); err != nil {
+		fmt.Fprintf(consoleErr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 	return true
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRunCustomCommandStdin(t *testing.T) {
+	workDir := t.TempDir()
+	out := t.TempDir()
+	t.Setenv("SPACE_TEST_OUT", out)
+
+	commands := filepath.Join(workDir, ".space", "commands")
+	if err := os.MkdirAll(commands, 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := `cat > "$SPACE_TEST_OUT/stdin"
+cp "$SPACE_CONTEXT_FILE" "$SPACE_TEST_OUT/context"
+printf '%s' "$SPACE_CONTEXT_JSON" > "$SPACE_TEST_OUT/env"
+`
+	cmdPath := filepath.Join(commands, "import.sh")
+	if err := os.WriteFile(cmdPath, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	input := filepath.Join(out, "input.csv")
+	if err := os.WriteFile(input, []byte("id,name\n1,alice\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	withStdin := func(fn func()) {
+		f, err := os.Open(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		original := os.Stdin
+		os.Stdin = f
+		defer func() { os.Stdin = original }()
+		fn()
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	withStdin(func() {
+		if err := runCustomCommand(cmdPath, workDir, []string{"--dry-run"}, false); err != nil {
+			t.Fatalf("runCustomCommand() error = %v", err)
+		}
+	})
+	if got := read("stdin"); got != "id,name\n1,alice\n" {
+		t.Errorf("stdin = %q, want the piped data", got)
+	}
+	var ctx CustomCommandContext
+	if err := json.Unmarshal([]byte(read("context")), &ctx); err != nil {
+		t.Fatalf("context file is not JSON: %v", err)
+	}
+	if ctx.Command != "import.sh" || len(ctx.Args) != 1 || ctx.Args[0] != "--dry-run" {
+		t.Errorf("context = %+v", ctx)
+	}
+	if err := json.Unmarshal([]byte(read("env")), &ctx); err != nil {
+		t.Errorf("SPACE_CONTEXT_JSON is not JSON: %v", err)
+	}
+
+	withStdin(func() {
+		if err := runCustomCommand(cmdPath, workDir, nil, true); err != nil {
+			t.Fatalf("runCustomCommand() error = %v", err)
+		}
+	})
+	if got := read("stdin"); !strings.Contains(got, `"command": "import.sh"`) {
+		t.Errorf("stdin = %q, want the JSON context", got)
+	}
+}
+
+func TestFindCustomCommandPlatform(t *testing.T) {
+	workDir := t.TempDir()
+	commands := filepath.Join(workDir, ".space", "commands")
+	if err := os.MkdirAll(commands, 0755); err != nil {
+		t.Fatal(err)
+	}
+	other := "windows"
+	if runtime.GOOS == "windows" {
+		other = "linux"
+	}
+	for name, content := range map[string]string{
+		"setup.sh":                      "echo generic",
+		"setup." + runtime.GOOS + ".sh": "echo native",
+		"setup." + other + ".sh":        "echo other",
+		"brew." + other + ".sh":         "echo brew",
+		"seed.sh":                       "# space: os=" + other + "\necho seed",
+	} {
+		if err := os.WriteFile(filepath.Join(commands, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path, err := findCustomCommand(workDir, "setup")
+	if err != nil || filepath.Base(path) != "setup."+runtime.GOOS+".sh" {
+		t.Errorf("findCustomCommand(setup) = %q, %v; want the %s variant", path, err, runtime.GOOS)
+	}
+	for _, name := range []string{"brew", "seed"} {
+		if _, err := findCustomCommand(workDir, name); err == nil || !strings.Contains(err.Error(), "only for "+other) {
+			t.Errorf("findCustomCommand(%s) error = %v, want only for %s", name, err, other)
+		}
+	}
+
+	if got := strings.Join(listCustomCommands(workDir), ","); got != "seed,setup" {
+		t.Errorf("listCustomCommands = %s, want seed,setup", got)
+	}
+}
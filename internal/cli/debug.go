@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	expvar.Publish("goroutines", expvar.Func(func() interface{} { return runtime.NumGoroutine() }))
+}
+
+// enableDebugListen adds the hidden --debug-listen flag to a long-running
+// command. When set, pprof and expvar are served on the address while the
+// command runs, so issues reported in the field can be profiled without a
+// special build.
+func enableDebugListen(cmd *cobra.Command) {
+	var addr string
+
+	cmd.Flags().StringVar(&addr, "debug-listen", "", "serve pprof and expvar on this address (e.g. 127.0.0.1:6060)")
+	_ = cmd.Flags().MarkHidden("debug-listen")
+
+	preRunE := cmd.PreRunE
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if addr != "" {
+			bound, err := startDebugServer(addr)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(consoleErr, "🐞 Debug endpoints on http://%s/debug/pprof/ and http://%s/debug/vars\n", bound, bound)
+		}
+		if preRunE != nil {
+			return preRunE(cmd, args)
+		}
+		return nil
+	}
+}
+
+// debugHandler serves the pprof and expvar endpoints
+func debugHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return mux
+}
+
+// startDebugServer serves the debug endpoints on addr in the background for
+// the life of the process and returns the bound address
+func startDebugServer(addr string) (string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s for debug endpoints: %w", addr, err)
+	}
+
+	if host, _, err := net.SplitHostPort(listener.Addr().String()); err == nil {
+		if ip := net.ParseIP(host); ip != nil && !ip.IsLoopback() {
+			fmt.Fprintf(consoleErr, "⚠️  Debug endpoints are reachable from other machines on %s\n", listener.Addr())
+		}
+	}
+
+	go func() { _ = http.Serve(listener, debugHandler()) }()
+	return listener.Addr().String(), nil
+}
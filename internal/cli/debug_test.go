@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStartDebugServer(t *testing.T) {
+	addr, err := startDebugServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]string{
+		"/debug/vars":   `"goroutines"`,
+		"/debug/pprof/": "goroutine",
+	} {
+		resp, err := http.Get("http://" + addr + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), want) {
+			t.Errorf("GET %s = %d, want body containing %s", path, resp.StatusCode, want)
+		}
+	}
+}
+
+func TestDebugListenFlagHidden(t *testing.T) {
+	for _, cmd := range []string{"agentd", "status"} {
+		found, _, err := rootCmd.Find([]string{cmd})
+		if err != nil {
+			t.Fatal(err)
+		}
+		flag := found.Flags().Lookup("debug-listen")
+		if flag == nil || !flag.Hidden {
+			t.Errorf("%s: expected hidden --debug-listen flag, got %+v", cmd, flag)
+		}
+	}
+}
@@ -5,10 +5,11 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
-	"strings"
-	"text/tabwriter"
+	"sync"
 	"time"
 
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/pkg/config"
 	"github.com/spf13/cobra"
 )
 
@@ -19,10 +20,13 @@ func newDNSCommand() *cobra.Command {
 		Long:  "Manage the space-dns-daemon for container DNS resolution.",
 	}
 
-	cmd.AddCommand(newDNSStatusCommand())
+	cmd.AddCommand(paged(newDNSStatusCommand()))
 	cmd.AddCommand(newDNSStopCommand())
 	cmd.AddCommand(newDNSStartCommand())
 	cmd.AddCommand(newDNSRestartCommand())
+	cmd.AddCommand(newDNSResolverCommand())
+	cmd.AddCommand(newDNSTraceCommand())
+	cmd.AddCommand(newDNSCacheCommand())
 
 	return cmd
 }
@@ -34,31 +38,38 @@ func newDNSStatusCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			state, err := loadDNSState()
 			if err != nil {
-				fmt.Println("❌ DNS daemon is not running")
-				fmt.Printf("   State file: %s\n", getDNSStateFile())
+				fmt.Fprintln(console, "❌ DNS daemon is not running")
+				fmt.Fprintf(console, "   State file: %s\n", getDNSStateFile())
 				return nil
 			}
 
-			fmt.Println("✅ space-dns-daemon is running")
-			fmt.Printf("   Address:      %s\n", state.Address)
-			fmt.Printf("   Project:      %s\n", state.ProjectName)
-			fmt.Printf("   Started:      %s\n", state.StartTime.Format(time.RFC3339))
-			fmt.Printf("   Uptime:       %s\n", time.Since(state.StartTime).Round(time.Second))
-			fmt.Printf("   State file:   %s\n", getDNSStateFile())
-			fmt.Println()
-			fmt.Println("📡 DNS Configuration:")
-			fmt.Printf("   Domain:       *.space.local\n")
-			fmt.Printf("   Resolver:     /etc/resolver/space.local\n")
-			fmt.Println()
+			fmt.Fprintln(console, "✅ space-dns-daemon is running")
+			fmt.Fprintf(console, "   Address:      %s\n", state.Address)
+			fmt.Fprintf(console, "   Project:      %s\n", state.ProjectName)
+			fmt.Fprintf(console, "   Started:      %s\n", state.StartTime.Format(time.RFC3339))
+			fmt.Fprintf(console, "   Uptime:       %s\n", time.Since(state.StartTime).Round(time.Second))
+			fmt.Fprintf(console, "   State file:   %s\n", getDNSStateFile())
+			fmt.Fprintln(console)
+			fmt.Fprintln(console, "📡 DNS Configuration:")
+			for _, domain := range state.BaseDomains() {
+				fmt.Fprintf(console, "   Domain:       *.%s\n", domain)
+				fmt.Fprintf(console, "   Resolver:     %s\n", resolverSummary(domain, state.Address))
+			}
+			fmt.Fprintln(console)
 
 			// List all registered DNS records
-			records, err := listDNSRecords(context.Background())
+			var records []DNSRecord
+			err = step("docker inspect of running containers", false, func() error {
+				var err error
+				records, err = listDNSRecords(context.Background(), state.BaseDomains()[0])
+				return err
+			})
 			if err != nil {
-				fmt.Printf("⚠️  Could not list DNS records: %v\n", err)
+				fmt.Fprintf(console, "⚠️  Could not list DNS records: %v\n", err)
 			} else if len(records) > 0 {
-				fmt.Println("📋 Registered DNS Records:")
-				fmt.Println()
-				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 3, ' ', 0)
+				fmt.Fprintln(console, "📋 Registered DNS Records:")
+				fmt.Fprintln(console)
+				w := newTable(cmd.OutOrStdout())
 				fmt.Fprintln(w, "HOSTNAME\tIP ADDRESS\tSERVICE\tPROJECT")
 				fmt.Fprintln(w, "--------\t----------\t-------\t-------")
 				for _, record := range records {
@@ -70,14 +81,14 @@ func newDNSStatusCommand() *cobra.Command {
 					)
 				}
 				w.Flush()
-				fmt.Println()
+				fmt.Fprintln(console)
 			} else {
-				fmt.Println("📋 No DNS records registered (no containers running)")
-				fmt.Println()
+				fmt.Fprintln(console, "📋 No DNS records registered (no containers running)")
+				fmt.Fprintln(console)
 			}
 
-			fmt.Println("💡 Test DNS resolution:")
-			fmt.Printf("   dig @%s <hostname>\n", state.Address)
+			fmt.Fprintln(console, "💡 Test DNS resolution:")
+			fmt.Fprintf(console, "   dig @%s <hostname>\n", state.Address)
 
 			return nil
 		},
@@ -91,22 +102,24 @@ func newDNSStopCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			state, err := loadDNSState()
 			if err != nil {
-				fmt.Println("ℹ️  DNS daemon is not running")
+				fmt.Fprintln(console, "ℹ️  DNS daemon is not running")
 				return nil
 			}
 
-			fmt.Printf("🛑 Stopping space-dns-daemon (%s)...\n", state.Address)
+			fmt.Fprintf(console, "🛑 Stopping space-dns-daemon (%s)...\n", state.Address)
 
-			// Note: We can't actually stop a server running in another process
-			// This just removes the state file as the server runs in the parent process
-			if err := removeDNSState(); err != nil {
-				return fmt.Errorf("failed to remove DNS state: %w", err)
+			// The DNS server lives in space agentd; a state file without a
+			// reachable agent is left over from a crash
+			if err := stopAgent(); err != nil {
+				if err := removeDNSState(); err != nil {
+					return fmt.Errorf("failed to remove DNS state: %w", err)
+				}
 			}
 
-			fmt.Println("✅ DNS daemon stopped")
-			fmt.Println()
-			fmt.Println("💡 Note: DNS resolver configuration at /etc/resolver/space.local is preserved")
-			fmt.Println("   Run 'space dns start' to start the daemon again")
+			fmt.Fprintln(console, "✅ DNS daemon stopped")
+			fmt.Fprintln(console)
+			fmt.Fprintln(console, "💡 Note: DNS resolver configuration at /etc/resolver/space.local is preserved")
+			fmt.Fprintln(console, "   Run 'space dns start' to start the daemon again")
 
 			return nil
 		},
@@ -117,41 +130,18 @@ func newDNSStartCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "start",
 		Short: "Start DNS daemon",
-		Long: `Start the space-dns-daemon in the foreground.
+		Long: `Start the space-dns-daemon in the foreground. The DNS server is run by
+space agentd, so this is the same as 'space agentd'.
 
 The DNS daemon will continue running until stopped with Ctrl+C or 'space dns stop'.
 To run in the background, use: space dns start &`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Check if already running
-			if isDNSServerRunning() {
-				state, _ := loadDNSState()
-				fmt.Printf("ℹ️  DNS daemon is already running on %s\n", state.Address)
-				return nil
-			}
-
-			ctx := context.Background()
-			// Empty project name means search all projects
-			projectName := ""
-
-			fmt.Println("🌐 Starting space-dns-daemon...")
-
-			if err := startDNSServer(ctx, projectName); err != nil {
-				return fmt.Errorf("failed to start DNS daemon: %w", err)
-			}
-
-			state, _ := loadDNSState()
-			fmt.Printf("✅ DNS daemon started on %s\n", state.Address)
-			fmt.Println("🔄 DNS daemon is running... (Press Ctrl+C to stop)")
-			fmt.Println()
-			fmt.Println("💡 Containers will be accessible at: *.space.local")
-			fmt.Println("💡 To run in background: space dns start &")
-			fmt.Println()
-
-			// Block forever to keep the DNS server running
-			select {}
+			return runAgent()
 		},
 	}
 
+	enableDebugListen(cmd)
+
 	return cmd
 }
 
@@ -162,25 +152,31 @@ func newDNSRestartCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Stop if running
 			if isDNSServerRunning() {
-				fmt.Println("🛑 Stopping DNS daemon...")
-				if err := removeDNSState(); err != nil {
-					return fmt.Errorf("failed to stop DNS daemon: %w", err)
+				fmt.Fprintln(console, "🛑 Stopping DNS daemon...")
+				if err := stopAgent(); err != nil {
+					if err := removeDNSState(); err != nil {
+						return fmt.Errorf("failed to stop DNS daemon: %w", err)
+					}
 				}
-				time.Sleep(500 * time.Millisecond)
 			}
 
-			// Start
-			ctx := context.Background()
-			projectName := "space"
-
-			fmt.Println("🌐 Starting space-dns-daemon...")
-
-			if err := startDNSServer(ctx, projectName); err != nil {
+			// Start in the background like 'space up' does
+			fmt.Fprintln(console, "🌐 Starting space-dns-daemon...")
+			if err := spawnAgent(); err != nil {
 				return fmt.Errorf("failed to start DNS daemon: %w", err)
 			}
 
-			state, _ := loadDNSState()
-			fmt.Printf("✅ DNS daemon restarted on %s\n", state.Address)
+			for i := 0; i < 50 && !isDNSServerRunning(); i++ {
+				time.Sleep(100 * time.Millisecond)
+			}
+			state, err := loadDNSState()
+			if err != nil {
+				if logPath, err := agentLogPath(); err == nil {
+					return fmt.Errorf("DNS daemon failed to start, see %s", logPath)
+				}
+				return fmt.Errorf("DNS daemon failed to start")
+			}
+			fmt.Fprintf(console, "✅ DNS daemon restarted on %s\n", state.Address)
 
 			return nil
 		},
@@ -195,73 +191,107 @@ type DNSRecord struct {
 	ProjectName string
 }
 
-// listDNSRecords lists all DNS records from running Docker containers
-func listDNSRecords(ctx context.Context) ([]DNSRecord, error) {
+// listDNSRecords lists all DNS records from running Docker containers, with
+// hostnames in the given base domain
+func listDNSRecords(ctx context.Context, domain string) ([]DNSRecord, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
 	// Get all running containers with their labels and IPs
-	cmd := exec.CommandContext(ctx, "docker", "ps",
-		"--format", "{{.Names}}|{{.Label \"com.docker.compose.service\"}}|{{.Label \"com.docker.compose.project\"}}|{{.Label \"com.docker.compose.project.working_dir\"}}")
+	cmd := exec.CommandContext(ctx, "docker", "ps", "--format", dns.PsFormat)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
 	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("failed to list containers: %w", err)
+		return nil, fmt.Errorf("failed to list containers: %w", timeoutError(ctx, "docker", dockerQueryTimeout, err))
 	}
 
-	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
-	records := make([]DNSRecord, 0, len(lines))
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
+	containers := dns.ParseContainers(stdout.String())
+	records := make([]DNSRecord, 0, len(containers))
 
-		parts := strings.Split(line, "|")
-		if len(parts) < 4 {
-			continue
-		}
-
-		containerName := parts[0]
-		serviceName := parts[1]
-		projectName := parts[2]
-		workDir := parts[3]
+	names := make([]string, 0, len(containers))
+	for _, container := range containers {
+		names = append(names, container.Name)
+	}
+	ips, err := containerIPs(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect containers: %w", timeoutError(ctx, "docker", dockerQueryTimeout, err))
+	}
 
+	labels := dns.Labels(containers)
+	for _, container := range containers {
 		// Skip containers without compose labels
-		if serviceName == "" || workDir == "" {
+		if container.Service == "" || container.WorkDir == "" {
 			continue
 		}
 
-		// Get container IP
-		ip, err := getContainerIP(ctx, containerName)
-		if err != nil || ip == "" {
+		ip := ips[container.Name]
+		if ip == "" {
 			continue
 		}
 
-		// Generate DNS hostname with hash
-		hostname := generateDNSDomain(serviceName, workDir)
-
-		records = append(records, DNSRecord{
-			Hostname:    hostname,
-			IPAddress:   ip,
-			ServiceName: serviceName,
-			ProjectName: projectName,
-		})
+		// One record per label: the service, its replica and container_name
+		for _, label := range labels[container.Name] {
+			records = append(records, DNSRecord{
+				Hostname:    generateDNSDomainIn(label, container.WorkDir, domain),
+				IPAddress:   ip,
+				ServiceName: container.Service,
+				ProjectName: container.Project,
+			})
+		}
 	}
 
 	return records, nil
 }
 
-// getContainerIP gets the IP address of a container
-func getContainerIP(ctx context.Context, containerName string) (string, error) {
-	cmd := exec.CommandContext(ctx, "docker", "inspect",
-		"--format", "{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}",
-		containerName)
+// containerIPMemo remembers container addresses for the rest of the
+// command, so commands that list records more than once inspect each
+// container only once
+var containerIPMemo = struct {
+	sync.Mutex
+	ips map[string]string
+}{ips: make(map[string]string)}
+
+// containerIPs returns the IP address of each named container that has one,
+// inspecting the ones not seen yet in this command in batches
+func containerIPs(ctx context.Context, names []string) (map[string]string, error) {
+	containerIPMemo.Lock()
+	defer containerIPMemo.Unlock()
+
+	var missing []string
+	for _, name := range names {
+		if _, ok := containerIPMemo.ips[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		inspected, err := dns.InspectContainerIPs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for name, ip := range inspected {
+			containerIPMemo.ips[name] = ip
+		}
+	}
 
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
+	ips := make(map[string]string, len(names))
+	for _, name := range names {
+		if ip, ok := containerIPMemo.ips[name]; ok {
+			ips[name] = ip
+		}
 	}
+	return ips, nil
+}
 
-	return strings.TrimSpace(string(output)), nil
+// daemonConfig loads the configuration the DNS daemon runs with from workDir,
+// falling back to the defaults
+func daemonConfig(workDir string) *config.Config {
+	if loader, err := newConfigLoader(workDir); err == nil {
+		if cfg, err := loader.Load(); err == nil {
+			return cfg
+		}
+	}
+	return config.Defaults()
 }
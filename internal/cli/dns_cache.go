@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/internal/events"
+	"github.com/spf13/cobra"
+)
+
+// Control socket ops serving the DNS cache
+const (
+	dnsCacheListOp  = "dns.cache.list"
+	dnsCacheFlushOp = "dns.cache.flush"
+)
+
+// dnsCacheFlushed is the reply to dns.cache.flush
+type dnsCacheFlushed struct {
+	Flushed int `json:"flushed"`
+}
+
+func newDNSCacheCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Inspect and flush the DNS daemon's cache",
+		Long: `Container addresses are cached by the DNS daemon for 30 seconds. When a
+hostname answers with an old address, list the cache to see whether the
+answer comes from it, and flush it to make the next query ask docker.
+
+To skip the cache for a single query, put "` + dns.NoCacheLabel + `" in front of the domain:
+
+  dig @127.0.0.1 -p 5353 api-a1b2c3.` + dns.NoCacheLabel + `.space.local
+
+If that answers and the plain hostname doesn't (or answers differently),
+the cache is stale; if both fail, the container isn't registered.`,
+	}
+
+	cmd.AddCommand(newDNSCacheListCommand())
+	cmd.AddCommand(newDNSCacheFlushCommand())
+
+	return cmd
+}
+
+func newDNSCacheListCommand() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the cached container addresses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries := []dns.CacheEntry{}
+			err := callAgent(dnsCacheListOp, nil, func(data json.RawMessage) error {
+				return json.Unmarshal(data, &entries)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to read the DNS cache (is the daemon running? try 'space dns start'): %w", err)
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(entries)
+			}
+
+			if len(entries) == 0 {
+				fmt.Fprintln(console, "The DNS cache is empty.")
+				return nil
+			}
+
+			w := newTable(console)
+			fmt.Fprintln(w, "HOSTNAME\tIP ADDRESS\tEXPIRES IN")
+			for _, entry := range entries {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Hostname, entry.IP, entry.TTL.Round(time.Second))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+
+	return cmd
+}
+
+func newDNSCacheFlushCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "flush [hostname]",
+		Short: "Drop a hostname from the DNS cache (all of it when none is given)",
+		Long: `Drop a hostname from the DNS daemon's cache, or the whole cache when no
+hostname is given. A bare service name (without dots) is expanded to its
+hostname in the current project.`,
+		Example: `  space dns cache flush
+  space dns cache flush api
+  space dns cache flush api-a1b2c3.space.local`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hostname := ""
+			if len(args) == 1 {
+				hostname = args[0]
+				if !strings.Contains(hostname, ".") {
+					hostname = serviceHostname(hostname)
+				}
+			}
+
+			var reply dnsCacheFlushed
+			err := callAgent(dnsCacheFlushOp, map[string]string{"hostname": hostname}, func(data json.RawMessage) error {
+				return json.Unmarshal(data, &reply)
+			})
+			if err != nil {
+				return fmt.Errorf("failed to flush the DNS cache (is the daemon running? try 'space dns start'): %w", err)
+			}
+
+			switch {
+			case hostname == "":
+				fmt.Fprintf(console, "🧹 Flushed the DNS cache (%d removed)\n", reply.Flushed)
+			case reply.Flushed == 0:
+				fmt.Fprintf(console, "ℹ️  %s was not cached\n", hostname)
+			default:
+				fmt.Fprintf(console, "🧹 Flushed %s\n", hostname)
+			}
+			return nil
+		},
+	}
+}
+
+// dnsCacheListHandler serves dns.cache.list requests from the control socket
+func dnsCacheListHandler(server *dns.Server) events.Handler {
+	return func(ctx context.Context, req events.Request, send func(interface{}) error) error {
+		if server == nil {
+			return fmt.Errorf("DNS server is not running")
+		}
+		return send(server.CacheEntries())
+	}
+}
+
+// dnsCacheFlushHandler serves dns.cache.flush requests from the control
+// socket; an empty hostname flushes the whole cache
+func dnsCacheFlushHandler(server *dns.Server) events.Handler {
+	return func(ctx context.Context, req events.Request, send func(interface{}) error) error {
+		if server == nil {
+			return fmt.Errorf("DNS server is not running")
+		}
+		return send(dnsCacheFlushed{Flushed: server.FlushCache(req.Args["hostname"])})
+	}
+}
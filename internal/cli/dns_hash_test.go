@@ -320,6 +320,25 @@ func TestGenerateDNSDomain_Format(t *testing.T) {
 	t.Logf("Domain: %s (expected pattern: %s)", domain, expectedPattern)
 }
 
+func TestGenerateDNSDomain_NormalizesServiceName(t *testing.T) {
+	workDir := "/Users/developer/project"
+	hash := generateDirectoryHash(workDir)
+
+	if got, want := generateDNSDomain("Api_Server", workDir), "api-server-"+hash+".space.local"; got != want {
+		t.Errorf("generateDNSDomain() = %q, want %q", got, want)
+	}
+
+	long := strings.Repeat("background-worker-", 5)
+	domain := generateDNSDomain(long, workDir)
+	label := strings.Split(domain, ".")[0]
+	if len(label) > 63 {
+		t.Errorf("label %q is %d characters, want at most 63", label, len(label))
+	}
+	if !strings.HasSuffix(label, "-"+hash) {
+		t.Errorf("label %q lost the directory hash", label)
+	}
+}
+
 // BenchmarkGenerateDirectoryHash benchmarks hash generation performance
 func BenchmarkGenerateDirectoryHash(b *testing.B) {
 	path := "/Users/developer/very/long/path/to/project/worktree/feature/branch"
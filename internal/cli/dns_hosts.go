@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// hostsMode reports whether service hostnames are written to /etc/hosts
+// instead of being served by the DNS daemon
+func hostsMode(cfg *config.Config) bool {
+	return cfg != nil && cfg.DNS.Mode == config.DNSModeHosts
+}
+
+// syncHostsFile rewrites the project's /etc/hosts block with the hostnames
+// and IPs of its running containers. Container IPs change on recreate, so
+// it runs after every up, restart and selective down.
+func syncHostsFile(ctx context.Context, cfg *config.Config, workDir, projectName string) error {
+	entries, err := projectHostEntries(ctx, cfg, workDir, projectName)
+	if err != nil {
+		return err
+	}
+
+	hosts := dns.NewHostsFile(projectName, dns.NewStdLogger())
+	fmt.Fprintf(console, "📝 Updating %s (may require sudo password)...\n", hosts.File())
+	return step("sudo hosts file update", true, func() error {
+		return hosts.Update(ctx, entries)
+	})
+}
+
+// removeHostsFileBlock removes the project's /etc/hosts block
+func removeHostsFileBlock(ctx context.Context, projectName string) error {
+	hosts := dns.NewHostsFile(projectName, dns.NewStdLogger())
+	if entries, err := hosts.Entries(); err == nil && len(entries) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(console, "📝 Removing entries from %s (may require sudo password)...\n", hosts.File())
+	return step("sudo hosts file update", true, func() error {
+		return hosts.Remove(ctx)
+	})
+}
+
+// projectHostEntries lists a hosts entry for each running container of the
+// project, carrying its hostname in every base domain
+func projectHostEntries(ctx context.Context, cfg *config.Config, workDir, projectName string) ([]dns.HostEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "docker", "ps",
+		"--filter", "label=com.docker.compose.project="+projectName,
+		"--format", dns.PsFormat)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", timeoutError(ctx, "docker", dockerQueryTimeout, err))
+	}
+
+	containers := dns.ParseContainers(stdout.String())
+	names := make([]string, 0, len(containers))
+	for _, container := range containers {
+		names = append(names, container.Name)
+	}
+	ips, err := containerIPs(ctx, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect containers: %w", timeoutError(ctx, "docker", dockerQueryTimeout, err))
+	}
+
+	labels := dns.Labels(containers)
+	var entries []dns.HostEntry
+	for _, container := range containers {
+		ip := ips[container.Name]
+		if ip == "" || len(labels[container.Name]) == 0 {
+			continue
+		}
+
+		var hostnames []string
+		for _, label := range labels[container.Name] {
+			hostnames = append(hostnames, serviceHostnames(cfg, label, workDir)...)
+		}
+		entries = append(entries, dns.HostEntry{IP: ip, Hostnames: hostnames})
+	}
+
+	return entries, nil
+}
+
+// serviceHostnames returns the hostnames of a service in every base domain,
+// the primary domain first
+func serviceHostnames(cfg *config.Config, serviceName, workDir string) []string {
+	var hostnames []string
+	for _, domain := range cfg.Network.BaseDomains() {
+		if cfg.Network.DNSHashing {
+			hostnames = append(hostnames, generateDNSDomainIn(serviceName, workDir, domain))
+		} else {
+			label, _ := dns.NormalizeLabel(serviceName)
+			hostnames = append(hostnames, label+"."+domain)
+		}
+	}
+	return hostnames
+}
+
+// serviceDNSActive reports whether service hostnames resolve for the project
+// in workDir: through the hosts file block in hosts mode, or the DNS daemon
+func serviceDNSActive(cfg *config.Config, workDir string) bool {
+	if hostsMode(cfg) {
+		hosts := dns.NewHostsFile(generateProjectName(cfg, workDir), dns.NewStdLogger())
+		entries, err := hosts.Entries()
+		return err == nil && len(entries) > 0
+	}
+	return isDNSServerRunning()
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestServiceHostnames(t *testing.T) {
+	workDir := "/projects/shop"
+	hash := generateDirectoryHash(workDir)
+
+	cfg := config.Defaults()
+	cfg.Network.CustomDomain = config.Domains{"shop.test", "space.local"}
+
+	want := []string{"web-" + hash + ".shop.test", "web-" + hash + ".space.local"}
+	if got := serviceHostnames(cfg, "web", workDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("serviceHostnames() = %v, want %v", got, want)
+	}
+
+	cfg.Network.DNSHashing = false
+	want = []string{"web.shop.test", "web.space.local"}
+	if got := serviceHostnames(cfg, "web", workDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("serviceHostnames() without hashing = %v, want %v", got, want)
+	}
+}
+
+func TestHostsMode(t *testing.T) {
+	cfg := config.Defaults()
+	if hostsMode(cfg) {
+		t.Error("hostsMode() = true for default config")
+	}
+
+	cfg.DNS.Mode = config.DNSModeHosts
+	if !hostsMode(cfg) {
+		t.Error("hostsMode() = false with dns.mode: hosts")
+	}
+}
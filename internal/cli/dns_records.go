@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/internal/events"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// dnsRecordsOp is the control socket op replacing a project's static DNS
+// records (dns.records in the config)
+const dnsRecordsOp = "dns.records"
+
+// dnsRecords returns the static records of cfg. A value that is not an
+// IPv4 address names a service, which the record becomes an alias of.
+func dnsRecords(cfg *config.Config, workDir string) []dns.Record {
+	names := make([]string, 0, len(cfg.DNS.Records))
+	for name := range cfg.DNS.Records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var records []dns.Record
+	for _, name := range names {
+		value := strings.TrimSpace(cfg.DNS.Records[name])
+		if ip := net.ParseIP(value); ip != nil {
+			if ip.To4() == nil {
+				fmt.Fprintf(console, "⚠️  Skipping DNS record %s: only IPv4 addresses are supported\n", name)
+				continue
+			}
+			records = append(records, dns.Record{Name: name, IP: value})
+			continue
+		}
+		records = append(records, dns.Record{Name: name, Target: dnsHostname(cfg, value, workDir)})
+	}
+	return records
+}
+
+// resolverDomains returns the domains that need a resolver file pointing at
+// the daemon: the base domains and the parent domains of static records
+func resolverDomains(cfg *config.Config) []string {
+	return append(cfg.Network.BaseDomains(), cfg.RecordZones()...)
+}
+
+// dnsRecordsHandler serves dns.records requests from the control socket
+func dnsRecordsHandler(server *dns.Server) events.Handler {
+	return func(ctx context.Context, req events.Request, send func(interface{}) error) error {
+		project := req.Args["project"]
+		if project == "" {
+			return fmt.Errorf("dns.records requires a project")
+		}
+		if server == nil {
+			return fmt.Errorf("DNS server is not running")
+		}
+
+		var records []dns.Record
+		if data := req.Args["records"]; data != "" {
+			if err := json.Unmarshal([]byte(data), &records); err != nil {
+				return fmt.Errorf("invalid records: %w", err)
+			}
+		}
+		server.SetRecords(project, records)
+		return nil
+	}
+}
+
+// registerDNSRecords hands the project's static records to the agent. It
+// is best effort: without a running agent nothing happens.
+func registerDNSRecords(projectName string, records []dns.Record) {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return
+	}
+	err = callAgent(dnsRecordsOp, map[string]string{
+		"project": projectName,
+		"records": string(data),
+	}, nil)
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  Could not register static DNS records: %v\n", err)
+		return
+	}
+	for _, rec := range records {
+		answer := rec.IP
+		if answer == "" {
+			answer = rec.Target
+		}
+		fmt.Fprintf(console, "📌 %s → %s\n", rec.Name, answer)
+	}
+}
+
+// releaseDNSRecords removes the project's static records from the agent
+func releaseDNSRecords(projectName string) {
+	_ = callAgent(dnsRecordsOp, map[string]string{"project": projectName}, nil)
+}
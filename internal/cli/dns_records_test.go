@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestDNSRecords(t *testing.T) {
+	cfg := config.Defaults()
+	cfg.DNS.Records = map[string]string{
+		"db.internal":  "db",
+		"ldap.corp":    "10.0.0.5",
+		"v6.internal":  "fd00::1",
+		"api.internal": " api ",
+	}
+	workDir := "/tmp/project"
+
+	want := []dns.Record{
+		{Name: "api.internal", Target: dnsHostname(cfg, "api", workDir)},
+		{Name: "db.internal", Target: dnsHostname(cfg, "db", workDir)},
+		{Name: "ldap.corp", IP: "10.0.0.5"},
+	}
+	if got := dnsRecords(cfg, workDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("dnsRecords() = %+v, want %+v", got, want)
+	}
+
+	if got := resolverDomains(cfg); !reflect.DeepEqual(got, []string{config.DefaultBaseDomain, "corp", "internal"}) {
+		t.Errorf("resolverDomains() = %v", got)
+	}
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/spf13/cobra"
+)
+
+func newDNSResolverCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resolver",
+		Short: "Inspect and repair the system resolver configuration",
+		Long: `Inspect and repair /etc/resolver/space.local, which routes *.space.local
+lookups to the DNS daemon. The file goes stale when the daemon binds a
+different port than the one it was written for.`,
+	}
+
+	cmd.AddCommand(newDNSResolverStatusCommand())
+	cmd.AddCommand(newDNSResolverRepairCommand())
+
+	return cmd
+}
+
+func newDNSResolverStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Check that the resolver points at the DNS daemon",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := loadDNSState()
+			if err != nil {
+				return fmt.Errorf("DNS daemon is not running; start it with 'space dns start'")
+			}
+
+			for i, domain := range state.BaseDomains() {
+				if i > 0 {
+					fmt.Fprintln(console)
+				}
+
+				resolver := dns.NewResolverManager(domain, state.Address, dns.NewStdLogger())
+				status, err := resolver.Verify()
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(console, "   File:         %s\n", status.File)
+				fmt.Fprintf(console, "   Daemon:       %s\n", status.Expected)
+
+				switch {
+				case status.OK():
+					fmt.Fprintf(console, "   Configured:   %s\n", status.Configured())
+					fmt.Fprintln(console)
+					fmt.Fprintln(console, "✅ Resolver is configured correctly")
+				case status.Stale():
+					fmt.Fprintf(console, "   Configured:   %s\n", status.Configured())
+					fmt.Fprintln(console)
+					fmt.Fprintln(console, "❌ Resolver is stale")
+					fmt.Fprintln(console, "💡 Run 'space dns resolver repair' to fix it")
+				default:
+					fmt.Fprintln(console)
+					fmt.Fprintln(console, "❌ Resolver is not configured")
+					fmt.Fprintln(console, "💡 Run 'space dns resolver repair' to create it")
+				}
+			}
+
+			return nil
+		},
+	}
+}
+
+func newDNSResolverRepairCommand() *cobra.Command {
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "repair",
+		Short: "Rewrite the resolver to point at the DNS daemon (requires sudo)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := loadDNSState()
+			if err != nil {
+				return fmt.Errorf("DNS daemon is not running; start it with 'space dns start'")
+			}
+
+			for _, domain := range state.BaseDomains() {
+				resolver := dns.NewResolverManager(domain, state.Address, dns.NewStdLogger())
+				status, err := resolver.Verify()
+				if err != nil {
+					return err
+				}
+
+				if status.OK() {
+					fmt.Fprintf(console, "✅ %s is already configured correctly\n", status.File)
+					continue
+				}
+
+				if !yes && !confirm(fmt.Sprintf("Point %s at %s?", status.File, status.Expected)) {
+					fmt.Fprintf(console, "ℹ️  %s left unchanged\n", status.File)
+					continue
+				}
+
+				fmt.Fprintf(console, "📝 Repairing %s (may require sudo password)...\n", status.File)
+				repair := func() error { return resolver.Repair(context.Background()) }
+				if err := step("sudo resolver repair", true, repair); err != nil {
+					return fmt.Errorf("failed to repair resolver: %w", err)
+				}
+
+				fmt.Fprintf(console, "✅ %s repaired\n", status.File)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Repair without asking for confirmation")
+
+	return cmd
+}
+
+// ensureResolver verifies the resolver file against the bound DNS address.
+// It never asks for sudo, since the agent usually runs without a terminal; a
+// missing or stale file is reported with the command that fixes it.
+func ensureResolver(resolver *dns.ResolverManager) {
+	if runtime.GOOS != "darwin" {
+		return
+	}
+
+	status, err := resolver.Verify()
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  Failed to check %s: %v\n", resolver.File(), err)
+		return
+	}
+
+	switch {
+	case status.OK():
+	case status.Stale() && status.Expected != dns.StableAddr():
+		fmt.Fprintf(console, "⚠️  %s points at %s, but the DNS daemon is on %s\n", status.File, status.Configured(), status.Expected)
+		fmt.Fprintln(console, "💡 Run 'space setup' so the daemon can use its stable address, or 'space dns resolver repair'")
+	case status.Stale():
+		fmt.Fprintf(console, "⚠️  %s points at %s, but the DNS daemon is on %s\n", status.File, status.Configured(), status.Expected)
+		fmt.Fprintln(console, "💡 Run 'space setup' to fix it")
+	default:
+		fmt.Fprintf(console, "⚠️  %s is missing\n", status.File)
+		fmt.Fprintln(console, "💡 Run 'space setup' to create it")
+	}
+}
+
+// confirm asks a yes/no question on the terminal. Without a terminal the
+// answer is yes so scripted runs behave like before.
+func confirm(question string) bool {
+	if !isInteractive() {
+		return true
+	}
+
+	fmt.Fprintf(console, "❓ %s [Y/n] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "" || answer == "y" || answer == "yes"
+}
+
+// confirmDestructive asks before an operation that destroys project data,
+// naming the project and directory it would hit so a mistyped WORKDIR or
+// project is caught. The answer defaults to no, and without a terminal the
+// operation is refused unless yes is set.
+func confirmDestructive(action, projectName, workDir string, yes bool) error {
+	fmt.Fprintf(console, "⚠️  This will %s of project %s\n", action, projectName)
+	fmt.Fprintf(console, "   📁 %s\n", workDir)
+	if yes {
+		return nil
+	}
+	if !isInteractive() {
+		return fmt.Errorf("refusing to %s without confirmation; pass --yes to proceed", action)
+	}
+
+	fmt.Fprint(console, "❓ Continue? [y/N] ")
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if err != nil || (answer != "y" && answer != "yes") {
+		return fmt.Errorf("aborted")
+	}
+	return nil
+}
+
+// resolverSummary describes the state of a domain's resolver file in one line
+func resolverSummary(domain, dnsAddr string) string {
+	resolver := dns.NewResolverManager(domain, dnsAddr, dns.NewStdLogger())
+	status, err := resolver.Verify()
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%s (%v)", resolver.File(), err)
+	case status.OK():
+		return status.File
+	case status.Stale():
+		return fmt.Sprintf("%s (stale: points at %s, run 'space dns resolver repair')", status.File, status.Configured())
+	default:
+		return fmt.Sprintf("%s (missing, run 'space dns resolver repair')", status.File)
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/internal/events"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// dnsTraceOp is the control socket op serving DNS traces
+const dnsTraceOp = "dns.trace"
+
+func newDNSTraceCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "trace <hostname>",
+		Short: "Trace how the DNS daemon resolves a hostname",
+		Long: `Resolve a hostname through the running DNS daemon and show every step:
+a static record, the domain match, the cache, how the name maps to a
+service, the docker lookup, and the upstream server for names outside
+*.space.local.
+
+A bare service name (without dots) is expanded to its hostname in the
+current project.`,
+		Example: `  space dns trace api
+  space dns trace api-a1b2c3.space.local
+  space dns trace github.com`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hostname := args[0]
+			if !strings.Contains(hostname, ".") {
+				hostname = serviceHostname(hostname)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			fmt.Fprintf(console, "🔎 Tracing %s\n\n", hostname)
+
+			var answer dns.TraceStep
+			req := events.Request{Op: dnsTraceOp, Args: map[string]string{"hostname": hostname}}
+			err := events.Call(ctx, events.SocketPath(), req, func(data json.RawMessage) error {
+				var step dns.TraceStep
+				if err := json.Unmarshal(data, &step); err != nil {
+					return err
+				}
+				if step.Stage == dns.StageAnswer {
+					answer = step
+					return nil
+				}
+				printTraceStep(step)
+				return nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to trace through the DNS daemon (is it running? try 'space dns start'): %w", err)
+			}
+
+			fmt.Fprintln(console)
+			if answer.Detail == "" {
+				fmt.Fprintf(console, "❌ %s does not resolve\n", hostname)
+				if answer.Error != "" {
+					fmt.Fprintf(console, "   %s\n", answer.Error)
+				}
+				return nil
+			}
+			fmt.Fprintf(console, "✅ %s → %s (%s)\n", hostname, answer.Detail, answer.Elapsed.Round(time.Microsecond))
+			return nil
+		},
+	}
+}
+
+// printTraceStep prints one stage of a trace
+func printTraceStep(step dns.TraceStep) {
+	icon := "✓"
+	if step.Error != "" {
+		icon = "✗"
+	}
+
+	line := fmt.Sprintf("   %s %-9s %s", icon, step.Stage, step.Detail)
+	if step.Elapsed > 0 {
+		line += fmt.Sprintf(" (%s)", step.Elapsed.Round(time.Microsecond))
+	}
+	fmt.Fprintln(console, line)
+	if step.Error != "" {
+		fmt.Fprintf(console, "     %s\n", step.Error)
+	}
+}
+
+// dnsTraceHandler serves trace requests from the control socket
+func dnsTraceHandler(server *dns.Server) events.Handler {
+	return func(ctx context.Context, req events.Request, send func(interface{}) error) error {
+		hostname := req.Args["hostname"]
+		if hostname == "" {
+			return fmt.Errorf("trace requires a hostname")
+		}
+		if server == nil {
+			return fmt.Errorf("DNS server is not running")
+		}
+
+		var sendErr error
+		start := time.Now()
+		ip, err := server.Trace(ctx, hostname, func(step dns.TraceStep) {
+			if sendErr == nil {
+				sendErr = send(step)
+			}
+		})
+		if sendErr != nil {
+			return sendErr
+		}
+
+		answer := dns.TraceStep{Stage: dns.StageAnswer, Detail: ip, Elapsed: time.Since(start)}
+		if err != nil {
+			answer.Error = err.Error()
+		}
+		return send(answer)
+	}
+}
+
+// serviceHostname expands a service name to its hostname in the current
+// project, honoring the project's DNS hashing setting
+func serviceHostname(service string) string {
+	workDir, err := filepath.Abs(Workdir)
+	if err != nil {
+		workDir, _ = os.Getwd()
+	}
+
+	var cfg *config.Config
+	if loader, err := newConfigLoader(workDir); err == nil {
+		cfg, _ = loader.Load()
+	}
+	return dnsHostname(cfg, service, workDir)
+}
@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// upHookEvents are the script hook events space up runs
+var upHookEvents = []hooks.EventType{hooks.PreUp, hooks.PostUp, hooks.OnDNSReady}
+
+// newDoctorCommand creates the doctor command
+func newDoctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the tools, setup and hook prerequisites space needs",
+		Long: `Check this machine for everything the project needs:
+
+  • docker and docker compose, at the supported versions
+  • the privileged DNS setup done by 'space setup' (macOS only)
+  • the commands hooks declare they need, through hooks.requires in
+    .space.yaml or a "# space: requires=jq,psql" comment in a script
+  • bind-mounted host paths owned by root, and on Linux services that
+    write into bind mounts as the image's user (see project.map_user)
+
+Each problem is printed with the command that fixes it, such as a brew,
+apt or go install line for a missing hook prerequisite.`,
+		Example: `  space doctor`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+			workDir := workDirOrEmpty()
+			cfg := daemonConfig(workDir)
+
+			problems := 0
+
+			fmt.Fprintln(console, "🔧 Tools:")
+			for _, tool := range checkTools(ctx, toolRequirements) {
+				version := tool.Version
+				if version == "" {
+					version = "-"
+				}
+				fmt.Fprintf(console, "   %s %s %s\n", toolStatusLabel(tool), tool.Name, version)
+				if tool.Required && (tool.Status == toolMissing || tool.Status == toolOutdated) {
+					problems++
+				}
+			}
+
+			fmt.Fprintln(console)
+			fmt.Fprintln(console, "🔐 Setup:")
+			steps, err := dns.PlanSetup(resolverDomains(cfg))
+			switch {
+			case err != nil:
+				fmt.Fprintf(console, "   ⚠️  Failed to check setup: %v\n", err)
+			case len(steps) == 0:
+				fmt.Fprintln(console, "   ✅ Privileged setup is complete")
+			default:
+				for _, step := range steps {
+					fmt.Fprintf(console, "   ❌ %s\n", step.Name)
+				}
+				fmt.Fprintln(console, "      space setup")
+				problems++
+			}
+
+			fmt.Fprintln(console)
+			fmt.Fprintln(console, "🪝 Hook prerequisites:")
+			prereqs := hooks.Prerequisites(workDir, hooks.AllEventTypes(), cfg.Hooks.Requires)
+			missing := hooks.MissingPrerequisites(workDir, prereqs)
+			if len(prereqs) == 0 {
+				fmt.Fprintln(console, "   ✅ None declared")
+			}
+			for _, p := range prereqs {
+				if !isMissing(missing, p.Command) {
+					fmt.Fprintf(console, "   ✅ %s\n", p.Command)
+				}
+			}
+			printMissingPrerequisites(missing)
+			problems += len(missing)
+
+			if project, err := loadComposeProject(workDir, cfg); err == nil && hasBindMounts(project) {
+				fmt.Fprintln(console)
+				fmt.Fprintln(console, "📂 Bind mounts:")
+				bindProblems, bindWarnings := bindMountFindings(cfg, project, runtime.GOOS)
+				for _, p := range bindProblems {
+					fmt.Fprintf(console, "   ❌ %s\n", p)
+				}
+				for _, w := range bindWarnings {
+					fmt.Fprintf(console, "   ⚠️  %s\n", w)
+				}
+				if len(bindProblems) == 0 && len(bindWarnings) == 0 {
+					fmt.Fprintln(console, "   ✅ No ownership problems")
+				}
+				problems += len(bindProblems)
+			}
+
+			if problems > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("found %d problem(s)", problems)
+			}
+			fmt.Fprintln(console)
+			fmt.Fprintln(console, "✅ Everything looks good")
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// checkHookPrerequisites refuses to run hooks whose commands are missing,
+// rather than letting them fail halfway with "command not found"
+func checkHookPrerequisites(workDir string, cfg *config.Config) error {
+	missing := hooks.MissingPrerequisites(workDir, hooks.Prerequisites(workDir, upHookEvents, cfg.Hooks.Requires))
+	if len(missing) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(console, "⚠️  Hooks need commands that are not installed:")
+	printMissingPrerequisites(missing)
+
+	names := make([]string, len(missing))
+	for i, p := range missing {
+		names[i] = p.Command
+	}
+	return fmt.Errorf("missing hook prerequisites: %s; install them or pass --force to start anyway", strings.Join(names, ", "))
+}
+
+// printMissingPrerequisites prints each missing command with the hooks
+// needing it and how to install it
+func printMissingPrerequisites(missing []hooks.Prerequisite) {
+	for _, p := range missing {
+		fmt.Fprintf(console, "   ❌ %s (needed by %s)\n", p.Command, strings.Join(p.Hooks, ", "))
+		fmt.Fprintf(console, "      %s\n", hooks.InstallHint(p.Command))
+	}
+}
+
+// isMissing reports whether command is among the missing prerequisites
+func isMissing(missing []hooks.Prerequisite, command string) bool {
+	for _, p := range missing {
+		if p.Command == command {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestCheckHookPrerequisites(t *testing.T) {
+	workDir := t.TempDir()
+	cfg := config.Defaults()
+
+	if err := checkHookPrerequisites(workDir, cfg); err != nil {
+		t.Fatalf("no prerequisites: %v", err)
+	}
+
+	cfg.Hooks.Requires = []string{"sh", "space-test-missing-tool"}
+	err := checkHookPrerequisites(workDir, cfg)
+	if err == nil || !strings.Contains(err.Error(), "missing hook prerequisites: space-test-missing-tool;") {
+		t.Errorf("error = %v, want space-test-missing-tool reported", err)
+	}
+}
@@ -8,15 +8,37 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/happy-sdk/space-cli/internal/hooks"
 	"github.com/spf13/cobra"
 )
 
 func newDownCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "down",
+	var volumes bool
+	var keepDNS bool
+	var yes bool
+	var dry bool
+
+	cmd := &cobra.Command{
+		Use:   "down [services or groups...]",
 		Short: "Stop and remove services",
-		Long:  "Stop all running services and remove containers, networks, and volumes.",
+		Long: `Stop running services and remove their containers and networks.
+
+Without arguments the whole project is taken down. When services are given,
+only those are stopped and removed and the rest of the stack keeps running.
+Group names expand to their members; services they depend on are left
+running, since other services may share them.
+
+Volumes are kept unless --volumes is passed, which asks for confirmation
+first (skip it with --yes). Use --keep-dns to leave the DNS server running
+when you intend to bring the stack right back up. --dry-run shows the compose
+commands, hooks and cleanup without running them.`,
+		Example: `  space down
+  space down api worker
+  space down frontend
+  space down --volumes
+  space down --volumes --yes
+  space down --keep-dns
+  space down --volumes --dry-run`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
@@ -37,7 +59,7 @@ func newDownCommand() *cobra.Command {
 			}
 
 			// Create loader
-			loader, err := config.NewLoader(workDir)
+			loader, err := newConfigLoader(workDir)
 			if err != nil {
 				return fmt.Errorf("failed to create config loader: %w", err)
 			}
@@ -48,53 +70,181 @@ func newDownCommand() *cobra.Command {
 				return fmt.Errorf("failed to load configuration: %w", err)
 			}
 
-			fmt.Printf("🛑 Stopping services for project: %s\n", cfg.Project.Name)
-			fmt.Printf("📁 Working directory: %s\n", workDir)
-			fmt.Println()
+			// Groups expand to their members only
+			args, err = cfg.ExpandGroups(args)
+			if err != nil {
+				return err
+			}
+
+			bannerf("🛑 Stopping services for project: %s", cfg.Project.Name)
+			bannerf("📁 Working directory: %s\n", workDir)
 
 			// Generate project name
 			projectName := generateProjectName(cfg, workDir)
-			fmt.Printf("📦 Project name: %s\n", projectName)
-			fmt.Println()
+			fmt.Fprintf(console, "📦 Project name: %s\n", projectName)
+			fmt.Fprintln(console)
+
+			// Stopping some services leaves the rest of the stack, and its
+			// DNS records, in place
+			selective := len(args) > 0
+
+			if dry {
+				plan := startDryRun()
+				defer stopDryRun()
+				base := composeCommand(workDir, cfg, withGeneratedServices(workDir, composeFilesFor(workDir, cfg)), projectName)
+				printDownPlan(plan, workDir, cfg, projectName, base, args, volumes, keepDNS)
+				return nil
+			}
+
+			if volumes {
+				action := "delete the volumes"
+				if selective {
+					action = "delete the anonymous volumes of " + strings.Join(args, ", ")
+				}
+				if err := confirmDestructive(action, projectName, workDir, yes); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				fmt.Fprintln(console)
+			}
+			if selective {
+				fmt.Fprintf(console, "📋 Stopping services: %s\n", strings.Join(args, ", "))
+				fmt.Fprintln(console)
+			}
 
 			// Clean up DNS server if running
-			if globalDNSServer != nil && globalDNSServer.IsRunning() {
+			if keepDNS || selective {
+				if isDNSServerRunning() {
+					fmt.Fprintln(console, "🌐 Keeping DNS server running")
+					fmt.Fprintln(console)
+				}
+			} else if globalDNSServer != nil && globalDNSServer.IsRunning() {
 				cleanupDNSServer(ctx)
-				fmt.Println()
+				fmt.Fprintln(console)
 			}
 
-			// Build docker compose command
-			composeCmd := []string{"docker", "compose"}
+			// Run pre-down hooks
+			runScriptHooks(ctx, hooks.PreDown, workDir, projectName, cfg, isDNSServerRunning(), false)
 
-			// Add compose files
-			for _, file := range cfg.Project.ComposeFiles {
-				composeCmd = append(composeCmd, "-f", file)
+			// Build docker compose commands with the compose files, including
+			// detected and generated overrides
+			base := composeCommand(workDir, cfg, withGeneratedServices(workDir, composeFilesFor(workDir, cfg)), projectName)
+
+			for _, composeCmd := range downCommands(base, args, volumes) {
+				// Execute docker compose
+				name := "docker compose " + composeCmd[len(base)]
+				downCtx, cancel := context.WithTimeout(ctx, composeStopTimeout)
+				dockerCmd := exec.CommandContext(downCtx, composeCmd[0], composeCmd[1:]...)
+				dockerCmd.Dir = workDir
+				dockerCmd.Stdout = os.Stdout
+				dockerCmd.Stderr = os.Stderr
+				dockerCmd.Stdin = os.Stdin
+
+				fmt.Fprintf(console, "🔧 Running: %s\n", strings.Join(composeCmd, " "))
+				fmt.Fprintln(console)
+
+				err := step(name, true, dockerCmd.Run)
+				cancel()
+				if err != nil {
+					return fmt.Errorf("failed to stop services: %w", timeoutError(downCtx, name, composeStopTimeout, err))
+				}
 			}
 
-			// Add project name
-			composeCmd = append(composeCmd, "-p", projectName)
+			// The databases of this worktree on shared servers go with
+			// its volumes
+			if volumes && !selective {
+				project, _ := loadComposeProject(workDir, cfg)
+				dropSharedDatabases(ctx, cfg, project, workDir, projectName)
+			}
+
+			// Shared networks go with the last project on them
+			if !selective {
+				releaseSharedNetworks(ctx, cfg)
+			}
+
+			// Close the public tunnels of the stopped services
+			if _, err := stopTunnels(workDir, args); err != nil {
+				fmt.Fprintf(console, "⚠️  Failed to close tunnels: %v\n", err)
+			}
 
-			// Add down command
-			composeCmd = append(composeCmd, "down")
+			// Remove rendered secrets and generated compose files once nothing
+			// uses them (--keep-dns keeps the DNS compose file for reuse)
+			if !selective {
+				if err := removeSecrets(projectName); err != nil {
+					fmt.Fprintf(console, "⚠️  Failed to remove rendered secrets: %v\n", err)
+				}
+			}
+			if !selective && !keepDNS {
+				if _, err := removeGenerated(workDir, false); err != nil {
+					fmt.Fprintf(console, "⚠️  Failed to remove generated compose files: %v\n", err)
+				}
+			}
 
-			// Execute docker compose
-			dockerCmd := exec.Command(composeCmd[0], composeCmd[1:]...)
-			dockerCmd.Dir = workDir
-			dockerCmd.Stdout = os.Stdout
-			dockerCmd.Stderr = os.Stderr
-			dockerCmd.Stdin = os.Stdin
+			// Nothing is left for the agent to stop, or to drift from
+			if !selective {
+				releaseProjectTTL(projectName)
+				releaseDNSRecords(projectName)
+				if err := removeUpState(workDir); err != nil {
+					fmt.Fprintf(console, "⚠️  Failed to remove up state: %v\n", err)
+				}
+			}
 
-			fmt.Printf("🔧 Running: %s\n", strings.Join(composeCmd, " "))
-			fmt.Println()
+			// Drop the stopped containers from the hosts file block
+			if hostsMode(cfg) {
+				var err error
+				if selective {
+					err = syncHostsFile(ctx, cfg, workDir, projectName)
+				} else {
+					err = removeHostsFileBlock(ctx, projectName)
+				}
+				if err != nil {
+					fmt.Fprintf(console, "⚠️  Failed to update hosts file: %v\n", err)
+				}
+			}
 
-			if err := dockerCmd.Run(); err != nil {
-				return fmt.Errorf("failed to stop services: %w", err)
+			fmt.Fprintln(console)
+			fmt.Fprintln(console, "✅ Services stopped successfully!")
+			if keepDNS && !selective {
+				fmt.Fprintln(console, "💡 DNS server left running, 'space up' will reuse it")
 			}
 
-			fmt.Println()
-			fmt.Println("✅ Services stopped successfully!")
+			// Run post-down hooks
+			runScriptHooks(ctx, hooks.PostDown, workDir, projectName, cfg, isDNSServerRunning(), false)
 
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVarP(&volumes, "volumes", "v", false, "Also remove named and anonymous volumes")
+	cmd.Flags().BoolVar(&keepDNS, "keep-dns", false, "Leave the DNS server and its records running")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete volumes without asking for confirmation")
+	cmd.Flags().BoolVar(&dry, "dry-run", false, "Show the compose commands, hooks and cleanup without stopping anything")
+
+	return cmd
+}
+
+// downCommands returns the compose commands that take down the given
+// services, or the whole project when none are given. Selected services use
+// stop + rm, which every compose version supports, unlike down with services.
+func downCommands(base, services []string, volumes bool) [][]string {
+	command := func(args ...string) []string {
+		return append(append([]string{}, base...), args...)
+	}
+
+	if len(services) == 0 {
+		down := command("down")
+		if volumes {
+			down = append(down, "--volumes")
+		}
+		return [][]string{down}
+	}
+
+	rm := command("rm", "-f")
+	if volumes {
+		rm = append(rm, "-v")
+	}
+	return [][]string{
+		append(command("stop"), services...),
+		append(rm, services...),
+	}
 }
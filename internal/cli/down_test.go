@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDownCommands(t *testing.T) {
+	base := []string{"docker", "compose", "-p", "shop"}
+
+	tests := []struct {
+		name     string
+		services []string
+		volumes  bool
+		want     [][]string
+	}{
+		{
+			name: "whole project keeps volumes",
+			want: [][]string{{"docker", "compose", "-p", "shop", "down"}},
+		},
+		{
+			name:    "whole project with volumes",
+			volumes: true,
+			want:    [][]string{{"docker", "compose", "-p", "shop", "down", "--volumes"}},
+		},
+		{
+			name:     "selected services",
+			services: []string{"api", "worker"},
+			want: [][]string{
+				{"docker", "compose", "-p", "shop", "stop", "api", "worker"},
+				{"docker", "compose", "-p", "shop", "rm", "-f", "api", "worker"},
+			},
+		},
+		{
+			name:     "selected services with volumes",
+			services: []string{"api"},
+			volumes:  true,
+			want: [][]string{
+				{"docker", "compose", "-p", "shop", "stop", "api"},
+				{"docker", "compose", "-p", "shop", "rm", "-f", "-v", "api"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := downCommands(base, tt.services, tt.volumes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("downCommands() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmDestructive(t *testing.T) {
+	original := console
+	defer func() { console = original }()
+	var out bytes.Buffer
+	console = &out
+
+	if err := confirmDestructive("delete the volumes", "shop-main", "/src/shop", true); err != nil {
+		t.Errorf("confirmDestructive(yes) = %v", err)
+	}
+	if !strings.Contains(out.String(), "delete the volumes of project shop-main") || !strings.Contains(out.String(), "/src/shop") {
+		t.Errorf("output does not name the project and directory:\n%s", out.String())
+	}
+
+	if isInteractive() {
+		t.Skip("stdin is a terminal")
+	}
+	err := confirmDestructive("delete the volumes", "shop-main", "/src/shop", false)
+	if err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("confirmDestructive() without a terminal = %v, want a refusal pointing at --yes", err)
+	}
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"gopkg.in/yaml.v3"
+)
+
+// upStateFile records what the last 'space up' started
+const upStateFile = "up.json"
+
+// upState is the compose input of the last successful 'space up', used to
+// notice compose files edited while the stack runs
+type upState struct {
+	Time time.Time `json:"time"`
+	DNS  bool      `json:"dns"`
+
+	// ComposeFiles maps each compose file to the hash of its content
+	ComposeFiles map[string]string `json:"compose_files"`
+
+	// Services maps each service to the hash of its definition as started,
+	// after the DNS override was applied
+	Services map[string]string `json:"services"`
+}
+
+// newUpState records the compose files and the service definitions of project
+func newUpState(workDir string, files []string, project *compose.Project, useDNS bool) *upState {
+	return &upState{
+		Time:         time.Now(),
+		DNS:          useDNS,
+		ComposeFiles: fileHashes(workDir, files),
+		Services:     serviceHashes(project),
+	}
+}
+
+// fileHashes hashes the content of each file; missing files hash to ""
+func fileHashes(workDir string, files []string) map[string]string {
+	hashes := make(map[string]string, len(files))
+	for _, file := range files {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workDir, file)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			hashes[file] = ""
+			continue
+		}
+		hashes[file] = contentHash(data)
+	}
+	return hashes
+}
+
+// serviceHashes hashes the definition of every service in project
+func serviceHashes(project *compose.Project) map[string]string {
+	hashes := make(map[string]string)
+	if project == nil {
+		return hashes
+	}
+	for name, svc := range project.Services() {
+		// yaml.v3 sorts map keys, so equal definitions hash alike
+		data, err := yaml.Marshal(svc)
+		if err != nil {
+			continue
+		}
+		hashes[name] = contentHash(data)
+	}
+	return hashes
+}
+
+// saveUpState writes the state of the last 'space up'
+func saveUpState(workDir string, state *upState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(stateDir(workDir), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(stateDir(workDir), upStateFile), data, 0644)
+}
+
+// loadUpState reads the state of the last 'space up'
+func loadUpState(workDir string) (*upState, error) {
+	data, err := os.ReadFile(filepath.Join(stateDir(workDir), upStateFile))
+	if err != nil {
+		return nil, err
+	}
+	var state upState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// removeUpState forgets the last 'space up'
+func removeUpState(workDir string) error {
+	err := os.Remove(filepath.Join(stateDir(workDir), upStateFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// changedFiles returns the compose files that differ from the recorded
+// state: edited, added or removed
+func (s *upState) changedFiles(workDir string, files []string) []string {
+	current := fileHashes(workDir, files)
+
+	var changed []string
+	for file, hash := range current {
+		if recorded, ok := s.ComposeFiles[file]; !ok || recorded != hash {
+			changed = append(changed, file)
+		}
+	}
+	for file := range s.ComposeFiles {
+		if _, ok := current[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// changedServices returns the services whose definition differs between two
+// sets of hashes, and the services that no longer exist
+func changedServices(recorded, current map[string]string) (changed, removed []string) {
+	for name, hash := range current {
+		if recorded[name] != hash {
+			changed = append(changed, name)
+		}
+	}
+	for name := range recorded {
+		if _, ok := current[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return changed, removed
+}
+
+// warnComposeDrift tells the user when compose files changed since the last
+// 'space up', since the running containers and DNS override no longer match
+func warnComposeDrift(out io.Writer, workDir string, files []string) {
+	state, err := loadUpState(workDir)
+	if err != nil {
+		return
+	}
+	changed := state.changedFiles(workDir, files)
+	if len(changed) == 0 {
+		return
+	}
+
+	fmt.Fprintf(out, "⚠️  %s changed since 'space up' (%s ago)\n", strings.Join(changed, ", "), time.Since(state.Time).Round(time.Second))
+	if state.DNS {
+		fmt.Fprintln(out, "💡 Run 'space up --refresh-override' to regenerate the DNS override and apply the changes")
+	} else {
+		fmt.Fprintln(out, "💡 Run 'space up --refresh-override' to apply the changes")
+	}
+}
+
+// refreshDelta compares the service definitions of project with the last
+// 'space up' and returns the services to recreate and those removed
+func refreshDelta(workDir string, project *compose.Project) (changed, removed []string, err error) {
+	if project == nil {
+		return nil, nil, fmt.Errorf("--refresh-override needs the compose files to load")
+	}
+	state, err := loadUpState(workDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("no previous 'space up' recorded, run 'space up' first")
+	}
+
+	changed, removed = changedServices(state.Services, serviceHashes(project))
+	if len(changed) > 0 {
+		fmt.Fprintf(console, "🔁 Changed since last up: %s\n", strings.Join(changed, ", "))
+	}
+	return changed, removed, nil
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+)
+
+func TestChangedServices(t *testing.T) {
+	recorded := map[string]string{"api": "a", "db": "b", "cache": "c"}
+	current := map[string]string{"api": "a", "db": "x", "web": "w"}
+
+	changed, removed := changedServices(recorded, current)
+	if want := []string{"db", "web"}; !reflect.DeepEqual(changed, want) {
+		t.Errorf("changed = %v, want %v", changed, want)
+	}
+	if want := []string{"cache"}; !reflect.DeepEqual(removed, want) {
+		t.Errorf("removed = %v, want %v", removed, want)
+	}
+}
+
+func TestComposeDrift(t *testing.T) {
+	workDir := t.TempDir()
+	file := filepath.Join(workDir, "docker-compose.yml")
+	write := func(body string) {
+		if err := os.WriteFile(file, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	load := func() *compose.Project {
+		project, err := compose.Load(workDir, []string{"docker-compose.yml"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return project
+	}
+
+	write("services:\n  api:\n    image: api:1\n  db:\n    image: postgres:16\n")
+	files := []string{"docker-compose.yml"}
+	if err := saveUpState(workDir, newUpState(workDir, files, load(), true)); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	warnComposeDrift(&out, workDir, files)
+	if out.Len() != 0 {
+		t.Fatalf("unexpected warning for unchanged files: %s", out.String())
+	}
+
+	write("services:\n  api:\n    image: api:2\n  db:\n    image: postgres:16\n")
+	warnComposeDrift(&out, workDir, files)
+	if !strings.Contains(out.String(), "docker-compose.yml changed") || !strings.Contains(out.String(), "--refresh-override") {
+		t.Errorf("missing drift warning, got: %s", out.String())
+	}
+
+	changed, removed, err := refreshDelta(workDir, load())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(changed, []string{"api"}) || len(removed) != 0 {
+		t.Errorf("refreshDelta = %v, %v; want [api], []", changed, removed)
+	}
+
+	if err := removeUpState(workDir); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	warnComposeDrift(&out, workDir, files)
+	if out.Len() != 0 {
+		t.Errorf("warning without recorded state: %s", out.String())
+	}
+}
@@ -0,0 +1,342 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// dryRun collects what 'space up --dry-run' or 'space down --dry-run' would
+// do. While it is set, generated files are recorded in it instead of being
+// written.
+var dryRun *dryRunPlan
+
+// dryRunPlan is what a command would do
+type dryRunPlan struct {
+	files    []plannedFile
+	actions  []string
+	commands [][]string
+}
+
+// plannedFile is a file that would be written
+type plannedFile struct {
+	path    string
+	content []byte
+}
+
+// startDryRun begins recording a dry run and returns the plan
+func startDryRun() *dryRunPlan {
+	dryRun = &dryRunPlan{}
+	return dryRun
+}
+
+// stopDryRun ends the dry run, so files are written again
+func stopDryRun() {
+	dryRun = nil
+}
+
+// addFile records a file, replacing an earlier version of it
+func (p *dryRunPlan) addFile(path string, content []byte) {
+	for i, f := range p.files {
+		if f.path == path {
+			p.files[i].content = content
+			return
+		}
+	}
+	p.files = append(p.files, plannedFile{path: path, content: content})
+}
+
+// addAction records a step other than a compose command or a file
+func (p *dryRunPlan) addAction(format string, args ...interface{}) {
+	p.actions = append(p.actions, fmt.Sprintf(format, args...))
+}
+
+// addCommand records a command line
+func (p *dryRunPlan) addCommand(args []string) {
+	p.commands = append(p.commands, args)
+}
+
+// content returns a file as it would be: recorded in the plan, or on disk
+func (p *dryRunPlan) content(path string) ([]byte, error) {
+	for _, f := range p.files {
+		if f.path == path {
+			return f.content, nil
+		}
+	}
+	return os.ReadFile(path)
+}
+
+// writeFile writes a file space generates, creating its directory, or
+// records it during a dry run. Private files get a private directory.
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	if dryRun != nil {
+		dryRun.addFile(path, data)
+		return nil
+	}
+
+	dirPerm := os.FileMode(0755)
+	if perm&0077 == 0 {
+		dirPerm = 0700
+	}
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, perm)
+}
+
+// dryRunResolver stands in for the secret resolver during a dry run, so no
+// secret is fetched
+type dryRunResolver struct{}
+
+func (dryRunResolver) Resolve(ctx context.Context, name string, secret config.SecretConfig) (string, error) {
+	return "<secret " + name + ">", nil
+}
+
+// plannedHooks lists the hooks that run for an event, in order: the
+// built-in ones, those of .space/hooks.go and the scripts
+func plannedHooks(workDir string, cfg *config.Config, event hooks.EventType) []string {
+	var planned []string
+	if event == hooks.PostUp {
+		if m := cfg.Hooks.Messaging; m != nil && len(m.Topics) > 0 {
+			planned = append(planned, "messaging (built-in)")
+		}
+		if st := cfg.Hooks.Storage; st != nil && (len(st.Buckets) > 0 || len(st.Queues) > 0) {
+			planned = append(planned, "storage (built-in)")
+		}
+	}
+
+	goHooks, err := hooks.LoadGoHooks(workDir)
+	if err != nil {
+		planned = append(planned, fmt.Sprintf("⚠️  .space/%s does not load: %v", hooks.GoHooksFile, err))
+	}
+	for _, h := range goHooks {
+		for _, e := range h.Events() {
+			if e == event {
+				planned = append(planned, fmt.Sprintf("%s (.space/%s)", h.Name(), hooks.GoHooksFile))
+				break
+			}
+		}
+	}
+
+	scripts, err := hooks.NewScriptExecutor(workDir).Scripts(event)
+	if err != nil {
+		planned = append(planned, fmt.Sprintf("⚠️  %v", err))
+	}
+	for _, script := range scripts {
+		name, _ := filepath.Rel(workDir, script)
+		if meta, err := hooks.ReadScriptMeta(script); err == nil && meta.Service != "" {
+			name += " (in " + meta.Service + ")"
+		}
+		planned = append(planned, name)
+	}
+	return planned
+}
+
+// plannedProject merges the compose files of a compose command line as they
+// would be, with the files of the plan in place of those on disk
+func plannedProject(workDir string, composeCmd []string) (*compose.Project, error) {
+	p := &compose.Project{WorkDir: workDir, Data: make(map[string]interface{})}
+	for i := 0; i+1 < len(composeCmd); i++ {
+		if composeCmd[i] != "-f" {
+			continue
+		}
+		file := composeCmd[i+1]
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(workDir, file)
+		}
+		data, err := dryRun.content(file)
+		if os.IsNotExist(err) {
+			// Rendered secrets are not shown
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", composeCmd[i+1], err)
+		}
+		if err := p.Merge(doc); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// boundPorts lists the host ports the services of project publish
+func boundPorts(project *compose.Project, services []string) []string {
+	if len(services) == 0 {
+		services = project.ServiceNames()
+	}
+	sort.Strings(services)
+
+	var ports []string
+	for _, name := range services {
+		for _, m := range project.Ports(name) {
+			published := "random port"
+			if m.Published > 0 {
+				published = fmt.Sprintf("localhost:%d", m.Published)
+			}
+			ports = append(ports, fmt.Sprintf("%s: %s → %d/%s", name, published, m.Target, m.Protocol))
+		}
+	}
+	return ports
+}
+
+// print prints the plan and the sections the command adds to it
+func (p *dryRunPlan) print(sections []dryRunSection) {
+	fmt.Fprintln(console)
+	fmt.Fprintln(console, "🧪 Dry run: nothing was started, stopped, written or run")
+
+	if len(p.commands) > 0 {
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "🔧 Commands:")
+		for _, args := range p.commands {
+			fmt.Fprintf(console, "   %s\n", redact.String(strings.Join(args, " ")))
+		}
+	}
+
+	if len(p.files) > 0 {
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "📄 Generated files:")
+		for _, f := range p.files {
+			fmt.Fprintf(console, "   %s\n", f.path)
+			for _, line := range strings.Split(strings.TrimRight(string(f.content), "\n"), "\n") {
+				fmt.Fprintf(console, "   │ %s\n", redact.String(line))
+			}
+		}
+	}
+
+	for _, s := range sections {
+		if len(s.lines) == 0 {
+			continue
+		}
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, s.title)
+		for _, line := range s.lines {
+			fmt.Fprintf(console, "   %s\n", line)
+		}
+	}
+
+	if len(p.actions) > 0 {
+		fmt.Fprintln(console)
+		fmt.Fprintln(console, "⚙️  Also:")
+		for _, action := range p.actions {
+			fmt.Fprintf(console, "   • %s\n", action)
+		}
+	}
+}
+
+// dryRunSection is a titled list in the dry run output
+type dryRunSection struct {
+	title string
+	lines []string
+}
+
+// hookSection lists the hooks of the given events, in the order they run
+func hookSection(workDir string, cfg *config.Config, events ...hooks.EventType) dryRunSection {
+	s := dryRunSection{title: "🪝 Hooks, in order:"}
+	for _, event := range events {
+		for _, hook := range plannedHooks(workDir, cfg, event) {
+			s.lines = append(s.lines, fmt.Sprintf("%s: %s", event, hook))
+		}
+	}
+	return s
+}
+
+// dnsSection lists the hostnames of the services and the static records
+func dnsSection(cfg *config.Config, workDir string, services []string) dryRunSection {
+	s := dryRunSection{title: "🌐 DNS records:"}
+	if len(services) == 0 {
+		services = sortedServiceNames(cfg)
+	}
+	for _, name := range services {
+		s.lines = append(s.lines, fmt.Sprintf("%s → %s", dnsHostname(cfg, name, workDir), name))
+	}
+	for _, r := range dnsRecords(cfg, workDir) {
+		target := r.IP
+		if target == "" {
+			target = r.Target
+		}
+		s.lines = append(s.lines, fmt.Sprintf("%s → %s (static)", r.Name, target))
+	}
+	return s
+}
+
+// printUpPlan prints what 'space up' would do with the compose command line
+// it built
+func printUpPlan(plan *dryRunPlan, workDir string, cfg *config.Config, composeCmd, services []string, useDNS bool) {
+	sections := []dryRunSection{hookSection(workDir, cfg, hooks.PreUp, hooks.OnServiceHealthy, hooks.PostUp)}
+	if useDNS {
+		sections = append(sections, dnsSection(cfg, workDir, services))
+	}
+
+	ports := dryRunSection{title: "🔌 Host ports:"}
+	if project, err := plannedProject(workDir, composeCmd); err != nil {
+		ports.lines = []string{"⚠️  " + err.Error()}
+	} else if ports.lines = boundPorts(project, services); len(ports.lines) == 0 {
+		ports.lines = []string{"none"}
+	}
+	sections = append(sections, ports)
+
+	plan.print(sections)
+}
+
+// printDownPlan prints what 'space down' would do with the given compose
+// command base
+func printDownPlan(plan *dryRunPlan, workDir string, cfg *config.Config, projectName string, base, services []string, volumes, keepDNS bool) {
+	selective := len(services) > 0
+	for _, args := range downCommands(base, services, volumes) {
+		plan.addCommand(args)
+	}
+
+	if volumes && !selective {
+		project, _ := loadComposeProject(workDir, cfg)
+		dbs, _ := sharedDatabases(cfg, project, workDir, projectName)
+		for _, db := range dbs {
+			plan.addAction("drop database %s on shared server %s", db.Database, db.Container)
+		}
+	}
+	if !selective {
+		for _, network := range slices.Sorted(maps.Keys(sharedNetworks(cfg, nil))) {
+			plan.addAction("remove network %s if no other project is on it", sharedNetworkName(network))
+		}
+	}
+	if tunnels, err := runningTunnels(workDir); err == nil {
+		for _, t := range tunnels {
+			if !selective || containsString(services, t.Service) {
+				plan.addAction("close the tunnel of %s (%s)", t.Service, t.URL)
+			}
+		}
+	}
+	if !selective {
+		plan.addAction("remove the rendered secrets in %s", secretsPath(projectName))
+		if !keepDNS {
+			generated, _ := removeGenerated(workDir, true)
+			for _, path := range generated {
+				plan.addAction("remove %s", path)
+			}
+		}
+		plan.addAction("release the project's static DNS records and TTL in the agent")
+	}
+	if hostsMode(cfg) {
+		if selective {
+			plan.addAction("drop the stopped services from /etc/hosts")
+		} else {
+			plan.addAction("remove the project's block from /etc/hosts")
+		}
+	}
+
+	plan.print([]dryRunSection{hookSection(workDir, cfg, hooks.PreDown, hooks.PostDown)})
+}
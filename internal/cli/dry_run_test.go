@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/internal/statedir"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestDryRunRecordsFiles(t *testing.T) {
+	dir := t.TempDir()
+	plan := startDryRun()
+	defer stopDryRun()
+
+	generated := filepath.Join(dir, dnsComposeFile)
+	if _, err := writeGenerated(generated, "# Auto-generated\n", []byte("services: {}\n")); err != nil {
+		t.Fatal(err)
+	}
+	private := filepath.Join(dir, "secrets", "environment.yml")
+	if err := writeFile(private, []byte("services: {}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{generated, filepath.Dir(private)} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s was written during a dry run", path)
+		}
+	}
+	if len(plan.files) != 2 || !strings.Contains(string(plan.files[0].content), hashMarker) {
+		t.Errorf("plan files = %+v", plan.files)
+	}
+
+	// A file space didn't write is still refused
+	foreign := filepath.Join(dir, healthComposeFile)
+	if err := os.WriteFile(foreign, []byte("services: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writeGenerated(foreign, "# Auto-generated\n", []byte("services: {}\n")); err == nil {
+		t.Error("expected a foreign file to be refused")
+	}
+}
+
+func TestPlannedProjectPorts(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(`services:
+  web:
+    image: nginx
+    ports: ["8080:80"]
+  worker:
+    image: worker
+    ports: ["9000"]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := startDryRun()
+	defer stopDryRun()
+
+	project, err := plannedProject(dir, []string{"docker", "compose", "-f", "docker-compose.yml", "-p", "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"web: localhost:8080 → 80/tcp", "worker: random port → 9000/tcp"}
+	if got := boundPorts(project, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("boundPorts() = %q, want %q", got, want)
+	}
+
+	// The planned DNS override replaces the files and binds no ports
+	override := filepath.Join(dir, dnsComposeFile)
+	plan.addFile(override, []byte("services:\n  web:\n    image: nginx\n    expose: [\"80\"]\n"))
+	project, err = plannedProject(dir, []string{"docker", "compose", "-f", override})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := boundPorts(project, nil); len(got) != 0 {
+		t.Errorf("boundPorts() with the DNS override = %q", got)
+	}
+}
+
+func TestPlannedHooks(t *testing.T) {
+	dir := t.TempDir()
+	hooksDir := filepath.Join(dir, ".space", "hooks", "pre-up.d")
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, mode := range map[string]os.FileMode{"20-seed.sh": 0755, "10-migrate.sh": 0755, "README.md": 0644, "30-off.sh": 0644} {
+		if err := os.WriteFile(filepath.Join(hooksDir, name), []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{".space/hooks/pre-up.d/10-migrate.sh", ".space/hooks/pre-up.d/20-seed.sh"}
+	if got := plannedHooks(dir, &config.Config{}, hooks.PreUp); !reflect.DeepEqual(got, want) {
+		t.Errorf("plannedHooks() = %q, want %q", got, want)
+	}
+	if got := plannedHooks(dir, &config.Config{}, hooks.PostUp); len(got) != 0 {
+		t.Errorf("plannedHooks(post-up) = %q", got)
+	}
+}
+
+func TestDownPlanWritesNoState(t *testing.T) {
+	stateDir := filepath.Join(t.TempDir(), "state")
+	t.Setenv(statedir.EnvVar, stateDir)
+	originalConsole := console
+	defer func() { console = originalConsole }()
+	console = io.Discard
+
+	plan := startDryRun()
+	defer stopDryRun()
+	printDownPlan(plan, t.TempDir(), config.Defaults(), "shop", []string{"docker", "compose"}, nil, false, true)
+
+	if _, err := os.Stat(stateDir); !os.IsNotExist(err) {
+		t.Errorf("the dry run created %s", stateDir)
+	}
+	want := "remove the rendered secrets in " + filepath.Join(stateDir, "tmp", "secrets", "shop")
+	if !slices.Contains(plan.actions, want) {
+		t.Errorf("plan actions = %q, want %q", plan.actions, want)
+	}
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/secrets"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// secretPrefix marks a secret reference in environment values: ${secret:NAME}
+const secretPrefix = "secret:"
+
+// secretResolver resolves configured secrets
+type secretResolver interface {
+	Resolve(ctx context.Context, name string, secret config.SecretConfig) (string, error)
+}
+
+// expandEnvValue interpolates an environment value from .space.yml:
+// ${VAR} and $VAR read the host environment, ${secret:NAME} resolves a
+// configured secret and $$ is a literal dollar sign.
+func expandEnvValue(ctx context.Context, value string, cfg *config.Config, resolver secretResolver) (string, error) {
+	var expandErr error
+	expanded := os.Expand(value, func(key string) string {
+		if key == "$" {
+			return "$"
+		}
+		if name, ok := strings.CutPrefix(key, secretPrefix); ok {
+			secret, ok := cfg.Secrets[name]
+			if !ok {
+				expandErr = fmt.Errorf("secret %q is not configured", name)
+				return ""
+			}
+			resolved, err := resolver.Resolve(ctx, name, secret)
+			if err != nil && expandErr == nil {
+				expandErr = fmt.Errorf("failed to resolve secret %s: %w", name, err)
+			}
+			return resolved
+		}
+		return os.Getenv(key)
+	})
+	return expanded, expandErr
+}
+
+// hostTimezone returns the IANA name of the host timezone, or "" if unknown
+func hostTimezone() string {
+	if tz := os.Getenv("TZ"); tz != "" {
+		return strings.TrimPrefix(tz, ":")
+	}
+	target, err := os.Readlink("/etc/localtime")
+	if err != nil {
+		return ""
+	}
+	if _, zone, ok := strings.Cut(target, "zoneinfo/"); ok {
+		return zone
+	}
+	return ""
+}
+
+// clockEnvironment returns the TZ and locale variables for project.timezone
+// and project.locale
+func clockEnvironment(project config.ProjectConfig) map[string]string {
+	vars := make(map[string]string)
+
+	tz := project.Timezone
+	if tz == "host" {
+		tz = hostTimezone()
+	}
+	if tz != "" {
+		vars["TZ"] = tz
+	}
+
+	if project.Locale != "" {
+		vars["LANG"] = project.Locale
+		vars["LC_ALL"] = project.Locale
+	}
+	return vars
+}
+
+// localtimeMount returns the volume mounting the zone file of timezone as
+// /etc/localtime, or "" when the host can't provide it. Only Linux hosts are
+// considered, since Docker Desktop doesn't share system directories by default.
+func localtimeMount(goos, timezone string, exists func(string) bool) string {
+	if goos != "linux" || timezone == "" {
+		return ""
+	}
+
+	source := "/etc/localtime"
+	if timezone != "host" {
+		source = filepath.Join("/usr/share/zoneinfo", timezone)
+	}
+	if !exists(source) {
+		return ""
+	}
+	return source + ":/etc/localtime:ro"
+}
+
+// fileExists reports whether a path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// serviceEnvironment expands the environment of every configured service
+// that exists in the compose project. Clock and locale variables apply to
+// all services; per-service variables take precedence.
+func serviceEnvironment(ctx context.Context, cfg *config.Config, project *compose.Project, resolver secretResolver) (map[string]map[string]string, error) {
+	envs := make(map[string]map[string]string)
+
+	if clock := clockEnvironment(cfg.Project); len(clock) > 0 && project != nil {
+		for _, name := range project.ServiceNames() {
+			vars := make(map[string]string, len(clock))
+			for key, value := range clock {
+				vars[key] = value
+			}
+			envs[name] = vars
+		}
+	}
+
+	for _, name := range sortedServiceNames(cfg) {
+		svc := cfg.Services[name]
+		if len(svc.Environment) == 0 {
+			continue
+		}
+		if project != nil {
+			if _, ok := project.Services()[name]; !ok {
+				continue
+			}
+		}
+
+		vars := envs[name]
+		if vars == nil {
+			vars = make(map[string]string, len(svc.Environment))
+		}
+		for key, value := range svc.Environment {
+			expanded, err := expandEnvValue(ctx, value, cfg, resolver)
+			if err != nil {
+				return nil, fmt.Errorf("service %s, %s: %w", name, key, err)
+			}
+			vars[key] = expanded
+		}
+		envs[name] = vars
+	}
+	return envs, nil
+}
+
+// environmentOverride builds the compose services override setting the
+// environment configured in .space.yml (per-service variables, timezone and
+// locale). It returns the override and the services it covers.
+func environmentOverride(ctx context.Context, cfg *config.Config, project *compose.Project, resolver secretResolver) (map[string]interface{}, []string, error) {
+	envs, err := serviceEnvironment(ctx, cfg, project, resolver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Services mapped to the host user run as it and learn its ids
+	uid, gid := hostUser()
+	mapped := mappedUserServices(cfg, project)
+	for _, name := range mapped {
+		if envs[name] == nil {
+			envs[name] = make(map[string]string)
+		}
+		for key, value := range map[string]string{"HOST_UID": strconv.Itoa(uid), "HOST_GID": strconv.Itoa(gid)} {
+			if _, set := envs[name][key]; !set {
+				envs[name][key] = value
+			}
+		}
+	}
+
+	// The zone file is mounted into every service
+	mount := localtimeMount(runtime.GOOS, cfg.Project.Timezone, fileExists)
+	if mount != "" && project != nil {
+		for _, name := range project.ServiceNames() {
+			if envs[name] == nil {
+				envs[name] = make(map[string]string)
+			}
+		}
+	}
+
+	services := make(map[string]interface{}, len(envs))
+	names := make([]string, 0, len(envs))
+	for name, vars := range envs {
+		environment := make(map[string]string, len(vars))
+		for key, value := range vars {
+			// Keep compose from interpolating the expanded values again
+			environment[key] = strings.ReplaceAll(value, "$", "$$")
+		}
+		override := map[string]interface{}{"environment": environment}
+		if mount != "" {
+			override["volumes"] = []string{mount}
+		}
+		if slices.Contains(mapped, name) {
+			override["user"] = fmt.Sprintf("%d:%d", uid, gid)
+		}
+		services[name] = override
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return services, names, nil
+}
+
+// renderServiceEnvironment writes the environment configured in .space.yml
+// as a compose override. The file lives next to the rendered secrets,
+// outside the project, since values may contain secrets. It returns the
+// override path and the services it covers, or "" when none set variables.
+func renderServiceEnvironment(ctx context.Context, cfg *config.Config, project *compose.Project, projectName string) (string, []string, error) {
+	var resolver secretResolver = secrets.NewResolver()
+	if dryRun != nil {
+		resolver = dryRunResolver{}
+	}
+	services, names, err := environmentOverride(ctx, cfg, project, resolver)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(services) == 0 {
+		return "", nil, nil
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{"services": services})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal environment override: %w", err)
+	}
+
+	dir, err := secretsDir(projectName)
+	if err != nil {
+		return "", nil, err
+	}
+	overrideFile := filepath.Join(dir, "environment.yml")
+	header := "# Auto-generated service environment from .space.yml - do not commit\n\n"
+	if err := writeFile(overrideFile, []byte(header+string(data)), 0600); err != nil {
+		return "", nil, fmt.Errorf("failed to write environment override: %w", err)
+	}
+	return overrideFile, names, nil
+}
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// fakeSecrets resolves secrets from a fixed map
+type fakeSecrets map[string]string
+
+func (f fakeSecrets) Resolve(ctx context.Context, name string, secret config.SecretConfig) (string, error) {
+	value, ok := f[name]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return value, nil
+}
+
+func TestExpandEnvValue(t *testing.T) {
+	t.Setenv("SPACE_TEST_USER", "alice")
+
+	cfg := &config.Config{Secrets: map[string]config.SecretConfig{
+		"API_TOKEN": {Source: "keychain"},
+		"MISSING":   {Source: "keychain"},
+	}}
+	resolver := fakeSecrets{"API_TOKEN": "s3cret"}
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "on", want: "on"},
+		{value: "${SPACE_TEST_USER}", want: "alice"},
+		{value: "user=$SPACE_TEST_USER", want: "user=alice"},
+		{value: "${SPACE_TEST_UNSET}", want: ""},
+		{value: "Bearer ${secret:API_TOKEN}", want: "Bearer s3cret"},
+		{value: "price $$5", want: "price $5"},
+		{value: "${secret:UNKNOWN}", wantErr: true},
+		{value: "${secret:MISSING}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := expandEnvValue(context.Background(), tt.value, cfg, resolver)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandEnvValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("expandEnvValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceEnvironment(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"api":    {Environment: map[string]string{"FEATURE_FLAG": "on", "TOKEN": "${secret:TOKEN}"}},
+			"web":    {Port: 3000},
+			"ghost":  {Environment: map[string]string{"A": "b"}},
+			"worker": {Environment: map[string]string{"MODE": "batch"}},
+		},
+		Secrets: map[string]config.SecretConfig{"TOKEN": {}},
+	}
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api":    map[string]interface{}{},
+			"web":    map[string]interface{}{},
+			"worker": map[string]interface{}{},
+		},
+	}}
+
+	envs, err := serviceEnvironment(context.Background(), cfg, project, fakeSecrets{"TOKEN": "t0k"})
+	if err != nil {
+		t.Fatalf("serviceEnvironment() error = %v", err)
+	}
+
+	want := map[string]map[string]string{
+		"api":    {"FEATURE_FLAG": "on", "TOKEN": "t0k"},
+		"worker": {"MODE": "batch"},
+	}
+	if !reflect.DeepEqual(envs, want) {
+		t.Errorf("serviceEnvironment() = %v, want %v", envs, want)
+	}
+}
+
+func TestClockEnvironment(t *testing.T) {
+	got := clockEnvironment(config.ProjectConfig{Timezone: "Europe/Berlin", Locale: "de_DE.UTF-8"})
+	want := map[string]string{"TZ": "Europe/Berlin", "LANG": "de_DE.UTF-8", "LC_ALL": "de_DE.UTF-8"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clockEnvironment() = %v, want %v", got, want)
+	}
+
+	t.Setenv("TZ", ":America/New_York")
+	if got := clockEnvironment(config.ProjectConfig{Timezone: "host"}); got["TZ"] != "America/New_York" {
+		t.Errorf("host timezone = %q, want America/New_York", got["TZ"])
+	}
+
+	if got := clockEnvironment(config.ProjectConfig{}); len(got) != 0 {
+		t.Errorf("clockEnvironment() = %v, want empty", got)
+	}
+}
+
+func TestLocaltimeMount(t *testing.T) {
+	exists := func(path string) bool { return path != "/usr/share/zoneinfo/Mars/Olympus" }
+
+	tests := []struct {
+		goos     string
+		timezone string
+		want     string
+	}{
+		{goos: "linux", timezone: "UTC", want: "/usr/share/zoneinfo/UTC:/etc/localtime:ro"},
+		{goos: "linux", timezone: "host", want: "/etc/localtime:/etc/localtime:ro"},
+		{goos: "linux", timezone: "Mars/Olympus", want: ""},
+		{goos: "linux", timezone: "", want: ""},
+		{goos: "darwin", timezone: "UTC", want: ""},
+	}
+	for _, tt := range tests {
+		if got := localtimeMount(tt.goos, tt.timezone, exists); got != tt.want {
+			t.Errorf("localtimeMount(%s, %q) = %q, want %q", tt.goos, tt.timezone, got, tt.want)
+		}
+	}
+}
+
+func TestServiceEnvironmentClock(t *testing.T) {
+	cfg := &config.Config{
+		Project: config.ProjectConfig{Timezone: "UTC", Locale: "en_US.UTF-8"},
+		Services: map[string]config.ServiceConfig{
+			"api": {Environment: map[string]string{"TZ": "Asia/Tokyo"}},
+		},
+	}
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api": map[string]interface{}{},
+			"db":  map[string]interface{}{},
+		},
+	}}
+
+	envs, err := serviceEnvironment(context.Background(), cfg, project, fakeSecrets{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envs["api"]["TZ"] != "Asia/Tokyo" || envs["api"]["LANG"] != "en_US.UTF-8" {
+		t.Errorf("api environment = %v, want service TZ and project locale", envs["api"])
+	}
+	if envs["db"]["TZ"] != "UTC" {
+		t.Errorf("db environment = %v, want TZ=UTC", envs["db"])
+	}
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/events"
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/internal/statedir"
+	"github.com/spf13/cobra"
+)
+
+// newEventsCommand creates the events command
+func newEventsCommand() *cobra.Command {
+	var types []string
+	var project string
+
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Stream lifecycle events as JSON",
+		Long: `Stream environment lifecycle events from the space daemon, one JSON object
+per line, until interrupted.
+
+Events use the hook event names (pre-up, post-up, pre-down, post-down,
+on-dns-ready, on-service-start, on-service-stop). External tools can also
+connect to the control socket directly and send:
+
+  {"op":"subscribe","filter":{"types":["post-up"],"project":"myapp-main"}}`,
+		Example: `  space events
+  space events --type on-service-start --type on-service-stop
+  space events --project myapp-main | jq .`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			for _, t := range types {
+				if !hooks.EventType(t).IsValid() {
+					return fmt.Errorf("unknown event type %q", t)
+				}
+			}
+
+			stream, err := events.Subscribe(ctx, events.SocketPath(), events.Filter{Types: types, Project: project})
+			if err != nil {
+				return fmt.Errorf("failed to connect to the space daemon (is it running? try 'space dns start'): %w", err)
+			}
+
+			encoder := json.NewEncoder(os.Stdout)
+			for e := range stream {
+				if err := encoder.Encode(e); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&types, "type", "t", nil, "Only stream events of this type (repeatable)")
+	cmd.Flags().StringVarP(&project, "project", "p", "", "Only stream events of this compose project")
+
+	return cmd
+}
+
+// publishEvent sends a lifecycle event to the daemon. It is best effort:
+// nothing happens when no daemon is listening.
+func publishEvent(event hooks.EventType, projectName, workDir string, data map[string]interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	_ = events.Publish(ctx, events.SocketPath(), events.Event{
+		Type:    string(event),
+		Project: projectName,
+		WorkDir: workDir,
+		Data:    data,
+	})
+}
+
+// startControlServer serves the event bus and the given ops on the control
+// socket. It runs until ctx is done.
+func startControlServer(ctx context.Context, handlers map[string]events.Handler) (*events.Bus, error) {
+	bus := events.NewBus()
+
+	if err := statedir.Ensure(); err != nil {
+		return nil, err
+	}
+	server, err := events.Listen(events.SocketPath(), bus)
+	if err != nil {
+		return nil, err
+	}
+	for op, h := range handlers {
+		server.Handle(op, h)
+	}
+
+	go func() {
+		defer server.Close()
+		if err := server.Serve(ctx); err != nil {
+			fmt.Fprintf(console, "⚠️  Control socket stopped: %v\n", err)
+		}
+	}()
+
+	return bus, nil
+}
@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// hostExplanation is how space would resolve a hostname, worked out from
+// the running containers without asking the DNS daemon
+type hostExplanation struct {
+	Hostname string
+
+	// Domain is the served domain the hostname is under, "" when the daemon
+	// forwards it upstream
+	Domain string
+
+	// Service and Hash are the parts of the service label; Hash is "" for
+	// names without a directory hash
+	Service string
+	Hash    string
+
+	// WorkDirs are the working directories of running containers whose
+	// hash is Hash
+	WorkDirs []string
+
+	Container *dns.Container
+
+	// Problem says why the hostname doesn't resolve to a container
+	Problem string
+}
+
+// explainHostname matches hostname against the served domains and the
+// running containers the way the DNS daemon does. Names without a hash are
+// looked up in project, the daemon's project, when it is set.
+func explainHostname(hostname string, domains []string, project string, containers []dns.Container) hostExplanation {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	e := hostExplanation{Hostname: hostname}
+
+	for _, domain := range domains {
+		if strings.HasSuffix(hostname, "."+domain) {
+			e.Domain = domain
+			break
+		}
+	}
+	if e.Domain == "" {
+		e.Problem = fmt.Sprintf("%s is not under %s, so the daemon forwards it upstream", hostname, strings.Join(domains, " or "))
+		return e
+	}
+
+	label, ok := dns.ServiceHost(hostname, e.Domain)
+	if !ok {
+		e.Problem = fmt.Sprintf("%s is not a service hostname: it needs a single label of letters, digits and hyphens in front of %s", hostname, e.Domain)
+		return e
+	}
+
+	candidates := containers
+	if dns.ValidateHashedDomain(hostname, e.Domain) {
+		e.Service = dns.ExtractServiceNameFromHashedDomain(hostname, e.Domain)
+		e.Hash = dns.ExtractHashFromHashedDomain(hostname, e.Domain)
+
+		candidates = nil
+		for _, container := range containers {
+			if container.WorkDir == "" || dns.GenerateDirectoryHash(container.WorkDir) != e.Hash {
+				continue
+			}
+			candidates = append(candidates, container)
+			if !containsString(e.WorkDirs, container.WorkDir) {
+				e.WorkDirs = append(e.WorkDirs, container.WorkDir)
+			}
+		}
+		if len(candidates) == 0 {
+			e.Problem = fmt.Sprintf("no running container has a working directory hashing to %s", e.Hash)
+			return e
+		}
+	} else {
+		e.Service = label
+		if project != "" {
+			candidates = nil
+			for _, container := range containers {
+				if container.Project == project {
+					candidates = append(candidates, container)
+				}
+			}
+		}
+	}
+
+	if match, ok := dns.FindByLabel(candidates, e.Service); ok {
+		e.Container = &match
+		return e
+	}
+	if e.Hash == "" {
+		for _, container := range candidates {
+			if container.Name == e.Service {
+				e.Container = &container
+				return e
+			}
+		}
+	}
+
+	if e.Hash != "" {
+		e.Problem = fmt.Sprintf("no container of service %s runs in %s", e.Service, strings.Join(e.WorkDirs, ", "))
+	} else if project != "" {
+		e.Problem = fmt.Sprintf("no container of project %s answers to %s", project, e.Service)
+	} else {
+		e.Problem = fmt.Sprintf("no running container answers to %s", e.Service)
+	}
+	return e
+}
+
+// stoppedWorktrees returns the project directories in the git worktrees of
+// workDir whose directory hash is hash, for hashes no running container has
+func stoppedWorktrees(workDir, hash string) []string {
+	root, err := runGit(workDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	list, err := runGit(root, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil
+	}
+	rel, err := filepath.Rel(root, workDir)
+	if err != nil {
+		return nil
+	}
+
+	var dirs []string
+	for _, worktree := range parseWorktreeList(list) {
+		dir := filepath.Join(worktree.Path, rel)
+		if dns.GenerateDirectoryHash(dir) == hash {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func newExplainCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain <hostname>",
+		Short: "Explain how space resolves a hostname",
+		Long: `Explain how space would resolve a hostname: the served domain it falls
+under, the worktree its directory hash maps to (from the working_dir labels
+of the running containers), the container answering to it, that container's
+IP, and whether the system resolver routes the name to the DNS daemon.
+
+Unlike 'space dns trace', this works out the answer from docker directly,
+so it also explains names while the daemon is down.
+
+A bare service name (without dots) is expanded to its hostname in the
+current project.`,
+		Example: `  space explain api
+  space explain api-6a9c8c.space.local`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			hostname := args[0]
+			if !strings.Contains(hostname, ".") {
+				hostname = serviceHostname(hostname)
+			}
+
+			workDir, err := filepath.Abs(Workdir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+			var cfg *config.Config
+			if loader, err := newConfigLoader(workDir); err == nil {
+				cfg, _ = loader.Load()
+			}
+
+			// The daemon's domains win; without it, those the project would
+			// have it serve
+			state, stateErr := loadDNSState()
+			var domains []string
+			project := ""
+			switch {
+			case stateErr == nil:
+				domains, project = state.BaseDomains(), state.ProjectName
+			case cfg != nil:
+				domains = cfg.Network.BaseDomains()
+			default:
+				domains = []string{config.DefaultBaseDomain}
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			defer cancel()
+
+			out, err := exec.CommandContext(ctx, "docker", "ps", "--format", dns.PsFormat).Output()
+			if err != nil {
+				return fmt.Errorf("failed to list containers: %w", err)
+			}
+			e := explainHostname(hostname, domains, project, dns.ParseContainers(string(out)))
+
+			fmt.Fprintf(console, "🔎 Explaining %s\n\n", e.Hostname)
+			if e.Domain == "" {
+				fmt.Fprintf(console, "   Domain:    none served by space\n")
+				fmt.Fprintln(console)
+				fmt.Fprintf(console, "ℹ️  %s\n", e.Problem)
+				return nil
+			}
+			fmt.Fprintf(console, "   Domain:    %s\n", e.Domain)
+			if e.Service != "" {
+				fmt.Fprintf(console, "   Service:   %s\n", e.Service)
+			}
+			if e.Hash != "" {
+				fmt.Fprintf(console, "   Hash:      %s\n", e.Hash)
+				for _, dir := range e.WorkDirs {
+					fmt.Fprintf(console, "   Worktree:  %s\n", dir)
+				}
+				if len(e.WorkDirs) == 0 {
+					for _, dir := range stoppedWorktrees(workDir, e.Hash) {
+						fmt.Fprintf(console, "   Worktree:  %s (not running)\n", dir)
+					}
+				}
+			}
+
+			ip := ""
+			if e.Container != nil {
+				fmt.Fprintf(console, "   Container: %s (project %s)\n", e.Container.Name, e.Container.Project)
+				ips, err := dns.InspectContainerIPs(ctx, []string{e.Container.Name})
+				switch {
+				case err != nil:
+					e.Problem = fmt.Sprintf("failed to inspect %s: %v", e.Container.Name, err)
+				case ips[e.Container.Name] == "":
+					e.Problem = fmt.Sprintf("%s has no IP address on any network", e.Container.Name)
+				default:
+					ip = ips[e.Container.Name]
+					fmt.Fprintf(console, "   IP:        %s\n", ip)
+				}
+			}
+
+			routed := explainRouting(cfg, state, stateErr, e.Domain)
+
+			fmt.Fprintln(console)
+			if e.Problem != "" {
+				fmt.Fprintf(console, "❌ %s does not resolve: %s\n", e.Hostname, e.Problem)
+				return nil
+			}
+			if !routed {
+				fmt.Fprintf(console, "⚠️  %s would resolve to %s, but lookups don't reach the DNS daemon\n", e.Hostname, ip)
+				return nil
+			}
+			fmt.Fprintf(console, "✅ %s → %s\n", e.Hostname, ip)
+			return nil
+		},
+	}
+}
+
+// explainRouting prints how lookups of names under domain reach the DNS
+// daemon and reports whether they do
+func explainRouting(cfg *config.Config, state *DNSState, stateErr error, domain string) bool {
+	if hostsMode(cfg) {
+		fmt.Fprintf(console, "   Resolver:  /etc/hosts (dns.mode: hosts)\n")
+		return true
+	}
+	if stateErr != nil {
+		fmt.Fprintf(console, "   Daemon:    not running, start it with 'space dns start'\n")
+		return false
+	}
+	fmt.Fprintf(console, "   Daemon:    %s\n", state.Address)
+
+	if runtime.GOOS != "darwin" {
+		fmt.Fprintf(console, "   Resolver:  not managed on %s, point *.%s at %s yourself\n", runtime.GOOS, domain, state.Address)
+		return true
+	}
+	fmt.Fprintf(console, "   Resolver:  %s\n", resolverSummary(domain, state.Address))
+	status, err := dns.NewResolverManager(domain, state.Address, dns.NewStdLogger()).Verify()
+	return err == nil && status.OK()
+}
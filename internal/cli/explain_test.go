@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+)
+
+func TestExplainHostname(t *testing.T) {
+	main, feature := "/work/shop", "/work/shop-feature"
+	containers := []dns.Container{
+		{Name: "shop-api-1", Service: "api", Project: "shop", WorkDir: main, Number: 1},
+		{Name: "shop-feature-api-1", Service: "api", Project: "shop-feature", WorkDir: feature, Number: 1},
+		{Name: "shop-feature-web-1", Service: "web", Project: "shop-feature", WorkDir: feature, Number: 1},
+		{Name: "redis"},
+	}
+	domains := []string{"space.local", "test"}
+	featureHash := dns.GenerateDirectoryHash(feature)
+
+	tests := []struct {
+		name      string
+		hostname  string
+		project   string
+		container string
+		domain    string
+		problem   string
+	}{
+		{
+			name:      "hashed name picks the worktree",
+			hostname:  "api-" + featureHash + ".space.local",
+			container: "shop-feature-api-1",
+			domain:    "space.local",
+		},
+		{
+			name:      "second domain and trailing dot",
+			hostname:  "WEB-" + featureHash + ".test.",
+			container: "shop-feature-web-1",
+			domain:    "test",
+		},
+		{
+			name:     "hash of no running worktree",
+			hostname: "api-000000.space.local",
+			domain:   "space.local",
+			problem:  "hashing to 000000",
+		},
+		{
+			name:     "service missing from the worktree",
+			hostname: "db-" + featureHash + ".space.local",
+			domain:   "space.local",
+			problem:  "no container of service db runs in " + feature,
+		},
+		{
+			name:      "unhashed name in the daemon's project",
+			hostname:  "api.space.local",
+			project:   "shop",
+			container: "shop-api-1",
+			domain:    "space.local",
+		},
+		{
+			name:      "unhashed container name",
+			hostname:  "redis.space.local",
+			container: "redis",
+			domain:    "space.local",
+		},
+		{
+			name:     "unhashed name outside the daemon's project",
+			hostname: "web.space.local",
+			project:  "shop",
+			domain:   "space.local",
+			problem:  "no container of project shop",
+		},
+		{
+			name:     "forwarded upstream",
+			hostname: "github.com",
+			problem:  "forwards it upstream",
+		},
+		{
+			name:     "nested label",
+			hostname: "a.api.space.local",
+			domain:   "space.local",
+			problem:  "not a service hostname",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := explainHostname(tt.hostname, domains, tt.project, containers)
+			if e.Domain != tt.domain {
+				t.Errorf("domain = %q, want %q", e.Domain, tt.domain)
+			}
+
+			name := ""
+			if e.Container != nil {
+				name = e.Container.Name
+			}
+			if name != tt.container {
+				t.Errorf("container = %q, want %q", name, tt.container)
+			}
+
+			if tt.problem == "" && e.Problem != "" {
+				t.Errorf("unexpected problem: %s", e.Problem)
+			}
+			if !strings.Contains(e.Problem, tt.problem) {
+				t.Errorf("problem = %q, want it to mention %q", e.Problem, tt.problem)
+			}
+		})
+	}
+}
+
+func TestExplainHostnameWorkDirs(t *testing.T) {
+	feature := "/work/shop-feature"
+	containers := []dns.Container{
+		{Name: "shop-feature-api-1", Service: "api", Project: "shop-feature", WorkDir: feature, Number: 1},
+		{Name: "shop-feature-web-1", Service: "web", Project: "shop-feature", WorkDir: feature, Number: 1},
+	}
+
+	e := explainHostname("web-"+dns.GenerateDirectoryHash(feature)+".space.local", []string{"space.local"}, "", containers)
+	if len(e.WorkDirs) != 1 || e.WorkDirs[0] != feature {
+		t.Errorf("work dirs = %v, want [%s]", e.WorkDirs, feature)
+	}
+	if e.Service != "web" {
+		t.Errorf("service = %q, want web", e.Service)
+	}
+}
@@ -0,0 +1,523 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// forwardStateFile is the file in the state directory describing the
+// running forward, so a detached one can be listed and stopped
+const forwardStateFile = "forward.json"
+
+// Delays between reconnects of a dropped tunnel. A connection that stayed up
+// for forwardStableAfter resets the delay.
+const (
+	forwardMinBackoff  = time.Second
+	forwardMaxBackoff  = 30 * time.Second
+	forwardStableAfter = time.Minute
+)
+
+// ForwardTunnel is one service forwarded through the SSH connection
+type ForwardTunnel struct {
+	Service string `json:"service"`
+
+	// ListenPort is the port opened on the side the service is exposed to:
+	// the remote machine with --to, this machine with --from
+	ListenPort int `json:"listen_port"`
+
+	// Host and Port are where the service is reached on its own side
+	Host string `json:"host"`
+	Port int    `json:"port"`
+
+	// URL is the address of the service where it is exposed
+	URL string `json:"url"`
+}
+
+// ForwardState describes a running forward
+type ForwardState struct {
+	PID       int             `json:"pid"`
+	Target    string          `json:"target"`
+	Reverse   bool            `json:"reverse"`
+	StartTime time.Time       `json:"start_time"`
+	Tunnels   []ForwardTunnel `json:"tunnels"`
+}
+
+// forwardOptions are the flags of space forward
+type forwardOptions struct {
+	to       string
+	from     string
+	port     int
+	bindAll  bool
+	detach   bool
+	identity string
+
+	// batch stops ssh from prompting, when no one could answer
+	batch bool
+}
+
+// sshCommand builds the ssh process of a forward; a variable so tests can
+// replace it
+var sshCommand = func(ctx context.Context, args []string) *exec.Cmd {
+	return exec.CommandContext(ctx, "ssh", args...)
+}
+
+// newForwardCommand creates the forward command
+func newForwardCommand() *cobra.Command {
+	var opts forwardOptions
+
+	cmd := &cobra.Command{
+		Use:   "forward [services...] (--to user@host | --from user@host)",
+		Short: "Share services with a remote machine over SSH tunnels",
+		Long: `Forward service ports over SSH, so the stack can be used from a machine
+that cannot reach this one directly, such as a teammate's laptop, a demo box
+or a cloud VM.
+
+With --to, services of the local stack are exposed on the remote machine
+through reverse tunnels (ssh -R): http://localhost:<port> there reaches the
+service here. With --from, the services of a stack running on the remote
+machine are brought to localhost here (ssh -L).
+
+Services default to every service with a port; groups are expanded. Each
+tunnel listens on the service's port unless --port sets the first port of a
+range. The tunnels are reopened with backoff when the connection drops, until
+Ctrl+C, or until 'space forward stop' when started with --detach.
+
+Authentication uses your SSH agent and ~/.ssh/config; detached forwards
+cannot prompt for passwords or host keys.`,
+		Example: `  space forward --to demo@vm.example.com          # expose all services on the VM
+  space forward web api --to dev@build-box         # only web and api
+  space forward db --from dev@build-box --port 15432 # the remote database on localhost:15432
+  space forward --to demo@vm --detach               # keep forwarding in the background
+  space forward stop`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runForward(cmd, args, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.to, "to", "", "expose local services on this SSH destination (user@host)")
+	cmd.Flags().StringVar(&opts.from, "from", "", "bring the services of this SSH destination (user@host) to localhost")
+	cmd.Flags().IntVar(&opts.port, "port", 0, "first port of the range the tunnels listen on (default: each service's port)")
+	cmd.Flags().BoolVar(&opts.bindAll, "bind-all", false, "listen on all interfaces instead of localhost (with --to the server needs GatewayPorts clientspecified)")
+	cmd.Flags().BoolVarP(&opts.detach, "detach", "d", false, "keep forwarding in the background")
+	cmd.Flags().StringVarP(&opts.identity, "identity", "i", "", "SSH private key to authenticate with")
+	cmd.MarkFlagsMutuallyExclusive("to", "from")
+
+	cmd.AddCommand(newForwardStatusCommand())
+	cmd.AddCommand(newForwardStopCommand())
+
+	return cmd
+}
+
+// runForward plans the tunnels and runs them in the foreground or detached
+func runForward(cmd *cobra.Command, args []string, opts forwardOptions) error {
+	target, reverse := opts.to, true
+	if opts.from != "" {
+		target, reverse = opts.from, false
+	}
+	if target == "" {
+		return fmt.Errorf("set the remote machine with --to user@host or --from user@host")
+	}
+
+	c, err := loadServiceCommandContext()
+	if err != nil {
+		return err
+	}
+
+	if state, err := loadForwardState(c.workDir); err == nil && processRunning(state.PID) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("a forward to %s is already running (pid %d); stop it with 'space forward stop'", state.Target, state.PID)
+	}
+
+	services, err := c.cfg.ExpandGroups(args)
+	if err != nil {
+		return err
+	}
+	if err := checkServiceNames(c.cfg, services, c.services); err != nil {
+		return err
+	}
+
+	tunnels, err := planForwardTunnels(c.cfg, c.workDir, c.projectName, services, target, reverse, opts)
+	if err != nil {
+		return err
+	}
+
+	opts.batch = !stdinIsTerminal()
+	if opts.detach {
+		return detachForward(c.workDir, args, target, reverse, opts, tunnels)
+	}
+
+	state := &ForwardState{
+		PID:       os.Getpid(),
+		Target:    target,
+		Reverse:   reverse,
+		StartTime: time.Now(),
+		Tunnels:   tunnels,
+	}
+	if err := saveForwardState(c.workDir, state); err != nil {
+		return err
+	}
+	defer removeForwardState(c.workDir, state.PID)
+
+	printForwardTunnels(state)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cmd.SilenceUsage = true
+	return superviseForward(ctx, sshForwardArgs(target, reverse, tunnels, opts))
+}
+
+// planForwardTunnels picks the services to forward and the ports their
+// tunnels listen on
+func planForwardTunnels(cfg *config.Config, workDir, projectName string, services []string, target string, reverse bool, opts forwardOptions) ([]ForwardTunnel, error) {
+	explicit := len(services) > 0
+	if !explicit {
+		services = sortedServiceNames(cfg)
+	}
+
+	useDNS := reverse && serviceDNSActive(cfg, workDir)
+	exposedHost := "localhost"
+	if opts.bindAll && reverse {
+		exposedHost = sshHost(target)
+	}
+
+	var tunnels []ForwardTunnel
+	for _, name := range services {
+		svc := cfg.Services[name]
+		if svc.Port == 0 && svc.ExternalPort == 0 {
+			if explicit {
+				return nil, fmt.Errorf("service %q has no port to forward", name)
+			}
+			continue
+		}
+
+		// Each side reaches the stack as space up publishes it there
+		host, port := serviceAddress(cfg, name, workDir, useDNS)
+
+		listen := port
+		if opts.port > 0 {
+			listen = opts.port + len(tunnels)
+		}
+
+		tunnels = append(tunnels, ForwardTunnel{
+			Service:    name,
+			ListenPort: listen,
+			Host:       host,
+			Port:       port,
+			URL:        renderServiceURL(cfg, name, projectName, exposedHost, listen),
+		})
+	}
+
+	if len(tunnels) == 0 {
+		return nil, fmt.Errorf("no services with ports to forward")
+	}
+	return tunnels, nil
+}
+
+// sshForwardArgs returns the ssh arguments opening every tunnel over one
+// connection. With --to, a service reached through the DNS name of the
+// daemon is resolved here, since ssh -R connects from this machine.
+func sshForwardArgs(target string, reverse bool, tunnels []ForwardTunnel, opts forwardOptions) []string {
+	args := []string{
+		"-N",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "ServerAliveInterval=15",
+		"-o", "ServerAliveCountMax=3",
+	}
+	if opts.batch {
+		args = append(args, "-o", "BatchMode=yes")
+	}
+	if opts.identity != "" {
+		args = append(args, "-i", opts.identity)
+	}
+
+	bind := ""
+	if opts.bindAll {
+		bind = "0.0.0.0:"
+	}
+
+	for _, t := range tunnels {
+		if reverse {
+			args = append(args, "-R", fmt.Sprintf("%s%d:%s:%d", bind, t.ListenPort, t.Host, t.Port))
+		} else {
+			args = append(args, "-L", fmt.Sprintf("%s%d:%s:%d", bind, t.ListenPort, t.Host, t.Port))
+		}
+	}
+
+	return append(args, target)
+}
+
+// superviseForward runs ssh until ctx is canceled, reconnecting with
+// backoff whenever the connection drops
+func superviseForward(ctx context.Context, args []string) error {
+	backoff := forwardMinBackoff
+	for {
+		started := time.Now()
+		sshCmd := sshCommand(ctx, args)
+		sshCmd.Stdin = os.Stdin
+		sshCmd.Stdout = os.Stdout
+		sshCmd.Stderr = os.Stderr
+
+		err := sshCmd.Run()
+		if ctx.Err() != nil {
+			fmt.Fprintln(console, "\n🛑 Forward stopped")
+			return nil
+		}
+		var exitErr *exec.ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			return fmt.Errorf("failed to run ssh: %w", err)
+		}
+
+		if time.Since(started) >= forwardStableAfter {
+			backoff = forwardMinBackoff
+		}
+		if err != nil {
+			fmt.Fprintf(consoleErr, "⚠️  Tunnel closed: %v; reconnecting in %s\n", err, backoff)
+		} else {
+			fmt.Fprintf(consoleErr, "⚠️  Tunnel closed; reconnecting in %s\n", backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(console, "🛑 Forward stopped")
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, forwardMaxBackoff)
+	}
+}
+
+// detachForward starts space forward again as a background process writing
+// to a log file in the state directory
+func detachForward(workDir string, services []string, target string, reverse bool, opts forwardOptions, tunnels []ForwardTunnel) error {
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	if err := os.MkdirAll(stateDir(workDir), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	logPath := filepath.Join(stateDir(workDir), "forward.log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create log file: %w", err)
+	}
+	defer logFile.Close()
+
+	args := []string{"--workdir", workDir}
+	if ConfigFile != "" && ConfigFile != "-" {
+		args = append(args, "--config", ConfigFile)
+	}
+	args = append(args, "forward")
+	if reverse {
+		args = append(args, "--to", target)
+	} else {
+		args = append(args, "--from", target)
+	}
+	if opts.port > 0 {
+		args = append(args, "--port", strconv.Itoa(opts.port))
+	}
+	if opts.bindAll {
+		args = append(args, "--bind-all")
+	}
+	if opts.identity != "" {
+		args = append(args, "--identity", opts.identity)
+	}
+	args = append(args, services...)
+
+	child := exec.Command(execPath, args...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.Stdin = nil
+	child.Env = append(os.Environ(), "NO_COLOR=1")
+
+	// Set process group to detach from parent
+	child.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("failed to start forward: %w", err)
+	}
+
+	printForwardTunnels(&ForwardState{PID: child.Process.Pid, Target: target, Reverse: reverse, Tunnels: tunnels})
+	fmt.Fprintf(console, "   Log: %s\n", logPath)
+	tipf("Stop it with 'space forward stop'")
+	return nil
+}
+
+// printForwardTunnels lists the tunnels of a forward and their URLs
+func printForwardTunnels(state *ForwardState) {
+	if state.Reverse {
+		fmt.Fprintf(console, "🔗 Forwarding to %s (pid %d)\n", state.Target, state.PID)
+	} else {
+		fmt.Fprintf(console, "🔗 Forwarding from %s (pid %d)\n", state.Target, state.PID)
+	}
+
+	w := newTable(console)
+	for _, t := range state.Tunnels {
+		if state.Reverse {
+			fmt.Fprintf(w, "   %s\t%s\t← %s:%d\n", t.Service, t.URL, t.Host, t.Port)
+		} else {
+			fmt.Fprintf(w, "   %s\t%s\t→ %s:%d\n", t.Service, t.URL, t.Host, t.Port)
+		}
+	}
+	w.Flush()
+
+	if state.Reverse {
+		fmt.Fprintf(console, "   URLs are reachable on %s\n", sshHost(state.Target))
+	}
+}
+
+// newForwardStatusCommand creates the forward status command
+func newForwardStatusCommand() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the running forward and its tunnels",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			state, err := loadForwardState(c.workDir)
+			if err == nil && !processRunning(state.PID) {
+				removeForwardState(c.workDir, state.PID)
+				state, err = nil, os.ErrNotExist
+			}
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(state)
+			}
+
+			if state == nil {
+				fmt.Fprintln(console, "No forward is running.")
+				tipf("Start one with 'space forward --to user@host'")
+				return nil
+			}
+			printForwardTunnels(state)
+			fmt.Fprintf(console, "   Running for %s\n", time.Since(state.StartTime).Round(time.Second))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+
+	return cmd
+}
+
+// newForwardStopCommand creates the forward stop command
+func newForwardStopCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the running forward",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			state, err := loadForwardState(c.workDir)
+			if errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintln(console, "No forward is running.")
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			if processRunning(state.PID) {
+				if err := syscall.Kill(state.PID, syscall.SIGTERM); err != nil {
+					return fmt.Errorf("failed to stop forward (pid %d): %w", state.PID, err)
+				}
+			}
+			removeForwardState(c.workDir, state.PID)
+
+			fmt.Fprintf(console, "✅ Stopped forward to %s\n", state.Target)
+			return nil
+		},
+	}
+}
+
+// loadForwardState reads the state of the forward of a project
+func loadForwardState(workDir string) (*ForwardState, error) {
+	data, err := os.ReadFile(filepath.Join(stateDir(workDir), forwardStateFile))
+	if err != nil {
+		return nil, err
+	}
+	var state ForwardState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse forward state: %w", err)
+	}
+	return &state, nil
+}
+
+// saveForwardState records a running forward
+func saveForwardState(workDir string, state *ForwardState) error {
+	if err := os.MkdirAll(stateDir(workDir), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode forward state: %w", err)
+	}
+	return os.WriteFile(filepath.Join(stateDir(workDir), forwardStateFile), data, 0644)
+}
+
+// removeForwardState deletes the forward state if it still belongs to pid
+func removeForwardState(workDir string, pid int) {
+	if state, err := loadForwardState(workDir); err == nil && state.PID == pid {
+		os.Remove(filepath.Join(stateDir(workDir), forwardStateFile))
+	}
+}
+
+// processRunning reports whether a process with the given pid exists
+func processRunning(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// stdinIsTerminal reports whether stdin is attached to a terminal
+func stdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// sshHost returns the host of an SSH destination such as user@host:22 or
+// ssh://user@host
+func sshHost(target string) string {
+	host := strings.TrimPrefix(target, "ssh://")
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+	if h, _, ok := strings.Cut(host, ":"); ok && !strings.HasPrefix(host, "[") {
+		host = h
+	}
+	return host
+}
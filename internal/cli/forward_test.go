@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestPlanForwardTunnels(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"web":    {Port: 3000},
+			"api":    {Port: 8080, ExternalPort: 18080, URLTemplate: "http://{host}:{port}/v1"},
+			"worker": {},
+		},
+	}
+
+	tunnels, err := planForwardTunnels(cfg, t.TempDir(), "shop", nil, "dev@box", false, forwardOptions{})
+	if err != nil {
+		t.Fatalf("planForwardTunnels() error = %v", err)
+	}
+	want := []ForwardTunnel{
+		{Service: "api", ListenPort: 18080, Host: "localhost", Port: 18080, URL: "http://localhost:18080/v1"},
+		{Service: "web", ListenPort: 3000, Host: "localhost", Port: 3000, URL: "http://localhost:3000"},
+	}
+	if !reflect.DeepEqual(tunnels, want) {
+		t.Errorf("planForwardTunnels() = %+v, want %+v", tunnels, want)
+	}
+
+	tunnels, err = planForwardTunnels(cfg, t.TempDir(), "shop", []string{"web", "api"}, "dev@box", false, forwardOptions{port: 15000})
+	if err != nil {
+		t.Fatalf("planForwardTunnels() error = %v", err)
+	}
+	if tunnels[0].ListenPort != 15000 || tunnels[1].ListenPort != 15001 {
+		t.Errorf("listen ports = %d, %d, want 15000, 15001", tunnels[0].ListenPort, tunnels[1].ListenPort)
+	}
+	if tunnels[1].URL != "http://localhost:15001/v1" {
+		t.Errorf("URL = %q, want the listen port", tunnels[1].URL)
+	}
+
+	if _, err := planForwardTunnels(cfg, t.TempDir(), "shop", []string{"worker"}, "dev@box", false, forwardOptions{}); err == nil {
+		t.Error("expected an error for a service without a port")
+	}
+}
+
+func TestSSHForwardArgs(t *testing.T) {
+	tunnels := []ForwardTunnel{
+		{Service: "web", ListenPort: 3000, Host: "localhost", Port: 3000},
+		{Service: "api", ListenPort: 9000, Host: "api-abc123.space.local", Port: 8080},
+	}
+	base := []string{"-N", "-o", "ExitOnForwardFailure=yes", "-o", "ServerAliveInterval=15", "-o", "ServerAliveCountMax=3"}
+
+	tests := []struct {
+		name    string
+		reverse bool
+		opts    forwardOptions
+		want    []string
+	}{
+		{
+			name:    "to",
+			reverse: true,
+			want:    append(append([]string{}, base...), "-R", "3000:localhost:3000", "-R", "9000:api-abc123.space.local:8080", "demo@vm"),
+		},
+		{
+			name: "from",
+			want: append(append([]string{}, base...), "-L", "3000:localhost:3000", "-L", "9000:api-abc123.space.local:8080", "demo@vm"),
+		},
+		{
+			name:    "batch, identity and all interfaces",
+			reverse: true,
+			opts:    forwardOptions{batch: true, identity: "~/.ssh/demo", bindAll: true},
+			want: append(append([]string{}, base...), "-o", "BatchMode=yes", "-i", "~/.ssh/demo",
+				"-R", "0.0.0.0:3000:localhost:3000", "-R", "0.0.0.0:9000:api-abc123.space.local:8080", "demo@vm"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sshForwardArgs("demo@vm", tt.reverse, tunnels, tt.opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sshForwardArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSHHost(t *testing.T) {
+	tests := map[string]string{
+		"vm.example.com":         "vm.example.com",
+		"demo@vm.example.com":    "vm.example.com",
+		"demo@vm:2222":           "vm",
+		"ssh://demo@vm.internal": "vm.internal",
+	}
+	for target, want := range tests {
+		if got := sshHost(target); got != want {
+			t.Errorf("sshHost(%q) = %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestForwardState(t *testing.T) {
+	workDir := t.TempDir()
+	state := &ForwardState{
+		PID:     os.Getpid(),
+		Target:  "demo@vm",
+		Reverse: true,
+		Tunnels: []ForwardTunnel{{Service: "web", ListenPort: 3000, Host: "localhost", Port: 3000}},
+	}
+	if err := saveForwardState(workDir, state); err != nil {
+		t.Fatalf("saveForwardState() error = %v", err)
+	}
+
+	loaded, err := loadForwardState(workDir)
+	if err != nil {
+		t.Fatalf("loadForwardState() error = %v", err)
+	}
+	if loaded.Target != "demo@vm" || len(loaded.Tunnels) != 1 || !processRunning(loaded.PID) {
+		t.Errorf("loadForwardState() = %+v", loaded)
+	}
+
+	// Another forward's state is left alone
+	removeForwardState(workDir, state.PID+1)
+	if _, err := loadForwardState(workDir); err != nil {
+		t.Errorf("state of another pid removed: %v", err)
+	}
+
+	removeForwardState(workDir, state.PID)
+	if _, err := loadForwardState(workDir); !os.IsNotExist(err) {
+		t.Errorf("loadForwardState() after remove error = %v, want not exist", err)
+	}
+}
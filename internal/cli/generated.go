@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hashMarker prefixes the header line holding the content hash of a
+// generated compose file
+const hashMarker = "# space-content-hash: "
+
+// generatedComposeFiles are the compose overrides space writes into the
+// project directory
+var generatedComposeFiles = []string{dnsComposeFile, healthComposeFile, platformComposeFile, observabilityComposeFile, mailComposeFile, networkComposeFile}
+
+// serviceComposeFiles are the generated overrides that add services rather
+// than change existing ones
+var serviceComposeFiles = []string{observabilityComposeFile, mailComposeFile}
+
+// generatedStatus describes an existing generated compose file
+type generatedStatus int
+
+const (
+	// generatedMissing means no file exists
+	generatedMissing generatedStatus = iota
+
+	// generatedCurrent means the file is intact and matches its hash
+	generatedCurrent
+
+	// generatedStale means the file was written by space but modified or
+	// truncated since (e.g. after a crash)
+	generatedStale
+
+	// generatedForeign means the file was not written by space
+	generatedForeign
+)
+
+// contentHash returns the short hash recorded in generated file headers
+func contentHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// splitGenerated splits a generated file into the recorded hash and the
+// body following the header comments
+func splitGenerated(data []byte) (hash string, body []byte, ok bool) {
+	rest := string(data)
+	for strings.HasPrefix(rest, "#") {
+		line, next, _ := strings.Cut(rest, "\n")
+		if strings.HasPrefix(line, hashMarker) {
+			hash = strings.TrimPrefix(line, hashMarker)
+		}
+		rest = next
+	}
+	if hash == "" {
+		return "", nil, false
+	}
+	return hash, []byte(strings.TrimPrefix(rest, "\n")), true
+}
+
+// inspectGenerated checks an existing generated compose file
+func inspectGenerated(path string) generatedStatus {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return generatedMissing
+	}
+
+	hash, body, ok := splitGenerated(data)
+	switch {
+	case ok && hash == contentHash(body):
+		return generatedCurrent
+	case ok || strings.HasPrefix(string(data), "# Auto-generated"):
+		return generatedStale
+	default:
+		return generatedForeign
+	}
+}
+
+// writeGenerated writes a generated compose file with its content hash in the
+// header. An intact file with the same content is reused, a stale one is
+// regenerated, and a file space did not write is never overwritten.
+func writeGenerated(path, header string, body []byte) (reused bool, err error) {
+	hash := contentHash(body)
+	content := header + hashMarker + hash + "\n\n" + string(body)
+
+	// A dry run shows the file, unless it would be refused
+	if dryRun != nil && inspectGenerated(path) != generatedForeign {
+		dryRun.addFile(path, []byte(content))
+		return false, nil
+	}
+
+	switch inspectGenerated(path) {
+	case generatedForeign:
+		return false, fmt.Errorf("%s exists and was not generated by space; remove it first", filepath.Base(path))
+	case generatedCurrent:
+		if data, err := os.ReadFile(path); err == nil {
+			if existing, _, _ := splitGenerated(data); existing == hash {
+				return true, nil
+			}
+		}
+	case generatedStale:
+		fmt.Fprintf(console, "🧹 Regenerating stale %s\n", filepath.Base(path))
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// removeGenerated deletes the generated compose files of a project directory
+// that space wrote, returning the removed paths
+func removeGenerated(workDir string, dryRun bool) ([]string, error) {
+	var removed []string
+	for _, name := range generatedComposeFiles {
+		path := filepath.Join(workDir, name)
+		switch inspectGenerated(path) {
+		case generatedMissing, generatedForeign:
+			continue
+		}
+
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				return removed, err
+			}
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
+
+// withGeneratedServices appends the generated overrides adding services that
+// are present, so ps and down see the containers they started
+func withGeneratedServices(workDir string, files []string) []string {
+	for _, name := range serviceComposeFiles {
+		path := filepath.Join(workDir, name)
+		if inspectGenerated(path) == generatedCurrent {
+			files = append(files, path)
+		}
+	}
+	return files
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteGenerated(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, dnsComposeFile)
+	header := "# Auto-generated DNS mode compose file\n"
+	body := []byte("services:\n  api:\n    image: api\n")
+
+	reused, err := writeGenerated(path, header, body)
+	if err != nil || reused {
+		t.Fatalf("first write = %v, %v", reused, err)
+	}
+	if got := inspectGenerated(path); got != generatedCurrent {
+		t.Fatalf("inspectGenerated() = %v, want current", got)
+	}
+
+	// Same content is reused
+	reused, err = writeGenerated(path, header, body)
+	if err != nil || !reused {
+		t.Fatalf("second write = %v, %v, want reused", reused, err)
+	}
+
+	// New content replaces the file
+	changed := []byte("services:\n  api:\n    image: api:v2\n")
+	if reused, err = writeGenerated(path, header, changed); err != nil || reused {
+		t.Fatalf("changed write = %v, %v", reused, err)
+	}
+
+	// A truncated file is stale and regenerated
+	data, _ := os.ReadFile(path)
+	if err := os.WriteFile(path, data[:len(data)-5], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := inspectGenerated(path); got != generatedStale {
+		t.Fatalf("inspectGenerated() = %v, want stale", got)
+	}
+	if _, err := writeGenerated(path, header, changed); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	if !strings.HasSuffix(string(data), string(changed)) {
+		t.Errorf("file not regenerated:\n%s", data)
+	}
+
+	// Legacy files without a hash are stale too
+	if err := os.WriteFile(path, []byte(header+"\nservices: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := inspectGenerated(path); got != generatedStale {
+		t.Errorf("legacy inspectGenerated() = %v, want stale", got)
+	}
+}
+
+func TestWriteGeneratedKeepsForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, dnsComposeFile)
+	if err := os.WriteFile(path, []byte("services: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := writeGenerated(path, "# Auto-generated\n", []byte("services: {}\n")); err == nil {
+		t.Fatal("expected an error for a file not written by space")
+	}
+
+	removed, err := removeGenerated(dir, false)
+	if err != nil || len(removed) != 0 {
+		t.Errorf("removeGenerated() = %v, %v, want nothing removed", removed, err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Error("foreign file was removed")
+	}
+}
+
+func TestRemoveGenerated(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range generatedComposeFiles {
+		if _, err := writeGenerated(filepath.Join(dir, name), "# Auto-generated\n", []byte("services: {}\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := removeGenerated(dir, true)
+	if err != nil || len(removed) != len(generatedComposeFiles) {
+		t.Fatalf("dry run removeGenerated() = %v, %v", removed, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, dnsComposeFile)); err != nil {
+		t.Fatal("dry run removed a file")
+	}
+
+	if removed, err = removeGenerated(dir, false); err != nil || len(removed) != len(generatedComposeFiles) {
+		t.Fatalf("removeGenerated() = %v, %v", removed, err)
+	}
+	for _, name := range generatedComposeFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", name)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gitBranchCacheTTL is how long a looked up branch is reused: long enough
+// that one command asks git once, short enough that space ui and status
+// --watch notice a checkout
+const gitBranchCacheTTL = 5 * time.Second
+
+// ciBranchVars are the environment variables CI systems name the branch
+// or ref being built in, most specific first. CI usually checks out a
+// detached HEAD, where git knows no branch. The generic ones are only read
+// under CI, see ciMarkerVars.
+var ciBranchVars = []string{
+	"GITHUB_HEAD_REF", // GitHub Actions, pull requests
+	"GITHUB_REF_NAME", // GitHub Actions
+	"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME",
+	"CI_COMMIT_REF_NAME", // GitLab CI
+	"BUILDKITE_BRANCH",
+	"CIRCLE_BRANCH",
+	"BITBUCKET_BRANCH",
+	"TRAVIS_PULL_REQUEST_BRANCH",
+	"TRAVIS_BRANCH",
+	"BRANCH_NAME", // Jenkins multibranch
+	"GIT_BRANCH",  // Jenkins git plugin, as origin/<branch>
+}
+
+// genericBranchVars are ciBranchVars names common enough to be left over in
+// a shell, where they would rename the project on a local bisect or rebase
+var genericBranchVars = []string{"BRANCH_NAME", "GIT_BRANCH"}
+
+// ciMarkerVars tell a CI run apart from a developer's shell
+var ciMarkerVars = []string{"CI", "JENKINS_URL"}
+
+type cachedBranch struct {
+	branch string
+	at     time.Time
+}
+
+var (
+	gitBranchMu    sync.Mutex
+	gitBranchCache = make(map[string]cachedBranch)
+)
+
+// getGitBranch returns the current git branch name, or on a detached HEAD
+// the ref the CI run builds. It returns "" when neither names one.
+func getGitBranch(workDir string) string {
+	key := filepath.Clean(workDir)
+
+	gitBranchMu.Lock()
+	cached, ok := gitBranchCache[key]
+	gitBranchMu.Unlock()
+	if ok && time.Since(cached.at) < gitBranchCacheTTL {
+		return cached.branch
+	}
+
+	branch := headBranch(workDir)
+	if branch == "" {
+		branch = ciBranch(os.Getenv)
+	}
+
+	gitBranchMu.Lock()
+	gitBranchCache[key] = cachedBranch{branch: branch, at: time.Now()}
+	gitBranchMu.Unlock()
+	return branch
+}
+
+// headBranch returns the branch HEAD is on, or "" for a detached HEAD or
+// outside a git repository
+func headBranch(workDir string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--short", "-q", "HEAD")
+	cmd.Dir = workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// ciBranch returns the branch or ref named by the first set ciBranchVars,
+// without a refs/heads/ or origin/ prefix
+func ciBranch(getenv func(string) string) string {
+	underCI := false
+	for _, name := range ciMarkerVars {
+		underCI = underCI || getenv(name) != ""
+	}
+
+	for _, name := range ciBranchVars {
+		ref := strings.TrimSpace(getenv(name))
+		if ref == "" || (!underCI && slices.Contains(genericBranchVars, name)) {
+			continue
+		}
+		ref = strings.TrimPrefix(ref, "refs/heads/")
+		ref = strings.TrimPrefix(ref, "origin/")
+		if ref != "" {
+			return ref
+		}
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// clearCIBranchVars unsets the CI branch variables for the test, so runs on
+// CI don't leak their branch in
+func clearCIBranchVars(t *testing.T) {
+	for _, name := range slices.Concat(ciBranchVars, ciMarkerVars) {
+		t.Setenv(name, "")
+	}
+}
+
+func TestCIBranch(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{"none", nil, ""},
+		{"github pull request", map[string]string{"GITHUB_HEAD_REF": "feature/login", "GITHUB_REF_NAME": "42/merge"}, "feature/login"},
+		{"github push", map[string]string{"GITHUB_REF_NAME": "main"}, "main"},
+		{"gitlab", map[string]string{"CI_COMMIT_REF_NAME": "fix-cart"}, "fix-cart"},
+		{"jenkins remote branch", map[string]string{"JENKINS_URL": "https://ci.example.com", "GIT_BRANCH": "origin/release/1.2"}, "release/1.2"},
+		{"jenkins multibranch", map[string]string{"CI": "true", "BRANCH_NAME": "feature/cart"}, "feature/cart"},
+		{"leftover outside CI", map[string]string{"GIT_BRANCH": "origin/old", "BRANCH_NAME": "old"}, ""},
+		{"full ref", map[string]string{"BUILDKITE_BRANCH": "refs/heads/dev"}, "dev"},
+		{"blank is skipped", map[string]string{"GITHUB_HEAD_REF": " ", "CIRCLE_BRANCH": "qa"}, "qa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ciBranch(func(name string) string { return tt.env[name] })
+			if got != tt.want {
+				t.Errorf("ciBranch() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetGitBranchDetachedHead(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	clearCIBranchVars(t)
+
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"checkout", "-q", "--detach"},
+	} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if branch := headBranch(repo); branch != "" {
+		t.Errorf("detached HEAD branch = %q, want none", branch)
+	}
+
+	t.Setenv("GITHUB_HEAD_REF", "feature/login")
+	if branch := getGitBranch(repo); branch != "feature/login" {
+		t.Errorf("branch = %q, want the CI ref", branch)
+	}
+
+	// The branch is reused within one command
+	t.Setenv("GITHUB_HEAD_REF", "other")
+	if branch := getGitBranch(repo); branch != "feature/login" {
+		t.Errorf("cached branch = %q, want feature/login", branch)
+	}
+}
+
+func TestProjectNameFallback(t *testing.T) {
+	clearCIBranchVars(t)
+
+	// Not a git repository, so git-branch can't name the project
+	workDir := filepath.Join(t.TempDir(), "checkout")
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		strategy string
+		fallback []string
+		want     string
+	}{
+		{"directory by default", config.NamingGitBranch, nil, "shop-checkout"},
+		{"static fallback", config.NamingGitBranch, []string{config.NamingStatic}, "shop"},
+		{"first working fallback", config.NamingGitBranch, []string{config.NamingGitBranch, config.NamingDirectory}, "shop-checkout"},
+		{"nothing works", config.NamingGitBranch, []string{config.NamingGitBranch}, "shop"},
+		{"strategy that works", config.NamingDirectory, []string{config.NamingStatic}, "shop-checkout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Project: config.ProjectConfig{
+				Name:           "shop",
+				NamingStrategy: tt.strategy,
+				NamingFallback: tt.fallback,
+			}}
+			if got := generateProjectName(cfg, workDir); got != tt.want {
+				t.Errorf("generateProjectName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Setenv("CI_COMMIT_REF_NAME", "feature/cart")
+	gitBranchMu.Lock()
+	delete(gitBranchCache, workDir)
+	gitBranchMu.Unlock()
+	cfg := &config.Config{Project: config.ProjectConfig{Name: "shop", NamingStrategy: config.NamingGitBranch}}
+	if got := generateProjectName(cfg, workDir); got != "shop-feature-cart" {
+		t.Errorf("generateProjectName() = %q, want the CI branch", got)
+	}
+}
@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// gitHookMarker identifies the git hooks written by 'space git-hooks install'
+const gitHookMarker = "# Managed by space; remove with 'space git-hooks uninstall'"
+
+// gitHookBackupSuffix names the hooks that were in place before install. The
+// managed hook runs them first, and uninstall puts them back.
+const gitHookBackupSuffix = ".pre-space"
+
+// managedGitHooks are the git hooks run after HEAD moves to another commit
+var managedGitHooks = []string{"post-checkout", "post-merge"}
+
+// refreshStack runs 'space up --refresh-override' for the project in workDir
+var refreshStack = func(workDir string) error {
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+	cmd := exec.Command(execPath, "--workdir", workDir, "up", "--refresh-override")
+	cmd.Stdout = console
+	cmd.Stderr = console
+	return cmd.Run()
+}
+
+func newGitHooksCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "git-hooks",
+		Short: "Keep the running stack in sync with branch switches",
+		Long: `Install git hooks that check the running stack after a checkout or merge.
+
+After HEAD moves, the hooks flush the DNS daemon's cache and warn when the
+compose files or .space.yaml changed, since the running containers were
+started from the old ones. With --auto-refresh they run
+'space up --refresh-override' instead of warning.`,
+	}
+
+	cmd.AddCommand(newGitHooksInstallCommand())
+	cmd.AddCommand(newGitHooksUninstallCommand())
+	cmd.AddCommand(newGitHooksRunCommand())
+
+	return cmd
+}
+
+func newGitHooksInstallCommand() *cobra.Command {
+	var autoRefresh bool
+
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install the post-checkout and post-merge hooks",
+		Long: `Install the post-checkout and post-merge hooks into the repository's hooks
+directory, which all its worktrees share. Hooks already in place are kept as
+<hook>` + gitHookBackupSuffix + ` and run first. Installing again updates the hooks.`,
+		Example: `  space git-hooks install
+  space git-hooks install --auto-refresh`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			dir, err := gitHooksDir(Workdir)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", dir, err)
+			}
+
+			for _, hook := range managedGitHooks {
+				kept, err := installGitHook(dir, hook, autoRefresh)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(console, "✅ Installed %s\n", filepath.Join(dir, hook))
+				if kept {
+					fmt.Fprintf(console, "   The existing hook runs first, from %s%s\n", hook, gitHookBackupSuffix)
+				}
+			}
+			if !autoRefresh {
+				tipf("Pass --auto-refresh to apply compose changes after a checkout instead of warning")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&autoRefresh, "auto-refresh", false, "Run 'space up --refresh-override' when a checkout changes the compose files of a running stack")
+
+	return cmd
+}
+
+func newGitHooksUninstallCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the hooks installed by space",
+		Long:  "Remove the hooks installed by space and put back the hooks they replaced.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			dir, err := gitHooksDir(Workdir)
+			if err != nil {
+				return err
+			}
+
+			removed := 0
+			for _, hook := range managedGitHooks {
+				ok, err := uninstallGitHook(dir, hook)
+				if err != nil {
+					return err
+				}
+				if ok {
+					fmt.Fprintf(console, "🗑️  Removed %s\n", filepath.Join(dir, hook))
+					removed++
+				}
+			}
+			if removed == 0 {
+				fmt.Fprintln(console, "No hooks installed by space")
+			}
+			return nil
+		},
+	}
+}
+
+// newGitHooksRunCommand is what the installed hooks call, with the
+// arguments git passed them
+func newGitHooksRunCommand() *cobra.Command {
+	var autoRefresh bool
+
+	cmd := &cobra.Command{
+		Use:    "run <hook> [args...]",
+		Short:  "Run the space actions of a git hook",
+		Hidden: true,
+		Args:   cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			workDir, err := filepath.Abs(Workdir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+			return runGitHook(workDir, args[0], args[1:], autoRefresh)
+		},
+	}
+
+	cmd.Flags().BoolVar(&autoRefresh, "auto-refresh", false, "Run 'space up --refresh-override' instead of warning")
+
+	return cmd
+}
+
+// gitHooksDir returns the hooks directory of the repository in workDir,
+// honoring core.hooksPath
+func gitHooksDir(workDir string) (string, error) {
+	dir, err := runGit(workDir, "rev-parse", "--path-format=absolute", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %w", err)
+	}
+	return dir, nil
+}
+
+// gitHookScript renders a managed hook. It does nothing when space isn't on
+// PATH and never fails the git command that ran it.
+func gitHookScript(hook string, autoRefresh bool) string {
+	flags := ""
+	if autoRefresh {
+		flags = " --auto-refresh"
+	}
+	return fmt.Sprintf(`#!/bin/sh
+%s
+if [ -x "$0%s" ]; then
+	"$0%s" "$@" || exit $?
+fi
+command -v space >/dev/null 2>&1 || exit 0
+space git-hooks run%s %s "$@" || true
+`, gitHookMarker, gitHookBackupSuffix, gitHookBackupSuffix, flags, hook)
+}
+
+// isManagedGitHook reports whether the hook at path was written by space
+func isManagedGitHook(path string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && bytes.Contains(data, []byte(gitHookMarker))
+}
+
+// installGitHook writes a managed hook into dir. A hook of the user's is
+// renamed to its backup first; it reports whether there was one.
+func installGitHook(dir, hook string, autoRefresh bool) (kept bool, err error) {
+	path := filepath.Join(dir, hook)
+	backup := path + gitHookBackupSuffix
+
+	if _, err := os.Stat(path); err == nil && !isManagedGitHook(path) {
+		if _, err := os.Stat(backup); err == nil {
+			return false, fmt.Errorf("%s exists and so does %s; move one of them away first", path, backup)
+		}
+		if err := os.Rename(path, backup); err != nil {
+			return false, fmt.Errorf("failed to keep the existing %s hook: %w", hook, err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(gitHookScript(hook, autoRefresh)), 0755); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	_, err = os.Stat(backup)
+	return err == nil, nil
+}
+
+// uninstallGitHook removes a managed hook from dir and puts back the hook it
+// replaced. Hooks space didn't write are left alone.
+func uninstallGitHook(dir, hook string) (bool, error) {
+	path := filepath.Join(dir, hook)
+	if !isManagedGitHook(path) {
+		return false, nil
+	}
+	if err := os.Remove(path); err != nil {
+		return false, fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+
+	backup := path + gitHookBackupSuffix
+	if _, err := os.Stat(backup); err == nil {
+		if err := os.Rename(backup, path); err != nil {
+			return true, fmt.Errorf("failed to restore %s: %w", path, err)
+		}
+	}
+	return true, nil
+}
+
+// runGitHook checks the stack of the worktree in workDir after git moved
+// HEAD. Only a stack that is up is checked: the DNS cache is flushed, and a
+// change to the compose files or the config is warned about, or applied with
+// autoRefresh.
+func runGitHook(workDir, hook string, args []string, autoRefresh bool) error {
+	var from, to string
+	switch hook {
+	case "post-checkout":
+		// A file checkout passes 0 as the third argument
+		if len(args) < 3 || args[2] != "1" || args[0] == args[1] {
+			return nil
+		}
+		from, to = args[0], args[1]
+	case "post-merge":
+		from, to = "ORIG_HEAD", "HEAD"
+	default:
+		return fmt.Errorf("unknown git hook %q", hook)
+	}
+
+	state, err := loadUpState(workDir)
+	if err != nil {
+		return nil
+	}
+
+	// Containers may be recreated under new addresses; the daemon isn't
+	// necessarily running, so a failed flush is fine
+	_ = callAgent(dnsCacheFlushOp, nil, nil)
+
+	sc, err := loadProjectContext(workDir)
+	if err != nil {
+		return err
+	}
+	changed := state.changedFiles(workDir, composeFilesFor(workDir, sc.cfg))
+	if out, err := runGit(workDir, "diff", "--name-only", from, to, "--", config.ConfigFileName, config.AlternateConfigFileName); err == nil && out != "" {
+		changed = append(changed, strings.Fields(out)...)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(console, "⚠️  space: %s changed, the running stack of %s is out of date\n", strings.Join(changed, ", "), sc.projectName)
+	if autoRefresh {
+		return refreshStack(workDir)
+	}
+	tipf("Run 'space up --refresh-override' to apply the changes")
+	return nil
+}
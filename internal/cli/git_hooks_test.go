@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallGitHook(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "post-checkout")
+	own := "#!/bin/sh\necho mine\n"
+	if err := os.WriteFile(path, []byte(own), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	kept, err := installGitHook(dir, "post-checkout", true)
+	if err != nil || !kept {
+		t.Fatalf("installGitHook() = %v, %v; want the existing hook kept", kept, err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), "space git-hooks run --auto-refresh post-checkout") {
+		t.Errorf("hook = %q", data)
+	}
+
+	// Installing again updates the managed hook and keeps the backup
+	if _, err := installGitHook(dir, "post-checkout", false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path + gitHookBackupSuffix); string(data) != own {
+		t.Errorf("backup = %q, want %q", data, own)
+	}
+
+	removed, err := uninstallGitHook(dir, "post-checkout")
+	if err != nil || !removed {
+		t.Fatalf("uninstallGitHook() = %v, %v", removed, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != own {
+		t.Errorf("restored hook = %q, want %q", data, own)
+	}
+	if removed, _ := uninstallGitHook(dir, "post-checkout"); removed {
+		t.Error("uninstallGitHook() removed a hook space didn't write")
+	}
+}
+
+func TestRunGitHook(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	compose := filepath.Join(repo, "docker-compose.yml")
+	if err := os.WriteFile(compose, []byte("services:\n  web:\n    image: nginx:1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "docker-compose.yml"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	head, err := runGit(repo, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var refreshed []string
+	oldRefresh, oldConsole := refreshStack, console
+	defer func() { refreshStack, console = oldRefresh, oldConsole }()
+	refreshStack = func(workDir string) error {
+		refreshed = append(refreshed, workDir)
+		return nil
+	}
+	var out bytes.Buffer
+	console = &out
+
+	// A stack that isn't up is left alone
+	if err := runGitHook(repo, "post-checkout", []string{"a", "b", "1"}, true); err != nil || out.Len() > 0 {
+		t.Fatalf("runGitHook() without a stack = %v, %q", err, out.String())
+	}
+
+	if err := saveUpState(repo, newUpState(repo, []string{"docker-compose.yml"}, nil, false)); err != nil {
+		t.Fatal(err)
+	}
+	if err := runGitHook(repo, "post-checkout", []string{head, head, "1"}, true); err != nil || len(refreshed) > 0 {
+		t.Fatalf("runGitHook() without changes = %v, refreshed %v", err, refreshed)
+	}
+
+	if err := os.WriteFile(compose, []byte("services:\n  web:\n    image: nginx:2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A file checkout passes 0 and is not a branch switch
+	if err := runGitHook(repo, "post-checkout", []string{"a", "b", "0"}, true); err != nil || len(refreshed) > 0 {
+		t.Fatalf("runGitHook() for a file checkout = %v, refreshed %v", err, refreshed)
+	}
+	if err := runGitHook(repo, "post-checkout", []string{"a", "b", "1"}, false); err != nil || len(refreshed) > 0 {
+		t.Fatalf("runGitHook() = %v, refreshed %v", err, refreshed)
+	}
+	if !strings.Contains(out.String(), "docker-compose.yml changed") {
+		t.Errorf("output = %q, want a warning", out.String())
+	}
+	if err := runGitHook(repo, "post-merge", []string{"0"}, true); err != nil || len(refreshed) != 1 {
+		t.Errorf("runGitHook(post-merge) = %v, refreshed %v", err, refreshed)
+	}
+}
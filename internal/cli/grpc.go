@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/grpcreflect"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newGRPCCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grpc",
+		Short: "Inspect gRPC services",
+		Long:  "Inspect services configured with protocol: grpc.",
+	}
+
+	cmd.AddCommand(newGRPCListCommand())
+
+	return cmd
+}
+
+func newGRPCListCommand() *cobra.Command {
+	var jsonOutput bool
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "list <service>",
+		Short: "List gRPC services and methods",
+		Long: `List the gRPC services and methods exposed by a service using server reflection.
+
+The service is reached through its *.space.local hostname while the DNS daemon
+is running, or its published localhost port otherwise. The server must register
+the reflection service (grpc.reflection.v1).`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get working directory
+			workDir := Workdir
+			if workDir == "." {
+				var err error
+				workDir, err = os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get working directory: %w", err)
+				}
+			}
+
+			// Make absolute
+			workDir, err := filepath.Abs(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+
+			// Create loader
+			loader, err := newConfigLoader(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to create config loader: %w", err)
+			}
+
+			// Load configuration
+			cfg, err := loader.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+
+			if project, err := loadComposeProject(workDir, cfg); err == nil {
+				applyComposeServices(cfg, project)
+			}
+
+			name := args[0]
+			svc, ok := cfg.Services[name]
+			if !ok {
+				return fmt.Errorf("unknown service %q", name)
+			}
+			if svc.EffectiveProtocol() != config.ProtocolGRPC {
+				return fmt.Errorf("service %q is not a grpc service (set protocol: grpc in .space.yaml)", name)
+			}
+
+			host, port := serviceAddress(cfg, name, workDir, serviceDNSActive(cfg, workDir))
+			if port == 0 {
+				return fmt.Errorf("service %q has no port configured", name)
+			}
+			address := net.JoinHostPort(host, strconv.Itoa(port))
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			services, err := grpcreflect.List(ctx, address)
+			if err != nil {
+				return fmt.Errorf("failed to list gRPC services of %s at %s: %w", name, address, err)
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(services)
+			}
+
+			fmt.Fprintf(console, "📡 %s (%s)\n", name, address)
+			if len(services) == 0 {
+				fmt.Fprintln(console, "   No services registered")
+				return nil
+			}
+			for _, s := range services {
+				fmt.Fprintln(console)
+				fmt.Fprintf(console, "   %s\n", s.Name)
+				for _, m := range s.Methods {
+					fmt.Fprintf(console, "     • %s\n", m.Signature())
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "Timeout for the reflection requests")
+
+	return cmd
+}
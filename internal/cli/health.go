@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/health"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// newHealthCommand creates the health command
+func newHealthCommand() *cobra.Command {
+	var all bool
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "health [services or groups...]",
+		Short: "Probe service health endpoints",
+		Long: `Probe the health of services concurrently and print the latency and HTTP
+status of each, exiting non-zero if any probe fails. Unlike 'space status' it
+does not query docker, which makes it a quick sanity check.
+
+By default only services with health_check enabled (and grpc services) are
+probed. With --all every service with a port is probed; http services
+without a configured endpoint try ` + strings.Join(health.DefaultEndpoints, ", ") + ` in order.
+Services named on the command line are always probed.`,
+		Example: `  space health
+  space health --all
+  space health api worker`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			services := sortedServiceNames(ctx.cfg)
+			if len(args) > 0 {
+				if services, err = ctx.cfg.ExpandGroups(args); err != nil {
+					return err
+				}
+				for _, name := range services {
+					if _, ok := ctx.cfg.Services[name]; !ok {
+						return fmt.Errorf("unknown service %q", name)
+					}
+				}
+			}
+
+			useDNS := serviceDNSActive(ctx.cfg, ctx.workDir)
+			targets := healthCheckTargets(ctx.cfg, ctx.workDir, ctx.projectName, useDNS, services, all || len(args) > 0)
+			if len(targets) == 0 {
+				fmt.Fprintln(console, "No services to probe.")
+				tipf("Enable health_check on a service or use --all")
+				return nil
+			}
+
+			results := health.Check(context.Background(), targets)
+
+			if jsonOutput {
+				encoder := json.NewEncoder(redact.NewWriter(os.Stdout))
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(results); err != nil {
+					return err
+				}
+			} else if err := printHealthResults(os.Stdout, results); err != nil {
+				return err
+			}
+
+			var failed []string
+			for _, result := range results {
+				if !result.Healthy {
+					failed = append(failed, result.Service)
+				}
+			}
+			if len(failed) > 0 {
+				return fmt.Errorf("%d service(s) unhealthy: %s", len(failed), strings.Join(failed, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Probe every service with a port, trying common health endpoints")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+
+	return cmd
+}
+
+// healthCheckTargets returns the probe targets for services. Services with
+// health checks use them; with all set, the remaining services with a port
+// are probed too, http ones on the default health endpoints.
+func healthCheckTargets(cfg *config.Config, workDir, projectName string, useDNS bool, services []string, all bool) []health.Target {
+	configured := make(map[string]health.Target)
+	for _, target := range healthTargets(cfg, workDir, projectName, useDNS) {
+		configured[target.Service] = target
+	}
+
+	var targets []health.Target
+	for _, name := range services {
+		if target, ok := configured[name]; ok {
+			targets = append(targets, target)
+			continue
+		}
+		if !all {
+			continue
+		}
+
+		host, port := serviceAddress(cfg, name, workDir, useDNS)
+		if port == 0 {
+			continue
+		}
+		targets = append(targets, health.Target{
+			Service:           name,
+			Protocol:          cfg.Services[name].EffectiveProtocol(),
+			Host:              host,
+			Port:              port,
+			Project:           projectName,
+			FallbackEndpoints: true,
+		})
+	}
+	return targets
+}
+
+// printHealthResults writes one line per probe followed by the errors
+func printHealthResults(out io.Writer, results []health.Result) error {
+	w := newTable(out)
+	fmt.Fprintln(w, "SERVICE\tHEALTH\tSTATUS\tLATENCY\tTARGET")
+	fmt.Fprintln(w, "-------\t------\t------\t-------\t------")
+	for _, r := range results {
+		state := "✓ healthy"
+		if !r.Healthy {
+			state = "✗ unhealthy"
+		}
+		status := "-"
+		if r.Status > 0 {
+			status = fmt.Sprint(r.Status)
+		}
+		target := r.URL
+		if target == "" {
+			target = r.Protocol + "://" + r.Address
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Service, state, status, r.Latency.Round(time.Millisecond), redact.String(target))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(out, "⚠️  %s: %s\n", r.Service, redact.String(r.Error))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/health"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestHealthCheckTargets(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"web":    {Port: 3000, HealthCheck: &config.HealthCheckConfig{Enabled: true, Endpoint: "/health"}},
+			"api":    {Port: 8080},
+			"db":     {Port: 5432, Protocol: "tcp"},
+			"worker": {},
+		},
+	}
+	services := sortedServiceNames(cfg)
+
+	targets := healthCheckTargets(cfg, "/tmp", "shop", false, services, false)
+	if len(targets) != 1 || targets[0].Service != "web" || targets[0].FallbackEndpoints {
+		t.Fatalf("configured targets = %+v", targets)
+	}
+
+	targets = healthCheckTargets(cfg, "/tmp", "shop", false, services, true)
+	var got []string
+	for _, target := range targets {
+		got = append(got, target.Service)
+		if target.Service != "web" && !target.FallbackEndpoints {
+			t.Errorf("%s should probe the default endpoints", target.Service)
+		}
+	}
+	if strings.Join(got, ",") != "api,db,web" {
+		t.Errorf("all targets = %v", got)
+	}
+}
+
+func TestPrintHealthResults(t *testing.T) {
+	results := []health.Result{
+		{Service: "api", Protocol: "http", Address: "localhost:8080", URL: "http://localhost:8080/healthz", Status: 200, Healthy: true, Latency: 12 * time.Millisecond},
+		{Service: "db", Protocol: "tcp", Address: "localhost:5432", Error: "connection refused", Latency: time.Millisecond},
+	}
+
+	var out bytes.Buffer
+	if err := printHealthResults(&out, results); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"http://localhost:8080/healthz", "200", "12ms", "tcp://localhost:5432", "✗ unhealthy", "db: connection refused"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
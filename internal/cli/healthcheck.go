@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/health"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// healthComposeFile is the generated override holding synthesized healthchecks
+const healthComposeFile = ".space-health-compose.yml"
+
+// imageHasHealthcheck reports whether a local image declares a HEALTHCHECK.
+// Images that are not pulled yet report false.
+var imageHasHealthcheck = func(ctx context.Context, image string) bool {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "docker", "image", "inspect",
+		"--format", "{{if .Config.Healthcheck}}yes{{end}}", image).Output()
+	return err == nil && strings.TrimSpace(string(out)) == "yes"
+}
+
+// synthesizeHealthchecks builds compose healthchecks for services with an
+// enabled health_check that have none in compose or in their image, so docker
+// health, depends_on conditions and space status share one definition.
+func synthesizeHealthchecks(ctx context.Context, cfg *config.Config, project *compose.Project) map[string]interface{} {
+	services := make(map[string]interface{})
+	if project == nil {
+		return services
+	}
+
+	for _, name := range sortedServiceNames(cfg) {
+		svc := cfg.Services[name]
+		command := healthCommand(cfg, name)
+		if svc.HealthCheck == nil || !svc.HealthCheck.Enabled || (svc.Port == 0 && command == "") {
+			continue
+		}
+		if _, ok := project.Services()[name]; !ok || project.HasHealthcheck(name) {
+			continue
+		}
+		if image := project.Image(name); image != "" && imageHasHealthcheck(ctx, image) {
+			continue
+		}
+
+		services[name] = map[string]interface{}{
+			"healthcheck": health.ComposeHealthcheck(health.Target{
+				Protocol: svc.EffectiveProtocol(),
+				Port:     svc.Port,
+				Check:    svc.HealthCheck,
+				Command:  command,
+			}),
+		}
+	}
+	return services
+}
+
+// writeHealthcheckOverride writes the synthesized healthchecks as a compose
+// override. It returns the override path and the services it covers, or ""
+// when no service needs a healthcheck.
+func writeHealthcheckOverride(ctx context.Context, workDir string, cfg *config.Config, project *compose.Project) (string, []string, error) {
+	services := synthesizeHealthchecks(ctx, cfg, project)
+	if len(services) == 0 {
+		return "", nil, nil
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{"services": services})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal healthchecks: %w", err)
+	}
+
+	header := "# Auto-generated healthchecks from .space.yml health_check settings\n"
+	overrideFile := filepath.Join(workDir, healthComposeFile)
+	if _, err := writeGenerated(overrideFile, header, data); err != nil {
+		return "", nil, fmt.Errorf("failed to write healthcheck override: %w", err)
+	}
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return overrideFile, names, nil
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestSynthesizeHealthchecks(t *testing.T) {
+	original := imageHasHealthcheck
+	imageHasHealthcheck = func(ctx context.Context, image string) bool {
+		return image == "with-healthcheck:latest"
+	}
+	defer func() { imageHasHealthcheck = original }()
+
+	enabled := &config.HealthCheckConfig{Enabled: true, Endpoint: "/health"}
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"api":      {Port: 8080, HealthCheck: enabled},
+			"db":       {Port: 5432, Protocol: "tcp", HealthCheck: &config.HealthCheckConfig{Enabled: true}},
+			"web":      {Port: 3000, HealthCheck: &config.HealthCheckConfig{Enabled: false}},
+			"worker":   {Port: 9000},
+			"compose":  {Port: 8081, HealthCheck: enabled},
+			"image":    {Port: 8082, HealthCheck: enabled},
+			"external": {Port: 8083, HealthCheck: enabled},
+		},
+	}
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api":     map[string]interface{}{"image": "api:latest"},
+			"db":      map[string]interface{}{"image": "postgres:16"},
+			"web":     map[string]interface{}{},
+			"worker":  map[string]interface{}{},
+			"compose": map[string]interface{}{"healthcheck": map[string]interface{}{"disable": true}},
+			"image":   map[string]interface{}{"image": "with-healthcheck:latest"},
+		},
+	}}
+
+	services := synthesizeHealthchecks(context.Background(), cfg, project)
+
+	var names []string
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if want := []string{"api", "db"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("synthesized for %v, want %v", names, want)
+	}
+
+	api := services["api"].(map[string]interface{})["healthcheck"].(map[string]interface{})
+	test := api["test"].([]interface{})
+	if !strings.Contains(test[1].(string), "http://127.0.0.1:8080/health") {
+		t.Errorf("api test = %v", test)
+	}
+}
+
+func TestWriteHealthcheckOverride(t *testing.T) {
+	original := imageHasHealthcheck
+	imageHasHealthcheck = func(ctx context.Context, image string) bool { return false }
+	defer func() { imageHasHealthcheck = original }()
+
+	workDir := t.TempDir()
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{"api": map[string]interface{}{}},
+	}}
+
+	// Nothing to synthesize
+	file, _, err := writeHealthcheckOverride(context.Background(), workDir, &config.Config{}, project)
+	if err != nil || file != "" {
+		t.Fatalf("writeHealthcheckOverride() = %q, %v, want no file", file, err)
+	}
+
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{
+		"api": {Port: 8080, HealthCheck: &config.HealthCheckConfig{Enabled: true}},
+	}}
+	file, services, err := writeHealthcheckOverride(context.Background(), workDir, cfg, project)
+	if err != nil {
+		t.Fatalf("writeHealthcheckOverride() error = %v", err)
+	}
+	if !reflect.DeepEqual(services, []string{"api"}) {
+		t.Errorf("services = %v, want [api]", services)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "healthcheck:") || !strings.Contains(string(data), "CMD-SHELL") {
+		t.Errorf("override missing healthcheck:\n%s", data)
+	}
+}
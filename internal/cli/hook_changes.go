@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/diff"
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+// hookWatchedFiles are the files of the project directory hooks commonly
+// rewrite, such as the vite config and env files. What hooks change in them
+// is shown as a diff and can be undone.
+var hookWatchedFiles = []string{".env*", "vite.config.*"}
+
+// hookChangeSetsKept bounds the change sets kept for 'space hooks undo'
+const hookChangeSetsKept = 20
+
+const (
+	// hookChangesFile describes a change set
+	hookChangesFile = "changes.json"
+
+	// hookDiffFile is the diff of a change set, with secrets masked
+	hookDiffFile = "changes.diff"
+
+	// hookBackupDir holds the files of a change set as they were before
+	hookBackupDir = "before"
+)
+
+// hookChangesDir holds a directory per hook run that changed files
+func hookChangesDir(workDir string) string {
+	return filepath.Join(workDir, ".space", "logs", "hook-changes")
+}
+
+// hookChangeSet is what the hooks of one event changed
+type hookChangeSet struct {
+	Event string       `json:"event"`
+	Time  time.Time    `json:"time"`
+	Files []hookChange `json:"files"`
+}
+
+// hookChange is a file hooks created, changed or removed
+type hookChange struct {
+	// Path is relative to the project directory
+	Path string `json:"path"`
+
+	// Existed is set when the file was there before; it is kept in the
+	// backup directory
+	Existed bool        `json:"existed"`
+	Mode    os.FileMode `json:"mode,omitempty"`
+
+	// Hash is the content the hooks left, or "" when they removed the
+	// file, so undo can tell when the file was edited since
+	Hash string `json:"hash,omitempty"`
+}
+
+// hookFile is a watched file as hooks found or left it
+type hookFile struct {
+	data []byte
+	mode os.FileMode
+}
+
+// snapshotHookFiles reads the watched files of workDir. Backups other tools
+// keep next to them are left out.
+func snapshotHookFiles(workDir string) map[string]hookFile {
+	files := make(map[string]hookFile)
+	for _, pattern := range hookWatchedFiles {
+		matches, _ := filepath.Glob(filepath.Join(workDir, pattern))
+		for _, path := range matches {
+			if strings.HasSuffix(path, ".backup") {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			files[filepath.Base(path)] = hookFile{data: data, mode: info.Mode().Perm()}
+		}
+	}
+	return files
+}
+
+// hookDiff returns the unified diff of what hooks changed, in path order
+func hookDiff(before, after map[string]hookFile) (string, []string) {
+	paths := make(map[string]bool)
+	for path := range before {
+		paths[path] = true
+	}
+	for path := range after {
+		paths[path] = true
+	}
+
+	var changed []string
+	var b strings.Builder
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		old, existed := before[path]
+		cur, exists := after[path]
+		if existed == exists && string(old.data) == string(cur.data) {
+			continue
+		}
+		from, to := "a/"+path, "b/"+path
+		if !existed {
+			from = "/dev/null"
+		}
+		if !exists {
+			to = "/dev/null"
+		}
+		b.WriteString(diff.Unified(from, to, old.data, cur.data))
+		changed = append(changed, path)
+	}
+	return b.String(), changed
+}
+
+// recordHookChanges shows what the hooks of event changed in the watched
+// files since before, and keeps the old files for 'space hooks undo'
+func recordHookChanges(workDir string, event hooks.EventType, before map[string]hookFile) {
+	after := snapshotHookFiles(workDir)
+	text, changed := hookDiff(before, after)
+	if len(changed) == 0 {
+		return
+	}
+
+	text = redact.String(text)
+	fmt.Fprintln(console)
+	fmt.Fprintf(console, "📝 %s hooks changed %s:\n", event, strings.Join(changed, ", "))
+	for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+		fmt.Fprintf(console, "   %s\n", line)
+	}
+
+	set := hookChangeSet{Event: string(event), Time: time.Now()}
+	dir := filepath.Join(hookChangesDir(workDir), set.Time.Format("20060102-150405.000")+"-"+string(event))
+	for _, path := range changed {
+		change := hookChange{Path: path}
+		if old, ok := before[path]; ok {
+			change.Existed, change.Mode = true, old.mode
+			if err := writeFile(filepath.Join(dir, hookBackupDir, path), old.data, 0600); err != nil {
+				fmt.Fprintf(console, "⚠️  Failed to keep the old %s: %v\n", path, err)
+				return
+			}
+		}
+		if cur, ok := after[path]; ok {
+			change.Hash = contentHash(cur.data)
+		}
+		set.Files = append(set.Files, change)
+	}
+
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err == nil {
+		err = writeFile(filepath.Join(dir, hookChangesFile), data, 0600)
+	}
+	if err == nil {
+		err = writeFile(filepath.Join(dir, hookDiffFile), []byte(text), 0600)
+	}
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  Failed to record the hook changes: %v\n", err)
+		return
+	}
+	pruneHookChanges(workDir)
+	tipf("Run 'space hooks undo' to revert them")
+}
+
+// hookChangeSets lists the directories of the recorded change sets, oldest
+// first
+func hookChangeSets(workDir string) []string {
+	entries, err := os.ReadDir(hookChangesDir(workDir))
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, filepath.Join(hookChangesDir(workDir), entry.Name()))
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// pruneHookChanges drops the oldest change sets beyond hookChangeSetsKept
+func pruneHookChanges(workDir string) {
+	dirs := hookChangeSets(workDir)
+	for len(dirs) > hookChangeSetsKept {
+		os.RemoveAll(dirs[0])
+		dirs = dirs[1:]
+	}
+}
+
+// undoHookChanges reverts the latest change set and drops it, so the one
+// before is next. Files edited since the hooks ran are only reverted with
+// force.
+func undoHookChanges(workDir string, force bool) (*hookChangeSet, error) {
+	dirs := hookChangeSets(workDir)
+	if len(dirs) == 0 {
+		return nil, nil
+	}
+	dir := dirs[len(dirs)-1]
+
+	data, err := os.ReadFile(filepath.Join(dir, hookChangesFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the hook changes: %w", err)
+	}
+	var set hookChangeSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Join(dir, hookChangesFile), err)
+	}
+
+	if !force {
+		for _, change := range set.Files {
+			data, err := os.ReadFile(filepath.Join(workDir, change.Path))
+			current := ""
+			if err == nil {
+				current = contentHash(data)
+			}
+			if current != change.Hash {
+				return nil, fmt.Errorf("%s changed since the %s hooks ran; pass --force to revert it anyway", change.Path, set.Event)
+			}
+		}
+	}
+
+	for _, change := range set.Files {
+		path := filepath.Join(workDir, change.Path)
+		if !change.Existed {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to remove %s: %w", change.Path, err)
+			}
+			continue
+		}
+		old, err := os.ReadFile(filepath.Join(dir, hookBackupDir, change.Path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the old %s: %w", change.Path, err)
+		}
+		if err := os.WriteFile(path, old, change.Mode); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", change.Path, err)
+		}
+		// WriteFile keeps the mode of a file that is there
+		if err := os.Chmod(path, change.Mode); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", change.Path, err)
+		}
+	}
+	return &set, os.RemoveAll(dir)
+}
+
+func newHooksUndoCommand() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Revert the latest file changes made by hooks",
+		Long: `Revert what the hooks of the latest event changed in the project's env files
+and vite config, from the copies kept in .space/logs/hook-changes. Running it
+again reverts the changes before those.
+
+Files edited since the hooks ran are left alone unless --force is set.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			workDir, err := filepath.Abs(Workdir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+
+			set, err := undoHookChanges(workDir, force)
+			if err != nil {
+				return err
+			}
+			if set == nil {
+				fmt.Fprintln(console, "No hook changes to undo")
+				return nil
+			}
+
+			fmt.Fprintf(console, "↩️  Reverted the changes of the %s hooks from %s:\n", set.Event, set.Time.Format(time.DateTime))
+			for _, change := range set.Files {
+				if change.Existed {
+					fmt.Fprintf(console, "   • restored %s\n", change.Path)
+				} else {
+					fmt.Fprintf(console, "   • removed %s\n", change.Path)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Revert files edited since the hooks ran")
+
+	return cmd
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/hooks"
+)
+
+func TestHookChanges(t *testing.T) {
+	oldConsole := console
+	defer func() { console = oldConsole }()
+	var out bytes.Buffer
+	console = &out
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		data, _ := os.ReadFile(filepath.Join(dir, name))
+		return string(data)
+	}
+	config := "export default defineConfig({\n  plugins: [],\n})\n"
+	write("vite.config.ts", config)
+	write("README.md", "docs\n")
+
+	// A hook updates the config, adds an env file and leaves a backup
+	before := snapshotHookFiles(dir)
+	write("vite.config.ts", "export default defineConfig({\n  plugins: [],\n  server: { allowedHosts: ['.space.local'] },\n})\n")
+	write(".env.development.local", "VITE_API_URL=http://api.space.local\n")
+	write("vite.config.ts.backup", config)
+	write("README.md", "edited\n")
+	recordHookChanges(dir, hooks.PostUp, before)
+
+	printed := out.String()
+	for _, want := range []string{
+		"post-up hooks changed .env.development.local, vite.config.ts",
+		"--- /dev/null\n   +++ b/.env.development.local",
+		"+  server: { allowedHosts: ['.space.local'] },",
+		"space hooks undo",
+	} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("output does not contain %q:\n%s", want, printed)
+		}
+	}
+	if strings.Contains(printed, "README") || strings.Contains(printed, ".backup") {
+		t.Errorf("unwatched files in the output:\n%s", printed)
+	}
+	if sets := hookChangeSets(dir); len(sets) != 1 {
+		t.Fatalf("recorded %d change sets, want 1", len(sets))
+	} else if _, err := os.Stat(filepath.Join(sets[0], hookDiffFile)); err != nil {
+		t.Errorf("diff not stored: %v", err)
+	}
+
+	// Edited since: refused without force
+	write(".env.development.local", "VITE_API_URL=mine\n")
+	if _, err := undoHookChanges(dir, false); err == nil || !strings.Contains(err.Error(), ".env.development.local changed") {
+		t.Fatalf("undoHookChanges() error = %v, want edited file refused", err)
+	}
+
+	set, err := undoHookChanges(dir, true)
+	if err != nil || set == nil || set.Event != string(hooks.PostUp) {
+		t.Fatalf("undoHookChanges() = %+v, %v", set, err)
+	}
+	if got := read("vite.config.ts"); got != config {
+		t.Errorf("vite.config.ts = %q, want the original", got)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "vite.config.ts")); err != nil || info.Mode().Perm() != 0640 {
+		t.Errorf("vite.config.ts mode = %v, %v, want 0640", info.Mode(), err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".env.development.local")); !os.IsNotExist(err) {
+		t.Errorf("created env file not removed: %v", err)
+	}
+	if sets := hookChangeSets(dir); len(sets) != 0 {
+		t.Errorf("%d change sets left after undo", len(sets))
+	}
+	if set, err := undoHookChanges(dir, false); set != nil || err != nil {
+		t.Errorf("second undoHookChanges() = %+v, %v, want nothing", set, err)
+	}
+}
+
+func TestHookChangesUnchanged(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("A=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	recordHookChanges(dir, hooks.PreUp, snapshotHookFiles(dir))
+	if _, err := os.Stat(hookChangesDir(dir)); !os.IsNotExist(err) {
+		t.Errorf("change set recorded without changes: %v", err)
+	}
+}
@@ -1,11 +1,17 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/internal/hooks/messaging"
+	"github.com/happy-sdk/space-cli/internal/hooks/storage"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/pkg/config"
 	"github.com/spf13/cobra"
 )
 
@@ -18,10 +24,36 @@ func newHooksCommand() *cobra.Command {
 
 	cmd.AddCommand(newHooksInitCommand())
 	cmd.AddCommand(newHooksListCommand())
+	cmd.AddCommand(newHooksTestCommand())
+	cmd.AddCommand(newHooksUndoCommand())
+	cmd.AddCommand(newHooksHelperCommand())
 
 	return cmd
 }
 
+// newHooksHelperCommand runs a bundled hook helper. Hook scripts reach it
+// through the shims on their PATH, e.g. wait-for-url.
+func newHooksHelperCommand() *cobra.Command {
+	var usage []string
+	for _, h := range hooks.Helpers {
+		usage = append(usage, "  "+h.Usage)
+	}
+
+	return &cobra.Command{
+		Use:    "helper <name> [args...]",
+		Short:  "Run a helper bundled for hook scripts",
+		Long:   "Run a helper bundled for hook scripts:\n\n" + strings.Join(usage, "\n"),
+		Hidden: true,
+		Args:   cobra.MinimumNArgs(1),
+		// Helper arguments such as timeouts are passed through untouched
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return hooks.RunHelper(context.Background(), args[0], args[1:], os.Stdin, os.Stdout)
+		},
+	}
+}
+
 func newHooksInitCommand() *cobra.Command {
 	var withTemplates bool
 
@@ -45,7 +77,7 @@ func newHooksInitCommand() *cobra.Command {
 				return fmt.Errorf("failed to resolve working directory: %w", err)
 			}
 
-			fmt.Printf("🪝 Initializing .space/ in %s\n", workDir)
+			fmt.Fprintf(console, "🪝 Initializing .space/ in %s\n", workDir)
 
 			if err := hooks.InitHooksDir(workDir); err != nil {
 				return fmt.Errorf("failed to initialize hooks: %w", err)
@@ -56,31 +88,31 @@ func newHooksInitCommand() *cobra.Command {
 				return fmt.Errorf("failed to initialize commands: %w", err)
 			}
 
-			fmt.Println("✅ Space directories initialized!")
-			fmt.Println()
-			fmt.Println("Created directories:")
-			fmt.Println("   .space/hooks/pre-up.d/      - Before docker compose up")
-			fmt.Println("   .space/hooks/post-up.d/     - After services are running")
-			fmt.Println("   .space/hooks/pre-down.d/    - Before docker compose down")
-			fmt.Println("   .space/hooks/post-down.d/   - After services are stopped")
-			fmt.Println("   .space/hooks/on-dns-ready.d/ - When DNS is configured")
-			fmt.Println("   .space/commands/            - Custom commands (any language)")
-			fmt.Println()
-			fmt.Println("📖 See .space/hooks/README.md for hook documentation")
-			fmt.Println("📖 See .space/commands/README.md for custom commands")
+			fmt.Fprintln(console, "✅ Space directories initialized!")
+			fmt.Fprintln(console)
+			fmt.Fprintln(console, "Created directories:")
+			fmt.Fprintln(console, "   .space/hooks/pre-up.d/      - Before docker compose up")
+			fmt.Fprintln(console, "   .space/hooks/post-up.d/     - After services are running")
+			fmt.Fprintln(console, "   .space/hooks/pre-down.d/    - Before docker compose down")
+			fmt.Fprintln(console, "   .space/hooks/post-down.d/   - After services are stopped")
+			fmt.Fprintln(console, "   .space/hooks/on-dns-ready.d/ - When DNS is configured")
+			fmt.Fprintln(console, "   .space/commands/            - Custom commands (any language)")
+			fmt.Fprintln(console)
+			fmt.Fprintln(console, "📖 See .space/hooks/README.md for hook documentation")
+			fmt.Fprintln(console, "📖 See .space/commands/README.md for custom commands")
 
 			if withTemplates {
 				if err := createTemplateHooks(workDir); err != nil {
-					fmt.Printf("⚠️  Failed to create templates: %v\n", err)
+					fmt.Fprintf(console, "⚠️  Failed to create templates: %v\n", err)
 				} else {
-					fmt.Println()
-					fmt.Println("📝 Created template hooks:")
-					fmt.Println("   .space/hooks/post-up.d/10-vite-env.sh.template")
-					fmt.Println("   .space/hooks/post-up.d/20-river-db.sh.template")
-					fmt.Println()
-					fmt.Println("💡 To use a template, rename it (remove .template) and make executable:")
-					fmt.Println("   mv .space/hooks/post-up.d/10-vite-env.sh.template .space/hooks/post-up.d/10-vite-env.sh")
-					fmt.Println("   chmod +x .space/hooks/post-up.d/10-vite-env.sh")
+					fmt.Fprintln(console)
+					fmt.Fprintln(console, "📝 Created template hooks:")
+					fmt.Fprintln(console, "   .space/hooks/post-up.d/10-vite-env.sh.template")
+					fmt.Fprintln(console, "   .space/hooks/post-up.d/20-river-db.sh.template")
+					fmt.Fprintln(console)
+					fmt.Fprintln(console, "💡 To use a template, rename it (remove .template) and make executable:")
+					fmt.Fprintln(console, "   mv .space/hooks/post-up.d/10-vite-env.sh.template .space/hooks/post-up.d/10-vite-env.sh")
+					fmt.Fprintln(console, "   chmod +x .space/hooks/post-up.d/10-vite-env.sh")
 				}
 			}
 
@@ -111,13 +143,14 @@ func newHooksListCommand() *cobra.Command {
 			workDir, _ = filepath.Abs(workDir)
 			hooksDir := filepath.Join(workDir, ".space", "hooks")
 
-			if _, err := os.Stat(hooksDir); os.IsNotExist(err) {
-				fmt.Println("No hooks configured.")
-				fmt.Println("Run 'space hooks init' to create the hooks directory.")
+			_, goHooksErr := os.Stat(filepath.Join(workDir, ".space", hooks.GoHooksFile))
+			if _, err := os.Stat(hooksDir); os.IsNotExist(err) && os.IsNotExist(goHooksErr) {
+				fmt.Fprintln(console, "No hooks configured.")
+				fmt.Fprintln(console, "Run 'space hooks init' to create the hooks directory.")
 				return nil
 			}
 
-			events := []string{"pre-up", "post-up", "pre-down", "post-down", "on-dns-ready"}
+			events := []string{"pre-up", "post-up", "pre-down", "post-down", "on-dns-ready", "on-service-healthy"}
 			hasHooks := false
 
 			for _, event := range events {
@@ -145,17 +178,40 @@ func newHooksListCommand() *cobra.Command {
 
 				if len(scripts) > 0 {
 					hasHooks = true
-					fmt.Printf("📁 %s:\n", event)
+					fmt.Fprintf(console, "📁 %s:\n", event)
 					for _, script := range scripts {
-						fmt.Printf("   • %s\n", script)
+						path := filepath.Join(eventDir, script)
+						meta, err := hooks.ReadScriptMeta(path)
+						switch {
+						case err != nil:
+							fmt.Fprintf(console, "   • %s ⚠️  %v\n", script, err)
+						case hooks.PlatformMismatch(path, meta) != "":
+							fmt.Fprintf(console, "   • %s (skipped here: %s)\n", script, hooks.PlatformMismatch(path, meta))
+						case meta.Service != "":
+							fmt.Fprintf(console, "   • %s (in %s)\n", script, meta.Service)
+						default:
+							fmt.Fprintf(console, "   • %s\n", script)
+						}
 					}
-					fmt.Println()
+					fmt.Fprintln(console)
+				}
+			}
+
+			goHooks, err := hooks.LoadGoHooks(workDir)
+			if err != nil {
+				fmt.Fprintf(console, "⚠️  Failed to load .space/%s: %v\n", hooks.GoHooksFile, err)
+			} else if len(goHooks) > 0 {
+				hasHooks = true
+				fmt.Fprintf(console, "📁 .space/%s:\n", hooks.GoHooksFile)
+				for _, h := range goHooks {
+					fmt.Fprintf(console, "   • %s %v - %s\n", h.Name(), h.Events(), h.Description())
 				}
+				fmt.Fprintln(console)
 			}
 
 			if !hasHooks {
-				fmt.Println("No executable hooks found.")
-				fmt.Println("Add scripts to .space/hooks/{event}.d/ and make them executable.")
+				fmt.Fprintln(console, "No executable hooks found.")
+				fmt.Fprintln(console, "Add scripts to .space/hooks/{event}.d/ and make them executable.")
 			}
 
 			return nil
@@ -165,6 +221,177 @@ func newHooksListCommand() *cobra.Command {
 	return cmd
 }
 
+// goHookLogger prints hook manager messages in verbose mode
+type goHookLogger struct {
+	verbose bool
+}
+
+func (l goHookLogger) Printf(format string, v ...interface{}) {
+	if l.verbose {
+		fmt.Fprintf(console, "   [verbose] "+format+"\n", v...)
+	}
+}
+
+// runGoHooks runs the Go-native hooks defined in .space/hooks.go for an event
+func runGoHooks(ctx context.Context, event hooks.EventType, hookCtx *hooks.HookContext, verbose bool) {
+	goHooks, err := hooks.LoadGoHooks(hookCtx.WorkDir)
+	if err != nil {
+		fmt.Fprintf(console, "   ⚠️  Failed to load .space/%s: %v\n", hooks.GoHooksFile, err)
+		return
+	}
+
+	manager := hooks.NewManagerWithLogger(goHookLogger{verbose: verbose})
+	for _, h := range goHooks {
+		if err := manager.Register(h); err != nil {
+			fmt.Fprintf(console, "   ⚠️  %v\n", err)
+		}
+	}
+	if !manager.HasHooksFor(event) {
+		return
+	}
+
+	fmt.Fprintln(console)
+	fmt.Fprintf(console, "🪝 Running %s Go hooks...\n", event)
+
+	for _, err := range manager.Execute(ctx, event, hookCtx) {
+		fmt.Fprintf(console, "   ⚠️  %v\n", err)
+	}
+}
+
+// runBuiltinHooks runs the hooks space provides itself, as enabled under
+// hooks: in .space.yml
+func runBuiltinHooks(ctx context.Context, event hooks.EventType, hookCtx *hooks.HookContext, cfg *config.Config, verbose bool) {
+	// The built-in hooks only handle post-up; skip resolving their services
+	// otherwise
+	if event != hooks.PostUp {
+		return
+	}
+
+	manager := hooks.NewManagerWithLogger(goHookLogger{verbose: verbose})
+	register := func(name string, h hooks.Hook, err error) {
+		if err != nil {
+			fmt.Fprintf(console, "   ⚠️  %s hook: %v\n", name, err)
+		} else if err := manager.Register(h); err != nil {
+			fmt.Fprintf(console, "   ⚠️  %v\n", err)
+		}
+	}
+
+	if m := cfg.Hooks.Messaging; m != nil && len(m.Topics) > 0 {
+		h, err := newMessagingHook(cfg)
+		register("messaging", h, err)
+	}
+	if st := cfg.Hooks.Storage; st != nil && (len(st.Buckets) > 0 || len(st.Queues) > 0) {
+		h, err := newStorageHook(cfg, hookCtx)
+		register("storage", h, err)
+	}
+	if !manager.HasHooksFor(event) {
+		return
+	}
+
+	fmt.Fprintln(console)
+	for _, err := range manager.Execute(ctx, event, hookCtx) {
+		fmt.Fprintf(console, "   ⚠️  %v\n", err)
+	}
+}
+
+// newMessagingHook creates the messaging hook for the configured broker
+// service, or the only kafka or nats service
+func newMessagingHook(cfg *config.Config) (*messaging.Hook, error) {
+	service := cfg.Hooks.Messaging.Service
+	if service == "" {
+		var brokers []string
+		for _, name := range sortedServiceNames(cfg) {
+			if scheme := cfg.Services[name].Scheme; scheme == messaging.KindKafka || scheme == messaging.KindNATS {
+				brokers = append(brokers, name)
+			}
+		}
+		switch len(brokers) {
+		case 0:
+			return nil, fmt.Errorf("no kafka or nats service found; set hooks.messaging.service")
+		case 1:
+			service = brokers[0]
+		default:
+			return nil, fmt.Errorf("several brokers (%s); set hooks.messaging.service", strings.Join(brokers, ", "))
+		}
+	}
+
+	svc, ok := cfg.Services[service]
+	if !ok {
+		return nil, fmt.Errorf("unknown service %q", service)
+	}
+	return messaging.NewHook(svc.Scheme, service, svc.Port, *cfg.Hooks.Messaging)
+}
+
+// newStorageHook creates the storage hook for the configured MinIO or
+// LocalStack service, or the only service running one of their images
+func newStorageHook(cfg *config.Config, hookCtx *hooks.HookContext) (*storage.Hook, error) {
+	st := cfg.Hooks.Storage
+	project, err := loadComposeProject(hookCtx.WorkDir, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	service := st.Service
+	if service == "" {
+		var candidates []string
+		for _, name := range project.ServiceNames() {
+			if storage.DetectKind(project.Image(name)) != "" {
+				candidates = append(candidates, name)
+			}
+		}
+		switch len(candidates) {
+		case 0:
+			return nil, fmt.Errorf("no minio or localstack service found; set hooks.storage.service")
+		case 1:
+			service = candidates[0]
+		default:
+			return nil, fmt.Errorf("several storage services (%s); set hooks.storage.service", strings.Join(candidates, ", "))
+		}
+	}
+
+	kind := storage.DetectKind(project.Image(service))
+	if kind == "" {
+		return nil, fmt.Errorf("service %q does not run a minio or localstack image", service)
+	}
+
+	accessKey, secretKey := st.AccessKey, st.SecretKey
+	if accessKey == "" && secretKey == "" {
+		env := project.Environment(service)
+		if user := env["MINIO_ROOT_USER"]; user != nil {
+			accessKey = config.InterpolateEnv(fmt.Sprint(user))
+		}
+		if password := env["MINIO_ROOT_PASSWORD"]; password != nil {
+			secretKey = config.InterpolateEnv(fmt.Sprint(password))
+		}
+	}
+	redact.Add(secretKey)
+
+	opts := storage.Options{
+		Kind:      kind,
+		Service:   service,
+		Port:      cfg.Services[service].Port,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Region:    st.Region,
+		Buckets:   st.Buckets,
+		Queues:    st.Queues,
+		EnvFile:   st.EnvFile,
+		Timeout:   st.Timeout,
+	}
+	if _, port := serviceAddress(cfg, service, hookCtx.WorkDir, hookCtx.DNSEnabled); port > 0 {
+		opts.Endpoint = serviceURL(cfg, service, hookCtx.ProjectName, hookCtx.WorkDir, hookCtx.DNSEnabled)
+	}
+	if !st.NoCORS {
+		opts.CORSOrigins = corsOrigins(cfg, hookCtx.ProjectName, hookCtx.WorkDir, hookCtx.DNSEnabled, st.CORSOrigins)
+	}
+	if opts.EnvFile == "" {
+		opts.EnvFile = filepath.Join(stateDir(hookCtx.WorkDir), "storage.env")
+	} else if !filepath.IsAbs(opts.EnvFile) {
+		opts.EnvFile = filepath.Join(hookCtx.WorkDir, opts.EnvFile)
+	}
+	return storage.NewHook(opts)
+}
+
 // createTemplateHooks creates template hook scripts
 func createTemplateHooks(workDir string) error {
 	hooksDir := filepath.Join(workDir, ".space", "hooks")
@@ -490,9 +717,9 @@ func main() {
     for name, svc := range ctx.Services {
         resp, err := http.Get(svc.URL + "/health")
         if err != nil {
-            fmt.Printf("%s: DOWN (%v)\n", name, err)
+            fmt.Fprintf(console, "%s: DOWN (%v)\n", name, err)
         } else {
-            fmt.Printf("%s: %s\n", name, resp.Status)
+            fmt.Fprintf(console, "%s: %s\n", name, resp.Status)
             resp.Body.Close()
         }
     }
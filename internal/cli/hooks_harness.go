@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newHooksTestCommand() *cobra.Command {
+	var event string
+	var fixture string
+	var projectName string
+	var dnsEnabled bool
+	var services []string
+	var expectExit int
+	var expectOutput []string
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "test <script>",
+		Short: "Run a single hook script with a synthesized context",
+		Long: `Run one hook script with a synthesized hook context, without starting
+the stack, and check its exit code and output.
+
+The context is built from .space.yaml like 'space up' does. Use --fixture to
+load a context JSON (the same document scripts receive on stdin) instead, and
+--service/--dns/--project to adjust individual values.
+
+The event defaults to the script's directory (e.g. post-up.d -> post-up).`,
+		Example: `  space hooks test .space/hooks/post-up.d/10-migrate.sh
+  space hooks test ./my-hook.sh --event post-up --dns --service api=8080
+  space hooks test ./my-hook.sh --fixture testdata/context.json --expect-output "migrated"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get working directory
+			workDir := Workdir
+			if workDir == "." {
+				var err error
+				workDir, err = os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get working directory: %w", err)
+				}
+			}
+
+			// Make absolute
+			workDir, err := filepath.Abs(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+
+			script, err := filepath.Abs(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to resolve script path: %w", err)
+			}
+			if _, err := os.Stat(script); err != nil {
+				return fmt.Errorf("script not found: %w", err)
+			}
+
+			hookCtx, fixtureEvent, err := synthesizeHookContext(workDir, fixture, cmd.Flags().Changed("dns"), dnsEnabled)
+			if err != nil {
+				return err
+			}
+			if projectName != "" {
+				hookCtx.ProjectName = projectName
+			}
+			if err := applyServiceOverrides(hookCtx, services); err != nil {
+				return err
+			}
+
+			// Resolve event: flag, fixture, script directory, then post-up
+			eventType := hooks.EventType(event)
+			if eventType == "" {
+				eventType = fixtureEvent
+			}
+			if eventType == "" {
+				eventType = hooks.EventType(strings.TrimSuffix(filepath.Base(filepath.Dir(script)), ".d"))
+			}
+			if !eventType.IsValid() {
+				eventType = hooks.PostUp
+			}
+
+			fmt.Fprintf(console, "🧪 Testing %s (%s)\n", filepath.Base(script), eventType)
+			fmt.Fprintf(console, "   Project: %s, DNS: %t, services: %d\n", hookCtx.ProjectName, hookCtx.DNSEnabled, len(hookCtx.Services))
+			fmt.Fprintln(console)
+
+			var output bytes.Buffer
+			executor := hooks.NewScriptExecutor(workDir)
+			executor.Timeout = timeout
+			executor.Stdout = io.MultiWriter(os.Stdout, &output)
+			executor.Stderr = io.MultiWriter(os.Stderr, &output)
+
+			start := time.Now()
+			runErr := executor.RunScript(context.Background(), eventType, script, hookCtx)
+			elapsed := time.Since(start).Round(time.Millisecond)
+
+			exitCode := 0
+			if runErr != nil {
+				var exitErr *exec.ExitError
+				if !errors.As(runErr, &exitErr) {
+					return runErr
+				}
+				exitCode = exitErr.ExitCode()
+			}
+
+			fmt.Fprintln(console)
+			var failures []string
+			if exitCode != expectExit {
+				failures = append(failures, fmt.Sprintf("exit code %d, expected %d", exitCode, expectExit))
+			}
+			for _, want := range expectOutput {
+				if !strings.Contains(output.String(), want) {
+					failures = append(failures, fmt.Sprintf("output does not contain %q", want))
+				}
+			}
+
+			if len(failures) > 0 {
+				cmd.SilenceUsage = true
+				for _, f := range failures {
+					fmt.Fprintf(console, "❌ %s\n", f)
+				}
+				return fmt.Errorf("hook test failed")
+			}
+
+			fmt.Fprintf(console, "✅ Passed (exit code %d, %s)\n", exitCode, elapsed)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&event, "event", "e", "", "Event to simulate (default: from script directory)")
+	cmd.Flags().StringVarP(&fixture, "fixture", "f", "", "Hook context JSON file to use instead of .space.yaml")
+	cmd.Flags().StringVarP(&projectName, "project", "p", "", "Override the project name")
+	cmd.Flags().BoolVar(&dnsEnabled, "dns", false, "Simulate DNS mode")
+	cmd.Flags().StringArrayVarP(&services, "service", "s", nil, "Add or override a service as name=port")
+	cmd.Flags().IntVar(&expectExit, "expect-exit", 0, "Expected exit code")
+	cmd.Flags().StringArrayVar(&expectOutput, "expect-output", nil, "Text the output must contain (repeatable)")
+	cmd.Flags().DurationVar(&timeout, "timeout", time.Minute, "Script timeout")
+
+	return cmd
+}
+
+// synthesizeHookContext builds a hook context from a fixture file or the
+// project configuration. dnsSet reports whether dnsEnabled was given explicitly.
+func synthesizeHookContext(workDir, fixture string, dnsSet, dnsEnabled bool) (*hooks.HookContext, hooks.EventType, error) {
+	if fixture != "" {
+		data, err := os.ReadFile(fixture)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read fixture: %w", err)
+		}
+		hookCtx, event, err := hooks.HookContextFromJSON(data)
+		if err != nil {
+			return nil, "", err
+		}
+		if hookCtx.WorkDir == "" {
+			hookCtx.WorkDir = workDir
+		}
+		if dnsSet {
+			hookCtx.DNSEnabled = dnsEnabled
+		}
+		return hookCtx, event, nil
+	}
+
+	cfg := config.Defaults()
+	if loader, err := newConfigLoader(workDir); err == nil {
+		if loaded, err := loader.Load(); err == nil {
+			cfg = loaded
+		}
+	}
+	if project, err := loadComposeProject(workDir, cfg); err == nil {
+		applyComposeServices(cfg, project)
+	}
+
+	useDNS := serviceDNSActive(cfg, workDir)
+	if dnsSet {
+		useDNS = dnsEnabled
+	}
+
+	hookCtx := buildHookContext(workDir, generateProjectName(cfg, workDir), cfg, useDNS)
+
+	// The startup logs of the last 'space up', to test analysis hooks on
+	if cfg.Hooks.StartupLogs != nil {
+		if _, err := os.Stat(startupLogsFile(workDir)); err == nil {
+			hookCtx.StartupLogs = startupLogsFile(workDir)
+		}
+	}
+	return hookCtx, "", nil
+}
+
+// applyServiceOverrides adds or updates services given as name=port
+func applyServiceOverrides(hookCtx *hooks.HookContext, specs []string) error {
+	for _, spec := range specs {
+		name, portStr, ok := strings.Cut(spec, "=")
+		port, err := strconv.Atoi(portStr)
+		if !ok || name == "" || err != nil {
+			return fmt.Errorf("invalid --service %q (expected name=port)", spec)
+		}
+
+		host := "localhost"
+		if hookCtx.DNSEnabled {
+			host = name + "." + hookCtx.BaseDomain
+			if hookCtx.Hash != "" {
+				host = fmt.Sprintf("%s-%s.%s", name, hookCtx.Hash, hookCtx.BaseDomain)
+			}
+		}
+
+		svc := &hooks.ServiceInfo{
+			Name:         name,
+			DNSName:      host,
+			Host:         host,
+			InternalPort: port,
+			URL:          fmt.Sprintf("http://%s:%d", host, port),
+		}
+		if !hookCtx.DNSEnabled {
+			svc.ExternalPort = port
+		}
+		hookCtx.Services[name] = svc
+	}
+	return nil
+}
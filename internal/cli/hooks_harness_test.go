@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/hooks"
+)
+
+func TestApplyServiceOverrides(t *testing.T) {
+	hookCtx := hooks.NewHookContext()
+	hookCtx.Hash = "abc123"
+
+	if err := applyServiceOverrides(hookCtx, []string{"web=3000"}); err != nil {
+		t.Fatalf("applyServiceOverrides() error = %v", err)
+	}
+	if svc := hookCtx.Services["web"]; svc.URL != "http://localhost:3000" || svc.ExternalPort != 3000 {
+		t.Errorf("port mode service = %+v", svc)
+	}
+
+	hookCtx.DNSEnabled = true
+	if err := applyServiceOverrides(hookCtx, []string{"api=8080"}); err != nil {
+		t.Fatalf("applyServiceOverrides() error = %v", err)
+	}
+	if svc := hookCtx.Services["api"]; svc.URL != "http://api-abc123.space.local:8080" || svc.ExternalPort != 0 {
+		t.Errorf("DNS mode service = %+v", svc)
+	}
+
+	for _, spec := range []string{"api", "=80", "api=http"} {
+		if err := applyServiceOverrides(hookCtx, []string{spec}); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
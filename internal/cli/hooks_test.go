@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestNewMessagingHook(t *testing.T) {
+	topics := []config.MessagingTopicConfig{{Name: "orders"}}
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"api":   {Port: 8080},
+			"kafka": {Port: 9092, Protocol: "tcp", Scheme: "kafka"},
+		},
+		Hooks: config.HooksConfig{Messaging: &config.MessagingHooksConfig{Topics: topics}},
+	}
+	if _, err := newMessagingHook(cfg); err != nil {
+		t.Errorf("newMessagingHook() with one broker: %v", err)
+	}
+
+	cfg.Services["nats"] = config.ServiceConfig{Port: 4222, Protocol: "tcp", Scheme: "nats"}
+	if _, err := newMessagingHook(cfg); err == nil || !strings.Contains(err.Error(), "kafka, nats") {
+		t.Errorf("expected ambiguous brokers to fail, got %v", err)
+	}
+
+	cfg.Hooks.Messaging.Service = "nats"
+	if _, err := newMessagingHook(cfg); err != nil {
+		t.Errorf("newMessagingHook() with service: %v", err)
+	}
+
+	cfg.Hooks.Messaging.Service = "api"
+	if _, err := newMessagingHook(cfg); err == nil {
+		t.Error("expected non-broker service to fail")
+	}
+}
+
+func TestNewStorageHook(t *testing.T) {
+	workDir := t.TempDir()
+	compose := `services:
+  minio:
+    image: minio/minio:latest
+    ports: ["9000"]
+    environment:
+      MINIO_ROOT_USER: admin
+      MINIO_ROOT_PASSWORD: ${MINIO_PASSWORD:-secret}
+  db:
+    image: postgres:16
+`
+	if err := os.WriteFile(filepath.Join(workDir, "docker-compose.yml"), []byte(compose), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{"minio": {Port: 9000}},
+		Hooks:    config.HooksConfig{Storage: &config.StorageHooksConfig{Buckets: []string{"uploads"}}},
+	}
+	hookCtx := hooks.NewHookContext()
+	hookCtx.WorkDir = workDir
+	hookCtx.ProjectName = "shop"
+
+	h, err := newStorageHook(cfg, hookCtx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := h.EnvVars()
+	if vars["AWS_ACCESS_KEY_ID"] != "admin" || vars["AWS_SECRET_ACCESS_KEY"] != "secret" {
+		t.Errorf("credentials from compose = %v", vars)
+	}
+
+	cfg.Hooks.Storage.Service = "db"
+	if _, err := newStorageHook(cfg, hookCtx); err == nil {
+		t.Error("expected non-storage service to fail")
+	}
+
+	cfg.Hooks.Storage = &config.StorageHooksConfig{Queues: []string{"jobs"}}
+	if _, err := newStorageHook(cfg, hookCtx); err == nil || !strings.Contains(err.Error(), "LocalStack") {
+		t.Errorf("expected queues on MinIO to fail, got %v", err)
+	}
+}
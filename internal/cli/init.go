@@ -0,0 +1,337 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/detect"
+	"github.com/happy-sdk/space-cli/internal/templates"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// newInitCommand creates the init command
+func newInitCommand() *cobra.Command {
+	var fromTemplate string
+	var vars []string
+	var force bool
+	var yes bool
+	var noDetect bool
+	var withCompose bool
+
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Set up space for a project, optionally from a template",
+		Long: `Set up space in the working directory.
+
+Without --from-template a .space.yaml is written from what the project's
+manifests suggest: package.json scripts and lockfiles, go.mod, Gemfile,
+pyproject.toml and requirements.txt, in the working directory and its direct
+subdirectories. Dev servers become services on their usual ports, client
+libraries of Postgres, MySQL, Redis and MongoDB add those databases, and the
+Vite hook is enabled for Vite apps. --compose also writes compose stubs for
+them. --no-detect writes a minimal .space.yaml instead.
+
+With --from-template a template
+directory holding .space.yaml, hooks, commands and compose snippets is
+fetched and rendered into the project. Templates are referenced as
+
+  <repo>[//<subdir>][?ref=<branch or tag>]
+
+where repo is a git URL or a local directory. Files ending in .tmpl are
+rendered with Go templates (the suffix is dropped); everything else is
+copied as is. A space-template.yaml in the template declares the variables
+to ask for:
+
+  description: Rails with Postgres
+  variables:
+    - name: project_name
+      prompt: Project name
+      default: "{{ .dir }}"
+    - name: web_port
+      default: "3000"
+
+{{ .dir }} is the name of the working directory. Existing files are never
+overwritten unless --force is given.`,
+		Example: `  space init
+  space init --compose
+  space init --from-template git@github.com:org/space-templates//rails-postgres
+  space init --from-template https://github.com/org/space-templates//go-api?ref=v2 --var web_port=8080 --yes`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workDir, err := filepath.Abs(Workdir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+
+			if fromTemplate == "" {
+				return initSpaceConfig(workDir, filepath.Base(workDir), !noDetect, withCompose, force)
+			}
+
+			preset, err := parseTemplateVars(vars)
+			if err != nil {
+				return err
+			}
+
+			var ask func(prompt, def string) (string, error)
+			if !yes && isInteractive() {
+				ask = promptWithDefault(os.Stdin)
+			}
+
+			return initFromTemplate(context.Background(), workDir, fromTemplate, preset, ask, force)
+		},
+	}
+
+	cmd.Flags().StringVarP(&fromTemplate, "from-template", "t", "", "Template to render: <repo>[//<subdir>][?ref=<ref>]")
+	cmd.Flags().StringArrayVar(&vars, "var", nil, "Set a template variable without asking (key=value, repeatable)")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite existing files")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Use defaults for variables not set with --var")
+	cmd.Flags().BoolVar(&noDetect, "no-detect", false, "Write a minimal .space.yaml without analyzing the project")
+	cmd.Flags().BoolVar(&withCompose, "compose", false, "Also add stubs for the detected services and databases to the compose file")
+
+	return cmd
+}
+
+// spaceConfigContent is the minimal .space.yaml written by init
+func spaceConfigContent(name string) string {
+	return fmt.Sprintf(`# space-cli configuration, see 'space config docs' for every option
+project:
+  name: %s
+`, name)
+}
+
+// initSpaceConfig writes a .space.yaml naming the project in workDir, with
+// the services, databases and hooks its manifests suggest when detect is set
+func initSpaceConfig(workDir, name string, detectStack, withCompose, force bool) error {
+	path := filepath.Join(workDir, ".space.yaml")
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf(".space.yaml already exists (use --force to overwrite)")
+	}
+
+	content := []byte(spaceConfigContent(name))
+	analysis := &detect.Analysis{}
+	if detectStack {
+		var err error
+		if analysis, err = detect.Analyze(workDir); err != nil {
+			return err
+		}
+		if content, err = detectedConfigContent(name, analysis); err != nil {
+			return err
+		}
+	}
+
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write .space.yaml: %w", err)
+	}
+
+	if len(analysis.Findings) > 0 {
+		fmt.Fprintln(console, "🔍 Detected:")
+		for _, finding := range analysis.Findings {
+			fmt.Fprintf(console, "   %s\n", finding)
+		}
+		fmt.Fprintln(console)
+	}
+	fmt.Fprintf(console, "✅ Created %s\n", path)
+
+	if withCompose {
+		if err := addInitComposeStubs(workDir, analysis); err != nil {
+			return err
+		}
+	}
+
+	if analysis.Next {
+		tipf("Next.js refuses dev requests from other hosts; add allowedDevOrigins: ['*.%s'] to next.config for DNS URLs", config.DefaultBaseDomain)
+	}
+	tipf("Run 'space hooks init' to add hook and command directories")
+	return nil
+}
+
+// detectedConfigContent returns a .space.yaml with the services and
+// databases of an analysis and, for Vite apps, the Vite hook enabled
+func detectedConfigContent(name string, a *detect.Analysis) ([]byte, error) {
+	content := spaceConfigContent(name)
+	if a.Vite {
+		content += `hooks:
+  vite:
+    enabled: true
+    auto_detect: true
+`
+	}
+
+	data := []byte(content)
+	for _, svc := range a.Services {
+		var err error
+		data, err = config.AddService(data, svc.Name, config.ServiceConfig{Port: svc.Port})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, db := range a.Databases {
+		if containsString(serviceNames(a), db) {
+			continue
+		}
+		svc := config.ServiceConfig{
+			Port:        detect.DatabaseDefaults[db].Port,
+			Protocol:    config.ProtocolTCP,
+			Scheme:      db,
+			Environment: detect.DatabaseDefaults[db].Environment,
+		}
+		var err error
+		if data, err = config.AddService(data, db, svc); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// serviceNames returns the names of the services of an analysis
+func serviceNames(a *detect.Analysis) []string {
+	var names []string
+	for _, svc := range a.Services {
+		names = append(names, svc.Name)
+	}
+	return names
+}
+
+// addInitComposeStubs adds the detected databases and the app in the
+// project directory to the compose file, skipping services it defines.
+// Apps in subdirectories need a build context compose stubs do not set.
+func addInitComposeStubs(workDir string, a *detect.Analysis) error {
+	cfg := config.Defaults()
+	var defined []string
+	if project, err := compose.Load(workDir, composeFilesFor(workDir, cfg)); err == nil {
+		defined = project.ServiceNames()
+	}
+
+	var added []string
+	var file string
+	add := func(name, image string, port int) error {
+		if containsString(defined, name) {
+			return nil
+		}
+		var err error
+		if file, err = addComposeStub(workDir, cfg, name, image, port); err != nil {
+			return err
+		}
+		added = append(added, name)
+		return nil
+	}
+
+	for _, svc := range a.Services {
+		if svc.Dir != "." {
+			fmt.Fprintf(console, "⚠️  Add %s to the compose file by hand, building ./%s\n", svc.Name, svc.Dir)
+			continue
+		}
+		if err := add(svc.Name, "", svc.Port); err != nil {
+			return err
+		}
+	}
+	for _, db := range a.Databases {
+		if err := add(db, detect.DatabaseDefaults[db].Image, detect.DatabaseDefaults[db].Port); err != nil {
+			return err
+		}
+	}
+
+	if len(added) > 0 {
+		fmt.Fprintf(console, "✅ Added %s to %s\n", strings.Join(added, ", "), filepath.Base(file))
+	}
+	return nil
+}
+
+// initFromTemplate fetches a template, resolves its variables and writes the
+// rendered files into workDir
+func initFromTemplate(ctx context.Context, workDir, rawSource string, preset map[string]string, ask func(prompt, def string) (string, error), force bool) error {
+	source, err := templates.ParseSource(rawSource)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(console, "📥 Fetching template %s\n", source)
+	var dir string
+	cleanup := func() {}
+	err = step("template fetch", false, func() error {
+		var err error
+		dir, cleanup, err = templates.Fetch(ctx, source)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	manifest, err := templates.LoadManifest(dir)
+	if err != nil {
+		return err
+	}
+	if manifest.Description != "" {
+		fmt.Fprintf(console, "📋 %s\n", manifest.Description)
+	}
+	fmt.Fprintln(console)
+
+	builtins := map[string]string{"dir": filepath.Base(workDir)}
+	values, err := manifest.Resolve(builtins, preset, ask)
+	if err != nil {
+		return err
+	}
+
+	files, err := templates.Render(dir, values)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("template %s has no files", source)
+	}
+
+	if conflicts := templates.Conflicts(workDir, files); len(conflicts) > 0 && !force {
+		return fmt.Errorf("template would overwrite existing files (use --force): %s", strings.Join(conflicts, ", "))
+	}
+
+	if err := templates.Write(workDir, files); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(console, "✅ Wrote %d file(s) from %s:\n", len(files), source)
+	for _, f := range files {
+		fmt.Fprintf(console, "   %s\n", f.Path)
+	}
+	fmt.Fprintln(console)
+	tipf("Run 'space config validate' to check the result, then 'space up'")
+	return nil
+}
+
+// parseTemplateVars parses key=value pairs given with --var
+func parseTemplateVars(vars []string) (map[string]string, error) {
+	preset := make(map[string]string, len(vars))
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var %q, expected key=value", v)
+		}
+		preset[key] = value
+	}
+	return preset, nil
+}
+
+// promptWithDefault asks for template variables on the terminal, showing the
+// default in brackets
+func promptWithDefault(in io.Reader) func(prompt, def string) (string, error) {
+	reader := bufio.NewReader(in)
+	return func(prompt, def string) (string, error) {
+		if def != "" {
+			fmt.Fprintf(console, "❓ %s [%s]: ", prompt, def)
+		} else {
+			fmt.Fprintf(console, "❓ %s: ", prompt)
+		}
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			return "", fmt.Errorf("failed to read answer: %w", err)
+		}
+		return strings.TrimSpace(answer), nil
+	}
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestInitFromTemplate(t *testing.T) {
+	tmpl := filepath.Join(t.TempDir(), "go-api")
+	if err := os.MkdirAll(tmpl, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"space-template.yaml": "variables:\n  - name: project_name\n    default: \"{{ .dir }}\"\n",
+		".space.yaml.tmpl":    "project:\n  name: {{ .project_name }}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpl, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	workDir := filepath.Join(t.TempDir(), "shop")
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := initFromTemplate(context.Background(), workDir, tmpl, nil, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(workDir, ".space.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "project:\n  name: shop\n" {
+		t.Errorf(".space.yaml = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "space-template.yaml")); !os.IsNotExist(err) {
+		t.Error("template manifest was copied into the project")
+	}
+
+	// Existing files are kept without --force
+	err = initFromTemplate(context.Background(), workDir, tmpl, map[string]string{"project_name": "other"}, nil, false)
+	if err == nil || !strings.Contains(err.Error(), ".space.yaml") {
+		t.Errorf("second init error = %v, want conflict on .space.yaml", err)
+	}
+
+	if err := initFromTemplate(context.Background(), workDir, tmpl, map[string]string{"project_name": "other"}, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(workDir, ".space.yaml")); string(data) != "project:\n  name: other\n" {
+		t.Errorf(".space.yaml after --force = %q", data)
+	}
+}
+
+func TestParseTemplateVars(t *testing.T) {
+	got, err := parseTemplateVars([]string{"web_port=8080", "dsn=postgres://u:p@db/x?a=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["web_port"] != "8080" || got["dsn"] != "postgres://u:p@db/x?a=b" {
+		t.Errorf("parseTemplateVars() = %v", got)
+	}
+
+	if _, err := parseTemplateVars([]string{"novalue"}); err == nil {
+		t.Error("parseTemplateVars() accepted a pair without =")
+	}
+}
+
+func TestInitSpaceConfigDetects(t *testing.T) {
+	workDir := t.TempDir()
+	files := map[string]string{
+		"package.json": `{"scripts": {"dev": "vite"}, "devDependencies": {"vite": "^5"}, "dependencies": {"pg": "^8"}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := initSpaceConfig(workDir, "shop", true, true, false); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(workDir, ".space.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg config.Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Services["web"].Port != 5173 {
+		t.Errorf("web = %+v, want port 5173", cfg.Services["web"])
+	}
+	if db := cfg.Services["postgres"]; db.Port != 5432 || db.Protocol != config.ProtocolTCP || db.Environment["POSTGRES_PASSWORD"] == "" {
+		t.Errorf("postgres = %+v", db)
+	}
+	if cfg.Hooks.Vite == nil || !cfg.Hooks.Vite.Enabled {
+		t.Error("vite hook not enabled")
+	}
+
+	data, err = os.ReadFile(filepath.Join(workDir, "docker-compose.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"web:", "build: .", "postgres:", "image: postgres:16"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("docker-compose.yml misses %q:\n%s", want, data)
+		}
+	}
+
+	if err := initSpaceConfig(workDir, "shop", true, false, false); err == nil {
+		t.Error("initSpaceConfig() overwrote .space.yaml without force")
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// ServiceLink is a rendered service URL
+type ServiceLink struct {
+	Service string `json:"service"`
+	URL     string `json:"url"`
+	DNS     bool   `json:"dns"`
+}
+
+// newLinksCommand creates the links command
+func newLinksCommand() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "links",
+		Short: "Show service URLs",
+		Long: `Display the URL of every service, rendered from each service's url_template.
+
+DNS URLs (*.space.local) are shown while the DNS daemon is running,
+localhost URLs otherwise.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get working directory
+			workDir := Workdir
+			if workDir == "." {
+				var err error
+				workDir, err = os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get working directory: %w", err)
+				}
+			}
+
+			// Make absolute
+			workDir, err := filepath.Abs(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+
+			// Create loader
+			loader, err := newConfigLoader(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to create config loader: %w", err)
+			}
+
+			// Load configuration
+			cfg, err := loader.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+
+			registerSensitiveValues(cfg)
+
+			if project, err := loadComposeProject(workDir, cfg); err == nil {
+				applyComposeServices(cfg, project)
+			}
+
+			links := collectServiceLinks(cfg, generateProjectName(cfg, workDir), workDir, serviceDNSActive(cfg, workDir))
+
+			if jsonOutput {
+				encoder := json.NewEncoder(redact.NewWriter(os.Stdout))
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(links)
+			}
+
+			if len(links) == 0 {
+				fmt.Fprintln(console, "No services with ports found.")
+				tipf("Define services with a port in .space.yaml")
+				return nil
+			}
+
+			w := newTable(console)
+			fmt.Fprintln(w, "SERVICE\tURL")
+			fmt.Fprintln(w, "-------\t---")
+			for _, link := range links {
+				fmt.Fprintf(w, "%s\t%s\n", link.Service, redact.String(link.URL))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+
+	return cmd
+}
+
+// collectServiceLinks renders the URL of every service that exposes a port
+func collectServiceLinks(cfg *config.Config, projectName, workDir string, useDNS bool) []ServiceLink {
+	links := make([]ServiceLink, 0, len(cfg.Services))
+	for _, name := range sortedServiceNames(cfg) {
+		if _, port := serviceAddress(cfg, name, workDir, useDNS); port == 0 {
+			continue
+		}
+		links = append(links, ServiceLink{
+			Service: name,
+			URL:     serviceURL(cfg, name, projectName, workDir, useDNS),
+			DNS:     useDNS,
+		})
+	}
+	return links
+}
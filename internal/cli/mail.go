@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// mailComposeFile is the generated override adding the mail catcher
+const mailComposeFile = ".space-mail-compose.yml"
+
+// mailService is the Mailpit service the mail preset adds
+const mailService = "mailpit"
+
+// Mailpit ports: SMTP for the services, the web UI for the host
+const (
+	mailSMTPPort = 1025
+	mailUIPort   = 8025
+)
+
+// mailServices returns the services declaring mail: true, in stable order
+func mailServices(cfg *config.Config) []string {
+	var names []string
+	for _, name := range sortedServiceNames(cfg) {
+		if cfg.Services[name].Mail {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// applyMailService registers Mailpit as a service when any service sends
+// mail, so links, ps and health show its web UI
+func applyMailService(cfg *config.Config) {
+	if len(mailServices(cfg)) == 0 {
+		return
+	}
+	if _, ok := cfg.Services[mailService]; ok {
+		return
+	}
+	cfg.Services[mailService] = config.ServiceConfig{Port: mailUIPort}
+}
+
+// mailOverride builds the Mailpit service and the SMTP environment of the
+// services declaring mail: true. Variables a service already sets in compose
+// or .space.yml are left alone.
+func mailOverride(cfg *config.Config, project *compose.Project, useDNS bool) (map[string]interface{}, []string, error) {
+	if _, ok := project.Services()[mailService]; ok {
+		return nil, nil, fmt.Errorf("compose already defines %s; remove mail: true or the service", mailService)
+	}
+
+	mailpit := map[string]interface{}{
+		"image": "axllent/mailpit:latest",
+	}
+	// Without DNS the web UI is reached through a published port
+	if !useDNS {
+		mailpit["ports"] = []string{fmt.Sprintf("%d:%d", mailUIPort, mailUIPort)}
+	}
+	services := map[string]interface{}{mailService: mailpit}
+
+	senders := mailServices(cfg)
+	for _, name := range senders {
+		if _, ok := project.Services()[name]; !ok {
+			return nil, nil, fmt.Errorf("service %s declares mail but is not a compose service", name)
+		}
+
+		existing := project.Environment(name)
+		environment := make(map[string]string)
+		for key, value := range map[string]string{
+			"SMTP_HOST": mailService,
+			"SMTP_PORT": strconv.Itoa(mailSMTPPort),
+		} {
+			_, inCompose := existing[key]
+			_, inConfig := cfg.Services[name].Environment[key]
+			if !inCompose && !inConfig {
+				environment[key] = value
+			}
+		}
+		if len(environment) > 0 {
+			services[name] = map[string]interface{}{
+				"environment": environment,
+				"depends_on":  []string{mailService},
+			}
+		}
+	}
+	return services, senders, nil
+}
+
+// writeMailOverride writes the mail preset as a compose override. It returns
+// the override path and the services sending mail, or "" when none does.
+func writeMailOverride(workDir string, cfg *config.Config, project *compose.Project, useDNS bool) (string, []string, error) {
+	if len(mailServices(cfg)) == 0 || project == nil {
+		return "", nil, nil
+	}
+
+	services, senders, err := mailOverride(cfg, project, useDNS)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{"services": services})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal mail override: %w", err)
+	}
+
+	header := "# Auto-generated mail catcher from .space.yml mail settings\n"
+	overrideFile := filepath.Join(workDir, mailComposeFile)
+	if _, err := writeGenerated(overrideFile, header, data); err != nil {
+		return "", nil, fmt.Errorf("failed to write mail override: %w", err)
+	}
+
+	sort.Strings(senders)
+	return overrideFile, senders, nil
+}
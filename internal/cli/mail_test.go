@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func mailProject() *compose.Project {
+	return &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api": map[string]interface{}{"image": "api:latest"},
+			"worker": map[string]interface{}{
+				"image":       "worker:latest",
+				"environment": map[string]interface{}{"SMTP_HOST": "relay"},
+			},
+			"web": map[string]interface{}{"image": "web:latest"},
+		},
+	}}
+}
+
+func TestMailOverride(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"api":    {Port: 8080, Mail: true},
+			"worker": {Mail: true, Environment: map[string]string{"SMTP_PORT": "2525"}},
+			"web":    {Port: 3000},
+		},
+	}
+
+	services, senders, err := mailOverride(cfg, mailProject(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"api", "worker"}; !reflect.DeepEqual(senders, want) {
+		t.Errorf("senders = %v, want %v", senders, want)
+	}
+
+	mailpit := services[mailService].(map[string]interface{})
+	if ports := mailpit["ports"].([]string); !reflect.DeepEqual(ports, []string{"8025:8025"}) {
+		t.Errorf("mailpit ports = %v", ports)
+	}
+
+	api := services["api"].(map[string]interface{})["environment"].(map[string]string)
+	if api["SMTP_HOST"] != mailService || api["SMTP_PORT"] != "1025" {
+		t.Errorf("api environment = %v", api)
+	}
+	// Variables set in compose or .space.yml are kept
+	if _, ok := services["worker"]; ok {
+		t.Errorf("worker override = %v, want none", services["worker"])
+	}
+	if _, ok := services["web"]; ok {
+		t.Error("web does not send mail")
+	}
+
+	// With DNS the web UI is not published
+	services, _, err = mailOverride(cfg, mailProject(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := services[mailService].(map[string]interface{})["ports"]; ok {
+		t.Error("mailpit ports published in DNS mode")
+	}
+}
+
+func TestMailOverrideErrors(t *testing.T) {
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{"mailer": {Mail: true}}}
+	if _, _, err := mailOverride(cfg, mailProject(), false); err == nil {
+		t.Error("expected non-compose sender to fail")
+	}
+
+	cfg = &config.Config{Services: map[string]config.ServiceConfig{"api": {Mail: true}}}
+	project := mailProject()
+	project.Data["services"].(map[string]interface{})[mailService] = map[string]interface{}{"image": "axllent/mailpit"}
+	if _, _, err := mailOverride(cfg, project, false); err == nil {
+		t.Error("expected existing mailpit service to fail")
+	}
+}
+
+func TestMailServiceLink(t *testing.T) {
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{"api": {Port: 8080, Mail: true}}}
+	applyComposeServices(cfg, mailProject())
+
+	for _, link := range collectServiceLinks(cfg, "shop", t.TempDir(), false) {
+		if link.Service == mailService {
+			if link.URL != "http://localhost:8025" {
+				t.Errorf("mailpit URL = %s", link.URL)
+			}
+			return
+		}
+	}
+	t.Error("mailpit missing from links")
+}
@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// observabilityComposeFile is the generated override adding the tracing services
+const observabilityComposeFile = ".space-otel-compose.yml"
+
+// Services added by the observability preset
+const (
+	otelCollectorService = "otel-collector"
+	jaegerService        = "jaeger"
+	tempoService         = "tempo"
+	grafanaService       = "grafana"
+)
+
+// traceUIHostPort publishes the trace UI of either backend without DNS
+const traceUIHostPort = 16686
+
+// otlpEndpoint is where instrumented services send traces (OTLP over HTTP)
+const otlpEndpoint = "http://" + otelCollectorService + ":4318"
+
+// traceUIService returns the service serving the trace UI of the backend
+func traceUIService(cfg *config.Config) string {
+	if cfg.Observability.TracingBackend() == config.TracingTempo {
+		return grafanaService
+	}
+	return jaegerService
+}
+
+// observabilityServiceNames returns the services the preset adds
+func observabilityServiceNames(cfg *config.Config) []string {
+	if cfg.Observability.TracingBackend() == config.TracingTempo {
+		return []string{otelCollectorService, tempoService, grafanaService}
+	}
+	return []string{otelCollectorService, jaegerService}
+}
+
+// applyObservabilityServices registers the trace UI as a service, so links,
+// ps and health show its URL
+func applyObservabilityServices(cfg *config.Config) {
+	if !cfg.Observability.Enabled {
+		return
+	}
+
+	name := traceUIService(cfg)
+	if _, ok := cfg.Services[name]; ok {
+		return
+	}
+
+	port := 16686
+	if name == grafanaService {
+		port = 3000
+	}
+	if cfg.Services == nil {
+		cfg.Services = make(map[string]config.ServiceConfig)
+	}
+	cfg.Services[name] = config.ServiceConfig{Port: port, ExternalPort: traceUIHostPort}
+}
+
+// tracedServices returns the services that export traces: the configured
+// ones, or every HTTP service of the compose project
+func tracedServices(cfg *config.Config, project *compose.Project) ([]string, error) {
+	if len(cfg.Observability.Services) > 0 {
+		names, err := cfg.ExpandGroups(cfg.Observability.Services)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if _, ok := project.Services()[name]; !ok {
+				return nil, fmt.Errorf("observability.services: %s is not a compose service", name)
+			}
+		}
+		return names, nil
+	}
+
+	preset := make(map[string]bool)
+	for _, name := range observabilityServiceNames(cfg) {
+		preset[name] = true
+	}
+
+	var names []string
+	for _, name := range project.ServiceNames() {
+		svc, ok := cfg.Services[name]
+		if preset[name] || !ok || svc.Port == 0 || svc.EffectiveProtocol() != config.ProtocolHTTP {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// observabilityOverride builds the compose services of the preset, reading
+// their configuration files from configDir, and the OTLP environment of the
+// traced services. Variables a service already sets are left alone.
+func observabilityOverride(cfg *config.Config, project *compose.Project, configDir string, useDNS bool) (map[string]interface{}, []string, error) {
+	for _, name := range observabilityServiceNames(cfg) {
+		if _, ok := project.Services()[name]; ok {
+			return nil, nil, fmt.Errorf("compose already defines %s; disable observability or rename the service", name)
+		}
+	}
+
+	traced, err := tracedServices(cfg, project)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Without DNS the UI is reached through a published port
+	publish := func(port int) []string {
+		if useDNS {
+			return nil
+		}
+		return []string{fmt.Sprintf("%d:%d", traceUIHostPort, port)}
+	}
+
+	services := map[string]interface{}{
+		otelCollectorService: map[string]interface{}{
+			"image":   "otel/opentelemetry-collector-contrib:latest",
+			"command": []string{"--config=/etc/otelcol/config.yaml"},
+			"volumes": []string{filepath.Join(configDir, "otel-collector.yaml") + ":/etc/otelcol/config.yaml:ro"},
+		},
+	}
+
+	switch cfg.Observability.TracingBackend() {
+	case config.TracingTempo:
+		services[tempoService] = map[string]interface{}{
+			"image":   "grafana/tempo:latest",
+			"command": []string{"-config.file=/etc/tempo.yaml"},
+			"volumes": []string{filepath.Join(configDir, "tempo.yaml") + ":/etc/tempo.yaml:ro"},
+		}
+		grafana := map[string]interface{}{
+			"image": "grafana/grafana:latest",
+			"environment": map[string]string{
+				"GF_AUTH_ANONYMOUS_ENABLED":  "true",
+				"GF_AUTH_ANONYMOUS_ORG_ROLE": "Admin",
+				"GF_AUTH_DISABLE_LOGIN_FORM": "true",
+			},
+			"volumes":    []string{filepath.Join(configDir, "grafana-datasources.yaml") + ":/etc/grafana/provisioning/datasources/space.yaml:ro"},
+			"depends_on": []string{tempoService},
+		}
+		if ports := publish(3000); ports != nil {
+			grafana["ports"] = ports
+		}
+		services[grafanaService] = grafana
+	default:
+		jaeger := map[string]interface{}{
+			"image":       "jaegertracing/all-in-one:latest",
+			"environment": map[string]string{"COLLECTOR_OTLP_ENABLED": "true"},
+		}
+		if ports := publish(16686); ports != nil {
+			jaeger["ports"] = ports
+		}
+		services[jaegerService] = jaeger
+	}
+
+	for _, name := range traced {
+		existing := project.Environment(name)
+		environment := make(map[string]string)
+		for key, value := range map[string]string{
+			"OTEL_EXPORTER_OTLP_ENDPOINT": otlpEndpoint,
+			"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf",
+			"OTEL_SERVICE_NAME":           name,
+		} {
+			if _, ok := existing[key]; !ok {
+				environment[key] = value
+			}
+		}
+		if len(environment) > 0 {
+			services[name] = map[string]interface{}{"environment": environment}
+		}
+	}
+	return services, traced, nil
+}
+
+// observabilityConfigs returns the configuration files of the preset services
+func observabilityConfigs(cfg *config.Config) map[string]string {
+	backend := jaegerService
+	if cfg.Observability.TracingBackend() == config.TracingTempo {
+		backend = tempoService
+	}
+
+	files := map[string]string{
+		"otel-collector.yaml": fmt.Sprintf(`receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+      http:
+        endpoint: 0.0.0.0:4318
+        cors:
+          allowed_origins: ["*"]
+processors:
+  batch: {}
+exporters:
+  otlp:
+    endpoint: %s:4317
+    tls:
+      insecure: true
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [batch]
+      exporters: [otlp]
+`, backend),
+	}
+
+	if backend == tempoService {
+		files["tempo.yaml"] = `server:
+  http_listen_port: 3200
+distributor:
+  receivers:
+    otlp:
+      protocols:
+        grpc:
+          endpoint: 0.0.0.0:4317
+storage:
+  trace:
+    backend: local
+    local:
+      path: /var/tempo/blocks
+    wal:
+      path: /var/tempo/wal
+`
+		files["grafana-datasources.yaml"] = `apiVersion: 1
+datasources:
+  - name: Tempo
+    type: tempo
+    access: proxy
+    url: http://tempo:3200
+    isDefault: true
+`
+	}
+	return files
+}
+
+// writeObservabilityOverride writes the tracing preset as a compose override
+// with its configuration files under .space/state. It returns the override
+// path and the traced services, or "" when observability is disabled.
+func writeObservabilityOverride(workDir string, cfg *config.Config, project *compose.Project, useDNS bool) (string, []string, error) {
+	if !cfg.Observability.Enabled || project == nil {
+		return "", nil, nil
+	}
+
+	configDir := filepath.Join(stateDir(workDir), "otel")
+	services, traced, err := observabilityOverride(cfg, project, configDir, useDNS)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for name, content := range observabilityConfigs(cfg) {
+		if err := writeFile(filepath.Join(configDir, name), []byte(content), 0644); err != nil {
+			return "", nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{"services": services})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal observability override: %w", err)
+	}
+
+	header := "# Auto-generated tracing services from .space.yml observability settings\n"
+	overrideFile := filepath.Join(workDir, observabilityComposeFile)
+	if _, err := writeGenerated(overrideFile, header, data); err != nil {
+		return "", nil, fmt.Errorf("failed to write observability override: %w", err)
+	}
+
+	sort.Strings(traced)
+	return overrideFile, traced, nil
+}
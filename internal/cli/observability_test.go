@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func observabilityProject() *compose.Project {
+	return &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api": map[string]interface{}{"image": "api:latest"},
+			"web": map[string]interface{}{
+				"image":       "web:latest",
+				"environment": map[string]interface{}{"OTEL_SERVICE_NAME": "frontend"},
+			},
+			"db": map[string]interface{}{"image": "postgres:16"},
+		},
+	}}
+}
+
+func TestObservabilityOverride(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"api": {Port: 8080},
+			"web": {Port: 3000},
+			"db":  {Port: 5432, Protocol: "tcp"},
+		},
+		Observability: config.ObservabilityConfig{Enabled: true},
+	}
+
+	services, traced, err := observabilityOverride(cfg, observabilityProject(), "/state/otel", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"api", "web"}; !reflect.DeepEqual(traced, want) {
+		t.Errorf("traced = %v, want %v", traced, want)
+	}
+
+	jaeger := services[jaegerService].(map[string]interface{})
+	if ports := jaeger["ports"].([]string); !reflect.DeepEqual(ports, []string{"16686:16686"}) {
+		t.Errorf("jaeger ports = %v", ports)
+	}
+	collector := services[otelCollectorService].(map[string]interface{})
+	if volumes := collector["volumes"].([]string); !strings.HasPrefix(volumes[0], "/state/otel/otel-collector.yaml:") {
+		t.Errorf("collector volumes = %v", volumes)
+	}
+
+	api := services["api"].(map[string]interface{})["environment"].(map[string]string)
+	if api["OTEL_EXPORTER_OTLP_ENDPOINT"] != otlpEndpoint || api["OTEL_SERVICE_NAME"] != "api" {
+		t.Errorf("api environment = %v", api)
+	}
+	// Variables set in compose are kept
+	web := services["web"].(map[string]interface{})["environment"].(map[string]string)
+	if _, ok := web["OTEL_SERVICE_NAME"]; ok {
+		t.Errorf("web environment overrides OTEL_SERVICE_NAME: %v", web)
+	}
+	if _, ok := services["db"]; ok {
+		t.Error("db should not be traced")
+	}
+
+	// With DNS the UI is not published
+	services, _, err = observabilityOverride(cfg, observabilityProject(), "/state/otel", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := services[jaegerService].(map[string]interface{})["ports"]; ok {
+		t.Error("jaeger ports published in DNS mode")
+	}
+}
+
+func TestObservabilityOverrideTempo(t *testing.T) {
+	cfg := &config.Config{
+		Services:      map[string]config.ServiceConfig{"api": {Port: 8080}},
+		Observability: config.ObservabilityConfig{Enabled: true, Backend: config.TracingTempo, Services: []string{"db"}},
+	}
+
+	services, traced, err := observabilityOverride(cfg, observabilityProject(), "/state/otel", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(traced, []string{"db"}) {
+		t.Errorf("traced = %v, want configured services", traced)
+	}
+	for _, name := range []string{otelCollectorService, tempoService, grafanaService} {
+		if _, ok := services[name]; !ok {
+			t.Errorf("missing %s", name)
+		}
+	}
+	if !strings.Contains(observabilityConfigs(cfg)["otel-collector.yaml"], "endpoint: tempo:4317") {
+		t.Error("collector does not export to tempo")
+	}
+
+	cfg.Observability.Services = []string{"missing"}
+	if _, _, err := observabilityOverride(cfg, observabilityProject(), "/state/otel", false); err == nil {
+		t.Error("expected unknown service to fail")
+	}
+}
+
+func TestObservabilityOverrideConflict(t *testing.T) {
+	cfg := &config.Config{Observability: config.ObservabilityConfig{Enabled: true}}
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{"jaeger": map[string]interface{}{"image": "jaegertracing/all-in-one"}},
+	}}
+	if _, _, err := observabilityOverride(cfg, project, "/state/otel", false); err == nil {
+		t.Error("expected existing jaeger service to fail")
+	}
+}
+
+func TestObservabilityLinksAndDown(t *testing.T) {
+	workDir := t.TempDir()
+	cfg := &config.Config{
+		Services:      map[string]config.ServiceConfig{"api": {Port: 8080}},
+		Observability: config.ObservabilityConfig{Enabled: true},
+	}
+	project := observabilityProject()
+	applyComposeServices(cfg, project)
+
+	links := collectServiceLinks(cfg, "shop", workDir, false)
+	var found bool
+	for _, link := range links {
+		if link.Service == jaegerService && link.URL == "http://localhost:16686" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("trace UI missing from links: %v", links)
+	}
+
+	path, _, err := writeObservabilityOverride(workDir, cfg, project, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(stateDir(workDir), "otel", "otel-collector.yaml")); err != nil {
+		t.Errorf("collector config not written: %v", err)
+	}
+	if files := withGeneratedServices(workDir, []string{"docker-compose.yml"}); !reflect.DeepEqual(files, []string{"docker-compose.yml", path}) {
+		t.Errorf("files = %v", files)
+	}
+
+	if _, err := removeGenerated(workDir, false); err != nil {
+		t.Fatal(err)
+	}
+	if files := withGeneratedServices(workDir, nil); len(files) != 0 {
+		t.Errorf("files after removal = %v", files)
+	}
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/happy-sdk/space-cli/pkg/xplat"
+	"github.com/spf13/cobra"
+)
+
+// newOpenCommand creates the open command
+func newOpenCommand() *cobra.Command {
+	var printOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "open [service]",
+		Short: "Open service URLs in the browser",
+		Long: `Open the URL of a service in the default browser. Without a service, every
+service marked "primary: true" in .space.yml is opened.
+
+DNS URLs (*.space.local) are used while the DNS daemon is running,
+localhost URLs otherwise.`,
+		Example: `  space open
+  space open web
+  space open --print`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			services, err := servicesToOpen(ctx.cfg, args)
+			if err != nil {
+				return err
+			}
+
+			useDNS := serviceDNSActive(ctx.cfg, ctx.workDir)
+			for _, name := range services {
+				url := serviceURL(ctx.cfg, name, ctx.projectName, ctx.workDir, useDNS)
+				if printOnly {
+					fmt.Fprintln(console, url)
+					continue
+				}
+
+				fmt.Fprintf(console, "🌐 Opening %s: %s\n", name, url)
+				if err := xplat.Open(url); err != nil {
+					return fmt.Errorf("failed to open %s: %w", url, err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the URLs instead of opening them")
+
+	return cmd
+}
+
+// servicesToOpen returns the services whose URLs should be opened: the named
+// service, or all primary http services
+func servicesToOpen(cfg *config.Config, args []string) ([]string, error) {
+	if len(args) > 0 {
+		name := args[0]
+		svc, ok := cfg.Services[name]
+		if !ok || svc.Port == 0 {
+			return nil, fmt.Errorf("service %q has no port configured", name)
+		}
+		if svc.EffectiveProtocol() != config.ProtocolHTTP {
+			return nil, fmt.Errorf("service %q is a %s service and cannot be opened in a browser", name, svc.EffectiveProtocol())
+		}
+		return []string{name}, nil
+	}
+
+	var services []string
+	for _, name := range sortedServiceNames(cfg) {
+		svc := cfg.Services[name]
+		if svc.Primary && svc.Port > 0 && svc.EffectiveProtocol() == config.ProtocolHTTP {
+			services = append(services, name)
+		}
+	}
+	if len(services) == 0 {
+		return nil, fmt.Errorf("no primary services configured; mark one with \"primary: true\" in .space.yml or run 'space open <service>'")
+	}
+	return services, nil
+}
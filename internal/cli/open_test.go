@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestServicesToOpen(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"web":      {Port: 3000, Primary: true},
+			"admin":    {Port: 3001, Primary: true},
+			"api":      {Port: 8080},
+			"postgres": {Port: 5432, Protocol: "tcp", Primary: true},
+			"worker":   {},
+		},
+	}
+
+	got, err := servicesToOpen(cfg, nil)
+	if err != nil {
+		t.Fatalf("servicesToOpen() error = %v", err)
+	}
+	if want := []string{"admin", "web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("servicesToOpen() = %v, want %v", got, want)
+	}
+
+	got, err = servicesToOpen(cfg, []string{"api"})
+	if err != nil || !reflect.DeepEqual(got, []string{"api"}) {
+		t.Errorf("servicesToOpen(api) = %v, %v", got, err)
+	}
+
+	for _, name := range []string{"postgres", "worker", "missing"} {
+		if _, err := servicesToOpen(cfg, []string{name}); err == nil {
+			t.Errorf("servicesToOpen(%s) expected an error", name)
+		}
+	}
+
+	if _, err := servicesToOpen(&config.Config{}, nil); err == nil {
+		t.Error("expected an error without primary services")
+	}
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/internal/render"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// outputConfig caches the output settings in the config visible from the
+// working directory
+var outputConfig = sync.OnceValue(func() config.OutputConfig {
+	return daemonConfig(workDirOrEmpty()).Output
+})
+
+// tipsOff reports whether output.tips is turned off; a variable so tests
+// can change it
+var tipsOff = func() bool {
+	return !outputConfig().Tips
+}
+
+// renderers picks, once per process, how stdout and stderr draw symbols and
+// colors, from output.symbols and output.theme and the environment
+var renderers = sync.OnceValues(func() (stdout, stderr *render.Renderer) {
+	opts := render.Options{Symbols: outputConfig().Symbols, Theme: outputConfig().Theme}
+	stdout, err := render.Detect(opts, stdoutIsTerminal(), os.Getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+	}
+	stderr, _ = render.Detect(opts, stderrIsTerminal(), os.Getenv)
+	return stdout, stderr
+})
+
+// stdoutRenderer returns the renderer of stdout
+func stdoutRenderer() *render.Renderer {
+	r, _ := renderers()
+	return r
+}
+
+// stderrRenderer returns the renderer of stderr
+func stderrRenderer() *render.Renderer {
+	_, r := renderers()
+	return r
+}
+
+// console and consoleErr are where commands print for people to read. They
+// write to the current os.Stdout and os.Stderr, so redirections by tests
+// and --record still apply, with the symbols and colors of the terminal.
+// JSON and other machine-readable output goes to os.Stdout directly.
+var (
+	console    io.Writer = render.NewWriter(func() io.Writer { return os.Stdout }, stdoutRenderer)
+	consoleErr io.Writer = render.NewWriter(func() io.Writer { return os.Stderr }, stderrRenderer)
+)
+
+func init() {
+	hooks.LogOutput = console
+}
+
+// newTable creates a table for console output whose columns stay aligned
+// around emoji and wide characters
+func newTable(w io.Writer) *render.Table {
+	return render.NewTable(w, stdoutRenderer(), 3)
+}
+
+// tipf prints a "💡 Tip:" line unless --quiet is set or output.tips is off
+func tipf(format string, args ...interface{}) {
+	if Quiet || tipsOff() {
+		return
+	}
+	fmt.Fprintf(console, "💡 Tip: "+format+"\n", args...)
+}
+
+// bannerf prints a progress or banner line unless --quiet is set
+func bannerf(format string, args ...interface{}) {
+	if Quiet {
+		return
+	}
+	fmt.Fprintf(console, format+"\n", args...)
+}
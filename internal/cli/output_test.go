@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"testing"
+)
+
+// stdout runs fn and returns what it printed
+func stdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	fn()
+	os.Stdout = orig
+	w.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestQuietOutput(t *testing.T) {
+	origQuiet, origTips := Quiet, tipsOff
+	defer func() { Quiet, tipsOff = origQuiet, origTips }()
+
+	show := func() string {
+		return stdout(t, func() {
+			bannerf("🚀 Starting %s", "shop")
+			tipf("Run 'space %s'", "links")
+		})
+	}
+
+	Quiet, tipsOff = false, func() bool { return false }
+	if got, want := show(), "🚀 Starting shop\n💡 Tip: Run 'space links'\n"; got != want {
+		t.Errorf("default output = %q, want %q", got, want)
+	}
+
+	tipsOff = func() bool { return true }
+	if got, want := show(), "🚀 Starting shop\n"; got != want {
+		t.Errorf("output with tips off = %q, want %q", got, want)
+	}
+
+	Quiet, tipsOff = true, func() bool { return false }
+	if got := show(); got != "" {
+		t.Errorf("quiet output = %q, want nothing", got)
+	}
+}
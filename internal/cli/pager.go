@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/render"
+	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
+)
+
+// terminalSize returns the rows and columns of the terminal on stdout, or
+// zeros when it has none; a variable so tests can set a size
+var terminalSize = func() (rows, cols int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil {
+		return 0, 0
+	}
+	return int(ws.Row), int(ws.Col)
+}
+
+// pagerCommand returns the pager to use, from $PAGER like git, or "" when
+// paging is turned off
+func pagerCommand() string {
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		pager = "less"
+	}
+	if pager = strings.TrimSpace(pager); pager == "cat" {
+		return ""
+	}
+	return pager
+}
+
+// paged makes cmd show its output through the pager when it is taller than
+// the terminal, unless --no-pager is set or stdout is not a terminal
+func paged(cmd *cobra.Command) *cobra.Command {
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if json, err := cmd.Flags().GetBool("json"); err == nil && json {
+			return run(cmd, args)
+		}
+		stop := startPager()
+		defer stop()
+		return run(cmd, args)
+	}
+	return cmd
+}
+
+// startPager sends stdout through a pipe into pageOutput and returns the
+// function that restores stdout and waits for the pager to quit
+func startPager() (stop func()) {
+	pager := pagerCommand()
+	if NoPager || pager == "" || !stdoutIsTerminal() {
+		return func() {}
+	}
+	rows, cols := terminalSize()
+	if rows == 0 {
+		return func() {}
+	}
+
+	// Pick the renderer while stdout is still the terminal, so the output
+	// keeps its colors; less shows them with -R
+	stdoutRenderer()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return func() {}
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pageOutput(r, stdout, rows, cols, pager)
+	}()
+
+	return func() {
+		os.Stdout = stdout
+		w.Close()
+		<-done
+		r.Close()
+	}
+}
+
+// pageOutput copies r to out. Once the output is taller than the terminal
+// it goes through the pager instead, with what was held back first.
+func pageOutput(r io.Reader, out io.Writer, rows, cols int, pager string) {
+	var held bytes.Buffer
+	used := 0
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		held.WriteString(line)
+		used += terminalRows(line, cols)
+		// Leave a row for the shell prompt
+		if used >= rows {
+			break
+		}
+		if err != nil {
+			out.Write(held.Bytes())
+			return
+		}
+	}
+
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		// What git sets: quit when it fits, keep colors, leave the output
+		// on the screen
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		out.Write(held.Bytes())
+		io.Copy(out, reader)
+		return
+	}
+
+	// Once the pager quits, the rest of the output is dropped so the
+	// command can finish
+	if _, err := io.Copy(stdin, io.MultiReader(&held, reader)); err != nil {
+		io.Copy(io.Discard, reader)
+	}
+	stdin.Close()
+	cmd.Wait()
+}
+
+// terminalRows returns how many terminal rows a line takes when it wraps at
+// cols
+func terminalRows(line string, cols int) int {
+	width := render.Width(strings.TrimSuffix(line, "\n"))
+	if width == 0 || cols <= 0 {
+		return 1
+	}
+	return (width + cols - 1) / cols
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPageOutput(t *testing.T) {
+	// Output that fits is printed as is
+	var out bytes.Buffer
+	pageOutput(strings.NewReader("one\ntwo\n"), &out, 5, 80, "tr a-z A-Z")
+	if out.String() != "one\ntwo\n" {
+		t.Errorf("short output = %q", out.String())
+	}
+
+	// Taller output goes through the pager, including the held back lines
+	out.Reset()
+	long := strings.Repeat("line\n", 10)
+	pageOutput(strings.NewReader(long), &out, 5, 80, "tr a-z A-Z")
+	if out.String() != strings.ToUpper(long) {
+		t.Errorf("paged output = %q", out.String())
+	}
+
+	// A pager that quits early doesn't block the rest of the output
+	out.Reset()
+	pageOutput(strings.NewReader(strings.Repeat("line\n", 100000)), &out, 5, 80, "head -n 1")
+	if out.String() != "line\n" {
+		t.Errorf("output after quitting the pager = %q", out.String())
+	}
+}
+
+func TestTerminalRows(t *testing.T) {
+	tests := []struct {
+		line string
+		want int
+	}{
+		{"\n", 1},
+		{"short\n", 1},
+		{strings.Repeat("x", 80) + "\n", 1},
+		{strings.Repeat("x", 81) + "\n", 2},
+		{"\033[32m" + strings.Repeat("x", 80) + "\033[0m\n", 1},
+	}
+	for _, tt := range tests {
+		if got := terminalRows(tt.line, 80); got != tt.want {
+			t.Errorf("terminalRows(%q) = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+}
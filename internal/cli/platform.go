@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/provider"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// platformComposeFile is the generated override pinning service platforms
+const platformComposeFile = ".space-platform-compose.yml"
+
+// imagePlatform returns the os/arch of a local image, or "" when it is not
+// pulled yet
+var imagePlatform = func(ctx context.Context, image string) string {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "docker", "image", "inspect",
+		"--format", "{{.Os}}/{{.Architecture}}", image).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// dockerPlatform returns the os/arch containers run on natively
+var dockerPlatform = func(ctx context.Context) string {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "docker", "version", "--format", "{{.Server.Os}}/{{.Server.Arch}}").Output()
+	if platform := strings.TrimSpace(string(out)); err == nil && platform != "/" {
+		return platform
+	}
+	return "linux/" + runtime.GOARCH
+}
+
+// providerConfigName returns the provider section of .space.yml that applies
+// to a detected provider, or "" for generic docker
+func providerConfigName(p provider.Provider) string {
+	switch p {
+	case provider.ProviderOrbStack:
+		return config.ProviderOrbStack
+	case provider.ProviderDockerDesktop:
+		return config.ProviderDocker
+	}
+	return ""
+}
+
+// planPlatforms returns the platform space sets per compose service:
+// services.<name>.platform, or the provider default for services whose
+// compose definition has no platform
+func planPlatforms(cfg *config.Config, project *compose.Project, providerName string) map[string]string {
+	platforms := make(map[string]string)
+	if project == nil {
+		return platforms
+	}
+
+	fallback := cfg.Provider.PlatformFor(providerName)
+	for name, svc := range project.Services() {
+		if platform := cfg.Services[name].Platform; platform != "" {
+			platforms[name] = platform
+		} else if _, ok := svc["platform"]; !ok && fallback != "" {
+			platforms[name] = fallback
+		}
+	}
+	return platforms
+}
+
+// writePlatformOverride writes the planned platforms as a compose override.
+// It returns the override path and the services it covers, or "" when no
+// service needs one.
+func writePlatformOverride(workDir string, platforms map[string]string) (string, []string, error) {
+	if len(platforms) == 0 {
+		return "", nil, nil
+	}
+
+	services := make(map[string]interface{}, len(platforms))
+	for name, platform := range platforms {
+		services[name] = map[string]interface{}{"platform": platform}
+	}
+	data, err := yaml.Marshal(map[string]interface{}{"services": services})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal platforms: %w", err)
+	}
+
+	header := "# Auto-generated platforms from .space.yml platform settings\n"
+	overrideFile := filepath.Join(workDir, platformComposeFile)
+	if _, err := writeGenerated(overrideFile, header, data); err != nil {
+		return "", nil, fmt.Errorf("failed to write platform override: %w", err)
+	}
+
+	names := make([]string, 0, len(platforms))
+	for name := range platforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return overrideFile, names, nil
+}
+
+// platformMismatches describes the services (all when services is empty)
+// whose local image is built for another architecture than the one they
+// will run as, which otherwise surfaces as "exec format error"
+func platformMismatches(ctx context.Context, project *compose.Project, platforms map[string]string, services []string) []string {
+	if project == nil {
+		return nil
+	}
+	if len(services) == 0 {
+		services = project.ServiceNames()
+	}
+	services = append([]string(nil), services...)
+	sort.Strings(services)
+
+	var host string
+	var mismatches []string
+	for _, name := range services {
+		image := project.Image(name)
+		if image == "" {
+			continue
+		}
+		built := imagePlatform(ctx, image)
+		if built == "" {
+			continue
+		}
+
+		runAs := platforms[name]
+		if runAs == "" {
+			runAs, _ = project.Services()[name]["platform"].(string)
+		}
+		if runAs == "" {
+			if host == "" {
+				host = dockerPlatform(ctx)
+			}
+			if platformArch(built) != platformArch(host) {
+				mismatches = append(mismatches, fmt.Sprintf("%s: image %s is built for %s, but containers run as %s here; set services.%s.platform: %s to run it emulated, or use an image with a %s build",
+					name, image, built, host, name, built, host))
+			}
+			continue
+		}
+		if platformArch(built) != platformArch(runAs) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: image %s is built for %s, but the service runs as %s; pull the %s variant or change its platform",
+				name, image, built, runAs, runAs))
+		}
+	}
+	return mismatches
+}
+
+// warnPlatformMismatches prints platformMismatches as warnings
+func warnPlatformMismatches(ctx context.Context, w io.Writer, project *compose.Project, platforms map[string]string, services []string) {
+	for _, mismatch := range platformMismatches(ctx, project, platforms, services) {
+		fmt.Fprintf(w, "⚠️  %s\n", mismatch)
+	}
+}
+
+// platformArch returns the architecture of an os/arch[/variant] platform,
+// with the aliases docker reports normalized
+func platformArch(platform string) string {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 {
+		return platform
+	}
+	switch parts[1] {
+	case "x86_64":
+		return "amd64"
+	case "aarch64":
+		return "arm64"
+	}
+	return parts[1]
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/provider"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestPlanPlatforms(t *testing.T) {
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api":    map[string]interface{}{"image": "api:latest"},
+			"legacy": map[string]interface{}{"image": "legacy:1"},
+			"pinned": map[string]interface{}{"image": "pinned:1", "platform": "linux/arm64"},
+		},
+	}}
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"legacy": {Platform: "linux/386"},
+		},
+		Provider: config.ProviderConfig{
+			Platform: "linux/amd64",
+			OrbStack: &config.OrbStackConfig{Platform: "linux/arm64"},
+		},
+	}
+
+	tests := []struct {
+		provider provider.Provider
+		want     map[string]string
+	}{
+		{provider.ProviderGeneric, map[string]string{"api": "linux/amd64", "legacy": "linux/386"}},
+		{provider.ProviderDockerDesktop, map[string]string{"api": "linux/amd64", "legacy": "linux/386"}},
+		{provider.ProviderOrbStack, map[string]string{"api": "linux/arm64", "legacy": "linux/386"}},
+	}
+	for _, tt := range tests {
+		if got := planPlatforms(cfg, project, providerConfigName(tt.provider)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("planPlatforms(%s) = %v, want %v", tt.provider, got, tt.want)
+		}
+	}
+
+	workDir := t.TempDir()
+	file, services, err := writePlatformOverride(workDir, map[string]string{"legacy": "linux/386", "api": "linux/amd64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(services, []string{"api", "legacy"}) {
+		t.Errorf("services = %v", services)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "legacy:\n        platform: linux/386") {
+		t.Errorf("override = %s", data)
+	}
+
+	if file, _, err := writePlatformOverride(workDir, nil); file != "" || err != nil {
+		t.Errorf("writePlatformOverride(nil) = %q, %v", file, err)
+	}
+}
+
+func TestPlatformMismatches(t *testing.T) {
+	originalImage, originalDocker := imagePlatform, dockerPlatform
+	defer func() { imagePlatform, dockerPlatform = originalImage, originalDocker }()
+	imagePlatform = func(ctx context.Context, image string) string {
+		return map[string]string{
+			"amd64-only:1": "linux/amd64",
+			"native:1":     "linux/arm64",
+		}[image]
+	}
+	dockerPlatform = func(context.Context) string { return "linux/aarch64" }
+
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"legacy":   map[string]interface{}{"image": "amd64-only:1"},
+			"emulated": map[string]interface{}{"image": "amd64-only:1"},
+			"pinned":   map[string]interface{}{"image": "native:1", "platform": "linux/amd64"},
+			"native":   map[string]interface{}{"image": "native:1"},
+			"unpulled": map[string]interface{}{"image": "missing:1"},
+			"built":    map[string]interface{}{"build": "."},
+		},
+	}}
+	platforms := map[string]string{"emulated": "linux/amd64"}
+
+	got := platformMismatches(context.Background(), project, platforms, nil)
+	if len(got) != 2 {
+		t.Fatalf("platformMismatches() = %q, want legacy and pinned", got)
+	}
+	if !strings.HasPrefix(got[0], "legacy: image amd64-only:1 is built for linux/amd64, but containers run as linux/aarch64 here; set services.legacy.platform: linux/amd64") {
+		t.Errorf("legacy = %q", got[0])
+	}
+	if !strings.HasPrefix(got[1], "pinned: image native:1 is built for linux/arm64, but the service runs as linux/amd64") {
+		t.Errorf("pinned = %q", got[1])
+	}
+
+	if got := platformMismatches(context.Background(), project, platforms, []string{"native", "emulated"}); len(got) != 0 {
+		t.Errorf("platformMismatches(native, emulated) = %q", got)
+	}
+}
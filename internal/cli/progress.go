@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// slowThreshold is how long a step may run before a note is shown
+	slowThreshold = 3 * time.Second
+
+	// slowNoteInterval is how often attached steps repeat their note
+	slowNoteInterval = 15 * time.Second
+
+	// progressOutput receives slow-step notes and timing summaries
+	progressOutput io.Writer = consoleErr
+
+	// progressAnimated reports whether notes may redraw a spinner line
+	progressAnimated = stderrIsTerminal
+)
+
+// spinnerFrames are drawn in turn while a captured step is slow
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// stepTiming is the duration of one tracked step
+type stepTiming struct {
+	label   string
+	elapsed time.Duration
+}
+
+var (
+	timingsMu sync.Mutex
+	timings   []stepTiming
+)
+
+// step runs fn and, once it has been running longer than slowThreshold,
+// reports "waiting on <label> (Ns)…" so long operations aren't mistaken for
+// a freeze. Captured steps redraw a spinner on stderr that is cleared when
+// they finish. Attached steps write to the terminal themselves (or prompt on
+// it, like sudo), so they get a plain line every slowNoteInterval instead.
+func step(label string, attached bool, fn func() error) error {
+	start := time.Now()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		watchStep(label, attached, start, done)
+	}()
+
+	err := fn()
+	close(done)
+	wg.Wait()
+
+	timingsMu.Lock()
+	timings = append(timings, stepTiming{label: label, elapsed: time.Since(start)})
+	timingsMu.Unlock()
+
+	return err
+}
+
+// watchStep emits slow-step notes until done is closed
+func watchStep(label string, attached bool, start time.Time, done <-chan struct{}) {
+	select {
+	case <-done:
+		return
+	case <-time.After(slowThreshold):
+	}
+
+	if attached || !progressAnimated() {
+		ticker := time.NewTicker(slowNoteInterval)
+		defer ticker.Stop()
+		for {
+			fmt.Fprintf(progressOutput, "⏳ waiting on %s (%s)…\n", label, formatElapsed(time.Since(start)))
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for frame := 0; ; frame++ {
+		fmt.Fprintf(progressOutput, "\r\033[K%s waiting on %s (%s)…",
+			spinnerFrames[frame%len(spinnerFrames)], label, formatElapsed(time.Since(start)))
+		select {
+		case <-done:
+			fmt.Fprint(progressOutput, "\r\033[K")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// formatElapsed renders a duration in whole seconds, or tenths below 10s
+func formatElapsed(d time.Duration) string {
+	if d < 10*time.Second {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return fmt.Sprintf("%ds", int(d.Seconds()))
+}
+
+// stderrIsTerminal reports whether stderr is a terminal
+func stderrIsTerminal() bool {
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// printTimings writes the total command time and its slowest steps
+func printTimings(w io.Writer, command string, total time.Duration) {
+	timingsMu.Lock()
+	steps := append([]stepTiming(nil), timings...)
+	timingsMu.Unlock()
+
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].elapsed > steps[j].elapsed })
+
+	fmt.Fprintf(w, "⏱️  %s took %s\n", command, formatElapsed(total))
+	for _, s := range steps {
+		fmt.Fprintf(w, "   %-40s %s\n", s.label, formatElapsed(s.elapsed))
+	}
+}
+
+// enableTimings prints a timing summary after every command when --timings
+// is set
+func enableTimings(root *cobra.Command) {
+	var (
+		enabled bool
+		started time.Time
+	)
+
+	root.PersistentFlags().BoolVar(&enabled, "timings", false, "print how long the command and its slow steps took")
+	root.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		started = time.Now()
+	}
+	root.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		if enabled {
+			printTimings(progressOutput, cmd.CommandPath(), time.Since(started))
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStepReportsSlowOperations(t *testing.T) {
+	origThreshold, origOutput, origAnimated := slowThreshold, progressOutput, progressAnimated
+	t.Cleanup(func() {
+		slowThreshold, progressOutput, progressAnimated = origThreshold, origOutput, origAnimated
+		timings = nil
+	})
+
+	var out bytes.Buffer
+	slowThreshold = 20 * time.Millisecond
+	progressOutput = &out
+	progressAnimated = func() bool { return false }
+	timings = nil
+
+	if err := step("fast thing", false, func() error { return nil }); err != nil {
+		t.Fatalf("step() error = %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("fast step printed %q", out.String())
+	}
+
+	want := errors.New("boom")
+	err := step("docker compose up", true, func() error {
+		time.Sleep(60 * time.Millisecond)
+		return want
+	})
+	if err != want {
+		t.Errorf("step() error = %v, want %v", err, want)
+	}
+	if !strings.HasPrefix(out.String(), "⏳ waiting on docker compose up (") {
+		t.Errorf("slow step printed %q", out.String())
+	}
+
+	if len(timings) != 2 || timings[1].label != "docker compose up" {
+		t.Fatalf("timings = %+v", timings)
+	}
+
+	var summary bytes.Buffer
+	printTimings(&summary, "space up", 2*time.Second)
+	lines := strings.Split(strings.TrimSpace(summary.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "space up took 2.0s") || !strings.Contains(lines[1], "docker compose up") {
+		t.Errorf("printTimings() = %q", summary.String())
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := map[time.Duration]string{
+		1500 * time.Millisecond:               "1.5s",
+		23 * time.Second:                      "23s",
+		90*time.Second + 400*time.Millisecond: "90s",
+	}
+	for d, want := range tests {
+		if got := formatElapsed(d); got != want {
+			t.Errorf("formatElapsed(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// registerProjectCommands adds the commands.custom entries of the project
+// config as top-level commands. It runs before cobra parses the command
+// line, so --workdir and --config are picked from args here. Names taken by
+// built-in commands are skipped with a warning.
+func registerProjectCommands(root *cobra.Command, args []string) {
+	workDir, configFile := preparseProjectFlags(args)
+	if configFile == config.StdinConfigFile {
+		// Reading stdin now would leave nothing for the command
+		return
+	}
+
+	saved := ConfigFile
+	ConfigFile = configFile
+	absDir, err := filepath.Abs(workDir)
+	var cfg *config.Config
+	if err == nil {
+		cfg = daemonConfig(absDir)
+	}
+	ConfigFile = saved
+	if cfg == nil {
+		return
+	}
+
+	names := make([]string, 0, len(cfg.Commands.Custom))
+	for name := range cfg.Commands.Custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if existing, _, err := root.Find([]string{name}); err == nil && existing != root {
+			if len(args) > 0 && args[0] == name {
+				fmt.Fprintf(consoleErr, "⚠️  commands.custom.%s is shadowed by the built-in 'space %s'\n", name, name)
+			}
+			continue
+		}
+		root.AddCommand(newProjectCommand(name, cfg.Commands.Custom[name]))
+	}
+}
+
+// preparseProjectFlags returns the values of --workdir and --config in
+// args, or their defaults
+func preparseProjectFlags(args []string) (workDir, configFile string) {
+	workDir = "."
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		next := func() string {
+			if i+1 < len(args) {
+				i++
+				return args[i]
+			}
+			return ""
+		}
+		switch {
+		case arg == "--":
+			return workDir, configFile
+		case arg == "-w" || arg == "--workdir":
+			workDir = next()
+		case strings.HasPrefix(arg, "--workdir="):
+			workDir = strings.TrimPrefix(arg, "--workdir=")
+		case strings.HasPrefix(arg, "-w") && !strings.HasPrefix(arg, "--"):
+			workDir = strings.TrimPrefix(strings.TrimPrefix(arg, "-w"), "=")
+		case arg == "--config":
+			configFile = next()
+		case strings.HasPrefix(arg, "--config="):
+			configFile = strings.TrimPrefix(arg, "--config=")
+		}
+	}
+	return workDir, configFile
+}
+
+// newProjectCommand creates the command of a commands.custom entry
+func newProjectCommand(name string, def config.CustomCommandConfig) *cobra.Command {
+	short := def.Description
+	if short == "" && def.Service != "" {
+		short = fmt.Sprintf("Run %q in %s", def.Command, def.Service)
+	} else if short == "" {
+		short = fmt.Sprintf("Run %q", def.Command)
+	}
+
+	return &cobra.Command{
+		Use:                name + " [args...]",
+		Short:              short,
+		Long:               short + "\n\nDefined in commands.custom of .space.yaml; arguments are appended to the command.",
+		DisableFlagParsing: true, // Pass all flags to the command
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			return runProjectCommand(cmd.Context(), c, name, def, args)
+		},
+	}
+}
+
+// runProjectCommand runs a commands.custom entry with sh -c, in its
+// service's container or on the host, starting the stack first if asked
+func runProjectCommand(ctx context.Context, c *serviceCommandContext, name string, def config.CustomCommandConfig, args []string) error {
+	if def.Service != "" {
+		if err := checkServiceNames(c.cfg, []string{def.Service}, c.services); err != nil {
+			return fmt.Errorf("commands.custom.%s: %w", name, err)
+		}
+	}
+
+	if def.Up {
+		running, err := stackRunning(ctx, c, def.Service)
+		if err != nil {
+			return err
+		}
+		if !running {
+			if err := startStack(c.workDir, def.Service); err != nil {
+				return err
+			}
+		}
+	}
+
+	// "$@" passes the arguments through sh -c without requoting them
+	script := def.Command + ` "$@"`
+	if def.Service != "" {
+		execArgs := []string{"exec"}
+		if !stdinIsTerminal() || !stdoutIsTerminal() {
+			execArgs = append(execArgs, "-T")
+		}
+		execArgs = append(execArgs, def.Service, "sh", "-c", script, name)
+		return c.compose(append(execArgs, args...)...)
+	}
+
+	shCmd := exec.CommandContext(ctx, "sh", append([]string{"-c", script, name}, args...)...)
+	shCmd.Dir = c.workDir
+	shCmd.Env = append(os.Environ(),
+		"SPACE_WORKDIR="+c.workDir,
+		"SPACE_PROJECT_NAME="+c.projectName,
+	)
+	shCmd.Stdin = os.Stdin
+	shCmd.Stdout = os.Stdout
+	shCmd.Stderr = os.Stderr
+	return shCmd.Run()
+}
+
+// stackRunning reports whether service, or with no service any service of
+// the project, is running
+func stackRunning(ctx context.Context, c *serviceCommandContext, service string) (bool, error) {
+	statuses, err := getDockerComposePS(ctx, c.workDir, c.cfg, c.projectName, false)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range statuses {
+		if s.State == "running" && (service == "" || s.Name == service) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// startStack runs space up for the project, or for one service and its
+// dependencies, as a child process
+func startStack(workDir, service string) error {
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to get executable path: %w", err)
+	}
+
+	args := []string{"--workdir", workDir}
+	if ConfigFile != "" {
+		args = append(args, "--config", ConfigFile)
+	}
+	if Quiet {
+		args = append(args, "--quiet")
+	}
+	args = append(args, "up")
+	if service != "" {
+		args = append(args, service)
+	}
+
+	fmt.Fprintf(console, "🚀 Starting the stack first: space %s\n", strings.Join(args[2:], " "))
+	upCmd := exec.Command(execPath, args...)
+	upCmd.Stdin = os.Stdin
+	upCmd.Stdout = os.Stdout
+	upCmd.Stderr = os.Stderr
+	if err := upCmd.Run(); err != nil {
+		return fmt.Errorf("failed to start the stack: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func TestPreparseProjectFlags(t *testing.T) {
+	tests := []struct {
+		args       []string
+		workDir    string
+		configFile string
+	}{
+		{nil, ".", ""},
+		{[]string{"test", "./..."}, ".", ""},
+		{[]string{"-w", "/src/shop", "test"}, "/src/shop", ""},
+		{[]string{"--workdir=/src/shop", "--config", "ci.yaml", "test"}, "/src/shop", "ci.yaml"},
+		{[]string{"-w/src/shop", "--config=-", "up"}, "/src/shop", "-"},
+		{[]string{"test", "--", "-w", "/elsewhere"}, ".", ""},
+	}
+
+	for _, tt := range tests {
+		workDir, configFile := preparseProjectFlags(tt.args)
+		if workDir != tt.workDir || configFile != tt.configFile {
+			t.Errorf("preparseProjectFlags(%q) = %q, %q, want %q, %q", tt.args, workDir, configFile, tt.workDir, tt.configFile)
+		}
+	}
+}
+
+func TestRegisterProjectCommands(t *testing.T) {
+	workDir := t.TempDir()
+	content := `project:
+  name: shop
+commands:
+  custom:
+    lint: golangci-lint run
+    test:
+      service: api
+      command: go test ./...
+      up: true
+    up:
+      command: echo shadowed
+`
+	if err := os.WriteFile(filepath.Join(workDir, config.ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	root := &cobra.Command{Use: "space"}
+	root.AddCommand(&cobra.Command{Use: "up", Short: "Start services"})
+	registerProjectCommands(root, []string{"--workdir", workDir, "test"})
+
+	shorts := make(map[string]string)
+	for _, cmd := range root.Commands() {
+		shorts[cmd.Name()] = cmd.Short
+	}
+	if shorts["lint"] != `Run "golangci-lint run"` {
+		t.Errorf("lint = %q", shorts["lint"])
+	}
+	if shorts["test"] != `Run "go test ./..." in api` {
+		t.Errorf("test = %q", shorts["test"])
+	}
+	if shorts["up"] != "Start services" {
+		t.Errorf("built-in up replaced by %q", shorts["up"])
+	}
+}
+
+func TestRunProjectCommandOnHost(t *testing.T) {
+	workDir := t.TempDir()
+	c := &serviceCommandContext{workDir: workDir, cfg: &config.Config{}, projectName: "shop"}
+	def := config.CustomCommandConfig{Command: `printf '%s|' "$SPACE_PROJECT_NAME" > out.txt; printf '%s|' >> out.txt`}
+
+	if err := runProjectCommand(context.Background(), c, "args", def, []string{"one two", "three"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(workDir, "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "shop|one two|three|" {
+		t.Errorf("output = %q, want arguments passed through unsplit", data)
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -146,3 +147,25 @@ func TestNormalizeProjectName_DockerCompliance(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeProjectName_Length(t *testing.T) {
+	short := "myapp-feature-login"
+	if got := normalizeProjectName(short); got != short {
+		t.Errorf("normalizeProjectName(%q) = %q, want unchanged", short, got)
+	}
+
+	long := "myapp-feature/really-long-descriptive-name-for-the-new-checkout-flow-v2"
+	got := normalizeProjectName(long)
+	if len(got) > 63 {
+		t.Errorf("normalizeProjectName() = %q (%d characters), want at most 63", got, len(got))
+	}
+	if !strings.HasPrefix(got, "myapp-feature-really-long") {
+		t.Errorf("normalizeProjectName() = %q, want the readable prefix kept", got)
+	}
+	if other := normalizeProjectName(long + "-v3"); other == got {
+		t.Errorf("distinct long names both normalized to %q", got)
+	}
+	if again := normalizeProjectName(long); again != got {
+		t.Errorf("normalization not stable: %q != %q", again, got)
+	}
+}
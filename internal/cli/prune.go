@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// newPruneCommand creates the prune command
+func newPruneCommand() *cobra.Command {
+	var dryRun bool
+	var force bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove files left behind by an interrupted run",
+		Long: `Remove the generated compose files (.space-dns-compose.yml,
+.space-health-compose.yml) and rendered secrets of the project when none of
+its containers are running, e.g. after space was killed mid-run.
+
+Only files carrying the space header are removed, after confirming the
+project (skip it with --yes).`,
+		Example: `  space prune
+  space prune --dry-run
+  space prune --yes`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			// Get working directory
+			workDir := Workdir
+			if workDir == "." {
+				var err error
+				workDir, err = os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get working directory: %w", err)
+				}
+			}
+
+			// Make absolute
+			workDir, err := filepath.Abs(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+
+			// Create loader
+			loader, err := newConfigLoader(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to create config loader: %w", err)
+			}
+
+			// Load configuration
+			cfg, err := loader.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+
+			projectName := generateProjectName(cfg, workDir)
+
+			// Files of a running stack are still in use
+			if !force {
+				services, err := getDockerComposePS(ctx, workDir, cfg, projectName, false)
+				if err != nil {
+					return fmt.Errorf("could not check for running containers (use --force to prune anyway): %w", err)
+				}
+				if len(services) > 0 {
+					return fmt.Errorf("project %s has %d running container(s); run 'space down' first or use --force", projectName, len(services))
+				}
+			}
+
+			removed, err := removeGenerated(workDir, true)
+			if err != nil {
+				return fmt.Errorf("failed to remove generated files: %w", err)
+			}
+			if secrets, err := secretsDir(projectName); err != nil {
+				return err
+			} else if _, err := os.Stat(secrets); err == nil {
+				removed = append(removed, secrets)
+			}
+
+			if len(removed) > 0 && !dryRun {
+				if err := confirmDestructive(fmt.Sprintf("remove %d generated file(s)", len(removed)), projectName, workDir, yes); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				if _, err := removeGenerated(workDir, false); err != nil {
+					return fmt.Errorf("failed to remove generated files: %w", err)
+				}
+				if err := removeSecrets(projectName); err != nil {
+					return fmt.Errorf("failed to remove rendered secrets: %w", err)
+				}
+			}
+
+			if len(removed) == 0 {
+				fmt.Fprintln(console, "✨ Nothing to prune")
+				return nil
+			}
+
+			verb := "Removed"
+			if dryRun {
+				verb = "Would remove"
+			}
+			for _, path := range removed {
+				fmt.Fprintf(console, "🧹 %s %s\n", verb, path)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be removed")
+	cmd.Flags().BoolVar(&force, "force", false, "Prune even if containers are running")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Prune without asking for confirmation")
+
+	return cmd
+}
@@ -11,9 +11,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"text/tabwriter"
 
+	"github.com/happy-sdk/space-cli/internal/dns"
 	"github.com/happy-sdk/space-cli/internal/provider"
+	"github.com/happy-sdk/space-cli/internal/redact"
 	"github.com/happy-sdk/space-cli/pkg/config"
 	"github.com/spf13/cobra"
 )
@@ -71,7 +72,7 @@ func newPsCommand() *cobra.Command {
 			}
 
 			// Create loader
-			loader, err := config.NewLoader(workDir)
+			loader, err := newConfigLoader(workDir)
 			if err != nil {
 				return fmt.Errorf("failed to create config loader: %w", err)
 			}
@@ -113,7 +114,17 @@ func newPsCommand() *cobra.Command {
 // runEnhancedPS runs the enhanced ps command with DNS and URL support
 func runEnhancedPS(ctx context.Context, workDir string, cfg *config.Config, projectName string, showAll bool, jsonOutput bool) error {
 	// Check if DNS mode is active
-	useDNS := isDNSServerRunning()
+	useDNS := serviceDNSActive(cfg, workDir)
+
+	registerSensitiveValues(cfg)
+
+	// Include services only described in compose files for URL fallbacks
+	if project, err := loadComposeProject(workDir, cfg); err == nil {
+		applyComposeServices(cfg, project)
+	}
+
+	// Warn on stderr so --json output stays parseable
+	warnComposeDrift(os.Stderr, workDir, composeFilesFor(workDir, cfg))
 
 	// Get service status from docker-compose ps
 	services, err := getDockerComposePS(ctx, workDir, cfg, projectName, showAll)
@@ -122,9 +133,9 @@ func runEnhancedPS(ctx context.Context, workDir string, cfg *config.Config, proj
 	}
 
 	if len(services) == 0 {
-		fmt.Println("No services running.")
-		fmt.Println()
-		fmt.Println("💡 Tip: Run 'space up' to start services")
+		fmt.Fprintln(console, "No services running.")
+		fmt.Fprintln(console)
+		tipf("Run 'space up' to start services")
 		return nil
 	}
 
@@ -137,9 +148,9 @@ func runEnhancedPS(ctx context.Context, workDir string, cfg *config.Config, proj
 }
 
 // runPsCommand executes the docker compose ps command (legacy/quiet mode)
-func runPsCommand(_ context.Context, workDir, projectName string, _ provider.Provider, quiet, noTrunc bool) error {
+func runPsCommand(ctx context.Context, workDir, projectName string, _ provider.Provider, quiet, noTrunc bool) error {
 	// Load config to get compose files
-	loader, err := config.NewLoader(workDir)
+	loader, err := newConfigLoader(workDir)
 	if err != nil {
 		return fmt.Errorf("failed to create config loader: %w", err)
 	}
@@ -149,12 +160,8 @@ func runPsCommand(_ context.Context, workDir, projectName string, _ provider.Pro
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	// Check if docker-compose.yml exists
-	composeFiles := cfg.Project.ComposeFiles
-	if len(composeFiles) == 0 {
-		// Default to docker-compose.yml
-		composeFiles = []string{"docker-compose.yml"}
-	}
+	// Resolve compose files (defaults to docker-compose.yml, plus detected overrides)
+	composeFiles := composeFilesFor(workDir, cfg)
 
 	// Verify at least one compose file exists
 	composeFileExists := false
@@ -174,15 +181,7 @@ func runPsCommand(_ context.Context, workDir, projectName string, _ provider.Pro
 	}
 
 	// Build docker compose ps command
-	composeCmd := []string{"docker", "compose"}
-
-	// Add compose files
-	for _, file := range composeFiles {
-		composeCmd = append(composeCmd, "-f", file)
-	}
-
-	// Add project name
-	composeCmd = append(composeCmd, "-p", projectName)
+	composeCmd := composeCommand(workDir, cfg, withGeneratedServices(workDir, composeFiles), projectName)
 
 	// Add ps command
 	composeCmd = append(composeCmd, "ps")
@@ -196,13 +195,18 @@ func runPsCommand(_ context.Context, workDir, projectName string, _ provider.Pro
 	}
 
 	// Execute docker compose
-	dockerCmd := exec.Command(composeCmd[0], composeCmd[1:]...)
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+	dockerCmd := exec.CommandContext(ctx, composeCmd[0], composeCmd[1:]...)
 	dockerCmd.Dir = workDir
 	dockerCmd.Stdout = os.Stdout
 	dockerCmd.Stderr = os.Stderr
 	dockerCmd.Stdin = os.Stdin
 
 	if err := dockerCmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return timeoutError(ctx, "docker compose ps", dockerQueryTimeout, err)
+		}
 		// Check if docker-compose file is not found
 		if strings.Contains(err.Error(), "no such file") {
 			return fmt.Errorf("docker-compose file not found in %s", workDir)
@@ -221,15 +225,7 @@ func runPsCommand(_ context.Context, workDir, projectName string, _ provider.Pro
 // getDockerComposePS executes docker-compose ps and parses the output
 func getDockerComposePS(ctx context.Context, workDir string, cfg *config.Config, projectName string, showAll bool) ([]ServiceStatus, error) {
 	// Build docker compose ps command
-	composeCmd := []string{"docker", "compose"}
-
-	// Add compose files
-	for _, file := range cfg.Project.ComposeFiles {
-		composeCmd = append(composeCmd, "-f", file)
-	}
-
-	// Add project name
-	composeCmd = append(composeCmd, "-p", projectName)
+	composeCmd := composeCommand(workDir, cfg, withGeneratedServices(workDir, composeFilesFor(workDir, cfg)), projectName)
 
 	// Add ps command with --format json
 	composeCmd = append(composeCmd, "ps", "--format", "json")
@@ -240,6 +236,8 @@ func getDockerComposePS(ctx context.Context, workDir string, cfg *config.Config,
 	}
 
 	// Execute command
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
 	dockerCmd := exec.CommandContext(ctx, composeCmd[0], composeCmd[1:]...)
 	dockerCmd.Dir = workDir
 
@@ -247,7 +245,10 @@ func getDockerComposePS(ctx context.Context, workDir string, cfg *config.Config,
 	dockerCmd.Stdout = &stdout
 	dockerCmd.Stderr = &stderr
 
-	if err := dockerCmd.Run(); err != nil {
+	if err := step("docker compose ps", false, dockerCmd.Run); err != nil {
+		if ctx.Err() != nil {
+			return nil, timeoutError(ctx, "docker compose ps", dockerQueryTimeout, err)
+		}
 		return nil, fmt.Errorf("failed to execute docker compose ps: %w (stderr: %s)", err, stderr.String())
 	}
 
@@ -310,7 +311,7 @@ func getDockerComposePS(ctx context.Context, workDir string, cfg *config.Config,
 		}
 
 		// Add DNS URLs if DNS mode is active
-		if isDNSServerRunning() {
+		if serviceDNSActive(cfg, workDir) {
 			status.DNSUrls = generateDNSUrls(serviceName, cfg, rawService.Publishers)
 		}
 
@@ -362,19 +363,19 @@ func generateDNSUrls(serviceName string, cfg *config.Config, publishers []struct
 	}
 
 	// Generate DNS domain with hash
-	baseDomain := generateDNSDomain(serviceName, absPath)
+	baseDomain := generateDNSDomainIn(serviceName, absPath, cfg.Network.BaseDomain())
 
 	// First, try to get ports from Publishers (most accurate)
 	for _, pub := range publishers {
 		if pub.TargetPort > 0 && pub.Protocol == "tcp" {
-			urls = append(urls, fmt.Sprintf("http://%s:%d", baseDomain, pub.TargetPort))
+			urls = append(urls, renderServiceURL(cfg, serviceName, "", baseDomain, pub.TargetPort))
 		}
 	}
 
 	// Fallback to configured ports if no publishers
 	if len(urls) == 0 {
 		if svc, ok := cfg.Services[serviceName]; ok && svc.Port > 0 {
-			urls = append(urls, fmt.Sprintf("http://%s:%d", baseDomain, svc.Port))
+			urls = append(urls, renderServiceURL(cfg, serviceName, "", baseDomain, svc.Port))
 		}
 	}
 
@@ -389,18 +390,20 @@ func generateDNSUrlsLegacy(serviceName string, cfg *config.Config, publishers []
 	Protocol      string `json:"Protocol"`
 }) []string {
 	urls := make([]string, 0)
+	label, _ := dns.NormalizeLabel(serviceName)
+	host := label + "." + cfg.Network.BaseDomain()
 
 	// First, try to get ports from Publishers (most accurate)
 	for _, pub := range publishers {
 		if pub.TargetPort > 0 && pub.Protocol == "tcp" {
-			urls = append(urls, fmt.Sprintf("http://%s.space.local:%d", serviceName, pub.TargetPort))
+			urls = append(urls, renderServiceURL(cfg, serviceName, "", host, pub.TargetPort))
 		}
 	}
 
 	// Fallback to configured ports if no publishers
 	if len(urls) == 0 {
 		if svc, ok := cfg.Services[serviceName]; ok && svc.Port > 0 {
-			urls = append(urls, fmt.Sprintf("http://%s.space.local:%d", serviceName, svc.Port))
+			urls = append(urls, renderServiceURL(cfg, serviceName, "", host, svc.Port))
 		}
 	}
 
@@ -419,7 +422,7 @@ func generateLocalUrls(serviceName string, cfg *config.Config, publishers []stru
 	// Use published ports from docker-compose ps
 	for _, pub := range publishers {
 		if pub.PublishedPort > 0 && pub.Protocol == "tcp" {
-			urls = append(urls, fmt.Sprintf("http://localhost:%d", pub.PublishedPort))
+			urls = append(urls, renderServiceURL(cfg, serviceName, "", "localhost", pub.PublishedPort))
 		}
 	}
 
@@ -427,9 +430,9 @@ func generateLocalUrls(serviceName string, cfg *config.Config, publishers []stru
 	if len(urls) == 0 {
 		if svc, ok := cfg.Services[serviceName]; ok {
 			if svc.ExternalPort > 0 {
-				urls = append(urls, fmt.Sprintf("http://localhost:%d", svc.ExternalPort))
+				urls = append(urls, renderServiceURL(cfg, serviceName, "", "localhost", svc.ExternalPort))
 			} else if svc.Port > 0 {
-				urls = append(urls, fmt.Sprintf("http://localhost:%d", svc.Port))
+				urls = append(urls, renderServiceURL(cfg, serviceName, "", "localhost", svc.Port))
 			}
 		}
 	}
@@ -439,18 +442,18 @@ func generateLocalUrls(serviceName string, cfg *config.Config, publishers []stru
 
 // outputJSON outputs service status as JSON
 func outputJSON(services []ServiceStatus) error {
-	encoder := json.NewEncoder(os.Stdout)
+	encoder := json.NewEncoder(redact.NewWriter(os.Stdout))
 	encoder.SetIndent("", "  ")
 	return encoder.Encode(services)
 }
 
 // outputTable outputs service status as a formatted table
 func outputTable(services []ServiceStatus, useDNS bool, _ *config.Config) error {
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	w := newTable(console)
 	defer w.Flush()
 
 	// Print header
-	fmt.Println()
+	fmt.Fprintln(console)
 	if useDNS {
 		fmt.Fprintln(w, "SERVICE\tSTATE\tPORTS\tDNS URL\tLOCAL URL")
 		fmt.Fprintln(w, "-------\t-----\t-----\t-------\t---------")
@@ -468,12 +471,12 @@ func outputTable(services []ServiceStatus, useDNS bool, _ *config.Config) error
 
 		dnsUrl := "-"
 		if len(svc.DNSUrls) > 0 {
-			dnsUrl = svc.DNSUrls[0]
+			dnsUrl = redact.String(svc.DNSUrls[0])
 		}
 
 		localUrl := "-"
 		if len(svc.LocalUrls) > 0 {
-			localUrl = svc.LocalUrls[0]
+			localUrl = redact.String(svc.LocalUrls[0])
 		}
 
 		// Format state with color indicators
@@ -486,7 +489,7 @@ func outputTable(services []ServiceStatus, useDNS bool, _ *config.Config) error
 		case "restarting":
 			stateDisplay = "🔄 " + svc.State
 		case "paused":
-			stateDisplay = "⏸️  " + svc.State
+			stateDisplay = "⏸️ " + svc.State
 		}
 
 		if useDNS {
@@ -508,21 +511,24 @@ func outputTable(services []ServiceStatus, useDNS bool, _ *config.Config) error
 	}
 
 	w.Flush()
-	fmt.Println()
+	fmt.Fprintln(console)
 
 	// Print DNS mode status
+	if Quiet {
+		return nil
+	}
 	if useDNS {
 		state, _ := loadDNSState()
-		fmt.Printf("🌐 DNS Mode: Active (daemon running on %s)\n", state.Address)
-		fmt.Println("   Services are accessible via .space.local domains")
+		fmt.Fprintf(console, "🌐 DNS Mode: Active (daemon running on %s)\n", state.Address)
+		fmt.Fprintln(console, "   Services are accessible via .space.local domains")
 	} else {
-		fmt.Println("🔌 DNS Mode: Inactive (using port bindings)")
-		fmt.Println("   Use 'space up' to enable DNS mode on supported providers")
+		fmt.Fprintln(console, "🔌 DNS Mode: Inactive (using port bindings)")
+		fmt.Fprintln(console, "   Use 'space up' to enable DNS mode on supported providers")
 	}
 
-	fmt.Println()
-	fmt.Println("💡 Tip: Run 'space logs <service>' to view service logs")
-	fmt.Println("💡 Tip: Run 'space shell <service>' to access a service shell")
+	fmt.Fprintln(console)
+	tipf("Run 'space logs <service>' to view service logs")
+	tipf("Run 'space shell <service>' to access a service shell")
 
 	return nil
 }
@@ -572,11 +578,17 @@ func ParseContainers(output string) []Container {
 	return containers
 }
 
-// generateDNSDomain creates a DNS domain name with directory-based hash
+// generateDNSDomain creates a DNS domain name with directory-based hash in
+// the default base domain
 // Format: {serviceName}-{hash}.space.local
 func generateDNSDomain(serviceName, workDir string) string {
+	return generateDNSDomainIn(serviceName, workDir, config.DefaultBaseDomain)
+}
+
+// generateDNSDomainIn creates a hashed DNS domain name in a base domain
+func generateDNSDomainIn(serviceName, workDir, baseDomain string) string {
 	hash := generateDirectoryHash(workDir)
-	return fmt.Sprintf("%s-%s.space.local", serviceName, hash)
+	return fmt.Sprintf("%s-%s.%s", dns.ServiceLabel(serviceName), hash, baseDomain)
 }
 
 // generateDirectoryHash creates a 6-character hash from a directory path
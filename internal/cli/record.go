@@ -0,0 +1,386 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+// castVersion is the asciinema transcript format written by --record
+const castVersion = 2
+
+// castHeader is the first line of a transcript. The asciinema fields come
+// first, so asciinema can play it; the rest is what support needs.
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Command   string            `json:"command,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+
+	Environment []string `json:"environment,omitempty"`
+	Duration    float64  `json:"duration"`
+	ExitCode    int      `json:"exit_code"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// castEvent is one chunk of output, Time seconds after the start
+type castEvent struct {
+	Time float64
+	Data string
+}
+
+// MarshalJSON writes the event as an asciinema [time, "o", data] line
+func (e castEvent) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.Time, "o", e.Data})
+}
+
+// UnmarshalJSON reads an asciinema event line
+func (e *castEvent) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("event has %d fields, want 3", len(fields))
+	}
+	if err := json.Unmarshal(fields[0], &e.Time); err != nil {
+		return err
+	}
+	return json.Unmarshal(fields[2], &e.Data)
+}
+
+// recordedEnv lists the environment variables kept in a transcript, by name
+// or prefix; values that look sensitive are masked
+var recordedEnv = []string{"SHELL", "TERM", "DOCKER_HOST", "DOCKER_CONTEXT", "COMPOSE_", "SPACE_"}
+
+// activeRecording is the transcript being captured for --record
+var activeRecording *recording
+
+// recording captures everything the command writes to stdout and stderr,
+// while still passing it through to the terminal
+type recording struct {
+	path    string
+	command string
+	started time.Time
+
+	stdout, stderr *os.File
+	writers        []*os.File
+	copies         sync.WaitGroup
+
+	mu     sync.Mutex
+	events []castEvent
+}
+
+// enableRecording adds --record, which captures the command into a
+// transcript written when Execute returns
+func enableRecording(root *cobra.Command) {
+	var path string
+
+	root.PersistentFlags().StringVar(&path, "record", "", "record the command, its output and exit code into a transcript file for bug reports (see 'space replay')")
+	preRun := root.PersistentPreRun
+	root.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if path != "" {
+			rec, err := startRecording(path, os.Args[1:])
+			if err != nil {
+				fmt.Fprintf(consoleErr, "⚠️  Could not record the session: %v\n", err)
+			} else {
+				activeRecording = rec
+			}
+		}
+		if preRun != nil {
+			preRun(cmd, args)
+		}
+	}
+}
+
+// startRecording redirects stdout and stderr through pipes feeding the
+// transcript
+func startRecording(path string, args []string) (*recording, error) {
+	rec := &recording{
+		path:    path,
+		command: strings.Join(append([]string{"space"}, args...), " "),
+		started: time.Now(),
+		stdout:  os.Stdout,
+		stderr:  os.Stderr,
+	}
+
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		outR.Close()
+		outW.Close()
+		return nil, err
+	}
+	rec.writers = []*os.File{outW, errW}
+
+	rec.copies.Add(2)
+	go rec.copy(outR, rec.stdout)
+	go rec.copy(errR, rec.stderr)
+
+	os.Stdout, os.Stderr = outW, errW
+	return rec, nil
+}
+
+// copy passes output through to the terminal and records it. Only whole
+// lines become events, so a secret split across writes is still masked.
+func (r *recording) copy(src io.ReadCloser, dst io.Writer) {
+	defer r.copies.Done()
+	defer src.Close()
+
+	var pending []byte
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			_, _ = dst.Write(buf[:n])
+			pending = append(pending, buf[:n]...)
+			if i := bytes.LastIndexByte(pending, '\n'); i >= 0 {
+				r.record(pending[:i+1])
+				pending = append(pending[:0], pending[i+1:]...)
+			}
+		}
+		if err != nil {
+			r.record(pending)
+			return
+		}
+	}
+}
+
+// record adds output to the transcript as one event
+func (r *recording) record(p []byte) {
+	if len(p) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, castEvent{
+		Time: time.Since(r.started).Seconds(),
+		Data: redact.String(string(p)),
+	})
+}
+
+// finish restores stdout and stderr and writes the transcript, with the
+// command's result
+func (r *recording) finish(ctx context.Context, cmdErr error) error {
+	os.Stdout, os.Stderr = r.stdout, r.stderr
+	for _, w := range r.writers {
+		w.Close()
+	}
+	r.copies.Wait()
+
+	r.mu.Lock()
+	events := r.events
+	r.mu.Unlock()
+
+	header := castHeader{
+		Version:     castVersion,
+		Width:       envInt("COLUMNS", 80),
+		Height:      envInt("LINES", 24),
+		Timestamp:   r.started.Unix(),
+		Command:     redact.String(r.command),
+		Env:         recordedEnvironment(os.Environ()),
+		Environment: strings.Split(strings.TrimSpace(reproEnvironment(ctx)), "\n"),
+		Duration:    time.Since(r.started).Seconds(),
+	}
+	if cmdErr != nil {
+		header.ExitCode = 1
+		header.Error = redact.String(cmdErr.Error())
+	}
+
+	if err := writeCast(r.path, header, events); err != nil {
+		return err
+	}
+	fmt.Fprintf(consoleErr, "📼 Session recorded to %s; review it before sharing ('space replay %s')\n", r.path, r.path)
+	return nil
+}
+
+// recordedEnvironment returns the recorded environment variables, masking
+// values that look sensitive
+func recordedEnvironment(environ []string) map[string]string {
+	env := make(map[string]string)
+	for _, kv := range environ {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || !recordedEnvName(k) {
+			continue
+		}
+		if sensitiveKey.MatchString(k) {
+			v = redact.Mask
+		}
+		env[k] = redact.String(v)
+	}
+	return env
+}
+
+// recordedEnvName reports whether a variable is kept in transcripts
+func recordedEnvName(name string) bool {
+	for _, keep := range recordedEnv {
+		if name == keep || (strings.HasSuffix(keep, "_") && strings.HasPrefix(name, keep)) {
+			return true
+		}
+	}
+	return false
+}
+
+// envInt returns an integer environment variable, or def
+func envInt(name string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+// writeCast writes a transcript: the header, then one event per line
+func writeCast(path string, header castHeader, events []castEvent) error {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+	for _, e := range events {
+		if err := encoder.Encode(e); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(path, b.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// readCast reads a transcript written by --record, or any asciinema v2
+// recording. Input and marker events are skipped.
+func readCast(r io.Reader) (castHeader, []castEvent, error) {
+	var header castHeader
+	var events []castEvent
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return header, nil, err
+		}
+		return header, nil, errors.New("transcript is empty")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+		return header, nil, fmt.Errorf("invalid transcript header: %w", err)
+	}
+	if header.Version != castVersion {
+		return header, nil, fmt.Errorf("unsupported transcript version %d", header.Version)
+	}
+
+	for line := 2; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var kind []interface{}
+		if err := json.Unmarshal(data, &kind); err != nil || len(kind) != 3 {
+			return header, nil, fmt.Errorf("invalid event on line %d", line)
+		}
+		if kind[1] != "o" {
+			continue
+		}
+		var e castEvent
+		if err := json.Unmarshal(data, &e); err != nil {
+			return header, nil, fmt.Errorf("invalid event on line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	return header, events, scanner.Err()
+}
+
+func newReplayCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "replay <transcript>",
+		Short: "Print a session recorded with --record",
+		Long: `Print a transcript recorded with 'space --record <file> <command>': the
+command, the environment it ran in, its output and its exit code.
+
+Transcripts are asciinema v2 recordings, so 'asciinema play' can also play
+them back with the original timing. Known secrets and sensitive-looking
+environment variables are masked when recording; review a transcript
+before attaching it to an issue.`,
+		Example: `  space --record session.cast up
+  space replay session.cast`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			header, events, err := readCast(f)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", args[0], err)
+			}
+			printCast(os.Stdout, header, events)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// printCast pretty-prints a transcript
+func printCast(w io.Writer, header castHeader, events []castEvent) {
+	command := header.Command
+	if command == "" {
+		command = "(unknown command)"
+	}
+	fmt.Fprintf(w, "📼 %s\n", command)
+	if header.Timestamp > 0 {
+		fmt.Fprintf(w, "   recorded %s\n", time.Unix(header.Timestamp, 0).Format(time.RFC1123))
+	}
+	for _, line := range header.Environment {
+		fmt.Fprintf(w, "   %s\n", line)
+	}
+
+	names := make([]string, 0, len(header.Env))
+	for name := range header.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "   %s=%s\n", name, header.Env[name])
+	}
+
+	fmt.Fprintln(w, strings.Repeat("─", 60))
+	var last string
+	for _, e := range events {
+		fmt.Fprint(w, e.Data)
+		last = e.Data
+	}
+	if last != "" && !strings.HasSuffix(last, "\n") {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, strings.Repeat("─", 60))
+
+	took := formatElapsed(time.Duration(header.Duration * float64(time.Second)))
+	if header.ExitCode == 0 {
+		fmt.Fprintf(w, "✅ Exited with code 0 after %s\n", took)
+		return
+	}
+	fmt.Fprintf(w, "❌ Exited with code %d after %s\n", header.ExitCode, took)
+	if header.Error != "" {
+		fmt.Fprintf(w, "   Error: %s\n", header.Error)
+	}
+}
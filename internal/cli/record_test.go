@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/redact"
+)
+
+func TestRecordingRoundTrip(t *testing.T) {
+	redact.Add("hunter2-secret")
+	path := filepath.Join(t.TempDir(), "session.cast")
+
+	var rec *recording
+	passthrough := stdout(t, func() {
+		var err error
+		rec, err = startRecording(path, []string{"up", "api"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Println("🚀 Starting services")
+		fmt.Print("password hunter2-")
+		fmt.Println("secret")
+		fmt.Fprint(os.Stderr, "partial")
+		if err := rec.finish(context.Background(), errors.New("compose failed")); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if !strings.Contains(passthrough, "password hunter2-secret") {
+		t.Errorf("output not passed through: %q", passthrough)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	header, events, err := readCast(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if header.Command != "space up api" || header.ExitCode != 1 || header.Error != "compose failed" {
+		t.Errorf("header = %+v", header)
+	}
+	var output strings.Builder
+	for _, e := range events {
+		output.WriteString(e.Data)
+	}
+	// stdout and stderr are read concurrently, so only each stream keeps its order
+	want := "🚀 Starting services\npassword " + redact.Mask + "\n"
+	if got := output.String(); !strings.Contains(got, want) || !strings.Contains(got, "partial") {
+		t.Errorf("recorded output = %q", got)
+	}
+}
+
+func TestRecordedEnvironment(t *testing.T) {
+	env := recordedEnvironment([]string{
+		"SHELL=/bin/zsh",
+		"HOME=/Users/dev",
+		"DOCKER_HOST=unix:///var/run/docker.sock",
+		"SPACE_API_TOKEN=abc123",
+		"COMPOSE_PROFILES=web",
+	})
+
+	want := map[string]string{
+		"SHELL":            "/bin/zsh",
+		"DOCKER_HOST":      "unix:///var/run/docker.sock",
+		"SPACE_API_TOKEN":  redact.Mask,
+		"COMPOSE_PROFILES": "web",
+	}
+	if len(env) != len(want) {
+		t.Errorf("env = %v", env)
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("%s = %q, want %q", k, env[k], v)
+		}
+	}
+}
+
+func TestReadCast(t *testing.T) {
+	cast := `{"version": 2, "width": 80, "height": 24, "timestamp": 1700000000}
+[0.1, "o", "hello "]
+[0.2, "i", "q"]
+[0.3, "o", "world\n"]
+`
+	header, events, err := readCast(strings.NewReader(cast))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Width != 80 || len(events) != 2 || events[1].Data != "world\n" {
+		t.Errorf("header = %+v, events = %+v", header, events)
+	}
+
+	if _, _, err := readCast(strings.NewReader(`{"version": 1}`)); err == nil {
+		t.Error("expected an error for asciinema v1")
+	}
+	if _, _, err := readCast(strings.NewReader("{\"version\": 2}\nnot json\n")); err == nil {
+		t.Error("expected an error for a broken event")
+	}
+}
+
+func TestPrintCast(t *testing.T) {
+	var b strings.Builder
+	printCast(&b, castHeader{
+		Command:     "space up",
+		Environment: []string{"space: dev"},
+		Env:         map[string]string{"SHELL": "/bin/zsh"},
+		Duration:    2.5,
+		ExitCode:    1,
+		Error:       "compose failed",
+	}, []castEvent{{Time: 0.1, Data: "no newline"}})
+
+	out := b.String()
+	for _, want := range []string{"📼 space up", "   space: dev", "   SHELL=/bin/zsh", "no newline\n", "❌ Exited with code 1 after 2.5s", "Error: compose failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
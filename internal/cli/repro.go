@@ -0,0 +1,375 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/internal/provider"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// reproImage replaces every service image in a reproduction
+const reproImage = "busybox:latest"
+
+// sensitiveKey matches environment variable names whose values are masked
+var sensitiveKey = regexp.MustCompile(`(?i)(pass|secret|token|key|credential|auth|private|cert)`)
+
+func newReproCommand() *cobra.Command {
+	var (
+		output string
+		tail   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "repro",
+		Short: "Package the project for a bug report",
+		Long: `Package everything needed to reproduce a problem into a tarball:
+
+  • the merged configuration
+  • the compose model with every image replaced by busybox
+  • the hook list
+  • provider, platform and version information
+  • recent logs of every service
+
+Secrets, database passwords and environment variables that look sensitive
+are masked. The compose model can be dropped into e2e/fixtures/ as a new
+fixture. Review the archive before sharing it.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			sc, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+			registerSensitiveValues(sc.cfg)
+
+			project, err := loadComposeProject(sc.workDir, sc.cfg)
+			if err != nil {
+				return fmt.Errorf("failed to load compose files: %w", err)
+			}
+
+			name := "space-repro-" + sc.projectName
+			if output == "" {
+				output = name + "-" + time.Now().Format("20060102-150405") + ".tar.gz"
+			}
+
+			files := make(map[string][]byte)
+
+			configData, err := reproConfig(sc.cfg)
+			if err != nil {
+				return err
+			}
+			files["space.yaml"] = configData
+
+			composeData, err := yaml.Marshal(reproComposeModel(project))
+			if err != nil {
+				return fmt.Errorf("failed to marshal compose model: %w", err)
+			}
+			files["docker-compose.yml"] = []byte(redact.String(string(composeData)))
+
+			files["hooks.txt"] = []byte(reproHooks(sc.workDir))
+			files["environment.txt"] = []byte(reproEnvironment(ctx))
+
+			err = step("collecting service logs", false, func() error {
+				for _, service := range project.ServiceNames() {
+					files["logs/"+service+".log"] = []byte(reproLogs(ctx, sc.workDir, sc.projectName, service, tail))
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			files["README.md"] = []byte(reproReadme(sc.projectName))
+
+			if err := writeReproArchive(output, name, files); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(console, "📦 Wrote %s (%d files)\n", output, len(files))
+			fmt.Fprintln(console, "💡 Secrets are masked, but review the archive before sharing it")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Archive path (default: space-repro-<project>-<time>.tar.gz)")
+	cmd.Flags().IntVar(&tail, "tail", 200, "Log lines to include per service")
+
+	return cmd
+}
+
+// reproConfig renders the merged configuration with sensitive values masked
+func reproConfig(cfg *config.Config) ([]byte, error) {
+	sanitized := *cfg
+
+	sanitized.Services = make(map[string]config.ServiceConfig, len(cfg.Services))
+	for name, svc := range cfg.Services {
+		svc.Environment = maskEnvironment(svc.Environment)
+		sanitized.Services[name] = svc
+	}
+
+	sanitized.Databases = append([]config.DatabaseConfig(nil), cfg.Databases...)
+	for i := range sanitized.Databases {
+		if sanitized.Databases[i].Password != "" {
+			sanitized.Databases[i].Password = redact.Mask
+		}
+	}
+
+	data, err := yaml.Marshal(&sanitized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %w", err)
+	}
+	return []byte(redact.String(string(data))), nil
+}
+
+// maskEnvironment masks the values of sensitive-looking variables
+func maskEnvironment(env map[string]string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	masked := make(map[string]string, len(env))
+	for k, v := range env {
+		if sensitiveKey.MatchString(k) && v != "" {
+			v = redact.Mask
+		}
+		masked[k] = v
+	}
+	return masked
+}
+
+// reproComposeModel returns a copy of the compose model that runs anywhere:
+// images become busybox idling forever, builds, bind mounts, env files and
+// secrets are dropped, healthchecks always pass and sensitive environment
+// values are masked. Service names, ports, networks, named volumes and
+// dependencies are kept, since they usually matter for the bug.
+func reproComposeModel(project *compose.Project) map[string]interface{} {
+	model := make(map[string]interface{})
+	if project == nil {
+		return model
+	}
+
+	// Deep copy through YAML so the loaded model isn't modified
+	data, err := yaml.Marshal(project.Data)
+	if err != nil || yaml.Unmarshal(data, &model) != nil {
+		return model
+	}
+
+	delete(model, "secrets")
+	delete(model, "configs")
+
+	services, _ := model["services"].(map[string]interface{})
+	for _, raw := range services {
+		svc, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		svc["image"] = reproImage
+		svc["command"] = []interface{}{"sh", "-c", "sleep infinity"}
+		for _, key := range []string{"build", "entrypoint", "env_file", "secrets", "configs", "working_dir", "user"} {
+			delete(svc, key)
+		}
+
+		if _, ok := svc["healthcheck"]; ok {
+			svc["healthcheck"] = map[string]interface{}{"test": []interface{}{"CMD", "true"}, "interval": "2s"}
+		}
+
+		if volumes, ok := svc["volumes"].([]interface{}); ok {
+			var named []interface{}
+			for _, v := range volumes {
+				if !isBindMount(v) {
+					named = append(named, v)
+				}
+			}
+			if len(named) > 0 {
+				svc["volumes"] = named
+			} else {
+				delete(svc, "volumes")
+			}
+		}
+
+		switch env := svc["environment"].(type) {
+		case map[string]interface{}:
+			for k, v := range env {
+				if sensitiveKey.MatchString(k) && v != nil {
+					env[k] = redact.Mask
+				}
+			}
+		case []interface{}:
+			for i, item := range env {
+				s, ok := item.(string)
+				if !ok {
+					continue
+				}
+				if k, _, found := strings.Cut(s, "="); found && sensitiveKey.MatchString(k) {
+					env[i] = k + "=" + redact.Mask
+				}
+			}
+		}
+	}
+
+	return model
+}
+
+// isBindMount reports whether a compose volume entry mounts a host path
+func isBindMount(v interface{}) bool {
+	switch vol := v.(type) {
+	case string:
+		source, _, hasTarget := strings.Cut(vol, ":")
+		return hasTarget && (strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~"))
+	case map[string]interface{}:
+		return vol["type"] == "bind"
+	}
+	return false
+}
+
+// reproHooks lists the hook scripts and whether Go hooks are defined
+func reproHooks(workDir string) string {
+	var b strings.Builder
+	for _, event := range hooks.AllEventTypes() {
+		entries, err := os.ReadDir(filepath.Join(workDir, ".space", "hooks", string(event)+".d"))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || entry.Name() == ".gitkeep" || filepath.Ext(entry.Name()) == ".template" {
+				continue
+			}
+			mode := "not executable"
+			if info, err := entry.Info(); err == nil && info.Mode()&0111 != 0 {
+				mode = "executable"
+			}
+			fmt.Fprintf(&b, "%s/%s (%s)\n", event, entry.Name(), mode)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(workDir, ".space", hooks.GoHooksFile)); err == nil {
+		fmt.Fprintf(&b, ".space/%s (Go hooks)\n", hooks.GoHooksFile)
+	}
+	if b.Len() == 0 {
+		return "no hooks\n"
+	}
+	return b.String()
+}
+
+// reproEnvironment describes the provider, platform and tool versions
+func reproEnvironment(ctx context.Context) string {
+	detected, err := provider.NewDetector().Detect(ctx)
+	providerInfo := detected.String()
+	if err != nil {
+		providerInfo = fmt.Sprintf("unknown (%v)", err)
+	}
+
+	build := currentBuild()
+	lines := []string{
+		"space: " + build.String(),
+		"go: " + build.Go,
+		"os/arch: " + runtime.GOOS + "/" + runtime.GOARCH,
+		"provider: " + providerInfo,
+		"docker: " + commandVersion(ctx, "docker", "version", "--format", "{{.Server.Version}}"),
+		"docker compose: " + commandVersion(ctx, "docker", "compose", "version", "--short"),
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// commandVersion runs a version command, returning "unavailable" on failure
+func commandVersion(ctx context.Context, name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return "unavailable"
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// reproLogs returns the recent logs of a service with secrets masked
+func reproLogs(ctx context.Context, workDir, projectName, service string, tail int) string {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "docker", "compose", "-p", projectName,
+		"logs", "--no-color", "--timestamps", "--tail", fmt.Sprint(tail), service)
+	cmd.Dir = workDir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		err = timeoutError(ctx, "docker compose logs", dockerQueryTimeout, err)
+		return fmt.Sprintf("logs unavailable: %v\n%s", err, redact.String(string(out)))
+	}
+	return redact.String(string(out))
+}
+
+// reproReadme explains the archive contents
+func reproReadme(projectName string) string {
+	return fmt.Sprintf(`# space repro: %s
+
+Created %s by space %s.
+
+- space.yaml: merged configuration (secrets masked)
+- docker-compose.yml: compose model with every image replaced by %s
+- hooks.txt: hook scripts found in .space/
+- environment.txt: space build, provider, platform and tool versions
+- logs/: recent logs per service
+
+To turn this into an e2e fixture, copy docker-compose.yml (and space.yaml as
+.space.yaml if the bug depends on it) into e2e/fixtures/<name>/.
+`, projectName, time.Now().Format(time.RFC3339), currentBuild(), reproImage)
+}
+
+// writeReproArchive writes files into a gzipped tarball below dir
+func writeReproArchive(path, dir string, files map[string][]byte) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		data := files[name]
+		hdr := &tar.Header{
+			Name:    dir + "/" + name,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
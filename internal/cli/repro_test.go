@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestReproComposeModel(t *testing.T) {
+	var data map[string]interface{}
+	err := yaml.Unmarshal([]byte(`
+services:
+  api:
+    build: .
+    image: shop/api
+    entrypoint: ["/app/start"]
+    env_file: .env
+    environment:
+      DB_PASSWORD: hunter22
+      LOG_LEVEL: debug
+    volumes:
+      - ./src:/app
+      - cache:/cache
+    healthcheck:
+      test: ["CMD", "curl", "-f", "http://localhost/health"]
+    ports:
+      - "8080:80"
+  worker:
+    image: shop/worker
+    environment:
+      - API_TOKEN=abc123
+      - QUEUE=jobs
+secrets:
+  stripe:
+    file: ./stripe.key
+volumes:
+  cache: {}
+`), &data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := reproComposeModel(&compose.Project{Data: data})
+	out, err := yaml.Marshal(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(out)
+
+	for _, want := range []string{"image: busybox:latest", "sleep infinity", "DB_PASSWORD: '****'", "LOG_LEVEL: debug",
+		"API_TOKEN=****", "QUEUE=jobs", "cache:/cache", "8080:80", "- \"true\""} {
+		if !strings.Contains(got, want) {
+			t.Errorf("model missing %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"build", "entrypoint", "env_file", "./src", "hunter22", "abc123", "stripe", "curl", "shop/api"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("model still contains %q:\n%s", unwanted, got)
+		}
+	}
+
+	// The loaded model is left alone
+	if data["services"].(map[string]interface{})["api"].(map[string]interface{})["image"] != "shop/api" {
+		t.Error("reproComposeModel modified the loaded model")
+	}
+}
+
+func TestReproConfig(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"api": {Port: 8080, Environment: map[string]string{"API_KEY": "k3y-value", "MODE": "dev"}},
+		},
+		Databases: []config.DatabaseConfig{{Name: "shop", Password: "pa55word"}},
+	}
+
+	data, err := reproConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	if strings.Contains(got, "k3y-value") || strings.Contains(got, "pa55word") || !strings.Contains(got, "MODE: dev") {
+		t.Errorf("reproConfig() =\n%s", got)
+	}
+	if cfg.Services["api"].Environment["API_KEY"] != "k3y-value" || cfg.Databases[0].Password != "pa55word" {
+		t.Error("reproConfig modified the configuration")
+	}
+	if !strings.Contains(got, redact.Mask) {
+		t.Errorf("expected masked values in:\n%s", got)
+	}
+}
+
+func TestWriteReproArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "repro.tar.gz")
+	files := map[string][]byte{"space.yaml": []byte("project: {}\n"), "logs/api.log": []byte("started\n")}
+	if err := writeReproArchive(path, "space-repro-shop", files); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+	if got := strings.Join(names, ","); got != "space-repro-shop/logs/api.log,space-repro-shop/space.yaml" {
+		t.Errorf("archive entries = %s", got)
+	}
+}
@@ -1,6 +1,10 @@
 package cli
 
 import (
+	"context"
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 )
 
@@ -8,8 +12,27 @@ var (
 	// Version is set at build time
 	Version = "dev"
 
+	// GitCommit is the commit the binary was built from, set at build time
+	GitCommit string
+
+	// BuildTime is when the binary was built (RFC 3339, UTC), set at build
+	// time
+	BuildTime string
+
 	// Workdir is the working directory
 	Workdir string
+
+	// Quiet suppresses tips, banners and URL listings, keeping results,
+	// warnings and errors
+	Quiet bool
+
+	// NoPager prints long output straight to the terminal instead of
+	// through $PAGER
+	NoPager bool
+
+	// ConfigFile replaces the discovered project config when set; "-" reads
+	// it from standard input
+	ConfigFile string
 )
 
 // rootCmd represents the base command
@@ -24,24 +47,63 @@ Features:
   • Provider-aware networking (OrbStack DNS, Docker port mapping)
   • Lifecycle hooks for automation
   • Custom commands support`,
-	Version: Version,
+	Version: currentBuild().String(),
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() error {
-	return rootCmd.Execute()
+	registerProjectCommands(rootCmd, os.Args[1:])
+	err := rootCmd.Execute()
+	if activeRecording != nil {
+		if recErr := activeRecording.finish(context.Background(), err); recErr != nil {
+			fmt.Fprintf(consoleErr, "⚠️  Could not save the recording: %v\n", recErr)
+		}
+	}
+	return err
 }
 
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&Workdir, "workdir", "w", ".", "working directory")
+	rootCmd.PersistentFlags().BoolVar(&Quiet, "quiet", false, "only print results, warnings and errors: no tips, banners or URL listings")
+	rootCmd.PersistentFlags().BoolVar(&NoPager, "no-pager", false, "do not page output taller than the terminal through $PAGER")
+	rootCmd.PersistentFlags().StringVar(&ConfigFile, "config", "", "project config file to use instead of .space.yaml (- for stdin); paths in it resolve against the working directory")
+	enableTimings(rootCmd)
+	enableRecording(rootCmd)
 
 	// Add subcommands
+	rootCmd.AddCommand(newInitCommand())
 	rootCmd.AddCommand(newUpCommand())
 	rootCmd.AddCommand(newDownCommand())
+	rootCmd.AddCommand(newPruneCommand())
 	rootCmd.AddCommand(newPsCommand())
+	rootCmd.AddCommand(paged(newLinksCommand()))
+	rootCmd.AddCommand(newOpenCommand())
+	rootCmd.AddCommand(newForwardCommand())
+	rootCmd.AddCommand(newTunnelCommand())
+	rootCmd.AddCommand(newUICommand())
+	rootCmd.AddCommand(newArchCommand())
+	rootCmd.AddCommand(newLogsCommand())
+	rootCmd.AddCommand(newShellCommand())
+	rootCmd.AddCommand(newRestartCommand())
+	rootCmd.AddCommand(newServiceCommand())
+	rootCmd.AddCommand(newWorktreeCommand())
+	rootCmd.AddCommand(newGitHooksCommand())
+	rootCmd.AddCommand(newStatusCommand())
+	rootCmd.AddCommand(newHealthCommand())
 	rootCmd.AddCommand(newConfigCommand())
+	rootCmd.AddCommand(newSetupCommand())
+	rootCmd.AddCommand(paged(newDoctorCommand()))
 	rootCmd.AddCommand(newDNSCommand())
+	rootCmd.AddCommand(newExplainCommand())
+	rootCmd.AddCommand(newAgentdCommand())
+	rootCmd.AddCommand(newGRPCCommand())
 	rootCmd.AddCommand(newHooksCommand())
+	rootCmd.AddCommand(newEventsCommand())
+	rootCmd.AddCommand(newSecretsCommand())
 	rootCmd.AddCommand(newRunCommand())
+	rootCmd.AddCommand(newReproCommand())
+	rootCmd.AddCommand(newReplayCommand())
+	rootCmd.AddCommand(newCheckpointCommand())
+	rootCmd.AddCommand(newVersionCommand())
 }
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/internal/secrets"
+	"github.com/happy-sdk/space-cli/internal/statedir"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newSecretsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage project secrets",
+		Long: `Manage secrets declared in the secrets block of .space.yaml.
+
+Secrets are resolved from the environment, the macOS keychain or the
+1Password CLI at up time and passed to containers through ephemeral files
+outside the project directory.`,
+	}
+
+	cmd.AddCommand(newSecretsCheckCommand())
+
+	return cmd
+}
+
+func newSecretsCheckCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "check",
+		Short: "Verify all secrets can be resolved",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Get working directory
+			workDir := Workdir
+			if workDir == "." {
+				var err error
+				workDir, err = os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get working directory: %w", err)
+				}
+			}
+
+			// Make absolute
+			workDir, err := filepath.Abs(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+
+			// Create loader
+			loader, err := newConfigLoader(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to create config loader: %w", err)
+			}
+
+			// Load configuration
+			cfg, err := loader.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+
+			if len(cfg.Secrets) == 0 {
+				fmt.Fprintln(console, "No secrets configured.")
+				return nil
+			}
+
+			errs := secrets.NewResolver().Check(context.Background(), cfg.Secrets)
+
+			names := make([]string, 0, len(cfg.Secrets))
+			for name := range cfg.Secrets {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			for _, name := range names {
+				source := cfg.Secrets[name].Source
+				if source == "" {
+					source = secrets.SourceEnv
+				}
+				if err, failed := errs[name]; failed {
+					fmt.Fprintf(console, "❌ %s (%s): %v\n", name, source, err)
+				} else {
+					fmt.Fprintf(console, "✅ %s (%s)\n", name, source)
+				}
+			}
+
+			if len(errs) > 0 {
+				return fmt.Errorf("%d of %d secrets could not be resolved", len(errs), len(cfg.Secrets))
+			}
+			return nil
+		},
+	}
+}
+
+// registerSensitiveValues registers database passwords and secrets that can be
+// read without prompting (env sources) with the output redactor. Secrets from
+// other sources are registered when they are resolved.
+func registerSensitiveValues(cfg *config.Config) {
+	for _, db := range cfg.Databases {
+		redact.Add(db.Password)
+	}
+	for name, secret := range cfg.Secrets {
+		if secret.Source != "" && secret.Source != secrets.SourceEnv {
+			continue
+		}
+		key := secret.Key
+		if key == "" {
+			key = name
+		}
+		redact.Add(os.Getenv(key))
+	}
+}
+
+// secretsDir returns the ephemeral directory secrets of a project are
+// rendered into. It lives outside the project so secrets never end up in the
+// repo, and below the per-user temp directory so other users can't read them.
+func secretsDir(projectName string) (string, error) {
+	if dryRun != nil {
+		return secretsPath(projectName), nil
+	}
+	if _, err := statedir.TempDir(); err != nil {
+		return "", err
+	}
+	return secretsPath(projectName), nil
+}
+
+// secretsPath returns where secretsDir is without creating anything, for
+// dry runs and previews
+func secretsPath(projectName string) string {
+	return filepath.Join(statedir.TempDirPath(), "secrets", projectName)
+}
+
+// renderSecrets resolves the configured secrets and writes a compose override
+// referencing them. It returns the override path, or "" if no secrets are configured.
+func renderSecrets(ctx context.Context, cfg *config.Config, project *compose.Project, projectName string) (string, error) {
+	if len(cfg.Secrets) == 0 {
+		return "", nil
+	}
+
+	var services []string
+	if project != nil {
+		services = project.ServiceNames()
+	}
+
+	dir, err := secretsDir(projectName)
+	if err != nil {
+		return "", err
+	}
+	doc, err := secrets.NewResolver().Render(ctx, dir, cfg.Secrets, services)
+	if err != nil || doc == "" {
+		return "", err
+	}
+
+	overrideFile := filepath.Join(dir, "compose.yml")
+	header := "# Auto-generated secrets override - do not commit\n\n"
+	if err := os.WriteFile(overrideFile, []byte(header+doc), 0600); err != nil {
+		return "", fmt.Errorf("failed to write secrets override: %w", err)
+	}
+	return overrideFile, nil
+}
+
+// removeSecrets deletes the rendered secrets of a project
+func removeSecrets(projectName string) error {
+	dir, err := secretsDir(projectName)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// selectionsFile stores the last service chosen per command
+const selectionsFile = "selections.json"
+
+// isInteractive reports whether stdin is a terminal
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// stateDir returns the per-project state directory
+func stateDir(workDir string) string {
+	return filepath.Join(workDir, ".space", "state")
+}
+
+// loadSelections reads the remembered service choices
+func loadSelections(workDir string) map[string]string {
+	selections := make(map[string]string)
+	data, err := os.ReadFile(filepath.Join(stateDir(workDir), selectionsFile))
+	if err == nil {
+		_ = json.Unmarshal(data, &selections)
+	}
+	return selections
+}
+
+// saveSelection remembers the service chosen for a command
+func saveSelection(workDir, command, service string) error {
+	selections := loadSelections(workDir)
+	selections[command] = service
+
+	data, err := json.MarshalIndent(selections, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(stateDir(workDir), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(stateDir(workDir), selectionsFile), data, 0644)
+}
+
+// selectService resolves the service argument of a command. Without a
+// terminal it fails with the list of services; otherwise the user picks one
+// from a filterable list, defaulting to the last choice for the command.
+func selectService(command, workDir string, services []string) (string, error) {
+	if len(services) == 0 {
+		return "", fmt.Errorf("no services found")
+	}
+	if !isInteractive() {
+		return "", fmt.Errorf("a service is required, available: %s", strings.Join(services, ", "))
+	}
+
+	last := loadSelections(workDir)[command]
+	service, err := promptService(os.Stdin, os.Stdout, services, last)
+	if err != nil {
+		return "", err
+	}
+
+	if err := saveSelection(workDir, command, service); err != nil {
+		fmt.Fprintf(console, "⚠️  Failed to remember selection: %v\n", err)
+	}
+	return service, nil
+}
+
+// promptService shows the services and reads the choice. Typing text narrows
+// the list with fuzzy matching, a number picks an entry and an empty line
+// picks the default.
+func promptService(in io.Reader, out io.Writer, services []string, def string) (string, error) {
+	reader := bufio.NewReader(in)
+	candidates := services
+
+	for {
+		if len(candidates) == 1 {
+			return candidates[0], nil
+		}
+
+		fmt.Fprintln(out, "📋 Select a service:")
+		for i, name := range candidates {
+			marker := " "
+			if name == def {
+				marker = "*"
+			}
+			fmt.Fprintf(out, "  %s %d) %s\n", marker, i+1, name)
+		}
+
+		prompt := "Number or filter"
+		if containsString(candidates, def) {
+			prompt += fmt.Sprintf(" [%s]", def)
+		}
+		fmt.Fprintf(out, "%s: ", prompt)
+
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("no service selected")
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "" && containsString(candidates, def):
+			return def, nil
+		case line == "":
+			continue
+		}
+
+		if n, err := strconv.Atoi(line); err == nil {
+			if n >= 1 && n <= len(candidates) {
+				return candidates[n-1], nil
+			}
+			fmt.Fprintf(out, "❌ %d is not in the list\n", n)
+			continue
+		}
+
+		filtered := fuzzyFilter(candidates, line)
+		if len(filtered) == 0 {
+			fmt.Fprintf(out, "❌ No service matches %q\n", line)
+			continue
+		}
+		candidates = filtered
+	}
+}
+
+// fuzzyFilter returns the names containing the characters of pattern in order
+func fuzzyFilter(names []string, pattern string) []string {
+	pattern = strings.ToLower(pattern)
+
+	var matches []string
+	for _, name := range names {
+		if fuzzyMatch(strings.ToLower(name), pattern) {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
+
+// fuzzyMatch reports whether pattern is a subsequence of s
+func fuzzyMatch(s, pattern string) bool {
+	want := []rune(pattern)
+	i := 0
+	for _, c := range s {
+		if i < len(want) && want[i] == c {
+			i++
+		}
+	}
+	return i == len(want)
+}
+
+// containsString reports whether list holds s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFuzzyFilter(t *testing.T) {
+	services := []string{"api", "api-worker", "postgres", "web"}
+
+	tests := map[string][]string{
+		"api": {"api", "api-worker"},
+		"awk": {"api-worker"},
+		"PG":  {"postgres"},
+		"xyz": nil,
+		"":    services,
+	}
+	for pattern, want := range tests {
+		if got := fuzzyFilter(services, pattern); !reflect.DeepEqual(got, want) {
+			t.Errorf("fuzzyFilter(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+}
+
+func TestPromptService(t *testing.T) {
+	services := []string{"api", "api-worker", "postgres", "web"}
+
+	tests := []struct {
+		name  string
+		input string
+		def   string
+		want  string
+	}{
+		{name: "number", input: "3\n", want: "postgres"},
+		{name: "default", input: "\n", def: "web", want: "web"},
+		{name: "filter to one", input: "wkr\n", want: "api-worker"},
+		{name: "filter then number", input: "api\n2\n", want: "api-worker"},
+		{name: "retry after no match", input: "zzz\n9\nweb\n", want: "web"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := promptService(strings.NewReader(tt.input), &out, services, tt.def)
+			if err != nil {
+				t.Fatalf("promptService() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("promptService() = %q, want %q\n%s", got, tt.want, out.String())
+			}
+		})
+	}
+
+	if _, err := promptService(strings.NewReader(""), &bytes.Buffer{}, services, ""); err == nil {
+		t.Error("expected an error when input ends without a choice")
+	}
+}
+
+func TestSaveSelection(t *testing.T) {
+	workDir := t.TempDir()
+
+	if got := loadSelections(workDir); len(got) != 0 {
+		t.Fatalf("loadSelections() = %v, want empty", got)
+	}
+
+	if err := saveSelection(workDir, "logs", "api"); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveSelection(workDir, "shell", "postgres"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"logs": "api", "shell": "postgres"}
+	if got := loadSelections(workDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("loadSelections() = %v, want %v", got, want)
+	}
+}
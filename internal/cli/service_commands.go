@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// serviceCommandContext is the loaded project a per-service command runs against
+type serviceCommandContext struct {
+	workDir     string
+	cfg         *config.Config
+	projectName string
+	services    []string
+}
+
+// loadServiceCommandContext loads the configuration and service list of the
+// project in the working directory
+func loadServiceCommandContext() (*serviceCommandContext, error) {
+	// Get working directory
+	workDir := Workdir
+	if workDir == "." {
+		var err error
+		workDir, err = os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+	}
+
+	// Make absolute
+	workDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve working directory: %w", err)
+	}
+
+	return loadProjectContext(workDir)
+}
+
+// loadProjectContext loads the configuration and service list of the
+// project in workDir, which must be absolute
+func loadProjectContext(workDir string) (*serviceCommandContext, error) {
+	// Create loader
+	loader, err := newConfigLoader(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config loader: %w", err)
+	}
+
+	// Load configuration
+	cfg, err := loader.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	services := sortedServiceNames(cfg)
+	if project, err := loadComposeProject(workDir, cfg); err == nil {
+		applyComposeServices(cfg, project)
+		services = project.ServiceNames()
+	}
+
+	return &serviceCommandContext{
+		workDir:     workDir,
+		cfg:         cfg,
+		projectName: generateProjectName(cfg, workDir),
+		services:    services,
+	}, nil
+}
+
+// service returns the service named in args, or asks the user to pick one
+func (c *serviceCommandContext) service(command string, args []string) (string, error) {
+	if len(args) > 0 {
+		if err := checkServiceNames(c.cfg, args[:1], c.services); err != nil {
+			return "", err
+		}
+		return args[0], nil
+	}
+	return selectService(command, c.workDir, c.services)
+}
+
+// serviceList returns the services named in args with groups expanded, or
+// asks the user to pick one
+func (c *serviceCommandContext) serviceList(command string, args []string) ([]string, error) {
+	if len(args) == 0 {
+		service, err := selectService(command, c.workDir, c.services)
+		if err != nil {
+			return nil, err
+		}
+		return []string{service}, nil
+	}
+	services, err := c.cfg.ExpandGroups(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkServiceNames(c.cfg, services, c.services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// compose runs a docker compose subcommand for the project attached to the terminal
+func (c *serviceCommandContext) compose(args ...string) error {
+	composeCmd := append(composeCommand(c.workDir, c.cfg, composeFilesFor(c.workDir, c.cfg), c.projectName), args...)
+
+	dockerCmd := exec.Command(composeCmd[0], composeCmd[1:]...)
+	dockerCmd.Dir = c.workDir
+	dockerCmd.Stdout = os.Stdout
+	dockerCmd.Stderr = os.Stderr
+	dockerCmd.Stdin = os.Stdin
+
+	return dockerCmd.Run()
+}
+
+// newLogsCommand creates the logs command
+func newLogsCommand() *cobra.Command {
+	var follow bool
+	var tail int
+
+	cmd := &cobra.Command{
+		Use:   "logs [services or groups...]",
+		Short: "Show service logs",
+		Long: `Show the logs of services; group names expand to their members. Without
+a service, pick one from a list (the last choice is preselected).`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			services, err := ctx.serviceList("logs", args)
+			if err != nil {
+				return err
+			}
+
+			composeArgs := []string{"logs"}
+			if follow {
+				composeArgs = append(composeArgs, "--follow")
+			}
+			if tail >= 0 {
+				composeArgs = append(composeArgs, "--tail", strconv.Itoa(tail))
+			}
+			return ctx.compose(append(composeArgs, services...)...)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Follow log output")
+	cmd.Flags().IntVarP(&tail, "tail", "n", -1, "Number of lines to show from the end (default all)")
+
+	return cmd
+}
+
+// newShellCommand creates the shell command
+func newShellCommand() *cobra.Command {
+	var shell string
+
+	cmd := &cobra.Command{
+		Use:   "shell [service]",
+		Short: "Open a shell in a service container",
+		Long: `Open an interactive shell in a running service container, using the
+service's configured shell, or bash when available and sh otherwise.
+Without a service, pick one from a list.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			service, err := ctx.service("shell", args)
+			if err != nil {
+				return err
+			}
+
+			// --shell, then the service's configured shell, then bash or sh
+			if shell == "" {
+				shell = ctx.cfg.Services[service].Shell
+			}
+			command := []string{"sh", "-c", "command -v bash >/dev/null && exec bash || exec sh"}
+			if shell != "" {
+				command = strings.Fields(shell)
+			}
+			return ctx.compose(append([]string{"exec", service}, command...)...)
+		},
+	}
+
+	cmd.Flags().StringVarP(&shell, "shell", "s", "", "Shell command to run (default: service shell, bash or sh)")
+
+	return cmd
+}
+
+// newRestartCommand creates the restart command
+func newRestartCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restart [services or groups...]",
+		Short: "Restart services",
+		Long: `Restart service containers; group names expand to their members. Without
+a service, pick one from a list.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			services, err := ctx.serviceList("restart", args)
+			if err != nil {
+				return err
+			}
+
+			names := strings.Join(services, ", ")
+			fmt.Fprintf(console, "🔄 Restarting %s...\n", names)
+			if err := ctx.compose(append([]string{"restart"}, services...)...); err != nil {
+				return fmt.Errorf("failed to restart %s: %w", names, err)
+			}
+			fmt.Fprintf(console, "✅ %s restarted\n", names)
+
+			// A restarted container may come back with a new IP
+			if hostsMode(ctx.cfg) {
+				if err := syncHostsFile(context.Background(), ctx.cfg, ctx.workDir, ctx.projectName); err != nil {
+					fmt.Fprintf(console, "⚠️  Failed to update hosts file: %v\n", err)
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// newServiceCommand creates the service command
+func newServiceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "service",
+		Short: "Add or remove services in .space.yaml",
+		Long: `Edit the services block of .space.yaml, keeping its comments.
+
+Use --compose with add to also append a service stub to the compose file.`,
+	}
+
+	cmd.AddCommand(newServiceAddCommand())
+	cmd.AddCommand(newServiceRemoveCommand())
+
+	return cmd
+}
+
+// newServiceAddCommand creates the service add command
+func newServiceAddCommand() *cobra.Command {
+	var (
+		port        int
+		health      string
+		protocol    string
+		withCompose bool
+		image       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <service>",
+		Short: "Add a service to .space.yaml",
+		Example: `  space service add api --port 6060 --health /healthz
+  space service add cache --port 6379 --protocol tcp --compose --image redis:7`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+			name := args[0]
+
+			svc := config.ServiceConfig{Port: port, Protocol: protocol}
+			if health != "" {
+				svc.HealthCheck = &config.HealthCheckConfig{Enabled: true, Endpoint: health}
+			}
+
+			// Check the config edit before touching the compose file
+			file, data, err := editConfigFile(c.workDir, func(data []byte) ([]byte, error) {
+				return config.AddService(data, name, svc)
+			})
+			if err != nil {
+				return err
+			}
+
+			if withCompose {
+				composeFile, err := addComposeStub(c.workDir, c.cfg, name, image, port)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(console, "✅ Added %s to %s\n", name, filepath.Base(composeFile))
+			}
+
+			if err := os.WriteFile(file, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+			fmt.Fprintf(console, "✅ Added %s to %s\n", name, filepath.Base(file))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&port, "port", 0, "Container port of the service")
+	cmd.Flags().StringVar(&health, "health", "", "Enable the health check with this endpoint (e.g. /healthz)")
+	cmd.Flags().StringVar(&protocol, "protocol", "", "Protocol: http (default), tcp or grpc")
+	cmd.Flags().BoolVar(&withCompose, "compose", false, "Also add a service stub to the compose file")
+	cmd.Flags().StringVar(&image, "image", "", "Image of the compose stub (default: build the project directory)")
+
+	return cmd
+}
+
+// newServiceRemoveCommand creates the service remove command
+func newServiceRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <service>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a service from .space.yaml",
+		Long: `Remove a service from .space.yaml and from the groups listing it.
+The compose file is left unchanged.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+			name := args[0]
+
+			file, data, err := editConfigFile(c.workDir, func(data []byte) ([]byte, error) {
+				out, removed, err := config.RemoveService(data, name)
+				if err == nil && !removed {
+					err = fmt.Errorf("service %s is not configured", name)
+				}
+				return out, err
+			})
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(file, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", file, err)
+			}
+			fmt.Fprintf(console, "✅ Removed %s from %s\n", name, filepath.Base(file))
+			return nil
+		},
+	}
+}
+
+// editConfigFile applies edit to the project config file, or to a new
+// .space.yaml if the project has none. It returns the file path and the
+// edited content for the caller to write.
+func editConfigFile(workDir string, edit func([]byte) ([]byte, error)) (string, []byte, error) {
+	loader, err := newConfigLoader(workDir)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create config loader: %w", err)
+	}
+
+	if ConfigFile == config.StdinConfigFile {
+		return "", nil, fmt.Errorf("can't edit a config read from standard input")
+	}
+
+	path, err := loader.FindConfigFile()
+	var data []byte
+	if err != nil {
+		path = filepath.Join(workDir, config.ConfigFileName)
+	} else if data, err = os.ReadFile(path); err != nil {
+		return "", nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	out, err := edit(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Reject edits that leave the config unloadable
+	var cfg config.Config
+	if err := yaml.Unmarshal(out, &cfg); err != nil {
+		return "", nil, fmt.Errorf("failed to parse edited config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return "", nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return path, out, nil
+}
+
+// addComposeStub adds a service stub to the first compose file of the
+// project, creating the file if needed, and returns its path
+func addComposeStub(workDir string, cfg *config.Config, name, image string, port int) (string, error) {
+	file := "docker-compose.yml"
+	if files := composeFilesFor(workDir, cfg); len(files) > 0 {
+		file = files[0]
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(workDir, file)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	out, err := compose.AddServiceStub(data, name, image, port)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(file, out, 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return file, nil
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServiceAddRemove(t *testing.T) {
+	workDir := t.TempDir()
+	oldWorkdir := Workdir
+	defer func() { Workdir = oldWorkdir }()
+	Workdir = workDir
+
+	configFile := filepath.Join(workDir, ".space.yaml")
+	if err := os.WriteFile(configFile, []byte("# Shop\nproject:\n  name: shop\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	add := newServiceCommand()
+	add.SetArgs([]string{"add", "api", "--port", "6060", "--health", "/healthz", "--compose", "--image", "api:dev"})
+	if err := add.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(configFile)
+	if !strings.HasPrefix(string(data), "# Shop\n") || !strings.Contains(string(data), "endpoint: /healthz") {
+		t.Errorf(".space.yaml:\n%s", data)
+	}
+	composeData, _ := os.ReadFile(filepath.Join(workDir, "docker-compose.yml"))
+	if !strings.Contains(string(composeData), "image: api:dev") {
+		t.Errorf("docker-compose.yml:\n%s", composeData)
+	}
+
+	// A second add fails before touching the compose file
+	again := newServiceCommand()
+	again.SetArgs([]string{"add", "api", "--compose"})
+	if err := again.Execute(); err == nil || !strings.Contains(err.Error(), "already configured") {
+		t.Errorf("expected duplicate add to fail, got %v", err)
+	}
+
+	remove := newServiceCommand()
+	remove.SetArgs([]string{"remove", "api"})
+	if err := remove.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(configFile)
+	if strings.Contains(string(data), "api") {
+		t.Errorf("api still configured:\n%s", data)
+	}
+	// The compose service is kept
+	if after, _ := os.ReadFile(filepath.Join(workDir, "docker-compose.yml")); string(after) != string(composeData) {
+		t.Errorf("compose file changed:\n%s", after)
+	}
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/spf13/cobra"
+)
+
+// newSetupCommand creates the setup command
+func newSetupCommand() *cobra.Command {
+	var dryRun bool
+	var yes bool
+
+	cmd := &cobra.Command{
+		Use:   "setup",
+		Short: "Perform the one-time privileged setup for DNS (requires sudo)",
+		Long: `Perform every privileged one-time step in a single sudo session:
+
+  • the loopback alias ` + dns.StableIP + ` the DNS server binds to
+  • a launchd job restoring the alias at boot
+  • a resolver file in /etc/resolver per base domain, and per parent
+    domain of the static records in dns.records
+
+'space up' never asks for a password; it tells you to run 'space setup'
+when a step is missing. Steps already in place are skipped, so running it
+again is safe. Only macOS needs these steps.`,
+		Example: `  space setup
+  space setup --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			domains := resolverDomains(daemonConfig(workDirOrEmpty()))
+
+			steps, err := dns.PlanSetup(domains)
+			if err != nil {
+				return fmt.Errorf("failed to check setup: %w", err)
+			}
+			if len(steps) == 0 {
+				fmt.Fprintln(console, "✅ Privileged setup is complete")
+				return nil
+			}
+
+			if dryRun {
+				fmt.Fprint(console, dns.SetupScript(steps))
+				return nil
+			}
+
+			fmt.Fprintln(console, "🔐 Missing setup steps:")
+			for _, step := range steps {
+				fmt.Fprintf(console, "   • %s\n", step.Name)
+			}
+			fmt.Fprintln(console)
+
+			if !yes && !confirm("Run them now with sudo?") {
+				fmt.Fprintln(console, "ℹ️  Nothing changed")
+				return nil
+			}
+
+			err = step("sudo setup", true, func() error {
+				return dns.RunSetup(context.Background(), steps)
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(console, "✅ Privileged setup is complete")
+
+			// A running agent bound a fallback port before the alias existed
+			if state, err := loadDNSState(); err == nil && isDNSServerRunning() && state.Address != dns.StableAddr() {
+				tipf("Run 'space dns restart' to move the DNS daemon from %s to %s", state.Address, dns.StableAddr())
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the script that would run as root")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Run without asking for confirmation")
+
+	return cmd
+}
+
+// warnMissingSetup tells the user which privileged steps are missing for
+// domains, instead of asking for sudo in the middle of a command
+func warnMissingSetup(domains []string) {
+	steps, err := dns.PlanSetup(domains)
+	if err != nil || len(steps) == 0 {
+		return
+	}
+
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.Name
+	}
+	fmt.Fprintf(console, "⚠️  One-time DNS setup is incomplete: %s\n", strings.Join(names, "; "))
+	fmt.Fprintf(console, "💡 Run 'space setup' once; *.%s may not resolve until then\n", strings.Join(domains, ", *."))
+}
@@ -0,0 +1,373 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// sharedDatabaseNetwork is the network shared database servers and the
+	// proxies of the worktrees using them meet on
+	sharedDatabaseNetwork = "space-shared"
+
+	// sharedDatabaseLabel marks the containers and network space created
+	// for shared databases
+	sharedDatabaseLabel = "dev.space.shared-database"
+
+	// sharedDatabaseProxyImage replaces a shared database service in each
+	// worktree, mapping its database name onto the shared server
+	sharedDatabaseProxyImage = "edoburu/pgbouncer:latest"
+
+	// sharedDatabaseStartTimeout bounds how long a shared server may take
+	// to accept connections, which includes initdb on first start
+	sharedDatabaseStartTimeout = 60 * time.Second
+
+	// postgresPort is the port of the shared servers and the proxies
+	postgresPort = 5432
+)
+
+// sharedDatabase is a database whose server is shared by all worktrees
+type sharedDatabase struct {
+	config.DatabaseConfig
+
+	// Image runs the shared server
+	Image string
+
+	// Container is the shared server
+	Container string
+
+	// Database is the database of this worktree on the server
+	Database string
+}
+
+// sharedDatabases returns the databases configured as shared, with the
+// user, password and name filled in from the compose service. project may
+// be nil when only the names are needed.
+func sharedDatabases(cfg *config.Config, project *compose.Project, workDir, projectName string) ([]sharedDatabase, error) {
+	var dbs []sharedDatabase
+	for _, db := range cfg.Databases {
+		if !db.Shared {
+			continue
+		}
+
+		shared := sharedDatabase{DatabaseConfig: db}
+		if project != nil {
+			shared.Image = project.Image(db.Service)
+			if shared.Image == "" {
+				return nil, fmt.Errorf("shared database %s: service %s has no image", db.Name, db.Service)
+			}
+			env := make(map[string]string)
+			for key, value := range project.Environment(db.Service) {
+				if value != nil {
+					env[key] = fmt.Sprint(value)
+				}
+			}
+			if detected, ok := config.DetectDatabase(db.Service, shared.Image, env); ok {
+				fillDatabaseDefaults(&shared.DatabaseConfig, detected)
+			}
+			if shared.Type != "postgres" {
+				return nil, fmt.Errorf("shared database %s: only postgres can be shared, %s runs %s", db.Name, db.Service, shared.Image)
+			}
+		}
+		if shared.User == "" {
+			shared.User = "postgres"
+		}
+		if shared.Name == "" {
+			shared.Name = shared.User
+		}
+
+		shared.Container = sharedDatabaseContainer(cfg, workDir, db.Service)
+		shared.Database = worktreeDatabaseName(shared.Name, projectName)
+		dbs = append(dbs, shared)
+	}
+	return dbs, nil
+}
+
+// fillDatabaseDefaults sets the fields of db left empty from a detected one
+func fillDatabaseDefaults(db *config.DatabaseConfig, detected config.DatabaseConfig) {
+	if db.Type == "" {
+		db.Type = detected.Type
+	}
+	if db.User == "" {
+		db.User = detected.User
+	}
+	if db.Password == "" {
+		db.Password = detected.Password
+	}
+	if db.Name == "" {
+		db.Name = detected.Name
+	}
+}
+
+// sharedDatabaseContainer names the shared server of a service after the
+// repository, so every worktree of it arrives at the same one
+func sharedDatabaseContainer(cfg *config.Config, workDir, service string) string {
+	repo := cfg.Project.Name
+	if repo == "" {
+		repo = filepath.Base(workDir)
+		if common, err := runGit(workDir, "rev-parse", "--path-format=absolute", "--git-common-dir"); err == nil {
+			repo = filepath.Base(filepath.Dir(common))
+		}
+	}
+	return normalizeProjectName(repo + "-shared-" + service)
+}
+
+// worktreeDatabaseName returns the database of a project on a shared
+// server: the configured name with a hash of the project, within the 63
+// characters postgres allows
+func worktreeDatabaseName(name, projectName string) string {
+	sum := sha256.Sum256([]byte(projectName))
+	suffix := "_" + hex.EncodeToString(sum[:])[:8]
+
+	var b strings.Builder
+	for _, c := range strings.ToLower(name) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '_' {
+			b.WriteRune(c)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	base := b.String()
+	if len(base) > 63-len(suffix) {
+		base = base[:63-len(suffix)]
+	}
+	return base + suffix
+}
+
+// prepareSharedDatabases starts the shared servers, creates this project's
+// databases on them and writes the override turning the database services
+// into proxies. It returns the override path and the services it covers,
+// or "" when no database is shared.
+func prepareSharedDatabases(ctx context.Context, cfg *config.Config, project *compose.Project, workDir, projectName string) (string, []string, error) {
+	if project == nil {
+		return "", nil, nil
+	}
+	dbs, err := sharedDatabases(cfg, project, workDir, projectName)
+	if err != nil || len(dbs) == 0 {
+		return "", nil, err
+	}
+
+	for _, db := range dbs {
+		fmt.Fprintf(console, "🐘 Using database %s on shared server %s\n", db.Database, db.Container)
+		if dryRun != nil {
+			dryRun.addAction("start shared server %s if needed and create database %s on it", db.Container, db.Database)
+			continue
+		}
+		if err := ensureSharedDatabase(ctx, db); err != nil {
+			return "", nil, err
+		}
+	}
+	return writeSharedDatabaseOverride(project, projectName, dbs)
+}
+
+// ensureSharedDatabase starts the shared server of a database when it is
+// not running and creates the worktree's database on it
+func ensureSharedDatabase(ctx context.Context, db sharedDatabase) error {
+	if err := ensureNetwork(ctx, sharedDatabaseNetwork, sharedDatabaseLabel); err != nil {
+		return err
+	}
+
+	out, err := exec.CommandContext(ctx, "docker", "container", "inspect", "--format", "{{.State.Running}}", db.Container).Output()
+	switch {
+	case err != nil:
+		args := []string{"run", "--detach",
+			"--name", db.Container,
+			"--network", sharedDatabaseNetwork,
+			"--restart", "unless-stopped",
+			"--label", sharedDatabaseLabel + "=" + db.Service,
+			"--volume", db.Container + "-data:/var/lib/postgresql/data",
+			"--env", "POSTGRES_USER=" + db.User,
+		}
+		if db.Password != "" {
+			args = append(args, "--env", "POSTGRES_PASSWORD="+db.Password)
+		} else {
+			args = append(args, "--env", "POSTGRES_HOST_AUTH_METHOD=trust")
+		}
+		if err := runDocker(ctx, append(args, db.Image)...); err != nil {
+			return fmt.Errorf("failed to start shared database %s: %w", db.Container, err)
+		}
+	case strings.TrimSpace(string(out)) != "true":
+		if err := runDocker(ctx, "start", db.Container); err != nil {
+			return fmt.Errorf("failed to start shared database %s: %w", db.Container, err)
+		}
+	}
+
+	// Over TCP, so the temporary server of the first start's initdb,
+	// which listens on the socket only, doesn't count
+	deadline := time.Now().Add(sharedDatabaseStartTimeout)
+	for runDocker(ctx, "exec", db.Container, "pg_isready", "--host", "127.0.0.1", "--username", db.User) != nil {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("shared database %s did not accept connections within %s", db.Container, sharedDatabaseStartTimeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+
+	if err := runDocker(ctx, "exec", db.Container, "createdb", "--username", db.User, db.Database); err != nil && !strings.Contains(err.Error(), "already exists") {
+		return fmt.Errorf("failed to create database %s: %w", db.Database, err)
+	}
+	return nil
+}
+
+// dropSharedDatabases drops the databases of a project from the shared
+// servers. Servers that are not running are left alone, and so are the
+// databases of other worktrees.
+func dropSharedDatabases(ctx context.Context, cfg *config.Config, project *compose.Project, workDir, projectName string) {
+	dbs, err := sharedDatabases(cfg, project, workDir, projectName)
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  %v\n", err)
+		return
+	}
+	for _, db := range dbs {
+		if err := runDocker(ctx, "exec", db.Container, "dropdb", "--username", db.User, "--if-exists", db.Database); err != nil {
+			fmt.Fprintf(console, "⚠️  Failed to drop database %s from %s: %v\n", db.Database, db.Container, err)
+			continue
+		}
+		fmt.Fprintf(console, "🗑️  Dropped database %s from %s\n", db.Database, db.Container)
+	}
+}
+
+// writeSharedDatabaseOverride writes the PgBouncer configuration of each
+// shared database and the compose override running it in place of the
+// database service. Both live next to the rendered secrets, since they
+// hold the password.
+func writeSharedDatabaseOverride(project *compose.Project, projectName string, dbs []sharedDatabase) (string, []string, error) {
+	dir, err := secretsDir(projectName)
+	if err != nil {
+		return "", nil, err
+	}
+	services := make(map[string]interface{}, len(dbs))
+	names := make([]string, 0, len(dbs))
+	for _, db := range dbs {
+		// PgBouncer runs as its own user, not as the host user, so the file
+		// must be world-readable; the private secrets directory keeps other
+		// users on the host out. Chmod too, as WriteFile keeps the mode of
+		// a file written before.
+		ini := filepath.Join(dir, "pgbouncer-"+db.Service+".ini")
+		if err := writeFile(ini, []byte(pgbouncerConfig(db)), 0600); err != nil {
+			return "", nil, fmt.Errorf("failed to write proxy config for %s: %w", db.Service, err)
+		}
+		if dryRun == nil {
+			if err := os.Chmod(ini, 0644); err != nil {
+				return "", nil, fmt.Errorf("failed to make proxy config for %s readable: %w", db.Service, err)
+			}
+		}
+
+		networks := map[string]interface{}{sharedDatabaseNetwork: map[string]interface{}{}}
+		for _, name := range serviceNetworks(project, db.Service) {
+			networks[name] = map[string]interface{}{}
+		}
+		// Compose merges the override into the database service, so drop
+		// what the proxy must not inherit, such as the data volume
+		services[db.Service] = map[string]interface{}{
+			"image":       sharedDatabaseProxyImage,
+			"entrypoint":  []string{"/usr/bin/pgbouncer"},
+			"command":     []string{"/etc/pgbouncer/pgbouncer.ini"},
+			"volumes":     composeTagged{"!override", []string{ini + ":/etc/pgbouncer/pgbouncer.ini:ro"}},
+			"networks":    networks,
+			"build":       composeTagged{"!reset", nil},
+			"environment": composeTagged{"!reset", nil},
+			"env_file":    composeTagged{"!reset", nil},
+			"user":        composeTagged{"!reset", nil},
+			"healthcheck": composeTagged{"!override", map[string]interface{}{
+				"test":     []string{"CMD-SHELL", fmt.Sprintf("nc -z 127.0.0.1 %d", postgresPort)},
+				"interval": "2s",
+				"timeout":  "2s",
+				"retries":  15,
+			}},
+		}
+		names = append(names, db.Service)
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{
+		"services": services,
+		"networks": map[string]interface{}{
+			sharedDatabaseNetwork: map[string]interface{}{"external": true, "name": sharedDatabaseNetwork},
+		},
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal shared database override: %w", err)
+	}
+
+	overrideFile := filepath.Join(dir, "shared-databases.yml")
+	header := "# Auto-generated shared database proxies from .space.yml - do not commit\n\n"
+	if err := writeFile(overrideFile, []byte(header+string(data)), 0600); err != nil {
+		return "", nil, fmt.Errorf("failed to write shared database override: %w", err)
+	}
+	return overrideFile, names, nil
+}
+
+// composeTagged is a value written with a compose merge tag: !reset drops
+// what the files before set, !override replaces it instead of merging
+type composeTagged struct {
+	tag   string
+	value interface{}
+}
+
+// MarshalYAML writes the value with its tag
+func (c composeTagged) MarshalYAML() (interface{}, error) {
+	var node yaml.Node
+	if err := node.Encode(c.value); err != nil {
+		return nil, err
+	}
+	node.Tag = c.tag
+	return &node, nil
+}
+
+// pgbouncerConfig maps the database name clients use onto the worktree's
+// database on the shared server. The proxy authenticates to the server
+// itself, so clients keep their connection strings.
+func pgbouncerConfig(db sharedDatabase) string {
+	quote := func(s string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+	}
+	target := fmt.Sprintf("host=%s port=%d dbname=%s user=%s", db.Container, postgresPort, db.Database, quote(db.User))
+	if db.Password != "" {
+		target += " password=" + quote(db.Password)
+	}
+
+	return fmt.Sprintf(`[databases]
+%s = %s
+
+[pgbouncer]
+listen_addr = 0.0.0.0
+listen_port = %d
+auth_type = any
+pool_mode = session
+ignore_startup_parameters = extra_float_digits,options
+`, db.Name, target, postgresPort)
+}
+
+// serviceNetworks returns the networks a compose service joins, written as
+// a list or a mapping, or "default" when it names none
+func serviceNetworks(project *compose.Project, service string) []string {
+	var names []string
+	switch networks := project.Services()[service]["networks"].(type) {
+	case []interface{}:
+		for _, n := range networks {
+			names = append(names, fmt.Sprint(n))
+		}
+	case map[string]interface{}:
+		for n := range networks {
+			names = append(names, n)
+		}
+	}
+	if len(names) == 0 {
+		names = []string{"default"}
+	}
+	return names
+}
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestWorktreeDatabaseName(t *testing.T) {
+	a, b := worktreeDatabaseName("app", "shop-main"), worktreeDatabaseName("app", "shop-feature-x")
+	if a == b || !strings.HasPrefix(a, "app_") || len(a) != len("app_")+8 {
+		t.Errorf("worktreeDatabaseName() = %q, %q", a, b)
+	}
+	if again := worktreeDatabaseName("app", "shop-main"); again != a {
+		t.Errorf("worktreeDatabaseName() is not stable: %q, %q", a, again)
+	}
+	if got := worktreeDatabaseName("My-App", "shop"); !strings.HasPrefix(got, "my_app_") {
+		t.Errorf("worktreeDatabaseName(My-App) = %q", got)
+	}
+	if got := worktreeDatabaseName(strings.Repeat("x", 80), "shop"); len(got) != 63 {
+		t.Errorf("long name has %d characters, want 63", len(got))
+	}
+}
+
+func TestSharedDatabases(t *testing.T) {
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"db": map[string]interface{}{
+				"image":       "postgres:16",
+				"environment": map[string]interface{}{"POSTGRES_USER": "shop", "POSTGRES_PASSWORD": "s3cret", "POSTGRES_DB": "app"},
+				"networks":    []interface{}{"backend"},
+			},
+			"cache": map[string]interface{}{"image": "redis:7"},
+		},
+	}}
+	cfg := &config.Config{
+		Project:   config.ProjectConfig{Name: "shop"},
+		Databases: []config.DatabaseConfig{{Service: "db", Shared: true}, {Service: "db2"}},
+	}
+
+	dbs, err := sharedDatabases(cfg, project, t.TempDir(), "shop-main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dbs) != 1 {
+		t.Fatalf("sharedDatabases() = %+v, want db only", dbs)
+	}
+	db := dbs[0]
+	if db.User != "shop" || db.Password != "s3cret" || db.Name != "app" || db.Image != "postgres:16" {
+		t.Errorf("database not filled from the service: %+v", db)
+	}
+	if db.Container != "shop-shared-db" || db.Database != worktreeDatabaseName("app", "shop-main") {
+		t.Errorf("container %q, database %q", db.Container, db.Database)
+	}
+
+	cfg.Databases = []config.DatabaseConfig{{Service: "cache", Shared: true}}
+	if _, err := sharedDatabases(cfg, project, t.TempDir(), "shop-main"); err == nil {
+		t.Error("expected sharing a redis service to fail")
+	}
+}
+
+func TestWriteSharedDatabaseOverride(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"db": map[string]interface{}{
+				"image":    "postgres:16",
+				"networks": []interface{}{"backend"},
+				"volumes":  []interface{}{"pgdata:/var/lib/postgresql/data"},
+			},
+		},
+	}}
+	db := sharedDatabase{
+		DatabaseConfig: config.DatabaseConfig{Name: "app", Service: "db", User: "shop", Password: "it's"},
+		Container:      "shop-shared-db",
+		Database:       "app_1a2b3c4d",
+	}
+
+	file, services, err := writeSharedDatabaseOverride(project, "shop-main", []sharedDatabase{db})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || services[0] != "db" {
+		t.Errorf("services = %v", services)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var override struct {
+		Services map[string]struct {
+			Image    string                 `yaml:"image"`
+			Volumes  []string               `yaml:"volumes"`
+			Networks map[string]interface{} `yaml:"networks"`
+		} `yaml:"services"`
+		Networks map[string]map[string]interface{} `yaml:"networks"`
+	}
+	if err := yaml.Unmarshal(data, &override); err != nil {
+		t.Fatal(err)
+	}
+	proxy := override.Services["db"]
+	if proxy.Image != sharedDatabaseProxyImage || len(proxy.Volumes) != 1 {
+		t.Errorf("proxy = %+v", proxy)
+	}
+	if _, ok := proxy.Networks["backend"]; !ok {
+		t.Errorf("proxy left the service's networks: %v", proxy.Networks)
+	}
+	if _, ok := proxy.Networks[sharedDatabaseNetwork]; !ok || override.Networks[sharedDatabaseNetwork]["external"] != true {
+		t.Errorf("proxy not on the external %s network: %+v", sharedDatabaseNetwork, override)
+	}
+
+	// The proxy replaces what it would otherwise inherit from postgres
+	for _, want := range []string{"volumes: !override", "healthcheck: !override", "environment: !reset null", "user: !reset null"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("override missing %q:\n%s", want, data)
+		}
+	}
+
+	iniPath := strings.SplitN(proxy.Volumes[0], ":", 2)[0]
+	info, err := os.Stat(iniPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0004 == 0 {
+		t.Errorf("pgbouncer.ini mode = %v, want it readable by the container user", info.Mode().Perm())
+	}
+	ini, err := os.ReadFile(iniPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `app = host=shop-shared-db port=5432 dbname=app_1a2b3c4d user='shop' password='it\'s'`
+	if !strings.Contains(string(ini), want) {
+		t.Errorf("pgbouncer.ini missing %q:\n%s", want, ini)
+	}
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// networkComposeFile is the generated override attaching services to
+// shared networks
+const networkComposeFile = ".space-network-compose.yml"
+
+const (
+	// sharedNetworkPrefix names the docker networks of shared_networks, so
+	// they don't clash with compose's <project>_<network> names
+	sharedNetworkPrefix = "space-net-"
+
+	// sharedNetworkLabel marks the networks space created for
+	// shared_networks; only those are removed again
+	sharedNetworkLabel = "dev.space.shared-network"
+)
+
+// sharedNetworkName returns the docker network of a shared network
+func sharedNetworkName(name string) string {
+	return sharedNetworkPrefix + name
+}
+
+// sharedNetworks returns the shared networks of the config with the
+// services joining each. With project set, services compose doesn't define
+// are left out.
+func sharedNetworks(cfg *config.Config, project *compose.Project) map[string][]string {
+	networks := make(map[string][]string)
+	for _, name := range sortedServiceNames(cfg) {
+		if project != nil {
+			if _, ok := project.Services()[name]; !ok {
+				continue
+			}
+		}
+		for _, network := range cfg.Services[name].SharedNetworks {
+			if !containsString(networks[network], name) {
+				networks[network] = append(networks[network], name)
+			}
+		}
+	}
+	return networks
+}
+
+// ensureNetwork creates a docker network with label set when it does not
+// exist yet
+func ensureNetwork(ctx context.Context, name, label string) error {
+	if err := runDocker(ctx, "network", "inspect", name); err == nil {
+		return nil
+	}
+	if err := runDocker(ctx, "network", "create", "--label", label+"=true", name); err != nil {
+		return fmt.Errorf("failed to create network %s: %w", name, err)
+	}
+	return nil
+}
+
+// prepareSharedNetworks creates the shared networks the services join and
+// writes the override attaching them. It returns the override path and the
+// networks, or "" when no service joins one.
+func prepareSharedNetworks(ctx context.Context, cfg *config.Config, project *compose.Project, workDir string) (string, []string, error) {
+	if project == nil {
+		return "", nil, nil
+	}
+	networks := sharedNetworks(cfg, project)
+	if len(networks) == 0 {
+		return "", nil, nil
+	}
+
+	names := slices.Sorted(maps.Keys(networks))
+	for _, network := range names {
+		if dryRun != nil {
+			dryRun.addAction("create network %s if needed", sharedNetworkName(network))
+			continue
+		}
+		if err := ensureNetwork(ctx, sharedNetworkName(network), sharedNetworkLabel); err != nil {
+			return "", nil, err
+		}
+	}
+
+	data, err := yaml.Marshal(sharedNetworkOverride(cfg, project, workDir, networks))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal shared network override: %w", err)
+	}
+
+	header := "# Auto-generated shared networks from .space.yml shared_networks\n"
+	overrideFile := filepath.Join(workDir, networkComposeFile)
+	if _, err := writeGenerated(overrideFile, header, data); err != nil {
+		return "", nil, fmt.Errorf("failed to write shared network override: %w", err)
+	}
+	return overrideFile, names, nil
+}
+
+// sharedNetworkOverride attaches each service to its shared networks,
+// keeping the networks it joins already. On a shared network a service
+// answers to its hostname, which is unique per worktree, rather than only to
+// its service name, which other projects likely share.
+func sharedNetworkOverride(cfg *config.Config, project *compose.Project, workDir string, networks map[string][]string) map[string]interface{} {
+	services := make(map[string]interface{})
+	external := make(map[string]interface{}, len(networks))
+	for network, members := range networks {
+		external[sharedNetworkName(network)] = map[string]interface{}{"external": true, "name": sharedNetworkName(network)}
+
+		for _, name := range members {
+			svc, ok := services[name].(map[string]interface{})
+			if !ok {
+				joined := make(map[string]interface{})
+				for _, n := range serviceNetworks(project, name) {
+					joined[n] = map[string]interface{}{}
+				}
+				svc = map[string]interface{}{"networks": joined}
+				services[name] = svc
+			}
+			svc["networks"].(map[string]interface{})[sharedNetworkName(network)] = map[string]interface{}{
+				"aliases": []string{dnsHostname(cfg, name, workDir)},
+			}
+		}
+	}
+	return map[string]interface{}{"services": services, "networks": external}
+}
+
+// releaseSharedNetworks removes the shared networks of the config that
+// space created and no container is on any more, as when this was the last
+// project using them
+func releaseSharedNetworks(ctx context.Context, cfg *config.Config) {
+	for network := range sharedNetworks(cfg, nil) {
+		name := sharedNetworkName(network)
+		if !unusedSharedNetwork(ctx, name) {
+			continue
+		}
+		if err := runDocker(ctx, "network", "rm", name); err != nil {
+			fmt.Fprintf(console, "⚠️  Failed to remove network %s: %v\n", name, err)
+			continue
+		}
+		fmt.Fprintf(console, "🗑️  Removed network %s, no project uses it any more\n", name)
+	}
+}
+
+// unusedSharedNetwork reports whether name exists, was created by space and
+// has no container on it
+func unusedSharedNetwork(ctx context.Context, name string) bool {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "docker", "network", "inspect", "--format",
+		fmt.Sprintf(`{{len .Containers}} {{index .Labels %q}}`, sharedNetworkLabel), name).Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(out)) == "0 true"
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestSharedNetworks(t *testing.T) {
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"auth": map[string]interface{}{"image": "auth-stub", "networks": []interface{}{"backend"}},
+			"api":  map[string]interface{}{"image": "api"},
+		},
+	}}
+	cfg := &config.Config{
+		Network: config.NetworkConfig{CustomDomain: config.Domains{"space.local"}},
+		Services: map[string]config.ServiceConfig{
+			"auth":   {SharedNetworks: []string{"auth", "auth"}},
+			"api":    {SharedNetworks: []string{"auth"}},
+			"worker": {SharedNetworks: []string{"jobs"}},
+		},
+	}
+
+	if got := sharedNetworks(cfg, nil); !reflect.DeepEqual(got, map[string][]string{"auth": {"api", "auth"}, "jobs": {"worker"}}) {
+		t.Errorf("sharedNetworks(nil) = %v", got)
+	}
+	networks := sharedNetworks(cfg, project)
+	if !reflect.DeepEqual(networks, map[string][]string{"auth": {"api", "auth"}}) {
+		t.Fatalf("sharedNetworks() = %v, want services compose defines only", networks)
+	}
+
+	override := sharedNetworkOverride(cfg, project, "/src/shop", networks)
+	external := override["networks"].(map[string]interface{})["space-net-auth"].(map[string]interface{})
+	if external["external"] != true || external["name"] != "space-net-auth" {
+		t.Errorf("network = %v, want external space-net-auth", external)
+	}
+
+	services := override["services"].(map[string]interface{})
+	auth := services["auth"].(map[string]interface{})["networks"].(map[string]interface{})
+	if _, ok := auth["backend"]; !ok {
+		t.Errorf("auth networks = %v, want backend kept", auth)
+	}
+	alias := auth["space-net-auth"].(map[string]interface{})["aliases"].([]string)
+	if want := dnsHostname(cfg, "auth", "/src/shop"); !reflect.DeepEqual(alias, []string{want}) {
+		t.Errorf("auth aliases = %v, want %s", alias, want)
+	}
+	api := services["api"].(map[string]interface{})["networks"].(map[string]interface{})
+	if _, ok := api["default"]; !ok || len(api) != 2 {
+		t.Errorf("api networks = %v, want default and space-net-auth", api)
+	}
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// startupLogsFile is where 'space up' keeps the startup logs of the
+// project's services for post-up hooks
+func startupLogsFile(workDir string) string {
+	return filepath.Join(workDir, ".space", "logs", "startup.log")
+}
+
+// startupLog is what one container logged while starting
+type startupLog struct {
+	// Name prefixes the lines: the service, with the replica number for
+	// scaled services
+	Name      string
+	Container string
+	Started   time.Time
+	Output    string
+}
+
+// collectStartupLogs writes what each running container of the project
+// logged in the startup window after it started, waiting for windows still
+// open, and returns the file
+func collectStartupLogs(ctx context.Context, cfg *config.Config, workDir, projectName string) (string, error) {
+	settings := *cfg.Hooks.StartupLogs
+	window := settings.EffectiveWindow()
+
+	started, err := projectStartTimes(ctx, projectName)
+	if err != nil {
+		return "", err
+	}
+
+	var last time.Time
+	for _, log := range started {
+		if log.Started.After(last) {
+			last = log.Started
+		}
+	}
+	if wait := time.Until(last.Add(window)); wait > 0 {
+		bannerf("📜 Collecting startup logs (%s)...", wait.Round(time.Second))
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	for i := range started {
+		started[i].Output = containerStartupLogs(ctx, started[i].Container, started[i].Started, window)
+	}
+
+	path := startupLogsFile(workDir)
+	data := formatStartupLogs(started, settings.EffectiveMaxLines())
+	if err := writeFile(path, []byte(redact.String(data)), 0600); err != nil {
+		return "", fmt.Errorf("failed to write startup logs: %w", err)
+	}
+	return path, nil
+}
+
+// listProjectContainers lists the running containers of a compose project
+func listProjectContainers(ctx context.Context, projectName string) ([]dns.Container, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "docker", "ps", "--format", dns.PsFormat,
+		"--filter", "label=com.docker.compose.project="+projectName).Output()
+	if err != nil {
+		return nil, timeoutError(ctx, "docker ps", dockerQueryTimeout, err)
+	}
+	return dns.ParseContainers(string(out)), nil
+}
+
+// projectStartTimes returns the running containers of the project with the
+// time each started
+func projectStartTimes(ctx context.Context, projectName string) ([]startupLog, error) {
+	containers, err := listProjectContainers(ctx, projectName)
+	if err != nil || len(containers) == 0 {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	args := []string{"inspect", "--format", "{{.Name}}|{{.State.StartedAt}}"}
+	names := make(map[string]string, len(containers))
+	for _, c := range containers {
+		args = append(args, c.Name)
+		names[c.Name] = c.Service
+		if c.Service == "" {
+			names[c.Name] = c.Name
+		} else if c.Number > 1 {
+			names[c.Name] = fmt.Sprintf("%s-%d", c.Service, c.Number)
+		}
+	}
+	out, err := exec.CommandContext(ctx, "docker", args...).Output()
+	if err != nil {
+		return nil, timeoutError(ctx, "docker inspect", dockerQueryTimeout, err)
+	}
+
+	logs := parseStartTimes(string(out))
+	for i := range logs {
+		logs[i].Name = names[logs[i].Container]
+	}
+	return logs, nil
+}
+
+// parseStartTimes parses docker inspect output of "{{.Name}}|{{.State.StartedAt}}"
+func parseStartTimes(out string) []startupLog {
+	var logs []startupLog
+	for _, line := range strings.Split(out, "\n") {
+		name, startedAt, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if !ok {
+			continue
+		}
+		started, err := time.Parse(time.RFC3339Nano, startedAt)
+		if err != nil {
+			continue
+		}
+		logs = append(logs, startupLog{Container: strings.TrimPrefix(name, "/"), Started: started})
+	}
+	return logs
+}
+
+// containerStartupLogs returns what a container wrote to stdout and stderr
+// in the window after it started
+func containerStartupLogs(ctx context.Context, name string, started time.Time, window time.Duration) string {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "docker", "logs",
+		"--since", started.Format(time.RFC3339Nano),
+		"--until", started.Add(window).Format(time.RFC3339Nano),
+		name).CombinedOutput()
+	if err != nil {
+		err = timeoutError(ctx, "docker logs", dockerQueryTimeout, err)
+		return fmt.Sprintf("logs unavailable: %v\n", err)
+	}
+	return string(out)
+}
+
+// formatStartupLogs prefixes each line with its service like docker compose
+// logs does, keeping the first maxLines lines per container
+func formatStartupLogs(logs []startupLog, maxLines int) string {
+	sort.Slice(logs, func(i, j int) bool { return logs[i].Name < logs[j].Name })
+
+	width := 0
+	for _, log := range logs {
+		width = max(width, len(log.Name))
+	}
+
+	var b strings.Builder
+	for _, log := range logs {
+		lines := strings.Split(strings.TrimRight(log.Output, "\n"), "\n")
+		if log.Output == "" {
+			lines = nil
+		}
+		dropped := 0
+		if len(lines) > maxLines {
+			lines, dropped = lines[:maxLines], len(lines)-maxLines
+		}
+		for _, line := range lines {
+			fmt.Fprintf(&b, "%-*s | %s\n", width, log.Name, line)
+		}
+		if dropped > 0 {
+			fmt.Fprintf(&b, "%-*s | [%d more lines]\n", width, log.Name, dropped)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseStartTimes(t *testing.T) {
+	out := "/shop-api-1|2026-10-15T09:30:01.123456789Z\n" +
+		"/shop-db-1|0001-01-01T00:00:00Z\n" +
+		"garbage\n" +
+		"/shop-web-1|not a time\n"
+
+	logs := parseStartTimes(out)
+	if len(logs) != 2 {
+		t.Fatalf("parseStartTimes() = %+v, want 2 containers", logs)
+	}
+	if logs[0].Container != "shop-api-1" {
+		t.Errorf("container = %q, want the name without the slash", logs[0].Container)
+	}
+	want := time.Date(2026, 10, 15, 9, 30, 1, 123456789, time.UTC)
+	if !logs[0].Started.Equal(want) {
+		t.Errorf("started = %v, want %v", logs[0].Started, want)
+	}
+}
+
+func TestFormatStartupLogs(t *testing.T) {
+	logs := []startupLog{
+		{Name: "worker", Output: "ready\n"},
+		{Name: "api", Output: "booting\nWARNING: 2 migrations pending\nlistening on :8080\n"},
+		{Name: "db", Output: ""},
+	}
+
+	got := formatStartupLogs(logs, 2)
+	want := "api    | booting\n" +
+		"api    | WARNING: 2 migrations pending\n" +
+		"api    | [1 more lines]\n" +
+		"worker | ready\n"
+	if got != want {
+		t.Errorf("formatStartupLogs() =\n%s\nwant\n%s", got, want)
+	}
+	if strings.Contains(got, "db ") {
+		t.Errorf("services without output should be left out:\n%s", got)
+	}
+}
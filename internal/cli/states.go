@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/events"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// seedServiceStates lists the containers of a project once with compose ps
+// and seeds the tracked states with them; docker events keep them current
+// from then on
+func seedServiceStates(ctx context.Context, cfg *config.Config, workDir, projectName string, states *events.States) error {
+	at := time.Now()
+	services, err := getDockerComposePS(ctx, workDir, cfg, projectName, true)
+	if err != nil {
+		return err
+	}
+
+	tracked := make([]events.ServiceState, 0, len(services))
+	for _, svc := range services {
+		tracked = append(tracked, serviceStateFromPS(svc))
+	}
+	states.Seed(projectName, at, tracked)
+	return nil
+}
+
+// serviceStateFromPS converts a compose ps entry to a tracked state
+func serviceStateFromPS(s ServiceStatus) events.ServiceState {
+	st := events.ServiceState{Service: s.Name, State: s.State}
+	switch {
+	case strings.Contains(s.Status, "(healthy)"):
+		st.Health = "healthy"
+	case strings.Contains(s.Status, "(unhealthy)"):
+		st.Health = "unhealthy"
+	case strings.Contains(s.Status, "(health: starting)"):
+		st.Health = "starting"
+	}
+	if m := exitCodePattern.FindStringSubmatch(s.Status); m != nil {
+		st.ExitCode = m[1]
+	}
+	return st
+}
+
+// trackedServiceStatuses returns the tracked states of a project as compose
+// ps entries
+func trackedServiceStatuses(states *events.States, projectName string) []ServiceStatus {
+	snapshot := states.Snapshot(projectName)
+	services := make([]ServiceStatus, 0, len(snapshot))
+	for _, st := range snapshot {
+		services = append(services, ServiceStatus{Name: st.Service, State: st.State, Status: st.Status()})
+	}
+	return services
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/events"
+)
+
+func TestTrackedServiceStatuses(t *testing.T) {
+	listed := []ServiceStatus{
+		{Name: "api", State: "running", Status: "Up 3 minutes (health: starting)"},
+		{Name: "db", State: "running", Status: "Up 3 minutes (healthy)"},
+		{Name: "worker", State: "exited", Status: "Exited (137) 5 seconds ago"},
+	}
+
+	states := events.NewStates()
+	tracked := make([]events.ServiceState, len(listed))
+	for i, s := range listed {
+		tracked[i] = serviceStateFromPS(s)
+	}
+	states.Seed("shop", time.Now(), tracked)
+
+	// The conditions status --watch derives must not change by tracking
+	for i, s := range trackedServiceStatuses(states, "shop") {
+		got := serviceCondition(ServiceHealth{State: s.State, Health: "-", status: s.Status})
+		want := serviceCondition(ServiceHealth{State: listed[i].State, Health: "-", status: listed[i].Status})
+		if s.Name != listed[i].Name || got != want {
+			t.Errorf("%s: condition %q, want %q", s.Name, got, want)
+		}
+	}
+}
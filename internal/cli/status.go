@@ -0,0 +1,322 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/health"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// ServiceHealth combines container state and health probe results
+type ServiceHealth struct {
+	Service  string `json:"service"`
+	State    string `json:"state"`
+	Protocol string `json:"protocol"`
+	URL      string `json:"url,omitempty"`
+	Health   string `json:"health"`
+	Error    string `json:"error,omitempty"`
+	Latency  string `json:"latency,omitempty"`
+
+	// status is the docker status line, e.g. "Exited (1) 2 seconds ago"
+	status string
+}
+
+// newStatusCommand creates the status command
+func newStatusCommand() *cobra.Command {
+	var jsonOutput bool
+	var watch bool
+	var interval time.Duration
+	var failFast bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show service health",
+		Long: `Display the state of every service together with its health.
+
+Services with health_check enabled are probed according to their protocol:
+http services with a GET request to the health endpoint, tcp services with a
+TCP connect and grpc services with the standard grpc.health.v1 Check call.
+grpc services are probed even without a health_check section.
+
+With --watch the table is redrawn as soon as docker reports a container
+change, and every --interval to probe health again, highlighting services
+whose state or health changed since the last frame. Add --fail-fast to exit
+non-zero as soon as a running service exits or starts restarting.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			// Get working directory
+			workDir := Workdir
+			if workDir == "." {
+				var err error
+				workDir, err = os.Getwd()
+				if err != nil {
+					return fmt.Errorf("failed to get working directory: %w", err)
+				}
+			}
+
+			// Make absolute
+			workDir, err := filepath.Abs(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+
+			// Create loader
+			loader, err := newConfigLoader(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to create config loader: %w", err)
+			}
+
+			// Load configuration
+			cfg, err := loader.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+
+			registerSensitiveValues(cfg)
+
+			if project, err := loadComposeProject(workDir, cfg); err == nil {
+				applyComposeServices(cfg, project)
+			}
+
+			projectName := generateProjectName(cfg, workDir)
+
+			if watch {
+				if jsonOutput {
+					return fmt.Errorf("--watch cannot be combined with --json")
+				}
+				return watchStatus(ctx, cfg, workDir, projectName, interval, failFast)
+			}
+
+			statuses, err := collectServiceHealth(ctx, cfg, workDir, projectName)
+			if err != nil {
+				return err
+			}
+
+			// Warn on stderr so --json output stays parseable
+			warnComposeDrift(os.Stderr, workDir, composeFilesFor(workDir, cfg))
+
+			if jsonOutput {
+				encoder := json.NewEncoder(redact.NewWriter(os.Stdout))
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(statuses)
+			}
+
+			if len(statuses) == 0 {
+				fmt.Fprintln(console, "No services found.")
+				return nil
+			}
+
+			return printStatusTable(os.Stdout, statuses, nil)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Re-poll and redraw until interrupted, highlighting changes")
+	cmd.Flags().DurationVarP(&interval, "interval", "n", 2*time.Second, "Polling interval in watch mode")
+	cmd.Flags().BoolVar(&failFast, "fail-fast", false, "In watch mode, exit non-zero as soon as a service crashes")
+	enableDebugListen(cmd)
+
+	return cmd
+}
+
+// collectServiceHealth polls the container state of every service and probes
+// the running ones with health checks enabled
+func collectServiceHealth(ctx context.Context, cfg *config.Config, workDir, projectName string) ([]ServiceHealth, error) {
+	services, err := getDockerComposePS(ctx, workDir, cfg, projectName, true)
+	if err != nil {
+		return nil, err
+	}
+	return probeServiceHealth(ctx, cfg, workDir, projectName, services), nil
+}
+
+// probeServiceHealth combines known container states with probes of the
+// running services with health checks enabled
+func probeServiceHealth(ctx context.Context, cfg *config.Config, workDir, projectName string, services []ServiceStatus) []ServiceHealth {
+	useDNS := serviceDNSActive(cfg, workDir)
+
+	// Container states
+	states := make(map[string]ServiceStatus)
+	for _, svc := range services {
+		states[svc.Name] = svc
+	}
+
+	// Probe running services with health checks enabled
+	var targets []health.Target
+	for _, target := range healthTargets(cfg, workDir, projectName, useDNS) {
+		if states[target.Service].State == "running" {
+			targets = append(targets, target)
+		}
+	}
+	results := make(map[string]health.Result)
+	for _, result := range health.Check(ctx, targets) {
+		results[result.Service] = result
+	}
+
+	statuses := make([]ServiceHealth, 0, len(cfg.Services))
+	for _, name := range sortedServiceNames(cfg) {
+		state := states[name].State
+		if state == "" {
+			state = "not created"
+		}
+
+		status := ServiceHealth{
+			Service:  name,
+			State:    state,
+			Protocol: cfg.Services[name].EffectiveProtocol(),
+			Health:   "-",
+			status:   states[name].Status,
+		}
+		if _, port := serviceAddress(cfg, name, workDir, useDNS); port > 0 {
+			status.URL = serviceURL(cfg, name, projectName, workDir, useDNS)
+		}
+		if result, ok := results[name]; ok {
+			status.Latency = result.Latency.Round(time.Millisecond).String()
+			if result.Healthy {
+				status.Health = "healthy"
+			} else {
+				status.Health = "unhealthy"
+				status.Error = result.Error
+			}
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses
+}
+
+// printStatusTable writes the status table followed by probe errors. When
+// highlight is set, it may decorate each rendered line; the header lines are
+// passed with an empty service.
+func printStatusTable(out io.Writer, statuses []ServiceHealth, highlight func(service, line string) string) error {
+	var table bytes.Buffer
+	w := newTable(&table)
+	fmt.Fprintln(w, "SERVICE\tSTATE\tHEALTH\tURL")
+	fmt.Fprintln(w, "-------\t-----\t------\t---")
+	for _, s := range statuses {
+		healthCol := s.Health
+		if s.Latency != "" && s.Health == "healthy" {
+			healthCol = fmt.Sprintf("%s (%s)", s.Health, s.Latency)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", s.Service, s.State, healthCol, redact.String(s.URL))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	// Decorate after alignment so escape codes do not skew the columns
+	lines := strings.SplitAfter(table.String(), "\n")
+	for i, line := range lines {
+		if highlight != nil && line != "" {
+			service := ""
+			if i >= 2 {
+				service = statuses[i-2].Service
+			}
+			line = highlight(service, line)
+		}
+		if _, err := io.WriteString(out, line); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range statuses {
+		if s.Error != "" {
+			fmt.Fprintf(out, "⚠️  %s: %s\n", s.Service, redact.String(s.Error))
+		}
+	}
+
+	return nil
+}
+
+// healthTargets returns probe targets for services with health_check enabled.
+// grpc services are checked through grpc.health.v1 unless health_check is
+// explicitly configured, since the health protocol is standard.
+func healthTargets(cfg *config.Config, workDir, projectName string, useDNS bool) []health.Target {
+	var targets []health.Target
+	for _, name := range sortedServiceNames(cfg) {
+		svc := cfg.Services[name]
+		if svc.HealthCheck == nil {
+			if svc.EffectiveProtocol() != config.ProtocolGRPC {
+				continue
+			}
+		} else if !svc.HealthCheck.Enabled {
+			continue
+		}
+
+		command := healthCommand(cfg, name)
+		host, port := serviceAddress(cfg, name, workDir, useDNS)
+		if port == 0 && command == "" {
+			continue
+		}
+
+		targets = append(targets, health.Target{
+			Service:  name,
+			Protocol: svc.EffectiveProtocol(),
+			Host:     host,
+			Port:     port,
+			Check:    svc.HealthCheck,
+			Project:  projectName,
+			Command:  command,
+		})
+	}
+	return targets
+}
+
+// healthCommand renders the readiness command of a service with the
+// credentials of the database it provides. Ports and hosts default to the
+// container's view of the service.
+func healthCommand(cfg *config.Config, service string) string {
+	svc := cfg.Services[service]
+	if svc.HealthCheck == nil || svc.HealthCheck.Command == "" {
+		return ""
+	}
+
+	db, _ := cfg.DatabaseFor(service)
+	if db.Host == "" {
+		db.Host = "localhost"
+	}
+	if db.Port == 0 {
+		db.Port = svc.Port
+	}
+	return db.ExpandVars(svc.HealthCheck.Command)
+}
+
+// waitForHealthy blocks until every health-checked service passes its probe
+func waitForHealthy(ctx context.Context, cfg *config.Config, workDir, projectName string, useDNS bool, onHealthy func(service string)) error {
+	targets := healthTargets(cfg, workDir, projectName, useDNS)
+	if len(targets) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(console, "⏳ Waiting for %d service(s) to become healthy...\n", len(targets))
+	var healthy func(health.Result)
+	if onHealthy != nil {
+		healthy = func(r health.Result) { onHealthy(r.Service) }
+	}
+	var results []health.Result
+	// Hooks run while waiting write to the terminal, so no spinner then
+	err := step("health checks", onHealthy != nil, func() error {
+		var err error
+		results, err = health.WaitEach(ctx, targets, healthy)
+		return err
+	})
+	for _, result := range results {
+		if result.Healthy {
+			fmt.Fprintf(console, "   ✓ %s (%s)\n", result.Service, result.Protocol)
+		} else {
+			fmt.Fprintf(console, "   ✗ %s (%s): %s\n", result.Service, result.Protocol, result.Error)
+		}
+	}
+	fmt.Fprintln(console)
+	return err
+}
@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func TestHealthTargets(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"web":      {Port: 3000, HealthCheck: &config.HealthCheckConfig{Enabled: true, Endpoint: "/health"}},
+			"worker":   {Port: 9000},
+			"db":       {Port: 5432, Protocol: "tcp", HealthCheck: &config.HealthCheckConfig{Enabled: true}},
+			"orders":   {Port: 50051, Protocol: "grpc"},
+			"payments": {Port: 50052, Protocol: "grpc", HealthCheck: &config.HealthCheckConfig{Enabled: false}},
+		},
+	}
+
+	targets := healthTargets(cfg, "/tmp", "shop", false)
+
+	got := make(map[string]string)
+	for _, target := range targets {
+		got[target.Service] = target.Protocol
+	}
+
+	want := map[string]string{"db": "tcp", "orders": "grpc", "web": "http"}
+	if len(got) != len(want) {
+		t.Fatalf("healthTargets() = %v, want %v", got, want)
+	}
+	for name, protocol := range want {
+		if got[name] != protocol {
+			t.Errorf("target %s protocol = %q, want %q", name, got[name], protocol)
+		}
+	}
+}
+
+func TestHealthCommand(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"postgres": {Port: 5432, HealthCheck: &config.HealthCheckConfig{
+				Enabled: true,
+				Command: "PGPASSWORD={db_password} pg_isready -h {db_host} -p {db_port} -U {db_user} -d {db_name}",
+			}},
+			"redis": {Port: 6379, HealthCheck: &config.HealthCheckConfig{Enabled: true, Command: "redis-cli -p {db_port} ping"}},
+			"web":   {Port: 3000, HealthCheck: &config.HealthCheckConfig{Enabled: true}},
+		},
+		Databases: []config.DatabaseConfig{
+			{Name: "shop", Service: "postgres", User: "app", Password: "secret"},
+		},
+	}
+
+	tests := map[string]string{
+		"postgres": "PGPASSWORD=secret pg_isready -h localhost -p 5432 -U app -d shop",
+		"redis":    "redis-cli -p 6379 ping",
+		"web":      "",
+	}
+	for service, want := range tests {
+		if got := healthCommand(cfg, service); got != want {
+			t.Errorf("healthCommand(%s) = %q, want %q", service, got, want)
+		}
+	}
+
+	for _, target := range healthTargets(cfg, "/tmp", "shop", false) {
+		if target.Service == "redis" && (target.Project != "shop" || target.Command != tests["redis"]) {
+			t.Errorf("redis target = %+v", target)
+		}
+	}
+}
+
+func TestCommandFlagsDontCollide(t *testing.T) {
+	// Cobra only merges the persistent flags, and panics on a taken
+	// shorthand, when a command runs; --help runs every command that far
+	var paths [][]string
+	var walk func(cmd *cobra.Command, path []string)
+	walk = func(cmd *cobra.Command, path []string) {
+		if !cmd.DisableFlagParsing && cmd.Name() != "help" {
+			paths = append(paths, path)
+		}
+		for _, sub := range cmd.Commands() {
+			walk(sub, append(slices.Clone(path), sub.Name()))
+		}
+	}
+	walk(rootCmd, nil)
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	defer func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+	}()
+	for _, path := range paths {
+		rootCmd.SetArgs(append(slices.Clone(path), "--help"))
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Errorf("space %s --help panicked: %v", strings.Join(path, " "), r)
+				}
+			}()
+			if err := rootCmd.Execute(); err != nil {
+				t.Errorf("space %s --help: %v", strings.Join(path, " "), err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/events"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// maxStatusChanges is how many recent transitions watch mode keeps listed
+const maxStatusChanges = 10
+
+// statusTransition is a change of a service's condition between two polls
+type statusTransition struct {
+	Time    time.Time
+	Service string
+	From    string
+	To      string
+	Crash   bool
+}
+
+// exitCodePattern extracts the exit code from a docker status line
+var exitCodePattern = regexp.MustCompile(`^Exited \((\d+)\)`)
+
+// serviceCondition summarizes a service in one word: its health when probed
+// while running ("starting" during the docker healthcheck grace period),
+// otherwise its container state with the exit code of failed containers
+func serviceCondition(s ServiceHealth) string {
+	if s.State == "running" && s.Health != "healthy" && strings.Contains(s.status, "(health: starting)") {
+		return "starting"
+	}
+	if s.State == "running" && s.Health != "-" {
+		return s.Health
+	}
+	if s.State == "running" {
+		return "running"
+	}
+	if m := exitCodePattern.FindStringSubmatch(s.status); m != nil && m[1] != "0" {
+		return fmt.Sprintf("exited (%s)", m[1])
+	}
+	return s.State
+}
+
+// isCrash reports whether moving from one condition to another means the
+// service crashed: a live container that died with an error or restarts
+func isCrash(from, to string) bool {
+	live := from == "running" || from == "healthy" || from == "unhealthy" || from == "starting"
+	return live && (strings.HasPrefix(to, "exited (") || to == "dead" || to == "restarting")
+}
+
+// diffStatuses returns the services whose condition changed between polls.
+// Services that appear or disappear are reported as "not created".
+func diffStatuses(prev, cur []ServiceHealth, now time.Time) []statusTransition {
+	before := make(map[string]string, len(prev))
+	for _, s := range prev {
+		before[s.Service] = serviceCondition(s)
+	}
+
+	var transitions []statusTransition
+	for _, s := range cur {
+		from, ok := before[s.Service]
+		if !ok {
+			from = "not created"
+		}
+		to := serviceCondition(s)
+		if from == to {
+			continue
+		}
+		transitions = append(transitions, statusTransition{
+			Time:    now,
+			Service: s.Service,
+			From:    from,
+			To:      to,
+			Crash:   isCrash(from, to),
+		})
+	}
+	return transitions
+}
+
+// watchStatus redraws the status table whenever docker reports a container
+// change and every interval, until interrupted. Container states follow
+// docker events rather than a compose ps per frame; when the event stream
+// fails, it falls back to polling. With failFast it returns an error as
+// soon as a service crashes.
+func watchStatus(ctx context.Context, cfg *config.Config, workDir, projectName string, interval time.Duration, failFast bool) error {
+	if interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Start following events before the listing they are applied on, so
+	// no change falls in between
+	states := events.NewStates()
+	streamErr := make(chan error, 1)
+	since := time.Now()
+	go func() { streamErr <- states.Watch(ctx, projectName, since) }()
+	tracking := seedServiceStates(ctx, cfg, workDir, projectName, states) == nil
+
+	terminal := stdoutIsTerminal()
+	var prev []ServiceHealth
+	var changes []statusTransition
+	first := true
+
+	for {
+		var statuses []ServiceHealth
+		var err error
+		if tracking {
+			statuses = probeServiceHealth(ctx, cfg, workDir, projectName, trackedServiceStatuses(states, projectName))
+		} else {
+			statuses, err = collectServiceHealth(ctx, cfg, workDir, projectName)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		// The first frame is the baseline, not a change
+		var transitions []statusTransition
+		if !first {
+			transitions = diffStatuses(prev, statuses, time.Now())
+		}
+		first = false
+		changes = append(changes, transitions...)
+		if len(changes) > maxStatusChanges {
+			changes = changes[len(changes)-maxStatusChanges:]
+		}
+
+		if err := renderStatusWatch(os.Stdout, statuses, transitions, changes, interval, terminal); err != nil {
+			return err
+		}
+
+		if failFast {
+			for _, t := range transitions {
+				if t.Crash {
+					return fmt.Errorf("service %s crashed: %s → %s", t.Service, t.From, t.To)
+				}
+			}
+		}
+		prev = statuses
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-states.Changed():
+		case <-streamErr:
+			tracking = false
+			streamErr = nil
+		}
+	}
+}
+
+// renderStatusWatch draws one watch frame. On a terminal the screen is
+// cleared and changed rows are colored; otherwise frames are appended and
+// changed rows are marked with a leading "*".
+func renderStatusWatch(out io.Writer, statuses []ServiceHealth, transitions, changes []statusTransition, interval time.Duration, terminal bool) error {
+	changed := make(map[string]statusTransition, len(transitions))
+	for _, t := range transitions {
+		changed[t.Service] = t
+	}
+
+	if terminal {
+		fmt.Fprint(out, "\033[H\033[2J")
+	} else {
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintf(out, "👀 Every %s: space status (%s, Ctrl+C to stop)\n\n", interval, time.Now().Format("15:04:05"))
+
+	if len(statuses) == 0 {
+		fmt.Fprintln(out, "No services found.")
+	} else {
+		highlight := func(service, line string) string {
+			t, ok := changed[service]
+			if !ok {
+				if terminal {
+					return line
+				}
+				return "  " + line
+			}
+			if !terminal {
+				return "* " + line
+			}
+			return transitionColor(t) + strings.TrimSuffix(line, "\n") + "\033[0m\n"
+		}
+		if err := printStatusTable(out, statuses, highlight); err != nil {
+			return err
+		}
+	}
+
+	if len(changes) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "🔄 Recent changes:")
+		for _, t := range changes {
+			marker := ""
+			if t.Crash {
+				marker = " 💥"
+			}
+			fmt.Fprintf(out, "   %s  %s: %s → %s%s\n", t.Time.Format("15:04:05"), t.Service, t.From, t.To, marker)
+		}
+	}
+
+	return nil
+}
+
+// transitionColor returns the ANSI color of a changed row: red for crashes
+// and failures, green for recoveries, yellow for anything else
+func transitionColor(t statusTransition) string {
+	switch {
+	case t.Crash, t.To == "unhealthy", t.To == "dead", strings.HasPrefix(t.To, "exited ("):
+		return "\033[1;31m"
+	case t.To == "healthy", t.To == "running":
+		return "\033[1;32m"
+	default:
+		return "\033[1;33m"
+	}
+}
+
+// stdoutIsTerminal reports whether stdout is attached to a terminal
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
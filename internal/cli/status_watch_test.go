@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServiceCondition(t *testing.T) {
+	tests := []struct {
+		name   string
+		status ServiceHealth
+		want   string
+	}{
+		{"probed healthy", ServiceHealth{State: "running", Health: "healthy"}, "healthy"},
+		{"probed unhealthy", ServiceHealth{State: "running", Health: "unhealthy"}, "unhealthy"},
+		{"not probed", ServiceHealth{State: "running", Health: "-"}, "running"},
+		{"healthcheck starting", ServiceHealth{State: "running", Health: "unhealthy", status: "Up 2 seconds (health: starting)"}, "starting"},
+		{"exited with error", ServiceHealth{State: "exited", Health: "-", status: "Exited (137) 3 seconds ago"}, "exited (137)"},
+		{"exited cleanly", ServiceHealth{State: "exited", Health: "-", status: "Exited (0) 3 seconds ago"}, "exited"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := serviceCondition(tt.status); got != tt.want {
+				t.Errorf("serviceCondition() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffStatuses(t *testing.T) {
+	prev := []ServiceHealth{
+		{Service: "api", State: "running", Health: "unhealthy", status: "Up 1 second (health: starting)"},
+		{Service: "web", State: "running", Health: "-"},
+		{Service: "migrate", State: "running", Health: "-"},
+		{Service: "db", State: "running", Health: "healthy"},
+	}
+	cur := []ServiceHealth{
+		{Service: "api", State: "running", Health: "healthy"},
+		{Service: "web", State: "exited", Health: "-", status: "Exited (1) 1 second ago"},
+		{Service: "migrate", State: "exited", Health: "-", status: "Exited (0) 1 second ago"},
+		{Service: "db", State: "running", Health: "healthy"},
+		{Service: "worker", State: "restarting", Health: "-"},
+	}
+
+	got := diffStatuses(prev, cur, time.Now())
+	want := []statusTransition{
+		{Service: "api", From: "starting", To: "healthy"},
+		{Service: "web", From: "running", To: "exited (1)", Crash: true},
+		{Service: "migrate", From: "running", To: "exited"},
+		{Service: "worker", From: "not created", To: "restarting"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("diffStatuses() returned %d transitions, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g := got[i]
+		if g.Service != want[i].Service || g.From != want[i].From || g.To != want[i].To || g.Crash != want[i].Crash {
+			t.Errorf("transition %d = %+v, want %+v", i, g, want[i])
+		}
+	}
+}
+
+func TestRenderStatusWatchMarksChanges(t *testing.T) {
+	statuses := []ServiceHealth{
+		{Service: "api", State: "running", Health: "healthy"},
+		{Service: "web", State: "running", Health: "-"},
+	}
+	transitions := []statusTransition{{Time: time.Now(), Service: "api", From: "starting", To: "healthy"}}
+
+	var out bytes.Buffer
+	if err := renderStatusWatch(&out, statuses, transitions, transitions, 2*time.Second, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var apiLine, webLine string
+	for _, line := range strings.Split(out.String(), "\n") {
+		switch {
+		case strings.Contains(line, "api") && strings.Contains(line, "running"):
+			apiLine = line
+		case strings.Contains(line, "web"):
+			webLine = line
+		}
+	}
+	if !strings.HasPrefix(apiLine, "* ") {
+		t.Errorf("changed row not marked: %q", apiLine)
+	}
+	if !strings.HasPrefix(webLine, "  ") {
+		t.Errorf("unchanged row marked: %q", webLine)
+	}
+	if !strings.Contains(out.String(), "api: starting → healthy") {
+		t.Errorf("recent changes missing transition:\n%s", out.String())
+	}
+}
@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Timeouts for external commands, so a hung docker daemon or git remote
+// fails the command instead of hanging it. Commands running user workloads,
+// such as compose up, logs, exec and custom commands, have none.
+const (
+	// dockerQueryTimeout bounds quick docker queries such as ps and inspect
+	dockerQueryTimeout = 15 * time.Second
+
+	// composeStopTimeout bounds compose down, which waits for containers to
+	// stop gracefully
+	composeStopTimeout = 5 * time.Minute
+
+	// gitTimeout bounds git commands, including checking out a worktree
+	gitTimeout = 2 * time.Minute
+)
+
+// timeoutError explains the error of a command killed because ctx, created
+// with the given timeout, expired; other errors are returned unchanged
+func timeoutError(ctx context.Context, name string, timeout time.Duration, err error) error {
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s did not respond within %s", name, timeout)
+	}
+	return err
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestTimeoutError(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not installed")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := exec.CommandContext(ctx, "sleep", "5").Run()
+	if got := timeoutError(ctx, "docker", 50*time.Millisecond, err); got == nil || got.Error() != "docker did not respond within 50ms" {
+		t.Errorf("timeoutError() = %v", got)
+	}
+
+	failed := errors.New("exit status 1")
+	if got := timeoutError(context.Background(), "docker", time.Second, failed); got != failed {
+		t.Errorf("timeoutError() = %v, want the original error", got)
+	}
+}
@@ -0,0 +1,488 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// tunnelStartTimeout bounds the wait for a provider to report its public URL
+const tunnelStartTimeout = 30 * time.Second
+
+// TunnelState describes the public tunnel of a service
+type TunnelState struct {
+	Service   string    `json:"service"`
+	Provider  string    `json:"provider"`
+	PID       int       `json:"pid"`
+	Target    string    `json:"target"`
+	URL       string    `json:"url"`
+	Env       string    `json:"env,omitempty"`
+	EnvValue  string    `json:"env_value,omitempty"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// tunnelProvider is a program that publishes a local address
+type tunnelProvider struct {
+	// args returns the arguments exposing target, an http URL
+	args func(target string) []string
+
+	// url finds the public URL in the program's output
+	url *regexp.Regexp
+}
+
+// tunnelProviders are the supported providers, in order of preference
+var tunnelProviders = map[string]tunnelProvider{
+	config.TunnelCloudflared: {
+		args: func(target string) []string {
+			return []string{"tunnel", "--no-autoupdate", "--url", target}
+		},
+		url: regexp.MustCompile(`(https://[a-z0-9-]+\.trycloudflare\.com)`),
+	},
+	config.TunnelNgrok: {
+		args: func(target string) []string {
+			return []string{"http", target, "--log", "stdout", "--log-format", "logfmt"}
+		},
+		url: regexp.MustCompile(`url=(https://[^\s"]+)`),
+	},
+}
+
+// newTunnelCommand creates the tunnel command
+func newTunnelCommand() *cobra.Command {
+	var provider string
+
+	cmd := &cobra.Command{
+		Use:   "tunnel [service]",
+		Short: "Expose a service on a public URL for webhooks",
+		Long: `Open a public URL for a service through cloudflared or ngrok, whichever is
+installed (or set services.<name>.tunnel.provider), so webhooks from Stripe,
+GitHub and the like reach the local stack.
+
+The tunnel runs in the background until 'space tunnel stop' or 'space down'.
+Its URL is shown by 'space tunnel list', passed to hook scripts as
+SPACE_SERVICE_<NAME>_PUBLIC_URL and, with tunnel.env set, written to
+.space/state/tunnels.env and set in the service's environment on the next
+'space up':
+
+  services:
+    api:
+      port: 8080
+      tunnel:
+        env: STRIPE_WEBHOOK_URL
+        path: /webhooks/stripe`,
+		Example: `  space tunnel api
+  space tunnel api --provider ngrok
+  space tunnel list
+  space tunnel stop api`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+			service, err := c.service("tunnel", args)
+			if err != nil {
+				return err
+			}
+
+			cmd.SilenceUsage = true
+			state, err := startTunnel(c, service, provider)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(console, "🌍 %s is public at %s\n", service, state.URL)
+			fmt.Fprintf(console, "   via %s → %s (pid %d)\n", state.Provider, state.Target, state.PID)
+			if state.Env != "" {
+				fmt.Fprintf(console, "   %s=%s\n", state.Env, state.EnvValue)
+				tipf("Run 'space up %s' to pass %s to the container", service, state.Env)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&provider, "provider", "", "tunnel provider: cloudflared or ngrok (default: tunnel.provider, then whichever is installed)")
+
+	cmd.AddCommand(newTunnelListCommand())
+	cmd.AddCommand(newTunnelStopCommand())
+
+	return cmd
+}
+
+// newTunnelListCommand creates the tunnel list command
+func newTunnelListCommand() *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the open tunnels and their public URLs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			tunnels, err := runningTunnels(c.workDir)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				if tunnels == nil {
+					tunnels = []TunnelState{}
+				}
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(tunnels)
+			}
+
+			if len(tunnels) == 0 {
+				fmt.Fprintln(console, "No tunnels are open.")
+				tipf("Open one with 'space tunnel <service>'")
+				return nil
+			}
+
+			w := newTable(console)
+			fmt.Fprintln(w, "SERVICE\tURL\tPROVIDER\tTARGET\tUPTIME")
+			for _, t := range tunnels {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.Service, t.URL, t.Provider, t.Target, time.Since(t.StartTime).Round(time.Second))
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "output in JSON format")
+
+	return cmd
+}
+
+// newTunnelStopCommand creates the tunnel stop command
+func newTunnelStopCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop [services...]",
+		Short: "Close the tunnels of services (all when none are given)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := loadServiceCommandContext()
+			if err != nil {
+				return err
+			}
+
+			stopped, err := stopTunnels(c.workDir, args)
+			if err != nil {
+				return err
+			}
+			if len(stopped) == 0 {
+				fmt.Fprintln(console, "No tunnels are open.")
+			}
+			return nil
+		},
+	}
+}
+
+// startTunnel opens the tunnel of a service, or returns the one already
+// open, once the provider reports its public URL
+func startTunnel(c *serviceCommandContext, service, provider string) (*TunnelState, error) {
+	if state, err := loadTunnelState(c.workDir, service); err == nil && processRunning(state.PID) {
+		return state, nil
+	}
+
+	svc := c.cfg.Services[service]
+	if svc.EffectiveProtocol() != config.ProtocolHTTP {
+		return nil, fmt.Errorf("service %q speaks %s; tunnels expose http services only", service, svc.EffectiveProtocol())
+	}
+	if svc.Port == 0 && svc.ExternalPort == 0 {
+		return nil, fmt.Errorf("service %q has no port; set services.%s.port in .space.yaml", service, service)
+	}
+
+	var tunnelCfg config.TunnelConfig
+	if svc.Tunnel != nil {
+		tunnelCfg = *svc.Tunnel
+	}
+	if provider == "" {
+		provider = tunnelCfg.Provider
+	}
+	provider, err := pickTunnelProvider(provider, exec.LookPath)
+	if err != nil {
+		return nil, err
+	}
+
+	host, port := serviceAddress(c.cfg, service, c.workDir, serviceDNSActive(c.cfg, c.workDir))
+	target := fmt.Sprintf("http://%s:%d", host, port)
+
+	dir := tunnelsDir(c.workDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create tunnel state directory: %w", err)
+	}
+	logPath := filepath.Join(dir, service+".log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tunnel log: %w", err)
+	}
+	defer logFile.Close()
+
+	proc := exec.Command(provider, tunnelProviders[provider].args(target)...)
+	proc.Stdout = logFile
+	proc.Stderr = logFile
+
+	// Set process group to detach from parent
+	proc.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+
+	if err := proc.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", provider, err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- proc.Wait() }()
+
+	fmt.Fprintf(console, "⏳ Opening a %s tunnel to %s...\n", provider, target)
+	url, err := waitForTunnelURL(provider, logPath, exited)
+	if err != nil {
+		proc.Process.Kill()
+		return nil, fmt.Errorf("%w (see %s)", err, logPath)
+	}
+
+	state := &TunnelState{
+		Service:   service,
+		Provider:  provider,
+		PID:       proc.Process.Pid,
+		Target:    target,
+		URL:       url,
+		StartTime: time.Now(),
+	}
+	if tunnelCfg.Env != "" {
+		state.Env = tunnelCfg.Env
+		state.EnvValue = strings.TrimSuffix(url, "/") + tunnelCfg.Path
+	}
+	if err := saveTunnelState(c.workDir, state); err != nil {
+		proc.Process.Kill()
+		return nil, err
+	}
+	if err := writeTunnelEnvFile(c.workDir); err != nil {
+		fmt.Fprintf(consoleErr, "⚠️  Failed to write tunnels.env: %v\n", err)
+	}
+	return state, nil
+}
+
+// pickTunnelProvider returns the provider to use: the one asked for, which
+// must be installed, or the first installed one
+func pickTunnelProvider(provider string, lookPath func(string) (string, error)) (string, error) {
+	if provider != "" {
+		if _, ok := tunnelProviders[provider]; !ok {
+			return "", fmt.Errorf("unknown tunnel provider %q: use %s or %s", provider, config.TunnelCloudflared, config.TunnelNgrok)
+		}
+		if _, err := lookPath(provider); err != nil {
+			return "", fmt.Errorf("%s is not installed: %s", provider, hooks.InstallHint(provider))
+		}
+		return provider, nil
+	}
+
+	for _, name := range []string{config.TunnelCloudflared, config.TunnelNgrok} {
+		if _, err := lookPath(name); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("no tunnel provider found: %s, or install ngrok", hooks.InstallHint(config.TunnelCloudflared))
+}
+
+// waitForTunnelURL polls the provider's log until the public URL appears,
+// the provider exits or tunnelStartTimeout passes
+func waitForTunnelURL(provider, logPath string, exited <-chan error) (string, error) {
+	deadline := time.After(tunnelStartTimeout)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if url := findTunnelURL(provider, logPath); url != "" {
+			return url, nil
+		}
+		select {
+		case err := <-exited:
+			if err == nil {
+				err = errors.New("exited")
+			}
+			return "", fmt.Errorf("%s stopped before opening the tunnel: %v", provider, err)
+		case <-deadline:
+			return "", fmt.Errorf("%s did not report a public URL within %s", provider, tunnelStartTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// findTunnelURL returns the first public URL in a provider's log
+func findTunnelURL(provider, logPath string) string {
+	f, err := os.Open(logPath)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	pattern := tunnelProviders[provider].url
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if m := pattern.FindStringSubmatch(scanner.Text()); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// tunnelsDir is where the state and logs of the tunnels of a project live
+func tunnelsDir(workDir string) string {
+	return filepath.Join(stateDir(workDir), "tunnels")
+}
+
+// tunnelEnvFile is the env file with the variables of the open tunnels
+func tunnelEnvFile(workDir string) string {
+	return filepath.Join(stateDir(workDir), "tunnels.env")
+}
+
+// loadTunnelState reads the tunnel state of a service
+func loadTunnelState(workDir, service string) (*TunnelState, error) {
+	data, err := os.ReadFile(filepath.Join(tunnelsDir(workDir), service+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var state TunnelState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse tunnel state of %s: %w", service, err)
+	}
+	return &state, nil
+}
+
+// saveTunnelState records the tunnel of a service
+func saveTunnelState(workDir string, state *TunnelState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tunnel state: %w", err)
+	}
+	return os.WriteFile(filepath.Join(tunnelsDir(workDir), state.Service+".json"), data, 0644)
+}
+
+// runningTunnels returns the open tunnels of a project by service name,
+// dropping the state of tunnels whose provider has exited
+func runningTunnels(workDir string) ([]TunnelState, error) {
+	files, err := filepath.Glob(filepath.Join(tunnelsDir(workDir), "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var tunnels []TunnelState
+	for _, file := range files {
+		service := strings.TrimSuffix(filepath.Base(file), ".json")
+		state, err := loadTunnelState(workDir, service)
+		if err != nil {
+			return nil, err
+		}
+		if !processRunning(state.PID) {
+			os.Remove(file)
+			continue
+		}
+		tunnels = append(tunnels, *state)
+	}
+	sort.Slice(tunnels, func(i, j int) bool { return tunnels[i].Service < tunnels[j].Service })
+	return tunnels, nil
+}
+
+// stopTunnels closes the tunnels of services, or all of them when services
+// is empty, and returns the services whose tunnels were closed
+func stopTunnels(workDir string, services []string) ([]string, error) {
+	tunnels, err := runningTunnels(workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var stopped []string
+	for _, t := range tunnels {
+		if len(services) > 0 && !containsString(services, t.Service) {
+			continue
+		}
+		if err := syscall.Kill(t.PID, syscall.SIGTERM); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return stopped, fmt.Errorf("failed to stop the tunnel of %s (pid %d): %w", t.Service, t.PID, err)
+		}
+		os.Remove(filepath.Join(tunnelsDir(workDir), t.Service+".json"))
+		stopped = append(stopped, t.Service)
+		fmt.Fprintf(console, "🔌 Closed the tunnel of %s (%s)\n", t.Service, t.URL)
+	}
+
+	if len(stopped) > 0 {
+		if err := writeTunnelEnvFile(workDir); err != nil {
+			return stopped, fmt.Errorf("failed to write tunnels.env: %w", err)
+		}
+	}
+	return stopped, nil
+}
+
+// writeTunnelEnvFile writes the variables of the open tunnels to
+// .space/state/tunnels.env, or removes it when none set one
+func writeTunnelEnvFile(workDir string) error {
+	tunnels, err := runningTunnels(workDir)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	for _, t := range tunnels {
+		if t.Env != "" {
+			fmt.Fprintf(&b, "%s=%s\n", t.Env, t.EnvValue)
+		}
+	}
+	if b.Len() == 0 {
+		if err := os.Remove(tunnelEnvFile(workDir)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	header := "# Public URLs of the tunnels opened by 'space tunnel' - do not commit\n"
+	return os.WriteFile(tunnelEnvFile(workDir), []byte(header+b.String()), 0644)
+}
+
+// applyTunnelEnvironment sets the tunnel.env variable of services with an
+// open tunnel in their configured environment, so space up passes it on
+func applyTunnelEnvironment(cfg *config.Config, workDir string) {
+	tunnels, _ := runningTunnels(workDir)
+	for _, t := range tunnels {
+		svc, ok := cfg.Services[t.Service]
+		if !ok || t.Env == "" {
+			continue
+		}
+		env := make(map[string]string, len(svc.Environment)+1)
+		for k, v := range svc.Environment {
+			env[k] = v
+		}
+		env[t.Env] = t.EnvValue
+		svc.Environment = env
+		cfg.Services[t.Service] = svc
+	}
+}
+
+// addTunnelsToHookContext gives hook scripts the public URLs of the open
+// tunnels and their tunnel.env variables
+func addTunnelsToHookContext(hookCtx *hooks.HookContext, workDir string) {
+	tunnels, _ := runningTunnels(workDir)
+	vars := make(map[string]string)
+	for _, t := range tunnels {
+		if svc, ok := hookCtx.Services[t.Service]; ok {
+			svc.PublicURL = t.URL
+		}
+		if t.Env != "" {
+			vars[t.Env] = t.EnvValue
+		}
+	}
+	if len(vars) > 0 {
+		hookCtx.AddEnvVars(vars)
+	}
+}
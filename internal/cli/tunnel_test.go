@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestPickTunnelProvider(t *testing.T) {
+	installed := func(names ...string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			if containsString(names, name) {
+				return "/usr/local/bin/" + name, nil
+			}
+			return "", errors.New("not found")
+		}
+	}
+
+	tests := []struct {
+		name      string
+		provider  string
+		installed []string
+		want      string
+		wantErr   bool
+	}{
+		{"prefers cloudflared", "", []string{"ngrok", "cloudflared"}, "cloudflared", false},
+		{"falls back to ngrok", "", []string{"ngrok"}, "ngrok", false},
+		{"configured provider", "ngrok", []string{"ngrok", "cloudflared"}, "ngrok", false},
+		{"configured but missing", "ngrok", []string{"cloudflared"}, "", true},
+		{"unknown provider", "localtunnel", []string{"localtunnel"}, "", true},
+		{"none installed", "", nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pickTunnelProvider(tt.provider, installed(tt.installed...))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("pickTunnelProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("pickTunnelProvider() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindTunnelURL(t *testing.T) {
+	tests := []struct {
+		provider string
+		log      string
+		want     string
+	}{
+		{
+			provider: config.TunnelCloudflared,
+			log: `2026-10-15T09:12:01Z INF Requesting new quick Tunnel on trycloudflare.com...
+2026-10-15T09:12:03Z INF |  https://calm-fox-river.trycloudflare.com                                             |
+2026-10-15T09:12:03Z INF Registered tunnel connection connIndex=0`,
+			want: "https://calm-fox-river.trycloudflare.com",
+		},
+		{
+			provider: config.TunnelNgrok,
+			log: `t=2026-10-15T09:12:01+0000 lvl=info msg="starting web service" obj=web addr=127.0.0.1:4040
+t=2026-10-15T09:12:02+0000 lvl=info msg="started tunnel" obj=tunnels name=command_line addr=http://localhost:8080 url=https://1a2b-203-0-113-7.ngrok-free.app`,
+			want: "https://1a2b-203-0-113-7.ngrok-free.app",
+		},
+		{
+			provider: config.TunnelCloudflared,
+			log:      "2026-10-15T09:12:01Z INF Requesting new quick Tunnel on trycloudflare.com...",
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		logPath := filepath.Join(t.TempDir(), "tunnel.log")
+		if err := os.WriteFile(logPath, []byte(tt.log), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := findTunnelURL(tt.provider, logPath); got != tt.want {
+			t.Errorf("findTunnelURL(%s) = %q, want %q", tt.provider, got, tt.want)
+		}
+	}
+}
+
+func TestTunnelState(t *testing.T) {
+	workDir := t.TempDir()
+	if err := os.MkdirAll(tunnelsDir(workDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	running := &TunnelState{
+		Service:  "api",
+		Provider: config.TunnelCloudflared,
+		PID:      os.Getpid(),
+		URL:      "https://calm-fox.trycloudflare.com",
+		Env:      "STRIPE_WEBHOOK_URL",
+		EnvValue: "https://calm-fox.trycloudflare.com/webhooks/stripe",
+	}
+	exited := &TunnelState{Service: "web", Provider: config.TunnelNgrok, PID: -1, URL: "https://gone.ngrok-free.app"}
+	for _, s := range []*TunnelState{running, exited} {
+		if err := saveTunnelState(workDir, s); err != nil {
+			t.Fatalf("saveTunnelState() error = %v", err)
+		}
+	}
+
+	tunnels, err := runningTunnels(workDir)
+	if err != nil {
+		t.Fatalf("runningTunnels() error = %v", err)
+	}
+	if len(tunnels) != 1 || tunnels[0].Service != "api" {
+		t.Fatalf("runningTunnels() = %+v, want only api", tunnels)
+	}
+	if _, err := loadTunnelState(workDir, "web"); !os.IsNotExist(err) {
+		t.Errorf("state of an exited tunnel kept: %v", err)
+	}
+
+	if err := writeTunnelEnvFile(workDir); err != nil {
+		t.Fatalf("writeTunnelEnvFile() error = %v", err)
+	}
+	data, err := os.ReadFile(tunnelEnvFile(workDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "STRIPE_WEBHOOK_URL=https://calm-fox.trycloudflare.com/webhooks/stripe\n") {
+		t.Errorf("tunnels.env = %q", data)
+	}
+
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{
+		"api": {Port: 8080, Environment: map[string]string{"LOG_LEVEL": "debug"}},
+	}}
+	applyTunnelEnvironment(cfg, workDir)
+	if env := cfg.Services["api"].Environment; env["STRIPE_WEBHOOK_URL"] != running.EnvValue || env["LOG_LEVEL"] != "debug" {
+		t.Errorf("api environment = %v", env)
+	}
+
+	hookCtx := hooks.NewHookContext()
+	hookCtx.Services["api"] = &hooks.ServiceInfo{Name: "api"}
+	addTunnelsToHookContext(hookCtx, workDir)
+	if hookCtx.Services["api"].PublicURL != running.URL {
+		t.Errorf("PublicURL = %q, want %q", hookCtx.Services["api"].PublicURL, running.URL)
+	}
+	if hookCtx.EnvVars()["STRIPE_WEBHOOK_URL"] != running.EnvValue {
+		t.Errorf("hook env = %v", hookCtx.EnvVars())
+	}
+}
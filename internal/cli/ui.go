@@ -0,0 +1,451 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/internal/events"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/internal/ui"
+	"github.com/happy-sdk/space-cli/pkg/xplat"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// uiStartOp is the control socket op starting the web dashboard
+	uiStartOp = "ui.start"
+
+	// defaultUIListen is where the dashboard is served unless --listen says
+	// otherwise
+	defaultUIListen = "127.0.0.1:7420"
+
+	// uiRequestHeader must be sent with requests that change something, so
+	// other sites open in the browser can't trigger them
+	uiRequestHeader = "X-Space-UI"
+
+	// maxUILogLines caps the log lines the dashboard asks for
+	maxUILogLines = 1000
+)
+
+// uiStarted is the reply to ui.start
+type uiStarted struct {
+	URL string `json:"url"`
+}
+
+// uiProject is a project listed by the dashboard
+type uiProject struct {
+	Name       string `json:"name"`
+	WorkDir    string `json:"work_dir"`
+	Containers int    `json:"containers"`
+}
+
+func newUICommand() *cobra.Command {
+	var listen string
+	var printOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "ui",
+		Short: "Open the web dashboard",
+		Long: `Open a dashboard of your projects in the browser. It lists the running
+projects and the one in the working directory, with the state, health and
+URL of every service, recent logs, and buttons to start, stop and restart
+services. It updates as containers start and stop.
+
+The dashboard is served by space agentd on localhost, which is started in
+the background when it is not running, and stays up as long as the agent.`,
+		Example: `  space ui
+  space ui --print
+  space ui --listen 127.0.0.1:8080`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !isDNSServerRunning() {
+				fmt.Fprintln(console, "🌐 Starting space agentd in background...")
+				if err := spawnAgent(); err != nil {
+					return err
+				}
+			}
+
+			var reply uiStarted
+			start := func() error {
+				return callAgent(uiStartOp, map[string]string{"listen": listen, "work_dir": workDirOrEmpty()}, func(data json.RawMessage) error {
+					return json.Unmarshal(data, &reply)
+				})
+			}
+			err := start()
+			// A freshly spawned agent needs a moment to open its socket
+			for i := 0; i < 50 && agentUnreachable(err); i++ {
+				time.Sleep(100 * time.Millisecond)
+				err = start()
+			}
+			if err != nil {
+				return fmt.Errorf("failed to start the dashboard: %w", err)
+			}
+
+			if printOnly {
+				fmt.Fprintln(console, reply.URL)
+				return nil
+			}
+			fmt.Fprintf(console, "🖥️  Dashboard: %s\n", reply.URL)
+			if err := xplat.Open(reply.URL); err != nil {
+				return fmt.Errorf("failed to open %s: %w", reply.URL, err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", defaultUIListen, "Address to serve the dashboard on (loopback only)")
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the URL instead of opening it")
+
+	return cmd
+}
+
+// agentUnreachable reports whether err came from not reaching the agent's
+// socket, rather than from the agent refusing the request
+func agentUnreachable(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// uiDashboard serves the web dashboard from the agent
+type uiDashboard struct {
+	ctx context.Context // the agent's; the server stops with it
+
+	states *events.States // Container states kept by the agent; nil to poll
+
+	mu       sync.Mutex
+	bus      *events.Bus
+	url      string
+	workDirs map[string]bool // Projects opened with space ui, listed while stopped
+}
+
+// newUIDashboard creates the dashboard of an agent running until ctx is done
+func newUIDashboard(ctx context.Context, states *events.States) *uiDashboard {
+	return &uiDashboard{ctx: ctx, states: states, workDirs: make(map[string]bool)}
+}
+
+// setBus sets the bus container events are streamed from
+func (d *uiDashboard) setBus(bus *events.Bus) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.bus = bus
+}
+
+// startHandler serves ui.start: it starts the server on the first request
+// and replies with its URL
+func (d *uiDashboard) startHandler() events.Handler {
+	return func(ctx context.Context, req events.Request, send func(interface{}) error) error {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		if workDir := req.Args["work_dir"]; workDir != "" {
+			d.workDirs[workDir] = true
+		}
+		if d.url == "" {
+			listen := req.Args["listen"]
+			if listen == "" {
+				listen = defaultUIListen
+			}
+			url, err := d.serve(listen)
+			if err != nil {
+				return err
+			}
+			d.url = url
+		}
+		return send(uiStarted{URL: d.url})
+	}
+}
+
+// serve listens on addr and serves the dashboard until the agent stops
+func (d *uiDashboard) serve(addr string) (string, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid listen address %q: %w", addr, err)
+	}
+	if !isLoopbackHost(host) {
+		return "", fmt.Errorf("the dashboard can only listen on a loopback address, not %s", host)
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	server := &http.Server{Handler: d.routes(), ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = server.Serve(listener) }()
+	go func() {
+		<-d.ctx.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}()
+
+	return "http://" + listener.Addr().String() + "/", nil
+}
+
+// routes returns the dashboard's assets and JSON API
+func (d *uiDashboard) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /", ui.Handler())
+	mux.HandleFunc("GET /api/projects", d.handleProjects)
+	mux.HandleFunc("GET /api/projects/{project}/services", d.handleServices)
+	mux.HandleFunc("GET /api/projects/{project}/logs", d.handleLogs)
+	mux.HandleFunc("POST /api/projects/{project}/services/{service}/{action}", d.handleAction)
+	mux.HandleFunc("GET /api/events", d.handleEvents)
+	return uiGuard(mux)
+}
+
+// uiGuard rejects requests addressed to another host, so a page on a
+// rebound DNS name can't read the API, and changes not made by the
+// dashboard's own script
+func uiGuard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if !isLoopbackHost(host) {
+			http.Error(w, "forbidden host", http.StatusForbidden)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Header.Get(uiRequestHeader) == "" {
+			http.Error(w, "missing "+uiRequestHeader+" header", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether host is localhost or a loopback address
+func isLoopbackHost(host string) bool {
+	ip := net.ParseIP(host)
+	return host == "localhost" || (ip != nil && ip.IsLoopback())
+}
+
+// projects returns the compose projects with running containers and the
+// ones opened with space ui, sorted by name
+func (d *uiDashboard) projects(ctx context.Context) ([]uiProject, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "docker", "ps", "--filter", "label=com.docker.compose.project", "--format", dns.PsFormat).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", timeoutError(ctx, "docker", dockerQueryTimeout, err))
+	}
+
+	byDir := make(map[string]*uiProject)
+	for _, c := range dns.ParseContainers(string(out)) {
+		if c.WorkDir == "" {
+			continue
+		}
+		p, ok := byDir[c.WorkDir]
+		if !ok {
+			p = &uiProject{Name: c.Project, WorkDir: c.WorkDir}
+			byDir[c.WorkDir] = p
+		}
+		p.Containers++
+	}
+
+	d.mu.Lock()
+	for workDir := range d.workDirs {
+		if _, ok := byDir[workDir]; !ok {
+			byDir[workDir] = &uiProject{Name: generateProjectName(daemonConfig(workDir), workDir), WorkDir: workDir}
+		}
+	}
+	d.mu.Unlock()
+
+	projects := make([]uiProject, 0, len(byDir))
+	for _, p := range byDir {
+		projects = append(projects, *p)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return projects, nil
+}
+
+// project loads the project named in the request path
+func (d *uiDashboard) project(r *http.Request) (*serviceCommandContext, int, error) {
+	projects, err := d.projects(r.Context())
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+	name := r.PathValue("project")
+	for _, p := range projects {
+		if p.Name == name {
+			c, err := loadProjectContext(p.WorkDir)
+			if err != nil {
+				return nil, http.StatusInternalServerError, err
+			}
+			return c, 0, nil
+		}
+	}
+	return nil, http.StatusNotFound, fmt.Errorf("unknown project %q", name)
+}
+
+func (d *uiDashboard) handleProjects(w http.ResponseWriter, r *http.Request) {
+	projects, err := d.projects(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeUIJSON(w, projects)
+}
+
+func (d *uiDashboard) handleServices(w http.ResponseWriter, r *http.Request) {
+	c, code, err := d.project(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	registerSensitiveValues(c.cfg)
+
+	statuses, err := d.serviceHealth(r.Context(), c)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeUIJSON(w, statuses)
+}
+
+// serviceHealth returns the health of a project's services. The container
+// states come from the agent's event stream; compose ps runs only the first
+// time a project is shown.
+func (d *uiDashboard) serviceHealth(ctx context.Context, c *serviceCommandContext) ([]ServiceHealth, error) {
+	if d.states == nil {
+		return collectServiceHealth(ctx, c.cfg, c.workDir, c.projectName)
+	}
+	if !d.states.Seeded(c.projectName) {
+		if err := seedServiceStates(ctx, c.cfg, c.workDir, c.projectName, d.states); err != nil {
+			return nil, err
+		}
+	}
+	return probeServiceHealth(ctx, c.cfg, c.workDir, c.projectName, trackedServiceStatuses(d.states, c.projectName)), nil
+}
+
+func (d *uiDashboard) handleLogs(w http.ResponseWriter, r *http.Request) {
+	c, code, err := d.project(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	service := r.URL.Query().Get("service")
+	if service != "" && !containsString(c.services, service) {
+		http.Error(w, fmt.Sprintf("unknown service %q", service), http.StatusNotFound)
+		return
+	}
+	tail, err := strconv.Atoi(r.URL.Query().Get("tail"))
+	if err != nil || tail <= 0 || tail > maxUILogLines {
+		tail = 200
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), dockerQueryTimeout)
+	defer cancel()
+
+	args := append(composeCommand(c.workDir, c.cfg, composeFilesFor(c.workDir, c.cfg), c.projectName),
+		"logs", "--no-color", "--timestamps", "--tail", strconv.Itoa(tail))
+	if service != "" {
+		args = append(args, service)
+	}
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = c.workDir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		http.Error(w, redact.String(fmt.Sprintf("%v: %s", timeoutError(ctx, "docker compose logs", dockerQueryTimeout, err), strings.TrimSpace(string(out)))), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	// The writer redacts whole lines; flush the last one, which compose
+	// may not end in a newline
+	redacted := redact.NewWriter(w)
+	_, _ = redacted.Write(out)
+	_ = redacted.Flush()
+}
+
+func (d *uiDashboard) handleAction(w http.ResponseWriter, r *http.Request) {
+	action := r.PathValue("action")
+	if action != "start" && action != "stop" && action != "restart" {
+		http.Error(w, fmt.Sprintf("unknown action %q", action), http.StatusNotFound)
+		return
+	}
+	c, code, err := d.project(r)
+	if err != nil {
+		http.Error(w, err.Error(), code)
+		return
+	}
+	service := r.PathValue("service")
+	if !containsString(c.services, service) {
+		http.Error(w, fmt.Sprintf("unknown service %q", service), http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), composeStopTimeout)
+	defer cancel()
+
+	args := append(composeCommand(c.workDir, c.cfg, composeFilesFor(c.workDir, c.cfg), c.projectName), action, service)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = c.workDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		http.Error(w, fmt.Sprintf("docker compose %s %s: %v: %s", action, service, err, strings.TrimSpace(string(out))), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleEvents streams container and lifecycle events from the agent's bus
+// as server-sent events, so the page refreshes when something changes
+func (d *uiDashboard) handleEvents(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	bus := d.bus
+	d.mu.Unlock()
+
+	flusher, ok := w.(http.Flusher)
+	if bus == nil || !ok {
+		http.Error(w, "events are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	stream, cancel := bus.Subscribe(events.Filter{})
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-stream:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeUIJSON writes v as a JSON response with secrets redacted
+func writeUIJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(redact.NewWriter(w)).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/events"
+)
+
+func TestUIGuard(t *testing.T) {
+	handler := uiGuard(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name   string
+		method string
+		host   string
+		header bool
+		want   int
+	}{
+		{"page on loopback", http.MethodGet, "127.0.0.1:7420", false, http.StatusNoContent},
+		{"page on localhost", http.MethodGet, "localhost:7420", false, http.StatusNoContent},
+		{"rebound hostname", http.MethodGet, "evil.example:7420", false, http.StatusForbidden},
+		{"action from the dashboard", http.MethodPost, "127.0.0.1:7420", true, http.StatusNoContent},
+		{"action from another site", http.MethodPost, "127.0.0.1:7420", false, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/projects", nil)
+			req.Host = tt.host
+			if tt.header {
+				req.Header.Set(uiRequestHeader, "1")
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestUIDashboardStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	d := newUIDashboard(ctx, nil)
+	d.setBus(events.NewBus())
+	start := func(args map[string]string) (string, error) {
+		var reply uiStarted
+		err := d.startHandler()(ctx, events.Request{Op: uiStartOp, Args: args}, func(data interface{}) error {
+			reply = data.(uiStarted)
+			return nil
+		})
+		return reply.URL, err
+	}
+
+	if _, err := start(map[string]string{"listen": "0.0.0.0:0"}); err == nil {
+		t.Fatal("expected a non-loopback address to be refused")
+	}
+
+	url, err := start(map[string]string{"listen": "127.0.0.1:0", "work_dir": "/src/shop"})
+	if err != nil {
+		t.Fatalf("ui.start error = %v", err)
+	}
+	if again, _ := start(map[string]string{"listen": "127.0.0.1:0"}); again != url {
+		t.Errorf("second ui.start = %q, want the running dashboard %q", again, url)
+	}
+	if !d.workDirs["/src/shop"] {
+		t.Error("work_dir of ui.start not remembered")
+	}
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `<script src="app.js">`) {
+		t.Errorf("GET / = %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = http.Post(url+"api/projects/shop/services/api/restart", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("POST without %s = %d, want 403", uiRequestHeader, resp.StatusCode)
+	}
+}
+
+func TestUIEvents(t *testing.T) {
+	bus := events.NewBus()
+	d := newUIDashboard(context.Background(), nil)
+	d.setBus(bus)
+
+	server := httptest.NewServer(d.routes())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	bus.Publish(events.Event{Type: "on-service-start", Project: "shop"})
+
+	line := make([]byte, 512)
+	n, err := resp.Body.Read(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, ok := strings.CutPrefix(strings.TrimSpace(string(line[:n])), "data: ")
+	if !ok {
+		t.Fatalf("event = %q", line[:n])
+	}
+	var e events.Event
+	if err := json.Unmarshal([]byte(data), &e); err != nil || e.Type != "on-service-start" || e.Project != "shop" {
+		t.Errorf("event = %+v, %v", e, err)
+	}
+}
@@ -3,16 +3,23 @@ package cli
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/happy-sdk/space-cli/internal/compose"
 	"github.com/happy-sdk/space-cli/internal/dns"
 	"github.com/happy-sdk/space-cli/internal/hooks"
 	"github.com/happy-sdk/space-cli/internal/provider"
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/internal/statedir"
 	"github.com/happy-sdk/space-cli/pkg/config"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
@@ -20,20 +27,66 @@ import (
 
 var (
 	// Global DNS server for cleanup
-	globalDNSServer   *dns.Server
-	globalDNSResolver *dns.ResolverManager
+	globalDNSServer    *dns.Server
+	globalDNSResolvers []*dns.ResolverManager
 )
 
 func newUpCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "up [services...]",
+		Use:   "up [services or groups...]",
 		Short: "Start services",
-		Long:  "Start all services or specific services defined in docker-compose.yml.",
+		Long: `Start all services or specific services defined in docker-compose.yml.
+Group names from .space.yaml expand to their members, and the services they
+depend on are started too.
+
+Before starting, the VM settings and the memory and CPU limits declared by
+the services are compared with the machine; a stack that cannot fit is
+refused unless --force is passed. So are hooks needing commands that are
+not installed (see 'space doctor'). Services using compose secrets or
+configs that are not defined, or whose file is missing, are always refused.
+In DNS mode the rewritten compose file is checked with 'docker compose
+config' first; if the rewrite made it invalid, what it changed is shown and
+the file is kept for inspection.
+
+Hooks in .space/hooks/on-service-healthy.d run for each service as soon as
+its health check passes; with such hooks up waits for the checks even
+without --wait.
+
+With --foreground no background agent is spawned: the DNS server runs inside
+the command, which follows the logs until Ctrl+C and then takes the project
+down again.
+
+With --dry-run nothing is started, written or run: the compose command line,
+the generated overrides, the hooks in the order they would run, the DNS
+records and the host ports are printed instead, for reviewing environment
+changes.`,
+		Example: `  space up
+  space up api worker
+  space up backend
+  space up --foreground
+  space up --dry-run`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
 			// Get verbose flag
 			verbose, _ := cmd.Flags().GetBool("verbose")
+			foreground, _ := cmd.Flags().GetBool("foreground")
+
+			if refresh, _ := cmd.Flags().GetBool("refresh-override"); refresh && foreground {
+				return fmt.Errorf("--refresh-override updates a running stack and cannot be combined with --foreground")
+			}
+
+			if refresh, _ := cmd.Flags().GetBool("refresh-override"); refresh && len(args) > 0 {
+				return fmt.Errorf("--refresh-override picks the changed services itself and takes no service arguments")
+			}
+
+			// A dry run records generated files instead of writing them and
+			// stops before anything is started
+			var plan *dryRunPlan
+			if dry, _ := cmd.Flags().GetBool("dry-run"); dry {
+				plan = startDryRun()
+				defer stopDryRun()
+			}
 
 			// Get working directory
 			workDir := Workdir
@@ -52,7 +105,7 @@ func newUpCommand() *cobra.Command {
 			}
 
 			// Create loader
-			loader, err := config.NewLoader(workDir)
+			loader, err := newConfigLoader(workDir)
 			if err != nil {
 				return fmt.Errorf("failed to create config loader: %w", err)
 			}
@@ -68,174 +121,453 @@ func newUpCommand() *cobra.Command {
 				return fmt.Errorf("invalid configuration: %w", err)
 			}
 
-			fmt.Printf("🚀 Starting services for project: %s\n", cfg.Project.Name)
-			fmt.Printf("📁 Working directory: %s\n", workDir)
-			fmt.Println()
+			// Mask configured passwords and secrets in all output
+			registerSensitiveValues(cfg)
+
+			bannerf("🚀 Starting services for project: %s", cfg.Project.Name)
+			bannerf("📁 Working directory: %s\n", workDir)
 
 			// Detect provider
 			detector := provider.NewDetector()
 			providerType, err := detector.Detect(ctx)
 			if err != nil {
-				fmt.Printf("⚠️  Failed to detect provider: %v\n", err)
+				fmt.Fprintf(console, "⚠️  Failed to detect provider: %v\n", err)
 				providerType = provider.ProviderGeneric
 			}
-			fmt.Printf("🔍 Detected provider: %s\n", providerType.Description())
+			fmt.Fprintf(console, "🔍 Detected provider: %s\n", providerType.Description())
 
 			// Generate project name
 			projectName := generateProjectName(cfg, workDir)
-			fmt.Printf("📦 Project name: %s\n", projectName)
+			fmt.Fprintf(console, "📦 Project name: %s\n", projectName)
+
+			// Load the merged compose model (configured files plus detected overrides)
+			composeFiles := composeFilesFor(workDir, cfg)
+			if len(composeFiles) > 1 {
+				fmt.Fprintf(console, "📄 Compose files: %s\n", strings.Join(composeFiles, ", "))
+			}
+			project, err := loadComposeProject(workDir, cfg)
+			if err != nil {
+				fmt.Fprintf(console, "⚠️  Failed to load compose files: %v\n", err)
+				project = nil
+			}
+			applyComposeServices(cfg, project)
+
+			// Expand groups and add the services they depend on
+			services, err := resolveServiceArgs(cfg, project, args)
+			if err != nil {
+				return err
+			}
+
+			// Refuse stacks that cannot fit the machine as declared, or
+			// whose hooks would fail on a missing command
+			if force, _ := cmd.Flags().GetBool("force"); !force {
+				if err := checkBudget(ctx, cfg, project, services); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				if err := checkHookPrerequisites(workDir, cfg); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+
+			// Fail before anything starts on secrets and configs compose
+			// could not provide
+			if err := checkComposeReferences(project, services); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
 
 			// Try to start DNS server if using OrbStack
 			useDNS := false
 			var overrideFile string
 			if providerType.SupportsContainerDNS() {
-				fmt.Println()
+				fmt.Fprintln(console)
 
 				// Check if DNS daemon is already running
-				if isDNSServerRunning() {
+				if hostsMode(cfg) {
+					fmt.Fprintln(console, "📝 DNS mode: hosts (entries written to /etc/hosts after start)")
+					useDNS = true
+				} else if isDNSServerRunning() {
 					state, _ := loadDNSState()
-					fmt.Printf("✅ Using existing space-dns-daemon on %s\n", state.Address)
+					fmt.Fprintf(console, "✅ Using existing space-dns-daemon on %s\n", state.Address)
 					useDNS = true
+				} else if plan != nil {
+					if foreground {
+						plan.addAction("run the DNS server in this process")
+					} else {
+						plan.addAction("start space agentd in the background to serve DNS")
+					}
+					useDNS = true
+				} else if foreground {
+					// Host the DNS server in this process for as long as it runs
+					fmt.Fprintln(console, "🌐 Starting DNS server in this process...")
+					if err := startDNSServer(ctx, projectName); err != nil {
+						fmt.Fprintf(console, "⚠️  Failed to start DNS server: %v\n", err)
+						fmt.Fprintln(console, "⚠️  Falling back to port bindings")
+					} else {
+						useDNS = true
+					}
 				} else {
 					// Start DNS daemon as background process
-					fmt.Println("🌐 Starting space-dns-daemon in background...")
-					if err := spawnDNSDaemon(); err != nil {
-						fmt.Printf("⚠️  Failed to start DNS daemon: %v\n", err)
-						fmt.Println("⚠️  Falling back to port bindings")
+					fmt.Fprintln(console, "🌐 Starting space agentd in background...")
+					if err := spawnAgent(); err != nil {
+						fmt.Fprintf(console, "⚠️  Failed to start DNS daemon: %v\n", err)
+						fmt.Fprintln(console, "⚠️  Falling back to port bindings")
 					} else {
 						// Wait a moment for daemon to start
 						time.Sleep(500 * time.Millisecond)
 
 						if isDNSServerRunning() {
 							state, _ := loadDNSState()
-							fmt.Printf("✅ DNS daemon started on %s\n", state.Address)
+							fmt.Fprintf(console, "✅ DNS daemon started on %s\n", state.Address)
 							useDNS = true
 						} else {
-							fmt.Println("⚠️  DNS daemon failed to start, falling back to port bindings")
+							fmt.Fprintln(console, "⚠️  DNS daemon failed to start, falling back to port bindings")
 						}
 					}
 				}
 
+				if useDNS && !hostsMode(cfg) {
+					warnMissingSetup(resolverDomains(cfg))
+				}
+
 				if useDNS {
-					fmt.Printf("   Containers will be accessible at: *.space.local\n")
+					bannerf("   Containers will be accessible at: *.space.local")
+
+					// Inject allowed origins into services with cors configured
+					if project != nil {
+						if updated := applyCORSEnv(project, cfg, projectName, workDir, useDNS); len(updated) > 0 {
+							fmt.Fprintf(console, "🔗 Injecting allowed origins into: %s\n", strings.Join(updated, ", "))
+						}
+					}
 
 					// Create modified compose file without port bindings
-					overrideFile, err = createDNSModeCompose(workDir, cfg)
+					overrideFile, err = createDNSModeCompose(workDir, project)
 					if err != nil {
-						fmt.Printf("⚠️  Failed to create DNS mode compose file: %v\n", err)
+						fmt.Fprintf(console, "⚠️  Failed to create DNS mode compose file: %v\n", err)
 						// Continue anyway - docker-compose will use original ports
 					}
 				}
 			}
 
-			fmt.Println()
+			fmt.Fprintln(console)
+
+			// With --refresh-override only services whose definition changed
+			// since the last up are recreated
+			refresh, _ := cmd.Flags().GetBool("refresh-override")
+			var removedServices []string
+			if refresh {
+				var changed []string
+				changed, removedServices, err = refreshDelta(workDir, project)
+				if err != nil {
+					return err
+				}
+				if len(changed) == 0 && len(removedServices) == 0 {
+					fmt.Fprintln(console, "✅ No service definitions changed, nothing to apply")
+					if overrideFile != "" {
+						_ = os.Remove(overrideFile)
+					}
+					if plan != nil {
+						return nil
+					}
+					return saveUpState(workDir, newUpState(workDir, composeFiles, project, useDNS))
+				}
+				services = changed
+				if len(removedServices) > 0 {
+					fmt.Fprintf(console, "🗑️  Removing services no longer defined: %s\n", strings.Join(removedServices, ", "))
+				}
+			}
 
-			// Build docker compose command
-			composeCmd := []string{"docker", "compose"}
+			// Build docker compose command; the project directory keeps
+			// relative paths resolving next to the original compose files
+			composeCmd := []string{"docker", "compose", "--project-directory", composeProjectDir(workDir, cfg)}
 
 			// Use DNS mode compose file if available, otherwise use original files
 			if overrideFile != "" {
 				composeCmd = append(composeCmd, "-f", overrideFile)
-				fmt.Printf("📝 Using DNS mode compose file: %s\n", overrideFile)
+				fmt.Fprintf(console, "📝 Using DNS mode compose file: %s\n", overrideFile)
 			} else {
 				// Add compose files
-				for _, file := range cfg.Project.ComposeFiles {
+				for _, file := range composeFiles {
 					composeCmd = append(composeCmd, "-f", file)
 				}
 			}
 
-			// Add project name
-			composeCmd = append(composeCmd, "-p", projectName)
+			// Add healthchecks synthesized from health_check settings
+			healthFile, healthServices, err := writeHealthcheckOverride(ctx, workDir, cfg, project)
+			if err != nil {
+				fmt.Fprintf(console, "⚠️  %v\n", err)
+			} else if healthFile != "" {
+				composeCmd = append(composeCmd, "-f", healthFile)
+				fmt.Fprintf(console, "🩺 Adding healthchecks for: %s\n", strings.Join(healthServices, ", "))
+			}
 
-			// Add up command
-			composeCmd = append(composeCmd, "up", "-d")
+			// Pin platforms from .space.yml and the provider defaults
+			providerName := providerConfigName(providerType)
+			platforms := planPlatforms(cfg, project, providerName)
+			platformFile, platformServices, err := writePlatformOverride(workDir, platforms)
+			if err != nil {
+				fmt.Fprintf(console, "⚠️  %v\n", err)
+			} else if platformFile != "" {
+				composeCmd = append(composeCmd, "-f", platformFile)
+				fmt.Fprintf(console, "🧬 Setting platforms for: %s\n", strings.Join(platformServices, ", "))
+			}
+			warnPlatformMismatches(ctx, console, project, platforms, services)
 
-			// Add services if specified
-			if len(args) > 0 {
-				composeCmd = append(composeCmd, args...)
-				fmt.Printf("📋 Starting services: %s\n", strings.Join(args, ", "))
-			} else {
-				fmt.Println("📋 Starting all services")
+			// Add the tracing preset
+			otelFile, tracedServices, err := writeObservabilityOverride(workDir, cfg, project, useDNS)
+			if err != nil {
+				return err
+			}
+			if otelFile != "" {
+				composeCmd = append(composeCmd, "-f", otelFile)
+				fmt.Fprintf(console, "🔭 Tracing with %s, exporting from: %s\n", cfg.Observability.TracingBackend(), strings.Join(tracedServices, ", "))
+				if len(services) > 0 {
+					services = append(services, observabilityServiceNames(cfg)...)
+				}
+			}
+
+			// Add the mail catcher
+			mailFile, mailSenders, err := writeMailOverride(workDir, cfg, project, useDNS)
+			if err != nil {
+				return err
+			}
+			if mailFile != "" {
+				composeCmd = append(composeCmd, "-f", mailFile)
+				fmt.Fprintf(console, "📬 Catching mail from: %s\n", strings.Join(mailSenders, ", "))
 			}
 
-			fmt.Println()
+			// Attach services to the networks they share with other projects
+			networkFile, networks, err := prepareSharedNetworks(ctx, cfg, project, workDir)
+			if err != nil {
+				return err
+			}
+			if networkFile != "" {
+				composeCmd = append(composeCmd, "-f", networkFile)
+				fmt.Fprintf(console, "🕸️  Joining shared networks: %s\n", strings.Join(networks, ", "))
+			}
 
-			// Execute docker compose
-			dockerCmd := exec.Command(composeCmd[0], composeCmd[1:]...)
-			dockerCmd.Dir = workDir
-			dockerCmd.Stdout = os.Stdout
-			dockerCmd.Stderr = os.Stderr
-			dockerCmd.Stdin = os.Stdin
+			// Add per-service environment from .space.yml, with the public
+			// URLs of open tunnels
+			applyTunnelEnvironment(cfg, workDir)
+			envFile, envServices, err := renderServiceEnvironment(ctx, cfg, project, projectName)
+			if err != nil {
+				return fmt.Errorf("failed to render service environment: %w", err)
+			}
+			if envFile != "" {
+				composeCmd = append(composeCmd, "-f", envFile)
+				fmt.Fprintf(console, "🧩 Setting environment for: %s\n", strings.Join(envServices, ", "))
+			}
+			if mapped := mappedUserServices(cfg, project); len(mapped) > 0 && envFile != "" {
+				uid, gid := hostUser()
+				fmt.Fprintf(console, "👤 Running as %d:%d: %s\n", uid, gid, strings.Join(mapped, ", "))
+			}
 
-			fmt.Printf("🔧 Running: %s\n", strings.Join(composeCmd, " "))
-			fmt.Println()
+			// Add rendered secrets; a dry run resolves none of them
+			var secretsFile string
+			if plan != nil && len(cfg.Secrets) > 0 {
+				dir := secretsPath(projectName)
+				secretsFile = filepath.Join(dir, "compose.yml")
+				plan.addAction("resolve the secrets %s into %s", strings.Join(slices.Sorted(maps.Keys(cfg.Secrets)), ", "), dir)
+			} else if secretsFile, err = renderSecrets(ctx, cfg, project, projectName); err != nil {
+				return err
+			}
+			if secretsFile != "" {
+				composeCmd = append(composeCmd, "-f", secretsFile)
+				fmt.Fprintf(console, "🔐 Injecting %d secret(s)\n", len(cfg.Secrets))
+			}
 
-			if err := dockerCmd.Run(); err != nil {
-				// Stop DNS server on failure (but keep resolver configured)
+			// Replace shared database services with proxies to this
+			// worktree's database on the shared server
+			sharedFile, sharedServices, err := prepareSharedDatabases(ctx, cfg, project, workDir, projectName)
+			if err != nil {
+				return err
+			}
+			if sharedFile != "" {
+				composeCmd = append(composeCmd, "-f", sharedFile)
+				fmt.Fprintf(console, "🔀 Proxying shared databases for: %s\n", strings.Join(sharedServices, ", "))
+			}
+
+			// Run the images of a restored checkpoint
+			if checkpointFile := checkpointOverrideFile(workDir); checkpointFile != "" {
+				composeCmd = append(composeCmd, "-f", checkpointFile)
+				fmt.Fprintln(console, "📸 Using the images of a restored checkpoint ('space checkpoint reset' to drop them)")
+			}
+
+			// Add project name
+			composeCmd = append(composeCmd, "-p", projectName)
+
+			// On failure the DNS server goes, but the DNS mode compose file
+			// stays so it can be inspected
+			failStart := func(err error) error {
 				if useDNS && globalDNSServer != nil {
-					fmt.Println("🛑 Stopping space-dns-daemon...")
+					fmt.Fprintln(console, "🛑 Stopping space-dns-daemon...")
 					if err := globalDNSServer.Stop(); err != nil {
-						fmt.Printf("⚠️  Failed to stop DNS daemon: %v\n", err)
+						fmt.Fprintf(console, "⚠️  Failed to stop DNS daemon: %v\n", err)
 					}
 					globalDNSServer = nil
 					// Remove DNS state file on failure
 					if err := removeDNSState(); err != nil {
-						fmt.Printf("⚠️  Failed to remove DNS state: %v\n", err)
+						fmt.Fprintf(console, "⚠️  Failed to remove DNS state: %v\n", err)
 					}
 					// Note: We intentionally do NOT remove the resolver file
 					// It's meant to be permanent once configured
 				}
-				// Don't remove DNS mode compose file on failure so user can inspect it
 				if overrideFile != "" {
-					fmt.Printf("💡 DNS mode compose file preserved for debugging: %s\n", overrideFile)
+					fmt.Fprintf(console, "💡 DNS mode compose file preserved for debugging: %s\n", overrideFile)
 				}
 				return fmt.Errorf("failed to start services: %w", err)
 			}
 
+			// Validate the generated DNS override with everything layered on
+			// it, rather than letting compose fail halfway through up
+			if overrideFile != "" && plan == nil {
+				if err := checkDNSOverride(ctx, workDir, composeCmd, overrideFile, composeFiles); err != nil {
+					cmd.SilenceUsage = true
+					return failStart(err)
+				}
+			}
+
+			// Add up command
+			composeCmd = append(composeCmd, "up", "-d")
+			composeCmd = append(composeCmd, cfg.Provider.ComposeArgsFor(providerName)...)
+			if len(removedServices) > 0 {
+				composeCmd = append(composeCmd, "--remove-orphans")
+			}
+
+			// Add services if specified
+			if len(services) > 0 {
+				composeCmd = append(composeCmd, services...)
+				fmt.Fprintf(console, "📋 Starting services: %s\n", strings.Join(services, ", "))
+			} else {
+				fmt.Fprintln(console, "📋 Starting all services")
+			}
+
+			// A dry run ends here, showing what the start would do
+			if plan != nil {
+				plan.addCommand(composeCmd)
+				if useDNS && hostsMode(cfg) {
+					plan.addAction("point the hostnames at the containers in /etc/hosts")
+				}
+				printUpPlan(plan, workDir, cfg, composeCmd, services, useDNS)
+				return nil
+			}
+
+			fmt.Fprintln(console)
+
+			// Run pre-up hooks
+			runScriptHooks(ctx, hooks.PreUp, workDir, projectName, cfg, useDNS, verbose)
+
+			// Execute docker compose
+			dockerCmd := exec.CommandContext(ctx, composeCmd[0], composeCmd[1:]...)
+			dockerCmd.Dir = workDir
+			dockerCmd.Stdout = os.Stdout
+			dockerCmd.Stderr = os.Stderr
+			dockerCmd.Stdin = os.Stdin
+
+			// Queue behind other projects pulling and starting containers,
+			// so the docker daemon isn't overloaded
+			slot, err := acquireUpSlot(ctx, cfg.Provider.Parallelism, projectName)
+			if err != nil {
+				return failStart(err)
+			}
+
+			fmt.Fprintf(console, "🔧 Running: %s\n", strings.Join(composeCmd, " "))
+			fmt.Fprintln(console)
+
+			err = step("docker compose up", true, dockerCmd.Run)
+			slot.release()
+			if err != nil {
+				return failStart(err)
+			}
+
 			// Clean up DNS mode compose file on success
 			if overrideFile != "" {
 				if err := os.Remove(overrideFile); err != nil {
-					fmt.Printf("⚠️  Failed to cleanup DNS mode compose file: %v\n", err)
+					fmt.Fprintf(console, "⚠️  Failed to cleanup DNS mode compose file: %v\n", err)
 				}
 			}
 
-			fmt.Println()
-			fmt.Println("✅ Services started successfully!")
+			fmt.Fprintln(console)
+			fmt.Fprintln(console, "✅ Services started successfully!")
+
+			// Point the project's hostnames at the new container IPs
+			if useDNS && hostsMode(cfg) {
+				if err := syncHostsFile(ctx, cfg, workDir, projectName); err != nil {
+					fmt.Fprintf(console, "⚠️  Failed to update hosts file: %v\n", err)
+				}
+			}
+
+			// Remember the compose input to detect later edits
+			if err := saveUpState(workDir, newUpState(workDir, composeFiles, project, useDNS)); err != nil {
+				fmt.Fprintf(console, "⚠️  Failed to save up state: %v\n", err)
+			}
+
+			// Let the agent stop the project once its TTL expires; in the
+			// foreground it stops with the command instead
+			if cfg.Agent.TTL > 0 && !foreground {
+				registerProjectTTL(projectName, workDir, cfg.Agent.TTL)
+			}
+
+			// Serve the project's static DNS records from the agent, or from
+			// the DNS server of this process
+			if useDNS && !hostsMode(cfg) && len(cfg.DNS.Records) > 0 {
+				if globalDNSServer != nil {
+					globalDNSServer.SetRecords(projectName, dnsRecords(cfg, workDir))
+				} else {
+					registerDNSRecords(projectName, dnsRecords(cfg, workDir))
+				}
+			}
 
 			// Show DNS daemon status
-			if useDNS {
-				fmt.Println("🔄 space-dns-daemon is running in the background")
-				fmt.Println("   Use 'space dns status' to check status")
-				fmt.Println("   Use 'space dns stop' to stop the daemon")
+			if useDNS && globalDNSServer != nil {
+				fmt.Fprintf(console, "🌐 DNS server is running in this process on %s\n", globalDNSServer.Addr())
+			} else if useDNS && !hostsMode(cfg) {
+				bannerf("🔄 space-dns-daemon is running in the background")
+				bannerf("   Use 'space dns status' to check status")
+				bannerf("   Use 'space dns stop' to stop the daemon")
+			}
+			fmt.Fprintln(console)
+
+			// Wait for health-checked services before running post-up hooks.
+			// Projects with on-service-healthy hooks are watched even
+			// without --wait, running the hooks as each service passes.
+			wait, _ := cmd.Flags().GetBool("wait")
+			if wait || hasHooksFor(workDir, hooks.OnServiceHealthy) {
+				onHealthy := func(service string) {
+					runServiceHooks(ctx, hooks.OnServiceHealthy, service, workDir, projectName, cfg, useDNS, verbose)
+				}
+				if err := waitForHealthy(ctx, cfg, workDir, projectName, useDNS, onHealthy); err != nil && wait {
+					return err
+				}
 			}
-			fmt.Println()
 
 			// Run post-up hooks (external scripts) - always run regardless of DNS mode
 			runScriptHooks(ctx, hooks.PostUp, workDir, projectName, cfg, useDNS, verbose)
 
-			// Show access information
-			if useDNS {
-				fmt.Println("🌍 Access your services at:")
-				for serviceName, service := range cfg.Services {
-					if service.Port > 0 {
-						fmt.Printf("   • %s: http://%s.space.local:%d\n", serviceName, serviceName, service.Port)
+			// Show access information ('space links' lists it when quiet)
+			if !Quiet {
+				fmt.Fprintln(console, "🌍 Access your services at:")
+				for _, serviceName := range sortedServiceNames(cfg) {
+					if _, port := serviceAddress(cfg, serviceName, workDir, useDNS); port > 0 {
+						fmt.Fprintf(console, "   • %s: %s\n", serviceName, redact.String(serviceURL(cfg, serviceName, projectName, workDir, useDNS)))
 					}
 				}
-			} else {
-				fmt.Println("🌍 Access your services at:")
-				for serviceName, service := range cfg.Services {
-					if service.ExternalPort > 0 {
-						fmt.Printf("   • %s: http://localhost:%d\n", serviceName, service.ExternalPort)
-					} else if service.Port > 0 {
-						fmt.Printf("   • %s: http://localhost:%d\n", serviceName, service.Port)
-					}
+				if otelFile != "" {
+					fmt.Fprintf(console, "🔭 Traces: %s\n", serviceURL(cfg, traceUIService(cfg), projectName, workDir, useDNS))
 				}
+				if mailFile != "" {
+					fmt.Fprintf(console, "📬 Mail: %s\n", serviceURL(cfg, mailService, projectName, workDir, useDNS))
+				}
+				fmt.Fprintln(console)
 			}
-
-			fmt.Println()
-			fmt.Println("💡 Tip: Run 'space config show' to see your configuration")
-			fmt.Println("💡 Tip: Run 'space status' to check service status")
-			fmt.Println("💡 Tip: Run 'space logs <service>' to view logs")
+			if foreground {
+				return followProject(ctx, cfg, workDir, projectName, composeFiles)
+			}
+			tipf("Run 'space config show' to see your configuration")
+			tipf("Run 'space status' to check service status")
+			tipf("Run 'space logs <service>' to view logs")
 
 			return nil
 		},
@@ -245,6 +577,11 @@ func newUpCommand() *cobra.Command {
 	cmd.Flags().Bool("build", false, "Build images before starting")
 	cmd.Flags().Bool("force-recreate", false, "Recreate containers even if config hasn't changed")
 	cmd.Flags().BoolP("verbose", "v", false, "Verbose output for debugging hooks and execution")
+	cmd.Flags().Bool("wait", false, "Wait for services with health checks to become healthy")
+	cmd.Flags().Bool("foreground", false, "Run the DNS server in this process and follow logs; Ctrl+C takes the project down")
+	cmd.Flags().Bool("force", false, "Start even when the stack exceeds the machine's CPUs or memory, or hook prerequisites are missing")
+	cmd.Flags().Bool("refresh-override", false, "Regenerate the DNS override and recreate only services changed since the last up")
+	cmd.Flags().Bool("dry-run", false, "Show the compose command, generated files, hooks, DNS records and host ports without starting anything")
 
 	return cmd
 }
@@ -261,43 +598,57 @@ func generateProjectName(cfg *config.Config, workDir string) string {
 		prefix = baseName + "-"
 	}
 
-	var projectName string
-	switch cfg.Project.NamingStrategy {
-	case "git-branch":
-		// Try to get git branch
+	strategies := append([]string{cfg.Project.NamingStrategy}, cfg.Project.NamingFallback...)
+	if len(cfg.Project.NamingFallback) == 0 {
+		strategies = append(strategies, config.NamingDirectory)
+	}
+
+	// When every strategy fails, the configured name is used
+	projectName := baseName
+	for _, strategy := range strategies {
+		if name, ok := projectNameBy(strategy, baseName, prefix, workDir); ok {
+			projectName = name
+			break
+		}
+	}
+
+	// Normalize project name to meet Docker requirements:
+	// - Must start with letter or number
+	// - Only lowercase alphanumeric, hyphens, and underscores
+	return normalizeProjectName(projectName)
+}
+
+// projectNameBy names the project with one naming strategy, reporting
+// false when the strategy can't, as git-branch without a branch
+func projectNameBy(strategy, baseName, prefix, workDir string) (string, bool) {
+	switch strategy {
+	case config.NamingGitBranch:
 		branch := getGitBranch(workDir)
-		if branch != "" {
-			// Clean branch name (remove special chars)
-			branch = strings.ReplaceAll(branch, "/", "-")
-			branch = strings.ReplaceAll(branch, "_", "-")
-			projectName = prefix + branch
-		} else {
-			// Fallback to directory
-			projectName = prefix + filepath.Base(workDir)
+		if branch == "" {
+			return "", false
 		}
+		// Clean branch name (remove special chars)
+		branch = strings.ReplaceAll(branch, "/", "-")
+		branch = strings.ReplaceAll(branch, "_", "-")
+		return prefix + branch, true
 
-	case "directory":
+	case config.NamingDirectory:
 		// Use directory name
-		projectName = prefix + filepath.Base(workDir)
-
-	case "static":
-		fallthrough
+		return prefix + filepath.Base(workDir), true
 
 	default:
 		// Use configured name
-		projectName = baseName
+		return baseName, true
 	}
-
-	// Normalize project name to meet Docker requirements:
-	// - Must start with letter or number
-	// - Only lowercase alphanumeric, hyphens, and underscores
-	return normalizeProjectName(projectName)
 }
 
 // normalizeProjectName ensures project name meets Docker Compose requirements:
 // - Must start with a letter or number
 // - Only lowercase alphanumeric characters, hyphens, and underscores
+// - At most 63 characters (RFC 1123 label length), truncated with a short hash
 func normalizeProjectName(name string) string {
+	original := name
+
 	// Convert to lowercase
 	name = strings.ToLower(name)
 
@@ -320,7 +671,22 @@ func normalizeProjectName(name string) string {
 		}
 	}
 
-	return result.String()
+	normalized, truncated := dns.Truncate(result.String(), original, dns.MaxLabelLength)
+	if truncated {
+		warnTruncatedName(original, normalized)
+	}
+	return normalized
+}
+
+// truncationWarned remembers names already reported as truncated
+var truncationWarned sync.Map
+
+// warnTruncatedName tells the user, once per name, that a name was shortened
+func warnTruncatedName(original, truncated string) {
+	if _, seen := truncationWarned.LoadOrStore(original, true); seen {
+		return
+	}
+	fmt.Fprintf(consoleErr, "⚠️  Project name %q exceeds %d characters, using %q\n", original, dns.MaxLabelLength, truncated)
 }
 
 // isAlphanumeric checks if a byte is a lowercase letter or digit
@@ -328,17 +694,6 @@ func isAlphanumeric(b byte) bool {
 	return (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9')
 }
 
-// getGitBranch returns the current git branch name
-func getGitBranch(workDir string) string {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = workDir
-	output, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-	return strings.TrimSpace(string(output))
-}
-
 // startDNSServer starts the embedded DNS server as a persistent daemon
 func startDNSServer(ctx context.Context, projectName string) error {
 	// Get working directory for hash generation
@@ -347,7 +702,7 @@ func startDNSServer(ctx context.Context, projectName string) error {
 		var err error
 		workDir, err = os.Getwd()
 		if err != nil {
-			fmt.Printf("⚠️  Could not get working directory: %v\n", err)
+			fmt.Fprintf(console, "⚠️  Could not get working directory: %v\n", err)
 			workDir = ""
 		}
 	}
@@ -364,7 +719,7 @@ func startDNSServer(ctx context.Context, projectName string) error {
 	if isDNSServerRunning() {
 		dnsState, err := loadDNSState()
 		if err == nil {
-			fmt.Printf("✅ DNS server already running on %s\n", dnsState.Address)
+			fmt.Fprintf(console, "✅ DNS server already running on %s\n", dnsState.Address)
 			return nil
 		}
 	}
@@ -372,17 +727,27 @@ func startDNSServer(ctx context.Context, projectName string) error {
 	// Create logger
 	logger := dns.NewStdLogger()
 
+	// Domains and query logging come from the config visible from workDir
+	daemonCfg := daemonConfig(workDir)
+	domains := daemonCfg.Network.BaseDomains()
+
 	// Create Docker client
 	dockerClient := dns.NewSimpleDockerClient(logger)
 
-	// Try alternative ports if 5353 is in use
-	ports := []int{5353, 5354, 5355, 5356}
+	// Prefer the stable loopback alias so the resolver file never changes,
+	// then fall back to alternative ports on 127.0.0.1
+	aliasReady := true
+	if err := dns.EnsureLoopbackAlias(ctx, dns.StableIP); err != nil {
+		logger.Debug("Loopback alias unavailable", "error", err)
+		aliasReady = false
+	}
+
 	var server *dns.Server
 	var dnsAddr string
 	var lastErr error
 
-	for _, port := range ports {
-		dnsAddr = fmt.Sprintf("127.0.0.1:%d", port)
+	for _, addr := range dns.PreferAddr(dns.ListenCandidates(aliasReady), lastDNSAddress()) {
+		dnsAddr = addr
 
 		// Create DNS server with hashing enabled
 		var err error
@@ -390,10 +755,12 @@ func startDNSServer(ctx context.Context, projectName string) error {
 			Addr:        dnsAddr,
 			Upstream:    "8.8.8.8:53",
 			ProjectName: projectName,
-			Domain:      "space.local",
-			WorkDir:     workDir,     // Enable directory-based hashing
-			UseHashing:  true,        // Enable hashing by default
+			Domain:      domains[0],
+			Domains:     domains[1:],
+			WorkDir:     workDir, // Enable directory-based hashing
+			UseHashing:  true,    // Enable hashing by default
 			CacheTTL:    30 * time.Second,
+			QueryLog:    daemonCfg.DNS.QueryLog,
 			Docker:      dockerClient,
 			Logger:      logger,
 		})
@@ -414,7 +781,7 @@ func startDNSServer(ctx context.Context, projectName string) error {
 
 	if server == nil || !server.IsRunning() {
 		if lastErr != nil {
-			return fmt.Errorf("failed to start DNS server on any port: %w", lastErr)
+			return fmt.Errorf("failed to start DNS server on any address: %w", lastErr)
 		}
 		return fmt.Errorf("failed to start DNS server")
 	}
@@ -422,50 +789,104 @@ func startDNSServer(ctx context.Context, projectName string) error {
 	// Store global reference
 	globalDNSServer = server
 
-	// Create resolver manager
-	resolver := dns.NewResolverManager("space.local", dnsAddr, logger)
-	globalDNSResolver = resolver
+	// Static records of the daemon's own config; projects add theirs on up
+	server.SetRecords("", dnsRecords(daemonCfg, workDir))
+
+	// Create a resolver per domain and verify each points at the bound
+	// address ('space setup' fixes it with sudo)
+	globalDNSResolvers = nil
+	for _, domain := range resolverDomains(daemonCfg) {
+		resolver := dns.NewResolverManager(domain, dnsAddr, logger)
+		globalDNSResolvers = append(globalDNSResolvers, resolver)
+
+		ensureResolver(resolver)
+	}
 
-	// Setup resolver (requires sudo)
-	fmt.Println("📝 Setting up DNS resolver (may require sudo password)...")
-	if err := resolver.Setup(ctx); err != nil {
-		// Clean up server if resolver setup fails
-		_ = server.Stop()
-		return fmt.Errorf("failed to setup resolver: %w", err)
+	// Remember the address so the next start gets it back
+	if err := statedir.Ensure(); err != nil {
+		logger.Debug("Failed to remember the DNS address", "error", err)
+	} else if err := os.WriteFile(dnsAddressFile(), []byte(dnsAddr+"\n"), 0644); err != nil {
+		logger.Debug("Failed to remember the DNS address", "error", err)
 	}
 
 	// Save DNS server state for persistence
-	if err := saveDNSState(dnsAddr, projectName); err != nil {
-		fmt.Printf("⚠️  Failed to save DNS state: %v\n", err)
+	if err := saveDNSState(dnsAddr, projectName, domains); err != nil {
+		fmt.Fprintf(console, "⚠️  Failed to save DNS state: %v\n", err)
 		// Don't fail - DNS server is running even if state save failed
 	}
 
 	return nil
 }
 
-// createDNSModeCompose creates a modified docker-compose file without port bindings for DNS mode
-func createDNSModeCompose(workDir string, cfg *config.Config) (string, error) {
-	// Get the original compose file
-	composeFile := filepath.Join(workDir, "docker-compose.yml")
-	if len(cfg.Project.ComposeFiles) > 0 {
-		composeFile = filepath.Join(workDir, cfg.Project.ComposeFiles[0])
+// checkComposeReferences refuses to start services using secrets or configs
+// that are undefined or read from a missing file
+func checkComposeReferences(project *compose.Project, services []string) error {
+	if project == nil {
+		return nil
+	}
+	problems := project.CheckReferences(services)
+	if len(problems) == 0 {
+		return nil
 	}
 
-	// Read docker-compose.yml
-	composeData, err := os.ReadFile(composeFile)
-	if err != nil {
-		return "", fmt.Errorf("failed to read docker-compose.yml: %w", err)
+	fmt.Fprintln(console, "❌ Services use secrets or configs that are not available:")
+	for _, p := range problems {
+		fmt.Fprintf(console, "   • %s\n", p)
 	}
+	return fmt.Errorf("%d missing compose secrets or configs; define them or create their files", len(problems))
+}
+
+// dnsComposeFile is the generated compose file used in DNS mode
+const dnsComposeFile = ".space-dns-compose.yml"
 
-	// Parse YAML
-	var composeConfig map[string]interface{}
-	if err := yaml.Unmarshal(composeData, &composeConfig); err != nil {
-		return "", fmt.Errorf("failed to parse docker-compose.yml: %w", err)
+// createDNSModeCompose creates a modified docker-compose file without port bindings for DNS mode.
+// The file is rendered from the merged compose model so override files are honored.
+func createDNSModeCompose(workDir string, project *compose.Project) (string, error) {
+	if project == nil {
+		return "", fmt.Errorf("compose files could not be loaded")
 	}
 
+	composeConfig := project.Data
+
 	// Process services to remove port bindings
+	removedPorts := removePortBindings(project)
+
+	if len(removedPorts) > 0 {
+		fmt.Fprintf(console, "🔧 Removing host port bindings for: %s\n", strings.Join(removedPorts, ", "))
+		fmt.Fprintf(console, "   Ports will be accessible via DNS at: *.space.local\n")
+	}
+
+	// Write modified compose file
+	path := filepath.Join(workDir, dnsComposeFile)
+
+	// Marshal back to YAML
+	modifiedData, err := yaml.Marshal(composeConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal modified compose: %w", err)
+	}
+
+	// Add header comment
+	header := "# Auto-generated DNS mode compose file\n"
+	header += "# This file has all port bindings removed - services accessible via DNS at *.space.local\n"
+	header += "# Generated from: " + strings.Join(project.Files, ", ") + "\n"
+
+	reused, err := writeGenerated(path, header, modifiedData)
+	if err != nil {
+		return "", fmt.Errorf("failed to write DNS mode compose file: %w", err)
+	}
+	if reused {
+		fmt.Fprintf(console, "♻️  Reusing up-to-date %s\n", dnsComposeFile)
+	}
+
+	return path, nil
+}
+
+// removePortBindings replaces the host port bindings of every service with
+// expose entries for the container ports, since services are reached by DNS
+// name. It returns the services that had bindings.
+func removePortBindings(project *compose.Project) []string {
 	removedPorts := []string{}
-	if composeServices, ok := composeConfig["services"].(map[string]interface{}); ok {
+	if composeServices, ok := project.Data["services"].(map[string]interface{}); ok {
 		for serviceName, serviceConfig := range composeServices {
 			if svc, ok := serviceConfig.(map[string]interface{}); ok {
 				// Check if service has ports defined
@@ -499,32 +920,8 @@ func createDNSModeCompose(workDir string, cfg *config.Config) (string, error) {
 		}
 	}
 
-	if len(removedPorts) > 0 {
-		fmt.Printf("🔧 Removing host port bindings for: %s\n", strings.Join(removedPorts, ", "))
-		fmt.Printf("   Ports will be accessible via DNS at: *.space.local\n")
-	}
-
-	// Write modified compose file
-	dnsComposeFile := filepath.Join(workDir, ".space-dns-compose.yml")
-
-	// Marshal back to YAML
-	modifiedData, err := yaml.Marshal(composeConfig)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal modified compose: %w", err)
-	}
-
-	// Add header comment
-	header := "# Auto-generated DNS mode compose file\n"
-	header += "# This file has all port bindings removed - services accessible via DNS at *.space.local\n"
-	header += "# Generated from: " + filepath.Base(composeFile) + "\n\n"
-
-	finalContent := header + string(modifiedData)
-
-	if err := os.WriteFile(dnsComposeFile, []byte(finalContent), 0644); err != nil {
-		return "", fmt.Errorf("failed to write DNS mode compose file: %w", err)
-	}
-
-	return dnsComposeFile, nil
+	sort.Strings(removedPorts)
+	return removedPorts
 }
 
 // DNSState represents the state of the running DNS daemon
@@ -533,25 +930,39 @@ type DNSState struct {
 	ProjectName string    `json:"project_name"`
 	StartTime   time.Time `json:"start_time"`
 	PID         int       `json:"pid"`
+	Domains     []string  `json:"domains,omitempty"`
+}
+
+// BaseDomains returns the domains the daemon serves, the first being the one
+// URLs use. State files written before domains were recorded get the default.
+func (s *DNSState) BaseDomains() []string {
+	if len(s.Domains) == 0 {
+		return []string{config.DefaultBaseDomain}
+	}
+	return s.Domains
 }
 
-// spawnDNSDaemon spawns the DNS daemon as a detached background process
-func spawnDNSDaemon() error {
+// spawnAgent starts space agentd, which runs the DNS server, as a detached
+// background process
+func spawnAgent() error {
 	// Get the path to the current executable
 	execPath, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("failed to get executable path: %w", err)
 	}
 
-	// Create log file for DNS daemon output
-	logPath := filepath.Join(os.TempDir(), "space-dns-daemon.log")
+	// Create log file for agent output
+	logPath, err := agentLogPath()
+	if err != nil {
+		return err
+	}
 	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to create log file: %w", err)
 	}
 
-	// Spawn "space dns start" as a background process
-	cmd := exec.Command(execPath, "dns", "start")
+	// Spawn "space agentd" as a background process
+	cmd := exec.Command(execPath, "agentd")
 
 	// Redirect output to log file
 	cmd.Stdout = logFile
@@ -566,33 +977,45 @@ func spawnDNSDaemon() error {
 	// Start the process in the background
 	if err := cmd.Start(); err != nil {
 		logFile.Close()
-		return fmt.Errorf("failed to spawn DNS daemon: %w", err)
+		return fmt.Errorf("failed to spawn space agentd: %w", err)
 	}
 
 	// Don't wait for the process - let it run independently
 	// Note: We don't close logFile here - the child process needs it
 
-	fmt.Printf("   DNS daemon log: %s\n", logPath)
+	fmt.Fprintf(console, "   Agent log: %s\n", logPath)
 
 	return nil
 }
 
 // getDNSStateFile returns the path to the DNS state file
 func getDNSStateFile() string {
-	homeDir, err := os.UserHomeDir()
+	return statedir.Path("dns-daemon.json")
+}
+
+// dnsAddressFile keeps the address the DNS daemon last bound. Unlike the
+// state file it outlives the daemon.
+func dnsAddressFile() string {
+	return statedir.Path("dns-address")
+}
+
+// lastDNSAddress returns the address the DNS daemon last bound, or ""
+func lastDNSAddress() string {
+	data, err := os.ReadFile(dnsAddressFile())
 	if err != nil {
-		return ".space-dns-daemon.json"
+		return ""
 	}
-	return filepath.Join(homeDir, ".space-dns-daemon.json")
+	return strings.TrimSpace(string(data))
 }
 
 // saveDNSState saves the DNS daemon state to a file
-func saveDNSState(address, projectName string) error {
+func saveDNSState(address, projectName string, domains []string) error {
 	state := DNSState{
 		Address:     address,
 		ProjectName: projectName,
 		StartTime:   time.Now(),
 		PID:         os.Getpid(),
+		Domains:     domains,
 	}
 
 	data, err := yaml.Marshal(state)
@@ -600,6 +1023,9 @@ func saveDNSState(address, projectName string) error {
 		return err
 	}
 
+	if err := statedir.Ensure(); err != nil {
+		return err
+	}
 	return os.WriteFile(getDNSStateFile(), data, 0644)
 }
 
@@ -638,110 +1064,109 @@ func removeDNSState() error {
 
 // cleanupDNSServer stops the DNS server and cleans up resolver
 func cleanupDNSServer(ctx context.Context) {
-	if globalDNSResolver != nil {
-		fmt.Println("🧹 Cleaning up DNS resolver...")
-		if err := globalDNSResolver.Cleanup(ctx); err != nil {
-			fmt.Printf("⚠️  Failed to cleanup resolver: %v\n", err)
+	if len(globalDNSResolvers) > 0 {
+		fmt.Fprintln(console, "🧹 Cleaning up DNS resolver...")
+		for _, resolver := range globalDNSResolvers {
+			// A resolver pointing at another address is another user's
+			// daemon's, or stale anyway; leave it alone
+			if status, err := resolver.Verify(); err == nil && status.Stale() {
+				continue
+			}
+			if err := resolver.Cleanup(ctx); err != nil {
+				fmt.Fprintf(console, "⚠️  Failed to cleanup resolver: %v\n", err)
+			}
 		}
-		globalDNSResolver = nil
+		globalDNSResolvers = nil
 	}
 
 	if globalDNSServer != nil {
-		fmt.Println("🛑 Stopping space-dns-daemon...")
+		fmt.Fprintln(console, "🛑 Stopping space-dns-daemon...")
 		if err := globalDNSServer.Stop(); err != nil {
-			fmt.Printf("⚠️  Failed to stop DNS daemon: %v\n", err)
+			fmt.Fprintf(console, "⚠️  Failed to stop DNS daemon: %v\n", err)
 		}
 		globalDNSServer = nil
 
 		// Remove state file
 		if err := removeDNSState(); err != nil {
-			fmt.Printf("⚠️  Failed to remove DNS state: %v\n", err)
+			fmt.Fprintf(console, "⚠️  Failed to remove DNS state: %v\n", err)
 		}
 	}
 }
 
 // runScriptHooks runs external hook scripts for a given event
 func runScriptHooks(ctx context.Context, event hooks.EventType, workDir, projectName string, cfg *config.Config, dnsEnabled, verbose bool) {
+	// Let external tools know about the event
+	publishEvent(event, projectName, workDir, map[string]interface{}{"dns_enabled": dnsEnabled})
+
+	// One context for all hooks, so variables exported by built-in hooks
+	// reach the project's hooks
+	hookCtx := buildHookContext(workDir, projectName, cfg, dnsEnabled)
+
+	// Post-up hooks get the services' startup logs to check, when configured
+	if event == hooks.PostUp && cfg.Hooks.StartupLogs != nil {
+		if path, err := collectStartupLogs(ctx, cfg, workDir, projectName); err != nil {
+			fmt.Fprintf(console, "⚠️  Failed to collect startup logs: %v\n", err)
+		} else {
+			hookCtx.StartupLogs = path
+		}
+	}
+
+	// Show what the hooks change in the project's files, and keep the old
+	// versions for 'space hooks undo'
+	defer recordHookChanges(workDir, event, snapshotHookFiles(workDir))
+
+	// Built-in hooks configured under hooks: run before project hooks
+	runBuiltinHooks(ctx, event, hookCtx, cfg, verbose)
+
+	// Run Go-native hooks from .space/hooks.go first
+	runGoHooks(ctx, event, hookCtx, verbose)
+
 	// Check if hooks directory exists
 	hooksDir := filepath.Join(workDir, ".space", "hooks", string(event)+".d")
 	if _, err := os.Stat(hooksDir); os.IsNotExist(err) {
 		if verbose {
-			fmt.Printf("   [verbose] No hooks directory found: %s\n", hooksDir)
+			fmt.Fprintf(console, "   [verbose] No hooks directory found: %s\n", hooksDir)
 		}
 		return // No hooks directory for this event
 	}
 
 	// Create script executor
 	executor := hooks.NewScriptExecutor(workDir)
+	executor.Verbose = verbose
 
-	fmt.Println()
-	fmt.Printf("🪝 Running %s hooks...\n", event)
+	fmt.Fprintln(console)
+	fmt.Fprintf(console, "🪝 Running %s hooks...\n", event)
 
 	if verbose {
-		fmt.Printf("   [verbose] Hooks directory: %s\n", hooksDir)
-	}
-
-	// Build hook context
-	hookCtx := &hooks.HookContext{
-		WorkDir:     workDir,
-		ProjectName: projectName,
-		DNSEnabled:  dnsEnabled,
-		BaseDomain:  "space.local",
-		Hash:        dns.GenerateDirectoryHash(workDir),
-		Services:    make(map[string]*hooks.ServiceInfo),
-		Metadata:    make(map[string]interface{}),
+		fmt.Fprintf(console, "   [verbose] Hooks directory: %s\n", hooksDir)
 	}
 
 	if verbose {
-		fmt.Printf("   [verbose] Hook context:\n")
-		fmt.Printf("             WorkDir: %s\n", hookCtx.WorkDir)
-		fmt.Printf("             ProjectName: %s\n", hookCtx.ProjectName)
-		fmt.Printf("             DNSEnabled: %t\n", hookCtx.DNSEnabled)
-		fmt.Printf("             Hash: %s\n", hookCtx.Hash)
+		fmt.Fprintf(console, "   [verbose] Hook context:\n")
+		fmt.Fprintf(console, "             WorkDir: %s\n", hookCtx.WorkDir)
+		fmt.Fprintf(console, "             ProjectName: %s\n", hookCtx.ProjectName)
+		fmt.Fprintf(console, "             DNSEnabled: %t\n", hookCtx.DNSEnabled)
+		fmt.Fprintf(console, "             Hash: %s\n", hookCtx.Hash)
 	}
 
-	// Add services from config with DNS names or localhost
-	for name, svc := range cfg.Services {
-		var serviceHost string
-		var serviceURL string
-		if dnsEnabled {
-			dnsName := fmt.Sprintf("%s-%s.%s", name, hookCtx.Hash, hookCtx.BaseDomain)
-			serviceHost = dnsName
-			serviceURL = fmt.Sprintf("http://%s:%d", dnsName, svc.Port)
-		} else {
-			// Non-DNS mode: use localhost with external port
-			serviceHost = "localhost"
-			port := svc.ExternalPort
-			if port == 0 {
-				port = svc.Port
-			}
-			serviceURL = fmt.Sprintf("http://localhost:%d", port)
-		}
-
-		hookCtx.Services[name] = &hooks.ServiceInfo{
-			Name:         name,
-			DNSName:      serviceHost,
-			InternalPort: svc.Port,
-			ExternalPort: svc.ExternalPort,
-			URL:          serviceURL,
-		}
-
-		if verbose {
-			fmt.Printf("   [verbose] Service '%s': host=%s, port=%d, url=%s\n",
-				name, serviceHost, svc.Port, serviceURL)
+	if verbose {
+		for _, name := range sortedServiceNames(cfg) {
+			svc := hookCtx.Services[name]
+			fmt.Fprintf(console, "   [verbose] Service '%s': host=%s, port=%d, url=%s\n",
+				name, svc.Host, svc.InternalPort, redact.String(svc.URL))
 		}
 	}
 
 	if verbose && len(cfg.Services) == 0 {
-		fmt.Printf("   [verbose] No services defined in config - hooks may not have service info\n")
-		fmt.Printf("   [verbose] Consider creating a .space.yaml with service definitions\n")
+		fmt.Fprintf(console, "   [verbose] No services defined in config - hooks may not have service info\n")
+		fmt.Fprintf(console, "   [verbose] Consider creating a .space.yaml with service definitions\n")
 	}
 
 	// List scripts that will be executed
 	if verbose {
 		entries, err := os.ReadDir(hooksDir)
 		if err == nil {
-			fmt.Printf("   [verbose] Scripts in directory:\n")
+			fmt.Fprintf(console, "   [verbose] Scripts in directory:\n")
 			for _, entry := range entries {
 				if !entry.IsDir() {
 					info, _ := entry.Info()
@@ -749,6 +1174,12 @@ func runScriptHooks(ctx context.Context, event hooks.EventType, workDir, project
 					status := "skipped (not executable)"
 					if executable {
 						status = "will execute"
+						path := filepath.Join(hooksDir, entry.Name())
+						if meta, err := hooks.ReadScriptMeta(path); err == nil {
+							if reason := hooks.PlatformMismatch(path, meta); reason != "" {
+								status = "skipped (" + reason + ")"
+							}
+						}
 					}
 					if strings.HasSuffix(entry.Name(), ".md") || strings.HasSuffix(entry.Name(), ".txt") {
 						status = "skipped (documentation)"
@@ -756,7 +1187,7 @@ func runScriptHooks(ctx context.Context, event hooks.EventType, workDir, project
 					if entry.Name() == ".gitkeep" {
 						status = "skipped (gitkeep)"
 					}
-					fmt.Printf("             %s - %s\n", entry.Name(), status)
+					fmt.Fprintf(console, "             %s - %s\n", entry.Name(), status)
 				}
 			}
 		}
@@ -764,6 +1195,77 @@ func runScriptHooks(ctx context.Context, event hooks.EventType, workDir, project
 
 	// Execute scripts
 	if err := executor.Execute(ctx, event, hookCtx); err != nil {
-		fmt.Printf("   ⚠️  Hook execution failed: %v\n", err)
+		fmt.Fprintf(console, "   ⚠️  Hook execution failed: %v\n", err)
+	}
+}
+
+// runServiceHooks runs the Go and script hooks of a per-service event, such
+// as on-service-healthy, with the service named in their context
+func runServiceHooks(ctx context.Context, event hooks.EventType, service, workDir, projectName string, cfg *config.Config, dnsEnabled, verbose bool) {
+	publishEvent(event, projectName, workDir, map[string]interface{}{"service": service})
+
+	hookCtx := buildHookContext(workDir, projectName, cfg, dnsEnabled)
+	hookCtx.ServiceName = service
+	defer recordHookChanges(workDir, event, snapshotHookFiles(workDir))
+
+	runGoHooks(ctx, event, hookCtx, verbose)
+
+	executor := hooks.NewScriptExecutor(workDir)
+	executor.Verbose = verbose
+	if !executor.HasScripts(event) {
+		return
+	}
+	fmt.Fprintf(console, "🪝 Running %s hooks for %s...\n", event, service)
+	if err := executor.Execute(ctx, event, hookCtx); err != nil {
+		fmt.Fprintf(console, "   ⚠️  Hook execution failed: %v\n", err)
+	}
+}
+
+// hasHooksFor reports whether the project has script or Go hooks for an
+// event
+func hasHooksFor(workDir string, event hooks.EventType) bool {
+	if hooks.NewScriptExecutor(workDir).HasScripts(event) {
+		return true
+	}
+	goHooks, err := hooks.LoadGoHooks(workDir)
+	if err != nil {
+		return false
 	}
+	for _, h := range goHooks {
+		if slices.Contains(h.Events(), event) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildHookContext builds the context passed to hook scripts, with services
+// from config addressed by DNS name or localhost
+func buildHookContext(workDir, projectName string, cfg *config.Config, dnsEnabled bool) *hooks.HookContext {
+	hookCtx := &hooks.HookContext{
+		WorkDir:      workDir,
+		ProjectName:  projectName,
+		DNSEnabled:   dnsEnabled,
+		BaseDomain:   cfg.Network.BaseDomain(),
+		Hash:         dns.GenerateDirectoryHash(workDir),
+		ComposeFiles: composeFilesFor(workDir, cfg),
+		Services:     make(map[string]*hooks.ServiceInfo),
+		Metadata:     make(map[string]interface{}),
+	}
+
+	for name, svc := range cfg.Services {
+		serviceHost, port := serviceAddress(cfg, name, workDir, dnsEnabled)
+
+		hookCtx.Services[name] = &hooks.ServiceInfo{
+			Name:         name,
+			DNSName:      serviceHost,
+			Host:         serviceHost,
+			InternalPort: svc.Port,
+			ExternalPort: svc.ExternalPort,
+			URL:          renderServiceURL(cfg, name, projectName, serviceHost, port),
+		}
+	}
+	addTunnelsToHookContext(hookCtx, workDir)
+
+	return hookCtx
 }
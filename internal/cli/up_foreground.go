@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// followProject streams the project's logs until Ctrl+C, or until every
+// container has exited, and then takes the project down the way 'space down'
+// does, including a DNS server started by this process
+func followProject(ctx context.Context, cfg *config.Config, workDir, projectName string, composeFiles []string) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	args := followArgs(workDir, cfg, projectName, composeFiles)
+	logsCmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	logsCmd.Dir = workDir
+	logsCmd.Stdout = os.Stdout
+	logsCmd.Stderr = os.Stderr
+	// Let compose exit on its own terms when we are stopped with SIGTERM
+	logsCmd.Cancel = func() error { return logsCmd.Process.Signal(os.Interrupt) }
+	logsCmd.WaitDelay = 5 * time.Second
+
+	fmt.Fprintln(console, "📜 Following logs, press Ctrl+C to stop and remove the project")
+	fmt.Fprintln(console)
+	if err := logsCmd.Run(); err != nil && ctx.Err() == nil {
+		fmt.Fprintf(console, "⚠️  Log stream ended: %v\n", err)
+	}
+
+	// A second Ctrl+C interrupts the teardown
+	stop()
+	fmt.Fprintln(console)
+
+	// Resolver files are permanent ('space setup'); only the server goes
+	globalDNSResolvers = nil
+	down := newDownCommand()
+	return down.RunE(down, nil)
+}
+
+// followArgs returns the compose command streaming the project's logs
+func followArgs(workDir string, cfg *config.Config, projectName string, composeFiles []string) []string {
+	args := composeCommand(workDir, cfg, withGeneratedServices(workDir, composeFiles), projectName)
+	return append(args, "logs", "--follow", "--tail", "20")
+}
@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestFollowArgs(t *testing.T) {
+	workDir := t.TempDir()
+	compose := filepath.Join(workDir, "docker-compose.yml")
+
+	got := strings.Join(followArgs(workDir, config.Defaults(), "shop-main", []string{compose}), " ")
+	want := "docker compose --project-directory " + workDir + " -f " + compose + " -p shop-main logs --follow --tail 20"
+	if got != want {
+		t.Errorf("followArgs() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/statedir"
+	"golang.org/x/sys/unix"
+)
+
+// upSlotPoll is how often a queued 'space up' checks for a free slot
+const upSlotPoll = 500 * time.Millisecond
+
+// upSlotsDir holds a lock file per 'space up' allowed to start containers
+// at once. The locks are released by the kernel when a process dies, so a
+// crashed run never blocks the queue.
+func upSlotsDir() (string, error) {
+	dir, err := statedir.TempDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "up-slots"), nil
+}
+
+// upSlot is a slot held by this process
+type upSlot struct {
+	file *os.File
+}
+
+// upSlotHolder is a 'space up' holding a slot
+type upSlotHolder struct {
+	Project string
+	PID     int
+	Since   time.Time
+}
+
+// acquireUpSlot waits until fewer than limit 'space up' are starting
+// containers and takes a slot, printing what it waits for while queued. It
+// returns nil without a limit.
+func acquireUpSlot(ctx context.Context, limit int, projectName string) (*upSlot, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	dir, err := upSlotsDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var waitingFor []string
+	queued := time.Now()
+	for {
+		slot, err := tryUpSlots(dir, limit, projectName)
+		if err != nil || slot != nil {
+			if slot != nil && waitingFor != nil {
+				fmt.Fprintf(console, "▶️  Continuing after %s in the queue\n", time.Since(queued).Round(time.Second))
+			}
+			return slot, err
+		}
+
+		// Report the queue again only when other projects hold the slots
+		holders := upSlotHolders(dir, limit)
+		if names := holderProjects(holders); !slices.Equal(names, waitingFor) {
+			fmt.Fprintf(console, "⏳ Waiting for another space up to finish pulling and starting containers: %s\n", describeHolders(holders))
+			if waitingFor == nil {
+				tipf("provider.parallelism in ~/.config/space/config.yaml sets how many run at once")
+			}
+			waitingFor = names
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(upSlotPoll):
+		}
+	}
+}
+
+// tryUpSlots takes the first free slot, or returns nil when all are held
+func tryUpSlots(dir string, limit int, projectName string) (*upSlot, error) {
+	for i := 0; i < limit; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("slot-%d", i))
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", path, err)
+		}
+		if err := unix.Flock(int(file.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+			file.Close()
+			continue
+		}
+
+		// Tell waiting runs who holds the slot
+		holder := fmt.Sprintf("%s\n%d\n%s\n", projectName, os.Getpid(), time.Now().Format(time.RFC3339))
+		if err := file.Truncate(0); err == nil {
+			_, _ = file.WriteAt([]byte(holder), 0)
+		}
+		return &upSlot{file: file}, nil
+	}
+	return nil, nil
+}
+
+// release frees the slot for the next queued 'space up'
+func (s *upSlot) release() {
+	if s == nil {
+		return
+	}
+	_ = s.file.Truncate(0)
+	_ = unix.Flock(int(s.file.Fd()), unix.LOCK_UN)
+	s.file.Close()
+}
+
+// upSlotHolders reads who holds the slots, skipping slots being taken and
+// those left behind by a run that crashed before releasing its slot
+func upSlotHolders(dir string, limit int) []upSlotHolder {
+	var holders []upSlotHolder
+	for i := 0; i < limit; i++ {
+		data, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("slot-%d", i)))
+		if err != nil {
+			continue
+		}
+		if holder, ok := parseUpSlotHolder(string(data)); ok && processRunning(holder.PID) {
+			holders = append(holders, holder)
+		}
+	}
+	return holders
+}
+
+// parseUpSlotHolder parses a slot file: the project, pid and start time on
+// a line each
+func parseUpSlotHolder(data string) (upSlotHolder, bool) {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	if len(lines) != 3 {
+		return upSlotHolder{}, false
+	}
+	pid, err := strconv.Atoi(lines[1])
+	if err != nil {
+		return upSlotHolder{}, false
+	}
+	since, err := time.Parse(time.RFC3339, lines[2])
+	if err != nil {
+		return upSlotHolder{}, false
+	}
+	return upSlotHolder{Project: lines[0], PID: pid, Since: since}, true
+}
+
+// holderProjects returns the projects holding slots, sorted
+func holderProjects(holders []upSlotHolder) []string {
+	names := make([]string, 0, len(holders))
+	for _, holder := range holders {
+		names = append(names, holder.Project)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// describeHolders lists the projects holding slots and for how long
+func describeHolders(holders []upSlotHolder) string {
+	if len(holders) == 0 {
+		return "another project"
+	}
+	parts := make([]string, 0, len(holders))
+	for _, holder := range holders {
+		parts = append(parts, fmt.Sprintf("%s (%s)", holder.Project, time.Since(holder.Since).Round(time.Second)))
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, ", ")
+}
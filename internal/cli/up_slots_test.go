@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/statedir"
+)
+
+func TestParseUpSlotHolder(t *testing.T) {
+	holder, ok := parseUpSlotHolder("shop-main\n4242\n2026-10-15T09:30:00Z\n")
+	if !ok {
+		t.Fatal("parseUpSlotHolder() failed")
+	}
+	if holder.Project != "shop-main" || holder.PID != 4242 || !holder.Since.Equal(time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("parseUpSlotHolder() = %+v", holder)
+	}
+
+	for _, data := range []string{"", "shop-main\n4242\n", "shop-main\npid\n2026-10-15T09:30:00Z", "shop-main\n4242\nyesterday"} {
+		if _, ok := parseUpSlotHolder(data); ok {
+			t.Errorf("parseUpSlotHolder(%q) succeeded", data)
+		}
+	}
+}
+
+func TestUpSlotHoldersSkipsCrashedRuns(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().Format(time.RFC3339)
+	running := fmt.Sprintf("shop-main\n%d\n%s\n", os.Getpid(), now)
+	if err := os.WriteFile(filepath.Join(dir, "slot-0"), []byte(running), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A run killed while holding slot-1 left its holder behind
+	crashed := exec.Command("true")
+	if err := crashed.Run(); err != nil {
+		t.Skip("true not available")
+	}
+	stale := fmt.Sprintf("blog-dev\n%d\n%s\n", crashed.Process.Pid, now)
+	if err := os.WriteFile(filepath.Join(dir, "slot-1"), []byte(stale), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	holders := upSlotHolders(dir, 2)
+	if got := holderProjects(holders); strings.Join(got, ",") != "shop-main" {
+		t.Errorf("upSlotHolders() = %v, want only the running holder", got)
+	}
+}
+
+func TestDescribeHolders(t *testing.T) {
+	if got := describeHolders(nil); got != "another project" {
+		t.Errorf("describeHolders(nil) = %q", got)
+	}
+
+	since := time.Now().Add(-90 * time.Second)
+	holders := []upSlotHolder{{Project: "shop-main", Since: since}, {Project: "blog-dev", Since: since}}
+	if got := describeHolders(holders); got != "blog-dev (1m30s), shop-main (1m30s)" {
+		t.Errorf("describeHolders() = %q", got)
+	}
+	if got := holderProjects(holders); strings.Join(got, ",") != "blog-dev,shop-main" {
+		t.Errorf("holderProjects() = %v", got)
+	}
+}
+
+func TestAcquireUpSlot(t *testing.T) {
+	t.Setenv(statedir.EnvVar, filepath.Join(t.TempDir(), "state"))
+	originalConsole := console
+	defer func() { console = originalConsole }()
+	console = io.Discard
+
+	if slot, err := acquireUpSlot(context.Background(), 0, "shop"); slot != nil || err != nil {
+		t.Fatalf("acquireUpSlot() without a limit = %v, %v", slot, err)
+	}
+
+	first, err := acquireUpSlot(context.Background(), 1, "shop-main")
+	if err != nil || first == nil {
+		t.Fatalf("acquireUpSlot() = %v, %v", first, err)
+	}
+
+	// The only slot is taken, so the second run queues behind the first
+	var buf bytes.Buffer
+	console = &buf
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := acquireUpSlot(ctx, 1, "blog-dev"); err == nil {
+		t.Fatal("acquireUpSlot() took a held slot")
+	}
+	if !strings.Contains(buf.String(), "shop-main") {
+		t.Errorf("queued output does not name the holder:\n%s", buf.String())
+	}
+
+	first.release()
+	dir, err := upSlotsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holders := upSlotHolders(dir, 1); len(holders) != 0 {
+		t.Errorf("released slot still names %v", holders)
+	}
+	second, err := acquireUpSlot(context.Background(), 1, "blog-dev")
+	if err != nil || second == nil {
+		t.Fatalf("acquireUpSlot() after release = %v, %v", second, err)
+	}
+	second.release()
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/internal/statedir"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCreateDNSModeComposeKeepsSecrets(t *testing.T) {
+	workDir := t.TempDir()
+	file := filepath.Join(workDir, "docker-compose.yml")
+	if err := os.WriteFile(file, []byte(`services:
+  api:
+    image: api:1
+    ports: ["8080:80"]
+    secrets:
+      - source: db_password
+        target: db
+    configs: [app]
+secrets:
+  db_password:
+    file: ./db.txt
+configs:
+  app:
+    content: "debug: true"
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := compose.Load(workDir, []string{file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := createDNSModeCompose(workDir, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := compose.Load(workDir, []string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := rendered.Services()["api"]["ports"]; ok {
+		t.Error("ports not removed")
+	}
+	if refs := rendered.References(nil); len(refs) != 2 {
+		t.Errorf("References() = %+v, want db_password and app", refs)
+	}
+	if rendered.Secrets()["db_password"]["file"] != "./db.txt" || rendered.Configs()["app"]["content"] != "debug: true" {
+		data, _ := yaml.Marshal(rendered.Data)
+		t.Errorf("top-level secrets or configs lost:\n%s", data)
+	}
+}
+
+func TestSaveDNSStateCreatesStateDir(t *testing.T) {
+	t.Setenv(statedir.EnvVar, filepath.Join(t.TempDir(), "not", "there", "yet"))
+
+	if err := saveDNSState("127.0.0.1:5353", "", []string{"space.local"}); err != nil {
+		t.Fatalf("saveDNSState() error = %v", err)
+	}
+	state, err := loadDNSState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Address != "127.0.0.1:5353" {
+		t.Errorf("loaded address = %q", state.Address)
+	}
+}
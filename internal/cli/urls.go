@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"sort"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// dnsHostname returns the DNS name of a service, honoring network.dns_hashing
+func dnsHostname(cfg *config.Config, serviceName, workDir string) string {
+	if cfg != nil && !cfg.Network.DNSHashing {
+		label, _ := dns.NormalizeLabel(serviceName)
+		return label + "." + cfg.Network.BaseDomain()
+	}
+	if cfg != nil {
+		return generateDNSDomainIn(serviceName, workDir, cfg.Network.BaseDomain())
+	}
+	return generateDNSDomain(serviceName, workDir)
+}
+
+// serviceAddress returns the host and port used to reach a service from the host machine
+func serviceAddress(cfg *config.Config, serviceName, workDir string, useDNS bool) (string, int) {
+	svc := cfg.Services[serviceName]
+	if useDNS {
+		return dnsHostname(cfg, serviceName, workDir), svc.Port
+	}
+
+	port := svc.ExternalPort
+	if port == 0 {
+		port = svc.Port
+	}
+	return "localhost", port
+}
+
+// renderServiceURL renders a service URL for the given host and port using the
+// service's url_template. An empty projectName falls back to the configured name.
+func renderServiceURL(cfg *config.Config, serviceName, projectName, host string, port int) string {
+	var svc config.ServiceConfig
+	if cfg != nil {
+		svc = cfg.Services[serviceName]
+		if projectName == "" {
+			projectName = cfg.Project.Name
+		}
+	}
+
+	return svc.RenderURL(config.URLVars{
+		Host:         host,
+		Port:         port,
+		Service:      serviceName,
+		Project:      projectName,
+		InternalPort: svc.Port,
+		ExternalPort: svc.ExternalPort,
+	})
+}
+
+// serviceURL returns the rendered URL of a configured service
+func serviceURL(cfg *config.Config, serviceName, projectName, workDir string, useDNS bool) string {
+	host, port := serviceAddress(cfg, serviceName, workDir, useDNS)
+	return renderServiceURL(cfg, serviceName, projectName, host, port)
+}
+
+// sortedServiceNames returns the configured service names in stable order
+func sortedServiceNames(cfg *config.Config) []string {
+	names := make([]string, 0, len(cfg.Services))
+	for name := range cfg.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
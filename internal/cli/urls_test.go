@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestRenderServiceURL(t *testing.T) {
+	cfg := &config.Config{
+		Project: config.ProjectConfig{Name: "shop"},
+		Services: map[string]config.ServiceConfig{
+			"web":   {Port: 3000},
+			"api":   {Port: 8443, URLTemplate: "https://{host}:{port}/v1"},
+			"admin": {Port: 8080, ExternalPort: 18080, URLTemplate: "http://{host}:{external_port}/{project}/{service}"},
+			"proxy": {Port: 80, URLTemplate: "https://{host}/app?upstream={internal_port}"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		service     string
+		projectName string
+		host        string
+		port        int
+		want        string
+	}{
+		{"default template", "web", "shop-main", "localhost", 3000, "http://localhost:3000"},
+		{"https with path prefix", "api", "shop-main", "api-abc123.space.local", 8443, "https://api-abc123.space.local:8443/v1"},
+		{"external port and project", "admin", "shop-main", "localhost", 18080, "http://localhost:18080/shop-main/admin"},
+		{"project falls back to config", "admin", "", "localhost", 18080, "http://localhost:18080/shop/admin"},
+		{"port omitted from template", "proxy", "shop-main", "proxy-abc123.space.local", 80, "https://proxy-abc123.space.local/app?upstream=80"},
+		{"unknown service uses default", "worker", "shop-main", "localhost", 9000, "http://localhost:9000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderServiceURL(cfg, tt.service, tt.projectName, tt.host, tt.port)
+			if got != tt.want {
+				t.Errorf("renderServiceURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceAddress(t *testing.T) {
+	workDir := "/home/user/project"
+	cfg := &config.Config{
+		Network: config.NetworkConfig{DNSHashing: true},
+		Services: map[string]config.ServiceConfig{
+			"api": {Port: 6060, ExternalPort: 16060},
+			"db":  {Port: 5432},
+		},
+	}
+
+	host, port := serviceAddress(cfg, "api", workDir, true)
+	if host != generateDNSDomain("api", workDir) || port != 6060 {
+		t.Errorf("DNS mode address = %s:%d, want %s:6060", host, port, generateDNSDomain("api", workDir))
+	}
+
+	host, port = serviceAddress(cfg, "api", workDir, false)
+	if host != "localhost" || port != 16060 {
+		t.Errorf("port mode address = %s:%d, want localhost:16060", host, port)
+	}
+
+	_, port = serviceAddress(cfg, "db", workDir, false)
+	if port != 5432 {
+		t.Errorf("port mode without external port = %d, want 5432", port)
+	}
+
+	cfg.Network.DNSHashing = false
+	host, _ = serviceAddress(cfg, "api", workDir, true)
+	if host != "api.space.local" {
+		t.Errorf("unhashed DNS host = %s, want api.space.local", host)
+	}
+
+	// URLs use the first of several base domains
+	cfg.Network.CustomDomain = config.Domains{"shop.test", "space.local"}
+	host, _ = serviceAddress(cfg, "api", workDir, true)
+	if host != "api.shop.test" {
+		t.Errorf("custom domain host = %s, want api.shop.test", host)
+	}
+	cfg.Network.DNSHashing = true
+	host, _ = serviceAddress(cfg, "api", workDir, true)
+	if want := "api-" + generateDirectoryHash(workDir) + ".shop.test"; host != want {
+		t.Errorf("custom domain hashed host = %s, want %s", host, want)
+	}
+}
+
+func TestRenderServiceURLProtocols(t *testing.T) {
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"db":    {Port: 5432, Protocol: "tcp", Scheme: "postgres"},
+			"raw":   {Port: 9000, Protocol: "tcp"},
+			"rpc":   {Port: 50051, Protocol: "grpc"},
+			"cache": {Port: 6379, Protocol: "tcp", Scheme: "redis", URLTemplate: "redis://{host}:{port}/0"},
+		},
+	}
+
+	tests := []struct {
+		service string
+		want    string
+	}{
+		{"db", "postgres://localhost:5432"},
+		{"raw", "tcp://localhost:9000"},
+		{"rpc", "grpc://localhost:50051"},
+		{"cache", "redis://localhost:6379/0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.service, func(t *testing.T) {
+			_, port := serviceAddress(cfg, tt.service, "/tmp", false)
+			got := renderServiceURL(cfg, tt.service, "", "localhost", port)
+			if got != tt.want {
+				t.Errorf("renderServiceURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyComposeServicesDetectsProtocol(t *testing.T) {
+	project := &compose.Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"db":    map[string]interface{}{"image": "postgres:16-alpine", "ports": []interface{}{"5432"}},
+			"cache": map[string]interface{}{"image": "docker.io/library/redis:7", "expose": []interface{}{6379}},
+			"web":   map[string]interface{}{"build": ".", "ports": []interface{}{"3000:3000"}},
+		},
+	}}
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"web":   {Port: 3000},
+			"cache": {Port: 6379, Scheme: "rediss"},
+		},
+	}
+
+	applyComposeServices(cfg, project)
+
+	if got := cfg.Services["db"]; got.Protocol != "tcp" || got.Scheme != "postgres" {
+		t.Errorf("db = %+v, want tcp/postgres", got)
+	}
+	if got := cfg.Services["cache"]; got.Scheme != "rediss" {
+		t.Errorf("explicit scheme overwritten: %+v", got)
+	}
+	if got := cfg.Services["web"]; got.EffectiveProtocol() != "http" {
+		t.Errorf("web protocol = %s, want http", got.EffectiveProtocol())
+	}
+}
+
+func TestApplyComposeServicesDetectsDatabases(t *testing.T) {
+	newProject := func() *compose.Project {
+		return &compose.Project{Data: map[string]interface{}{
+			"services": map[string]interface{}{
+				"db": map[string]interface{}{
+					"image":       "postgres:16-alpine",
+					"environment": []interface{}{"POSTGRES_USER=app", "POSTGRES_PASSWORD=s3cret-pw", "POSTGRES_DB=shop"},
+				},
+				"cache": map[string]interface{}{"image": "redis:7"},
+				"web":   map[string]interface{}{"build": "."},
+			},
+		}}
+	}
+
+	cfg := &config.Config{Databases: []config.DatabaseConfig{{Name: "sessions", Service: "cache", Type: "redis"}}}
+	applyComposeServices(cfg, newProject())
+
+	if len(cfg.Databases) != 2 {
+		t.Fatalf("databases = %+v", cfg.Databases)
+	}
+	db, _ := cfg.DatabaseFor("db")
+	if db.Type != "postgres" || db.User != "app" || db.Password != "s3cret-pw" || db.Name != "shop" {
+		t.Errorf("detected db = %+v", db)
+	}
+	if cache, _ := cfg.DatabaseFor("cache"); cache.Name != "sessions" {
+		t.Errorf("configured database replaced: %+v", cache)
+	}
+
+	// Applying again does not register the database twice
+	applyComposeServices(cfg, newProject())
+	if len(cfg.Databases) != 2 {
+		t.Errorf("databases after second apply = %+v", cfg.Databases)
+	}
+
+	cfg = &config.Config{Project: config.ProjectConfig{IgnoreDatabaseImages: true}}
+	applyComposeServices(cfg, newProject())
+	if len(cfg.Databases) != 0 {
+		t.Errorf("detection not disabled: %+v", cfg.Databases)
+	}
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// hostUser returns the uid and gid of the user running space; a variable so
+// tests can set them
+var hostUser = func() (uid, gid int) {
+	return os.Getuid(), os.Getgid()
+}
+
+// mappedUserServices returns the services that run as the host user: those
+// with map_user set, and with project.map_user those bind-mounting host
+// paths without a user of their own
+func mappedUserServices(cfg *config.Config, project *compose.Project) []string {
+	if project == nil {
+		return nil
+	}
+	var names []string
+	for _, name := range project.ServiceNames() {
+		if cfg.Services[name].MapUser || cfg.Project.MapUser && project.User(name) == "" && len(project.BindMounts(name)) > 0 {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// bindMountFindings checks the bind mounts of the compose services for
+// ownership problems. Problems are host paths owned by root, which the host
+// user cannot edit; on Linux, services writing into bind mounts as the
+// image's user are warned about, since that is usually root.
+func bindMountFindings(cfg *config.Config, project *compose.Project, goos string) (problems, warnings []string) {
+	uid, gid := hostUser()
+	if project == nil || uid == 0 {
+		return nil, nil
+	}
+
+	mapped := make(map[string]bool)
+	for _, name := range mappedUserServices(cfg, project) {
+		mapped[name] = true
+	}
+
+	for _, name := range project.ServiceNames() {
+		mounts := project.BindMounts(name)
+		if len(mounts) == 0 {
+			continue
+		}
+		for _, path := range mounts {
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if st, ok := info.Sys().(*syscall.Stat_t); ok && st.Uid == 0 {
+				problems = append(problems, fmt.Sprintf("%s: %s is owned by root\n      sudo chown -R %d:%d %s", name, path, uid, gid, path))
+			}
+		}
+		if goos == "linux" && !mapped[name] && project.User(name) == "" {
+			warnings = append(warnings, fmt.Sprintf("%s writes into bind mounts as the image's user, often root; set project.map_user: true", name))
+		}
+	}
+	return problems, warnings
+}
+
+// hasBindMounts reports whether any service of project bind-mounts host paths
+func hasBindMounts(project *compose.Project) bool {
+	for _, name := range project.ServiceNames() {
+		if len(project.BindMounts(name)) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/internal/compose"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestEnvironmentOverrideMapsUser(t *testing.T) {
+	oldHostUser := hostUser
+	defer func() { hostUser = oldHostUser }()
+	hostUser = func() (int, int) { return 1000, 1001 }
+
+	cfg := &config.Config{
+		Project: config.ProjectConfig{MapUser: true},
+		Services: map[string]config.ServiceConfig{
+			"worker": {MapUser: true, Environment: map[string]string{"HOST_UID": "2000"}},
+		},
+	}
+	project := &compose.Project{ProjectDir: t.TempDir(), Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api":    map[string]interface{}{"volumes": []interface{}{"./src:/src"}},
+			"db":     map[string]interface{}{"volumes": []interface{}{"pgdata:/var/lib/postgresql/data"}},
+			"node":   map[string]interface{}{"user": "node", "volumes": []interface{}{"./web:/web"}},
+			"worker": map[string]interface{}{},
+		},
+	}}
+
+	if got, want := mappedUserServices(cfg, project), []string{"api", "worker"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("mappedUserServices() = %v, want %v", got, want)
+	}
+
+	services, _, err := environmentOverride(context.Background(), cfg, project, fakeSecrets{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := services["api"].(map[string]interface{})
+	if api["user"] != "1000:1001" {
+		t.Errorf("api user = %v", api["user"])
+	}
+	if env := api["environment"].(map[string]string); env["HOST_UID"] != "1000" || env["HOST_GID"] != "1001" {
+		t.Errorf("api environment = %v", env)
+	}
+	// The service's own variables win
+	if env := services["worker"].(map[string]interface{})["environment"].(map[string]string); env["HOST_UID"] != "2000" {
+		t.Errorf("worker environment = %v", env)
+	}
+	if _, ok := services["node"]; ok {
+		t.Error("node has a user of its own and must not be mapped")
+	}
+}
+
+func TestBindMountFindings(t *testing.T) {
+	oldHostUser := hostUser
+	defer func() { hostUser = oldHostUser }()
+	hostUser = func() (int, int) { return 1000, 1000 }
+
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	project := &compose.Project{ProjectDir: dir, Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api": map[string]interface{}{"volumes": []interface{}{"./data:/data"}},
+			"db":  map[string]interface{}{"volumes": []interface{}{"pgdata:/var/lib/postgresql/data"}},
+		},
+	}}
+
+	problems, warnings := bindMountFindings(&config.Config{}, project, "linux")
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "api ") {
+		t.Errorf("warnings = %q, want one for api", warnings)
+	}
+	// The test files are owned by root when the tests run as root
+	if os.Getuid() == 0 && (len(problems) != 1 || !strings.Contains(problems[0], "sudo chown -R 1000:1000 "+filepath.Join(dir, "data"))) {
+		t.Errorf("problems = %q, want the root-owned data directory", problems)
+	}
+
+	if _, warnings := bindMountFindings(&config.Config{Project: config.ProjectConfig{MapUser: true}}, project, "linux"); len(warnings) != 0 {
+		t.Errorf("warnings with map_user = %q", warnings)
+	}
+	if _, warnings := bindMountFindings(&config.Config{}, project, "darwin"); len(warnings) != 0 {
+		t.Errorf("warnings on macOS = %q", warnings)
+	}
+}
@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+// Tool check results
+const (
+	toolOK       = "ok"
+	toolOutdated = "outdated"
+	toolMissing  = "missing"
+	toolUnknown  = "unknown"
+)
+
+// toolRequirement is an external tool reported by 'space version --check'
+type toolRequirement struct {
+	name    string
+	command []string
+	minimum string
+
+	// optional tools are only checked when installed
+	optional bool
+}
+
+// toolRequirements lists the tools space drives and the oldest versions it
+// supports
+var toolRequirements = []toolRequirement{
+	{name: "docker", command: []string{"docker", "version", "--format", "{{.Server.Version}}"}, minimum: "20.10.0"},
+	{name: "docker compose", command: []string{"docker", "compose", "version", "--short"}, minimum: "2.20.0"},
+	{name: "orbstack", command: []string{"orbctl", "version"}, minimum: "1.0.0", optional: true},
+	{name: "lima", command: []string{"limactl", "--version"}, minimum: "0.18.0", optional: true},
+}
+
+// toolVersionOutput runs a version command; a variable so tests can stub it
+var toolVersionOutput = func(ctx context.Context, command []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerQueryTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, command[0], command[1:]...).Output()
+	if err != nil {
+		return "", timeoutError(ctx, command[0], dockerQueryTimeout, err)
+	}
+	return string(out), nil
+}
+
+// ToolStatus is the checked version of one external tool
+type ToolStatus struct {
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+	Minimum  string `json:"minimum"`
+	Required bool   `json:"required"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// VersionReport is the output of 'space version'
+type VersionReport struct {
+	BuildInfo
+	OS       string       `json:"os"`
+	Arch     string       `json:"arch"`
+	Provider string       `json:"provider,omitempty"`
+	Tools    []ToolStatus `json:"tools,omitempty"`
+}
+
+// newVersionCommand creates the version command
+func newVersionCommand() *cobra.Command {
+	var check bool
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show the version, and check the installed tools",
+		Long: `Show the space version with the commit, build time and Go version of the
+binary. With --check, also detect the provider and the
+versions of docker, docker compose, OrbStack and Lima, and report whether
+each meets the minimum supported version. OrbStack and Lima are only
+checked when installed.
+
+--check exits with an error when a required tool is missing or too old, so
+it can gate CI jobs; --json prints the report for bug reports and scripts.
+
+The commit and build time are injected by 'make build'; binaries built with
+go install fall back to what Go recorded from the checkout.`,
+		Example: `  space version
+  space version --check
+  space version --check --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			report := VersionReport{
+				BuildInfo: currentBuild(),
+				OS:        runtime.GOOS,
+				Arch:      runtime.GOARCH,
+			}
+			if check {
+				if detected, err := provider.NewDetector().Detect(ctx); err == nil {
+					report.Provider = detected.String()
+				}
+				report.Tools = checkTools(ctx, toolRequirements)
+			}
+
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(report); err != nil {
+					return err
+				}
+			} else {
+				printVersionReport(report)
+			}
+
+			if failed := failedTools(report.Tools); len(failed) > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("unsupported tools: %s", strings.Join(failed, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "Check the provider and tool versions against the supported minimums")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+
+	return cmd
+}
+
+// printVersionReport prints the report as text
+func printVersionReport(report VersionReport) {
+	fmt.Fprintf(console, "space %s (%s/%s, %s)\n", report.Version, report.OS, report.Arch, report.Go)
+	if report.Commit != "" {
+		fmt.Fprintf(console, "   Commit:  %s\n", report.Commit)
+	}
+	if report.BuildTime != "" {
+		fmt.Fprintf(console, "   Built:   %s\n", report.BuildTime)
+	}
+	if report.Tools == nil {
+		return
+	}
+
+	if report.Provider != "" {
+		fmt.Fprintf(console, "🔍 Provider: %s\n", provider.Provider(report.Provider).Description())
+	}
+	fmt.Fprintln(console)
+
+	w := newTable(console)
+	fmt.Fprintln(w, "TOOL\tVERSION\tMINIMUM\tSTATUS")
+	fmt.Fprintln(w, "----\t-------\t-------\t------")
+	for _, tool := range report.Tools {
+		version := tool.Version
+		if version == "" {
+			version = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", tool.Name, version, tool.Minimum, toolStatusLabel(tool))
+	}
+	_ = w.Flush()
+}
+
+// toolStatusLabel describes a check result for the text report
+func toolStatusLabel(tool ToolStatus) string {
+	switch tool.Status {
+	case toolOK:
+		return "✅ ok"
+	case toolOutdated:
+		return "❌ too old"
+	case toolMissing:
+		if !tool.Required {
+			return "➖ not installed"
+		}
+		return "❌ not found"
+	default:
+		return "⚠️  version not recognized"
+	}
+}
+
+// checkTools checks the installed version of each tool. Optional tools
+// that are not installed are reported as missing, but not required.
+func checkTools(ctx context.Context, requirements []toolRequirement) []ToolStatus {
+	statuses := make([]ToolStatus, 0, len(requirements))
+	for _, req := range requirements {
+		status := ToolStatus{Name: req.name, Minimum: req.minimum, Required: !req.optional}
+
+		out, err := toolVersionOutput(ctx, req.command)
+		switch version := parseVersion(out); {
+		case err != nil:
+			status.Status = toolMissing
+			status.Error = err.Error()
+		case version == "":
+			status.Status = toolUnknown
+		case versionAtLeast(version, req.minimum):
+			status.Version, status.Status = version, toolOK
+		default:
+			status.Version, status.Status = version, toolOutdated
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// failedTools returns the required tools that are missing or too old
+func failedTools(statuses []ToolStatus) []string {
+	var failed []string
+	for _, status := range statuses {
+		if status.Required && (status.Status == toolMissing || status.Status == toolOutdated) {
+			failed = append(failed, status.Name)
+		}
+	}
+	return failed
+}
+
+// versionPattern matches a dotted version number such as 2.24.6
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)+`)
+
+// parseVersion extracts the first dotted version number from command output,
+// e.g. "v2.24.6-desktop.1" gives 2.24.6
+func parseVersion(output string) string {
+	return versionPattern.FindString(output)
+}
+
+// versionAtLeast reports whether dotted version have is minimum or newer;
+// missing components count as 0
+func versionAtLeast(have, minimum string) bool {
+	a, b := strings.Split(have, "."), strings.Split(minimum, ".")
+	for i := 0; i < len(a) || i < len(b); i++ {
+		x, y := versionPart(a, i), versionPart(b, i)
+		if x != y {
+			return x > y
+		}
+	}
+	return true
+}
+
+// versionPart returns component i of a split version, or 0
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	n, _ := strconv.Atoi(parts[i])
+	return n
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		have, minimum string
+		want          bool
+	}{
+		{"2.24.6", "2.20.0", true},
+		{"2.20", "2.20.0", true},
+		{"2.9.1", "2.20.0", false},
+		{"20.10.24", "20.10.0", true},
+		{"1.0.0", "1.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := versionAtLeast(tt.have, tt.minimum); got != tt.want {
+			t.Errorf("versionAtLeast(%q, %q) = %v, want %v", tt.have, tt.minimum, got, tt.want)
+		}
+	}
+
+	if got := parseVersion("Docker Compose version v2.24.6-desktop.1\n"); got != "2.24.6" {
+		t.Errorf("parseVersion() = %q", got)
+	}
+}
+
+func TestCheckTools(t *testing.T) {
+	outputs := map[string]string{
+		"docker":  "19.03.8\n",
+		"orbctl":  "Version: 1.5.1 (1050100)\nCommit: abc\n",
+		"limactl": "limactl version\n",
+	}
+	orig := toolVersionOutput
+	defer func() { toolVersionOutput = orig }()
+	toolVersionOutput = func(_ context.Context, command []string) (string, error) {
+		if out, ok := outputs[command[0]]; ok {
+			return out, nil
+		}
+		return "", errors.New("executable file not found")
+	}
+
+	requirements := []toolRequirement{
+		{name: "docker", command: []string{"docker"}, minimum: "20.10.0"},
+		{name: "compose", command: []string{"compose"}, minimum: "2.20.0"},
+		{name: "orbstack", command: []string{"orbctl"}, minimum: "1.0.0", optional: true},
+		{name: "lima", command: []string{"limactl"}, minimum: "0.18.0", optional: true},
+		{name: "colima", command: []string{"colima"}, minimum: "0.6.0", optional: true},
+	}
+	want := []string{toolOutdated, toolMissing, toolOK, toolUnknown, toolMissing}
+
+	statuses := checkTools(context.Background(), requirements)
+	for i, status := range statuses {
+		if status.Status != want[i] {
+			t.Errorf("%s status = %s, want %s", status.Name, status.Status, want[i])
+		}
+	}
+	if statuses[2].Version != "1.5.1" {
+		t.Errorf("orbstack version = %q", statuses[2].Version)
+	}
+
+	failed := failedTools(statuses)
+	if len(failed) != 2 || failed[0] != "docker" || failed[1] != "compose" {
+		t.Errorf("failedTools() = %v, want only the required tools", failed)
+	}
+}
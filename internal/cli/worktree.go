@@ -0,0 +1,527 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// newWorktreeCommand creates the worktree command
+func newWorktreeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "worktree",
+		Short: "Manage git worktrees of the project",
+		Long: `Create git worktrees ready for 'space up'. Each worktree runs as its own
+project with its own hashed hostnames, so branches can run side by side.`,
+	}
+
+	cmd.AddCommand(newWorktreeAddCommand())
+	cmd.AddCommand(newWorktreeRemoveCommand())
+
+	return cmd
+}
+
+// newWorktreeAddCommand creates the worktree add command
+func newWorktreeAddCommand() *cobra.Command {
+	var (
+		path string
+		base string
+		pull bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <branch>",
+		Short: "Create a worktree for a branch and prepare it for space",
+		Long: `Run 'git worktree add' for the branch (creating it from --base or HEAD if
+it doesn't exist), copy the local files git doesn't track that the project
+needs (worktree.copy, default .env, .env.local and .env.*.local), write a
+.space.yaml if the worktree has none, and print the URLs its services will
+have. --pull also pulls the images of the new worktree.`,
+		Example: `  space worktree add feature/checkout
+  space worktree add fix-login --path ../shop-login --pull`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			branch := args[0]
+
+			workDir, err := filepath.Abs(Workdir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+			if resolved, err := filepath.EvalSymlinks(workDir); err == nil {
+				workDir = resolved
+			}
+
+			root, err := runGit(workDir, "rev-parse", "--show-toplevel")
+			if err != nil {
+				return fmt.Errorf("%s is not in a git repository: %w", workDir, err)
+			}
+			if path == "" {
+				path = defaultWorktreePath(root, branch)
+			}
+			if path, err = filepath.Abs(path); err != nil {
+				return fmt.Errorf("failed to resolve worktree path: %w", err)
+			}
+
+			loader, err := config.NewLoader(workDir)
+			if err != nil {
+				return fmt.Errorf("failed to create config loader: %w", err)
+			}
+			cfg, err := loader.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+
+			gitArgs := []string{"worktree", "add"}
+			if _, err := runGit(root, "show-ref", "--verify", "--quiet", "refs/heads/"+branch); err == nil {
+				gitArgs = append(gitArgs, path, branch)
+			} else {
+				gitArgs = append(gitArgs, "-b", branch, path)
+				if base != "" {
+					gitArgs = append(gitArgs, base)
+				}
+			}
+			fmt.Fprintf(console, "🌳 Running: git %s\n", strings.Join(gitArgs, " "))
+			if _, err := runGit(root, gitArgs...); err != nil {
+				return err
+			}
+
+			// The project may live in a subdirectory of the repository
+			rel, err := filepath.Rel(root, workDir)
+			if err != nil {
+				return err
+			}
+			projectDir := filepath.Join(path, rel)
+
+			copied, err := copyWorktreeFiles(workDir, projectDir, cfg.Worktree.CopyPatterns())
+			if err != nil {
+				return err
+			}
+			for _, file := range copied {
+				fmt.Fprintf(console, "📄 Copied %s\n", file)
+			}
+
+			if _, err := os.Stat(filepath.Join(projectDir, config.ConfigFileName)); err != nil {
+				if _, err := os.Stat(filepath.Join(projectDir, config.AlternateConfigFileName)); err != nil {
+					// Keep the project name, the branch tells worktrees apart
+					name := cfg.Project.Name
+					if name == "" {
+						name = filepath.Base(workDir)
+					}
+					if err := initSpaceConfig(projectDir, name, false, false, false); err != nil {
+						return err
+					}
+				}
+			}
+
+			return describeWorktree(projectDir, pull)
+		},
+	}
+
+	cmd.Flags().StringVar(&path, "path", "", "Directory of the worktree (default: <repo>-<branch> next to the repository)")
+	cmd.Flags().StringVar(&base, "base", "", "Start a new branch from this ref instead of HEAD")
+	cmd.Flags().BoolVar(&pull, "pull", false, "Pull the images of the new worktree")
+
+	return cmd
+}
+
+// newWorktreeRemoveCommand creates the worktree remove command
+func newWorktreeRemoveCommand() *cobra.Command {
+	var (
+		force       bool
+		keepVolumes bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "remove <branch or path>",
+		Aliases: []string{"rm"},
+		Short:   "Stop a worktree's project and remove the worktree",
+		Long: `Take down the containers, networks and volumes of the project in a
+worktree, drop its rendered secrets, hosts file entries and scheduled
+auto-shutdown, then run 'git worktree remove'. Removing a worktree with git
+directly leaves its stack running with nothing to stop it from.`,
+		Example: `  space worktree remove feature/checkout
+  space worktree remove ../shop-login --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workDir, err := filepath.Abs(Workdir)
+			if err != nil {
+				return fmt.Errorf("failed to resolve working directory: %w", err)
+			}
+			if resolved, err := filepath.EvalSymlinks(workDir); err == nil {
+				workDir = resolved
+			}
+
+			root, err := runGit(workDir, "rev-parse", "--show-toplevel")
+			if err != nil {
+				return fmt.Errorf("%s is not in a git repository: %w", workDir, err)
+			}
+			list, err := runGit(root, "worktree", "list", "--porcelain")
+			if err != nil {
+				return err
+			}
+			worktree, err := findWorktree(parseWorktreeList(list), args[0])
+			if err != nil {
+				return err
+			}
+
+			// The project lives at the same place in every worktree
+			rel, err := filepath.Rel(root, workDir)
+			if err != nil {
+				return err
+			}
+			projectDir := filepath.Join(worktree.Path, rel)
+
+			// Files space wrote or copied don't count as changes
+			if _, err := os.Stat(worktree.Path); err == nil && !force {
+				loader, err := config.NewLoader(workDir)
+				if err != nil {
+					return fmt.Errorf("failed to create config loader: %w", err)
+				}
+				source, err := loader.Load()
+				if err != nil {
+					return fmt.Errorf("failed to load configuration: %w", err)
+				}
+				name := source.Project.Name
+				if name == "" {
+					name = filepath.Base(workDir)
+				}
+
+				changes, err := worktreeChanges(root, worktree.Path, rel, name)
+				if err != nil {
+					return err
+				}
+				if len(changes) > 0 {
+					return fmt.Errorf("%s has changes not made by space: %s (use --force to remove anyway)", worktree.Path, strings.Join(changes, ", "))
+				}
+			}
+
+			if _, err := os.Stat(projectDir); err == nil {
+				cleanupWorktreeProject(context.Background(), projectDir, !keepVolumes)
+			}
+
+			// Untracked files left are the ones space wrote or copied
+			gitArgs := []string{"worktree", "remove", "--force"}
+			gitArgs = append(gitArgs, worktree.Path)
+			fmt.Fprintf(console, "🌳 Running: git %s\n", strings.Join(gitArgs, " "))
+			if _, err := runGit(root, gitArgs...); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(console, "✅ Removed worktree %s\n", worktree.Path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Remove the worktree even with uncommitted changes")
+	cmd.Flags().BoolVar(&keepVolumes, "keep-volumes", false, "Keep the project's volumes")
+
+	return cmd
+}
+
+// worktreeChanges lists the changed paths of a worktree, except untracked
+// files space generated or copied from the source repository: generated
+// compose files, .space/state, the .space.yaml written by add and files
+// identical to their source
+func worktreeChanges(sourceRoot, worktreeRoot, rel, configName string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "-z", "--untracked-files=all")
+	cmd.Dir = worktreeRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status: %w", timeoutError(ctx, "git", gitTimeout, err))
+	}
+
+	var changes []string
+	entries := strings.Split(string(out), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		status, path := entry[:2], filepath.FromSlash(entry[3:])
+		if status[0] == 'R' || status[0] == 'C' {
+			// The source path follows
+			i++
+		}
+		if status != "??" || !spaceOwnedFile(sourceRoot, worktreeRoot, rel, path, configName) {
+			changes = append(changes, filepath.ToSlash(path))
+		}
+	}
+	return changes, nil
+}
+
+// spaceOwnedFile reports whether an untracked worktree file was written by
+// space or copied from the source repository
+func spaceOwnedFile(sourceRoot, worktreeRoot, rel, path, configName string) bool {
+	data, err := os.ReadFile(filepath.Join(worktreeRoot, path))
+	if err != nil {
+		return false
+	}
+
+	if projectPath, err := filepath.Rel(rel, path); err == nil && !strings.HasPrefix(projectPath, "..") {
+		if strings.HasPrefix(projectPath, stateDir("")+string(filepath.Separator)) {
+			return true
+		}
+		for _, name := range generatedComposeFiles {
+			if projectPath == name {
+				return true
+			}
+		}
+		if projectPath == config.ConfigFileName && string(data) == spaceConfigContent(configName) {
+			return true
+		}
+	}
+
+	source, err := os.ReadFile(filepath.Join(sourceRoot, path))
+	return err == nil && bytes.Equal(data, source)
+}
+
+// gitWorktree is an entry of 'git worktree list --porcelain'
+type gitWorktree struct {
+	Path   string
+	Branch string
+}
+
+// parseWorktreeList parses 'git worktree list --porcelain' output; the main
+// worktree comes first
+func parseWorktreeList(out string) []gitWorktree {
+	var worktrees []gitWorktree
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			worktrees = append(worktrees, gitWorktree{Path: strings.TrimPrefix(line, "worktree ")})
+		case strings.HasPrefix(line, "branch ") && len(worktrees) > 0:
+			worktrees[len(worktrees)-1].Branch = strings.TrimPrefix(line, "branch refs/heads/")
+		}
+	}
+	return worktrees
+}
+
+// findWorktree returns the linked worktree with the given branch or path
+func findWorktree(worktrees []gitWorktree, target string) (gitWorktree, error) {
+	path, _ := filepath.Abs(target)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	for i, worktree := range worktrees {
+		if worktree.Branch != target && worktree.Path != path {
+			continue
+		}
+		if i == 0 {
+			return gitWorktree{}, fmt.Errorf("%s is the main worktree", worktree.Path)
+		}
+		return worktree, nil
+	}
+	return gitWorktree{}, fmt.Errorf("no worktree for %s", target)
+}
+
+// cleanupWorktreeProject takes down the project in projectDir and removes
+// the state space keeps for it outside the directory. Failures are reported
+// and skipped so the worktree can still be removed.
+func cleanupWorktreeProject(ctx context.Context, projectDir string, volumes bool) {
+	loader, err := config.NewLoader(projectDir)
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  Failed to create config loader: %v\n", err)
+		return
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		fmt.Fprintf(console, "⚠️  Failed to load configuration: %v\n", err)
+		return
+	}
+	projectName := generateProjectName(cfg, projectDir)
+	fmt.Fprintf(console, "📦 Project name: %s\n", projectName)
+
+	base := composeCommand(projectDir, cfg, withGeneratedServices(projectDir, composeFilesFor(projectDir, cfg)), projectName)
+	composeCmd := append(downCommands(base, nil, volumes)[0], "--remove-orphans")
+
+	fmt.Fprintf(console, "🔧 Running: %s\n", strings.Join(composeCmd, " "))
+	downCtx, cancel := context.WithTimeout(ctx, composeStopTimeout)
+	defer cancel()
+	dockerCmd := exec.CommandContext(downCtx, composeCmd[0], composeCmd[1:]...)
+	dockerCmd.Dir = projectDir
+	dockerCmd.Stdout = os.Stdout
+	dockerCmd.Stderr = os.Stderr
+	if err := step("docker compose down", true, dockerCmd.Run); err != nil {
+		fmt.Fprintf(console, "⚠️  Failed to stop services: %v\n", timeoutError(downCtx, "docker compose down", composeStopTimeout, err))
+	}
+
+	if volumes {
+		project, _ := loadComposeProject(projectDir, cfg)
+		dropSharedDatabases(ctx, cfg, project, projectDir, projectName)
+	}
+	releaseSharedNetworks(ctx, cfg)
+	if err := removeSecrets(projectName); err != nil {
+		fmt.Fprintf(console, "⚠️  Failed to remove rendered secrets: %v\n", err)
+	}
+	releaseProjectTTL(projectName)
+	releaseDNSRecords(projectName)
+	if hostsMode(cfg) {
+		if err := removeHostsFileBlock(ctx, projectName); err != nil {
+			fmt.Fprintf(console, "⚠️  Failed to update hosts file: %v\n", err)
+		}
+	}
+}
+
+// describeWorktree optionally pulls the images of a new worktree and prints
+// the URLs its services will have
+func describeWorktree(projectDir string, pull bool) error {
+	loader, err := config.NewLoader(projectDir)
+	if err != nil {
+		return fmt.Errorf("failed to create config loader: %w", err)
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	project, err := loadComposeProject(projectDir, cfg)
+	if err == nil {
+		applyComposeServices(cfg, project)
+	}
+
+	if pull && err == nil {
+		composeCmd := composeCommand(projectDir, cfg, composeFilesFor(projectDir, cfg), generateProjectName(cfg, projectDir))
+		composeCmd = append(composeCmd, "pull", "--ignore-buildable")
+
+		fmt.Fprintf(console, "🔧 Running: %s\n", strings.Join(composeCmd, " "))
+		dockerCmd := exec.Command(composeCmd[0], composeCmd[1:]...)
+		dockerCmd.Dir = projectDir
+		dockerCmd.Stdout = os.Stdout
+		dockerCmd.Stderr = os.Stderr
+		if err := step("docker compose pull", true, dockerCmd.Run); err != nil {
+			fmt.Fprintf(console, "⚠️  Failed to pull images: %v\n", err)
+		}
+	}
+
+	projectName := generateProjectName(cfg, projectDir)
+	fmt.Fprintln(console)
+	fmt.Fprintf(console, "✅ Worktree ready: %s\n", projectDir)
+	fmt.Fprintf(console, "📦 Project name: %s\n", projectName)
+
+	if links := collectServiceLinks(cfg, projectName, projectDir, true); len(links) > 0 {
+		fmt.Fprintln(console, "🌍 Its services will be reachable at:")
+		for _, link := range links {
+			fmt.Fprintf(console, "   • %s: %s\n", link.Service, link.URL)
+		}
+	}
+
+	fmt.Fprintln(console)
+	tipf("Run 'cd %s && space up' to start it", projectDir)
+	return nil
+}
+
+// defaultWorktreePath places a worktree next to the repository, named after
+// the repository and the branch
+func defaultWorktreePath(root, branch string) string {
+	name := strings.NewReplacer("/", "-", "\\", "-", " ", "-").Replace(branch)
+	return filepath.Join(filepath.Dir(root), filepath.Base(root)+"-"+name)
+}
+
+// runGit runs git in dir and returns its trimmed output
+func runGit(dir string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), gitTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", timeoutError(ctx, "git "+args[0], gitTimeout, err)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// copyWorktreeFiles copies the files and directories matching the patterns
+// from src into dst, never overwriting files dst already has. A config file
+// git doesn't track is copied too. It returns the copied paths relative to
+// src.
+func copyWorktreeFiles(src, dst string, patterns []string) ([]string, error) {
+	patterns = append([]string{config.ConfigFileName, config.AlternateConfigFileName}, patterns...)
+
+	var copied []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(src, pattern))
+		if err != nil {
+			return copied, fmt.Errorf("invalid worktree.copy pattern %q: %w", pattern, err)
+		}
+
+		for _, match := range matches {
+			err := filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil || d.IsDir() || seen[path] {
+					return err
+				}
+				seen[path] = true
+
+				rel, err := filepath.Rel(src, path)
+				if err != nil {
+					return err
+				}
+				target := filepath.Join(dst, rel)
+				if _, err := os.Lstat(target); err == nil {
+					return nil
+				}
+				if err := copyFile(path, target); err != nil {
+					return fmt.Errorf("failed to copy %s: %w", rel, err)
+				}
+				copied = append(copied, rel)
+				return nil
+			})
+			if err != nil {
+				return copied, err
+			}
+		}
+	}
+	return copied, nil
+}
+
+// copyFile copies a regular file, keeping its permissions
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultWorktreePath(t *testing.T) {
+	if got := defaultWorktreePath("/src/shop", "feature/checkout"); got != "/src/shop-feature-checkout" {
+		t.Errorf("defaultWorktreePath() = %s", got)
+	}
+}
+
+func TestCopyWorktreeFiles(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	for name, content := range map[string]string{
+		".space.yaml":            "project:\n  name: shop\n",
+		".env.development.local": "SECRET=1\n",
+		".env":                   "A=1\n",
+		"certs/dev.pem":          "pem",
+		"README.md":              "tracked",
+	} {
+		path := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Files the worktree already has are kept
+	if err := os.WriteFile(filepath.Join(dst, ".env"), []byte("A=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	copied, err := copyWorktreeFiles(src, dst, []string{".env", ".env.*.local", "certs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".space.yaml", ".env.development.local", filepath.Join("certs", "dev.pem")}
+	if !reflect.DeepEqual(copied, want) {
+		t.Errorf("copied = %v, want %v", copied, want)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dst, ".env")); string(data) != "A=2\n" {
+		t.Errorf(".env overwritten: %s", data)
+	}
+	info, err := os.Stat(filepath.Join(dst, ".env.development.local"))
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("copied file mode = %v, %v", info, err)
+	}
+}
+
+func TestWorktreeAdd(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	parent := t.TempDir()
+	repo := filepath.Join(parent, "shop")
+	if err := os.MkdirAll(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"docker-compose.yml": "services:\n  web:\n    image: nginx\n    ports: [\"80\"]\n",
+		".gitignore":         ".env.local\n",
+		".env.local":         "TOKEN=dev\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"add", "docker-compose.yml", ".gitignore"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldWorkdir := Workdir
+	defer func() { Workdir = oldWorkdir }()
+	Workdir = repo
+
+	cmd := newWorktreeCommand()
+	cmd.SetArgs([]string{"add", "feature/login"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	worktree := filepath.Join(parent, "shop-feature-login")
+	if branch := getGitBranch(worktree); branch != "feature/login" {
+		t.Errorf("worktree branch = %q", branch)
+	}
+	if data, err := os.ReadFile(filepath.Join(worktree, ".env.local")); err != nil || string(data) != "TOKEN=dev\n" {
+		t.Errorf(".env.local = %q, %v", data, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(worktree, ".space.yaml")); err != nil || !strings.Contains(string(data), "name: shop\n") {
+		t.Errorf(".space.yaml = %q, %v; want the source project name", data, err)
+	}
+
+	// The main worktree is never removed
+	remove := newWorktreeCommand()
+	remove.SetArgs([]string{"remove", "main"})
+	if err := remove.Execute(); err == nil || !strings.Contains(err.Error(), "main worktree") {
+		t.Errorf("expected removing the main worktree to fail, got %v", err)
+	}
+
+	// Files space didn't write block the removal
+	notes := filepath.Join(worktree, "notes.txt")
+	if err := os.WriteFile(notes, []byte("todo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	remove = newWorktreeCommand()
+	remove.SetArgs([]string{"remove", "feature/login"})
+	if err := remove.Execute(); err == nil || !strings.Contains(err.Error(), "notes.txt") {
+		t.Errorf("expected untracked notes.txt to block removal, got %v", err)
+	}
+	if err := os.Remove(notes); err != nil {
+		t.Fatal(err)
+	}
+
+	remove = newWorktreeCommand()
+	remove.SetArgs([]string{"remove", "feature/login"})
+	if err := remove.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(worktree); !os.IsNotExist(err) {
+		t.Errorf("worktree still exists: %v", err)
+	}
+
+	// The branch is kept, so a new worktree checks it out
+	again := newWorktreeCommand()
+	again.SetArgs([]string{"add", "feature/login", "--path", filepath.Join(parent, "other")})
+	if err := again.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if branch := getGitBranch(filepath.Join(parent, "other")); branch != "feature/login" {
+		t.Errorf("re-added worktree branch = %q", branch)
+	}
+}
+
+func TestParseWorktreeList(t *testing.T) {
+	out := "worktree /src/shop\nHEAD abc\nbranch refs/heads/main\n\nworktree /src/shop-fix\nHEAD def\nbranch refs/heads/fix/login\n\nworktree /src/shop-detached\nHEAD 123\ndetached\n"
+	want := []gitWorktree{
+		{Path: "/src/shop", Branch: "main"},
+		{Path: "/src/shop-fix", Branch: "fix/login"},
+		{Path: "/src/shop-detached"},
+	}
+	worktrees := parseWorktreeList(out)
+	if !reflect.DeepEqual(worktrees, want) {
+		t.Fatalf("parseWorktreeList() = %+v", worktrees)
+	}
+
+	if got, err := findWorktree(worktrees, "fix/login"); err != nil || got.Path != "/src/shop-fix" {
+		t.Errorf("findWorktree(fix/login) = %+v, %v", got, err)
+	}
+	if got, err := findWorktree(worktrees, "/src/shop-detached"); err != nil || got.Path != "/src/shop-detached" {
+		t.Errorf("findWorktree(path) = %+v, %v", got, err)
+	}
+	if _, err := findWorktree(worktrees, "missing"); err == nil {
+		t.Error("expected unknown worktree to fail")
+	}
+}
@@ -0,0 +1,486 @@
+// Package compose provides a merged, read-mostly view of docker compose files
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Project is the merged model of one or more docker compose files
+type Project struct {
+	// WorkDir is the directory relative compose file paths are resolved against
+	WorkDir string
+
+	// ProjectDir is the directory relative paths inside the files resolve
+	// against, like docker compose --project-directory
+	ProjectDir string
+
+	// Files is the ordered list of compose files that were merged
+	Files []string
+
+	// Data is the merged compose document
+	Data map[string]interface{}
+}
+
+// OverrideFileNames returns the candidate override file names for a compose file.
+// Compose implicitly merges "<name>.override.<ext>" next to its default files,
+// e.g. docker-compose.yml -> docker-compose.override.yml / docker-compose.override.yaml
+func OverrideFileNames(file string) []string {
+	ext := filepath.Ext(file)
+	base := strings.TrimSuffix(file, ext)
+	if strings.HasSuffix(base, ".override") {
+		return nil
+	}
+	return []string{base + ".override.yml", base + ".override.yaml"}
+}
+
+// defaultFileNames are the compose files plain `docker compose` looks for
+// in the project directory when invoked without -f flags
+var defaultFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// isDefaultFile reports whether file is one of defaultFileNames at the root
+// of the work directory, the only files compose merges an override for
+func isDefaultFile(workDir, file string) bool {
+	path := absPath(workDir, file)
+	return filepath.Dir(path) == filepath.Clean(workDir) && slices.Contains(defaultFileNames, filepath.Base(path))
+}
+
+// ResolveFiles returns the compose files to use for a project.
+// When withOverrides is true, the existing override of each default compose
+// file is inserted directly after it, mirroring what plain `docker compose`
+// does when invoked without -f flags. Other files get none, as with -f.
+func ResolveFiles(workDir string, files []string, withOverrides bool) []string {
+	if len(files) == 0 {
+		files = []string{"docker-compose.yml"}
+	}
+
+	listed := make(map[string]bool, len(files))
+	for _, file := range files {
+		listed[filepath.Clean(file)] = true
+	}
+
+	resolved := make([]string, 0, len(files))
+	for _, file := range files {
+		resolved = append(resolved, file)
+		if !withOverrides || !isDefaultFile(workDir, file) {
+			continue
+		}
+		for _, override := range OverrideFileNames(file) {
+			if listed[filepath.Clean(override)] {
+				continue
+			}
+			if _, err := os.Stat(absPath(workDir, override)); err == nil {
+				resolved = append(resolved, override)
+				listed[filepath.Clean(override)] = true
+				break
+			}
+		}
+	}
+
+	return resolved
+}
+
+// DefaultProjectDir returns the project directory docker compose uses when
+// none is given: the directory of the first compose file
+func DefaultProjectDir(workDir string, files []string) string {
+	if len(files) == 0 {
+		return workDir
+	}
+	return filepath.Dir(absPath(workDir, files[0]))
+}
+
+// Load reads and merges the given compose files in order (later files win).
+// The project directory defaults to that of the first file.
+func Load(workDir string, files []string) (*Project, error) {
+	p := &Project{
+		WorkDir:    workDir,
+		ProjectDir: DefaultProjectDir(workDir, files),
+		Files:      files,
+		Data:       make(map[string]interface{}),
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(absPath(workDir, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+
+		mergeMaps(p.Data, doc)
+	}
+
+	return p, nil
+}
+
+// Merge applies an override document on top of the project, as if it were
+// passed as one more -f file. The document is normalized through YAML so
+// typed maps and slices merge like parsed ones.
+func (p *Project) Merge(override map[string]interface{}) error {
+	data, err := yaml.Marshal(override)
+	if err != nil {
+		return fmt.Errorf("failed to marshal override: %w", err)
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse override: %w", err)
+	}
+
+	mergeMaps(p.Data, doc)
+	return nil
+}
+
+// Services returns the merged services section keyed by service name
+func (p *Project) Services() map[string]map[string]interface{} {
+	services := make(map[string]map[string]interface{})
+	raw, ok := p.Data["services"].(map[string]interface{})
+	if !ok {
+		return services
+	}
+	for name, def := range raw {
+		if svc, ok := def.(map[string]interface{}); ok {
+			services[name] = svc
+		} else {
+			services[name] = map[string]interface{}{}
+		}
+	}
+	return services
+}
+
+// ServiceNames returns the sorted list of service names
+func (p *Project) ServiceNames() []string {
+	services := p.Services()
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Image returns the image of a service, or "" if it is built locally
+func (p *Project) Image(service string) string {
+	svc, ok := p.Services()[service]
+	if !ok {
+		return ""
+	}
+	image, _ := svc["image"].(string)
+	return image
+}
+
+// HasHealthcheck reports whether a service defines a healthcheck section
+// (including one that disables the image's healthcheck)
+func (p *Project) HasHealthcheck(service string) bool {
+	svc, ok := p.Services()[service]
+	if !ok {
+		return false
+	}
+	_, ok = svc["healthcheck"]
+	return ok
+}
+
+// DependsOn returns the sorted services a service depends on (list and
+// mapping syntax)
+func (p *Project) DependsOn(service string) []string {
+	svc, ok := p.Services()[service]
+	if !ok {
+		return nil
+	}
+
+	var deps []string
+	switch d := svc["depends_on"].(type) {
+	case []interface{}:
+		for _, name := range d {
+			deps = append(deps, fmt.Sprint(name))
+		}
+	case map[string]interface{}:
+		for name := range d {
+			deps = append(deps, name)
+		}
+	}
+	sort.Strings(deps)
+	return deps
+}
+
+// WithDependencies returns services followed by everything they depend on,
+// transitively, each once
+func (p *Project) WithDependencies(services []string) []string {
+	seen := make(map[string]bool, len(services))
+	out := make([]string, 0, len(services))
+	for _, name := range services {
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+
+	for i := 0; i < len(out); i++ {
+		for _, dep := range p.DependsOn(out[i]) {
+			if !seen[dep] {
+				seen[dep] = true
+				out = append(out, dep)
+			}
+		}
+	}
+	return out
+}
+
+// Environment returns the environment of a service as a mapping
+func (p *Project) Environment(service string) map[string]interface{} {
+	svc, ok := p.Services()[service]
+	if !ok {
+		return nil
+	}
+	return toMapping(svc["environment"])
+}
+
+// SetEnvironment sets an environment variable on a service, normalizing the
+// environment section to a mapping
+func (p *Project) SetEnvironment(service, key, value string) {
+	raw, ok := p.Data["services"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	def, ok := raw[service]
+	if !ok {
+		return
+	}
+	svc, ok := def.(map[string]interface{})
+	if !ok {
+		// "service:" with an empty body
+		svc = make(map[string]interface{})
+		raw[service] = svc
+	}
+	env := toMapping(svc["environment"])
+	env[key] = value
+	svc["environment"] = env
+}
+
+// PortMapping is a single entry of a service's ports section
+type PortMapping struct {
+	Published int
+	Target    int
+	Protocol  string
+}
+
+// Ports returns the parsed port mappings of a service (short and long syntax)
+func (p *Project) Ports(service string) []PortMapping {
+	svc, ok := p.Services()[service]
+	if !ok {
+		return nil
+	}
+	return ParsePorts(svc["ports"])
+}
+
+// ContainerPorts returns the container-side ports of a service from
+// its ports and expose sections
+func (p *Project) ContainerPorts(service string) []int {
+	svc, ok := p.Services()[service]
+	if !ok {
+		return nil
+	}
+
+	seen := make(map[int]bool)
+	var ports []int
+	for _, m := range ParsePorts(svc["ports"]) {
+		if m.Target > 0 && !seen[m.Target] {
+			seen[m.Target] = true
+			ports = append(ports, m.Target)
+		}
+	}
+	if expose, ok := svc["expose"].([]interface{}); ok {
+		for _, e := range expose {
+			port, _ := parsePortNumber(fmt.Sprint(e))
+			if port > 0 && !seen[port] {
+				seen[port] = true
+				ports = append(ports, port)
+			}
+		}
+	}
+	return ports
+}
+
+// BindMounts returns the host paths a service bind-mounts, resolved against
+// the project directory
+func (p *Project) BindMounts(service string) []string {
+	volumes, _ := p.Services()[service]["volumes"].([]interface{})
+	var paths []string
+	for _, v := range volumes {
+		if source := bindSource(v); source != "" {
+			paths = append(paths, p.hostPath(source))
+		}
+	}
+	return paths
+}
+
+// User returns the user a service runs as, or "" for the image's user
+func (p *Project) User(service string) string {
+	user, _ := p.Services()[service]["user"].(string)
+	return user
+}
+
+// ParsePorts parses a compose ports section
+func ParsePorts(raw interface{}) []PortMapping {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var mappings []PortMapping
+	for _, entry := range list {
+		switch v := entry.(type) {
+		case map[string]interface{}:
+			m := PortMapping{Protocol: "tcp"}
+			m.Target, _ = parsePortNumber(fmt.Sprint(v["target"]))
+			if published, ok := v["published"]; ok {
+				m.Published, _ = parsePortNumber(fmt.Sprint(published))
+			}
+			if proto, ok := v["protocol"].(string); ok && proto != "" {
+				m.Protocol = proto
+			}
+			mappings = append(mappings, m)
+		default:
+			mappings = append(mappings, parseShortPort(fmt.Sprint(v)))
+		}
+	}
+	return mappings
+}
+
+// parseShortPort parses "[ip:][published:]target[/proto]"
+func parseShortPort(spec string) PortMapping {
+	m := PortMapping{Protocol: "tcp"}
+	if idx := strings.LastIndex(spec, "/"); idx != -1 {
+		m.Protocol = spec[idx+1:]
+		spec = spec[:idx]
+	}
+
+	parts := strings.Split(spec, ":")
+	m.Target, _ = parsePortNumber(parts[len(parts)-1])
+	if len(parts) > 1 {
+		m.Published, _ = parsePortNumber(parts[len(parts)-2])
+	}
+	return m
+}
+
+// parsePortNumber parses a port or the first port of a range ("8000-8010")
+func parsePortNumber(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if idx := strings.Index(s, "-"); idx != -1 {
+		s = s[:idx]
+	}
+	return strconv.Atoi(s)
+}
+
+// sequenceKeys are service keys whose sequences are concatenated on merge
+// (compose appends these instead of replacing them)
+var sequenceKeys = map[string]bool{
+	"ports":          true,
+	"expose":         true,
+	"volumes":        true,
+	"dns":            true,
+	"dns_search":     true,
+	"external_links": true,
+	"devices":        true,
+	"tmpfs":          true,
+	"extra_hosts":    true,
+}
+
+// mappingKeys are keys that may be written as either a list or a mapping
+var mappingKeys = map[string]bool{
+	"environment": true,
+	"labels":      true,
+}
+
+// mergeMaps deep-merges src into dst using compose override semantics
+func mergeMaps(dst, src map[string]interface{}) {
+	for key, srcVal := range src {
+		dstVal, exists := dst[key]
+		if !exists {
+			dst[key] = srcVal
+			continue
+		}
+
+		if mappingKeys[key] {
+			merged := toMapping(dstVal)
+			for k, v := range toMapping(srcVal) {
+				merged[k] = v
+			}
+			dst[key] = merged
+			continue
+		}
+
+		switch s := srcVal.(type) {
+		case map[string]interface{}:
+			if d, ok := dstVal.(map[string]interface{}); ok {
+				mergeMaps(d, s)
+				continue
+			}
+		case []interface{}:
+			if d, ok := dstVal.([]interface{}); ok && sourceKeys[key] != "" {
+				dst[key] = mergeBySource(d, s)
+				continue
+			}
+			if d, ok := dstVal.([]interface{}); ok && sequenceKeys[key] {
+				dst[key] = appendUnique(d, s)
+				continue
+			}
+		}
+
+		dst[key] = srcVal
+	}
+}
+
+// toMapping converts a KEY=VALUE list or a mapping into a mapping
+func toMapping(v interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			result[k] = val
+		}
+	case []interface{}:
+		for _, item := range t {
+			parts := strings.SplitN(fmt.Sprint(item), "=", 2)
+			if len(parts) == 2 {
+				result[parts[0]] = parts[1]
+			} else {
+				result[parts[0]] = nil
+			}
+		}
+	}
+	return result
+}
+
+// appendUnique appends entries of src not already present in dst
+func appendUnique(dst, src []interface{}) []interface{} {
+	seen := make(map[string]bool, len(dst))
+	for _, v := range dst {
+		seen[fmt.Sprint(v)] = true
+	}
+	for _, v := range src {
+		if !seen[fmt.Sprint(v)] {
+			seen[fmt.Sprint(v)] = true
+			dst = append(dst, v)
+		}
+	}
+	return dst
+}
+
+// absPath resolves a compose file path against the work directory
+func absPath(workDir, file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(workDir, file)
+}
@@ -0,0 +1,225 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+func TestResolveFiles(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      []string
+		files         []string
+		withOverrides bool
+		want          []string
+	}{
+		{
+			name:          "no override present",
+			existing:      []string{"docker-compose.yml"},
+			files:         []string{"docker-compose.yml"},
+			withOverrides: true,
+			want:          []string{"docker-compose.yml"},
+		},
+		{
+			name:          "yml override detected",
+			existing:      []string{"docker-compose.yml", "docker-compose.override.yml"},
+			files:         []string{"docker-compose.yml"},
+			withOverrides: true,
+			want:          []string{"docker-compose.yml", "docker-compose.override.yml"},
+		},
+		{
+			name:          "yaml override detected",
+			existing:      []string{"compose.yaml", "compose.override.yaml"},
+			files:         []string{"compose.yaml"},
+			withOverrides: true,
+			want:          []string{"compose.yaml", "compose.override.yaml"},
+		},
+		{
+			name:          "override ignored",
+			existing:      []string{"docker-compose.yml", "docker-compose.override.yml"},
+			files:         []string{"docker-compose.yml"},
+			withOverrides: false,
+			want:          []string{"docker-compose.yml"},
+		},
+		{
+			name:          "override already listed",
+			existing:      []string{"docker-compose.yml", "docker-compose.override.yml"},
+			files:         []string{"docker-compose.yml", "docker-compose.override.yml"},
+			withOverrides: true,
+			want:          []string{"docker-compose.yml", "docker-compose.override.yml"},
+		},
+		{
+			name:          "explicit file gets no override",
+			existing:      []string{"compose.dev.yml", "compose.dev.override.yml"},
+			files:         []string{"compose.dev.yml"},
+			withOverrides: true,
+			want:          []string{"compose.dev.yml"},
+		},
+		{
+			name:          "default name outside the work directory",
+			existing:      []string{"deploy/docker-compose.yml", "deploy/docker-compose.override.yml"},
+			files:         []string{"deploy/docker-compose.yml"},
+			withOverrides: true,
+			want:          []string{"deploy/docker-compose.yml"},
+		},
+		{
+			name:          "empty list defaults",
+			existing:      []string{"docker-compose.yml", "docker-compose.override.yml"},
+			files:         nil,
+			withOverrides: true,
+			want:          []string{"docker-compose.yml", "docker-compose.override.yml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.existing {
+				if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755); err != nil {
+					t.Fatal(err)
+				}
+				writeFile(t, dir, name, "services: {}\n")
+			}
+
+			got := ResolveFiles(dir, tt.files, tt.withOverrides)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveFiles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMergesOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "docker-compose.yml", `
+services:
+  api:
+    image: api:latest
+    ports:
+      - "6060:6060"
+    environment:
+      - MODE=base
+      - KEEP=1
+  db:
+    image: postgres:16
+`)
+	writeFile(t, dir, "docker-compose.override.yml", `
+services:
+  api:
+    image: api:dev
+    ports:
+      - "9229:9229"
+    environment:
+      MODE: dev
+  web:
+    image: nginx
+    expose:
+      - "80"
+`)
+
+	project, err := Load(dir, []string{"docker-compose.yml", "docker-compose.override.yml"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	wantNames := []string{"api", "db", "web"}
+	if got := project.ServiceNames(); !reflect.DeepEqual(got, wantNames) {
+		t.Errorf("ServiceNames() = %v, want %v", got, wantNames)
+	}
+
+	api := project.Services()["api"]
+	if api["image"] != "api:dev" {
+		t.Errorf("image = %v, want api:dev", api["image"])
+	}
+
+	if got := project.ContainerPorts("api"); !reflect.DeepEqual(got, []int{6060, 9229}) {
+		t.Errorf("ContainerPorts(api) = %v, want [6060 9229]", got)
+	}
+
+	env, ok := api["environment"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("environment not merged into mapping: %T", api["environment"])
+	}
+	if env["MODE"] != "dev" || env["KEEP"] != "1" {
+		t.Errorf("environment = %v, want MODE=dev KEEP=1", env)
+	}
+
+	if got := project.ContainerPorts("web"); !reflect.DeepEqual(got, []int{80}) {
+		t.Errorf("ContainerPorts(web) = %v, want [80]", got)
+	}
+}
+
+func TestParsePorts(t *testing.T) {
+	raw := []interface{}{
+		"8080:80",
+		"127.0.0.1:5432:5432/tcp",
+		"9000",
+		6379,
+		"53:53/udp",
+		map[string]interface{}{"target": 443, "published": "8443"},
+	}
+
+	want := []PortMapping{
+		{Published: 8080, Target: 80, Protocol: "tcp"},
+		{Published: 5432, Target: 5432, Protocol: "tcp"},
+		{Target: 9000, Protocol: "tcp"},
+		{Target: 6379, Protocol: "tcp"},
+		{Published: 53, Target: 53, Protocol: "udp"},
+		{Published: 8443, Target: 443, Protocol: "tcp"},
+	}
+
+	if got := ParsePorts(raw); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePorts() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWithDependencies(t *testing.T) {
+	p := &Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"web":      map[string]interface{}{"depends_on": []interface{}{"api"}},
+			"api":      map[string]interface{}{"depends_on": map[string]interface{}{"postgres": map[string]interface{}{"condition": "service_healthy"}, "redis": nil}},
+			"worker":   map[string]interface{}{"depends_on": []interface{}{"redis"}},
+			"postgres": map[string]interface{}{},
+			"redis":    map[string]interface{}{},
+		},
+	}}
+
+	got := p.WithDependencies([]string{"web", "worker"})
+	want := []string{"web", "worker", "api", "redis", "postgres"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WithDependencies() = %v, want %v", got, want)
+	}
+}
+
+func TestBindMounts(t *testing.T) {
+	p := &Project{ProjectDir: "/src/shop", Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"web": map[string]interface{}{
+				"user": "1000:1000",
+				"volumes": []interface{}{
+					"./app:/app",
+					"node_modules:/app/node_modules",
+					map[string]interface{}{"type": "bind", "source": "/var/log/shop", "target": "/logs"},
+					map[string]interface{}{"type": "volume", "source": "cache", "target": "/cache"},
+				},
+			},
+		},
+	}}
+
+	want := []string{"/src/shop/app", "/var/log/shop"}
+	if got := p.BindMounts("web"); !reflect.DeepEqual(got, want) {
+		t.Errorf("BindMounts() = %v, want %v", got, want)
+	}
+	if got := p.User("web"); got != "1000:1000" {
+		t.Errorf("User() = %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package compose
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AddServiceStub adds a minimal service to the YAML of a compose file,
+// keeping its comments: the image, or a build of the project directory
+// without one, and the port if set. It fails if the service exists.
+func AddServiceStub(data []byte, name, image string, port int) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse compose file: %w", err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode}
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("compose file is not a mapping")
+	}
+
+	var services *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "services" {
+			services = root.Content[i+1]
+			if services.Kind != yaml.MappingNode {
+				services = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				root.Content[i+1] = services
+			}
+		}
+	}
+	if services == nil {
+		services = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		root.Content = append(root.Content, scalar("services"), services)
+	}
+	for i := 0; i < len(services.Content); i += 2 {
+		if services.Content[i].Value == name {
+			return nil, fmt.Errorf("compose already defines service %s", name)
+		}
+	}
+
+	stub := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if image != "" {
+		stub.Content = append(stub.Content, scalar("image"), scalar(image))
+	} else {
+		stub.Content = append(stub.Content, scalar("build"), scalar("."))
+	}
+	if port > 0 {
+		ports := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+		ports.Content = append(ports.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: strconv.Itoa(port), Style: yaml.DoubleQuotedStyle})
+		stub.Content = append(stub.Content, scalar("ports"), ports)
+	}
+	services.Content = append(services.Content, scalar(name), stub)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode compose file: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scalar returns a plain string node
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
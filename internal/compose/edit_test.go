@@ -0,0 +1,31 @@
+package compose
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAddServiceStub(t *testing.T) {
+	input := "# Local stack\nservices:\n  db:\n    image: postgres:16 # pinned\n"
+
+	out, err := AddServiceStub([]byte(input), "api", "", 6060)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Local stack\nservices:\n  db:\n    image: postgres:16 # pinned\n  api:\n    build: .\n    ports: [\"6060\"]\n"
+	if string(out) != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out, want)
+	}
+
+	out, err = AddServiceStub(nil, "cache", "redis:7", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "services:\n  cache:\n    image: redis:7\n" {
+		t.Errorf("output:\n%s", out)
+	}
+
+	if _, err := AddServiceStub([]byte(input), "db", "", 0); err == nil || !strings.Contains(err.Error(), "db") {
+		t.Errorf("expected existing service to fail, got %v", err)
+	}
+}
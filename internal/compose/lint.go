@@ -0,0 +1,124 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Lint rules
+const (
+	RuleNoHealthcheck = "no-healthcheck"
+	RuleLatestTag     = "latest-tag"
+	RuleWritableMount = "world-writable-mount"
+)
+
+// Finding is a lint warning about a service
+type Finding struct {
+	Service string `json:"service"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// String formats the finding for terminal output
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Service, f.Message, f.Rule)
+}
+
+// Lint checks the services of the project for common mistakes: no
+// healthcheck, images without a pinned tag and bind mounts of world-writable
+// host paths. imageHealthcheck reports whether an image declares a
+// HEALTHCHECK itself; it may be nil.
+func (p *Project) Lint(imageHealthcheck func(image string) bool) []Finding {
+	var findings []Finding
+	services := p.Services()
+
+	for _, name := range p.ServiceNames() {
+		svc := services[name]
+		image := p.Image(name)
+
+		if !p.HasHealthcheck(name) && (image == "" || imageHealthcheck == nil || !imageHealthcheck(image)) {
+			findings = append(findings, Finding{
+				Service: name,
+				Rule:    RuleNoHealthcheck,
+				Message: "no healthcheck, so depends_on conditions and 'space status' cannot tell when it is ready",
+			})
+		}
+
+		if image != "" && !hasPinnedTag(image) {
+			findings = append(findings, Finding{
+				Service: name,
+				Rule:    RuleLatestTag,
+				Message: fmt.Sprintf("image %s is not pinned to a version tag or digest", image),
+			})
+		}
+
+		volumes, _ := svc["volumes"].([]interface{})
+		for _, v := range volumes {
+			source := bindSource(v)
+			if source == "" {
+				continue
+			}
+			path := p.hostPath(source)
+			info, err := os.Stat(path)
+			if err != nil || info.Mode().Perm()&0002 == 0 {
+				continue
+			}
+			findings = append(findings, Finding{
+				Service: name,
+				Rule:    RuleWritableMount,
+				Message: fmt.Sprintf("bind mount %s is world-writable (%v)", source, info.Mode().Perm()),
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Service < findings[j].Service })
+	return findings
+}
+
+// hasPinnedTag reports whether an image reference names a tag other than
+// latest, or a digest
+func hasPinnedTag(image string) bool {
+	if strings.Contains(image, "@") {
+		return true
+	}
+	// The tag follows the last colon after the last slash; a colon before it
+	// belongs to a registry port
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	if i < 0 {
+		return false
+	}
+	tag := name[i+1:]
+	return tag != "" && tag != "latest"
+}
+
+// bindSource returns the host path of a bind mount volume entry, or "" for
+// named volumes and tmpfs
+func bindSource(v interface{}) string {
+	switch vol := v.(type) {
+	case string:
+		source, _, hasTarget := strings.Cut(vol, ":")
+		if hasTarget && (strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~")) {
+			return source
+		}
+	case map[string]interface{}:
+		if vol["type"] == "bind" {
+			source, _ := vol["source"].(string)
+			return source
+		}
+	}
+	return ""
+}
+
+// hostPath resolves a bind mount source against the project directory
+func (p *Project) hostPath(source string) string {
+	if strings.HasPrefix(source, "~") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(source, "~"))
+		}
+	}
+	return absPath(p.ProjectDir, source)
+}
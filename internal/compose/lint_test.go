@@ -0,0 +1,82 @@
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHasPinnedTag(t *testing.T) {
+	tests := map[string]bool{
+		"postgres":                          false,
+		"postgres:latest":                   false,
+		"postgres:16":                       true,
+		"registry:5000/app":                 false,
+		"registry:5000/app:1.2":             true,
+		"ghcr.io/org/app@sha256:0123456789": true,
+	}
+	for image, want := range tests {
+		if got := hasPinnedTag(image); got != want {
+			t.Errorf("hasPinnedTag(%q) = %v, want %v", image, got, want)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"open", "private"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Set the mode explicitly, the umask would clear o+w on Mkdir
+	if err := os.Chmod(filepath.Join(dir, "open"), 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, dir, "docker-compose.yml", `services:
+  web:
+    image: nginx
+    volumes:
+      - ./open:/usr/share/nginx/html
+      - ./private:/etc/nginx/conf.d
+      - cache:/var/cache/nginx
+  db:
+    image: postgres:16
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+    volumes:
+      - type: bind
+        source: ./open
+        target: /backup
+  api:
+    build: .
+  redis:
+    image: redis:7
+volumes:
+  cache: {}
+`)
+
+	p, err := Load(dir, []string{"docker-compose.yml"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	imageHealthcheck := func(image string) bool { return image == "redis:7" }
+
+	var got []string
+	for _, f := range p.Lint(imageHealthcheck) {
+		got = append(got, f.Service+" "+f.Rule)
+	}
+	want := []string{
+		"api " + RuleNoHealthcheck,
+		"db " + RuleWritableMount,
+		"web " + RuleNoHealthcheck,
+		"web " + RuleLatestTag,
+		"web " + RuleWritableMount,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package compose
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Resources are the memory and CPUs a service may use, per container
+type Resources struct {
+	// Memory is the memory limit in bytes, or the reservation when no
+	// limit is set; 0 when neither is
+	Memory int64
+
+	// CPUs is the CPU limit, 0 when unset
+	CPUs float64
+
+	// MemoryReservation and CPUReservation are what the service reserves,
+	// 0 when unset. Unlike limits, which are caps, they must fit.
+	MemoryReservation int64
+	CPUReservation    float64
+
+	// Replicas is the number of containers started (deploy.replicas or
+	// scale), at least 1
+	Replicas int
+}
+
+// Resources returns the declared resources of a service from mem_limit,
+// mem_reservation, cpus, scale and the deploy section
+func (p *Project) Resources(service string) Resources {
+	res := Resources{Replicas: 1}
+	svc, ok := p.Services()[service]
+	if !ok {
+		return res
+	}
+
+	deploy := toMap(svc["deploy"])
+	resources := toMap(deploy["resources"])
+	limits := toMap(resources["limits"])
+	reservations := toMap(resources["reservations"])
+
+	for _, v := range []interface{}{limits["memory"], svc["mem_limit"], reservations["memory"], svc["mem_reservation"]} {
+		if memory, err := ParseMemory(v); err == nil && memory > 0 {
+			res.Memory = memory
+			break
+		}
+	}
+	for _, v := range []interface{}{limits["cpus"], svc["cpus"]} {
+		if cpus, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(v)), 64); err == nil && cpus > 0 {
+			res.CPUs = cpus
+			break
+		}
+	}
+	for _, v := range []interface{}{reservations["memory"], svc["mem_reservation"]} {
+		if memory, err := ParseMemory(v); err == nil && memory > 0 {
+			res.MemoryReservation = memory
+			break
+		}
+	}
+	if cpus, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(reservations["cpus"])), 64); err == nil && cpus > 0 {
+		res.CPUReservation = cpus
+	}
+	for _, v := range []interface{}{deploy["replicas"], svc["scale"]} {
+		if n, err := strconv.Atoi(strings.TrimSpace(fmt.Sprint(v))); err == nil && n >= 0 {
+			res.Replicas = n
+			break
+		}
+	}
+	return res
+}
+
+// memoryUnits are the size suffixes docker and compose accept, in binary
+// multiples like docker; "GB" counts as "g"
+var memoryUnits = map[string]int64{
+	"":  1,
+	"b": 1,
+	"k": 1 << 10,
+	"m": 1 << 20,
+	"g": 1 << 30,
+	"t": 1 << 40,
+}
+
+// ParseMemory parses a memory size such as 512m, 1.5g, "8GB" or a plain
+// number of bytes. A nil value is 0.
+func ParseMemory(v interface{}) (int64, error) {
+	switch t := v.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return int64(t), nil
+	case int64:
+		return t, nil
+	case float64:
+		return int64(t), nil
+	}
+
+	s := strings.ToLower(strings.TrimSpace(fmt.Sprint(v)))
+	unit := strings.TrimLeft(s, "0123456789.")
+	number := strings.TrimSuffix(s, unit)
+	unit = strings.TrimSuffix(strings.TrimSuffix(unit, "b"), "i")
+	if unit == "" && strings.HasSuffix(s, "b") {
+		unit = "b"
+	}
+
+	multiplier, ok := memoryUnits[strings.TrimSpace(unit)]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("invalid memory size %q", s)
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory size %q", s)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// toMap returns v as a mapping, or an empty one
+func toMap(v interface{}) map[string]interface{} {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m
+	}
+	return map[string]interface{}{}
+}
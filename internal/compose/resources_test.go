@@ -0,0 +1,63 @@
+package compose
+
+import "testing"
+
+func TestParseMemory(t *testing.T) {
+	tests := map[interface{}]int64{
+		nil:      0,
+		1024:     1024,
+		"512m":   512 << 20,
+		"1.5g":   3 << 29,
+		"8GB":    8 << 30,
+		"4096MB": 4 << 30,
+		"2GiB":   2 << 30,
+		"100b":   100,
+		"300":    300,
+		"64k":    64 << 10,
+	}
+	for in, want := range tests {
+		got, err := ParseMemory(in)
+		if err != nil || got != want {
+			t.Errorf("ParseMemory(%v) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+
+	for _, in := range []string{"lots", "5x", "g"} {
+		if _, err := ParseMemory(in); err == nil {
+			t.Errorf("ParseMemory(%q): expected an error", in)
+		}
+	}
+}
+
+func TestResources(t *testing.T) {
+	p := &Project{Data: map[string]interface{}{
+		"services": map[string]interface{}{
+			"api": map[string]interface{}{
+				"deploy": map[string]interface{}{
+					"replicas": 3,
+					"resources": map[string]interface{}{
+						"limits":       map[string]interface{}{"memory": "512M", "cpus": "0.5"},
+						"reservations": map[string]interface{}{"memory": "256M", "cpus": "0.25"},
+					},
+				},
+			},
+			"db": map[string]interface{}{
+				"mem_reservation": "1g",
+				"cpus":            2,
+			},
+			"web": map[string]interface{}{},
+		},
+	}}
+
+	tests := map[string]Resources{
+		"api":     {Memory: 512 << 20, CPUs: 0.5, MemoryReservation: 256 << 20, CPUReservation: 0.25, Replicas: 3},
+		"db":      {Memory: 1 << 30, CPUs: 2, MemoryReservation: 1 << 30, Replicas: 1},
+		"web":     {Replicas: 1},
+		"missing": {Replicas: 1},
+	}
+	for service, want := range tests {
+		if got := p.Resources(service); got != want {
+			t.Errorf("Resources(%s) = %+v, want %+v", service, got, want)
+		}
+	}
+}
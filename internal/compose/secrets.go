@@ -0,0 +1,134 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Top-level sections services reference by name
+const (
+	KindSecret = "secret"
+	KindConfig = "config"
+)
+
+// sourceKeys are service keys listing references to top-level secrets or
+// configs; compose merges them by source, the later entry winning
+var sourceKeys = map[string]string{
+	"secrets": KindSecret,
+	"configs": KindConfig,
+}
+
+// Reference is a service's use of a top-level secret or config
+type Reference struct {
+	Service string `json:"service"`
+	Kind    string `json:"kind"`
+	Source  string `json:"source"`
+}
+
+// Secrets returns the top-level secret definitions
+func (p *Project) Secrets() map[string]map[string]interface{} {
+	return p.definitions("secrets")
+}
+
+// Configs returns the top-level config definitions
+func (p *Project) Configs() map[string]map[string]interface{} {
+	return p.definitions("configs")
+}
+
+func (p *Project) definitions(key string) map[string]map[string]interface{} {
+	result := make(map[string]map[string]interface{})
+	section, _ := p.Data[key].(map[string]interface{})
+	for name, v := range section {
+		def, _ := v.(map[string]interface{})
+		if def == nil {
+			def = map[string]interface{}{}
+		}
+		result[name] = def
+	}
+	return result
+}
+
+// References returns the secrets and configs the services (all when
+// services is empty) use, ordered by service
+func (p *Project) References(services []string) []Reference {
+	if len(services) == 0 {
+		services = p.ServiceNames()
+	}
+	services = append([]string(nil), services...)
+	sort.Strings(services)
+
+	all := p.Services()
+	var refs []Reference
+	for _, name := range services {
+		for _, key := range []string{"secrets", "configs"} {
+			entries, _ := all[name][key].([]interface{})
+			for _, entry := range entries {
+				if source := referenceSource(entry); source != "" {
+					refs = append(refs, Reference{Service: name, Kind: sourceKeys[key], Source: source})
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// CheckReferences describes the secrets and configs the services (all when
+// services is empty) use that are not defined at the top level, or whose
+// file does not exist. Compose only reports these once containers are
+// being created.
+func (p *Project) CheckReferences(services []string) []string {
+	defs := map[string]map[string]map[string]interface{}{
+		KindSecret: p.Secrets(),
+		KindConfig: p.Configs(),
+	}
+
+	var problems []string
+	for _, ref := range p.References(services) {
+		def, ok := defs[ref.Kind][ref.Source]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: %s %s is not defined under top-level %ss", ref.Service, ref.Kind, ref.Source, ref.Kind))
+			continue
+		}
+		file, _ := def["file"].(string)
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(p.hostPath(file)); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s %s reads %s, which does not exist", ref.Service, ref.Kind, ref.Source, file))
+		}
+	}
+	return problems
+}
+
+// referenceSource returns the name a secrets or configs entry refers to,
+// written either as the name or as a mapping with a source
+func referenceSource(entry interface{}) string {
+	switch e := entry.(type) {
+	case string:
+		return e
+	case map[string]interface{}:
+		source, _ := e["source"].(string)
+		return source
+	}
+	return ""
+}
+
+// mergeBySource merges secrets or configs entries, replacing dst entries
+// with the src entry for the same source
+func mergeBySource(dst, src []interface{}) []interface{} {
+	index := make(map[string]int, len(dst))
+	for i, v := range dst {
+		index[referenceSource(v)] = i
+	}
+	for _, v := range src {
+		source := referenceSource(v)
+		if i, ok := index[source]; ok && source != "" {
+			dst[i] = v
+			continue
+		}
+		index[source] = len(dst)
+		dst = append(dst, v)
+	}
+	return dst
+}
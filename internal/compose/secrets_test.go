@@ -0,0 +1,109 @@
+package compose
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSecretsMergeBySource(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "docker-compose.yml", `services:
+  api:
+    image: api:1
+    secrets:
+      - db_password
+      - api_key
+    configs:
+      - source: nginx
+        target: /etc/nginx.conf
+secrets:
+  db_password:
+    file: ./secrets/db.txt
+  api_key:
+    environment: API_KEY
+configs:
+  nginx:
+    file: ./nginx.conf
+`)
+	writeFile(t, dir, "docker-compose.override.yml", `services:
+  api:
+    secrets:
+      - source: db_password
+        target: /run/secrets/db
+      - tls_cert
+secrets:
+  tls_cert:
+    external: true
+`)
+
+	p, err := Load(dir, []string{"docker-compose.yml", "docker-compose.override.yml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Reference{
+		{Service: "api", Kind: KindSecret, Source: "db_password"},
+		{Service: "api", Kind: KindSecret, Source: "api_key"},
+		{Service: "api", Kind: KindSecret, Source: "tls_cert"},
+		{Service: "api", Kind: KindConfig, Source: "nginx"},
+	}
+	if got := p.References(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("References() = %+v, want %+v", got, want)
+	}
+	if secrets := p.Secrets(); len(secrets) != 3 || secrets["tls_cert"]["external"] != true {
+		t.Errorf("Secrets() = %v", secrets)
+	}
+
+	// The merged model must survive the YAML round trip of generated files
+	data, err := yaml.Marshal(p.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(doc, p.Data) {
+		t.Errorf("round trip = %v, want %v", doc, p.Data)
+	}
+}
+
+func TestCheckReferences(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "db.txt", "hunter2")
+	writeFile(t, dir, "docker-compose.yml", `services:
+  api:
+    image: api:1
+    secrets: [db_password, api_key]
+  worker:
+    image: worker:1
+    secrets: [smtp_password]
+    configs: [worker]
+secrets:
+  db_password:
+    file: ./db.txt
+  api_key:
+    file: ./api.txt
+configs:
+  worker:
+    content: "queue: default"
+`)
+
+	p, err := Load(dir, []string{"docker-compose.yml"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"api: secret api_key reads ./api.txt, which does not exist",
+		"worker: secret smtp_password is not defined under top-level secrets",
+	}
+	if got := p.CheckReferences(nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckReferences() = %q, want %q", got, want)
+	}
+	if got := p.CheckReferences([]string{"worker"}); len(got) != 1 {
+		t.Errorf("CheckReferences(worker) = %q", got)
+	}
+}
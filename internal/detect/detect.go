@@ -0,0 +1,541 @@
+// Package detect reads the manifests of a project (package.json, go.mod,
+// Gemfile, pyproject.toml, requirements.txt) to propose the services, ports,
+// databases and hooks of a first .space.yaml
+package detect
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Database kinds a project's client libraries point at
+const (
+	Postgres = "postgres"
+	MySQL    = "mysql"
+	Redis    = "redis"
+	MongoDB  = "mongodb"
+)
+
+// DatabaseDefaults are the image, port and environment proposed for each
+// database kind; the environment holds what the image needs to start
+var DatabaseDefaults = map[string]struct {
+	Image       string
+	Port        int
+	Environment map[string]string
+}{
+	Postgres: {"postgres:16", 5432, map[string]string{"POSTGRES_PASSWORD": "postgres"}},
+	MySQL:    {"mysql:8", 3306, map[string]string{"MYSQL_ROOT_PASSWORD": "mysql"}},
+	Redis:    {"redis:7", 6379, nil},
+	MongoDB:  {"mongo:7", 27017, nil},
+}
+
+// Service is a dev server proposed as a service
+type Service struct {
+	// Name is the proposed service name
+	Name string
+
+	// Dir is the directory of the app relative to the project, "." for the
+	// project itself
+	Dir string
+
+	// Port is the port the dev server listens on
+	Port int
+
+	// Framework names what was recognized, e.g. "vite" or "rails"
+	Framework string
+
+	// Command starts the dev server, e.g. "pnpm run dev"
+	Command string
+
+	// Source is the manifest the service was found in, e.g. "web/package.json"
+	Source string
+}
+
+// Analysis is what was learned about a project
+type Analysis struct {
+	// Services are the proposed dev-server services
+	Services []Service
+
+	// Databases are the database kinds client libraries were found for,
+	// sorted
+	Databases []string
+
+	// Vite and Next report Vite and Next.js apps, for their hooks
+	Vite bool
+	Next bool
+
+	// Findings explain each conclusion, one line each
+	Findings []string
+}
+
+// skipDirs are subdirectories never searched for apps
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"tmp":          true,
+}
+
+// Analyze inspects the manifests in dir and in its direct subdirectories,
+// where the apps of a monorepo usually live
+func Analyze(dir string) (*Analysis, error) {
+	a := &Analysis{}
+	databases := make(map[string]bool)
+
+	dirs := []string{"."}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		if e.IsDir() && !strings.HasPrefix(e.Name(), ".") && !skipDirs[e.Name()] {
+			dirs = append(dirs, e.Name())
+		}
+	}
+
+	for _, rel := range dirs {
+		var proposed *Service
+		for _, analyze := range analyzers {
+			svc, dbs, err := analyze(dir, rel, a)
+			if err != nil {
+				return nil, err
+			}
+			for _, db := range dbs {
+				databases[db] = true
+			}
+			// One service per directory, from the first manifest
+			if proposed == nil {
+				proposed = svc
+			}
+		}
+		if proposed != nil {
+			a.Services = append(a.Services, *proposed)
+		}
+	}
+
+	uniqueNames(a.Services)
+	for _, svc := range a.Services {
+		switch svc.Framework {
+		case "vite":
+			a.Vite = true
+		case "next":
+			a.Next = true
+		}
+		a.Findings = append(a.Findings, fmt.Sprintf("%s: %s (%s) → %s on port %d", svc.Source, svc.Framework, svc.Command, svc.Name, svc.Port))
+	}
+	for db := range databases {
+		a.Databases = append(a.Databases, db)
+	}
+	sort.Strings(a.Databases)
+	return a, nil
+}
+
+// analyzer inspects one kind of manifest in dir/rel, returning the service
+// it proposes, if any, and the databases its libraries use
+type analyzer func(dir, rel string, a *Analysis) (*Service, []string, error)
+
+// analyzers run in order; the first proposing a service wins for a directory
+var analyzers = []analyzer{analyzePackageJSON, analyzeGoMod, analyzeGemfile, analyzePython}
+
+// uniqueNames suffixes the framework to the names of subdirectory apps
+// named like the app of the project itself, e.g. web/ next to a root web
+func uniqueNames(services []Service) {
+	count := make(map[string]int)
+	for _, s := range services {
+		count[s.Name]++
+	}
+	for i, s := range services {
+		if count[s.Name] > 1 && s.Dir != "." {
+			services[i].Name = s.Name + "-" + serviceName(s.Framework)
+		}
+	}
+}
+
+// invalidNameChars are replaced in service names made from directories
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// serviceName turns a directory name into a service name
+func serviceName(dir string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(filepath.Base(dir)), "-")
+	return strings.Trim(name, "-")
+}
+
+// nameFor returns the name of a service in rel, the directory name for
+// subdirectories and def for the project itself
+func nameFor(rel, def string) string {
+	if rel == "." {
+		return def
+	}
+	if name := serviceName(rel); name != "" {
+		return name
+	}
+	return def
+}
+
+// source returns the path of a manifest relative to the project
+func source(rel, file string) string {
+	return filepath.ToSlash(filepath.Join(rel, file))
+}
+
+// portFlag finds an explicit port in a dev command: --port 4000, -p 4000,
+// --port=4000 or PORT=4000
+var portFlag = regexp.MustCompile(`(?:--port[ =]|-p |PORT=)(\d{2,5})\b`)
+
+// commandPort returns the port set in a command, or def
+func commandPort(command string, def int) int {
+	if m := portFlag.FindStringSubmatch(command); m != nil {
+		if port, err := strconv.Atoi(m[1]); err == nil && port > 0 && port < 65536 {
+			return port
+		}
+	}
+	return def
+}
+
+// packageJSON is the part of package.json detection reads
+type packageJSON struct {
+	Scripts         map[string]string `json:"scripts"`
+	Dependencies    map[string]string `json:"dependencies"`
+	DevDependencies map[string]string `json:"devDependencies"`
+	Workspaces      json.RawMessage   `json:"workspaces"`
+}
+
+// nodeFramework is a framework recognized by a dependency
+type nodeFramework struct {
+	dependency string
+	name       string
+	port       int
+	frontend   bool
+}
+
+// nodeFrameworks are checked in order; meta-frameworks come before the
+// libraries they build on
+var nodeFrameworks = []nodeFramework{
+	{"next", "next", 3000, true},
+	{"nuxt", "nuxt", 3000, true},
+	{"astro", "astro", 4321, true},
+	{"@remix-run/dev", "remix", 3000, true},
+	{"@angular/cli", "angular", 4200, true},
+	{"vite", "vite", 5173, true},
+	{"react-scripts", "create-react-app", 3000, true},
+	{"@nestjs/core", "nest", 3000, false},
+	{"fastify", "fastify", 3000, false},
+	{"express", "express", 3000, false},
+}
+
+// nodeDatabases maps client libraries to the databases they talk to
+var nodeDatabases = map[string]string{
+	"pg":       Postgres,
+	"postgres": Postgres,
+	"mysql":    MySQL,
+	"mysql2":   MySQL,
+	"redis":    Redis,
+	"ioredis":  Redis,
+	"mongodb":  MongoDB,
+	"mongoose": MongoDB,
+}
+
+// packageManager returns the package manager of a node app from its
+// lockfile
+func packageManager(dir string) string {
+	for _, lock := range []struct{ file, manager string }{
+		{"pnpm-lock.yaml", "pnpm"},
+		{"yarn.lock", "yarn"},
+		{"bun.lockb", "bun"},
+		{"bun.lock", "bun"},
+	} {
+		if _, err := os.Stat(filepath.Join(dir, lock.file)); err == nil {
+			return lock.manager
+		}
+	}
+	return "npm"
+}
+
+// analyzePackageJSON proposes a service for a node app with a dev or start
+// script
+func analyzePackageJSON(dir, rel string, a *Analysis) (*Service, []string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, rel, "package.json"))
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", source(rel, "package.json"), err)
+	}
+
+	deps := make(map[string]bool)
+	for name := range pkg.Dependencies {
+		deps[name] = true
+	}
+	for name := range pkg.DevDependencies {
+		deps[name] = true
+	}
+
+	dbs := libraryDatabases(deps, nodeDatabases, source(rel, "package.json"), a)
+
+	script := "dev"
+	if pkg.Scripts[script] == "" {
+		script = "start"
+	}
+	command := pkg.Scripts[script]
+	if command == "" || len(pkg.Workspaces) > 0 {
+		// The apps of a workspace root are proposed from their directories
+		return nil, dbs, nil
+	}
+
+	svc := &Service{
+		Name:    nameFor(rel, "app"),
+		Dir:     rel,
+		Port:    3000,
+		Command: packageManager(filepath.Join(dir, rel)) + " run " + script,
+		Source:  source(rel, "package.json"),
+	}
+	for _, fw := range nodeFrameworks {
+		if !deps[fw.dependency] {
+			continue
+		}
+		svc.Framework = fw.name
+		svc.Port = fw.port
+		if rel == "." {
+			svc.Name = "api"
+			if fw.frontend {
+				svc.Name = "web"
+			}
+		}
+		break
+	}
+	if svc.Framework == "" {
+		svc.Framework = "node"
+	}
+	svc.Port = commandPort(command, svc.Port)
+	return svc, dbs, nil
+}
+
+// goDatabases maps module paths to the databases they talk to
+var goDatabases = []struct{ module, db string }{
+	{"github.com/lib/pq", Postgres},
+	{"github.com/jackc/pgx", Postgres},
+	{"gorm.io/driver/postgres", Postgres},
+	{"github.com/go-sql-driver/mysql", MySQL},
+	{"gorm.io/driver/mysql", MySQL},
+	{"github.com/redis/go-redis", Redis},
+	{"github.com/go-redis/redis", Redis},
+	{"github.com/gomodule/redigo", Redis},
+	{"go.mongodb.org/mongo-driver", MongoDB},
+}
+
+// analyzeGoMod proposes a service for a Go module with a main package
+func analyzeGoMod(dir, rel string, a *Analysis) (*Service, []string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, rel, "go.mod"))
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var dbs []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(line), "require "))
+		if len(fields) == 0 {
+			continue
+		}
+		for _, d := range goDatabases {
+			if strings.HasPrefix(fields[0], d.module) && !seen[d.db] {
+				seen[d.db] = true
+				dbs = append(dbs, d.db)
+				a.Findings = append(a.Findings, fmt.Sprintf("%s: %s uses %s", source(rel, "go.mod"), d.module, d.db))
+			}
+		}
+	}
+
+	if !hasGoMain(filepath.Join(dir, rel)) {
+		return nil, dbs, nil
+	}
+
+	svc := &Service{
+		Name:      nameFor(rel, "api"),
+		Dir:       rel,
+		Port:      8080,
+		Framework: "go",
+		Command:   "go run .",
+		Source:    source(rel, "go.mod"),
+	}
+	if _, err := os.Stat(filepath.Join(dir, rel, "main.go")); err != nil {
+		svc.Command = "go run ./cmd/..."
+	}
+	return svc, dbs, nil
+}
+
+// hasGoMain reports whether a module has a main.go or a cmd directory
+func hasGoMain(dir string) bool {
+	for _, p := range []string{"main.go", "cmd"} {
+		if _, err := os.Stat(filepath.Join(dir, p)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// gemPattern matches a gem line of a Gemfile
+var gemPattern = regexp.MustCompile(`^\s*gem\s+["']([^"']+)["']`)
+
+// rubyDatabases maps gems to the databases they talk to
+var rubyDatabases = map[string]string{
+	"pg":      Postgres,
+	"mysql2":  MySQL,
+	"trilogy": MySQL,
+	"redis":   Redis,
+	"sidekiq": Redis,
+	"mongoid": MongoDB,
+}
+
+// analyzeGemfile proposes a service for a Rails or Sinatra app
+func analyzeGemfile(dir, rel string, a *Analysis) (*Service, []string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, rel, "Gemfile"))
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gems := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := gemPattern.FindStringSubmatch(line); m != nil {
+			gems[m[1]] = true
+		}
+	}
+
+	dbs := libraryDatabases(gems, rubyDatabases, source(rel, "Gemfile"), a)
+
+	var svc *Service
+	switch {
+	case gems["rails"]:
+		svc = &Service{Name: nameFor(rel, "web"), Port: 3000, Framework: "rails", Command: "bin/rails server"}
+	case gems["sinatra"]:
+		svc = &Service{Name: nameFor(rel, "web"), Port: 4567, Framework: "sinatra", Command: "bundle exec ruby app.rb"}
+	default:
+		return nil, dbs, nil
+	}
+	svc.Dir = rel
+	svc.Source = source(rel, "Gemfile")
+	return svc, dbs, nil
+}
+
+// pythonDatabases maps packages to the databases they talk to
+var pythonDatabases = map[string]string{
+	"psycopg":         Postgres,
+	"psycopg2":        Postgres,
+	"psycopg2-binary": Postgres,
+	"asyncpg":         Postgres,
+	"mysqlclient":     MySQL,
+	"pymysql":         MySQL,
+	"redis":           Redis,
+	"celery":          Redis,
+	"pymongo":         MongoDB,
+	"motor":           MongoDB,
+}
+
+// pythonPackage matches the package name at the start of a requirement
+var pythonPackage = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*`)
+
+// analyzePython proposes a service for a Django, FastAPI or Flask app from
+// pyproject.toml or requirements.txt
+func analyzePython(dir, rel string, a *Analysis) (*Service, []string, error) {
+	var file string
+	var requirements []string
+	for _, name := range []string{"pyproject.toml", "requirements.txt"} {
+		data, err := os.ReadFile(filepath.Join(dir, rel, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		file = name
+		requirements = pythonRequirements(name, string(data))
+		break
+	}
+	if file == "" {
+		return nil, nil, nil
+	}
+
+	packages := make(map[string]bool)
+	for _, r := range requirements {
+		packages[strings.ToLower(r)] = true
+	}
+
+	dbs := libraryDatabases(packages, pythonDatabases, source(rel, file), a)
+
+	var svc *Service
+	switch {
+	case packages["django"]:
+		svc = &Service{Name: nameFor(rel, "web"), Port: 8000, Framework: "django", Command: "python manage.py runserver"}
+	case packages["fastapi"]:
+		svc = &Service{Name: nameFor(rel, "api"), Port: 8000, Framework: "fastapi", Command: "uvicorn main:app --reload"}
+	case packages["flask"]:
+		svc = &Service{Name: nameFor(rel, "web"), Port: 5000, Framework: "flask", Command: "flask run"}
+	default:
+		return nil, dbs, nil
+	}
+	svc.Dir = rel
+	svc.Source = source(rel, file)
+	return svc, dbs, nil
+}
+
+// pythonRequirements returns the package names listed in a requirements
+// file, or quoted in the dependency lists of a pyproject.toml
+func pythonRequirements(file, content string) []string {
+	var names []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if file == "pyproject.toml" {
+			// Entries such as "django>=5", in [project] dependencies, or
+			// django = "^5" in Poetry tables
+			if strings.HasPrefix(line, `"`) || strings.HasPrefix(line, `'`) {
+				line = strings.Trim(line, `"',`)
+			} else if key, _, ok := strings.Cut(line, "="); ok && !strings.HasPrefix(line, "[") {
+				line = strings.TrimSpace(key)
+			} else {
+				continue
+			}
+		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if m := pythonPackage.FindString(line); m != "" {
+			names = append(names, m)
+		}
+	}
+	return names
+}
+
+// libraryDatabases returns the databases of the libraries in libs, sorted,
+// recording a finding for each
+func libraryDatabases(libs map[string]bool, known map[string]string, src string, a *Analysis) []string {
+	var names []string
+	for lib := range known {
+		if libs[lib] {
+			names = append(names, lib)
+		}
+	}
+	sort.Strings(names)
+
+	var dbs []string
+	for _, lib := range names {
+		dbs = append(dbs, known[lib])
+		a.Findings = append(a.Findings, fmt.Sprintf("%s: %s uses %s", src, lib, known[lib]))
+	}
+	return dbs
+}
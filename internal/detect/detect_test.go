@@ -0,0 +1,161 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles creates files under dir from paths relative to it
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]string
+		wantServices  []Service
+		wantDatabases []string
+		wantVite      bool
+		wantNext      bool
+	}{
+		{
+			name: "vite app with pnpm",
+			files: map[string]string{
+				"package.json":   `{"scripts": {"dev": "vite", "build": "vite build"}, "devDependencies": {"vite": "^5.0.0"}}`,
+				"pnpm-lock.yaml": "",
+			},
+			wantServices: []Service{{Name: "web", Dir: ".", Port: 5173, Framework: "vite", Command: "pnpm run dev", Source: "package.json"}},
+			wantVite:     true,
+		},
+		{
+			name: "next app with a port and postgres",
+			files: map[string]string{
+				"package.json": `{"scripts": {"dev": "next dev -p 4000"}, "dependencies": {"next": "15.0.0", "pg": "^8"}}`,
+				"yarn.lock":    "",
+			},
+			wantServices:  []Service{{Name: "web", Dir: ".", Port: 4000, Framework: "next", Command: "yarn run dev", Source: "package.json"}},
+			wantDatabases: []string{Postgres},
+			wantNext:      true,
+		},
+		{
+			name: "express api started with start",
+			files: map[string]string{
+				"package.json": `{"scripts": {"start": "node server.js"}, "dependencies": {"express": "^4", "ioredis": "^5", "mongoose": "^8"}}`,
+			},
+			wantServices:  []Service{{Name: "api", Dir: ".", Port: 3000, Framework: "express", Command: "npm run start", Source: "package.json"}},
+			wantDatabases: []string{MongoDB, Redis},
+		},
+		{
+			name: "monorepo with a workspace root",
+			files: map[string]string{
+				"package.json":          `{"workspaces": ["frontend"], "scripts": {"dev": "turbo dev"}}`,
+				"frontend/package.json": `{"scripts": {"dev": "vite"}, "devDependencies": {"vite": "^5"}}`,
+				"backend/go.mod":        "module example.com/backend\n\ngo 1.22\n\nrequire (\n\tgithub.com/jackc/pgx/v5 v5.5.0\n)\n",
+				"backend/main.go":       "package main\n",
+				"node_modules/x/go.mod": "module x\n",
+			},
+			wantServices: []Service{
+				{Name: "backend", Dir: "backend", Port: 8080, Framework: "go", Command: "go run .", Source: "backend/go.mod"},
+				{Name: "frontend", Dir: "frontend", Port: 5173, Framework: "vite", Command: "npm run dev", Source: "frontend/package.json"},
+			},
+			wantDatabases: []string{Postgres},
+			wantVite:      true,
+		},
+		{
+			name: "go library without a main package",
+			files: map[string]string{
+				"go.mod": "module example.com/lib\n\nrequire github.com/go-sql-driver/mysql v1.8.0\n",
+			},
+			wantDatabases: []string{MySQL},
+		},
+		{
+			name: "rails",
+			files: map[string]string{
+				"Gemfile": "source \"https://rubygems.org\"\n\ngem \"rails\", \"~> 7.1\"\ngem 'pg'\ngem \"sidekiq\"\n",
+			},
+			wantServices:  []Service{{Name: "web", Dir: ".", Port: 3000, Framework: "rails", Command: "bin/rails server", Source: "Gemfile"}},
+			wantDatabases: []string{Postgres, Redis},
+		},
+		{
+			name: "fastapi in pyproject",
+			files: map[string]string{
+				"pyproject.toml": "[project]\nname = \"svc\"\ndependencies = [\n  \"fastapi>=0.110\",\n  \"asyncpg\",\n]\n",
+			},
+			wantServices:  []Service{{Name: "api", Dir: ".", Port: 8000, Framework: "fastapi", Command: "uvicorn main:app --reload", Source: "pyproject.toml"}},
+			wantDatabases: []string{Postgres},
+		},
+		{
+			name: "django in requirements",
+			files: map[string]string{
+				"requirements.txt": "# web\nDjango==5.0\npsycopg2-binary==2.9\n-r dev.txt\n",
+			},
+			wantServices:  []Service{{Name: "web", Dir: ".", Port: 8000, Framework: "django", Command: "python manage.py runserver", Source: "requirements.txt"}},
+			wantDatabases: []string{Postgres},
+		},
+		{
+			name:  "nothing to detect",
+			files: map[string]string{"README.md": "# hello\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			a, err := Analyze(dir)
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if !reflect.DeepEqual(a.Services, tt.wantServices) {
+				t.Errorf("Services = %+v, want %+v", a.Services, tt.wantServices)
+			}
+			if !reflect.DeepEqual(a.Databases, tt.wantDatabases) {
+				t.Errorf("Databases = %v, want %v", a.Databases, tt.wantDatabases)
+			}
+			if a.Vite != tt.wantVite || a.Next != tt.wantNext {
+				t.Errorf("Vite, Next = %v, %v, want %v, %v", a.Vite, a.Next, tt.wantVite, tt.wantNext)
+			}
+			if len(tt.wantServices)+len(tt.wantDatabases) > 0 && len(a.Findings) == 0 {
+				t.Error("no findings explain the analysis")
+			}
+		})
+	}
+}
+
+func TestAnalyzeDuplicateNames(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json":      `{"scripts": {"dev": "vite"}, "devDependencies": {"vite": "^5"}}`,
+		"web/package.json":  `{"scripts": {"dev": "next dev"}, "dependencies": {"next": "15"}}`,
+		"Web App/README.md": "",
+	})
+
+	a, err := Analyze(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range a.Services {
+		names = append(names, s.Name)
+	}
+	// The project's own app keeps its name
+	if !reflect.DeepEqual(names, []string{"web", "web-next"}) {
+		t.Errorf("names = %v, want [web web-next]", names)
+	}
+	if serviceName("Web App") != "web-app" {
+		t.Errorf("serviceName(Web App) = %q, want web-app", serviceName("Web App"))
+	}
+}
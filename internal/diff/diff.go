@@ -0,0 +1,153 @@
+// Package diff renders the changes between two versions of a text file as a
+// unified diff, like diff -u and git diff.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around a change
+const context = 3
+
+// maxCells bounds the table of the line matching. Larger files are shown as
+// removed and added as a whole.
+const maxCells = 4 << 20
+
+// op is a line of the edit script: kept (' '), removed ('-') or added ('+').
+// a and b are the number of lines of each file before it.
+type op struct {
+	kind byte
+	text string
+	a, b int
+}
+
+// Unified returns the unified diff turning a into b, or "" when they are
+// equal. fromName and toName head the diff, e.g. "a/.env" or "/dev/null".
+func Unified(fromName, toName string, a, b []byte) string {
+	if string(a) == string(b) {
+		return ""
+	}
+	ops := edits(lines(string(a)), lines(string(b)))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+	for start := 0; start < len(ops); {
+		// Find the next change and the last change close enough to share
+		// its hunk
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+		last := first
+		for i := first + 1; i < len(ops) && i <= last+2*context; i++ {
+			if ops[i].kind != ' ' {
+				last = i
+			}
+		}
+
+		from := max(first-context, start)
+		to := min(last+context+1, len(ops))
+		writeHunk(&out, ops[from:to])
+		start = to
+	}
+	return out.String()
+}
+
+// writeHunk writes the header and the lines of a hunk
+func writeHunk(out *strings.Builder, ops []op) {
+	var aCount, bCount int
+	for _, o := range ops {
+		if o.kind != '+' {
+			aCount++
+		}
+		if o.kind != '-' {
+			bCount++
+		}
+	}
+	// An empty side starts at the line before the hunk
+	aStart, bStart := ops[0].a, ops[0].b
+	if aCount > 0 {
+		aStart++
+	}
+	if bCount > 0 {
+		bStart++
+	}
+	fmt.Fprintf(out, "@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount))
+
+	for _, o := range ops {
+		out.WriteByte(o.kind)
+		out.WriteString(o.text)
+		if !strings.HasSuffix(o.text, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a side of a hunk header, leaving out a count of one
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// lines splits s into lines, keeping their line endings
+func lines(s string) []string {
+	l := strings.SplitAfter(s, "\n")
+	if l[len(l)-1] == "" {
+		l = l[:len(l)-1]
+	}
+	return l
+}
+
+// edits returns the edit script turning a into b, keeping their longest
+// common subsequence of lines
+func edits(a, b []string) []op {
+	var ops []op
+	if len(a)*len(b) > maxCells {
+		for i, line := range a {
+			ops = append(ops, op{kind: '-', text: line, a: i, b: 0})
+		}
+		for j, line := range b {
+			ops = append(ops, op{kind: '+', text: line, a: len(a), b: j})
+		}
+		return ops
+	}
+
+	// common[i][j] is the length of the longest common subsequence of
+	// a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{kind: ' ', text: a[i], a: i, b: j})
+			i++
+			j++
+		case j == len(b) || i < len(a) && common[i+1][j] >= common[i][j+1]:
+			ops = append(ops, op{kind: '-', text: a[i], a: i, b: j})
+			i++
+		default:
+			ops = append(ops, op{kind: '+', text: b[j], a: i, b: j})
+			j++
+		}
+	}
+	return ops
+}
@@ -0,0 +1,69 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{name: "equal", a: "x\n", b: "x\n"},
+		{
+			name: "changed line",
+			a:    "a\nb\nc\n",
+			b:    "a\nB\nc\n",
+			want: "--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "created",
+			b:    "x\ny\n",
+			want: "--- a/f\n+++ b/f\n@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name: "removed",
+			a:    "x\n",
+			want: "--- a/f\n+++ b/f\n@@ -1 +0,0 @@\n-x\n",
+		},
+		{
+			name: "no newline at end",
+			a:    "x\n",
+			b:    "x\ny",
+			want: "--- a/f\n+++ b/f\n@@ -1 +1,2 @@\n x\n+y\n\\ No newline at end of file\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("a/f", "b/f", []byte(tt.a), []byte(tt.b)); got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedHunks(t *testing.T) {
+	var a []string
+	for i := 1; i <= 20; i++ {
+		a = append(a, string(rune('a'+i-1)))
+	}
+	b := append([]string(nil), a...)
+	b[1] = "B"
+	b[17] = "R"
+
+	got := Unified("a/f", "b/f", []byte(strings.Join(a, "\n")+"\n"), []byte(strings.Join(b, "\n")+"\n"))
+	if strings.Count(got, "@@ -") != 2 {
+		t.Fatalf("want two hunks for distant changes, got\n%s", got)
+	}
+	if !strings.Contains(got, "@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n") || !strings.Contains(got, "@@ -15,6 +15,6 @@\n o\n p\n q\n-r\n+R\n s\n t\n") {
+		t.Errorf("unexpected hunks:\n%s", got)
+	}
+
+	b[5] = "F"
+	got = Unified("a/f", "b/f", []byte(strings.Join(a, "\n")+"\n"), []byte(strings.Join(b, "\n")+"\n"))
+	if !strings.Contains(got, "@@ -1,9 +1,9 @@\n") {
+		t.Errorf("want close changes in one hunk, got\n%s", got)
+	}
+}
@@ -0,0 +1,107 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"runtime"
+	"slices"
+)
+
+const (
+	// StableIP is the dedicated loopback alias the DNS server prefers. Using
+	// its own address lets the server take the standard port without
+	// conflicting with mDNS on 5353, so the resolver file never changes.
+	StableIP = "127.88.0.1"
+
+	// StablePort is the port used on the loopback alias
+	StablePort = 53
+)
+
+// FallbackPorts are tried on 127.0.0.1 when the loopback alias is unavailable
+var FallbackPorts = []int{5353, 5354, 5355, 5356}
+
+// StableAddr returns the address of the DNS server on the loopback alias
+func StableAddr() string {
+	return net.JoinHostPort(StableIP, fmt.Sprint(StablePort))
+}
+
+// ListenCandidates returns the addresses to try binding, in order. The
+// loopback alias comes first when it is available.
+func ListenCandidates(aliasReady bool) []string {
+	var addrs []string
+	if aliasReady {
+		addrs = append(addrs, StableAddr())
+	}
+	for _, port := range FallbackPorts {
+		addrs = append(addrs, net.JoinHostPort("127.0.0.1", fmt.Sprint(port)))
+	}
+	return addrs
+}
+
+// PreferAddr moves last, the address the user's daemon bound before, in
+// front of the other fallback ports, behind the loopback alias. On a machine
+// several users share, each daemon then gets its own port back rather than
+// whichever is free first.
+func PreferAddr(candidates []string, last string) []string {
+	i := slices.Index(candidates, last)
+	first := 0
+	if len(candidates) > 0 && candidates[0] == StableAddr() {
+		first = 1
+	}
+	if i <= first {
+		return candidates
+	}
+
+	addrs := slices.Delete(slices.Clone(candidates), i, i+1)
+	return slices.Insert(addrs, first, last)
+}
+
+// EnsureLoopbackAlias makes sure ip is assigned to the loopback interface,
+// adding it with sudo when needed. On Linux the whole 127.0.0.0/8 range is
+// routed to lo, so no alias is required there.
+func EnsureLoopbackAlias(ctx context.Context, ip string) error {
+	if runtime.GOOS == "linux" {
+		return nil
+	}
+
+	assigned, err := hasLocalAddress(ip)
+	if err != nil {
+		return err
+	}
+	if assigned {
+		return nil
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// -n: never prompt, fall back to the port range instead
+		cmd = exec.CommandContext(ctx, "sudo", "-n", "ifconfig", "lo0", "alias", ip, "up")
+	default:
+		return fmt.Errorf("loopback aliases are not supported on %s", runtime.GOOS)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to add loopback alias %s: %w: %s", ip, err, output)
+	}
+
+	return nil
+}
+
+// hasLocalAddress reports whether ip is assigned to a local interface
+func hasLocalAddress(ip string) (bool, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false, fmt.Errorf("failed to list interface addresses: %w", err)
+	}
+
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.String() == ip {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
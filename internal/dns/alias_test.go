@@ -0,0 +1,67 @@
+package dns
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestListenCandidates(t *testing.T) {
+	fallback := []string{"127.0.0.1:5353", "127.0.0.1:5354", "127.0.0.1:5355", "127.0.0.1:5356"}
+
+	if got := ListenCandidates(false); !reflect.DeepEqual(got, fallback) {
+		t.Errorf("ListenCandidates(false) = %v, want %v", got, fallback)
+	}
+
+	want := append([]string{"127.88.0.1:53"}, fallback...)
+	if got := ListenCandidates(true); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListenCandidates(true) = %v, want %v", got, want)
+	}
+}
+
+func TestPreferAddr(t *testing.T) {
+	fallback := ListenCandidates(false)
+	withAlias := ListenCandidates(true)
+
+	tests := []struct {
+		name       string
+		candidates []string
+		last       string
+		want       []string
+	}{
+		{"no previous address", withAlias, "", withAlias},
+		{"unknown address", withAlias, "127.0.0.1:9999", withAlias},
+		{"behind the alias", withAlias, "127.0.0.1:5355", []string{"127.88.0.1:53", "127.0.0.1:5355", "127.0.0.1:5353", "127.0.0.1:5354", "127.0.0.1:5356"}},
+		{"without the alias", fallback, "127.0.0.1:5356", []string{"127.0.0.1:5356", "127.0.0.1:5353", "127.0.0.1:5354", "127.0.0.1:5355"}},
+		{"the alias itself", withAlias, "127.88.0.1:53", withAlias},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PreferAddr(tt.candidates, tt.last); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PreferAddr() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The candidates are left alone
+	if !reflect.DeepEqual(withAlias, ListenCandidates(true)) {
+		t.Errorf("PreferAddr modified its input: %v", withAlias)
+	}
+}
+
+func TestHasLocalAddress(t *testing.T) {
+	ok, err := hasLocalAddress("127.0.0.1")
+	if err != nil {
+		t.Fatalf("hasLocalAddress() error = %v", err)
+	}
+	if !ok {
+		t.Error("expected 127.0.0.1 to be a local address")
+	}
+
+	ok, err = hasLocalAddress("192.0.2.123")
+	if err != nil {
+		t.Fatalf("hasLocalAddress() error = %v", err)
+	}
+	if ok {
+		t.Error("did not expect 192.0.2.123 to be a local address")
+	}
+}
@@ -1,74 +1,224 @@
 package dns
 
 import (
+	"container/list"
+	"hash/fnv"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
+// NoCacheLabel in front of a served domain makes a query skip the cache:
+// api-a1b2c3.nocache.space.local is answered straight from docker, which
+// tells a stale cache entry apart from a container that isn't registered
+const NoCacheLabel = "nocache"
+
+// cacheShards is the number of independently locked parts of the cache, so
+// concurrent queries for different hostnames rarely wait on each other
+const cacheShards = 16
+
 // cacheEntry represents a cached DNS entry
 type cacheEntry struct {
+	key     string
 	ip      string
 	expires time.Time
 }
 
-// cache is a simple in-memory cache for DNS records
+// cache holds container addresses for a TTL. It is split into shards by
+// hostname, each evicting its least recently used entry when full.
 type cache struct {
-	entries map[string]*cacheEntry
-	ttl     time.Duration
+	shards [cacheShards]cacheShard
+	ttl    time.Duration
+}
+
+// cacheShard is a part of the cache with its own lock and LRU order
+type cacheShard struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // Most recently used first
 	maxSize int
-	mu      sync.RWMutex
 }
 
-// newCache creates a new cache
+// newCache creates a new cache holding about maxSize entries
 func newCache(ttl time.Duration, maxSize int) *cache {
-	return &cache{
-		entries: make(map[string]*cacheEntry),
-		ttl:     ttl,
-		maxSize: maxSize,
+	c := &cache{ttl: ttl}
+	perShard := max((maxSize+cacheShards-1)/cacheShards, 1)
+	for i := range c.shards {
+		c.shards[i] = cacheShard{
+			entries: make(map[string]*list.Element),
+			order:   list.New(),
+			maxSize: perShard,
+		}
 	}
+	return c
 }
 
-// get retrieves a value from the cache
+// shard returns the shard holding key
+func (c *cache) shard(key string) *cacheShard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &c.shards[h.Sum32()%cacheShards]
+}
+
+// get retrieves a value from the cache, marking it recently used. Expired
+// entries are dropped.
 func (c *cache) get(key string) string {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	entry, ok := c.entries[key]
+	elem, ok := s.entries[key]
 	if !ok {
 		return ""
 	}
 
+	entry := elem.Value.(*cacheEntry)
 	if time.Now().After(entry.expires) {
-		// Entry expired
+		s.remove(elem)
 		return ""
 	}
 
+	s.order.MoveToFront(elem)
 	return entry.ip
 }
 
-// set stores a value in the cache
+// peek returns a live entry with its remaining lifetime, leaving the LRU
+// order alone
+func (c *cache) peek(key string) (string, time.Duration, bool) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return "", 0, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	remaining := time.Until(entry.expires)
+	if remaining <= 0 {
+		return "", 0, false
+	}
+
+	return entry.ip, remaining, true
+}
+
+// set stores a value in the cache, evicting the least recently used entry
+// of its shard when the shard is full
 func (c *cache) set(key, value string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	// Check cache size
-	if len(c.entries) >= c.maxSize {
-		// Remove oldest entry (simple LRU approximation)
-		oldestKey := ""
-		oldestTime := time.Now()
-		for k, v := range c.entries {
-			if v.expires.Before(oldestTime) {
-				oldestKey = k
-				oldestTime = v.expires
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expires := time.Now().Add(c.ttl)
+	if elem, ok := s.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.ip, entry.expires = value, expires
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	for s.order.Len() >= s.maxSize {
+		s.remove(s.order.Back())
+	}
+	s.entries[key] = s.order.PushFront(&cacheEntry{key: key, ip: value, expires: expires})
+}
+
+// remove drops an entry; callers hold mu
+func (s *cacheShard) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.entries, elem.Value.(*cacheEntry).key)
+}
+
+// len returns the number of entries, live or expired
+func (c *cache) len() int {
+	n := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		n += s.order.Len()
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// list returns the live entries, sorted by key
+func (c *cache) list() []CacheEntry {
+	now := time.Now()
+	var entries []CacheEntry
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		for elem := s.order.Front(); elem != nil; elem = elem.Next() {
+			entry := elem.Value.(*cacheEntry)
+			if remaining := entry.expires.Sub(now); remaining > 0 {
+				entries = append(entries, CacheEntry{Hostname: entry.key, IP: entry.ip, TTL: remaining})
 			}
 		}
-		if oldestKey != "" {
-			delete(c.entries, oldestKey)
+		s.mu.Unlock()
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Hostname < entries[j].Hostname })
+	return entries
+}
+
+// flush removes the entry for key, or every entry when key is empty, and
+// returns how many were removed
+func (c *cache) flush(key string) int {
+	if key != "" {
+		s := c.shard(key)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		elem, ok := s.entries[key]
+		if !ok {
+			return 0
 		}
+		s.remove(elem)
+		return 1
 	}
 
-	c.entries[key] = &cacheEntry{
-		ip:      value,
-		expires: time.Now().Add(c.ttl),
+	n := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		n += s.order.Len()
+		s.entries = make(map[string]*list.Element)
+		s.order.Init()
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// CacheEntry is a container address the server answers from its cache
+type CacheEntry struct {
+	Hostname string        `json:"hostname"`
+	IP       string        `json:"ip"`
+	TTL      time.Duration `json:"ttl"` // Time left before docker is asked again
+}
+
+// CacheEntries returns the live cache entries, sorted by hostname
+func (s *Server) CacheEntries() []CacheEntry {
+	return s.cache.list()
+}
+
+// FlushCache drops the cached address of hostname, or the whole cache when
+// hostname is empty, and returns how many entries were dropped
+func (s *Server) FlushCache(hostname string) int {
+	if hostname == "" {
+		return s.cache.flush("")
+	}
+	hostname, _ = s.cutNoCache(normalizeName(hostname))
+	return s.cache.flush(hostname)
+}
+
+// cutNoCache strips the NoCacheLabel in front of the served domain from
+// hostname, reporting whether it was there
+func (s *Server) cutNoCache(hostname string) (string, bool) {
+	domain := s.domainFor(hostname)
+	suffix := "." + NoCacheLabel + "." + domain
+	if !strings.HasSuffix(hostname, suffix) {
+		return hostname, false
 	}
+	return strings.TrimSuffix(hostname, suffix) + "." + domain, true
 }
@@ -0,0 +1,185 @@
+package dns
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServerCache(t *testing.T) {
+	docker := &fakeDocker{ips: map[string]string{"api-a1b2c3": "172.18.0.4", "web-a1b2c3": "172.18.0.5"}}
+	server, err := NewServer(Config{Domain: "space.local", Docker: docker, Logger: &recordingLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ip := server.lookupContainer("API-a1b2c3.space.local"); ip != "172.18.0.4" {
+		t.Fatalf("lookupContainer() = %q", ip)
+	}
+	server.lookupContainer("web-a1b2c3.space.local")
+
+	entries := server.CacheEntries()
+	if len(entries) != 2 || entries[0].Hostname != "api-a1b2c3.space.local" || entries[0].IP != "172.18.0.4" || entries[0].TTL <= 0 {
+		t.Fatalf("CacheEntries() = %+v", entries)
+	}
+
+	// The container moved: the cache keeps answering the old address, a
+	// nocache query gets the new one without refreshing the cache
+	docker.ips["api-a1b2c3"] = "172.18.0.9"
+	if ip := server.lookupContainer("api-a1b2c3.space.local"); ip != "172.18.0.4" {
+		t.Errorf("cached lookup = %q, want the stale address", ip)
+	}
+	if ip := server.lookupContainer("api-a1b2c3.nocache.space.local"); ip != "172.18.0.9" {
+		t.Errorf("nocache lookup = %q, want the new address", ip)
+	}
+	if ip, _, _ := server.cache.peek("api-a1b2c3.space.local"); ip != "172.18.0.4" {
+		t.Errorf("nocache lookup changed the cache to %q", ip)
+	}
+	if _, _, ok := server.cache.peek("api-a1b2c3.nocache.space.local"); ok {
+		t.Error("nocache hostname was cached")
+	}
+
+	if n := server.FlushCache("api-a1b2c3.space.local."); n != 1 {
+		t.Errorf("FlushCache(api) = %d, want 1", n)
+	}
+	if n := server.FlushCache("api-a1b2c3.space.local"); n != 0 {
+		t.Errorf("FlushCache(api) again = %d, want 0", n)
+	}
+	if ip := server.lookupContainer("api-a1b2c3.space.local"); ip != "172.18.0.9" {
+		t.Errorf("lookup after flush = %q, want the new address", ip)
+	}
+	if n := server.FlushCache(""); n != 2 {
+		t.Errorf("FlushCache() = %d, want 2", n)
+	}
+	if entries := server.CacheEntries(); len(entries) != 0 {
+		t.Errorf("CacheEntries() after flush = %+v", entries)
+	}
+}
+
+func TestCacheLRU(t *testing.T) {
+	c := newCache(time.Minute, cacheShards*2)
+
+	// Find three keys of one shard, which holds two entries
+	var keys []string
+	for i := 0; len(keys) < 3; i++ {
+		key := fmt.Sprintf("svc%d.space.local", i)
+		if c.shard(key) == c.shard("svc0.space.local") {
+			keys = append(keys, key)
+		}
+	}
+
+	c.set(keys[0], "10.0.0.1")
+	c.set(keys[1], "10.0.0.2")
+	c.get(keys[0]) // keys[1] is now the least recently used
+	c.set(keys[2], "10.0.0.3")
+
+	if c.get(keys[1]) != "" {
+		t.Errorf("%s was not evicted", keys[1])
+	}
+	if c.get(keys[0]) != "10.0.0.1" || c.get(keys[2]) != "10.0.0.3" {
+		t.Errorf("recently used entries were evicted: %+v", c.list())
+	}
+
+	// Setting a key again refreshes it in place
+	c.set(keys[2], "10.0.0.4")
+	if c.len() != 2 || c.get(keys[2]) != "10.0.0.4" {
+		t.Errorf("entries after update = %+v", c.list())
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	c := newCache(time.Millisecond, 10)
+	c.set("api.space.local", "10.0.0.1")
+	time.Sleep(5 * time.Millisecond)
+
+	if ip := c.get("api.space.local"); ip != "" {
+		t.Errorf("get() = %q after the TTL", ip)
+	}
+	if c.len() != 0 {
+		t.Errorf("expired entry kept after get, len = %d", c.len())
+	}
+}
+
+// TestCacheLoad queries the cache from many goroutines at once, as a stack
+// of many services does; run with -race to check the locking
+func TestCacheLoad(t *testing.T) {
+	const (
+		workers  = 32
+		queries  = 2000
+		hosts    = 500
+		capacity = 256
+	)
+	c := newCache(time.Minute, capacity)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < queries; i++ {
+				key := fmt.Sprintf("svc%d.space.local", (w*queries+i)%hosts)
+				switch {
+				case i%100 == 99:
+					c.flush(key)
+				case c.get(key) == "":
+					c.set(key, "10.0.0.1")
+				}
+				if i%500 == 0 {
+					c.list()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if n := c.len(); n > capacity+cacheShards {
+		t.Errorf("cache holds %d entries, over its capacity of %d", n, capacity)
+	}
+	for _, entry := range c.list() {
+		if entry.IP != "10.0.0.1" {
+			t.Errorf("entry %+v has the wrong address", entry)
+		}
+	}
+}
+
+// BenchmarkCacheParallel measures cache hits from concurrent queries
+func BenchmarkCacheParallel(b *testing.B) {
+	c := newCache(time.Minute, 1000)
+	keys := make([]string, 200)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("svc%d-a1b2c3.space.local", i)
+		c.set(keys[i], "172.18.0.4")
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			c.get(keys[i%len(keys)])
+			i++
+		}
+	})
+}
+
+// BenchmarkServerLookupParallel measures container lookups through the
+// server, mostly answered from the cache
+func BenchmarkServerLookupParallel(b *testing.B) {
+	ips := make(map[string]string)
+	for i := 0; i < 200; i++ {
+		ips[fmt.Sprintf("svc%d-a1b2c3", i)] = "172.18.0.4"
+	}
+	server, err := NewServer(Config{Domain: "space.local", Docker: &fakeDocker{ips: ips}, Logger: &recordingLogger{}})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			server.lookupContainer(fmt.Sprintf("svc%d-a1b2c3.space.local", i%200))
+			i++
+		}
+	})
+}
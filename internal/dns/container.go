@@ -0,0 +1,116 @@
+package dns
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PsFormat is the docker ps --format template printing the fields
+// ParseContainer reads: the container name and its compose labels
+const PsFormat = `{{.Names}}|{{.Label "com.docker.compose.service"}}|{{.Label "com.docker.compose.project"}}|{{.Label "com.docker.compose.project.working_dir"}}|{{.Label "com.docker.compose.container-number"}}`
+
+// Container is a running container as listed with PsFormat
+type Container struct {
+	Name    string
+	Service string // compose service, empty for containers not run by compose
+	Project string
+	WorkDir string
+	Number  int // replica index of a scaled service, 1 when not scaled
+}
+
+// ParseContainer parses one line printed by docker ps with PsFormat
+func ParseContainer(line string) Container {
+	fields := strings.SplitN(strings.TrimSpace(line), "|", 5)
+	for len(fields) < 5 {
+		fields = append(fields, "")
+	}
+
+	number, err := strconv.Atoi(fields[4])
+	if err != nil || number < 1 {
+		number = 1
+	}
+	return Container{
+		Name:    fields[0],
+		Service: fields[1],
+		Project: fields[2],
+		WorkDir: fields[3],
+		Number:  number,
+	}
+}
+
+// ParseContainers parses docker ps output printed with PsFormat, skipping
+// blank lines
+func ParseContainers(output string) []Container {
+	var containers []Container
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			containers = append(containers, ParseContainer(line))
+		}
+	}
+	return containers
+}
+
+// Labels returns the DNS labels of each compose container, by container
+// name. A container answers to its service with its replica index (api-2)
+// and, when set, to its container_name; the lowest running replica of a
+// service also answers to the bare service name. Containers not run by
+// compose have no labels.
+func Labels(containers []Container) map[string][]string {
+	// The lowest running replica of each service gets the bare name
+	first := make(map[string]Container)
+	for _, c := range containers {
+		key := c.Project + "/" + c.Service
+		if prev, ok := first[key]; !ok || c.Number < prev.Number {
+			first[key] = c
+		}
+	}
+
+	labels := make(map[string][]string)
+	for _, c := range containers {
+		if c.Service == "" {
+			continue
+		}
+
+		service := ServiceLabel(c.Service)
+		var own []string
+		if first[c.Project+"/"+c.Service].Name == c.Name {
+			own = append(own, service)
+		}
+		if c.Number > 1 {
+			own = append(own, ServiceLabel(service+"-"+strconv.Itoa(c.Number)))
+		}
+		if !hasGeneratedName(c) {
+			if label := ServiceLabel(c.Name); label != service {
+				own = append(own, label)
+			}
+		}
+		labels[c.Name] = own
+	}
+	return labels
+}
+
+// hasGeneratedName reports whether a container has the name compose gives
+// it without a container_name override (project_service_1 in compose v1)
+func hasGeneratedName(c Container) bool {
+	n := strconv.Itoa(c.Number)
+	return c.Name == c.Project+"-"+c.Service+"-"+n || c.Name == c.Project+"_"+c.Service+"_"+n
+}
+
+// FindByLabel returns the container answering to a DNS label. Candidates
+// are checked in name order, so the result is stable.
+func FindByLabel(containers []Container, label string) (Container, bool) {
+	label = strings.ToLower(label)
+	labels := Labels(containers)
+
+	sorted := append([]Container(nil), containers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, c := range sorted {
+		for _, own := range labels[c.Name] {
+			if own == label {
+				return c, true
+			}
+		}
+	}
+	return Container{}, false
+}
@@ -0,0 +1,59 @@
+package dns
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseContainers(t *testing.T) {
+	output := "shop-api-2|api|shop|/src/shop|2\nlegacy-db|db|shop|/src/shop|1\n\nbuildkit|||| \n"
+
+	want := []Container{
+		{Name: "shop-api-2", Service: "api", Project: "shop", WorkDir: "/src/shop", Number: 2},
+		{Name: "legacy-db", Service: "db", Project: "shop", WorkDir: "/src/shop", Number: 1},
+		{Name: "buildkit", Number: 1},
+	}
+	if got := ParseContainers(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseContainers() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	containers := []Container{
+		{Name: "shop-api-1", Service: "api", Project: "shop", Number: 1},
+		{Name: "shop-api-2", Service: "api", Project: "shop", Number: 2},
+		{Name: "shop_worker_3", Service: "worker", Project: "shop", Number: 3},
+		{Name: "Legacy_DB", Service: "db", Project: "shop", Number: 1},
+		{Name: "blog-api-1", Service: "api", Project: "blog", Number: 1},
+		{Name: "buildkit", Number: 1},
+	}
+
+	want := map[string][]string{
+		"shop-api-1":    {"api"},
+		"shop-api-2":    {"api-2"},
+		"shop_worker_3": {"worker", "worker-3"},
+		"Legacy_DB":     {"db", "legacy-db"},
+		"blog-api-1":    {"api"},
+	}
+	if got := Labels(containers); !reflect.DeepEqual(got, want) {
+		t.Errorf("Labels() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		label string
+		want  string
+	}{
+		{"api", "blog-api-1"},
+		{"api-2", "shop-api-2"},
+		{"worker", "shop_worker_3"},
+		{"Legacy-DB", "Legacy_DB"},
+	}
+	for _, tt := range tests {
+		if got, ok := FindByLabel(containers, tt.label); !ok || got.Name != tt.want {
+			t.Errorf("FindByLabel(%q) = %q, %v; want %q", tt.label, got.Name, ok, tt.want)
+		}
+	}
+	if _, ok := FindByLabel(containers, "api-3"); ok {
+		t.Error("FindByLabel() matched a replica that isn't running")
+	}
+}
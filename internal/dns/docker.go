@@ -22,35 +22,31 @@ func NewSimpleDockerClient(logger Logger) *SimpleDockerClient {
 	}
 }
 
-// GetContainerIP gets the IP address of a container
+// GetContainerIP gets the IP address of the container of a project that
+// answers to a DNS label (see Labels) or has that exact name
 func (c *SimpleDockerClient) GetContainerIP(ctx context.Context, projectName, containerName string) (string, error) {
 	// If projectName is empty, search all containers
 	if projectName == "" {
 		return c.findContainerIPAcrossProjects(ctx, containerName, "")
 	}
 
-	// Try exact match first
-	ip, err := c.getIP(ctx, projectName, containerName)
-	if err == nil && ip != "" {
-		return ip, nil
-	}
-
-	// Try with project prefix
-	ip, err = c.getIP(ctx, projectName, projectName+"-"+containerName)
-	if err == nil && ip != "" {
-		return ip, nil
+	containers, err := c.listContainers(ctx, "label=com.docker.compose.project="+projectName)
+	if err != nil {
+		return "", err
 	}
 
-	// Try with suffix variants
-	suffixes := []string{"-1", "_1"}
-	for _, suffix := range suffixes {
-		ip, err = c.getIP(ctx, projectName, projectName+"-"+containerName+suffix)
-		if err == nil && ip != "" {
-			return ip, nil
+	match, ok := FindByLabel(containers, containerName)
+	if !ok {
+		for _, container := range containers {
+			if container.Name == containerName {
+				match, ok = container, true
+			}
 		}
 	}
-
-	return "", fmt.Errorf("container not found: %s", containerName)
+	if !ok {
+		return "", fmt.Errorf("container not found: %s", containerName)
+	}
+	return c.getIP(ctx, projectName, match.Name)
 }
 
 // GetContainerIPByHash gets the IP address of a container matching both service name and directory hash
@@ -58,6 +54,21 @@ func (c *SimpleDockerClient) GetContainerIPByHash(ctx context.Context, serviceNa
 	return c.findContainerIPAcrossProjects(ctx, serviceName, hash)
 }
 
+// listContainers lists running containers with their compose labels,
+// optionally narrowed by a docker ps filter
+func (c *SimpleDockerClient) listContainers(ctx context.Context, filter string) ([]Container, error) {
+	args := []string{"ps", "--format", PsFormat}
+	if filter != "" {
+		args = append(args, "--filter", filter)
+	}
+
+	output, err := exec.CommandContext(ctx, "docker", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	return ParseContainers(string(output)), nil
+}
+
 // getIP gets the IP address of a container by exact name
 func (c *SimpleDockerClient) getIP(ctx context.Context, _, containerName string) (string, error) {
 	// Use docker inspect to get container IP
@@ -78,124 +89,77 @@ func (c *SimpleDockerClient) getIP(ctx context.Context, _, containerName string)
 	return ip, nil
 }
 
-// ListProjectContainers lists all containers for a project
+// ListProjectContainers returns the IP address of each DNS label (see
+// Labels) of a project's containers
 func (c *SimpleDockerClient) ListProjectContainers(ctx context.Context, projectName string) (map[string]string, error) {
-	// Use docker ps to list containers
-	cmd := exec.CommandContext(ctx, "docker", "ps",
-		"--filter", fmt.Sprintf("label=com.docker.compose.project=%s", projectName),
-		"--format", "{{.Names}}")
+	projectContainers, err := c.listContainers(ctx, "label=com.docker.compose.project="+projectName)
+	if err != nil {
+		return nil, err
+	}
 
-	output, err := cmd.Output()
+	names := make([]string, 0, len(projectContainers))
+	for _, container := range projectContainers {
+		names = append(names, container.Name)
+	}
+	ips, err := InspectContainerIPs(ctx, names)
 	if err != nil {
 		return nil, err
 	}
 
 	containers := make(map[string]string)
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-
-	for _, line := range lines {
-		if line == "" {
-			continue
-		}
-
-		// Get IP for this container
-		ip, err := c.getIP(ctx, projectName, line)
-		if err != nil {
-			c.logger.Warn("Failed to get IP for container", "container", line, "error", err)
+	for name, labels := range Labels(projectContainers) {
+		ip, ok := ips[name]
+		if !ok {
+			c.logger.Warn("Failed to get IP for container", "container", name)
 			continue
 		}
-
-		// Extract service name from container name
-		// Format: projectname-servicename-1
-		parts := strings.Split(line, "-")
-		if len(parts) >= 2 {
-			serviceName := strings.Join(parts[1:len(parts)-1], "-")
-			containers[serviceName] = ip
+		for _, label := range labels {
+			containers[label] = ip
 		}
 	}
 
 	return containers, nil
 }
 
-// findContainerIPAcrossProjects searches all containers for a matching service name and optional hash
-func (c *SimpleDockerClient) findContainerIPAcrossProjects(ctx context.Context, serviceName, hash string) (string, error) {
-	// List all running containers with their labels
-	cmd := exec.CommandContext(ctx, "docker", "ps", "--format", "{{.Names}}")
-	output, err := cmd.Output()
+// findContainerIPAcrossProjects searches all containers for one answering
+// to a DNS label, in the directory with the given hash unless hash is empty
+func (c *SimpleDockerClient) findContainerIPAcrossProjects(ctx context.Context, label, hash string) (string, error) {
+	// Compose labels carry the service, replica and working directory, so
+	// matching doesn't need an inspect per container
+	containers, err := c.listContainers(ctx, "")
 	if err != nil {
-		return "", fmt.Errorf("failed to list containers: %w", err)
+		return "", err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-
-	// If no hash specified, fall back to matching by service name only (legacy behavior)
+	// If no hash specified, match by label only (legacy behavior)
 	if hash == "" {
-		for _, containerName := range lines {
-			if containerName == "" {
-				continue
-			}
-			if c.matchesServiceName(containerName, serviceName) {
-				ip, err := c.getIP(ctx, "", containerName)
-				if err == nil && ip != "" {
-					return ip, nil
-				}
-			}
+		if match, ok := FindByLabel(containers, label); ok {
+			return c.getIP(ctx, "", match.Name)
 		}
-		return "", fmt.Errorf("container not found for service: %s", serviceName)
+		return "", fmt.Errorf("container not found for service: %s", label)
 	}
 
-	// Match by both service name AND directory hash
-	for _, containerName := range lines {
-		if containerName == "" {
+	// Match by both label AND directory hash
+	var inDir []Container
+	for _, container := range containers {
+		if container.WorkDir == "" {
+			c.logger.Debug("No working directory label on container", "container", container.Name)
 			continue
 		}
-
-		// Check service name first
-		if !c.matchesServiceName(containerName, serviceName) {
-			continue
-		}
-
-		// Get container's working directory and compute its hash
-		workDir, err := c.getContainerWorkDir(ctx, containerName)
-		if err != nil {
-			c.logger.Debug("Failed to get working dir for container", "container", containerName, "error", err)
-			continue
-		}
-
-		// Compute hash from working directory
-		containerHash := computeHash(workDir)
-		c.logger.Debug("Checking container hash", "container", containerName, "workDir", workDir, "hash", containerHash, "expected", hash)
-
-		if containerHash == hash {
-			ip, err := c.getIP(ctx, "", containerName)
-			if err == nil && ip != "" {
-				c.logger.Info("Found container by hash", "service", serviceName, "hash", hash, "container", containerName, "ip", ip)
-				return ip, nil
-			}
+		if computeHash(container.WorkDir) == hash {
+			inDir = append(inDir, container)
 		}
 	}
 
-	return "", fmt.Errorf("container not found for service %s with hash %s", serviceName, hash)
-}
-
-// getContainerWorkDir gets the working directory of a container from Docker labels
-func (c *SimpleDockerClient) getContainerWorkDir(ctx context.Context, containerName string) (string, error) {
-	// Get the com.docker.compose.project.working_dir label
-	cmd := exec.CommandContext(ctx, "docker", "inspect",
-		"--format", "{{index .Config.Labels \"com.docker.compose.project.working_dir\"}}",
-		containerName)
-
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-
-	workDir := strings.TrimSpace(string(output))
-	if workDir == "" {
-		return "", fmt.Errorf("no working directory label found")
+	if match, ok := FindByLabel(inDir, label); ok {
+		ip, err := c.getIP(ctx, "", match.Name)
+		if err == nil && ip != "" {
+			c.logger.Info("Found container by hash", "service", label, "hash", hash, "container", match.Name, "ip", ip)
+			return ip, nil
+		}
 	}
 
-	return workDir, nil
+	return "", fmt.Errorf("container not found for service %s with hash %s", label, hash)
 }
 
 // computeHash computes a 6-character hash from a directory path
@@ -218,31 +182,3 @@ func computeHash(dirPath string) string {
 	hexHash := hex.EncodeToString(hashBytes)
 	return hexHash[:6]
 }
-
-// matchesServiceName checks if a container name matches the service name pattern
-func (c *SimpleDockerClient) matchesServiceName(containerName, serviceName string) bool {
-	// Container format: projectname-servicename-1 or projectname-servicename_1
-	// Service format: servicename (without hash, hash is only in DNS domain)
-
-	// Check if container name ends with -servicename-1
-	if strings.HasSuffix(containerName, "-"+serviceName+"-1") {
-		return true
-	}
-
-	// Check if container name ends with -servicename_1
-	if strings.HasSuffix(containerName, "-"+serviceName+"_1") {
-		return true
-	}
-
-	// Check if container name ends with _servicename-1 (less common but possible)
-	if strings.HasSuffix(containerName, "_"+serviceName+"-1") {
-		return true
-	}
-
-	// Check if container name ends with _servicename_1
-	if strings.HasSuffix(containerName, "_"+serviceName+"_1") {
-		return true
-	}
-
-	return false
-}
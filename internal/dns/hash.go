@@ -4,7 +4,6 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"path/filepath"
-	"strings"
 )
 
 // GenerateDirectoryHash creates a 6-character hash from a directory path.
@@ -46,40 +45,30 @@ func GenerateDirectoryHash(dirPath string) string {
 // Example:
 //   GenerateHashedDomainName("web", "/home/user/project", "space.local")
 //   -> "web-a1b2c3.space.local"
+//
+// The service name is normalized to a DNS label (see ServiceLabel).
 func GenerateHashedDomainName(serviceName, dirPath, domain string) string {
 	hash := GenerateDirectoryHash(dirPath)
-	return serviceName + "-" + hash + "." + domain
+	return ServiceLabel(serviceName) + "-" + hash + "." + domain
 }
 
 // ExtractServiceNameFromHashedDomain extracts the service name from a hashed domain.
-// This is useful for reverse lookups in the DNS server.
+// This is useful for reverse lookups in the DNS server. A name without a
+// hash yields its label, and a name that cannot name a service (see
+// ServiceHost) yields "".
 //
 // Example:
 //   ExtractServiceNameFromHashedDomain("web-a1b2c3.space.local", "space.local")
 //   -> "web"
 func ExtractServiceNameFromHashedDomain(domain, baseDomain string) string {
-	// Remove trailing dot first if present
-	domain = strings.TrimSuffix(domain, ".")
-
-	// Remove the base domain suffix
-	domain = strings.TrimSuffix(domain, "."+baseDomain)
-
-	// Find the last dash (before the hash)
-	lastDash := strings.LastIndex(domain, "-")
-	if lastDash == -1 {
-		// No hash present, return as-is
-		return domain
+	label, ok := ServiceHost(domain, baseDomain)
+	if !ok {
+		return ""
 	}
-
-	// Check if what follows the dash looks like a 6-char hex hash
-	potentialHash := domain[lastDash+1:]
-	if len(potentialHash) == 6 && isHexString(potentialHash) {
-		// This looks like a hash, return the service name part
-		return domain[:lastDash]
+	if service, _, ok := splitHashedLabel(label); ok {
+		return service
 	}
-
-	// No hash pattern detected, return full domain
-	return domain
+	return label
 }
 
 // ExtractHashFromHashedDomain extracts the hash from a hashed domain.
@@ -90,27 +79,12 @@ func ExtractServiceNameFromHashedDomain(domain, baseDomain string) string {
 //	ExtractHashFromHashedDomain("web-a1b2c3.space.local", "space.local")
 //	-> "a1b2c3"
 func ExtractHashFromHashedDomain(domain, baseDomain string) string {
-	// Remove trailing dot first if present
-	domain = strings.TrimSuffix(domain, ".")
-
-	// Remove the base domain suffix
-	domain = strings.TrimSuffix(domain, "."+baseDomain)
-
-	// Find the last dash (before the hash)
-	lastDash := strings.LastIndex(domain, "-")
-	if lastDash == -1 {
-		// No hash present
+	label, ok := ServiceHost(domain, baseDomain)
+	if !ok {
 		return ""
 	}
-
-	// Check if what follows the dash looks like a 6-char hex hash
-	potentialHash := domain[lastDash+1:]
-	if len(potentialHash) == 6 && isHexString(potentialHash) {
-		return potentialHash
-	}
-
-	// No hash pattern detected
-	return ""
+	_, hash, _ := splitHashedLabel(label)
+	return hash
 }
 
 // isHexString checks if a string contains only hexadecimal characters
@@ -125,18 +99,12 @@ func isHexString(s string) bool {
 
 // ValidateHashedDomain validates if a domain has the correct hashed format.
 // Returns true if the domain matches the pattern: {service}-{hash}.{baseDomain}
+// with a single valid label in front of baseDomain.
 func ValidateHashedDomain(domain, baseDomain string) bool {
-	// Remove the base domain suffix
-	domain = strings.TrimSuffix(domain, "."+baseDomain)
-	domain = strings.TrimSuffix(domain, ".")
-
-	// Find the last dash
-	lastDash := strings.LastIndex(domain, "-")
-	if lastDash == -1 {
+	label, ok := ServiceHost(domain, baseDomain)
+	if !ok {
 		return false
 	}
-
-	// Check if what follows is a 6-char hex hash
-	potentialHash := domain[lastDash+1:]
-	return len(potentialHash) == 6 && isHexString(potentialHash)
+	_, _, ok = splitHashedLabel(label)
+	return ok
 }
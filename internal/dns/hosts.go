@@ -0,0 +1,182 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// HostEntry maps an IP address to the hostnames that resolve to it
+type HostEntry struct {
+	IP        string
+	Hostnames []string
+}
+
+// HostsFile manages a delimited block of entries in /etc/hosts. It is the
+// fallback for machines where the resolver configuration cannot be changed:
+// each project owns one block, rewritten whenever its containers change.
+type HostsFile struct {
+	path    string
+	project string
+	logger  Logger
+}
+
+// NewHostsFile creates a hosts file manager for project's block
+func NewHostsFile(project string, logger Logger) *HostsFile {
+	return &HostsFile{
+		path:    "/etc/hosts",
+		project: project,
+		logger:  logger,
+	}
+}
+
+// File returns the path of the hosts file
+func (h *HostsFile) File() string {
+	return h.path
+}
+
+// Entries returns the entries currently in the project's block
+func (h *HostsFile) Entries() ([]HostEntry, error) {
+	content, err := os.ReadFile(h.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts file: %w", err)
+	}
+	return parseHostsBlock(string(content), h.project), nil
+}
+
+// Update replaces the project's block with entries. Nothing is written when
+// the block is already up to date; an empty entries list removes the block.
+func (h *HostsFile) Update(ctx context.Context, entries []HostEntry) error {
+	content, err := os.ReadFile(h.path)
+	if err != nil {
+		return fmt.Errorf("failed to read hosts file: %w", err)
+	}
+
+	updated := replaceHostsBlock(string(content), h.project, entries)
+	if updated == string(content) {
+		h.logger.Info("Hosts file already up to date", "file", h.path)
+		return nil
+	}
+
+	return h.write(ctx, updated)
+}
+
+// Remove deletes the project's block
+func (h *HostsFile) Remove(ctx context.Context) error {
+	return h.Update(ctx, nil)
+}
+
+// write installs content as the hosts file with sudo and flushes the DNS cache
+func (h *HostsFile) write(ctx context.Context, content string) error {
+	tmpFile, err := os.CreateTemp("", "space-hosts-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp hosts file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write temp hosts file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write temp hosts file: %w", err)
+	}
+
+	h.logger.Info("Updating hosts file", "file", h.path, "project", h.project)
+
+	// cp keeps the owner and mode of the existing file
+	cmd := exec.CommandContext(ctx, "sudo", "cp", tmpFile.Name(), h.path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to update hosts file: %w", err)
+	}
+
+	flushDNSCache(ctx, h.logger)
+	return nil
+}
+
+// hostsBlockMarkers returns the lines delimiting project's block
+func hostsBlockMarkers(project string) (begin, end string) {
+	return fmt.Sprintf("# >>> space %s >>>", project), fmt.Sprintf("# <<< space %s <<<", project)
+}
+
+// renderHostsBlock renders project's block, without a trailing newline
+func renderHostsBlock(project string, entries []HostEntry) string {
+	begin, end := hostsBlockMarkers(project)
+
+	lines := []string{begin, "# Managed by space, removed by 'space down'. Do not edit."}
+	for _, entry := range entries {
+		if entry.IP == "" || len(entry.Hostnames) == 0 {
+			continue
+		}
+		lines = append(lines, entry.IP+"\t"+strings.Join(entry.Hostnames, " "))
+	}
+	lines = append(lines, end)
+
+	return strings.Join(lines, "\n")
+}
+
+// replaceHostsBlock returns content with project's block replaced by one
+// holding entries, appended when there was none. With no entries the block
+// is removed. Lines outside the block are left untouched.
+func replaceHostsBlock(content, project string, entries []HostEntry) string {
+	begin, end := hostsBlockMarkers(project)
+
+	var kept []string
+	inBlock := false
+	at := -1
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		switch {
+		case strings.TrimSpace(line) == begin:
+			inBlock = true
+			if at < 0 {
+				at = len(kept)
+			}
+		case inBlock && strings.TrimSpace(line) == end:
+			inBlock = false
+		case !inBlock:
+			kept = append(kept, line)
+		}
+	}
+
+	if len(entries) > 0 {
+		block := renderHostsBlock(project, entries)
+		if at < 0 {
+			kept = append(kept, block)
+		} else {
+			kept = append(kept[:at], append([]string{block}, kept[at:]...)...)
+		}
+	}
+
+	if len(kept) == 1 && kept[0] == "" {
+		return ""
+	}
+	return strings.Join(kept, "\n") + "\n"
+}
+
+// parseHostsBlock extracts the entries of project's block from content
+func parseHostsBlock(content, project string) []HostEntry {
+	begin, end := hostsBlockMarkers(project)
+
+	var entries []HostEntry
+	inBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == begin:
+			inBlock = true
+		case trimmed == end:
+			inBlock = false
+		case inBlock && trimmed != "" && !strings.HasPrefix(trimmed, "#"):
+			fields := strings.Fields(trimmed)
+			if len(fields) >= 2 {
+				entries = append(entries, HostEntry{IP: fields[0], Hostnames: fields[1:]})
+			}
+		}
+	}
+	return entries
+}
@@ -0,0 +1,77 @@
+package dns
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReplaceHostsBlock(t *testing.T) {
+	entries := []HostEntry{
+		{IP: "192.168.97.2", Hostnames: []string{"api-a1b2c3.space.local"}},
+		{IP: "192.168.97.3", Hostnames: []string{"web-a1b2c3.space.local", "web-a1b2c3.shop.test"}},
+	}
+	block := "# >>> space shop >>>\n" +
+		"# Managed by space, removed by 'space down'. Do not edit.\n" +
+		"192.168.97.2\tapi-a1b2c3.space.local\n" +
+		"192.168.97.3\tweb-a1b2c3.space.local web-a1b2c3.shop.test\n" +
+		"# <<< space shop <<<\n"
+
+	tests := []struct {
+		name    string
+		content string
+		entries []HostEntry
+		want    string
+	}{
+		{
+			name:    "append to hosts file",
+			content: "127.0.0.1\tlocalhost\n",
+			entries: entries,
+			want:    "127.0.0.1\tlocalhost\n" + block,
+		},
+		{
+			name:    "replace in place",
+			content: "127.0.0.1\tlocalhost\n# >>> space shop >>>\n10.0.0.9\told.space.local\n# <<< space shop <<<\n::1\tlocalhost\n",
+			entries: entries,
+			want:    "127.0.0.1\tlocalhost\n" + block + "::1\tlocalhost\n",
+		},
+		{
+			name:    "remove block",
+			content: "127.0.0.1\tlocalhost\n" + block,
+			want:    "127.0.0.1\tlocalhost\n",
+		},
+		{
+			name:    "other projects untouched",
+			content: "# >>> space blog >>>\n10.0.0.5\tweb-ffffff.space.local\n# <<< space blog <<<\n",
+			want:    "# >>> space blog >>>\n10.0.0.5\tweb-ffffff.space.local\n# <<< space blog <<<\n",
+		},
+		{
+			name:    "nothing to remove",
+			content: "",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := replaceHostsBlock(tt.content, "shop", tt.entries)
+			if got != tt.want {
+				t.Errorf("replaceHostsBlock() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHostsBlock(t *testing.T) {
+	entries := []HostEntry{
+		{IP: "192.168.97.2", Hostnames: []string{"api-a1b2c3.space.local"}},
+		{IP: "192.168.97.3", Hostnames: []string{"web-a1b2c3.space.local", "web-a1b2c3.shop.test"}},
+	}
+	content := replaceHostsBlock("127.0.0.1\tlocalhost\n", "shop", entries)
+
+	if got := parseHostsBlock(content, "shop"); !reflect.DeepEqual(got, entries) {
+		t.Errorf("parseHostsBlock() = %v, want %v", got, entries)
+	}
+	if got := parseHostsBlock(content, "blog"); len(got) != 0 {
+		t.Errorf("parseHostsBlock() for another project = %v, want none", got)
+	}
+}
@@ -0,0 +1,96 @@
+package dns
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+const (
+	// inspectBatchSize is how many containers one docker inspect covers
+	inspectBatchSize = 50
+
+	// maxDockerInspects caps the docker inspect processes run at once
+	maxDockerInspects = 4
+)
+
+// runDockerInspect runs docker inspect and returns its standard output,
+// replaced in tests
+var runDockerInspect = func(ctx context.Context, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, "docker", append([]string{"inspect"}, args...)...).Output()
+}
+
+// InspectContainerIPs returns the IP address of each named container,
+// inspecting them in batches with a bounded number of docker processes.
+// Containers that don't exist or have no address are left out; an error is
+// returned only when no batch could be inspected.
+func InspectContainerIPs(ctx context.Context, names []string) (map[string]string, error) {
+	seen := make(map[string]bool, len(names))
+	var unique []string
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		ips      = make(map[string]string, len(unique))
+		firstErr error
+		failed   int
+		batches  int
+	)
+	slots := make(chan struct{}, maxDockerInspects)
+
+	for start := 0; start < len(unique); start += inspectBatchSize {
+		batch := unique[start:min(start+inspectBatchSize, len(unique))]
+		batches++
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			args := append([]string{"--format", "{{.Name}}|{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}"}, batch...)
+			out, err := runDockerInspect(ctx, args...)
+
+			mu.Lock()
+			defer mu.Unlock()
+			// docker inspect exits non-zero when any container is missing
+			// but still prints the ones it found
+			if err != nil && len(out) == 0 {
+				failed++
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			for name, ip := range parseInspectIPs(string(out)) {
+				ips[name] = ip
+			}
+		}()
+	}
+	wg.Wait()
+
+	if batches > 0 && failed == batches {
+		return nil, firstErr
+	}
+	return ips, nil
+}
+
+// parseInspectIPs parses "/name|ip" lines printed by docker inspect
+func parseInspectIPs(out string) map[string]string {
+	ips := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		name, ip, ok := strings.Cut(strings.TrimSpace(line), "|")
+		if !ok || ip == "" {
+			continue
+		}
+		ips[strings.TrimPrefix(name, "/")] = ip
+	}
+	return ips
+}
@@ -0,0 +1,87 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInspectContainerIPs(t *testing.T) {
+	old := runDockerInspect
+	defer func() { runDockerInspect = old }()
+
+	var (
+		mu             sync.Mutex
+		calls, running int
+		maxRunning     int
+	)
+	runDockerInspect = func(_ context.Context, args ...string) ([]byte, error) {
+		mu.Lock()
+		calls++
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+
+		names := args[2:]
+		var out strings.Builder
+		for _, name := range names {
+			if name == "gone" {
+				continue
+			}
+			fmt.Fprintf(&out, "/%s|10.0.0.%s\n", name, strings.TrimPrefix(name, "c"))
+		}
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if strings.Contains(strings.Join(names, " "), "gone") {
+			return []byte(out.String()), errors.New("exit status 1")
+		}
+		return []byte(out.String()), nil
+	}
+
+	var names []string
+	for i := 0; i < 3*inspectBatchSize*maxDockerInspects/2; i++ {
+		names = append(names, fmt.Sprintf("c%d", i))
+	}
+	names = append(names, "c1", "gone")
+
+	ips, err := InspectContainerIPs(context.Background(), names)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantBatches := (len(names) - 1 + inspectBatchSize - 1) / inspectBatchSize
+	if calls != wantBatches {
+		t.Errorf("docker inspect ran %d times, want %d batches", calls, wantBatches)
+	}
+	if maxRunning > maxDockerInspects {
+		t.Errorf("%d inspects ran at once, want at most %d", maxRunning, maxDockerInspects)
+	}
+	if len(ips) != len(names)-2 || ips["c7"] != "10.0.0.7" {
+		t.Errorf("got %d addresses, c7 = %q", len(ips), ips["c7"])
+	}
+	if _, ok := ips["gone"]; ok {
+		t.Error("missing container should be left out")
+	}
+}
+
+func TestInspectContainerIPsFails(t *testing.T) {
+	old := runDockerInspect
+	defer func() { runDockerInspect = old }()
+	runDockerInspect = func(context.Context, ...string) ([]byte, error) {
+		return nil, errors.New("docker not running")
+	}
+
+	if _, err := InspectContainerIPs(context.Background(), []string{"web"}); err == nil {
+		t.Error("expected an error when no container could be inspected")
+	}
+	if ips, err := InspectContainerIPs(context.Background(), nil); err != nil || len(ips) != 0 {
+		t.Errorf("no names = %v, %v", ips, err)
+	}
+}
@@ -0,0 +1,58 @@
+package dns
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+const (
+	// MaxLabelLength is the RFC 1123 limit for a single DNS label
+	MaxLabelLength = 63
+
+	// truncationHashLength is the length of the hash appended to truncated names
+	truncationHashLength = 6
+)
+
+// NormalizeLabel turns a name into a valid RFC 1123 DNS label: lowercase
+// letters, digits and single hyphens, not starting or ending with a hyphen,
+// at most 63 characters. Longer names are truncated with a short hash of the original
+// so distinct names stay distinct. It reports whether truncation occurred.
+func NormalizeLabel(name string) (string, bool) {
+	var b strings.Builder
+	for _, c := range strings.ToLower(name) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			b.WriteRune(c)
+		} else if !strings.HasSuffix(b.String(), "-") {
+			b.WriteRune('-')
+		}
+	}
+
+	label := strings.Trim(b.String(), "-")
+	if label == "" {
+		label = "x"
+	}
+	return Truncate(label, name, MaxLabelLength)
+}
+
+// ServiceLabel returns the DNS label used for a service in hashed domains.
+// It leaves room for the "-{hash}" directory suffix within 63 characters.
+func ServiceLabel(service string) string {
+	label, _ := NormalizeLabel(service)
+	label, _ = Truncate(label, service, MaxLabelLength-1-truncationHashLength)
+	return label
+}
+
+// Truncate shortens name to max characters, replacing the tail with "-" and
+// a short hash of original. Names within the limit are returned unchanged.
+func Truncate(name, original string, max int) (string, bool) {
+	if len(name) <= max {
+		return name, false
+	}
+
+	sum := sha256.Sum256([]byte(original))
+	suffix := hex.EncodeToString(sum[:])[:truncationHashLength]
+
+	head := strings.TrimRight(name[:max-1-len(suffix)], "-_")
+	return head + "-" + suffix, true
+}
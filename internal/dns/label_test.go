@@ -0,0 +1,80 @@
+package dns
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLabel(t *testing.T) {
+	long := "feature-" + strings.Repeat("really-long-descriptive-name-", 3)
+
+	tests := []struct {
+		name          string
+		input         string
+		want          string
+		wantTruncated bool
+	}{
+		{name: "valid", input: "api", want: "api"},
+		{name: "uppercase and underscores", input: "My_Service", want: "my-service"},
+		{name: "slashes and dots", input: "feature/v1.2", want: "feature-v1-2"},
+		{name: "separator runs", input: "api__v2", want: "api-v2"},
+		{name: "leading and trailing separators", input: "_-api-_", want: "api"},
+		{name: "only separators", input: "__", want: "x"},
+		{name: "exactly 63", input: strings.Repeat("a", 63), want: strings.Repeat("a", 63)},
+		{name: "too long", input: long, wantTruncated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := NormalizeLabel(tt.input)
+			if truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", truncated, tt.wantTruncated)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("NormalizeLabel(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if len(got) > MaxLabelLength {
+				t.Errorf("label %q is %d characters", got, len(got))
+			}
+			if strings.HasPrefix(got, "-") || strings.HasSuffix(got, "-") {
+				t.Errorf("label %q starts or ends with a hyphen", got)
+			}
+		})
+	}
+}
+
+func TestNormalizeLabelTruncationIsStable(t *testing.T) {
+	a := strings.Repeat("x", 70) + "-one"
+	b := strings.Repeat("x", 70) + "-two"
+
+	la, _ := NormalizeLabel(a)
+	lb, _ := NormalizeLabel(b)
+	if la == lb {
+		t.Errorf("distinct names truncated to the same label %q", la)
+	}
+	if again, _ := NormalizeLabel(a); again != la {
+		t.Errorf("truncation not deterministic: %q != %q", again, la)
+	}
+}
+
+func TestServiceLabelFitsHashedDomain(t *testing.T) {
+	service := strings.Repeat("worker-", 12)
+	dirHash := "a1b2c3"
+
+	label := ServiceLabel(service)
+	domain := label + "-" + dirHash + ".space.local"
+	if first := strings.Split(domain, ".")[0]; len(first) > MaxLabelLength {
+		t.Fatalf("label %q is %d characters", first, len(first))
+	}
+
+	if got := ExtractServiceNameFromHashedDomain(domain, "space.local"); got != label {
+		t.Errorf("ExtractServiceNameFromHashedDomain() = %q, want %q", got, label)
+	}
+	if got := ExtractHashFromHashedDomain(domain, "space.local"); got != dirHash {
+		t.Errorf("ExtractHashFromHashedDomain() = %q, want %q", got, dirHash)
+	}
+
+	if got := ServiceLabel("api"); got != "api" {
+		t.Errorf("ServiceLabel(api) = %q", got)
+	}
+}
@@ -0,0 +1,61 @@
+package dns
+
+import "strings"
+
+// MaxNameLength is the RFC 1035 limit for a hostname in text form
+const MaxNameLength = 253
+
+// punycodePrefix starts the ACE form of an internationalized label.
+// NormalizeLabel never produces it, so no service answers to one.
+const punycodePrefix = "xn--"
+
+// ValidLabel reports whether label is an RFC 1123 label: 1 to 63 letters,
+// digits and hyphens, not starting or ending with a hyphen
+func ValidLabel(label string) bool {
+	if label == "" || len(label) > MaxLabelLength || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// ServiceHost returns the label in front of baseDomain in hostname, the
+// part naming a service, and reports whether hostname can name a service:
+// a single valid label directly under baseDomain, within the length limit
+// and not punycode. A trailing dot and the case of baseDomain are ignored.
+func ServiceHost(hostname, baseDomain string) (string, bool) {
+	hostname = strings.TrimSuffix(hostname, ".")
+	if len(hostname) > MaxNameLength || baseDomain == "" {
+		return "", false
+	}
+
+	cut := len(hostname) - len(baseDomain) - 1
+	if cut <= 0 || hostname[cut] != '.' || !strings.EqualFold(hostname[cut+1:], baseDomain) {
+		return "", false
+	}
+
+	label := hostname[:cut]
+	if !ValidLabel(label) || strings.HasPrefix(strings.ToLower(label), punycodePrefix) {
+		return "", false
+	}
+	return label, true
+}
+
+// splitHashedLabel splits a service label into the service and the
+// 6-character directory hash following its last hyphen
+func splitHashedLabel(label string) (service, hash string, ok bool) {
+	lastDash := strings.LastIndexByte(label, '-')
+	if lastDash <= 0 || label[lastDash-1] == '-' {
+		return "", "", false
+	}
+	hash = label[lastDash+1:]
+	if len(hash) != 6 || !isHexString(hash) {
+		return "", "", false
+	}
+	return label[:lastDash], hash, true
+}
@@ -0,0 +1,147 @@
+package dns
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestServiceHost(t *testing.T) {
+	tests := []struct {
+		hostname string
+		want     string
+		ok       bool
+	}{
+		{hostname: "web-a1b2c3.space.local", want: "web-a1b2c3", ok: true},
+		{hostname: "web.space.local.", want: "web", ok: true},
+		{hostname: "Web.SPACE.local", want: "Web", ok: true},
+		{hostname: "space.local"},
+		{hostname: ".space.local"},
+		{hostname: "web..space.local"},
+		{hostname: "a.web.space.local"},
+		{hostname: "web.other.local"},
+		{hostname: "webspace.local"},
+		{hostname: "-web.space.local"},
+		{hostname: "web-.space.local"},
+		{hostname: "we_b.space.local"},
+		{hostname: "wéb.space.local"},
+		{hostname: "xn--wb-9ja.space.local"},
+		{hostname: strings.Repeat("a", 64) + ".space.local"},
+		{hostname: strings.Repeat("a", 63) + ".space.local", want: strings.Repeat("a", 63), ok: true},
+		{hostname: ""},
+	}
+	for _, tt := range tests {
+		got, ok := ServiceHost(tt.hostname, "space.local")
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ServiceHost(%q) = %q, %v; want %q, %v", tt.hostname, got, ok, tt.want, tt.ok)
+		}
+	}
+	if _, ok := ServiceHost("web.", ""); ok {
+		t.Error("ServiceHost() accepted an empty base domain")
+	}
+}
+
+func TestHashedDomainRejectsMalformed(t *testing.T) {
+	for _, domain := range []string{
+		"a.web-a1b2c3.space.local",
+		"web--a1b2c3.space.local",
+		"-a1b2c3.space.local",
+		"xn--web-a1b2c3.space.local",
+		"web-a1b2c3.other.local",
+		strings.Repeat("a", 60) + "-a1b2c3.space.local",
+	} {
+		if ValidateHashedDomain(domain, "space.local") {
+			t.Errorf("ValidateHashedDomain(%q) = true", domain)
+		}
+		if got := ExtractHashFromHashedDomain(domain, "space.local"); got != "" {
+			t.Errorf("ExtractHashFromHashedDomain(%q) = %q", domain, got)
+		}
+	}
+}
+
+// countingDocker counts the lookups reaching docker
+type countingDocker struct {
+	fakeDocker
+	lookups int
+}
+
+func (d *countingDocker) GetContainerIP(ctx context.Context, project, name string) (string, error) {
+	d.lookups++
+	return d.fakeDocker.GetContainerIP(ctx, project, name)
+}
+
+func (d *countingDocker) GetContainerIPByHash(ctx context.Context, service, hash string) (string, error) {
+	d.lookups++
+	return d.fakeDocker.GetContainerIPByHash(ctx, service, hash)
+}
+
+func TestServerRejectsMalformedNames(t *testing.T) {
+	docker := &countingDocker{fakeDocker: fakeDocker{ips: map[string]string{"api-a1b2c3": "172.18.0.4"}}}
+	server, err := NewServer(Config{Domain: "space.local", Docker: docker, Logger: &recordingLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"space.local", "x.api-a1b2c3.space.local", "api..space.local", "xn--pi-a1b2c3.space.local", "api-a1b2c3.example.com"} {
+		if ip := server.lookupContainer(name); ip != "" {
+			t.Errorf("lookupContainer(%q) = %q", name, ip)
+		}
+	}
+	if docker.lookups != 0 {
+		t.Errorf("%d malformed names reached docker", docker.lookups)
+	}
+
+	if ip := server.lookupContainer("api-a1b2c3.space.local"); ip != "172.18.0.4" || docker.lookups != 1 {
+		t.Errorf("lookupContainer() = %q after %d lookups", ip, docker.lookups)
+	}
+}
+
+func FuzzHashedDomain(f *testing.F) {
+	for _, seed := range []string{
+		"web-a1b2c3.space.local", "web.space.local.", "-a1b2c3.space.local", ".space.local",
+		"a.b-a1b2c3.space.local", "xn--bcher-kva.space.local", "web-a1b2c3", "", ".", "-", "..",
+		strings.Repeat("a-", 200) + "a1b2c3.space.local",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, domain string) {
+		const base = "space.local"
+		service := ExtractServiceNameFromHashedDomain(domain, base)
+		hash := ExtractHashFromHashedDomain(domain, base)
+		label, ok := ServiceHost(domain, base)
+
+		if !ok {
+			if service != "" || hash != "" || ValidateHashedDomain(domain, base) {
+				t.Fatalf("%q is no service host but gave service %q, hash %q", domain, service, hash)
+			}
+			return
+		}
+		if len(label) > MaxLabelLength || strings.ContainsAny(label, "._") {
+			t.Fatalf("ServiceHost(%q) = %q, not a single valid label", domain, label)
+		}
+		if ValidateHashedDomain(domain, base) {
+			if label != service+"-"+hash || !ValidLabel(service) || len(hash) != 6 {
+				t.Fatalf("%q split into service %q and hash %q", domain, service, hash)
+			}
+		} else if service != label || hash != "" {
+			t.Fatalf("unhashed %q gave service %q, hash %q", domain, service, hash)
+		}
+	})
+}
+
+func FuzzGeneratedNames(f *testing.F) {
+	for _, seed := range []string{"web", "API_Server", "my service", "---", "", "ünïcode", strings.Repeat("x", 100)} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, service string) {
+		name := GenerateHashedDomainName(service, "/src/project", "space.local")
+		if !ValidateHashedDomain(name, "space.local") {
+			t.Fatalf("generated name %q for %q does not validate", name, service)
+		}
+		if got := ExtractServiceNameFromHashedDomain(name, "space.local"); got != ServiceLabel(service) {
+			t.Fatalf("service of %q = %q, want %q", name, got, ServiceLabel(service))
+		}
+	})
+}
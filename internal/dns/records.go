@@ -0,0 +1,164 @@
+package dns
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Record is a static record served by the DNS server: an A record when IP
+// is set, otherwise a CNAME to Target
+type Record struct {
+	Name   string `json:"name"`
+	IP     string `json:"ip,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+// records holds static records per owner, so each project can replace its
+// own without touching the others
+type records struct {
+	mu      sync.RWMutex
+	byOwner map[string]map[string]Record
+	owners  []string // Keys of byOwner in sort order, kept on writes so lookups don't sort
+}
+
+func newRecords() *records {
+	return &records{byOwner: make(map[string]map[string]Record)}
+}
+
+// SetRecords replaces the static records of owner, usually a project name;
+// an empty list removes them. When owners declare the same name, the
+// first owner in sort order wins.
+func (s *Server) SetRecords(owner string, list []Record) {
+	s.records.mu.Lock()
+	defer s.records.mu.Unlock()
+
+	if len(list) == 0 {
+		delete(s.records.byOwner, owner)
+		s.records.sortOwners()
+		return
+	}
+
+	byName := make(map[string]Record, len(list))
+	for _, rec := range list {
+		rec.Name = normalizeName(rec.Name)
+		rec.Target = normalizeName(rec.Target)
+		byName[rec.Name] = rec
+	}
+	s.records.byOwner[owner] = byName
+	s.records.sortOwners()
+	s.logger.Info("Static DNS records updated", "owner", owner, "records", len(byName))
+}
+
+// Records returns every static record, sorted by name
+func (s *Server) Records() []Record {
+	s.records.mu.RLock()
+	defer s.records.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var list []Record
+	for _, owner := range s.records.owners {
+		for name, rec := range s.records.byOwner[owner] {
+			if !seen[name] {
+				seen[name] = true
+				list = append(list, rec)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// lookupRecord returns the static record for hostname, if any
+func (s *Server) lookupRecord(hostname string) (Record, bool) {
+	hostname = normalizeName(hostname)
+
+	s.records.mu.RLock()
+	defer s.records.mu.RUnlock()
+	for _, owner := range s.records.owners {
+		if rec, ok := s.records.byOwner[owner][hostname]; ok {
+			return rec, true
+		}
+	}
+	return Record{}, false
+}
+
+// sortOwners refreshes the sorted owners after a write; callers hold mu
+func (r *records) sortOwners() {
+	r.owners = r.owners[:0]
+	for owner := range r.byOwner {
+		r.owners = append(r.owners, owner)
+	}
+	sort.Strings(r.owners)
+}
+
+// withRecords answers queries for static records and passes every other
+// query to next
+func (s *Server) withRecords(next dns.Handler) dns.Handler {
+	return dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		if len(r.Question) != 1 {
+			next.ServeDNS(w, r)
+			return
+		}
+		rec, ok := s.lookupRecord(r.Question[0].Name)
+		if !ok {
+			next.ServeDNS(w, r)
+			return
+		}
+
+		m := new(dns.Msg)
+		m.SetReply(r)
+		m.Authoritative = true
+		m.Answer = s.answerRecord(r.Question[0], rec)
+		if err := w.WriteMsg(m); err != nil {
+			s.logger.Debug("Failed to write DNS response", "error", err)
+		}
+	})
+}
+
+// answerRecord returns the answer to q from a static record. Other query
+// types than A get an empty answer for an address record; an alias is
+// followed when its target is a container hostname the server resolves.
+func (s *Server) answerRecord(q dns.Question, rec Record) []dns.RR {
+	start := time.Now()
+	hostname := strings.TrimSuffix(q.Name, ".")
+
+	if rec.IP != "" {
+		if q.Qtype != dns.TypeA {
+			return nil
+		}
+		s.logQuery(hostname, q.Qtype, rec.IP, "static", start, nil)
+		return []dns.RR{aRecord(q.Name, rec.IP)}
+	}
+
+	answer := []dns.RR{&dns.CNAME{
+		Hdr: dns.RR_Header{
+			Name:   q.Name,
+			Rrtype: dns.TypeCNAME,
+			Class:  dns.ClassINET,
+			Ttl:    30,
+		},
+		Target: dns.Fqdn(rec.Target),
+	}}
+	s.logQuery(hostname, q.Qtype, rec.Target, "static", start, nil)
+
+	if q.Qtype == dns.TypeA && s.serves(rec.Target) {
+		if ip := s.lookupContainer(rec.Target); ip != "" {
+			answer = append(answer, aRecord(dns.Fqdn(rec.Target), ip))
+		}
+	}
+	return answer
+}
+
+// serves reports whether hostname is under one of the served domains
+func (s *Server) serves(hostname string) bool {
+	return strings.HasSuffix(hostname, "."+s.domainFor(hostname))
+}
+
+// normalizeName lowercases a hostname and strips its trailing dot
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+}
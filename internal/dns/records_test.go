@@ -0,0 +1,99 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestServerRecords(t *testing.T) {
+	docker := &fakeDocker{ips: map[string]string{"db-a1b2c3": "172.18.0.5"}}
+	server, err := NewServer(Config{Domain: "space.local", Docker: docker, Logger: &recordingLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server.SetRecords("app", []Record{
+		{Name: "DB.internal.", Target: "db-a1b2c3.space.local"},
+		{Name: "legacy.corp", IP: "10.0.0.7"},
+	})
+	server.SetRecords("other", []Record{{Name: "legacy.corp", IP: "10.0.0.9"}})
+
+	question := func(name string, qtype uint16) dns.Question {
+		return dns.Question{Name: dns.Fqdn(name), Qtype: qtype, Qclass: dns.ClassINET}
+	}
+
+	rec, ok := server.lookupRecord("legacy.corp.")
+	if !ok || rec.IP != "10.0.0.7" {
+		t.Fatalf("lookupRecord() = %+v, %v; want the first owner's address", rec, ok)
+	}
+	if answer := server.answerRecord(question("legacy.corp", dns.TypeA), rec); len(answer) != 1 || answer[0].(*dns.A).A.String() != "10.0.0.7" {
+		t.Errorf("A answer = %v", answer)
+	}
+	if answer := server.answerRecord(question("legacy.corp", dns.TypeAAAA), rec); len(answer) != 0 {
+		t.Errorf("AAAA answer = %v, want none", answer)
+	}
+
+	rec, ok = server.lookupRecord("db.internal")
+	if !ok {
+		t.Fatal("names should match case-insensitively")
+	}
+	answer := server.answerRecord(question("db.internal", dns.TypeA), rec)
+	if len(answer) != 2 || answer[0].(*dns.CNAME).Target != "db-a1b2c3.space.local." || answer[1].(*dns.A).A.String() != "172.18.0.5" {
+		t.Errorf("alias answer = %v", answer)
+	}
+
+	ip, err := server.Trace(context.Background(), "db.internal", func(TraceStep) {})
+	if err != nil || ip != "172.18.0.5" {
+		t.Errorf("Trace() = %q, %v", ip, err)
+	}
+
+	server.SetRecords("app", nil)
+	if records := server.Records(); len(records) != 1 || records[0].IP != "10.0.0.9" {
+		t.Errorf("Records() after removal = %+v", records)
+	}
+	if _, ok := server.lookupRecord("db.internal"); ok {
+		t.Error("removed record still answered")
+	}
+}
+
+// TestServerRecordsConcurrent replaces records while they are looked up, as
+// projects coming and going do under query load; run with -race
+func TestServerRecordsConcurrent(t *testing.T) {
+	server, err := NewServer(Config{Domain: "space.local", Docker: &fakeDocker{}, Logger: &recordingLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.SetRecords("base", []Record{{Name: "legacy.corp", IP: "10.0.0.7"}})
+
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			owner := fmt.Sprintf("project%d", w)
+			for i := 0; i < 200; i++ {
+				server.SetRecords(owner, []Record{{Name: owner + ".corp", IP: "10.0.1.1"}})
+				server.SetRecords(owner, nil)
+			}
+		}(w)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if rec, ok := server.lookupRecord("legacy.corp"); !ok || rec.IP != "10.0.0.7" {
+					t.Errorf("lookupRecord() = %+v, %v during updates", rec, ok)
+					return
+				}
+				server.Records()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if records := server.Records(); len(records) != 1 {
+		t.Errorf("Records() = %+v, want the base record only", records)
+	}
+}
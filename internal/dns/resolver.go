@@ -3,6 +3,7 @@ package dns
 import (
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -27,9 +28,82 @@ func NewResolverManager(domain, dnsAddr string, logger Logger) *ResolverManager
 	}
 }
 
-// Setup creates the resolver configuration
+// ResolverStatus describes the state of the resolver file compared to the
+// address the DNS server is actually bound to
+type ResolverStatus struct {
+	File       string
+	Exists     bool
+	Nameserver string
+	Port       string
+	Expected   string
+}
+
+// Configured returns the address the resolver file points at
+func (s *ResolverStatus) Configured() string {
+	if !s.Exists {
+		return ""
+	}
+	return net.JoinHostPort(s.Nameserver, s.Port)
+}
+
+// OK reports whether the resolver file points at the expected address
+func (s *ResolverStatus) OK() bool {
+	return s.Exists && s.Configured() == s.Expected
+}
+
+// Stale reports whether the resolver file exists but points elsewhere
+func (s *ResolverStatus) Stale() bool {
+	return s.Exists && !s.OK()
+}
+
+// File returns the path of the resolver file
+func (r *ResolverManager) File() string {
+	return filepath.Join(r.resolverDir, r.domain)
+}
+
+// Verify reads the resolver file and compares it to the DNS server address
+func (r *ResolverManager) Verify() (*ResolverStatus, error) {
+	status := &ResolverStatus{
+		File:     r.File(),
+		Expected: net.JoinHostPort(r.extractHost(r.dnsAddr), r.extractPort(r.dnsAddr)),
+	}
+
+	content, err := os.ReadFile(status.File)
+	if os.IsNotExist(err) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resolver file: %w", err)
+	}
+
+	status.Exists = true
+	status.Nameserver, status.Port = parseResolverFile(string(content))
+	return status, nil
+}
+
+// Setup creates the resolver configuration. It is idempotent: nothing is
+// written when the resolver file already points at the DNS server.
 func (r *ResolverManager) Setup(ctx context.Context) error {
-	resolverFile := filepath.Join(r.resolverDir, r.domain)
+	status, err := r.Verify()
+	if err == nil && status.OK() {
+		r.logger.Info("Resolver already configured correctly")
+		return nil
+	}
+	if err == nil && status.Stale() {
+		r.logger.Info("Resolver file is stale", "file", status.File, "configured", status.Configured(), "expected", status.Expected)
+	}
+
+	return r.write(ctx)
+}
+
+// Repair rewrites the resolver file so it points at the DNS server
+func (r *ResolverManager) Repair(ctx context.Context) error {
+	return r.write(ctx)
+}
+
+// write installs the resolver file with sudo and flushes the DNS cache
+func (r *ResolverManager) write(ctx context.Context) error {
+	resolverFile := r.File()
 
 	// Check if resolver directory exists
 	if _, err := os.Stat(r.resolverDir); os.IsNotExist(err) {
@@ -39,30 +113,24 @@ func (r *ResolverManager) Setup(ctx context.Context) error {
 		}
 	}
 
-	// Check if resolver file already exists
-	if _, err := os.Stat(resolverFile); err == nil {
-		r.logger.Info("Resolver file already exists", "file", resolverFile)
-		// Check if it points to our DNS server
-		content, err := os.ReadFile(resolverFile)
-		if err == nil && strings.Contains(string(content), r.extractHost(r.dnsAddr)) {
-			r.logger.Info("Resolver already configured correctly")
-			return nil
-		}
-	}
-
-	// Extract host and port from dnsAddr
-	host := r.extractHost(r.dnsAddr)
-	port := r.extractPort(r.dnsAddr)
-
-	// Create resolver content
-	content := fmt.Sprintf("nameserver %s\nport %s\n", host, port)
-
-	// Write to temporary file first
-	tmpFile := filepath.Join(os.TempDir(), r.domain)
-	if err := os.WriteFile(tmpFile, []byte(content), 0644); err != nil {
+	// Write to a temporary file first, one of our own so users sharing the
+	// machine don't write each other's
+	tmp, err := os.CreateTemp("", "space-resolver-*")
+	if err != nil {
 		return fmt.Errorf("failed to write temp resolver file: %w", err)
 	}
+	tmpFile := tmp.Name()
 	defer os.Remove(tmpFile)
+	_, err = tmp.WriteString(r.content())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpFile, 0644)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write temp resolver file: %w", err)
+	}
 
 	r.logger.Info("Creating resolver configuration", "file", resolverFile)
 
@@ -82,9 +150,35 @@ func (r *ResolverManager) Setup(ctx context.Context) error {
 	return nil
 }
 
+// content renders the resolver file for the DNS server address
+func (r *ResolverManager) content() string {
+	return fmt.Sprintf("nameserver %s\nport %s\n", r.extractHost(r.dnsAddr), r.extractPort(r.dnsAddr))
+}
+
+// parseResolverFile extracts the nameserver and port from resolver file
+// content. The port defaults to 53 like the system resolver does.
+func parseResolverFile(content string) (nameserver, port string) {
+	port = "53"
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			if nameserver == "" {
+				nameserver = fields[1]
+			}
+		case "port":
+			port = fields[1]
+		}
+	}
+	return nameserver, port
+}
+
 // Cleanup removes the resolver configuration
 func (r *ResolverManager) Cleanup(ctx context.Context) error {
-	resolverFile := filepath.Join(r.resolverDir, r.domain)
+	resolverFile := r.File()
 
 	// Check if file exists
 	if _, err := os.Stat(resolverFile); os.IsNotExist(err) {
@@ -112,8 +206,7 @@ func (r *ResolverManager) Cleanup(ctx context.Context) error {
 
 // IsConfigured checks if the resolver is already configured
 func (r *ResolverManager) IsConfigured() bool {
-	resolverFile := filepath.Join(r.resolverDir, r.domain)
-	_, err := os.Stat(resolverFile)
+	_, err := os.Stat(r.File())
 	return err == nil
 }
 
@@ -130,6 +223,13 @@ func (r *ResolverManager) runSudo(ctx context.Context, command string, args ...s
 
 // flushDNSCache flushes the macOS DNS cache
 func (r *ResolverManager) flushDNSCache(ctx context.Context) error {
+	flushDNSCache(ctx, r.logger)
+	return nil
+}
+
+// flushDNSCache flushes the macOS DNS cache, logging failures at debug level
+// since the commands do not exist on other systems
+func flushDNSCache(ctx context.Context, logger Logger) {
 	// Different commands for different macOS versions
 	commands := [][]string{
 		{"sudo", "dscacheutil", "-flushcache"},
@@ -138,11 +238,9 @@ func (r *ResolverManager) flushDNSCache(ctx context.Context) error {
 
 	for _, cmd := range commands {
 		if err := exec.CommandContext(ctx, cmd[0], cmd[1:]...).Run(); err != nil {
-			r.logger.Debug("Command failed", "command", strings.Join(cmd, " "), "error", err)
+			logger.Debug("Command failed", "command", strings.Join(cmd, " "), "error", err)
 		}
 	}
-
-	return nil
 }
 
 // extractHost extracts the host from an address (e.g., "127.0.0.1:5353" -> "127.0.0.1")
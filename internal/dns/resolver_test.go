@@ -0,0 +1,116 @@
+package dns
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseResolverFile(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		wantNameserver string
+		wantPort       string
+	}{
+		{
+			name:           "nameserver and port",
+			content:        "nameserver 127.0.0.1\nport 5353\n",
+			wantNameserver: "127.0.0.1",
+			wantPort:       "5353",
+		},
+		{
+			name:           "default port",
+			content:        "nameserver 127.0.0.1\n",
+			wantNameserver: "127.0.0.1",
+			wantPort:       "53",
+		},
+		{
+			name:           "comments and extra whitespace",
+			content:        "# space-cli\n  nameserver   127.0.0.1\nport\t5354\n",
+			wantNameserver: "127.0.0.1",
+			wantPort:       "5354",
+		},
+		{
+			name:     "empty",
+			content:  "",
+			wantPort: "53",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nameserver, port := parseResolverFile(tt.content)
+			if nameserver != tt.wantNameserver || port != tt.wantPort {
+				t.Errorf("parseResolverFile() = %q, %q, want %q, %q", nameserver, port, tt.wantNameserver, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestResolverManagerVerify(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   *string
+		wantOK    bool
+		wantStale bool
+	}{
+		{
+			name:    "missing file",
+			content: nil,
+		},
+		{
+			name:    "matching address",
+			content: strPtr("nameserver 127.0.0.1\nport 5354\n"),
+			wantOK:  true,
+		},
+		{
+			name:      "stale port",
+			content:   strPtr("nameserver 127.0.0.1\nport 5353\n"),
+			wantStale: true,
+		},
+		{
+			name:      "wrong host",
+			content:   strPtr("nameserver 10.0.0.1\nport 5354\n"),
+			wantStale: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewResolverManager("space.local", "127.0.0.1:5354", NewSimpleLogger(false))
+			r.resolverDir = t.TempDir()
+			if tt.content != nil {
+				if err := os.WriteFile(filepath.Join(r.resolverDir, "space.local"), []byte(*tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			status, err := r.Verify()
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if status.Expected != "127.0.0.1:5354" {
+				t.Errorf("Expected = %q", status.Expected)
+			}
+			if status.OK() != tt.wantOK {
+				t.Errorf("OK() = %v, want %v", status.OK(), tt.wantOK)
+			}
+			if status.Stale() != tt.wantStale {
+				t.Errorf("Stale() = %v, want %v", status.Stale(), tt.wantStale)
+			}
+		})
+	}
+}
+
+func TestResolverManagerContentRoundTrip(t *testing.T) {
+	r := NewResolverManager("space.local", "127.0.0.1:5355", NewSimpleLogger(false))
+	nameserver, port := parseResolverFile(r.content())
+	if nameserver != "127.0.0.1" || port != "5355" {
+		t.Errorf("content() parsed to %q, %q", nameserver, port)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -11,17 +12,24 @@ import (
 	"github.com/miekg/dns"
 )
 
+// resolveTimeout bounds looking up a container for one query, so a docker
+// daemon that doesn't answer fails the query before the client gives up
+const resolveTimeout = 3 * time.Second
+
 // Server is an embedded DNS server that resolves *.orb.local domains
 type Server struct {
 	addr         string
 	upstream     string
 	projectName  string
 	domain       string
+	domains      []string // domain plus additional domains, longest first
 	workDir      string // Working directory for hash generation
 	useHashing   bool   // Enable directory-based hashing for DNS names
+	queryLog     bool   // Log every query with its latency
 	server       *dns.Server
 	docker       DockerClient
 	cache        *cache
+	records      *records // Static records, answered before anything else
 	mu           sync.RWMutex
 	running      bool
 	logger       Logger
@@ -48,9 +56,11 @@ type Config struct {
 	Upstream    string        // Upstream DNS server (e.g., "8.8.8.8:53")
 	ProjectName string        // Docker compose project name
 	Domain      string        // Domain to handle (e.g., "orb.local")
+	Domains     []string      // Additional domains answered like Domain
 	WorkDir     string        // Working directory for hash generation
 	UseHashing  bool          // Enable directory-based hashing (default: true)
 	CacheTTL    time.Duration // Cache TTL (default: 30s)
+	QueryLog    bool          // Log every query with its answer and latency
 	Docker      DockerClient  // Docker client
 	Logger      Logger        // Logger
 }
@@ -84,22 +94,27 @@ func NewServer(cfg Config) (*Server, error) {
 		upstream:    cfg.Upstream,
 		projectName: cfg.ProjectName,
 		domain:      cfg.Domain,
+		domains:     servedDomains(cfg.Domain, cfg.Domains),
 		workDir:     cfg.WorkDir,
 		useHashing:  useHashing,
+		queryLog:    cfg.QueryLog,
 		docker:      cfg.Docker,
 		cache:       newCache(cfg.CacheTTL, 1000),
+		records:     newRecords(),
 		logger:      cfg.Logger,
 	}
 
 	// Create DNS server
 	mux := dns.NewServeMux()
-	mux.HandleFunc(cfg.Domain+".", s.handleOrbLocal)
+	for _, domain := range s.domains {
+		mux.HandleFunc(domain+".", s.handleOrbLocal)
+	}
 	mux.HandleFunc(".", s.handleUpstream)
 
 	s.server = &dns.Server{
 		Addr:    cfg.Addr,
 		Net:     "udp",
-		Handler: mux,
+		Handler: s.withRecords(mux),
 	}
 
 	return s, nil
@@ -115,7 +130,7 @@ func (s *Server) Start(ctx context.Context) error {
 	s.running = true
 	s.mu.Unlock()
 
-	s.logger.Info("Starting DNS server", "addr", s.addr, "domain", s.domain)
+	s.logger.Info("Starting DNS server", "addr", s.addr, "domains", strings.Join(s.domains, ","))
 
 	// Start server in goroutine
 	errCh := make(chan error, 1)
@@ -172,47 +187,8 @@ func (s *Server) handleOrbLocal(w dns.ResponseWriter, r *dns.Msg) {
 			continue
 		}
 
-		hostname := strings.TrimSuffix(q.Name, ".")
-
-		// Check cache first
-		if ip := s.cache.get(hostname); ip != "" {
-			s.logger.Debug("DNS cache hit", "hostname", hostname, "ip", ip)
-			rr := &dns.A{
-				Hdr: dns.RR_Header{
-					Name:   q.Name,
-					Rrtype: dns.TypeA,
-					Class:  dns.ClassINET,
-					Ttl:    30,
-				},
-				A: net.ParseIP(ip),
-			}
-			m.Answer = append(m.Answer, rr)
-			continue
-		}
-
-		// Resolve from Docker
-		ip, err := s.resolveContainerIP(context.Background(), hostname)
-		if err != nil {
-			s.logger.Warn("Failed to resolve container", "hostname", hostname, "error", err)
-			continue
-		}
-
-		if ip != "" {
-			// Cache the result
-			s.cache.set(hostname, ip)
-
-			s.logger.Debug("DNS resolved", "hostname", hostname, "ip", ip)
-
-			rr := &dns.A{
-				Hdr: dns.RR_Header{
-					Name:   q.Name,
-					Rrtype: dns.TypeA,
-					Class:  dns.ClassINET,
-					Ttl:    30,
-				},
-				A: net.ParseIP(ip),
-			}
-			m.Answer = append(m.Answer, rr)
+		if ip := s.lookupContainer(strings.TrimSuffix(q.Name, ".")); ip != "" {
+			m.Answer = append(m.Answer, aRecord(q.Name, ip))
 		}
 	}
 
@@ -221,13 +197,74 @@ func (s *Server) handleOrbLocal(w dns.ResponseWriter, r *dns.Msg) {
 	}
 }
 
+// lookupContainer returns the IP of the container hostname names, from the
+// cache or docker, or "" if none matches. A hostname with the NoCacheLabel
+// is always looked up in docker and leaves the cache alone.
+func (s *Server) lookupContainer(hostname string) string {
+	start := time.Now()
+	hostname, bypass := s.cutNoCache(normalizeName(hostname))
+
+	// Malformed names and names outside the served domains never reach
+	// the cache or docker
+	if _, ok := ServiceHost(hostname, s.domainFor(hostname)); !ok {
+		s.logger.Debug("DNS query rejected", "hostname", hostname)
+		s.logQuery(hostname, dns.TypeA, "", "name check", start, nil)
+		return ""
+	}
+
+	// Check cache first
+	if bypass {
+		s.logger.Debug("DNS cache bypassed", "hostname", hostname)
+	} else if ip := s.cache.get(hostname); ip != "" {
+		s.logger.Debug("DNS cache hit", "hostname", hostname, "ip", ip)
+		s.logQuery(hostname, dns.TypeA, ip, "cache", start, nil)
+		return ip
+	}
+
+	// Resolve from Docker
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	ip, err := s.resolveContainerIP(ctx, hostname)
+	cancel()
+	s.logQuery(hostname, dns.TypeA, ip, "docker", start, err)
+	if err != nil {
+		s.logger.Warn("Failed to resolve container", "hostname", hostname, "error", err)
+		return ""
+	}
+
+	if ip != "" && !bypass {
+		// Cache the result
+		s.cache.set(hostname, ip)
+		s.logger.Debug("DNS resolved", "hostname", hostname, "ip", ip)
+	}
+	return ip
+}
+
+// aRecord returns an A record answering name with ip
+func aRecord(name, ip string) *dns.A {
+	return &dns.A{
+		Hdr: dns.RR_Header{
+			Name:   name,
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    30,
+		},
+		A: net.ParseIP(ip),
+	}
+}
+
 // handleUpstream forwards queries to upstream DNS
 func (s *Server) handleUpstream(w dns.ResponseWriter, r *dns.Msg) {
 	// Forward to upstream DNS
 	c := new(dns.Client)
 	c.Timeout = 2 * time.Second
 
+	start := time.Now()
 	resp, _, err := c.Exchange(r, s.upstream)
+	if s.queryLog {
+		for _, q := range r.Question {
+			s.logQuery(strings.TrimSuffix(q.Name, "."), q.Qtype, answerSummary(resp), "upstream", start, err)
+		}
+	}
 	if err != nil {
 		s.logger.Warn("Failed to forward DNS query", "error", err)
 		m := new(dns.Msg)
@@ -245,19 +282,16 @@ func (s *Server) handleUpstream(w dns.ResponseWriter, r *dns.Msg) {
 
 // resolveContainerIP resolves a container IP from its hostname
 func (s *Server) resolveContainerIP(ctx context.Context, hostname string) (string, error) {
-	// Strip domain suffix
-	hostname = strings.TrimSuffix(hostname, "."+s.domain)
-	hostname = strings.TrimSuffix(hostname, ".")
+	domain := s.domainFor(hostname)
+	label, ok := ServiceHost(hostname, domain)
+	if !ok {
+		return "", fmt.Errorf("%q is not a service hostname under %s", hostname, domain)
+	}
 
 	// Check if this is a valid hashed domain (service-hash.domain)
-	fullHostname := hostname + "." + s.domain
-	if ValidateHashedDomain(fullHostname, s.domain) {
-		// Extract service name and hash from hashed domain
-		serviceName := ExtractServiceNameFromHashedDomain(fullHostname, s.domain)
-		hash := ExtractHashFromHashedDomain(fullHostname, s.domain)
-
+	if serviceName, hash, ok := splitHashedLabel(label); ok {
 		s.logger.Debug("Extracted service and hash from domain",
-			"hostname", hostname,
+			"hostname", label,
 			"service", serviceName,
 			"hash", hash)
 
@@ -275,8 +309,8 @@ func (s *Server) resolveContainerIP(ctx context.Context, hostname string) (strin
 	}
 
 	// Not a hashed domain - fall back to legacy lookup by service name only
-	s.logger.Debug("Non-hashed domain lookup", "hostname", hostname)
-	ip, err := s.docker.GetContainerIP(ctx, s.projectName, hostname)
+	s.logger.Debug("Non-hashed domain lookup", "hostname", label)
+	ip, err := s.docker.GetContainerIP(ctx, s.projectName, label)
 	if err != nil {
 		return "", err
 	}
@@ -284,6 +318,39 @@ func (s *Server) resolveContainerIP(ctx context.Context, hostname string) (strin
 	return ip, nil
 }
 
+// domainFor returns the served domain hostname belongs to, or the primary
+// domain if none matches
+func (s *Server) domainFor(hostname string) string {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	for _, domain := range s.domains {
+		if strings.HasSuffix(hostname, "."+domain) {
+			return domain
+		}
+	}
+	return s.domain
+}
+
+// servedDomains returns the primary domain followed by the additional ones,
+// without duplicates, ordered longest first so nested domains match first
+func servedDomains(primary string, extra []string) []string {
+	domains := []string{primary}
+	seen := map[string]bool{primary: true}
+	for _, domain := range extra {
+		domain = strings.ToLower(strings.Trim(domain, "."))
+		if domain != "" && !seen[domain] {
+			seen[domain] = true
+			domains = append(domains, domain)
+		}
+	}
+	sort.SliceStable(domains, func(i, j int) bool { return len(domains[i]) > len(domains[j]) })
+	return domains
+}
+
+// Domains returns every domain the server answers for
+func (s *Server) Domains() []string {
+	return append([]string(nil), s.domains...)
+}
+
 // IsRunning returns true if the server is running
 func (s *Server) IsRunning() bool {
 	s.mu.RLock()
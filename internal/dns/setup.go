@@ -0,0 +1,197 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// AliasLaunchDaemon is the launchd job that restores the loopback alias at
+// boot, since ifconfig aliases do not survive a restart
+const AliasLaunchDaemon = "/Library/LaunchDaemons/dev.space.loopback-alias.plist"
+
+// SetupFile is a file a setup step installs
+type SetupFile struct {
+	Path    string
+	Content string
+	Mode    os.FileMode
+}
+
+// SetupStep is a one-time change that needs root
+type SetupStep struct {
+	// Name identifies the step in messages
+	Name string
+
+	// Files are written before Commands run
+	Files []SetupFile
+
+	// Commands run as root, in order
+	Commands [][]string
+}
+
+// setupState is what is already in place on the machine
+type setupState struct {
+	aliasAssigned bool
+	launchDaemon  string
+	resolvers     map[string]bool
+}
+
+// PlanSetup returns the privileged steps still missing for domains: the
+// loopback alias, the launchd job keeping it across reboots and a resolver
+// file per domain pointing at the alias. Only macOS needs them; elsewhere
+// the plan is empty.
+func PlanSetup(domains []string) ([]SetupStep, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, nil
+	}
+
+	state := setupState{resolvers: make(map[string]bool)}
+
+	assigned, err := hasLocalAddress(StableIP)
+	if err != nil {
+		return nil, err
+	}
+	state.aliasAssigned = assigned
+
+	if data, err := os.ReadFile(AliasLaunchDaemon); err == nil {
+		state.launchDaemon = string(data)
+	}
+
+	for _, domain := range domains {
+		status, err := NewResolverManager(domain, StableAddr(), nil).Verify()
+		if err != nil {
+			return nil, err
+		}
+		state.resolvers[domain] = status.OK()
+	}
+
+	return planSetup(domains, state), nil
+}
+
+// planSetup computes the missing steps from the current state
+func planSetup(domains []string, state setupState) []SetupStep {
+	var steps []SetupStep
+
+	if !state.aliasAssigned {
+		steps = append(steps, SetupStep{
+			Name:     "loopback alias " + StableIP,
+			Commands: [][]string{aliasCommand()},
+		})
+	}
+
+	if plist := aliasLaunchDaemonPlist(); state.launchDaemon != plist {
+		steps = append(steps, SetupStep{
+			Name:  "launchd job " + filepath.Base(AliasLaunchDaemon),
+			Files: []SetupFile{{Path: AliasLaunchDaemon, Content: plist, Mode: 0644}},
+			Commands: [][]string{
+				{"chown", "root:wheel", AliasLaunchDaemon},
+				{"launchctl", "load", "-w", AliasLaunchDaemon},
+			},
+		})
+	}
+
+	var resolverFiles []SetupFile
+	var resolverDomains []string
+	for _, domain := range domains {
+		if state.resolvers[domain] {
+			continue
+		}
+		resolver := NewResolverManager(domain, StableAddr(), nil)
+		resolverFiles = append(resolverFiles, SetupFile{Path: resolver.File(), Content: resolver.content(), Mode: 0644})
+		resolverDomains = append(resolverDomains, domain)
+	}
+	if len(resolverFiles) > 0 {
+		steps = append(steps, SetupStep{
+			Name:  "resolver for " + strings.Join(resolverDomains, ", "),
+			Files: resolverFiles,
+			Commands: [][]string{
+				{"dscacheutil", "-flushcache"},
+				{"killall", "-HUP", "mDNSResponder"},
+			},
+		})
+	}
+
+	return steps
+}
+
+// aliasCommand adds the stable loopback alias
+func aliasCommand() []string {
+	return []string{"/sbin/ifconfig", "lo0", "alias", StableIP, "up"}
+}
+
+// aliasLaunchDaemonPlist renders the launchd job adding the alias at boot
+func aliasLaunchDaemonPlist() string {
+	var args strings.Builder
+	for _, arg := range aliasCommand() {
+		fmt.Fprintf(&args, "\t\t<string>%s</string>\n", arg)
+	}
+
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>dev.space.loopback-alias</string>
+	<key>ProgramArguments</key>
+	<array>
+` + args.String() + `	</array>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>
+`
+}
+
+// SetupScript renders steps as one shell script, so they run under a single
+// sudo prompt
+func SetupScript(steps []SetupStep) string {
+	var b strings.Builder
+	b.WriteString("set -e\n")
+	for _, step := range steps {
+		fmt.Fprintf(&b, "\n# %s\n", step.Name)
+		for _, f := range step.Files {
+			fmt.Fprintf(&b, "mkdir -p %s\n", shellQuote(filepath.Dir(f.Path)))
+			fmt.Fprintf(&b, "printf '%%s' %s > %s\n", shellQuote(f.Content), shellQuote(f.Path))
+			fmt.Fprintf(&b, "chmod %o %s\n", f.Mode, shellQuote(f.Path))
+		}
+		for _, cmd := range step.Commands {
+			quoted := make([]string, len(cmd))
+			for i, arg := range cmd {
+				quoted[i] = shellQuote(arg)
+			}
+			b.WriteString(strings.Join(quoted, " "))
+			// Cache flushes fail harmlessly on older systems
+			if cmd[0] == "dscacheutil" || cmd[0] == "killall" {
+				b.WriteString(" || true")
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// RunSetup runs steps as root with one sudo invocation attached to the
+// terminal
+func RunSetup(ctx context.Context, steps []SetupStep) error {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, "sudo", "/bin/sh", "-c", SetupScript(steps))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("privileged setup failed: %w", err)
+	}
+	return nil
+}
+
+// shellQuote quotes s for /bin/sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,63 @@
+package dns
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlanSetup(t *testing.T) {
+	domains := []string{"space.local", "dev.test"}
+
+	missing := planSetup(domains, setupState{resolvers: map[string]bool{"dev.test": true}})
+	if len(missing) != 3 {
+		t.Fatalf("planSetup() = %d steps, want 3: %+v", len(missing), missing)
+	}
+	resolvers := missing[2]
+	if len(resolvers.Files) != 1 || resolvers.Files[0].Path != "/etc/resolver/space.local" {
+		t.Errorf("resolver step files = %+v, want only space.local", resolvers.Files)
+	}
+	if resolvers.Files[0].Content != "nameserver 127.88.0.1\nport 53\n" {
+		t.Errorf("resolver content = %q", resolvers.Files[0].Content)
+	}
+
+	done := planSetup(domains, setupState{
+		aliasAssigned: true,
+		launchDaemon:  aliasLaunchDaemonPlist(),
+		resolvers:     map[string]bool{"space.local": true, "dev.test": true},
+	})
+	if len(done) != 0 {
+		t.Errorf("planSetup() = %+v, want nothing to do", done)
+	}
+}
+
+func TestSetupScript(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "it's.conf")
+	content := "line 'one'\n$HOME `two`\n"
+
+	script := SetupScript([]SetupStep{{
+		Name:     "test",
+		Files:    []SetupFile{{Path: path, Content: content, Mode: 0600}},
+		Commands: [][]string{{"touch", filepath.Join(dir, "ran")}},
+	}})
+
+	if out, err := exec.Command("/bin/sh", "-c", script).CombinedOutput(); err != nil {
+		t.Fatalf("script failed: %v: %s\n%s", err, out, script)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("file not written: %v", err)
+	}
+	if string(data) != content {
+		t.Errorf("content = %q, want %q", data, content)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ran")); err != nil {
+		t.Error("command did not run")
+	}
+}
@@ -0,0 +1,168 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// Resolution stages reported by Trace
+const (
+	StageRecord   = "record"
+	StageDomain   = "domain"
+	StageCache    = "cache"
+	StageName     = "name"
+	StageDocker   = "docker"
+	StageUpstream = "upstream"
+
+	// StageAnswer carries the address a query is answered with
+	StageAnswer = "answer"
+)
+
+// TraceStep is one stage of a traced resolution
+type TraceStep struct {
+	Stage   string        `json:"stage"`
+	Detail  string        `json:"detail"`
+	Elapsed time.Duration `json:"elapsed"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// Trace resolves hostname the way an A query would and reports each stage
+// to report. Unlike a query it always asks docker, even on a cache hit, so
+// a stale cache entry shows up; the cache itself is left untouched. It
+// returns the address a query would have been answered with.
+func (s *Server) Trace(ctx context.Context, hostname string, report func(TraceStep)) (string, error) {
+	hostname = strings.ToLower(strings.TrimSuffix(hostname, "."))
+	hostname, _ = s.cutNoCache(hostname)
+
+	if rec, ok := s.lookupRecord(hostname); ok {
+		if rec.IP != "" {
+			report(TraceStep{Stage: StageRecord, Detail: "static address " + rec.IP})
+			return rec.IP, nil
+		}
+		report(TraceStep{Stage: StageRecord, Detail: "static alias of " + rec.Target})
+		hostname = rec.Target
+	}
+
+	domain := s.domainFor(hostname)
+	if !strings.HasSuffix(hostname, "."+domain) {
+		report(TraceStep{Stage: StageDomain, Detail: fmt.Sprintf("outside %s, forwarded to %s", strings.Join(s.domains, ", "), s.upstream)})
+		return s.traceUpstream(hostname, report)
+	}
+	report(TraceStep{Stage: StageDomain, Detail: "served locally (" + domain + ")"})
+
+	if _, ok := ServiceHost(hostname, domain); !ok {
+		err := fmt.Errorf("not a service hostname: one label of letters, digits and hyphens before %s", domain)
+		report(TraceStep{Stage: StageName, Detail: "rejected before the cache and docker", Error: err.Error()})
+		return "", err
+	}
+
+	start := time.Now()
+	cached, remaining, hit := s.cache.peek(hostname)
+	if hit {
+		report(TraceStep{Stage: StageCache, Detail: fmt.Sprintf("hit %s (expires in %s)", cached, remaining.Round(time.Second)), Elapsed: time.Since(start)})
+	} else {
+		report(TraceStep{Stage: StageCache, Detail: "miss", Elapsed: time.Since(start)})
+	}
+
+	if ValidateHashedDomain(hostname, domain) {
+		report(TraceStep{Stage: StageName, Detail: fmt.Sprintf("service %q in the directory with hash %s",
+			ExtractServiceNameFromHashedDomain(hostname, domain), ExtractHashFromHashedDomain(hostname, domain))})
+	} else {
+		project := s.projectName
+		if project == "" {
+			project = "any project"
+		}
+		report(TraceStep{Stage: StageName, Detail: fmt.Sprintf("unhashed service %q in %s", strings.TrimSuffix(hostname, "."+domain), project)})
+	}
+
+	start = time.Now()
+	ip, err := s.resolveContainerIP(ctx, hostname)
+	step := TraceStep{Stage: StageDocker, Elapsed: time.Since(start)}
+	switch {
+	case err != nil:
+		step.Detail = "no matching container"
+		step.Error = err.Error()
+	case hit && ip != cached:
+		step.Detail = fmt.Sprintf("container at %s, but the cache still answers %s", ip, cached)
+	default:
+		step.Detail = "container at " + ip
+	}
+	report(step)
+
+	if hit {
+		return cached, nil
+	}
+	return ip, err
+}
+
+// traceUpstream resolves a foreign hostname through the upstream server
+func (s *Server) traceUpstream(hostname string, report func(TraceStep)) (string, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn(hostname), dns.TypeA)
+
+	c := new(dns.Client)
+	c.Timeout = 2 * time.Second
+
+	start := time.Now()
+	resp, _, err := c.Exchange(m, s.upstream)
+	step := TraceStep{Stage: StageUpstream, Detail: answerSummary(resp), Elapsed: time.Since(start)}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	report(step)
+	if err != nil {
+		return "", err
+	}
+
+	for _, rr := range resp.Answer {
+		if a, ok := rr.(*dns.A); ok {
+			return a.A.String(), nil
+		}
+	}
+	return "", fmt.Errorf("no A record for %s", hostname)
+}
+
+// logQuery writes one query log line when query logging is enabled
+func (s *Server) logQuery(hostname string, qtype uint16, answer, source string, start time.Time, err error) {
+	if !s.queryLog {
+		return
+	}
+
+	if answer == "" {
+		answer = "-"
+	}
+	line := fmt.Sprintf("query %s %s -> %s via %s in %s", dns.TypeToString[qtype], hostname, answer, source, time.Since(start).Round(time.Microsecond))
+	if err != nil {
+		line += ": " + err.Error()
+	}
+	s.logger.Info(line)
+}
+
+// answerSummary describes the records of a response in one line
+func answerSummary(resp *dns.Msg) string {
+	if resp == nil {
+		return "no response"
+	}
+	if len(resp.Answer) == 0 {
+		return dns.RcodeToString[resp.Rcode] + ", no answers"
+	}
+
+	answers := make([]string, 0, len(resp.Answer))
+	for _, rr := range resp.Answer {
+		switch v := rr.(type) {
+		case *dns.A:
+			answers = append(answers, v.A.String())
+		case *dns.AAAA:
+			answers = append(answers, v.AAAA.String())
+		case *dns.CNAME:
+			answers = append(answers, "CNAME "+strings.TrimSuffix(v.Target, "."))
+		default:
+			answers = append(answers, dns.TypeToString[rr.Header().Rrtype])
+		}
+	}
+	return strings.Join(answers, ", ")
+}
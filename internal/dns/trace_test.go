@@ -0,0 +1,111 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeDocker struct {
+	ips map[string]string
+}
+
+func (f *fakeDocker) GetContainerIP(_ context.Context, _, containerName string) (string, error) {
+	if ip, ok := f.ips[containerName]; ok {
+		return ip, nil
+	}
+	return "", fmt.Errorf("container not found: %s", containerName)
+}
+
+func (f *fakeDocker) GetContainerIPByHash(_ context.Context, serviceName, hash string) (string, error) {
+	if ip, ok := f.ips[serviceName+"-"+hash]; ok {
+		return ip, nil
+	}
+	return "", fmt.Errorf("container not found for service %s with hash %s", serviceName, hash)
+}
+
+func (f *fakeDocker) ListProjectContainers(context.Context, string) (map[string]string, error) {
+	return f.ips, nil
+}
+
+type recordingLogger struct {
+	infos []string
+}
+
+func (l *recordingLogger) Info(msg string, _ ...interface{}) { l.infos = append(l.infos, msg) }
+func (l *recordingLogger) Warn(string, ...interface{})       {}
+func (l *recordingLogger) Error(string, ...interface{})      {}
+func (l *recordingLogger) Debug(string, ...interface{})      {}
+
+func TestServerTrace(t *testing.T) {
+	docker := &fakeDocker{ips: map[string]string{"api-a1b2c3": "172.18.0.4"}}
+	logger := &recordingLogger{}
+	server, err := NewServer(Config{Domain: "space.local", Docker: docker, Logger: logger, QueryLog: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trace := func(hostname string) (string, []TraceStep, error) {
+		var steps []TraceStep
+		ip, err := server.Trace(context.Background(), hostname, func(step TraceStep) {
+			steps = append(steps, step)
+		})
+		return ip, steps, err
+	}
+
+	ip, steps, err := trace("api-a1b2c3.space.local.")
+	if err != nil || ip != "172.18.0.4" {
+		t.Fatalf("Trace() = %q, %v", ip, err)
+	}
+	var stages []string
+	for _, step := range steps {
+		stages = append(stages, step.Stage)
+	}
+	if got := strings.Join(stages, ","); got != "domain,cache,name,docker" {
+		t.Errorf("stages = %s", got)
+	}
+	if steps[1].Detail != "miss" || !strings.Contains(steps[2].Detail, `"api"`) {
+		t.Errorf("unexpected steps %+v", steps)
+	}
+
+	// A stale cache entry answers the query but is flagged
+	server.cache.set("api-a1b2c3.space.local", "172.18.0.9")
+	ip, steps, _ = trace("api-a1b2c3.space.local")
+	if ip != "172.18.0.9" {
+		t.Errorf("Trace() = %q, want the cached address", ip)
+	}
+	if !strings.Contains(steps[3].Detail, "cache still answers 172.18.0.9") {
+		t.Errorf("docker step = %+v", steps[3])
+	}
+
+	_, steps, err = trace("web-a1b2c3.space.local")
+	if err == nil || steps[len(steps)-1].Error == "" {
+		t.Errorf("expected unknown service to fail, got %v %+v", err, steps)
+	}
+
+	server.logQuery("api-a1b2c3.space.local", 1, "172.18.0.4", "docker", time.Now(), nil)
+	if len(logger.infos) != 1 || !strings.HasPrefix(logger.infos[0], "query A api-a1b2c3.space.local -> 172.18.0.4 via docker in ") {
+		t.Errorf("query log = %q", logger.infos)
+	}
+}
+
+func TestServerMultipleDomains(t *testing.T) {
+	docker := &fakeDocker{ips: map[string]string{"api-a1b2c3": "172.18.0.4"}}
+	server, err := NewServer(Config{Domain: "shop.test", Domains: []string{"space.local", ".Shop.Test"}, Docker: docker, Logger: &recordingLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(server.Domains(), ","); got != "space.local,shop.test" {
+		t.Errorf("Domains() = %s", got)
+	}
+
+	for _, hostname := range []string{"api-a1b2c3.shop.test", "api-a1b2c3.space.local"} {
+		ip, err := server.resolveContainerIP(context.Background(), hostname)
+		if err != nil || ip != "172.18.0.4" {
+			t.Errorf("resolveContainerIP(%s) = %q, %v", hostname, ip, err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// Service lifecycle event types, matching the hook events
+const (
+	TypeServiceStart = "on-service-start"
+	TypeServiceStop  = "on-service-stop"
+)
+
+// dockerEvent is the subset of `docker events --format '{{json .}}'` we use
+type dockerEvent struct {
+	Action string `json:"Action"`
+	Actor  struct {
+		ID         string            `json:"ID"`
+		Attributes map[string]string `json:"Attributes"`
+	} `json:"Actor"`
+	TimeNano int64 `json:"timeNano"`
+}
+
+// WatchDocker publishes on-service-start/on-service-stop events for compose
+// containers until ctx is done. When states is set, the same events keep it
+// current.
+func WatchDocker(ctx context.Context, bus *Bus, states *States) error {
+	return streamDocker(ctx, "", time.Time{}, func(de dockerEvent) {
+		if states != nil {
+			states.apply(de)
+		}
+		if e, ok := fromDockerEvent(de); ok {
+			bus.Publish(e)
+		}
+	})
+}
+
+// containerActions are the container events that change a service's state
+var containerActions = []string{"create", "start", "restart", "pause", "unpause", "die", "health_status", "destroy"}
+
+// streamDocker passes the container events of compose containers in project
+// (all projects when empty) to fn until ctx is done, replaying those since
+// the given time when it is set
+func streamDocker(ctx context.Context, project string, since time.Time, fn func(dockerEvent)) error {
+	label := "com.docker.compose.project"
+	if project != "" {
+		label += "=" + project
+	}
+	args := []string{"events", "--filter", "type=container", "--filter", "label=" + label}
+	for _, action := range containerActions {
+		args = append(args, "--filter", "event="+action)
+	}
+	if !since.IsZero() {
+		args = append(args, "--since", strconv.FormatInt(since.Unix(), 10))
+	}
+	args = append(args, "--format", "{{json .}}")
+	cmd := exec.CommandContext(ctx, "docker", args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to read docker events: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start docker events: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		var de dockerEvent
+		if err := json.Unmarshal(scanner.Bytes(), &de); err != nil {
+			continue
+		}
+		fn(de)
+	}
+
+	if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("docker events exited: %w", err)
+	}
+	return nil
+}
+
+// fromDockerEvent converts a docker container event to a lifecycle event
+func fromDockerEvent(de dockerEvent) (Event, bool) {
+	var eventType string
+	switch de.Action {
+	case "start":
+		eventType = TypeServiceStart
+	case "die":
+		eventType = TypeServiceStop
+	default:
+		return Event{}, false
+	}
+
+	attrs := de.Actor.Attributes
+	e := Event{
+		Type:    eventType,
+		Project: attrs["com.docker.compose.project"],
+		WorkDir: attrs["com.docker.compose.project.working_dir"],
+		Service: attrs["com.docker.compose.service"],
+		Data: map[string]interface{}{
+			"container": attrs["name"],
+		},
+	}
+	if de.TimeNano > 0 {
+		e.Time = time.Unix(0, de.TimeNano)
+	}
+	if code, ok := attrs["exitCode"]; ok {
+		e.Data["exit_code"] = code
+	}
+	return e, true
+}
@@ -0,0 +1,106 @@
+// Package events publishes environment lifecycle events (the hook events)
+// to external subscribers over the control socket
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a lifecycle event, serialized as one JSON object per line
+type Event struct {
+	// Type is the hook event name (e.g., "post-up", "on-service-start")
+	Type string `json:"type"`
+
+	// Time is when the event occurred
+	Time time.Time `json:"time"`
+
+	// Project is the Docker Compose project name
+	Project string `json:"project,omitempty"`
+
+	// WorkDir is the project directory
+	WorkDir string `json:"work_dir,omitempty"`
+
+	// Service is set for service-specific events
+	Service string `json:"service,omitempty"`
+
+	// Data holds event-specific details
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Filter selects events for a subscriber; empty fields match everything
+type Filter struct {
+	Types   []string `json:"types,omitempty"`
+	Project string   `json:"project,omitempty"`
+}
+
+// Match reports whether the event passes the filter
+func (f Filter) Match(e Event) bool {
+	if f.Project != "" && f.Project != e.Project {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == e.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// subscriberBuffer is how many events a slow subscriber may lag behind
+// before events are dropped for it
+const subscriberBuffer = 64
+
+// Bus fans published events out to subscribers
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[chan Event]Filter
+}
+
+// NewBus creates an event bus
+func NewBus() *Bus {
+	return &Bus{subs: make(map[chan Event]Filter)}
+}
+
+// Subscribe returns a channel receiving matching events and a function
+// that cancels the subscription
+func (b *Bus) Subscribe(filter Filter) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subs[ch] = filter
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers an event to all matching subscribers without blocking
+func (b *Bus) Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for ch, filter := range b.subs {
+		if !filter.Match(e) {
+			continue
+		}
+		select {
+		case ch <- e:
+		default:
+			// Subscriber is not keeping up, drop the event
+		}
+	}
+}
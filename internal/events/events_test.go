@@ -0,0 +1,138 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBusFilter(t *testing.T) {
+	bus := NewBus()
+	all, cancelAll := bus.Subscribe(Filter{})
+	defer cancelAll()
+	shop, cancelShop := bus.Subscribe(Filter{Types: []string{"post-up"}, Project: "shop"})
+	defer cancelShop()
+
+	bus.Publish(Event{Type: "pre-up", Project: "shop"})
+	bus.Publish(Event{Type: "post-up", Project: "blog"})
+	bus.Publish(Event{Type: "post-up", Project: "shop"})
+
+	if got := len(all); got != 3 {
+		t.Errorf("unfiltered subscriber got %d events, want 3", got)
+	}
+	if got := len(shop); got != 1 {
+		t.Fatalf("filtered subscriber got %d events, want 1", got)
+	}
+	if e := <-shop; e.Type != "post-up" || e.Project != "shop" || e.Time.IsZero() {
+		t.Errorf("unexpected event %+v", e)
+	}
+}
+
+func TestSocketPublishSubscribe(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control.sock")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	srv, err := Listen(path, NewBus())
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer srv.Close()
+	go srv.Serve(ctx)
+
+	if _, err := Listen(path, NewBus()); err == nil {
+		t.Error("expected second Listen on a live socket to fail")
+	}
+
+	events, err := Subscribe(ctx, path, Filter{Types: []string{"post-up"}})
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	// Publish until the subscription is registered on the server
+	deadline := time.After(2 * time.Second)
+	for {
+		if err := Publish(ctx, path, Event{Type: "post-up", Project: "shop", Service: "api"}); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		select {
+		case e := <-events:
+			if e.Project != "shop" || e.Service != "api" {
+				t.Errorf("unexpected event %+v", e)
+			}
+			return
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("timed out waiting for event")
+		}
+	}
+}
+
+func TestSocketCall(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control.sock")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	srv, err := Listen(path, NewBus())
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer srv.Close()
+	srv.Handle("count", func(ctx context.Context, req Request, send func(interface{}) error) error {
+		if req.Args["to"] != "2" {
+			return errors.New("can only count to 2")
+		}
+		for i := 1; i <= 2; i++ {
+			if err := send(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	go srv.Serve(ctx)
+
+	var got []int
+	err = Call(ctx, path, Request{Op: "count", Args: map[string]string{"to": "2"}}, func(data json.RawMessage) error {
+		var n int
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		got = append(got, n)
+		return nil
+	})
+	if err != nil || len(got) != 2 || got[1] != 2 {
+		t.Errorf("Call() = %v, %v", got, err)
+	}
+
+	noop := func(json.RawMessage) error { return nil }
+	if err := Call(ctx, path, Request{Op: "count", Args: map[string]string{"to": "3"}}, noop); err == nil || err.Error() != "can only count to 2" {
+		t.Errorf("Call() error = %v, want handler error", err)
+	}
+	if err := Call(ctx, path, Request{Op: "missing"}, noop); err == nil {
+		t.Error("expected unknown op to fail")
+	}
+}
+
+func TestFromDockerEvent(t *testing.T) {
+	var de dockerEvent
+	de.Action = "die"
+	de.Actor.Attributes = map[string]string{
+		"com.docker.compose.project": "shop-main",
+		"com.docker.compose.service": "api",
+		"name":                       "shop-main-api-1",
+		"exitCode":                   "137",
+	}
+
+	e, ok := fromDockerEvent(de)
+	if !ok || e.Type != TypeServiceStop || e.Service != "api" || e.Data["exit_code"] != "137" {
+		t.Errorf("fromDockerEvent() = %+v, %v", e, ok)
+	}
+
+	de.Action = "attach"
+	if _, ok := fromDockerEvent(de); ok {
+		t.Error("expected attach to be ignored")
+	}
+}
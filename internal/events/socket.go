@@ -0,0 +1,289 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/statedir"
+)
+
+// Request is sent by clients as the first line on a control connection
+type Request struct {
+	// Op is "publish", "subscribe" or an op registered with Server.Handle
+	Op string `json:"op"`
+
+	// Event to publish
+	Event *Event `json:"event,omitempty"`
+
+	// Filter for subscriptions
+	Filter Filter `json:"filter,omitempty"`
+
+	// Args are the arguments of a registered op
+	Args map[string]string `json:"args,omitempty"`
+}
+
+// Response acknowledges a request. Registered ops first stream any number
+// of responses carrying Data, then finish with OK or Error set.
+type Response struct {
+	OK    bool            `json:"ok"`
+	Error string          `json:"error,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+}
+
+// Handler serves a registered op, streaming intermediate results with send
+type Handler func(ctx context.Context, req Request, send func(data interface{}) error) error
+
+// SocketPath returns the control socket path, ~/.space-control.sock unless
+// SPACE_STATE_DIR moves it
+func SocketPath() string {
+	return statedir.Path("control.sock")
+}
+
+// Server exposes a bus on a unix socket
+type Server struct {
+	bus      *Bus
+	listener net.Listener
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+// Listen creates a server for bus listening on path, replacing a stale socket
+func Listen(path string, bus *Bus) (*Server, error) {
+	if conn, err := net.DialTimeout("unix", path, 200*time.Millisecond); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("control socket %s is already in use", path)
+	}
+	_ = os.Remove(path)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict control socket permissions: %w", err)
+	}
+
+	return &Server{bus: bus, listener: listener, handlers: make(map[string]Handler)}, nil
+}
+
+// Handle registers a handler for a custom op
+func (s *Server) Handle(op string, h Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[op] = h
+}
+
+// Serve accepts connections until ctx is done or the server is closed
+func (s *Server) Serve(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		s.listener.Close()
+	}()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+// Close stops the server and removes the socket
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	_ = os.Remove(s.listener.Addr().String())
+	return err
+}
+
+// handle serves a single connection
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return
+	}
+
+	var req Request
+	encoder := json.NewEncoder(conn)
+	if err := json.Unmarshal(line, &req); err != nil {
+		_ = encoder.Encode(Response{Error: "invalid request: " + err.Error()})
+		return
+	}
+
+	switch req.Op {
+	case "publish":
+		if req.Event == nil || req.Event.Type == "" {
+			_ = encoder.Encode(Response{Error: "publish requires an event with a type"})
+			return
+		}
+		s.bus.Publish(*req.Event)
+		_ = encoder.Encode(Response{OK: true})
+
+	case "subscribe":
+		ch, cancel := s.bus.Subscribe(req.Filter)
+		defer cancel()
+
+		// Detect the client going away
+		closed := make(chan struct{})
+		go func() {
+			_, _ = reader.ReadByte()
+			close(closed)
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-closed:
+				return
+			case e := <-ch:
+				if err := encoder.Encode(e); err != nil {
+					return
+				}
+			}
+		}
+
+	default:
+		s.mu.RLock()
+		h, ok := s.handlers[req.Op]
+		s.mu.RUnlock()
+		if !ok {
+			_ = encoder.Encode(Response{Error: fmt.Sprintf("unknown op %q", req.Op)})
+			return
+		}
+
+		send := func(data interface{}) error {
+			raw, err := json.Marshal(data)
+			if err != nil {
+				return err
+			}
+			return encoder.Encode(Response{Data: raw})
+		}
+		if err := h(ctx, req, send); err != nil {
+			_ = encoder.Encode(Response{Error: err.Error()})
+			return
+		}
+		_ = encoder.Encode(Response{OK: true})
+	}
+}
+
+// Call runs a registered op on the server at path, passing each streamed
+// result to fn
+func Call(ctx context.Context, path string, req Request, fn func(data json.RawMessage) error) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(conn)
+	for {
+		var resp Response
+		if err := decoder.Decode(&resp); err != nil {
+			return err
+		}
+		switch {
+		case resp.Data != nil:
+			if err := fn(resp.Data); err != nil {
+				return err
+			}
+		case resp.OK:
+			return nil
+		default:
+			return errors.New(resp.Error)
+		}
+	}
+}
+
+// Publish sends an event to the server at path
+func Publish(ctx context.Context, path string, e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if err := json.NewEncoder(conn).Encode(Request{Op: "publish", Event: &e}); err != nil {
+		return err
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return err
+	}
+	if !resp.OK {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// Subscribe streams matching events from the server at path until ctx is done
+func Subscribe(ctx context.Context, path string, filter Filter) (<-chan Event, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.NewEncoder(conn).Encode(Request{Op: "subscribe", Filter: filter}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	ch := make(chan Event)
+	go func() {
+		defer close(ch)
+		decoder := json.NewDecoder(conn)
+		for {
+			var e Event
+			if err := decoder.Decode(&e); err != nil {
+				return
+			}
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
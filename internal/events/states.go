@@ -0,0 +1,216 @@
+package events
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Container states of a tracked service
+const (
+	StateCreated = "created"
+	StateRunning = "running"
+	StatePaused  = "paused"
+	StateExited  = "exited"
+)
+
+// ServiceState is the container state of a compose service
+type ServiceState struct {
+	Service string `json:"service"`
+	State   string `json:"state"`
+
+	// Health is the docker healthcheck status ("starting", "healthy" or
+	// "unhealthy"), empty for containers without one
+	Health string `json:"health,omitempty"`
+
+	// ExitCode is set for exited containers
+	ExitCode string `json:"exit_code,omitempty"`
+
+	// Since is when the state was last seen to change
+	Since time.Time `json:"since"`
+}
+
+// Status returns the state as a docker status line without the durations,
+// e.g. "Up (healthy)" or "Exited (1)"
+func (s ServiceState) Status() string {
+	switch s.State {
+	case StateRunning:
+		switch s.Health {
+		case "":
+			return "Up"
+		case "starting":
+			return "Up (health: starting)"
+		}
+		return "Up (" + s.Health + ")"
+	case StateExited:
+		return "Exited (" + s.ExitCode + ")"
+	case "":
+		return ""
+	}
+	return strings.ToUpper(s.State[:1]) + s.State[1:]
+}
+
+// States follows docker events to keep the container state of compose
+// services current, so callers need not poll compose ps. A project is seeded
+// once from a listing; events keep it up to date from then on.
+type States struct {
+	mu       sync.Mutex
+	projects map[string]map[string]ServiceState
+	seeded   map[string]time.Time
+	changed  chan struct{}
+}
+
+// NewStates creates an empty state tracker
+func NewStates() *States {
+	return &States{
+		projects: make(map[string]map[string]ServiceState),
+		seeded:   make(map[string]time.Time),
+		changed:  make(chan struct{}, 1),
+	}
+}
+
+// Seed sets the states of a project from a listing taken at time at.
+// Services that changed after it keep their tracked state.
+func (s *States) Seed(project string, at time.Time, states []ServiceState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	services := make(map[string]ServiceState, len(states))
+	for _, st := range states {
+		if st.Since.IsZero() {
+			st.Since = at
+		}
+		services[st.Service] = st
+	}
+	for name, st := range s.projects[project] {
+		if st.Since.After(at) {
+			services[name] = st
+		}
+	}
+	s.projects[project] = services
+	s.seeded[project] = at
+	s.notify()
+}
+
+// Reset forgets all projects, so they are seeded again
+func (s *States) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.projects = make(map[string]map[string]ServiceState)
+	s.seeded = make(map[string]time.Time)
+}
+
+// Seeded reports whether a project has been seeded
+func (s *States) Seeded(project string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.seeded[project]
+	return ok
+}
+
+// Snapshot returns the states of a project's services, sorted by service
+func (s *States) Snapshot(project string) []ServiceState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make([]ServiceState, 0, len(s.projects[project]))
+	for _, st := range s.projects[project] {
+		states = append(states, st)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Service < states[j].Service })
+	return states
+}
+
+// Changed returns a channel that receives after states change. Changes
+// made while nobody receives are coalesced into one notification.
+func (s *States) Changed() <-chan struct{} {
+	return s.changed
+}
+
+// Watch applies the docker events of a project (all projects when empty)
+// since the given time until ctx is done
+func (s *States) Watch(ctx context.Context, project string, since time.Time) error {
+	return streamDocker(ctx, project, since, func(de dockerEvent) {
+		s.apply(de)
+	})
+}
+
+// apply moves a service through the state machine on a container event.
+// It reports whether the state changed.
+func (s *States) apply(de dockerEvent) bool {
+	attrs := de.Actor.Attributes
+	project, service := attrs["com.docker.compose.project"], attrs["com.docker.compose.service"]
+	// One-off containers of compose run are not the service
+	if project == "" || service == "" || attrs["com.docker.compose.oneoff"] == "True" {
+		return false
+	}
+
+	at := time.Now()
+	if de.TimeNano > 0 {
+		at = time.Unix(0, de.TimeNano)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Replayed events older than the listing a project was seeded from
+	// are already part of it
+	if seededAt, ok := s.seeded[project]; ok && at.Before(seededAt) {
+		return false
+	}
+
+	st, ok := s.projects[project][service]
+	if !ok {
+		st = ServiceState{Service: service}
+	}
+	prev := st
+
+	action, status, _ := strings.Cut(de.Action, ": ")
+	switch action {
+	case "create":
+		st.State, st.Health, st.ExitCode = StateCreated, "", ""
+	case "start", "restart", "unpause":
+		if st.State != StateRunning && st.State != StatePaused {
+			// docker sets a fresh container with a healthcheck to starting,
+			// but emits no event for it
+			st.Health = ""
+		}
+		st.State, st.ExitCode = StateRunning, ""
+	case "pause":
+		st.State = StatePaused
+	case "die":
+		st.State, st.Health, st.ExitCode = StateExited, "", attrs["exitCode"]
+	case "health_status":
+		st.Health = status
+	case "destroy":
+		if _, tracked := s.projects[project][service]; tracked {
+			delete(s.projects[project], service)
+			s.notify()
+			return true
+		}
+		return false
+	default:
+		return false
+	}
+
+	if ok && st.State == prev.State && st.Health == prev.Health && st.ExitCode == prev.ExitCode {
+		return false
+	}
+	st.Since = at
+	if s.projects[project] == nil {
+		s.projects[project] = make(map[string]ServiceState)
+	}
+	s.projects[project][service] = st
+	s.notify()
+	return true
+}
+
+// notify signals Changed without blocking; the caller holds s.mu
+func (s *States) notify() {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
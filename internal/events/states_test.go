@@ -0,0 +1,116 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func containerEvent(action, service string, at time.Time, attrs map[string]string) dockerEvent {
+	var de dockerEvent
+	de.Action = action
+	de.TimeNano = at.UnixNano()
+	de.Actor.Attributes = map[string]string{
+		"com.docker.compose.project": "shop",
+		"com.docker.compose.service": service,
+	}
+	for k, v := range attrs {
+		de.Actor.Attributes[k] = v
+	}
+	return de
+}
+
+func TestStatesApply(t *testing.T) {
+	s := NewStates()
+	base := time.Unix(1700000000, 0)
+	at := func(sec int) time.Time { return base.Add(time.Duration(sec) * time.Second) }
+
+	s.Seed("shop", at(0), []ServiceState{
+		{Service: "api", State: StateRunning, Health: "healthy"},
+		{Service: "db", State: StateRunning},
+	})
+	<-s.Changed()
+
+	steps := []struct {
+		event  dockerEvent
+		change bool
+	}{
+		// Replayed from before the listing
+		{containerEvent("die", "api", at(-1), map[string]string{"exitCode": "1"}), false},
+		{containerEvent("health_status: unhealthy", "api", at(1), nil), true},
+		{containerEvent("die", "api", at(2), map[string]string{"exitCode": "137"}), true},
+		{containerEvent("start", "api", at(3), nil), true},
+		{containerEvent("start", "api", at(4), nil), false},
+		{containerEvent("create", "worker", at(5), nil), true},
+		{containerEvent("pause", "db", at(6), nil), true},
+		{containerEvent("destroy", "gone", at(7), nil), false},
+		{containerEvent("start", "migrate", at(8), map[string]string{"com.docker.compose.oneoff": "True"}), false},
+		{containerEvent("exec_start: sh", "db", at(9), nil), false},
+	}
+	for i, step := range steps {
+		if got := s.apply(step.event); got != step.change {
+			t.Errorf("step %d: apply(%s %s) = %v, want %v", i, step.event.Action, step.event.Actor.Attributes["com.docker.compose.service"], got, step.change)
+		}
+	}
+
+	want := []ServiceState{
+		{Service: "api", State: StateRunning, Since: at(3)},
+		{Service: "db", State: StatePaused, Since: at(6)},
+		{Service: "worker", State: StateCreated, Since: at(5)},
+	}
+	if got := s.Snapshot("shop"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() = %+v, want %+v", got, want)
+	}
+
+	select {
+	case <-s.Changed():
+	default:
+		t.Error("expected a change notification")
+	}
+
+	s.apply(containerEvent("destroy", "worker", at(10), nil))
+	if got := len(s.Snapshot("shop")); got != 2 {
+		t.Errorf("after destroy %d services, want 2", got)
+	}
+
+	s.Reset()
+	if s.Seeded("shop") || len(s.Snapshot("shop")) != 0 {
+		t.Error("Reset() kept the project")
+	}
+}
+
+func TestStatesSeedKeepsNewerEvents(t *testing.T) {
+	s := NewStates()
+	listed := time.Unix(1700000000, 0)
+
+	// An event that arrived while compose ps was running
+	s.apply(containerEvent("die", "api", listed.Add(time.Second), map[string]string{"exitCode": "2"}))
+	s.Seed("shop", listed, []ServiceState{
+		{Service: "api", State: StateRunning},
+		{Service: "db", State: StateRunning},
+	})
+
+	got := s.Snapshot("shop")
+	if len(got) != 2 || got[0].State != StateExited || got[0].ExitCode != "2" || got[1].Since != listed {
+		t.Errorf("Snapshot() = %+v", got)
+	}
+	if !s.Seeded("shop") || s.Seeded("blog") {
+		t.Error("Seeded() mismatch")
+	}
+}
+
+func TestServiceStateStatus(t *testing.T) {
+	tests := map[string]ServiceState{
+		"Up":                    {State: StateRunning},
+		"Up (healthy)":          {State: StateRunning, Health: "healthy"},
+		"Up (health: starting)": {State: StateRunning, Health: "starting"},
+		"Exited (137)":          {State: StateExited, ExitCode: "137"},
+		"Paused":                {State: StatePaused},
+		"Restarting":            {State: "restarting"},
+	}
+	for want, st := range tests {
+		if got := st.Status(); got != want {
+			t.Errorf("%+v.Status() = %q, want %q", st, got, want)
+		}
+	}
+}
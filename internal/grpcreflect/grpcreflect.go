@@ -0,0 +1,144 @@
+// Package grpcreflect lists the services and methods of a gRPC server
+// using the server reflection protocol (grpc.reflection.v1)
+package grpcreflect
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Service is a gRPC service exposed by a server
+type Service struct {
+	Name    string   `json:"name"`
+	Methods []Method `json:"methods"`
+}
+
+// Method is a single RPC of a service
+type Method struct {
+	Name            string `json:"name"`
+	Input           string `json:"input"`
+	Output          string `json:"output"`
+	ClientStreaming bool   `json:"client_streaming,omitempty"`
+	ServerStreaming bool   `json:"server_streaming,omitempty"`
+}
+
+// Signature returns the method in proto syntax, e.g.
+// "Watch(HealthCheckRequest) returns (stream HealthCheckResponse)"
+func (m Method) Signature() string {
+	input, output := m.Input, m.Output
+	if m.ClientStreaming {
+		input = "stream " + input
+	}
+	if m.ServerStreaming {
+		output = "stream " + output
+	}
+	return fmt.Sprintf("%s(%s) returns (%s)", m.Name, input, output)
+}
+
+// List connects to address and returns all services with their methods,
+// sorted by name. The reflection service itself is omitted.
+func List(ctx context.Context, address string) ([]Service, error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reflection stream: %w", err)
+	}
+	defer stream.CloseSend()
+
+	resp, err := roundTrip(stream, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var services []Service
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		name := svc.GetName()
+		if strings.HasPrefix(name, "grpc.reflection.") {
+			continue
+		}
+
+		methods, err := describe(stream, name)
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, Service{Name: name, Methods: methods})
+	}
+
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+	return services, nil
+}
+
+// describe resolves the methods of a fully-qualified service name
+func describe(stream reflectionpb.ServerReflection_ServerReflectionInfoClient, service string) ([]Method, error) {
+	resp, err := roundTrip(stream, &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, raw := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		var file descriptorpb.FileDescriptorProto
+		if err := proto.Unmarshal(raw, &file); err != nil {
+			return nil, fmt.Errorf("failed to decode descriptor for %s: %w", service, err)
+		}
+
+		for _, sd := range file.GetService() {
+			if qualify(file.GetPackage(), sd.GetName()) != service {
+				continue
+			}
+
+			methods := make([]Method, 0, len(sd.GetMethod()))
+			for _, md := range sd.GetMethod() {
+				methods = append(methods, Method{
+					Name:            md.GetName(),
+					Input:           strings.TrimPrefix(md.GetInputType(), "."),
+					Output:          strings.TrimPrefix(md.GetOutputType(), "."),
+					ClientStreaming: md.GetClientStreaming(),
+					ServerStreaming: md.GetServerStreaming(),
+				})
+			}
+			return methods, nil
+		}
+	}
+
+	return nil, fmt.Errorf("service %s not found in reflection descriptors", service)
+}
+
+// roundTrip sends a reflection request and waits for its response
+func roundTrip(stream reflectionpb.ServerReflection_ServerReflectionInfoClient, req *reflectionpb.ServerReflectionRequest) (*reflectionpb.ServerReflectionResponse, error) {
+	if err := stream.Send(req); err != nil {
+		return nil, fmt.Errorf("reflection request failed: %w", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("reflection request failed: %w", err)
+	}
+	if e := resp.GetErrorResponse(); e != nil {
+		return nil, fmt.Errorf("reflection error: %s", e.GetErrorMessage())
+	}
+	return resp, nil
+}
+
+// qualify returns the fully-qualified name of a symbol in a package
+func qualify(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
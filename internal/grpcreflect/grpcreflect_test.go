@@ -0,0 +1,67 @@
+package grpcreflect
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+func TestList(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	reflection.Register(srv)
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	services, err := List(ctx, ln.Addr().String())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	if len(services) != 1 || services[0].Name != "grpc.health.v1.Health" {
+		t.Fatalf("List() = %+v, want only grpc.health.v1.Health", services)
+	}
+
+	signatures := make(map[string]string)
+	for _, m := range services[0].Methods {
+		signatures[m.Name] = m.Signature()
+	}
+	if got := signatures["Check"]; got != "Check(grpc.health.v1.HealthCheckRequest) returns (grpc.health.v1.HealthCheckResponse)" {
+		t.Errorf("Check signature = %q", got)
+	}
+	if got := signatures["Watch"]; got != "Watch(grpc.health.v1.HealthCheckRequest) returns (stream grpc.health.v1.HealthCheckResponse)" {
+		t.Errorf("Watch signature = %q", got)
+	}
+}
+
+func TestListWithoutReflection(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	srv := grpc.NewServer()
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := List(ctx, ln.Addr().String()); err == nil {
+		t.Error("expected error when reflection is not registered")
+	}
+}
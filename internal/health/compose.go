@@ -0,0 +1,66 @@
+package health
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// ComposeHealthcheck returns a compose healthcheck section that probes the
+// target from inside its container the same way space probes it from the
+// host. The test tries several tools since images ship different ones.
+// Target.Port must be the container port; Host is ignored.
+func ComposeHealthcheck(target Target) map[string]interface{} {
+	target.Host = "127.0.0.1"
+	check := target.Check
+
+	healthcheck := map[string]interface{}{
+		"test":     []interface{}{"CMD-SHELL", composeTest(target)},
+		"timeout":  composeDuration(target.timeout()),
+		"interval": composeDuration(DefaultInterval),
+		"retries":  DefaultRetries,
+	}
+	if check != nil && check.Interval > 0 {
+		healthcheck["interval"] = composeDuration(check.Interval)
+	}
+	if check != nil && check.Retries > 0 {
+		healthcheck["retries"] = check.Retries
+	}
+	return healthcheck
+}
+
+// composeTest returns the shell command of the healthcheck
+func composeTest(t Target) string {
+	if t.Command != "" {
+		return t.Command
+	}
+
+	addr := t.Address()
+	tcp := fmt.Sprintf("nc -z %s %d || bash -c 'echo > /dev/tcp/%s/%d'", t.Host, t.Port, t.Host, t.Port)
+
+	switch t.Protocol {
+	case config.ProtocolTCP:
+		return tcp
+	case config.ProtocolGRPC:
+		probe := "grpc_health_probe -addr=" + addr
+		if service := t.endpoint(); service != "" {
+			probe += " -service=" + service
+		}
+		// Fall back to a TCP connect when the image lacks grpc_health_probe
+		return fmt.Sprintf("if command -v grpc_health_probe >/dev/null; then %s; else %s; fi", probe, tcp)
+	default:
+		endpoint := t.endpoint()
+		if endpoint != "" && !strings.HasPrefix(endpoint, "/") {
+			endpoint = "/" + endpoint
+		}
+		url := fmt.Sprintf("http://%s%s", addr, endpoint)
+		return fmt.Sprintf("curl -fsS -o /dev/null %s || wget -q -O /dev/null %s", url, url)
+	}
+}
+
+// composeDuration formats a duration the way compose expects (e.g. "1m30s")
+func composeDuration(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,84 @@
+package health
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+func TestComposeHealthcheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		check    *config.HealthCheckConfig
+		contains []string
+	}{
+		{
+			name:     "http with endpoint",
+			protocol: config.ProtocolHTTP,
+			check:    &config.HealthCheckConfig{Enabled: true, Endpoint: "health"},
+			contains: []string{"curl -fsS -o /dev/null http://127.0.0.1:8080/health", "wget -q -O /dev/null http://127.0.0.1:8080/health"},
+		},
+		{
+			name:     "tcp",
+			protocol: config.ProtocolTCP,
+			check:    &config.HealthCheckConfig{Enabled: true},
+			contains: []string{"nc -z 127.0.0.1 8080", "/dev/tcp/127.0.0.1/8080"},
+		},
+		{
+			name:     "grpc with service",
+			protocol: config.ProtocolGRPC,
+			check:    &config.HealthCheckConfig{Enabled: true, Endpoint: "orders.v1.Orders"},
+			contains: []string{"grpc_health_probe -addr=127.0.0.1:8080 -service=orders.v1.Orders", "nc -z 127.0.0.1 8080"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hc := ComposeHealthcheck(Target{Protocol: tt.protocol, Port: 8080, Check: tt.check})
+
+			test, ok := hc["test"].([]interface{})
+			if !ok || len(test) != 2 || test[0] != "CMD-SHELL" {
+				t.Fatalf("test = %#v, want CMD-SHELL command", hc["test"])
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(test[1].(string), want) {
+					t.Errorf("test %q does not contain %q", test[1], want)
+				}
+			}
+		})
+	}
+}
+
+func TestComposeHealthcheckCommand(t *testing.T) {
+	hc := ComposeHealthcheck(Target{
+		Protocol: config.ProtocolTCP,
+		Port:     5432,
+		Check:    &config.HealthCheckConfig{Enabled: true},
+		Command:  "pg_isready -U app",
+	})
+
+	test := hc["test"].([]interface{})
+	if test[1] != "pg_isready -U app" {
+		t.Errorf("test = %v, want the readiness command", test)
+	}
+}
+
+func TestComposeHealthcheckTiming(t *testing.T) {
+	hc := ComposeHealthcheck(Target{Protocol: config.ProtocolHTTP, Port: 80})
+	if hc["timeout"] != "2s" || hc["interval"] != "1s" || hc["retries"] != DefaultRetries {
+		t.Errorf("defaults = %v", hc)
+	}
+
+	hc = ComposeHealthcheck(Target{Protocol: config.ProtocolHTTP, Port: 80, Check: &config.HealthCheckConfig{
+		Enabled:  true,
+		Timeout:  5 * time.Second,
+		Interval: 90 * time.Second,
+		Retries:  3,
+	}})
+	if hc["timeout"] != "5s" || hc["interval"] != "1m30s" || hc["retries"] != 3 {
+		t.Errorf("configured = %v", hc)
+	}
+}
@@ -0,0 +1,384 @@
+// Package health probes services over HTTP, TCP and gRPC
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Default probe settings, used when health_check leaves them unset
+const (
+	DefaultTimeout  = 2 * time.Second
+	DefaultInterval = 1 * time.Second
+	DefaultRetries  = 30
+)
+
+// DefaultEndpoints are tried in order for http services without a configured
+// endpoint when Target.FallbackEndpoints is set
+var DefaultEndpoints = []string{"/health", "/healthz", "/readyz"}
+
+// Prober checks whether a service is healthy
+type Prober interface {
+	// Probe returns nil if the service is healthy
+	Probe(ctx context.Context) error
+}
+
+// Target describes a service to probe
+type Target struct {
+	// Service is the service name
+	Service string
+
+	// Protocol is the effective service protocol (http, tcp or grpc)
+	Protocol string
+
+	// Host and Port are the address the service is reachable at
+	Host string
+	Port int
+
+	// Check is the service health check configuration (may be nil)
+	Check *config.HealthCheckConfig
+
+	// Project is the compose project, used to find the container for Command
+	Project string
+
+	// Command is the rendered readiness command run inside the container.
+	// When set it replaces the network probe.
+	Command string
+
+	// FallbackEndpoints probes DefaultEndpoints on http services without a
+	// configured endpoint, using the first one that exists
+	FallbackEndpoints bool
+}
+
+// Address returns host:port
+func (t Target) Address() string {
+	return net.JoinHostPort(t.Host, fmt.Sprint(t.Port))
+}
+
+// timeout returns the per-probe timeout
+func (t Target) timeout() time.Duration {
+	if t.Check != nil && t.Check.Timeout > 0 {
+		return t.Check.Timeout
+	}
+	return DefaultTimeout
+}
+
+// endpoint returns the configured health endpoint
+func (t Target) endpoint() string {
+	if t.Check == nil {
+		return ""
+	}
+	return t.Check.Endpoint
+}
+
+// NewProber returns the prober matching the target protocol
+func NewProber(t Target) Prober {
+	if t.Command != "" {
+		return &ExecProbe{Project: t.Project, Service: t.Service, Command: t.Command, Timeout: t.timeout()}
+	}
+
+	switch t.Protocol {
+	case config.ProtocolTCP:
+		return &TCPProbe{Address: t.Address(), Timeout: t.timeout()}
+	case config.ProtocolGRPC:
+		return &GRPCProbe{Address: t.Address(), Service: t.endpoint(), Timeout: t.timeout()}
+	default:
+		endpoint := t.endpoint()
+		if endpoint != "" && !strings.HasPrefix(endpoint, "/") {
+			endpoint = "/" + endpoint
+		}
+		return &HTTPProbe{URL: "http://" + t.Address() + endpoint, Timeout: t.timeout()}
+	}
+}
+
+// HTTPProbe checks that a GET request returns a 2xx or 3xx status
+type HTTPProbe struct {
+	URL     string
+	Timeout time.Duration
+
+	// StatusCode is the status of the last response
+	StatusCode int
+}
+
+// Probe implements Prober
+func (p *HTTPProbe) Probe(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{
+		// Redirects count as healthy, don't follow them
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	p.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("GET %s returned %s", p.URL, resp.Status)
+	}
+	return nil
+}
+
+// TCPProbe checks that a TCP connection can be established
+type TCPProbe struct {
+	Address string
+	Timeout time.Duration
+}
+
+// Probe implements Prober
+func (p *TCPProbe) Probe(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: p.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.Address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// GRPCProbe calls the standard grpc.health.v1 Check method
+type GRPCProbe struct {
+	Address string
+	Service string
+	Timeout time.Duration
+}
+
+// Probe implements Prober
+func (p *GRPCProbe) Probe(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	conn, err := grpc.NewClient(p.Address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: p.Service})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("service status is %s", resp.GetStatus())
+	}
+	return nil
+}
+
+// ExecProbe runs a command inside the service container and checks that it
+// exits with status 0
+type ExecProbe struct {
+	Project string
+	Service string
+	Command string
+	Timeout time.Duration
+}
+
+// execCommand builds docker commands, replaced in tests
+var execCommand = exec.CommandContext
+
+// Probe implements Prober
+func (p *ExecProbe) Probe(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.Timeout)
+	defer cancel()
+
+	out, err := execCommand(ctx, "docker", "ps", "-q",
+		"--filter", "label=com.docker.compose.project="+p.Project,
+		"--filter", "label=com.docker.compose.service="+p.Service).Output()
+	if err != nil {
+		return fmt.Errorf("failed to find container: %w", err)
+	}
+	containers := strings.Fields(string(out))
+	if len(containers) == 0 {
+		return fmt.Errorf("no running container for %s", p.Service)
+	}
+
+	out, err = execCommand(ctx, "docker", "exec", containers[0], "sh", "-c", p.Command).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// Result is the outcome of probing one target
+type Result struct {
+	Service  string        `json:"service"`
+	Protocol string        `json:"protocol"`
+	Address  string        `json:"address"`
+	URL      string        `json:"url,omitempty"`
+	Status   int           `json:"status,omitempty"`
+	Healthy  bool          `json:"healthy"`
+	Error    string        `json:"error,omitempty"`
+	Latency  time.Duration `json:"latency"`
+}
+
+// Check probes all targets concurrently, returning results in target order
+func Check(ctx context.Context, targets []Target) []Result {
+	results := make([]Result, len(targets))
+
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			results[i] = probe(ctx, t)
+		}(i, t)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Wait probes all targets until each is healthy or runs out of retries.
+// The returned results hold the last probe of every target.
+func Wait(ctx context.Context, targets []Target) ([]Result, error) {
+	return WaitEach(ctx, targets, nil)
+}
+
+// WaitEach is Wait calling healthy, when not nil, as soon as each target
+// passes. The calls are made one at a time, so healthy need not be safe for
+// concurrent use; targets still waiting keep being probed meanwhile.
+func WaitEach(ctx context.Context, targets []Target, healthy func(Result)) ([]Result, error) {
+	results := make([]Result, len(targets))
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t Target) {
+			defer wg.Done()
+			results[i] = waitFor(ctx, t)
+			if healthy != nil && results[i].Healthy {
+				mu.Lock()
+				defer mu.Unlock()
+				healthy(results[i])
+			}
+		}(i, t)
+	}
+	wg.Wait()
+
+	var unhealthy []string
+	for _, r := range results {
+		if !r.Healthy {
+			unhealthy = append(unhealthy, r.Service)
+		}
+	}
+	if len(unhealthy) > 0 {
+		return results, fmt.Errorf("services not healthy: %s", strings.Join(unhealthy, ", "))
+	}
+	return results, nil
+}
+
+// waitFor retries a single target
+func waitFor(ctx context.Context, t Target) Result {
+	retries, interval := DefaultRetries, DefaultInterval
+	if t.Check != nil {
+		if t.Check.Retries > 0 {
+			retries = t.Check.Retries
+		}
+		if t.Check.Interval > 0 {
+			interval = t.Check.Interval
+		}
+	}
+
+	var result Result
+	for attempt := 0; attempt < retries; attempt++ {
+		result = probe(ctx, t)
+		if result.Healthy {
+			return result
+		}
+
+		select {
+		case <-ctx.Done():
+			result.Error = ctx.Err().Error()
+			return result
+		case <-time.After(interval):
+		}
+	}
+	return result
+}
+
+// probe runs a single probe against a target
+func probe(ctx context.Context, t Target) Result {
+	if t.FallbackEndpoints && t.Command == "" && t.endpoint() == "" && t.Protocol != config.ProtocolTCP && t.Protocol != config.ProtocolGRPC {
+		return probeFallback(ctx, t)
+	}
+
+	start := time.Now()
+	prober := NewProber(t)
+	err := prober.Probe(ctx)
+	return newResult(t, prober, err, time.Since(start))
+}
+
+// probeFallback tries DefaultEndpoints in order. A 404 moves on to the next
+// endpoint; any other answer decides the result.
+func probeFallback(ctx context.Context, t Target) Result {
+	var result Result
+	for _, endpoint := range DefaultEndpoints {
+		check := withEndpoint(t.Check, endpoint)
+		target := t
+		target.Check = &check
+
+		start := time.Now()
+		prober := NewProber(target)
+		err := prober.Probe(ctx)
+		result = newResult(target, prober, err, time.Since(start))
+		if result.Status != http.StatusNotFound {
+			return result
+		}
+	}
+	result.Error = fmt.Sprintf("no health endpoint found (tried %s)", strings.Join(DefaultEndpoints, ", "))
+	return result
+}
+
+// withEndpoint returns a copy of check, which may be nil, probing
+// endpoint
+func withEndpoint(check *config.HealthCheckConfig, endpoint string) config.HealthCheckConfig {
+	var c config.HealthCheckConfig
+	if check != nil {
+		c = *check
+	}
+	c.Endpoint = endpoint
+	return c
+}
+
+// newResult describes the outcome of one probe
+func newResult(t Target, prober Prober, err error, latency time.Duration) Result {
+	result := Result{
+		Service:  t.Service,
+		Protocol: t.Protocol,
+		Address:  t.Address(),
+		Healthy:  err == nil,
+		Latency:  latency,
+	}
+	if p, ok := prober.(*HTTPProbe); ok {
+		result.URL = p.URL
+		result.Status = p.StatusCode
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,227 @@
+package health
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// splitAddr splits a listener address into host and port
+func splitAddr(t *testing.T, addr string) (string, int) {
+	t.Helper()
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatalf("failed to split %s: %v", addr, err)
+	}
+	port, _ := strconv.Atoi(portStr)
+	return host, port
+}
+
+func TestHTTPProbe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/health" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	host, port := splitAddr(t, srv.Listener.Addr().String())
+
+	healthy := Target{Service: "web", Protocol: config.ProtocolHTTP, Host: host, Port: port,
+		Check: &config.HealthCheckConfig{Enabled: true, Endpoint: "health"}}
+	if err := NewProber(healthy).Probe(context.Background()); err != nil {
+		t.Errorf("expected healthy, got %v", err)
+	}
+
+	unhealthy := healthy
+	unhealthy.Check = &config.HealthCheckConfig{Enabled: true, Endpoint: "/ready"}
+	if err := NewProber(unhealthy).Probe(context.Background()); err == nil {
+		t.Error("expected 503 to be unhealthy")
+	}
+}
+
+func TestFallbackEndpoints(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/healthz" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	host, port := splitAddr(t, srv.Listener.Addr().String())
+	target := Target{Service: "web", Protocol: config.ProtocolHTTP, Host: host, Port: port, FallbackEndpoints: true}
+
+	results := Check(context.Background(), []Target{target})
+	if r := results[0]; !r.Healthy || r.Status != http.StatusOK || !strings.HasSuffix(r.URL, "/healthz") {
+		t.Errorf("fallback result = %+v", r)
+	}
+
+	// A configured endpoint is probed as is
+	target.Check = &config.HealthCheckConfig{Enabled: true, Endpoint: "/ready"}
+	results = Check(context.Background(), []Target{target})
+	if r := results[0]; r.Healthy || r.Status != http.StatusNotFound {
+		t.Errorf("configured result = %+v", r)
+	}
+
+	target.Check = nil
+	srv.Config.Handler = http.NotFoundHandler()
+	results = Check(context.Background(), []Target{target})
+	if r := results[0]; r.Healthy || !strings.Contains(r.Error, "no health endpoint found") {
+		t.Errorf("missing endpoint result = %+v", r)
+	}
+}
+
+func TestTCPProbe(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	host, port := splitAddr(t, ln.Addr().String())
+
+	target := Target{Service: "db", Protocol: config.ProtocolTCP, Host: host, Port: port}
+	if err := NewProber(target).Probe(context.Background()); err != nil {
+		t.Errorf("expected healthy, got %v", err)
+	}
+
+	ln.Close()
+	if err := NewProber(target).Probe(context.Background()); err == nil {
+		t.Error("expected closed port to be unhealthy")
+	}
+}
+
+func TestGRPCProbe(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	hs := grpchealth.NewServer()
+	hs.SetServingStatus("orders", healthpb.HealthCheckResponse_NOT_SERVING)
+	srv := grpc.NewServer()
+	healthpb.RegisterHealthServer(srv, hs)
+	go srv.Serve(ln)
+	defer srv.Stop()
+
+	host, port := splitAddr(t, ln.Addr().String())
+
+	target := Target{Service: "api", Protocol: config.ProtocolGRPC, Host: host, Port: port}
+	if err := NewProber(target).Probe(context.Background()); err != nil {
+		t.Errorf("expected server to be serving, got %v", err)
+	}
+
+	target.Check = &config.HealthCheckConfig{Enabled: true, Endpoint: "orders"}
+	if err := NewProber(target).Probe(context.Background()); err == nil {
+		t.Error("expected NOT_SERVING service to be unhealthy")
+	}
+}
+
+func TestExecProbe(t *testing.T) {
+	original := execCommand
+	defer func() { execCommand = original }()
+
+	var container string
+	execCommand = func(ctx context.Context, name string, args ...string) *exec.Cmd {
+		switch args[0] {
+		case "ps":
+			return exec.CommandContext(ctx, "echo", container)
+		case "exec":
+			// Run the readiness command locally instead of in the container
+			return exec.CommandContext(ctx, args[2], args[3:]...)
+		}
+		t.Fatalf("unexpected docker command %v", args)
+		return nil
+	}
+
+	target := Target{Service: "db", Project: "shop", Protocol: config.ProtocolTCP, Command: "exit 0"}
+
+	container = ""
+	if err := NewProber(target).Probe(context.Background()); err == nil || !strings.Contains(err.Error(), "no running container") {
+		t.Errorf("expected missing container error, got %v", err)
+	}
+
+	container = "abc123"
+	if err := NewProber(target).Probe(context.Background()); err != nil {
+		t.Errorf("expected healthy, got %v", err)
+	}
+
+	target.Command = "echo 'no response' >&2; exit 2"
+	err := NewProber(target).Probe(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "no response") {
+		t.Errorf("expected failure with output, got %v", err)
+	}
+}
+
+func TestWait(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+	host, port := splitAddr(t, ln.Addr().String())
+
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	_, closedPort := splitAddr(t, closed.Addr().String())
+	closed.Close()
+
+	check := &config.HealthCheckConfig{Enabled: true, Retries: 2, Interval: 10 * time.Millisecond}
+	targets := []Target{
+		{Service: "db", Protocol: config.ProtocolTCP, Host: host, Port: port, Check: check},
+		{Service: "cache", Protocol: config.ProtocolTCP, Host: host, Port: closedPort, Check: check},
+	}
+
+	results, err := Wait(context.Background(), targets)
+	if err == nil {
+		t.Fatal("expected error for unhealthy service")
+	}
+	if !results[0].Healthy || results[1].Healthy {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
+
+func TestWaitEach(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+	host, port := splitAddr(t, ln.Addr().String())
+
+	closed, _ := net.Listen("tcp", "127.0.0.1:0")
+	_, closedPort := splitAddr(t, closed.Addr().String())
+	closed.Close()
+
+	check := &config.HealthCheckConfig{Enabled: true, Retries: 2, Interval: 10 * time.Millisecond}
+	targets := []Target{
+		{Service: "db", Protocol: config.ProtocolTCP, Host: host, Port: port, Check: check},
+		{Service: "api", Protocol: config.ProtocolTCP, Host: host, Port: port, Check: check},
+		{Service: "cache", Protocol: config.ProtocolTCP, Host: host, Port: closedPort, Check: check},
+	}
+
+	var healthy []string
+	_, err = WaitEach(context.Background(), targets, func(r Result) {
+		healthy = append(healthy, r.Service)
+	})
+	if err == nil {
+		t.Fatal("expected error for unhealthy service")
+	}
+	sort.Strings(healthy)
+	if strings.Join(healthy, ",") != "api,db" {
+		t.Errorf("healthy callbacks = %v, want api and db", healthy)
+	}
+}
@@ -68,7 +68,7 @@ func (h *EnvFileHook) Execute(ctx context.Context, event EventType, hookCtx *Hoo
 	}
 
 	// Store in metadata for consumers
-	hookCtx.SetMetadata("env_vars", envVars)
+	hookCtx.AddEnvVars(envVars)
 
 	return nil
 }
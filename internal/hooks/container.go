@@ -0,0 +1,167 @@
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/internal/redact"
+)
+
+// metaPrefix starts the comment line holding a script's metadata, e.g.
+//
+//	# space: service=web user=app
+//	# space: os=darwin,linux arch=arm64
+//	# space: requires=jq,psql
+const metaPrefix = "space:"
+
+// ScriptMeta is the hook metadata declared in a script's leading comments
+type ScriptMeta struct {
+	// Service runs the script inside the service's container instead of on
+	// the host
+	Service string
+
+	// User runs the script as this user inside the container
+	User string
+
+	// OS and Arch limit the platforms the script runs on, e.g. os=darwin,linux
+	OS   []string
+	Arch []string
+
+	// Requires lists commands the script needs on the host, e.g.
+	// requires=jq,psql
+	Requires []string
+}
+
+// ReadScriptMeta reads the metadata of a script from its leading comment
+// lines; a script without any runs on the host
+func ReadScriptMeta(script string) (ScriptMeta, error) {
+	f, err := os.Open(script)
+	if err != nil {
+		return ScriptMeta{}, err
+	}
+	defer f.Close()
+
+	var meta ScriptMeta
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		comment, ok := strings.CutPrefix(line, "#")
+		if !ok {
+			comment, ok = strings.CutPrefix(line, "//")
+		}
+		if !ok {
+			break
+		}
+
+		directive, ok := strings.CutPrefix(strings.TrimSpace(comment), metaPrefix)
+		if !ok {
+			continue
+		}
+		for _, field := range strings.Fields(directive) {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "service":
+				meta.Service = value
+			case "user":
+				meta.User = value
+			case "os":
+				meta.OS = strings.Split(value, ",")
+			case "arch":
+				meta.Arch = strings.Split(value, ",")
+			case "requires":
+				meta.Requires = append(meta.Requires, strings.Split(value, ",")...)
+			default:
+				return meta, fmt.Errorf("%s: unknown hook setting %q", filepath.Base(script), key)
+			}
+		}
+	}
+	return meta, scanner.Err()
+}
+
+// findContainer returns the running container of a compose service; a
+// variable so tests can stub it
+var findContainer = func(ctx context.Context, project, service string) (string, error) {
+	out, err := exec.CommandContext(ctx, "docker", "ps",
+		"--filter", "label=com.docker.compose.project="+project,
+		"--filter", "label=com.docker.compose.service="+service,
+		"--format", "{{.Names}}").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the %s container: %w", service, err)
+	}
+	names := strings.Fields(string(out))
+	if len(names) == 0 {
+		return "", fmt.Errorf("service %s has no running container", service)
+	}
+	return names[0], nil
+}
+
+// containerScriptPath is where a script is copied inside the container
+func containerScriptPath(script string) string {
+	return "/tmp/space-hook-" + filepath.Base(script)
+}
+
+// execArgs returns the docker exec arguments running a copied script with
+// the hook context on stdin. Variables are passed by name, so their values
+// come from the docker client's environment and stay out of process lists.
+func (e *ScriptExecutor) execArgs(container, script string, meta ScriptMeta, env []string) []string {
+	args := []string{"exec", "-i"}
+	if meta.User != "" {
+		args = append(args, "--user", meta.User)
+	}
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		args = append(args, "--env", name)
+	}
+
+	interpreter, interpreterArgs := e.getInterpreter(script)
+	args = append(args, container, interpreter)
+	args = append(args, interpreterArgs...)
+	return append(args, containerScriptPath(script))
+}
+
+// runInContainer copies a script into the container of meta.Service and
+// runs it there with the space variables, so it can use the tools of the
+// image rather than the host's
+func (e *ScriptExecutor) runInContainer(ctx context.Context, script string, meta ScriptMeta, contextJSON []byte, hookCtx *HookContext) error {
+	ctx, cancel := context.WithTimeout(ctx, e.Timeout)
+	defer cancel()
+
+	container, err := findContainer(ctx, hookCtx.ProjectName, meta.Service)
+	if err != nil {
+		return err
+	}
+
+	target := container + ":" + containerScriptPath(script)
+	if out, err := exec.CommandContext(ctx, "docker", "cp", script, target).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to copy script into %s: %s", container, strings.TrimSpace(string(out)))
+	}
+	defer func() {
+		_ = exec.Command("docker", "exec", container, "rm", "-f", containerScriptPath(script)).Run()
+	}()
+
+	env := e.spaceEnvironment(hookCtx)
+	cmd := exec.CommandContext(ctx, "docker", e.execArgs(container, script, meta, env)...)
+	cmd.Env = append(os.Environ(), env...)
+
+	stdout := redact.NewWriter(writerOr(e.Stdout, os.Stdout))
+	stderr := redact.NewWriter(writerOr(e.Stderr, os.Stderr))
+	defer stdout.Flush()
+	defer stderr.Flush()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Stdin = bytes.NewReader(contextJSON)
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("script failed in %s: %w", container, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package hooks
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeScript(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadScriptMeta(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		content string
+		want    ScriptMeta
+		wantErr bool
+	}{
+		{"#!/bin/sh\n# space: service=web user=app\nrails db:migrate\n", ScriptMeta{Service: "web", User: "app"}, false},
+		{"#!/usr/bin/env node\n\n// space: service=api\nconsole.log(1)\n", ScriptMeta{Service: "api"}, false},
+		{"#!/bin/sh\necho hi\n# space: service=web\n", ScriptMeta{}, false},
+		{"#!/bin/sh\n# space: os=darwin,linux arch=arm64\n", ScriptMeta{OS: []string{"darwin", "linux"}, Arch: []string{"arm64"}}, false},
+		{"#!/bin/sh\n# space: image=ruby\n", ScriptMeta{}, true},
+	}
+	for i, tc := range tests {
+		got, err := ReadScriptMeta(writeScript(t, dir, "hook.sh", tc.content))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("case %d: error = %v, wantErr %v", i, err, tc.wantErr)
+			continue
+		}
+		if !tc.wantErr && !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("case %d: meta = %+v, want %+v", i, got, tc.want)
+		}
+	}
+}
+
+func TestExecArgs(t *testing.T) {
+	executor := NewScriptExecutor("/tmp/test")
+	args := executor.execArgs("shop-web-1", "/p/.space/hooks/post-up.d/10-migrate.rb",
+		ScriptMeta{Service: "web", User: "app"}, []string{"SPACE_HASH=abc", "SPACE_PROJECT_NAME=shop"})
+
+	want := []string{
+		"exec", "-i", "--user", "app",
+		"--env", "SPACE_HASH", "--env", "SPACE_PROJECT_NAME",
+		"shop-web-1", "ruby", "/tmp/space-hook-10-migrate.rb",
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("execArgs = %v, want %v", args, want)
+	}
+}
+
+func TestRunScriptInContainer(t *testing.T) {
+	orig := findContainer
+	defer func() { findContainer = orig }()
+
+	var project, service string
+	findContainer = func(ctx context.Context, p, s string) (string, error) {
+		project, service = p, s
+		return "", errors.New("service web has no running container")
+	}
+
+	dir := t.TempDir()
+	script := writeScript(t, dir, "migrate.sh", "#!/bin/sh\n# space: service=web\nexit 0\n")
+	hookCtx := NewHookContext()
+	hookCtx.ProjectName = "shop"
+	hookCtx.WorkDir = dir
+
+	err := NewScriptExecutor(dir).RunScript(context.Background(), PostUp, script, hookCtx)
+	if err == nil || !strings.Contains(err.Error(), "no running container") {
+		t.Fatalf("RunScript() error = %v, want the container lookup error", err)
+	}
+	if project != "shop" || service != "web" {
+		t.Errorf("looked up %s/%s, want shop/web", project, service)
+	}
+}
@@ -1,15 +1,19 @@
 package hooks
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/happy-sdk/space-cli/internal/redact"
 )
 
 // ScriptExecutor executes external hook scripts
@@ -22,6 +26,13 @@ type ScriptExecutor struct {
 
 	// Logger for output
 	Logger ScriptLogger
+
+	// Stdout and Stderr receive script output (default: os.Stdout, os.Stderr)
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Verbose notes scripts skipped for another platform
+	Verbose bool
 }
 
 // ScriptLogger interface for script execution logging
@@ -31,12 +42,22 @@ type ScriptLogger interface {
 	Error(msg string, args ...interface{})
 }
 
-// DefaultScriptLogger logs to stdout
+// LogOutput is where DefaultScriptLogger writes; nil is os.Stdout. space
+// points it at its renderer.
+var LogOutput io.Writer
+
+// DefaultScriptLogger logs to LogOutput
 type DefaultScriptLogger struct{}
 
-func (l *DefaultScriptLogger) Info(msg string, args ...interface{})  { fmt.Printf("   "+msg+"\n", args...) }
-func (l *DefaultScriptLogger) Warn(msg string, args ...interface{})  { fmt.Printf("   ⚠️  "+msg+"\n", args...) }
-func (l *DefaultScriptLogger) Error(msg string, args ...interface{}) { fmt.Printf("   ❌ "+msg+"\n", args...) }
+func (l *DefaultScriptLogger) Info(msg string, args ...interface{}) {
+	fmt.Fprintln(writerOr(LogOutput, os.Stdout), redact.String(fmt.Sprintf("   "+msg, args...)))
+}
+func (l *DefaultScriptLogger) Warn(msg string, args ...interface{}) {
+	fmt.Fprintln(writerOr(LogOutput, os.Stdout), redact.String(fmt.Sprintf("   ⚠️  "+msg, args...)))
+}
+func (l *DefaultScriptLogger) Error(msg string, args ...interface{}) {
+	fmt.Fprintln(writerOr(LogOutput, os.Stdout), redact.String(fmt.Sprintf("   ❌ "+msg, args...)))
+}
 
 // NewScriptExecutor creates a new script executor
 func NewScriptExecutor(workDir string) *ScriptExecutor {
@@ -56,41 +77,100 @@ type HookContextJSON struct {
 	BaseDomain  string                     `json:"base_domain"`
 	DNSEnabled  bool                       `json:"dns_enabled"`
 	DNSAddress  string                     `json:"dns_address,omitempty"`
+	StartupLogs string                     `json:"startup_logs,omitempty"`
 	Services    map[string]ServiceInfoJSON `json:"services"`
 	Metadata    map[string]interface{}     `json:"metadata,omitempty"`
+
+	// Service is the service a service event is about
+	Service *ServiceInfoJSON `json:"service,omitempty"`
 }
 
 // ServiceInfoJSON is the JSON structure for service info
 type ServiceInfoJSON struct {
-	Name         string `json:"name"`
-	DNSName      string `json:"dns_name,omitempty"`
-	InternalPort int    `json:"internal_port,omitempty"`
-	ExternalPort int    `json:"external_port,omitempty"`
-	URL          string `json:"url,omitempty"`
-	Status       string `json:"status,omitempty"`
+	Name          string `json:"name"`
+	DNSName       string `json:"dns_name,omitempty"`
+	ContainerName string `json:"container_name,omitempty"`
+	Host          string `json:"host,omitempty"`
+	InternalPort  int    `json:"internal_port,omitempty"`
+	ExternalPort  int    `json:"external_port,omitempty"`
+	URL           string `json:"url,omitempty"`
+	PublicURL     string `json:"public_url,omitempty"`
+	Status        string `json:"status,omitempty"`
 }
 
-// Execute runs all scripts for a given event
-func (e *ScriptExecutor) Execute(ctx context.Context, event EventType, hookCtx *HookContext) error {
-	// Build event directory path (e.g., .space/hooks/post-up.d/)
-	eventDir := filepath.Join(e.HooksDir, string(event)+".d")
+// serviceInfoJSON converts a service for the JSON context
+func serviceInfoJSON(svc *ServiceInfo) ServiceInfoJSON {
+	return ServiceInfoJSON{
+		Name:          svc.Name,
+		DNSName:       svc.DNSName,
+		ContainerName: svc.ContainerName,
+		Host:          svc.Host,
+		InternalPort:  svc.InternalPort,
+		ExternalPort:  svc.ExternalPort,
+		URL:           svc.URL,
+		PublicURL:     svc.PublicURL,
+		Status:        svc.Status,
+	}
+}
 
-	// Check if directory exists
-	if _, err := os.Stat(eventDir); os.IsNotExist(err) {
-		return nil // No hooks for this event
+// HookContextFromJSON decodes a hook context as passed to scripts on stdin
+func HookContextFromJSON(data []byte) (*HookContext, EventType, error) {
+	var j HookContextJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, "", fmt.Errorf("failed to parse hook context: %w", err)
 	}
 
-	// Find all executable scripts
-	scripts, err := e.findScripts(eventDir)
-	if err != nil {
-		return fmt.Errorf("failed to find scripts: %w", err)
+	hookCtx := NewHookContext()
+	hookCtx.WorkDir = j.WorkDir
+	hookCtx.ProjectName = j.ProjectName
+	hookCtx.Hash = j.Hash
+	hookCtx.DNSEnabled = j.DNSEnabled
+	hookCtx.DNSAddress = j.DNSAddress
+	hookCtx.StartupLogs = j.StartupLogs
+	if j.BaseDomain != "" {
+		hookCtx.BaseDomain = j.BaseDomain
 	}
+	for k, v := range j.Metadata {
+		hookCtx.Metadata[k] = v
+	}
+	for name, svc := range j.Services {
+		if svc.Name == "" {
+			svc.Name = name
+		}
+		host := svc.Host
+		if host == "" {
+			host = svc.DNSName
+		}
+		hookCtx.Services[name] = &ServiceInfo{
+			Name:          svc.Name,
+			DNSName:       svc.DNSName,
+			ContainerName: svc.ContainerName,
+			Host:          host,
+			InternalPort:  svc.InternalPort,
+			ExternalPort:  svc.ExternalPort,
+			URL:           svc.URL,
+			PublicURL:     svc.PublicURL,
+			Status:        svc.Status,
+		}
+	}
+	if j.Service != nil {
+		hookCtx.ServiceName = j.Service.Name
+	}
+
+	return hookCtx, EventType(j.Event), nil
+}
 
-	if len(scripts) == 0 {
+// Execute runs all scripts for a given event
+func (e *ScriptExecutor) Execute(ctx context.Context, event EventType, hookCtx *HookContext) error {
+	runnable, metas, err := e.runnable(event)
+	if err != nil {
+		return err
+	}
+	if len(runnable) == 0 {
 		return nil
 	}
 
-	e.Logger.Info("Running %d %s hook(s)...", len(scripts), event)
+	e.Logger.Info("Running %d %s hook(s)...", len(runnable), event)
 
 	// Build context JSON
 	contextJSON, err := e.buildContextJSON(event, hookCtx)
@@ -102,11 +182,11 @@ func (e *ScriptExecutor) Execute(ctx context.Context, event EventType, hookCtx *
 	env := e.buildEnvironment(hookCtx)
 
 	// Execute each script in order
-	for _, script := range scripts {
+	for _, script := range runnable {
 		scriptName := filepath.Base(script)
 		e.Logger.Info("Running %s...", scriptName)
 
-		if err := e.runScript(ctx, script, contextJSON, env, hookCtx.WorkDir); err != nil {
+		if err := e.run(ctx, script, metas[script], contextJSON, env, hookCtx); err != nil {
 			e.Logger.Error("%s failed: %v", scriptName, err)
 			// Continue with other scripts unless it's critical
 			// Could add a "fail-fast" option later
@@ -116,6 +196,82 @@ func (e *ScriptExecutor) Execute(ctx context.Context, event EventType, hookCtx *
 	return nil
 }
 
+// Scripts returns the scripts Execute runs for an event, in order
+func (e *ScriptExecutor) Scripts(event EventType) ([]string, error) {
+	runnable, _, err := e.runnable(event)
+	return runnable, err
+}
+
+// runnable finds the executable scripts of an event, skipping those for
+// other platforms, with their metadata
+func (e *ScriptExecutor) runnable(event EventType) ([]string, map[string]ScriptMeta, error) {
+	// Build event directory path (e.g., .space/hooks/post-up.d/)
+	eventDir := filepath.Join(e.HooksDir, string(event)+".d")
+
+	// Check if directory exists
+	if _, err := os.Stat(eventDir); os.IsNotExist(err) {
+		return nil, nil, nil // No hooks for this event
+	}
+
+	// Find all executable scripts
+	scripts, err := e.findScripts(eventDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find scripts: %w", err)
+	}
+
+	// Skip scripts for other platforms
+	var runnable []string
+	metas := make(map[string]ScriptMeta)
+	for _, script := range scripts {
+		meta, err := ReadScriptMeta(script)
+		if err != nil {
+			e.Logger.Error("%v", err)
+			continue
+		}
+		if reason := PlatformMismatch(script, meta); reason != "" {
+			if e.Verbose {
+				e.Logger.Info("Skipping %s (%s)", filepath.Base(script), reason)
+			}
+			continue
+		}
+		runnable = append(runnable, script)
+		metas[script] = meta
+	}
+	return runnable, metas, nil
+}
+
+// HasScripts reports whether an event has any executable scripts
+func (e *ScriptExecutor) HasScripts(event EventType) bool {
+	scripts, err := e.findScripts(filepath.Join(e.HooksDir, string(event)+".d"))
+	return err == nil && len(scripts) > 0
+}
+
+// RunScript runs a single script for an event with the given context,
+// regardless of where the script lives, whether it is executable or which
+// platforms it is for.
+// A non-zero exit is returned as an error wrapping *exec.ExitError.
+func (e *ScriptExecutor) RunScript(ctx context.Context, event EventType, script string, hookCtx *HookContext) error {
+	contextJSON, err := e.buildContextJSON(event, hookCtx)
+	if err != nil {
+		return fmt.Errorf("failed to build context: %w", err)
+	}
+
+	meta, err := ReadScriptMeta(script)
+	if err != nil {
+		return err
+	}
+	return e.run(ctx, script, meta, contextJSON, e.buildEnvironment(hookCtx), hookCtx)
+}
+
+// run runs a script on the host, or in a service container when its
+// metadata names one
+func (e *ScriptExecutor) run(ctx context.Context, script string, meta ScriptMeta, contextJSON []byte, env []string, hookCtx *HookContext) error {
+	if meta.Service != "" {
+		return e.runInContainer(ctx, script, meta, contextJSON, hookCtx)
+	}
+	return e.runScript(ctx, script, contextJSON, env, hookCtx.WorkDir)
+}
+
 // findScripts finds all executable scripts in a directory, sorted by name
 func (e *ScriptExecutor) findScripts(dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
@@ -168,19 +324,17 @@ func (e *ScriptExecutor) buildContextJSON(event EventType, hookCtx *HookContext)
 		BaseDomain:  hookCtx.BaseDomain,
 		DNSEnabled:  hookCtx.DNSEnabled,
 		DNSAddress:  hookCtx.DNSAddress,
+		StartupLogs: hookCtx.StartupLogs,
 		Services:    make(map[string]ServiceInfoJSON),
 		Metadata:    hookCtx.Metadata,
 	}
 
 	for name, svc := range hookCtx.Services {
-		ctx.Services[name] = ServiceInfoJSON{
-			Name:         svc.Name,
-			DNSName:      svc.DNSName,
-			InternalPort: svc.InternalPort,
-			ExternalPort: svc.ExternalPort,
-			URL:          svc.URL,
-			Status:       svc.Status,
-		}
+		ctx.Services[name] = serviceInfoJSON(svc)
+	}
+	if svc, ok := hookCtx.Services[hookCtx.ServiceName]; ok {
+		info := serviceInfoJSON(svc)
+		ctx.Service = &info
 	}
 
 	return json.MarshalIndent(ctx, "", "  ")
@@ -188,20 +342,49 @@ func (e *ScriptExecutor) buildContextJSON(event EventType, hookCtx *HookContext)
 
 // buildEnvironment creates environment variables for hook scripts
 func (e *ScriptExecutor) buildEnvironment(hookCtx *HookContext) []string {
-	env := os.Environ()
-
-	// Add space-specific variables
-	env = append(env,
-		"SPACE_WORKDIR="+hookCtx.WorkDir,
-		"SPACE_PROJECT_NAME="+hookCtx.ProjectName,
-		"SPACE_HASH="+hookCtx.Hash,
-		"SPACE_BASE_DOMAIN="+hookCtx.BaseDomain,
-		fmt.Sprintf("SPACE_DNS_ENABLED=%t", hookCtx.DNSEnabled),
+	env := append(os.Environ(), e.spaceEnvironment(hookCtx)...)
+
+	// Shared snippets in .space/hooks/lib come first on the PATH, then the
+	// bundled helpers; exec uses the last PATH entry
+	libDir := filepath.Join(e.HooksDir, "lib")
+	path := []string{libDir}
+	if dir, err := helpersDir(); err == nil {
+		path = append(path, dir)
+	} else if e.Logger != nil {
+		e.Logger.Warn("Hook helpers unavailable: %v", err)
+	}
+	// The startup logs are a host path, unlike the variables containers get
+	if hookCtx.StartupLogs != "" {
+		env = append(env, "SPACE_STARTUP_LOGS="+hookCtx.StartupLogs)
+	}
+	return append(env,
+		"SPACE_HOOKS_LIB="+libDir,
+		"PATH="+strings.Join(append(path, os.Getenv("PATH")), string(os.PathListSeparator)),
 	)
+}
+
+// spaceEnvironment returns the variables space sets for hook scripts, which
+// also reach scripts run inside a container
+func (e *ScriptExecutor) spaceEnvironment(hookCtx *HookContext) []string {
+	env := []string{
+		"SPACE_WORKDIR=" + hookCtx.WorkDir,
+		"SPACE_PROJECT_NAME=" + hookCtx.ProjectName,
+		"SPACE_HASH=" + hookCtx.Hash,
+		"SPACE_BASE_DOMAIN=" + hookCtx.BaseDomain,
+		fmt.Sprintf("SPACE_DNS_ENABLED=%t", hookCtx.DNSEnabled),
+	}
 
 	if hookCtx.DNSAddress != "" {
 		env = append(env, "SPACE_DNS_ADDRESS="+hookCtx.DNSAddress)
 	}
+	if hookCtx.ServiceName != "" {
+		env = append(env, "SPACE_SERVICE_NAME="+hookCtx.ServiceName)
+	}
+
+	// Variables exported by earlier hooks, e.g. storage credentials
+	for k, v := range hookCtx.EnvVars() {
+		env = append(env, k+"="+v)
+	}
 
 	// Add service-specific variables
 	for name, svc := range hookCtx.Services {
@@ -216,6 +399,9 @@ func (e *ScriptExecutor) buildEnvironment(hookCtx *HookContext) []string {
 		if svc.URL != "" {
 			env = append(env, prefix+"_URL="+svc.URL)
 		}
+		if svc.PublicURL != "" {
+			env = append(env, prefix+"_PUBLIC_URL="+svc.PublicURL)
+		}
 	}
 
 	return env
@@ -234,26 +420,23 @@ func (e *ScriptExecutor) runScript(ctx context.Context, script string, contextJS
 	cmd := exec.CommandContext(ctx, interpreter, cmdArgs...)
 	cmd.Dir = workDir
 	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 
-	// Pass context JSON via stdin
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return fmt.Errorf("failed to create stdin pipe: %w", err)
-	}
+	// Mask sensitive values in script output
+	stdout := redact.NewWriter(writerOr(e.Stdout, os.Stdout))
+	stderr := redact.NewWriter(writerOr(e.Stderr, os.Stderr))
+	defer stdout.Flush()
+	defer stderr.Flush()
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	// Pass context JSON via stdin. exec copies it in the background and
+	// ignores scripts that exit without reading it.
+	cmd.Stdin = bytes.NewReader(contextJSON)
 
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start script: %w", err)
 	}
 
-	// Write context JSON to stdin
-	if _, err := stdin.Write(contextJSON); err != nil {
-		stdin.Close()
-		return fmt.Errorf("failed to write context: %w", err)
-	}
-	stdin.Close()
-
 	if err := cmd.Wait(); err != nil {
 		return fmt.Errorf("script failed: %w", err)
 	}
@@ -261,6 +444,14 @@ func (e *ScriptExecutor) runScript(ctx context.Context, script string, contextJS
 	return nil
 }
 
+// writerOr returns w, or fallback if w is nil
+func writerOr(w, fallback io.Writer) io.Writer {
+	if w != nil {
+		return w
+	}
+	return fallback
+}
+
 // getInterpreter determines the interpreter for a script
 func (e *ScriptExecutor) getInterpreter(script string) (string, []string) {
 	ext := filepath.Ext(script)
@@ -285,7 +476,7 @@ func InitHooksDir(workDir string) error {
 	hooksDir := filepath.Join(workDir, ".space", "hooks")
 
 	// Create event directories
-	events := []string{"pre-up", "post-up", "pre-down", "post-down", "on-dns-ready"}
+	events := []string{"pre-up", "post-up", "pre-down", "post-down", "on-dns-ready", "on-service-healthy"}
 	for _, event := range events {
 		eventDir := filepath.Join(hooksDir, event+".d")
 		if err := os.MkdirAll(eventDir, 0755); err != nil {
@@ -301,6 +492,18 @@ func InitHooksDir(workDir string) error {
 		}
 	}
 
+	// Create the shared library directory
+	libDir := filepath.Join(hooksDir, "lib")
+	if err := os.MkdirAll(libDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", libDir, err)
+	}
+	gitkeep := filepath.Join(libDir, ".gitkeep")
+	if _, err := os.Stat(gitkeep); os.IsNotExist(err) {
+		if err := os.WriteFile(gitkeep, []byte(""), 0644); err != nil {
+			return err
+		}
+	}
+
 	// Create README
 	readme := filepath.Join(hooksDir, "README.md")
 	if _, err := os.Stat(readme); os.IsNotExist(err) {
@@ -324,7 +527,9 @@ Scripts in these directories are executed during space lifecycle events.
 ├── post-up.d/     # After services are running
 ├── pre-down.d/    # Before docker compose down
 ├── post-down.d/   # After services are stopped
-└── on-dns-ready.d/ # When DNS is configured
+├── on-dns-ready.d/ # When DNS is configured
+├── on-service-healthy.d/ # Per service, once its health check passes
+└── lib/           # Shared snippets, on the PATH of every hook
 ` + "```" + `
 
 ## Writing Hooks
@@ -351,8 +556,12 @@ SPACE_SERVICE_POSTGRES_DNS_NAME=postgres-6a9c8c.space.local
 SPACE_SERVICE_POSTGRES_PORT=5432
 SPACE_SERVICE_API_SERVER_DNS_NAME=api-server-6a9c8c.space.local
 SPACE_SERVICE_API_SERVER_PORT=6060
+SPACE_SERVICE_API_SERVER_PUBLIC_URL=https://calm-fox.trycloudflare.com  # while 'space tunnel' runs
 ` + "```" + `
 
+` + "`SPACE_HOOKS_LIB`" + ` points at ` + "`lib/`" + `; source shared shell snippets with
+` + "`. \"$SPACE_HOOKS_LIB/common.sh\"`" + ` or run executables in it by name.
+
 ### JSON on stdin
 
 ` + "```json" + `
@@ -373,6 +582,72 @@ SPACE_SERVICE_API_SERVER_PORT=6060
 }
 ` + "```" + `
 
+## Running Inside a Container
+
+A script declaring a service in its leading comments runs inside that
+service's container instead of on the host, so it can use the image's tools:
+
+` + "```bash" + `
+#!/bin/sh
+# space: service=web user=app
+bundle exec rails db:migrate
+` + "```" + `
+
+The script is copied into the running container and executed with the
+` + "`SPACE_*`" + ` variables and the JSON context on stdin. The host PATH, lib/ and
+helpers are not available there.
+
+## Per-Service Hooks
+
+Scripts in on-service-healthy.d/ run once per service as soon as its health
+check first passes after ` + "`space up`" + `. The service is named in
+` + "`SPACE_SERVICE_NAME`" + ` and described in the ` + "`service`" + ` field of the JSON
+context:
+
+` + "```bash" + `
+#!/bin/sh
+[ "$SPACE_SERVICE_NAME" = postgres ] || exit 0
+psql -h "$(json-get service.host)" -U postgres -f schema.sql
+` + "```" + `
+
+## Platform-Specific Hooks
+
+A script named for a platform, such as 10-brew.darwin.sh, seed.arm64.sh or
+init.linux-amd64.sh, only runs there. The same can be declared in its
+leading comments:
+
+` + "```bash" + `
+#!/bin/sh
+# space: os=darwin,linux arch=arm64
+` + "```" + `
+
+Skipped scripts are reported by ` + "`space up -v`" + ` and ` + "`space hooks list`" + `.
+
+## Prerequisites
+
+Declare the commands a script needs on the host, so ` + "`space up`" + ` stops
+with install instructions instead of failing halfway through the hook:
+
+` + "```bash" + `
+#!/bin/sh
+# space: requires=jq,psql
+` + "```" + `
+
+` + "`space doctor`" + ` checks them too.
+
+## Helpers
+
+These commands are on the PATH of every hook:
+
+` + "```bash" + `
+wait-for-url http://api.space.local/health 120   # until it answers below 400
+wait-for-port postgres.space.local 5432          # until it accepts connections
+json-get services.api.url < context.json         # a value from JSON on stdin or a file
+` + "```" + `
+
+The optional timeout is in seconds (default 60); the helpers exit non-zero
+when it runs out.
+
 ## Example: Vite Environment Setup
 
 ` + "```bash" + `
@@ -440,4 +715,51 @@ psql "postgres://admin:test@${PG_HOST}:5432/postgres" -c "CREATE DATABASE river"
 river migrate-up --database-url "$DATABASE_URL"
 echo "✅ River database ready"
 ` + "```" + `
+
+## Go Hooks
+
+Hooks can also be written in Go in ` + "`.space/hooks.go`" + `. The file is
+interpreted by space (no build step) and declares a ` + "`Hooks`" + ` function
+returning typed hooks with direct access to the hook context:
+
+` + "```go" + `
+package space
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/happy-sdk/space-cli/pkg/hooks"
+)
+
+type printURLs struct{}
+
+func (printURLs) Name() string               { return "print-urls" }
+func (printURLs) Description() string        { return "Prints service URLs" }
+func (printURLs) Events() []hooks.EventType { return []hooks.EventType{hooks.PostUp} }
+
+func (printURLs) Execute(ctx context.Context, event hooks.EventType, hookCtx *hooks.HookContext) error {
+	for name, svc := range hookCtx.Services {
+		fmt.Println(name, svc.URL)
+	}
+	return nil
+}
+
+func Hooks() []hooks.Hook {
+	return []hooks.Hook{printURLs{}}
+}
+` + "```" + `
+
+Go hooks run before the scripts of the same event. Run ` + "`space hooks list`" + `
+to check that the file loads.
+
+## Event Stream
+
+While the DNS daemon is running (` + "`space dns start`" + `), every hook event and
+container start/stop is also published as JSON on the control socket
+(` + "`~/.space-control.sock`" + `). Watch it with:
+
+` + "```bash" + `
+space events --type post-up --type on-service-stop
+` + "```" + `
 `
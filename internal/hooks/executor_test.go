@@ -2,8 +2,11 @@ package hooks
 
 import (
 	"context"
+	"errors"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -148,6 +151,7 @@ func TestScriptExecutor_BuildEnvironment(t *testing.T) {
 		BaseDomain:  "space.local",
 		DNSEnabled:  true,
 		DNSAddress:  "127.0.0.1:5353",
+		StartupLogs: "/path/to/project/.space/logs/startup.log",
 		Services: map[string]*ServiceInfo{
 			"api-server": {
 				Name:         "api-server",
@@ -183,10 +187,12 @@ func TestScriptExecutor_BuildEnvironment(t *testing.T) {
 		"SPACE_BASE_DOMAIN":                "space.local",
 		"SPACE_DNS_ENABLED":                "true",
 		"SPACE_DNS_ADDRESS":                "127.0.0.1:5353",
+		"SPACE_STARTUP_LOGS":               "/path/to/project/.space/logs/startup.log",
 		"SPACE_SERVICE_API_SERVER_DNS_NAME": "api-server-abc123.space.local",
 		"SPACE_SERVICE_API_SERVER_PORT":    "6060",
 		"SPACE_SERVICE_API_SERVER_URL":     "http://api-server-abc123.space.local:6060",
 	}
+	checks["SPACE_HOOKS_LIB"] = filepath.Join("/tmp/test", ".space", "hooks", "lib")
 
 	for key, expected := range checks {
 		actual, ok := envMap[key]
@@ -198,6 +204,10 @@ func TestScriptExecutor_BuildEnvironment(t *testing.T) {
 			t.Errorf("Environment variable %s: expected %q, got %q", key, expected, actual)
 		}
 	}
+
+	if !strings.HasPrefix(envMap["PATH"], checks["SPACE_HOOKS_LIB"]+string(os.PathListSeparator)) {
+		t.Errorf("PATH does not start with the hooks lib: %q", envMap["PATH"])
+	}
 }
 
 func TestScriptExecutor_GetInterpreter(t *testing.T) {
@@ -235,7 +245,7 @@ func TestInitHooksDir(t *testing.T) {
 	}
 
 	// Check directories were created
-	events := []string{"pre-up", "post-up", "pre-down", "post-down", "on-dns-ready"}
+	events := []string{"pre-up", "post-up", "pre-down", "post-down", "on-dns-ready", "on-service-healthy"}
 	for _, event := range events {
 		dir := filepath.Join(tmpDir, ".space", "hooks", event+".d")
 		if _, err := os.Stat(dir); os.IsNotExist(err) {
@@ -265,6 +275,7 @@ func TestBuildContextJSON(t *testing.T) {
 		Hash:        "abc123",
 		BaseDomain:  "space.local",
 		DNSEnabled:  true,
+		StartupLogs: "/path/to/project/.space/logs/startup.log",
 		Services: map[string]*ServiceInfo{
 			"postgres": {
 				Name:         "postgres",
@@ -290,6 +301,7 @@ func TestBuildContextJSON(t *testing.T) {
 		`"hash": "abc123"`,
 		`"base_domain": "space.local"`,
 		`"dns_enabled": true`,
+		`"startup_logs": "/path/to/project/.space/logs/startup.log"`,
 		`"postgres"`,
 		`"internal_port": 5432`,
 	}
@@ -301,6 +313,44 @@ func TestBuildContextJSON(t *testing.T) {
 	}
 }
 
+func TestBuildContextJSON_ServiceEvent(t *testing.T) {
+	executor := NewScriptExecutor("/tmp/test")
+
+	hookCtx := NewHookContext()
+	hookCtx.ServiceName = "postgres"
+	hookCtx.Services["postgres"] = &ServiceInfo{
+		Name:          "postgres",
+		DNSName:       "postgres-abc123.space.local",
+		ContainerName: "myproject-postgres-1",
+		Host:          "postgres-abc123.space.local",
+		InternalPort:  5432,
+	}
+
+	jsonData, err := executor.buildContextJSON(OnServiceHealthy, hookCtx)
+	if err != nil {
+		t.Fatalf("buildContextJSON failed: %v", err)
+	}
+	jsonStr := string(jsonData)
+	for _, field := range []string{`"event": "on-service-healthy"`, `"service": {`, `"container_name": "myproject-postgres-1"`} {
+		if !strings.Contains(jsonStr, field) {
+			t.Errorf("Expected JSON to contain %s:\n%s", field, jsonStr)
+		}
+	}
+
+	decoded, event, err := HookContextFromJSON(jsonData)
+	if err != nil {
+		t.Fatalf("HookContextFromJSON failed: %v", err)
+	}
+	if event != OnServiceHealthy || decoded.ServiceName != "postgres" || decoded.Services["postgres"].ContainerName != "myproject-postgres-1" {
+		t.Errorf("decoded event %s, service %q, info %+v", event, decoded.ServiceName, decoded.Services["postgres"])
+	}
+
+	env := strings.Join(executor.spaceEnvironment(hookCtx), "\n")
+	if !strings.Contains(env, "SPACE_SERVICE_NAME=postgres") {
+		t.Errorf("Expected SPACE_SERVICE_NAME in environment:\n%s", env)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > 0 && containsHelper(s, substr))
 }
@@ -313,3 +363,121 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestScriptExecutor_RunScript(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	script := filepath.Join(tmpDir, "hook.sh")
+	content := `#!/bin/sh
+echo "project=$SPACE_PROJECT_NAME api=$SPACE_SERVICE_API_URL"
+cat
+exit 3
+`
+	// Deliberately not executable: RunScript runs it anyway
+	if err := os.WriteFile(script, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write script: %v", err)
+	}
+
+	hookCtx, event, err := HookContextFromJSON([]byte(`{
+		"event": "post-up",
+		"project_name": "shop-main",
+		"dns_enabled": true,
+		"services": {"api": {"internal_port": 8080, "url": "http://api.space.local:8080"}}
+	}`))
+	if err != nil {
+		t.Fatalf("HookContextFromJSON() error = %v", err)
+	}
+	if event != PostUp {
+		t.Errorf("event = %q, want post-up", event)
+	}
+	if hookCtx.Services["api"].Name != "api" {
+		t.Errorf("service name not filled from key: %+v", hookCtx.Services["api"])
+	}
+	hookCtx.WorkDir = tmpDir
+
+	var stdout strings.Builder
+	executor := NewScriptExecutor(tmpDir)
+	executor.Stdout = &stdout
+
+	err = executor.RunScript(context.Background(), event, script, hookCtx)
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("RunScript() error = %v, want exit code 3", err)
+	}
+
+	out := stdout.String()
+	if !strings.Contains(out, "project=shop-main api=http://api.space.local:8080") {
+		t.Errorf("missing env output: %q", out)
+	}
+	if !strings.Contains(out, `"event": "post-up"`) {
+		t.Errorf("missing context JSON on stdin: %q", out)
+	}
+}
+
+func TestLoadGoHooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".space"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	src := `package space
+
+import (
+	"context"
+	"strings"
+
+	"github.com/happy-sdk/space-cli/pkg/hooks"
+)
+
+type collectURLs struct{}
+
+func (collectURLs) Name() string               { return "collect-urls" }
+func (collectURLs) Description() string        { return "Collects service URLs" }
+func (collectURLs) Events() []hooks.EventType { return []hooks.EventType{hooks.PostUp} }
+
+func (collectURLs) Execute(ctx context.Context, event hooks.EventType, hookCtx *hooks.HookContext) error {
+	var urls []string
+	for _, svc := range hookCtx.Services {
+		urls = append(urls, svc.URL)
+	}
+	hookCtx.SetMetadata("urls", strings.Join(urls, ","))
+	return nil
+}
+
+func Hooks() []hooks.Hook {
+	return []hooks.Hook{collectURLs{}}
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, ".space", GoHooksFile), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadGoHooks(tmpDir)
+	if err != nil {
+		t.Fatalf("LoadGoHooks() error = %v", err)
+	}
+	if len(loaded) != 1 || loaded[0].Name() != "collect-urls" {
+		t.Fatalf("LoadGoHooks() = %v", loaded)
+	}
+
+	m := NewManager()
+	if err := m.Register(loaded[0]); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	hookCtx := NewHookContext()
+	hookCtx.Services["web"] = &ServiceInfo{Name: "web", URL: "http://web.space.local:3000"}
+	if errs := m.Execute(context.Background(), PostUp, hookCtx); len(errs) > 0 {
+		t.Fatalf("Execute() errors = %v", errs)
+	}
+	if got, _ := hookCtx.GetMetadata("urls"); got != "http://web.space.local:3000" {
+		t.Errorf("metadata urls = %v", got)
+	}
+}
+
+func TestLoadGoHooks_NoFile(t *testing.T) {
+	loaded, err := LoadGoHooks(t.TempDir())
+	if err != nil || loaded != nil {
+		t.Errorf("LoadGoHooks() = %v, %v; want nil, nil", loaded, err)
+	}
+}
@@ -0,0 +1,113 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/happy-sdk/space-cli/internal/provider"
+	"github.com/traefik/yaegi/interp"
+	"github.com/traefik/yaegi/stdlib"
+)
+
+// GoHooksFile is the project file that defines Go-native hooks
+const GoHooksFile = "hooks.go"
+
+// publicPackage is the import path Go hook files use for the hooks API
+const publicPackage = "github.com/happy-sdk/space-cli/pkg/hooks"
+
+// LoadGoHooks interprets .space/hooks.go and returns the hooks returned by
+// its Hooks function. It returns nil if the file does not exist.
+func LoadGoHooks(workDir string) ([]Hook, error) {
+	path := filepath.Join(workDir, ".space", GoHooksFile)
+	src, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), path, src, parser.PackageClauseOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	i := interp.New(interp.Options{GoPath: os.Getenv("GOPATH")})
+	if err := i.Use(stdlib.Symbols); err != nil {
+		return nil, fmt.Errorf("failed to load stdlib symbols: %w", err)
+	}
+	if err := i.Use(Symbols); err != nil {
+		return nil, fmt.Errorf("failed to load hooks symbols: %w", err)
+	}
+
+	if _, err := i.EvalPath(path); err != nil {
+		return nil, fmt.Errorf("failed to evaluate %s: %w", path, err)
+	}
+
+	fn, err := i.Eval(file.Name.Name + ".Hooks")
+	if err != nil {
+		return nil, fmt.Errorf("%s must declare func Hooks() []hooks.Hook: %w", path, err)
+	}
+	hooksFn, ok := fn.Interface().(func() []Hook)
+	if !ok {
+		return nil, fmt.Errorf("%s: Hooks has type %s, want func() []hooks.Hook", path, fn.Type())
+	}
+
+	return hooksFn(), nil
+}
+
+// symbols are the hooks API values available to interpreted hook files
+var symbols = map[string]reflect.Value{
+	// Types
+	"Hook":        reflect.ValueOf((*Hook)(nil)),
+	"HookContext": reflect.ValueOf((*HookContext)(nil)),
+	"ServiceInfo": reflect.ValueOf((*ServiceInfo)(nil)),
+	"EnvChange":   reflect.ValueOf((*EnvChange)(nil)),
+	"EventType":   reflect.ValueOf((*EventType)(nil)),
+
+	// Events
+	"PreUp":            reflect.ValueOf(PreUp),
+	"PostUp":           reflect.ValueOf(PostUp),
+	"PreDown":          reflect.ValueOf(PreDown),
+	"PostDown":         reflect.ValueOf(PostDown),
+	"OnDNSReady":       reflect.ValueOf(OnDNSReady),
+	"OnEnvChange":      reflect.ValueOf(OnEnvChange),
+	"OnServiceStart":   reflect.ValueOf(OnServiceStart),
+	"OnServiceStop":    reflect.ValueOf(OnServiceStop),
+	"OnServiceHealthy": reflect.ValueOf(OnServiceHealthy),
+
+	// Interface wrappers
+	"_Hook": reflect.ValueOf((*_hooks_Hook)(nil)),
+}
+
+// Symbols exposes the public hooks API (pkg/hooks) to interpreted hook files.
+// The types are aliases of this package's types, so the interpreter also needs
+// them under this package's path to find the interface wrappers.
+var Symbols = interp.Exports{
+	publicPackage + "/hooks":                              symbols,
+	"github.com/happy-sdk/space-cli/internal/hooks/hooks": symbols,
+	"github.com/happy-sdk/space-cli/internal/provider/provider": {
+		"Provider": reflect.ValueOf((*provider.Provider)(nil)),
+	},
+}
+
+// _hooks_Hook lets interpreted types satisfy Hook
+type _hooks_Hook struct {
+	IValue       interface{}
+	WDescription func() string
+	WEvents      func() []EventType
+	WExecute     func(ctx context.Context, event EventType, hookCtx *HookContext) error
+	WName        func() string
+}
+
+func (W _hooks_Hook) Description() string { return W.WDescription() }
+func (W _hooks_Hook) Events() []EventType { return W.WEvents() }
+func (W _hooks_Hook) Execute(ctx context.Context, event EventType, hookCtx *HookContext) error {
+	return W.WExecute(ctx, event, hookCtx)
+}
+func (W _hooks_Hook) Name() string { return W.WName() }
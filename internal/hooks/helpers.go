@@ -0,0 +1,248 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Helper is a command bundled with space and put on the PATH of hook
+// scripts, so hooks don't each reimplement polling loops
+type Helper struct {
+	Name  string
+	Usage string
+	Run   func(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+// Helpers lists the bundled hook helpers
+var Helpers = []Helper{
+	{Name: "wait-for-url", Usage: "wait-for-url <url> [timeout]", Run: waitForURL},
+	{Name: "wait-for-port", Usage: "wait-for-port <host> <port> [timeout]", Run: waitForPort},
+	{Name: "json-get", Usage: "json-get <path> [file]", Run: jsonGet},
+}
+
+// helperTimeout is how long the wait helpers poll by default
+const helperTimeout = 60 * time.Second
+
+// helperInterval is the delay between polls of the wait helpers
+var helperInterval = time.Second
+
+// RunHelper runs a bundled helper by name
+func RunHelper(ctx context.Context, name string, args []string, stdin io.Reader, stdout io.Writer) error {
+	for _, h := range Helpers {
+		if h.Name == name {
+			return h.Run(ctx, args, stdin, stdout)
+		}
+	}
+	return fmt.Errorf("unknown hook helper %q", name)
+}
+
+// helpersDir writes the helper shims, which call back into the space binary,
+// once per process and returns their directory
+var helpersDir = sync.OnceValues(func() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return writeHelperShims(filepath.Join(base, "space", "hook-helpers"), exe)
+})
+
+// writeHelperShims writes a shim per helper into a directory named after the
+// binary, so different space builds don't share shims
+func writeHelperShims(base, exe string) (string, error) {
+	sum := sha256.Sum256([]byte(exe))
+	dir := filepath.Join(base, hex.EncodeToString(sum[:])[:12])
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	for _, h := range Helpers {
+		shim := fmt.Sprintf("#!/bin/sh\nexec %s hooks helper %s \"$@\"\n", shellQuote(exe), h.Name)
+		path := filepath.Join(dir, h.Name)
+		if existing, err := os.ReadFile(path); err == nil && string(existing) == shim {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(shim), 0755); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// shellQuote quotes s for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// helperDeadline applies the optional timeout argument, in seconds or as a
+// duration such as 90s
+func helperDeadline(ctx context.Context, arg string) (context.Context, context.CancelFunc, error) {
+	timeout := helperTimeout
+	if arg != "" {
+		if seconds, err := strconv.Atoi(arg); err == nil {
+			timeout = time.Duration(seconds) * time.Second
+		} else if d, err := time.ParseDuration(arg); err == nil {
+			timeout = d
+		} else {
+			return nil, nil, fmt.Errorf("invalid timeout %q", arg)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, cancel, nil
+}
+
+// poll calls check until it succeeds or ctx ends, returning the last error
+func poll(ctx context.Context, what string, check func(ctx context.Context) error) error {
+	for {
+		err := check(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s: %w", what, err)
+		case <-time.After(helperInterval):
+		}
+	}
+}
+
+// waitForURL waits until a URL answers with a status below 400
+func waitForURL(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: wait-for-url <url> [timeout]")
+	}
+	ctx, cancel, err := helperDeadline(ctx, optionalArg(args, 1))
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	return poll(ctx, args[0], func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, args[0], nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		return nil
+	})
+}
+
+// waitForPort waits until a TCP port accepts connections. The address may
+// also be given as one host:port argument.
+func waitForPort(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	var addr, timeout string
+	switch {
+	case len(args) == 1, len(args) == 2 && strings.Contains(args[0], ":"):
+		addr, timeout = args[0], optionalArg(args, 1)
+	case len(args) == 2, len(args) == 3:
+		addr, timeout = net.JoinHostPort(args[0], args[1]), optionalArg(args, 2)
+	default:
+		return fmt.Errorf("usage: wait-for-port <host> <port> [timeout]")
+	}
+	ctx, cancel, err := helperDeadline(ctx, timeout)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	var dialer net.Dialer
+	return poll(ctx, addr, func(ctx context.Context) error {
+		conn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+}
+
+// jsonGet prints the value at a dotted path (services.api.url, items.0)
+// of a JSON document read from a file or stdin, such as the hook context.
+// Strings print raw, other values as JSON.
+func jsonGet(ctx context.Context, args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) < 1 || len(args) > 2 {
+		return fmt.Errorf("usage: json-get <path> [file]")
+	}
+
+	var data []byte
+	var err error
+	if file := optionalArg(args, 1); file != "" && file != "-" {
+		data, err = os.ReadFile(file)
+	} else {
+		data, err = io.ReadAll(stdin)
+	}
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	path := strings.Trim(args[0], ".")
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			switch v := value.(type) {
+			case map[string]interface{}:
+				next, ok := v[key]
+				if !ok {
+					return fmt.Errorf("%s: key %q not found", args[0], key)
+				}
+				value = next
+			case []interface{}:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= len(v) {
+					return fmt.Errorf("%s: no index %q", args[0], key)
+				}
+				value = v[i]
+			default:
+				return fmt.Errorf("%s: %q is not an object or array", args[0], key)
+			}
+		}
+	}
+
+	if s, ok := value.(string); ok {
+		_, err := fmt.Fprintln(stdout, s)
+		return err
+	}
+	out, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(stdout, string(out))
+	return err
+}
+
+// optionalArg returns args[i], or "" when it is absent
+func optionalArg(args []string, i int) string {
+	if i < len(args) {
+		return args[i]
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package hooks
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain keeps the helper shims written by the executor tests out of the
+// user's cache directory
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "hook-helpers-*")
+	if err != nil {
+		panic(err)
+	}
+	helpersDir = func() (string, error) { return writeHelperShims(dir, "space") }
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestJSONGet(t *testing.T) {
+	doc := `{"services": {"api": {"url": "http://api.space.local", "internal_port": 8080}}, "tags": ["a", "b"]}`
+
+	tests := map[string]string{
+		"services.api.url":            "http://api.space.local\n",
+		".services.api.internal_port": "8080\n",
+		"tags.1":                      "b\n",
+		"services.api":                `{"internal_port":8080,"url":"http://api.space.local"}` + "\n",
+	}
+	for path, want := range tests {
+		var out strings.Builder
+		if err := RunHelper(context.Background(), "json-get", []string{path}, strings.NewReader(doc), &out); err != nil {
+			t.Errorf("json-get %s: %v", path, err)
+			continue
+		}
+		if out.String() != want {
+			t.Errorf("json-get %s = %q, want %q", path, out.String(), want)
+		}
+	}
+
+	for _, path := range []string{"services.web", "tags.5", "services.api.url.host"} {
+		if err := RunHelper(context.Background(), "json-get", []string{path}, strings.NewReader(doc), &strings.Builder{}); err == nil {
+			t.Errorf("json-get %s: expected an error", path)
+		}
+	}
+}
+
+func TestWaitHelpers(t *testing.T) {
+	orig := helperInterval
+	helperInterval = 10 * time.Millisecond
+	defer func() { helperInterval = orig }()
+
+	ready := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-ready:
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	if err := RunHelper(context.Background(), "wait-for-url", []string{server.URL, "100ms"}, nil, nil); err == nil {
+		t.Error("wait-for-url: expected a timeout while the server returns 503")
+	}
+	close(ready)
+	if err := RunHelper(context.Background(), "wait-for-url", []string{server.URL, "5"}, nil, nil); err != nil {
+		t.Errorf("wait-for-url: %v", err)
+	}
+
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	if err := RunHelper(context.Background(), "wait-for-port", []string{host, port, "5"}, nil, nil); err != nil {
+		t.Errorf("wait-for-port: %v", err)
+	}
+	if err := RunHelper(context.Background(), "wait-for-port", []string{host + ":" + port}, nil, nil); err != nil {
+		t.Errorf("wait-for-port host:port: %v", err)
+	}
+	if err := RunHelper(context.Background(), "wait-for-port", []string{host, port, "soon"}, nil, nil); err == nil {
+		t.Error("wait-for-port: expected an error for an invalid timeout")
+	}
+}
+
+func TestWriteHelperShims(t *testing.T) {
+	dir, err := writeHelperShims(t.TempDir(), "/opt/my space/space")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range Helpers {
+		info, err := os.Stat(filepath.Join(dir, h.Name))
+		if err != nil {
+			t.Fatalf("shim %s: %v", h.Name, err)
+		}
+		if info.Mode()&0111 == 0 {
+			t.Errorf("shim %s is not executable", h.Name)
+		}
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "json-get"))
+	if want := "exec '/opt/my space/space' hooks helper json-get \"$@\""; !strings.Contains(string(data), want) {
+		t.Errorf("shim = %q, want it to contain %q", data, want)
+	}
+}
@@ -0,0 +1,246 @@
+// Package messaging provides the built-in hook that creates Kafka topics and
+// NATS JetStream streams once the broker is ready after 'space up'.
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// Broker kinds, matching the connection string schemes of their services
+const (
+	KindKafka = "kafka"
+	KindNATS  = "nats"
+)
+
+// Default broker ports, used when the service declares none
+const (
+	DefaultKafkaPort = 9092
+	DefaultNATSPort  = 4222
+)
+
+// DefaultTimeout bounds waiting for the broker to accept connections
+const DefaultTimeout = 60 * time.Second
+
+// natsBoxImage provides the nats CLI, which the server images don't ship
+const natsBoxImage = "natsio/nats-box:latest"
+
+// errToolMissing marks output of a container without the Kafka tools
+const errToolMissing = "kafka-topics not found in the container"
+
+// runDocker runs a docker command and returns its combined output, replaced
+// in tests
+var runDocker = func(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// Hook creates the configured topics or streams on a broker service
+type Hook struct {
+	kind     string
+	service  string
+	port     int
+	cfg      config.MessagingHooksConfig
+	interval time.Duration
+}
+
+// NewHook creates the messaging hook for the broker service of the given
+// kind. A zero port uses the default port of the broker.
+func NewHook(kind, service string, port int, cfg config.MessagingHooksConfig) (*Hook, error) {
+	switch kind {
+	case KindKafka:
+		if port == 0 {
+			port = DefaultKafkaPort
+		}
+	case KindNATS:
+		if port == 0 {
+			port = DefaultNATSPort
+		}
+	default:
+		return nil, fmt.Errorf("service %q is not a kafka or nats broker", service)
+	}
+
+	for i, topic := range cfg.Topics {
+		if topic.Name == "" {
+			return nil, fmt.Errorf("topic %d has no name", i+1)
+		}
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	return &Hook{kind: kind, service: service, port: port, cfg: cfg, interval: time.Second}, nil
+}
+
+// Name returns the hook name
+func (h *Hook) Name() string {
+	return "messaging"
+}
+
+// Description returns the hook description
+func (h *Hook) Description() string {
+	return "Creates Kafka topics or NATS streams once the broker is ready"
+}
+
+// Events returns the events this hook handles
+func (h *Hook) Events() []hooks.EventType {
+	return []hooks.EventType{hooks.PostUp}
+}
+
+// Execute waits for the broker and creates every topic that does not exist
+func (h *Hook) Execute(ctx context.Context, event hooks.EventType, hookCtx *hooks.HookContext) error {
+	if len(h.cfg.Topics) == 0 {
+		return nil
+	}
+
+	container, err := h.container(ctx, hookCtx.ProjectName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📨 Waiting for %s (%s)...\n", h.service, h.kind)
+	if err := h.waitReady(ctx, container); err != nil {
+		return err
+	}
+
+	var created []string
+	var failed []string
+	for _, topic := range h.cfg.Topics {
+		if out, err := h.run(ctx, container, h.createScript(topic)); err != nil {
+			fmt.Printf("   ✗ %s: %s\n", topic.Name, errorDetail(out, err))
+			failed = append(failed, topic.Name)
+			continue
+		}
+		fmt.Printf("   ✓ %s\n", topic.Name)
+		created = append(created, topic.Name)
+	}
+	hookCtx.SetMetadata("messaging.topics", created)
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to create %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// container returns the running container of the broker service
+func (h *Hook) container(ctx context.Context, project string) (string, error) {
+	out, err := runDocker(ctx, "ps", "-q",
+		"--filter", "label=com.docker.compose.project="+project,
+		"--filter", "label=com.docker.compose.service="+h.service)
+	if err != nil {
+		return "", fmt.Errorf("failed to find %s container: %s", h.service, errorDetail(out, err))
+	}
+	containers := strings.Fields(out)
+	if len(containers) == 0 {
+		return "", fmt.Errorf("no running container for %s", h.service)
+	}
+	return containers[0], nil
+}
+
+// waitReady retries the readiness script until it succeeds or the timeout
+// passes
+func (h *Hook) waitReady(ctx context.Context, container string) error {
+	ctx, cancel := context.WithTimeout(ctx, h.cfg.Timeout)
+	defer cancel()
+
+	for {
+		out, err := h.run(ctx, container, h.readyScript())
+		if err == nil {
+			return nil
+		}
+		if strings.Contains(out, errToolMissing) {
+			return fmt.Errorf("%s: %s", h.service, errToolMissing)
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not ready after %s: %s", h.service, h.cfg.Timeout, errorDetail(out, err))
+		case <-time.After(h.interval):
+		}
+	}
+}
+
+// run executes a script against the broker: inside its container for Kafka,
+// whose images ship the topic tools, and in a nats-box container sharing the
+// broker's network for NATS
+func (h *Hook) run(ctx context.Context, container, script string) (string, error) {
+	if h.kind == KindNATS {
+		return runDocker(ctx, "run", "--rm", "--network", "container:"+container,
+			"-e", fmt.Sprintf("NATS_URL=nats://127.0.0.1:%d", h.port),
+			natsBoxImage, "sh", "-c", script)
+	}
+	return runDocker(ctx, "exec", container, "sh", "-c", script)
+}
+
+// readyScript succeeds once the broker serves requests
+func (h *Hook) readyScript() string {
+	if h.kind == KindNATS {
+		// Streams need JetStream, which account info reports on
+		return "nats account info"
+	}
+	return h.kafkaScript("rpk cluster info", "--list")
+}
+
+// createScript creates a topic or stream unless it exists
+func (h *Hook) createScript(topic config.MessagingTopicConfig) string {
+	if h.kind == KindNATS {
+		subjects := topic.Subjects
+		if len(subjects) == 0 {
+			subjects = []string{topic.Name}
+		}
+		add := fmt.Sprintf("nats stream add %s --subjects %s --defaults", quote(topic.Name), quote(strings.Join(subjects, ",")))
+		if topic.Retention > 0 {
+			add += " --max-age " + topic.Retention.String()
+		}
+		return fmt.Sprintf("nats stream info %s >/dev/null 2>&1 || %s", quote(topic.Name), add)
+	}
+
+	partitions := topic.Partitions
+	if partitions == 0 {
+		partitions = 1
+	}
+	rpk := fmt.Sprintf("rpk topic create %s -p %d", quote(topic.Name), partitions)
+	tool := fmt.Sprintf("--create --if-not-exists --topic %s --partitions %d", quote(topic.Name), partitions)
+	if topic.Retention > 0 {
+		retention := fmt.Sprintf("retention.ms=%d", topic.Retention.Milliseconds())
+		rpk += " -c " + retention
+		tool += " --config " + retention
+	}
+	// rpk has no --if-not-exists; an existing topic is not an error
+	rpk = fmt.Sprintf("out=$(%s 2>&1) || echo \"$out\" | grep -q TOPIC_ALREADY_EXISTS || { echo \"$out\" >&2; exit 1; }", rpk)
+	return h.kafkaScript(rpk, tool)
+}
+
+// kafkaScript runs rpkCommand on Redpanda, or kafka-topics with toolArgs on
+// Kafka images, wherever the image keeps it
+func (h *Hook) kafkaScript(rpkCommand, toolArgs string) string {
+	return fmt.Sprintf(`if command -v rpk >/dev/null 2>&1; then
+  %s
+else
+  tool=""
+  for t in kafka-topics kafka-topics.sh /opt/kafka/bin/kafka-topics.sh /opt/bitnami/kafka/bin/kafka-topics.sh; do
+    if command -v "$t" >/dev/null 2>&1; then tool="$t"; break; fi
+  done
+  [ -n "$tool" ] || { echo %s >&2; exit 127; }
+  "$tool" --bootstrap-server localhost:%d %s
+fi`, rpkCommand, quote(errToolMissing), h.port, toolArgs)
+}
+
+// errorDetail prefers the command output over the exit status
+func errorDetail(out string, err error) string {
+	if out != "" {
+		return out
+	}
+	return err.Error()
+}
+
+// quote quotes s for /bin/sh
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,128 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/hooks"
+	"github.com/happy-sdk/space-cli/pkg/config"
+)
+
+// fakeDocker records docker commands; ready reports after how many readiness
+// checks the broker accepts connections
+func fakeDocker(t *testing.T, ready int) *[]string {
+	t.Helper()
+	orig := runDocker
+	t.Cleanup(func() { runDocker = orig })
+
+	var commands []string
+	checks := 0
+	runDocker = func(_ context.Context, args ...string) (string, error) {
+		cmd := strings.Join(args, " ")
+		commands = append(commands, cmd)
+		switch {
+		case args[0] == "ps":
+			return "c1", nil
+		case strings.Contains(cmd, "--list") || strings.HasSuffix(cmd, "nats account info"):
+			checks++
+			if checks < ready {
+				return "connection refused", fmt.Errorf("exit status 1")
+			}
+		}
+		return "", nil
+	}
+	return &commands
+}
+
+func TestKafkaTopics(t *testing.T) {
+	commands := fakeDocker(t, 3)
+
+	h, err := NewHook(KindKafka, "kafka", 0, config.MessagingHooksConfig{Topics: []config.MessagingTopicConfig{
+		{Name: "orders", Partitions: 3, Retention: 7 * 24 * time.Hour},
+		{Name: "audit"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.interval = time.Millisecond
+
+	hookCtx := hooks.NewHookContext()
+	hookCtx.ProjectName = "shop"
+	if err := h.Execute(context.Background(), hooks.PostUp, hookCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	// ps, three readiness checks, two creates
+	if len(*commands) != 6 {
+		t.Fatalf("commands = %q", *commands)
+	}
+	if !strings.Contains((*commands)[0], "com.docker.compose.project=shop") {
+		t.Errorf("container lookup = %q", (*commands)[0])
+	}
+	orders := (*commands)[4]
+	for _, want := range []string{"exec c1 sh -c", "--bootstrap-server localhost:9092", "--create --if-not-exists --topic 'orders' --partitions 3", "retention.ms=604800000"} {
+		if !strings.Contains(orders, want) {
+			t.Errorf("create command missing %q:\n%s", want, orders)
+		}
+	}
+	if !strings.Contains((*commands)[5], "--partitions 1") || strings.Contains((*commands)[5], "retention.ms") {
+		t.Errorf("default topic command:\n%s", (*commands)[5])
+	}
+
+	created, _ := hookCtx.GetMetadata("messaging.topics")
+	if fmt.Sprint(created) != "[orders audit]" {
+		t.Errorf("metadata = %v", created)
+	}
+}
+
+func TestNATSStreams(t *testing.T) {
+	commands := fakeDocker(t, 1)
+
+	h, err := NewHook(KindNATS, "nats", 0, config.MessagingHooksConfig{Topics: []config.MessagingTopicConfig{
+		{Name: "ORDERS", Subjects: []string{"orders.>"}, Retention: 24 * time.Hour},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := h.Execute(context.Background(), hooks.PostUp, hooks.NewHookContext()); err != nil {
+		t.Fatal(err)
+	}
+
+	create := (*commands)[2]
+	for _, want := range []string{"run --rm --network container:c1", "NATS_URL=nats://127.0.0.1:4222", natsBoxImage, "nats stream info 'ORDERS'", "--subjects 'orders.>'", "--max-age 24h0m0s"} {
+		if !strings.Contains(create, want) {
+			t.Errorf("create command missing %q:\n%s", want, create)
+		}
+	}
+}
+
+func TestWaitReadyTimeout(t *testing.T) {
+	fakeDocker(t, 1000)
+
+	h, err := NewHook(KindKafka, "kafka", 0, config.MessagingHooksConfig{
+		Timeout: 20 * time.Millisecond,
+		Topics:  []config.MessagingTopicConfig{{Name: "orders"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.interval = time.Millisecond
+
+	err = h.Execute(context.Background(), hooks.PostUp, hooks.NewHookContext())
+	if err == nil || !strings.Contains(err.Error(), "not ready after 20ms: connection refused") {
+		t.Errorf("Execute() = %v", err)
+	}
+}
+
+func TestNewHookErrors(t *testing.T) {
+	if _, err := NewHook("redis", "cache", 0, config.MessagingHooksConfig{}); err == nil {
+		t.Error("expected unsupported broker to fail")
+	}
+	if _, err := NewHook(KindKafka, "kafka", 0, config.MessagingHooksConfig{Topics: []config.MessagingTopicConfig{{}}}); err == nil {
+		t.Error("expected topic without name to fail")
+	}
+}
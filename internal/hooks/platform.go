@@ -0,0 +1,91 @@
+package hooks
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Platform is an OS and architecture as runtime.GOOS and runtime.GOARCH
+// name them
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+// hostPlatform is the platform scripts are matched against; a variable so
+// tests can change it
+var hostPlatform = Platform{OS: runtime.GOOS, Arch: runtime.GOARCH}
+
+// knownOS and knownArch are the names recognized in script names
+var (
+	knownOS   = map[string]bool{"darwin": true, "linux": true, "windows": true, "freebsd": true}
+	knownArch = map[string]bool{"amd64": true, "arm64": true, "386": true, "arm": true}
+)
+
+// NamePlatform returns the OS and architecture constraints in a script
+// name: dot-separated parts after the first naming an OS, an architecture
+// or both, as in 10-setup.darwin.sh, seed.arm64.py or init.linux-amd64.sh
+func NamePlatform(name string) (oses, arches []string) {
+	parts := strings.Split(filepath.Base(name), ".")
+	for _, part := range parts[1:] {
+		osName, arch, _ := strings.Cut(part, "-")
+		switch {
+		case knownOS[osName] && (arch == "" || knownArch[arch]):
+			oses = append(oses, osName)
+			if arch != "" {
+				arches = append(arches, arch)
+			}
+		case knownArch[part]:
+			arches = append(arches, part)
+		}
+	}
+	return oses, arches
+}
+
+// StripPlatform removes the platform parts and the extension of a script
+// name, giving the name a command is run by
+func StripPlatform(name string) string {
+	parts := strings.Split(filepath.Base(name), ".")
+	if len(parts) > 1 {
+		parts = parts[:len(parts)-1]
+	}
+	kept := parts[:1]
+	for _, part := range parts[1:] {
+		if oses, arches := NamePlatform("x." + part); len(oses) == 0 && len(arches) == 0 {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, ".")
+}
+
+// PlatformMismatch reports why a script does not run on the host platform,
+// from the constraints in its name and its os= and arch= metadata, or ""
+// when it does
+func PlatformMismatch(script string, meta ScriptMeta) string {
+	oses, arches := NamePlatform(script)
+	oses = append(oses, meta.OS...)
+	arches = append(arches, meta.Arch...)
+
+	var wanted []string
+	if len(oses) > 0 && !hasPlatform(oses, hostPlatform.OS) {
+		wanted = append(wanted, oses...)
+	}
+	if len(arches) > 0 && !hasPlatform(arches, hostPlatform.Arch) {
+		wanted = append(wanted, arches...)
+	}
+	if len(wanted) == 0 {
+		return ""
+	}
+	return "only for " + strings.Join(wanted, ", ")
+}
+
+// hasPlatform reports whether list holds s
+func hasPlatform(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
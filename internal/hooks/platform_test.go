@@ -0,0 +1,109 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNamePlatform(t *testing.T) {
+	tests := []struct {
+		name         string
+		oses, arches []string
+		commandName  string
+	}{
+		{"10-setup.darwin.sh", []string{"darwin"}, nil, "10-setup"},
+		{"seed.arm64.py", nil, []string{"arm64"}, "seed"},
+		{"init.linux-amd64.sh", []string{"linux"}, []string{"amd64"}, "init"},
+		{"setup.darwin", []string{"darwin"}, nil, "setup"},
+		{"linux.sh", nil, nil, "linux"},
+		{"db.seed.sh", nil, nil, "db.seed"},
+	}
+	for _, tc := range tests {
+		oses, arches := NamePlatform(tc.name)
+		if !reflect.DeepEqual(oses, tc.oses) || !reflect.DeepEqual(arches, tc.arches) {
+			t.Errorf("NamePlatform(%q) = %v, %v; want %v, %v", tc.name, oses, arches, tc.oses, tc.arches)
+		}
+		if got := StripPlatform(tc.name); got != tc.commandName {
+			t.Errorf("StripPlatform(%q) = %q, want %q", tc.name, got, tc.commandName)
+		}
+	}
+}
+
+func TestPlatformMismatch(t *testing.T) {
+	orig := hostPlatform
+	hostPlatform = Platform{OS: "linux", Arch: "arm64"}
+	defer func() { hostPlatform = orig }()
+
+	tests := []struct {
+		script string
+		meta   ScriptMeta
+		want   string
+	}{
+		{"10-setup.sh", ScriptMeta{}, ""},
+		{"10-setup.linux.sh", ScriptMeta{}, ""},
+		{"10-setup.darwin.sh", ScriptMeta{}, "only for darwin"},
+		{"10-setup.sh", ScriptMeta{OS: []string{"darwin", "linux"}}, ""},
+		{"10-setup.sh", ScriptMeta{OS: []string{"darwin"}, Arch: []string{"amd64"}}, "only for darwin, amd64"},
+		{"10-setup.linux-amd64.sh", ScriptMeta{}, "only for amd64"},
+	}
+	for _, tc := range tests {
+		if got := PlatformMismatch(tc.script, tc.meta); got != tc.want {
+			t.Errorf("PlatformMismatch(%q, %+v) = %q, want %q", tc.script, tc.meta, got, tc.want)
+		}
+	}
+}
+
+// recordingLogger collects log lines
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Info(msg string, args ...interface{})  { l.add(msg, args...) }
+func (l *recordingLogger) Warn(msg string, args ...interface{})  { l.add(msg, args...) }
+func (l *recordingLogger) Error(msg string, args ...interface{}) { l.add(msg, args...) }
+func (l *recordingLogger) add(msg string, args ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(msg, args...))
+}
+
+func TestExecuteSkipsOtherPlatforms(t *testing.T) {
+	orig := hostPlatform
+	hostPlatform = Platform{OS: "linux", Arch: "amd64"}
+	defer func() { hostPlatform = orig }()
+
+	workDir := t.TempDir()
+	eventDir := filepath.Join(workDir, ".space", "hooks", "post-up.d")
+	if err := os.MkdirAll(eventDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeScript(t, eventDir, "10-brew.darwin.sh", "#!/bin/sh\necho brew\n")
+	writeScript(t, eventDir, "20-apt.sh", "#!/bin/sh\n# space: os=linux\necho apt\n")
+	writeScript(t, eventDir, "30-rosetta.sh", "#!/bin/sh\n# space: arch=arm64\necho rosetta\n")
+
+	var stdout strings.Builder
+	logger := &recordingLogger{}
+	executor := NewScriptExecutor(workDir)
+	executor.Stdout = &stdout
+	executor.Logger = logger
+	executor.Verbose = true
+
+	hookCtx := NewHookContext()
+	hookCtx.WorkDir = workDir
+	if err := executor.Execute(context.Background(), PostUp, hookCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	if stdout.String() != "apt\n" {
+		t.Errorf("output = %q, want only the linux hook", stdout.String())
+	}
+	log := strings.Join(logger.lines, "\n")
+	for _, want := range []string{"Skipping 10-brew.darwin.sh (only for darwin)", "Skipping 30-rosetta.sh (only for arm64)", "Running 1 post-up hook(s)"} {
+		if !strings.Contains(log, want) {
+			t.Errorf("log missing %q:\n%s", want, log)
+		}
+	}
+}
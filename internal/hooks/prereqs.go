@@ -0,0 +1,160 @@
+package hooks
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+// Prerequisite is a command hooks need on the host
+type Prerequisite struct {
+	Command string
+
+	// Hooks names the scripts requiring the command, or "hooks.requires"
+	// for the config
+	Hooks []string
+}
+
+// lookPath finds a command on the PATH; a variable so tests can stub it
+var lookPath = exec.LookPath
+
+// installHint holds the ways to install a command; empty fields are not
+// available through that package manager
+type installHint struct {
+	brew, apt, dnf, goInstall string
+}
+
+// installHints are the install commands for tools hooks commonly use
+var installHints = map[string]installHint{
+	"jq":        {brew: "brew install jq", apt: "sudo apt-get install -y jq", dnf: "sudo dnf install -y jq"},
+	"yq":        {brew: "brew install yq", goInstall: "go install github.com/mikefarah/yq/v4@latest"},
+	"psql":      {brew: "brew install libpq && brew link --force libpq", apt: "sudo apt-get install -y postgresql-client", dnf: "sudo dnf install -y postgresql"},
+	"pg_dump":   {brew: "brew install libpq && brew link --force libpq", apt: "sudo apt-get install -y postgresql-client", dnf: "sudo dnf install -y postgresql"},
+	"mysql":     {brew: "brew install mysql-client", apt: "sudo apt-get install -y mysql-client", dnf: "sudo dnf install -y mysql"},
+	"redis-cli": {brew: "brew install redis", apt: "sudo apt-get install -y redis-tools", dnf: "sudo dnf install -y redis"},
+	"curl":      {brew: "brew install curl", apt: "sudo apt-get install -y curl", dnf: "sudo dnf install -y curl"},
+	"node":      {brew: "brew install node", apt: "sudo apt-get install -y nodejs", dnf: "sudo dnf install -y nodejs"},
+	"python3":   {brew: "brew install python", apt: "sudo apt-get install -y python3", dnf: "sudo dnf install -y python3"},
+	"ruby":      {brew: "brew install ruby", apt: "sudo apt-get install -y ruby", dnf: "sudo dnf install -y ruby"},
+	"aws":       {brew: "brew install awscli", apt: "sudo apt-get install -y awscli", dnf: "sudo dnf install -y awscli"},
+	"mc":        {brew: "brew install minio/stable/mc", goInstall: "go install github.com/minio/mc@latest"},
+	"river":     {goInstall: "go install github.com/riverqueue/river/cmd/river@latest"},
+	"goose":     {brew: "brew install goose", goInstall: "go install github.com/pressly/goose/v3/cmd/goose@latest"},
+	"migrate":   {brew: "brew install golang-migrate", goInstall: "go install -tags 'postgres mysql' github.com/golang-migrate/migrate/v4/cmd/migrate@latest"},
+	"sqlc":      {brew: "brew install sqlc", goInstall: "go install github.com/sqlc-dev/sqlc/cmd/sqlc@latest"},
+	"atlas":     {brew: "brew install ariga/tap/atlas"},
+
+	// Tunnel providers of space tunnel
+	"cloudflared": {brew: "brew install cloudflared"},
+	"ngrok":       {brew: "brew install ngrok"},
+}
+
+// InstallHint returns a copy-pastable command installing a tool with the
+// host's package manager, falling back to go install
+func InstallHint(command string) string {
+	hint, ok := installHints[command]
+	if !ok {
+		return "install " + command + " and make sure it is on the PATH"
+	}
+
+	var candidates []string
+	switch hostPlatform.OS {
+	case "darwin":
+		candidates = []string{hint.brew}
+	case "linux":
+		if _, err := lookPath("apt-get"); err == nil {
+			candidates = append(candidates, hint.apt)
+		}
+		if _, err := lookPath("dnf"); err == nil {
+			candidates = append(candidates, hint.dnf)
+		}
+		if _, err := lookPath("brew"); err == nil {
+			candidates = append(candidates, hint.brew)
+		}
+	}
+	candidates = append(candidates, hint.goInstall, hint.brew)
+	for _, c := range candidates {
+		if c != "" {
+			return c
+		}
+	}
+	return "install " + command + " and make sure it is on the PATH"
+}
+
+// Prerequisites collects the commands required by the config and by the
+// scripts of the given events. Scripts running in a container or meant for
+// another platform are left out, since the host doesn't need their tools.
+func Prerequisites(workDir string, events []EventType, required []string) []Prerequisite {
+	byCommand := make(map[string]*Prerequisite)
+	add := func(command, hook string) {
+		if command == "" {
+			return
+		}
+		p, ok := byCommand[command]
+		if !ok {
+			p = &Prerequisite{Command: command}
+			byCommand[command] = p
+		}
+		p.Hooks = append(p.Hooks, hook)
+	}
+
+	for _, command := range required {
+		add(command, "hooks.requires")
+	}
+
+	executor := NewScriptExecutor(workDir)
+	for _, event := range events {
+		scripts, err := executor.findScripts(filepath.Join(executor.HooksDir, string(event)+".d"))
+		if err != nil {
+			continue
+		}
+		for _, script := range scripts {
+			meta, err := ReadScriptMeta(script)
+			if err != nil || meta.Service != "" || PlatformMismatch(script, meta) != "" {
+				continue
+			}
+			for _, command := range meta.Requires {
+				add(command, string(event)+".d/"+filepath.Base(script))
+			}
+		}
+	}
+
+	prereqs := make([]Prerequisite, 0, len(byCommand))
+	for _, p := range byCommand {
+		prereqs = append(prereqs, *p)
+	}
+	sort.Slice(prereqs, func(i, j int) bool { return prereqs[i].Command < prereqs[j].Command })
+	return prereqs
+}
+
+// MissingPrerequisites returns the prerequisites found neither on the PATH
+// nor among the lib scripts and bundled helpers hooks get on theirs
+func MissingPrerequisites(workDir string, prereqs []Prerequisite) []Prerequisite {
+	lib := filepath.Join(workDir, ".space", "hooks", "lib")
+
+	var missing []Prerequisite
+	for _, p := range prereqs {
+		if isHelper(p.Command) {
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(lib, p.Command)); err == nil && info.Mode()&0111 != 0 {
+			continue
+		}
+		if _, err := lookPath(p.Command); err == nil {
+			continue
+		}
+		missing = append(missing, p)
+	}
+	return missing
+}
+
+// isHelper reports whether command is a bundled helper
+func isHelper(command string) bool {
+	for _, h := range Helpers {
+		if h.Name == command {
+			return true
+		}
+	}
+	return false
+}
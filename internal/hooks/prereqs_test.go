@@ -0,0 +1,87 @@
+package hooks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// stubLookPath makes lookPath find only the given commands
+func stubLookPath(t *testing.T, found ...string) {
+	t.Helper()
+	orig := lookPath
+	t.Cleanup(func() { lookPath = orig })
+	lookPath = func(file string) (string, error) {
+		for _, f := range found {
+			if f == file {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestPrerequisites(t *testing.T) {
+	orig := hostPlatform
+	hostPlatform = Platform{OS: "linux", Arch: "amd64"}
+	defer func() { hostPlatform = orig }()
+
+	workDir := t.TempDir()
+	hooksDir := filepath.Join(workDir, ".space", "hooks")
+	for _, dir := range []string{"pre-up.d", "post-up.d", "lib"} {
+		if err := os.MkdirAll(filepath.Join(hooksDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeScript(t, filepath.Join(hooksDir, "pre-up.d"), "10-check.sh", "#!/bin/sh\n# space: requires=jq,my-tool\n")
+	writeScript(t, filepath.Join(hooksDir, "post-up.d"), "10-seed.sh", "#!/bin/sh\n# space: requires=psql,wait-for-url\n")
+	writeScript(t, filepath.Join(hooksDir, "post-up.d"), "20-migrate.sh", "#!/bin/sh\n# space: service=web requires=rails\n")
+	writeScript(t, filepath.Join(hooksDir, "post-up.d"), "30-brew.darwin.sh", "#!/bin/sh\n# space: requires=brew\n")
+	writeScript(t, filepath.Join(hooksDir, "lib"), "my-tool", "#!/bin/sh\n")
+
+	prereqs := Prerequisites(workDir, []EventType{PreUp, PostUp}, []string{"jq"})
+	want := []Prerequisite{
+		{Command: "jq", Hooks: []string{"hooks.requires", "pre-up.d/10-check.sh"}},
+		{Command: "my-tool", Hooks: []string{"pre-up.d/10-check.sh"}},
+		{Command: "psql", Hooks: []string{"post-up.d/10-seed.sh"}},
+		{Command: "wait-for-url", Hooks: []string{"post-up.d/10-seed.sh"}},
+	}
+	if !reflect.DeepEqual(prereqs, want) {
+		t.Fatalf("Prerequisites = %+v, want %+v", prereqs, want)
+	}
+
+	stubLookPath(t, "jq")
+	missing := MissingPrerequisites(workDir, prereqs)
+	if len(missing) != 1 || missing[0].Command != "psql" {
+		t.Errorf("MissingPrerequisites = %+v, want only psql", missing)
+	}
+}
+
+func TestInstallHint(t *testing.T) {
+	orig := hostPlatform
+	defer func() { hostPlatform = orig }()
+
+	tests := []struct {
+		os      string
+		found   []string
+		command string
+		want    string
+	}{
+		{"darwin", nil, "jq", "brew install jq"},
+		{"linux", []string{"apt-get"}, "jq", "sudo apt-get install -y jq"},
+		{"linux", []string{"dnf"}, "psql", "sudo dnf install -y postgresql"},
+		{"linux", []string{"apt-get"}, "river", "go install github.com/riverqueue/river/cmd/river@latest"},
+		{"darwin", nil, "river", "go install github.com/riverqueue/river/cmd/river@latest"},
+		{"linux", nil, "atlas", "brew install ariga/tap/atlas"},
+		{"darwin", nil, "frobnicate", "install frobnicate and make sure it is on the PATH"},
+	}
+	for _, tc := range tests {
+		hostPlatform = Platform{OS: tc.os, Arch: "arm64"}
+		stubLookPath(t, tc.found...)
+		if got := InstallHint(tc.command); got != tc.want {
+			t.Errorf("InstallHint(%s) on %s = %q, want %q", tc.command, tc.os, got, tc.want)
+		}
+	}
+}
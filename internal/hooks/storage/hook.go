@@ -0,0 +1,337 @@
+// Package storage provides the built-in hook that creates S3 buckets and SQS
+// queues on MinIO or LocalStack once the service is ready after 'space up',
+// and exports the endpoint and credentials applications need.
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/hooks"
+)
+
+// Service kinds, detected from the service image
+const (
+	KindMinIO      = "minio"
+	KindLocalStack = "localstack"
+)
+
+// Default service ports and credentials, used when the service declares none
+const (
+	DefaultMinIOPort      = 9000
+	DefaultLocalStackPort = 4566
+	DefaultRegion         = "us-east-1"
+)
+
+// DefaultTimeout bounds waiting for the service to accept requests
+const DefaultTimeout = 60 * time.Second
+
+// mcImage provides the MinIO client, which the server image doesn't need
+const mcImage = "minio/mc:latest"
+
+// runDocker runs a docker command and returns its combined output, replaced
+// in tests
+var runDocker = func(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "docker", args...).CombinedOutput()
+	return strings.TrimSpace(string(out)), err
+}
+
+// DetectKind returns the kind of an S3-compatible service image, or ""
+func DetectKind(image string) string {
+	name := strings.ToLower(image)
+	switch {
+	case strings.Contains(name, "localstack"):
+		return KindLocalStack
+	case strings.Contains(name, "minio") && !strings.Contains(name, "minio/mc"):
+		return KindMinIO
+	}
+	return ""
+}
+
+// Options configure the storage hook. Credentials, endpoint and origins are
+// resolved by the caller from the project configuration.
+type Options struct {
+	// Kind is KindMinIO or KindLocalStack
+	Kind string
+
+	// Service is the compose service and Port its container port
+	Service string
+	Port    int
+
+	// Endpoint is the URL applications on the host reach the service at
+	Endpoint string
+
+	AccessKey string
+	SecretKey string
+	Region    string
+
+	Buckets []string
+	Queues  []string
+
+	// CORSOrigins are allowed on the buckets; empty leaves CORS alone
+	CORSOrigins []string
+
+	// EnvFile receives the exported variables; empty writes no file
+	EnvFile string
+
+	// Timeout bounds waiting for the service (default: DefaultTimeout)
+	Timeout time.Duration
+}
+
+// Hook creates buckets and queues on a MinIO or LocalStack service
+type Hook struct {
+	opts     Options
+	interval time.Duration
+}
+
+// NewHook creates the storage hook, filling in the defaults of the kind
+func NewHook(opts Options) (*Hook, error) {
+	switch opts.Kind {
+	case KindMinIO:
+		if len(opts.Queues) > 0 {
+			return nil, fmt.Errorf("queues need LocalStack, %s runs MinIO", opts.Service)
+		}
+		if opts.Port == 0 {
+			opts.Port = DefaultMinIOPort
+		}
+		if opts.AccessKey == "" && opts.SecretKey == "" {
+			opts.AccessKey, opts.SecretKey = "minioadmin", "minioadmin"
+		}
+	case KindLocalStack:
+		if opts.Port == 0 {
+			opts.Port = DefaultLocalStackPort
+		}
+		// LocalStack accepts any credentials
+		if opts.AccessKey == "" && opts.SecretKey == "" {
+			opts.AccessKey, opts.SecretKey = "test", "test"
+		}
+	default:
+		return nil, fmt.Errorf("service %q is not a minio or localstack service", opts.Service)
+	}
+
+	if opts.Region == "" {
+		opts.Region = DefaultRegion
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	return &Hook{opts: opts, interval: time.Second}, nil
+}
+
+// Name returns the hook name
+func (h *Hook) Name() string {
+	return "storage"
+}
+
+// Description returns the hook description
+func (h *Hook) Description() string {
+	return "Creates S3 buckets and SQS queues on MinIO or LocalStack"
+}
+
+// Events returns the events this hook handles
+func (h *Hook) Events() []hooks.EventType {
+	return []hooks.EventType{hooks.PostUp}
+}
+
+// EnvVars returns the variables applications use to reach the service
+func (h *Hook) EnvVars() map[string]string {
+	vars := map[string]string{
+		"AWS_ACCESS_KEY_ID":     h.opts.AccessKey,
+		"AWS_SECRET_ACCESS_KEY": h.opts.SecretKey,
+		"AWS_REGION":            h.opts.Region,
+		"AWS_DEFAULT_REGION":    h.opts.Region,
+	}
+	if h.opts.Endpoint != "" {
+		vars["AWS_ENDPOINT_URL"] = h.opts.Endpoint
+		vars["S3_ENDPOINT"] = h.opts.Endpoint
+		// Neither service serves virtual-hosted bucket names
+		vars["S3_FORCE_PATH_STYLE"] = "true"
+	}
+	return vars
+}
+
+// Execute waits for the service, creates the missing buckets and queues,
+// applies CORS and exports the connection variables
+func (h *Hook) Execute(ctx context.Context, event hooks.EventType, hookCtx *hooks.HookContext) error {
+	container, err := h.container(ctx, hookCtx.ProjectName)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🪣 Waiting for %s (%s)...\n", h.opts.Service, h.opts.Kind)
+	if err := h.waitReady(ctx, container); err != nil {
+		return err
+	}
+
+	var failed []string
+	step := func(name, script string) {
+		if out, err := h.run(ctx, container, script); err != nil {
+			fmt.Printf("   ✗ %s: %s\n", name, errorDetail(out, err))
+			failed = append(failed, name)
+			return
+		}
+		fmt.Printf("   ✓ %s\n", name)
+	}
+
+	for _, bucket := range h.opts.Buckets {
+		step("bucket "+bucket, h.bucketScript(bucket))
+	}
+	for _, queue := range h.opts.Queues {
+		step("queue "+queue, fmt.Sprintf("awslocal sqs create-queue --queue-name %s >/dev/null", quote(queue)))
+	}
+	if script := h.corsScript(); script != "" {
+		step("CORS for "+strings.Join(h.opts.CORSOrigins, ", "), script)
+	}
+
+	vars := h.EnvVars()
+	hookCtx.AddEnvVars(vars)
+	if h.opts.EnvFile != "" {
+		if err := writeEnvFile(h.opts.EnvFile, vars); err != nil {
+			return err
+		}
+		fmt.Printf("   ✓ %s\n", h.opts.EnvFile)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to create %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// container returns the running container of the service
+func (h *Hook) container(ctx context.Context, project string) (string, error) {
+	out, err := runDocker(ctx, "ps", "-q",
+		"--filter", "label=com.docker.compose.project="+project,
+		"--filter", "label=com.docker.compose.service="+h.opts.Service)
+	if err != nil {
+		return "", fmt.Errorf("failed to find %s container: %s", h.opts.Service, errorDetail(out, err))
+	}
+	containers := strings.Fields(out)
+	if len(containers) == 0 {
+		return "", fmt.Errorf("no running container for %s", h.opts.Service)
+	}
+	return containers[0], nil
+}
+
+// waitReady retries listing buckets until it succeeds or the timeout passes
+func (h *Hook) waitReady(ctx context.Context, container string) error {
+	ctx, cancel := context.WithTimeout(ctx, h.opts.Timeout)
+	defer cancel()
+
+	script := "mc ls space >/dev/null"
+	if h.opts.Kind == KindLocalStack {
+		script = "awslocal s3api list-buckets >/dev/null"
+	}
+
+	for {
+		out, err := h.run(ctx, container, script)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not ready after %s: %s", h.opts.Service, h.opts.Timeout, errorDetail(out, err))
+		case <-time.After(h.interval):
+		}
+	}
+}
+
+// run executes a script against the service: in an mc container sharing the
+// MinIO network, or inside LocalStack, which ships awslocal
+func (h *Hook) run(ctx context.Context, container, script string) (string, error) {
+	if h.opts.Kind == KindMinIO {
+		host := url.URL{
+			Scheme: "http",
+			User:   url.UserPassword(h.opts.AccessKey, h.opts.SecretKey),
+			Host:   fmt.Sprintf("127.0.0.1:%d", h.opts.Port),
+		}
+		return runDocker(ctx, "run", "--rm", "--network", "container:"+container,
+			"-e", "MC_HOST_space="+host.String(),
+			"--entrypoint", "sh", mcImage, "-c", script)
+	}
+	return runDocker(ctx, "exec", "-e", "AWS_DEFAULT_REGION="+h.opts.Region, container, "sh", "-c", script)
+}
+
+// bucketScript creates a bucket unless it exists
+func (h *Hook) bucketScript(bucket string) string {
+	if h.opts.Kind == KindMinIO {
+		return "mc mb --ignore-existing " + quote("space/"+bucket)
+	}
+	return fmt.Sprintf("awslocal s3api head-bucket --bucket %[1]s >/dev/null 2>&1 || awslocal s3 mb s3://%[1]s", quote(bucket))
+}
+
+// corsScript allows the configured origins: server-wide on MinIO, which has
+// no bucket CORS, and per bucket on LocalStack
+func (h *Hook) corsScript() string {
+	if len(h.opts.CORSOrigins) == 0 {
+		return ""
+	}
+	if h.opts.Kind == KindMinIO {
+		return "mc admin config set space api " + quote("cors_allow_origin="+strings.Join(h.opts.CORSOrigins, ","))
+	}
+	if len(h.opts.Buckets) == 0 {
+		return ""
+	}
+
+	policy, _ := json.Marshal(map[string]interface{}{
+		"CORSRules": []map[string]interface{}{{
+			"AllowedOrigins": h.opts.CORSOrigins,
+			"AllowedMethods": []string{"GET", "PUT", "POST", "DELETE", "HEAD"},
+			"AllowedHeaders": []string{"*"},
+			"ExposeHeaders":  []string{"ETag"},
+		}},
+	})
+	var commands []string
+	for _, bucket := range h.opts.Buckets {
+		commands = append(commands, fmt.Sprintf("awslocal s3api put-bucket-cors --bucket %s --cors-configuration %s", quote(bucket), quote(string(policy))))
+	}
+	return strings.Join(commands, " && ")
+}
+
+// writeEnvFile writes vars as a dotenv file
+func writeEnvFile(path string, vars map[string]string) error {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString("# Auto-generated by the space storage hook\n")
+	for _, k := range keys {
+		value := vars[k]
+		// Quote values the shell would split, so the file can be sourced
+		if strings.ContainsAny(value, " \t'\"$`\\#;&|<>()") {
+			value = quote(value)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", k, value)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// Holds credentials
+	return os.WriteFile(path, []byte(b.String()), 0600)
+}
+
+// errorDetail prefers the command output over the exit status
+func errorDetail(out string, err error) string {
+	if out != "" {
+		return out
+	}
+	return err.Error()
+}
+
+// quote quotes s for /bin/sh
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
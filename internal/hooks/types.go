@@ -2,6 +2,7 @@ package hooks
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/happy-sdk/space-cli/internal/provider"
 )
@@ -25,6 +26,10 @@ const (
 	// Service events
 	OnServiceStart EventType = "on-service-start"
 	OnServiceStop  EventType = "on-service-stop"
+
+	// OnServiceHealthy fires once per service when its health check first
+	// passes after up, with ServiceName set
+	OnServiceHealthy EventType = "on-service-healthy"
 )
 
 // String returns the string representation of the event type
@@ -43,6 +48,7 @@ func AllEventTypes() []EventType {
 		OnEnvChange,
 		OnServiceStart,
 		OnServiceStop,
+		OnServiceHealthy,
 	}
 }
 
@@ -79,6 +85,9 @@ type ServiceInfo struct {
 	// URL is the full URL to access the service
 	URL string
 
+	// PublicURL is the URL of the service's public tunnel, if one is open
+	PublicURL string
+
 	// Status is the service status (running, stopped, etc.)
 	Status string
 
@@ -115,6 +124,10 @@ type HookContext struct {
 	// ComposeFiles is the list of docker-compose files in use
 	ComposeFiles []string
 
+	// StartupLogs is the file with what each service logged while
+	// starting, set for post-up hooks when hooks.startup_logs is configured
+	StartupLogs string
+
 	// Metadata allows hooks to store arbitrary data
 	Metadata map[string]interface{}
 
@@ -171,6 +184,37 @@ func (c *HookContext) GetMetadata(key string) (interface{}, bool) {
 	return v, ok
 }
 
+// envVarsKey is the metadata key holding variables hooks export
+const envVarsKey = "env_vars"
+
+// AddEnvVars merges variables into those exported to later hooks. Hook
+// scripts receive them in their environment.
+func (c *HookContext) AddEnvVars(vars map[string]string) {
+	merged := c.EnvVars()
+	for k, v := range vars {
+		merged[k] = v
+	}
+	c.SetMetadata(envVarsKey, merged)
+}
+
+// EnvVars returns a copy of the variables exported by hooks
+func (c *HookContext) EnvVars() map[string]string {
+	vars := make(map[string]string)
+	v, _ := c.GetMetadata(envVarsKey)
+	switch t := v.(type) {
+	case map[string]string:
+		for k, val := range t {
+			vars[k] = val
+		}
+	case map[string]interface{}:
+		// Metadata read back from JSON
+		for k, val := range t {
+			vars[k] = fmt.Sprint(val)
+		}
+	}
+	return vars
+}
+
 // Hook defines the interface that all hooks must implement
 type Hook interface {
 	// Name returns the unique name of this hook
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os/exec"
 	"strings"
+	"time"
 )
 
 // Provider represents a Docker provider
@@ -16,6 +17,10 @@ const (
 	ProviderGeneric       Provider = "generic"
 )
 
+// detectTimeout bounds detection, so a docker daemon that doesn't answer
+// falls back to the generic provider
+const detectTimeout = 10 * time.Second
+
 // Detector detects the Docker provider
 type Detector struct{}
 
@@ -26,6 +31,9 @@ func NewDetector() *Detector {
 
 // Detect detects the Docker provider
 func (d *Detector) Detect(ctx context.Context) (Provider, error) {
+	ctx, cancel := context.WithTimeout(ctx, detectTimeout)
+	defer cancel()
+
 	// Check if OrbStack is running
 	if d.isOrbStack(ctx) {
 		return ProviderOrbStack, nil
@@ -0,0 +1,310 @@
+// Package render adapts the emoji-marked lines space prints to the terminal
+// showing them: emoji, plain unicode symbols or ASCII, with status lines
+// colored by a theme, or no colors at all.
+package render
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Symbols selects how status symbols are drawn
+type Symbols string
+
+const (
+	// Emoji draws symbols as written, e.g. ✅ and ⚠️
+	Emoji Symbols = "emoji"
+
+	// Unicode draws single-width symbols such as ✔ and ⚠, for terminals
+	// that render emoji poorly
+	Unicode Symbols = "unicode"
+
+	// ASCII draws symbols such as [ok] and [!], for CI logs and TERM=dumb
+	ASCII Symbols = "ascii"
+)
+
+// Theme holds the SGR color codes of the kinds of status lines; empty codes
+// leave a line uncolored
+type Theme struct {
+	Success string
+	Error   string
+	Warning string
+	Info    string
+	Tip     string
+}
+
+// Themes are the built-in color themes
+var Themes = map[string]Theme{
+	"default": {Success: "32", Error: "31", Warning: "33", Info: "36", Tip: "2"},
+	"bright":  {Success: "1;92", Error: "1;91", Warning: "1;93", Info: "1;96", Tip: "1"},
+	"mono":    {},
+}
+
+// DefaultTheme is the theme used when none is configured
+const DefaultTheme = "default"
+
+// kind is the kind of status a symbol marks, which picks its color
+type kind int
+
+const (
+	plain kind = iota
+	success
+	failure
+	warning
+	info
+	tip
+)
+
+// symbol is a symbol space prints and its replacements
+type symbol struct {
+	emoji   string
+	unicode string
+	ascii   string
+	kind    kind
+}
+
+// symbols lists the status symbols; other emoji become a bullet. Longer
+// forms come first so ⚠️ is matched before ⚠.
+var symbols = []symbol{
+	{"⚠️", "⚠", "[!]", warning},
+	{"⚠", "⚠", "[!]", warning},
+	{"✅", "✔", "[ok]", success},
+	{"✓", "✓", "+", success},
+	{"❌", "✖", "[x]", failure},
+	{"✗", "✗", "x", failure},
+	{"💥", "✖", "[x]", failure},
+	{"💡", "›", "*", tip},
+	{"ℹ️", "ℹ", "[i]", info},
+	{"ℹ", "ℹ", "[i]", info},
+	{"❓", "?", "[?]", plain},
+	{"⏳", "…", "...", plain},
+	{"⏱️", "◷", "[t]", plain},
+	{"⏱", "◷", "[t]", plain},
+	{"🛑", "■", "[stop]", plain},
+	{"➖", "-", "-", plain},
+	{"•", "•", "-", plain},
+	{"→", "→", "->", plain},
+	{"…", "…", "...", plain},
+	{"─", "─", "-", plain},
+	{"├", "├", "|", plain},
+	{"└", "└", "`", plain},
+}
+
+// spinnerFrames are the braille frames of the progress spinner; ASCII
+// replaces them in turn with a classic |/-\ spinner
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// Renderer draws text for one output stream
+type Renderer struct {
+	Symbols Symbols
+	Color   bool
+	Theme   Theme
+}
+
+// Plain draws text as written, without colors
+var Plain = &Renderer{Symbols: Emoji}
+
+// Options are the configured output settings
+type Options struct {
+	// Symbols is "emoji", "unicode" or "ascii"; empty picks ascii on
+	// TERM=dumb and emoji otherwise
+	Symbols string
+
+	// Theme names one of Themes; empty is DefaultTheme
+	Theme string
+}
+
+// Detect returns the renderer for a stream. Colors need a terminal, an
+// unset NO_COLOR and a TERM other than dumb.
+func Detect(opts Options, terminal bool, getenv func(string) string) (*Renderer, error) {
+	r := &Renderer{Symbols: Emoji}
+
+	dumb := getenv("TERM") == "dumb"
+	switch Symbols(opts.Symbols) {
+	case "":
+		if dumb {
+			r.Symbols = ASCII
+		}
+	case Emoji, Unicode, ASCII:
+		r.Symbols = Symbols(opts.Symbols)
+	default:
+		return Plain, fmt.Errorf("unknown output.symbols %q: use emoji, unicode or ascii", opts.Symbols)
+	}
+
+	name := opts.Theme
+	if name == "" {
+		name = DefaultTheme
+	}
+	theme, ok := Themes[name]
+	if !ok {
+		return Plain, fmt.Errorf("unknown output.theme %q: use default, bright or mono", opts.Theme)
+	}
+	r.Theme = theme
+	r.Color = terminal && !dumb && getenv("NO_COLOR") == "" && theme != (Theme{})
+	return r, nil
+}
+
+// String replaces the symbols in s for the renderer's symbol set
+func (r *Renderer) String(s string) string {
+	if r.Symbols == Emoji || isASCII(s) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] < 0x80 {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		if sym, ok := symbolAt(s[i:]); ok {
+			b.WriteString(r.draw(sym))
+			i += len(sym.emoji)
+			continue
+		}
+		c, size := utf8.DecodeRuneInString(s[i:])
+		switch frame := slices.Index(spinnerFrames, c); {
+		case frame >= 0 && r.Symbols == ASCII:
+			b.WriteByte(`|/-\`[frame%4])
+		case c == variationSelector:
+			// Presentation selector of a replaced emoji
+		case isEmoji(c):
+			if r.Symbols == ASCII {
+				b.WriteByte('*')
+			} else {
+				b.WriteRune('•')
+			}
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// Line renders one line: its symbols, and its color from the status
+// symbol it starts with, if any
+func (r *Renderer) Line(line string) string {
+	if !r.Color {
+		return r.String(line)
+	}
+	code := r.color(line)
+	line = r.String(line)
+	if code == "" || line == "" {
+		return line
+	}
+	return "\033[" + code + "m" + line + "\033[0m"
+}
+
+// color returns the SGR code for a line starting with a status symbol,
+// after any indentation
+func (r *Renderer) color(line string) string {
+	sym, ok := symbolAt(strings.TrimLeft(line, " "))
+	if !ok {
+		return ""
+	}
+	switch sym.kind {
+	case success:
+		return r.Theme.Success
+	case failure:
+		return r.Theme.Error
+	case warning:
+		return r.Theme.Warning
+	case info:
+		return r.Theme.Info
+	case tip:
+		return r.Theme.Tip
+	}
+	return ""
+}
+
+// draw returns a symbol in the renderer's symbol set
+func (r *Renderer) draw(sym symbol) string {
+	switch r.Symbols {
+	case Unicode:
+		return sym.unicode
+	case ASCII:
+		return sym.ascii
+	}
+	return sym.emoji
+}
+
+// symbolAt returns the status symbol s starts with
+func symbolAt(s string) (symbol, bool) {
+	for _, sym := range symbols {
+		if strings.HasPrefix(s, sym.emoji) {
+			return sym, true
+		}
+	}
+	return symbol{}, false
+}
+
+// Writer renders everything written to it, line by line, onto the writer
+// returned by target at the time of the write, so redirections made after
+// it is created still apply. Partial lines such as prompts are passed on
+// right away.
+type Writer struct {
+	target   func() io.Writer
+	renderer func() *Renderer
+
+	mu sync.Mutex
+
+	// midLine is set while the last write ended inside a line
+	midLine bool
+}
+
+// NewWriter creates a Writer rendering with the renderer that renderer
+// returns, which is asked on every write so it can be picked lazily
+func NewWriter(target func() io.Writer, renderer func() *Renderer) *Writer {
+	return &Writer{target: target, renderer: renderer}
+}
+
+// Write renders p and writes it to the target. It reports len(p) on
+// success, since rendering changes the byte count.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	r := w.renderer()
+	if r.Symbols == Emoji && !r.Color {
+		w.midLine = len(p) > 0 && p[len(p)-1] != '\n'
+		return w.target().Write(p)
+	}
+
+	var b strings.Builder
+	lines := strings.SplitAfter(string(p), "\n")
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		text, newline := strings.CutSuffix(line, "\n")
+		if w.midLine {
+			// The start of the line was colored on its own
+			b.WriteString(r.String(text))
+		} else {
+			b.WriteString(r.Line(text))
+		}
+		if newline {
+			b.WriteByte('\n')
+		}
+		w.midLine = !newline
+	}
+	if _, err := io.WriteString(w.target(), b.String()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// isASCII reports whether s holds only ASCII
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,123 @@
+package render
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		terminal bool
+		env      map[string]string
+		symbols  Symbols
+		color    bool
+		wantErr  bool
+	}{
+		{"terminal", Options{}, true, nil, Emoji, true, false},
+		{"pipe", Options{}, false, nil, Emoji, false, false},
+		{"NO_COLOR", Options{}, true, map[string]string{"NO_COLOR": "1"}, Emoji, false, false},
+		{"dumb terminal", Options{}, true, map[string]string{"TERM": "dumb"}, ASCII, false, false},
+		{"configured symbols win", Options{Symbols: "unicode"}, true, map[string]string{"TERM": "dumb"}, Unicode, false, false},
+		{"mono theme", Options{Theme: "mono"}, true, nil, Emoji, false, false},
+		{"unknown symbols", Options{Symbols: "braille"}, true, nil, Emoji, false, true},
+		{"unknown theme", Options{Theme: "neon"}, true, nil, Emoji, false, true},
+	}
+	for _, tc := range tests {
+		r, err := Detect(tc.opts, tc.terminal, env(tc.env))
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tc.name, err, tc.wantErr)
+		}
+		if r.Symbols != tc.symbols || r.Color != tc.color {
+			t.Errorf("%s: symbols %s color %t, want %s %t", tc.name, r.Symbols, r.Color, tc.symbols, tc.color)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	ascii := &Renderer{Symbols: ASCII}
+	unicode := &Renderer{Symbols: Unicode}
+
+	tests := []struct {
+		r    *Renderer
+		in   string
+		want string
+	}{
+		{ascii, "✅ Services started", "[ok] Services started"},
+		{ascii, "⚠️  Hook failed", "[!]  Hook failed"},
+		{ascii, "   • web → http://web.space.local", "   - web -> http://web.space.local"},
+		{ascii, "📦 Project name: café", "* Project name: café"},
+		{ascii, "⠙ waiting", "/ waiting"},
+		{unicode, "✅ ok ⚠️ warn 🪝 hooks", "✔ ok ⚠ warn • hooks"},
+		{Plain, "✅ unchanged", "✅ unchanged"},
+	}
+	for _, tc := range tests {
+		if got := tc.r.String(tc.in); got != tc.want {
+			t.Errorf("%s String(%q) = %q, want %q", tc.r.Symbols, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestWriter(t *testing.T) {
+	var out strings.Builder
+	r := &Renderer{Symbols: ASCII, Color: true, Theme: Themes["default"]}
+	w := NewWriter(func() io.Writer { return &out }, func() *Renderer { return r })
+
+	for _, s := range []string{"✅ done\n   ❌ failed\nplain\n", "❓ Continue? ", "[y/N] ✓\n"} {
+		if n, err := w.Write([]byte(s)); err != nil || n != len(s) {
+			t.Fatalf("Write(%q) = %d, %v", s, n, err)
+		}
+	}
+
+	want := "\033[32m[ok] done\033[0m\n" +
+		"\033[31m   [x] failed\033[0m\n" +
+		"plain\n" +
+		"[?] Continue? [y/N] +\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"web", 3},
+		{"✅ ok", 5},
+		{"⚠️ warn", 7},
+		{"⏸️ paused", 9},
+		{"•", 1},
+		{"日本", 4},
+		{"\033[32mok\033[0m", 2},
+	}
+	for _, tc := range tests {
+		if got := Width(tc.s); got != tc.want {
+			t.Errorf("Width(%q) = %d, want %d", tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	var out strings.Builder
+	table := NewTable(&out, Plain, 3)
+	_, _ = io.WriteString(table, "SERVICE\tSTATE\tURL\n")
+	_, _ = io.WriteString(table, "web\t✅ running\thttp://web.space.local\n")
+	_, _ = io.WriteString(table, "db\t⏸️ paused\t-\n")
+	if err := table.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "SERVICE   STATE        URL\n" +
+		"web       ✅ running   http://web.space.local\n" +
+		"db        ⏸️ paused    -\n"
+	if out.String() != want {
+		t.Errorf("table =\n%s\nwant\n%s", out.String(), want)
+	}
+}
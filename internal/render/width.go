@@ -0,0 +1,173 @@
+package render
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Presentation selectors and the joiner of emoji sequences. The emoji
+// selector, as in ⚠️, makes a narrow symbol two columns wide.
+const (
+	variationSelector = '\uFE0F'
+	textSelector      = '\uFE0E'
+	zeroWidthJoiner   = '\u200D'
+)
+
+// wideRanges are the code points terminals draw two columns wide: East
+// Asian wide characters and emoji
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115F},
+	{0x231A, 0x231B},
+	{0x23E9, 0x23EC},
+	{0x23F0, 0x23F0},
+	{0x23F3, 0x23F3},
+	{0x25FD, 0x25FE},
+	{0x2614, 0x2615},
+	{0x26AA, 0x26AB},
+	{0x26BD, 0x26BE},
+	{0x26C4, 0x26C5},
+	{0x26D4, 0x26D4},
+	{0x26EA, 0x26EA},
+	{0x26F2, 0x26F5},
+	{0x26FA, 0x26FD},
+	{0x2705, 0x2705},
+	{0x270A, 0x270B},
+	{0x2728, 0x2728},
+	{0x274C, 0x274C},
+	{0x274E, 0x274E},
+	{0x2753, 0x2755},
+	{0x2757, 0x2757},
+	{0x2795, 0x2797},
+	{0x27B0, 0x27B0},
+	{0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C},
+	{0x2B50, 0x2B50},
+	{0x2B55, 0x2B55},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE30, 0xFE4F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F300, 0x1F64F},
+	{0x1F680, 0x1F6FF},
+	{0x1F900, 0x1F9FF},
+	{0x1FA70, 0x1FAFF},
+	{0x20000, 0x3FFFD},
+}
+
+// isWide reports whether c is drawn two columns wide
+func isWide(c rune) bool {
+	for _, r := range wideRanges {
+		if c < r.lo {
+			return false
+		}
+		if c <= r.hi {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmoji reports whether c is a pictographic emoji, as opposed to a wide
+// letter
+func isEmoji(c rune) bool {
+	return c >= 0x1F300 && c <= 0x1FAFF || c >= 0x2600 && c <= 0x27BF || c >= 0x2300 && c <= 0x23FF
+}
+
+// Width returns the number of columns s takes on a terminal, skipping
+// color escapes
+func Width(s string) int {
+	width := 0
+	var prev rune
+	for i := 0; i < len(s); {
+		if strings.HasPrefix(s[i:], "\033[") {
+			// Skip a CSI sequence such as \033[32m up to its final byte
+			end := strings.IndexFunc(s[i+2:], func(c rune) bool { return c >= '@' && c <= '~' })
+			if end >= 0 {
+				i += end + 3
+				continue
+			}
+		}
+
+		c, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case c == variationSelector:
+			if prev != 0 && !isWide(prev) {
+				width++
+			}
+		case c == zeroWidthJoiner || c == textSelector || unicode.Is(unicode.Mn, c):
+		case isWide(c):
+			width += 2
+		default:
+			width++
+		}
+		prev = c
+	}
+	return width
+}
+
+// Table lays out tab-terminated cells in aligned columns like
+// text/tabwriter, but measures cells by their width on screen, so emoji and
+// wide characters keep the columns straight. Symbols are rendered before
+// measuring.
+type Table struct {
+	w        io.Writer
+	renderer *Renderer
+	padding  int
+	buf      bytes.Buffer
+}
+
+// NewTable creates a table writing to w, with padding spaces between columns
+func NewTable(w io.Writer, renderer *Renderer, padding int) *Table {
+	return &Table{w: w, renderer: renderer, padding: padding}
+}
+
+// Write buffers rows until Flush
+func (t *Table) Write(p []byte) (int, error) {
+	return t.buf.Write(p)
+}
+
+// Flush writes the buffered rows, padding every tab-terminated cell to the
+// width of its column. The text after the last tab of a row is not part
+// of a column.
+func (t *Table) Flush() error {
+	text := strings.TrimSuffix(t.buf.String(), "\n")
+	t.buf.Reset()
+	if text == "" {
+		return nil
+	}
+
+	var rows [][]string
+	var widths []int
+	for _, line := range strings.Split(text, "\n") {
+		cells := strings.Split(t.renderer.String(line), "\t")
+		for i, cell := range cells[:len(cells)-1] {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], Width(cell))
+		}
+		rows = append(rows, cells)
+	}
+
+	var b strings.Builder
+	for _, cells := range rows {
+		for i, cell := range cells[:len(cells)-1] {
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-Width(cell)+t.padding))
+		}
+		b.WriteString(cells[len(cells)-1])
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
@@ -0,0 +1,276 @@
+// Package secrets resolves secrets from external stores and renders them into
+// ephemeral files that docker compose can reference
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/redact"
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Secret sources
+const (
+	SourceEnv       = "env"
+	SourceKeychain  = "keychain"
+	SourceOnePasswd = "1password"
+)
+
+// Resolver fetches secret values
+type Resolver struct {
+	// lookupEnv reads environment variables
+	lookupEnv func(string) (string, bool)
+
+	// run executes an external command and returns its stdout
+	run func(ctx context.Context, name string, args ...string) (string, error)
+}
+
+// NewResolver creates a resolver that reads the process environment and
+// shells out to `security` and `op`
+func NewResolver() *Resolver {
+	return &Resolver{
+		lookupEnv: os.LookupEnv,
+		run:       runCommand,
+	}
+}
+
+// Resolve returns the value of a single secret. Resolved values are
+// registered with the output redactor.
+func (r *Resolver) Resolve(ctx context.Context, name string, secret config.SecretConfig) (string, error) {
+	value, err := r.resolve(ctx, name, secret)
+	if err != nil {
+		return "", err
+	}
+	redact.Add(value)
+	return value, nil
+}
+
+// resolve fetches a secret from its source
+func (r *Resolver) resolve(ctx context.Context, name string, secret config.SecretConfig) (string, error) {
+	key := secret.Key
+	if key == "" {
+		key = name
+	}
+
+	switch secret.Source {
+	case SourceEnv, "":
+		value, ok := r.lookupEnv(key)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", key)
+		}
+		return value, nil
+
+	case SourceKeychain:
+		args := []string{"find-generic-password", "-w"}
+		service, account, hasAccount := strings.Cut(key, "/")
+		args = append(args, "-s", service)
+		if hasAccount {
+			args = append(args, "-a", account)
+		}
+		value, err := r.run(ctx, "security", args...)
+		if err != nil {
+			return "", fmt.Errorf("keychain item %s: %w", key, err)
+		}
+		return strings.TrimRight(value, "\n"), nil
+
+	case SourceOnePasswd, "op":
+		value, err := r.run(ctx, "op", "read", "--no-newline", key)
+		if err != nil {
+			return "", fmt.Errorf("1password reference %s: %w", key, err)
+		}
+		return value, nil
+
+	default:
+		return "", fmt.Errorf("unknown secret source %q", secret.Source)
+	}
+}
+
+// Check resolves every secret and returns the errors keyed by secret name
+func (r *Resolver) Check(ctx context.Context, secrets map[string]config.SecretConfig) map[string]error {
+	errs := make(map[string]error)
+	for name, secret := range secrets {
+		if _, err := r.Resolve(ctx, name, secret); err != nil {
+			errs[name] = err
+		}
+	}
+	return errs
+}
+
+// Render resolves all secrets and writes them below dir: one env file per
+// service plus one file per file-mounted secret. It returns a compose
+// override document referencing those files, or "" if there is nothing to
+// inject. Files are created with 0600 permissions.
+func (r *Resolver) Render(ctx context.Context, dir string, secrets map[string]config.SecretConfig, services []string) (string, error) {
+	if len(secrets) == 0 {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+
+	envs := make(map[string]map[string]string)
+	mounts := make(map[string][]string)
+
+	for _, name := range sortedNames(secrets) {
+		secret := secrets[name]
+		value, err := r.Resolve(ctx, name, secret)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve secret %s: %w", name, err)
+		}
+
+		targets := secret.Services
+		if len(targets) == 0 {
+			targets = services
+		}
+
+		if secret.File != "" {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(value), 0600); err != nil {
+				return "", fmt.Errorf("failed to write secret %s: %w", name, err)
+			}
+			for _, svc := range targets {
+				mounts[svc] = append(mounts[svc], path+":"+secret.File+":ro")
+			}
+			continue
+		}
+
+		envName := secret.Env
+		if envName == "" {
+			envName = name
+		}
+		for _, svc := range targets {
+			if envs[svc] == nil {
+				envs[svc] = make(map[string]string)
+			}
+			envs[svc][envName] = value
+		}
+	}
+
+	override := make(map[string]interface{})
+	for svc, vars := range envs {
+		path := filepath.Join(dir, svc+".env")
+		data, err := formatEnvFile(vars)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			return "", fmt.Errorf("failed to write env file for %s: %w", svc, err)
+		}
+		serviceOverride(override, svc)["env_file"] = []string{path}
+	}
+	for svc, volumes := range mounts {
+		serviceOverride(override, svc)["volumes"] = volumes
+	}
+
+	if len(override) == 0 {
+		return "", nil
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{"services": override})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal secrets override: %w", err)
+	}
+	return string(data), nil
+}
+
+// Preview returns the services override Render would produce for dir
+// without resolving any secret or writing files
+func Preview(dir string, secrets map[string]config.SecretConfig, services []string) map[string]interface{} {
+	override := make(map[string]interface{})
+	for _, name := range sortedNames(secrets) {
+		secret := secrets[name]
+		targets := secret.Services
+		if len(targets) == 0 {
+			targets = services
+		}
+
+		for _, svc := range targets {
+			entry := serviceOverride(override, svc)
+			if secret.File != "" {
+				volumes, _ := entry["volumes"].([]string)
+				entry["volumes"] = append(volumes, filepath.Join(dir, name)+":"+secret.File+":ro")
+			} else {
+				entry["env_file"] = []string{filepath.Join(dir, svc+".env")}
+			}
+		}
+	}
+	return override
+}
+
+// serviceOverride returns the override entry of a service, creating it
+func serviceOverride(override map[string]interface{}, svc string) map[string]interface{} {
+	entry, ok := override[svc].(map[string]interface{})
+	if !ok {
+		entry = make(map[string]interface{})
+		override[svc] = entry
+	}
+	return entry
+}
+
+// formatEnvFile renders KEY='VALUE' lines in sorted order. Compose
+// interpolates $VAR and strips " #" comments in unquoted values, but keeps
+// single-quoted ones verbatim, newlines included. A single quote can't be
+// escaped inside them, nor can a trailing backslash, so such values are
+// refused rather than silently changed.
+func formatEnvFile(vars map[string]string) (string, error) {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		value := vars[k]
+		if strings.Contains(value, "'") || strings.HasSuffix(value, "\\") {
+			return "", fmt.Errorf("secret %s contains a single quote or ends in a backslash, which compose env files can't represent; mount it as a file instead", k)
+		}
+		fmt.Fprintf(&b, "%s='%s'\n", k, value)
+	}
+	return b.String(), nil
+}
+
+// sortedNames returns secret names in sorted order
+func sortedNames(secrets map[string]config.SecretConfig) []string {
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// commandTimeout bounds secret manager commands, leaving time to unlock them
+const commandTimeout = 2 * time.Minute
+
+// runCommand executes a command and returns its stdout
+func runCommand(ctx context.Context, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("%s did not respond within %s", name, commandTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
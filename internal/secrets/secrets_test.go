@@ -0,0 +1,170 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/happy-sdk/space-cli/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// fakeResolver returns a resolver backed by fixed values
+func fakeResolver(env map[string]string, commands map[string]string) *Resolver {
+	return &Resolver{
+		lookupEnv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+		run: func(ctx context.Context, name string, args ...string) (string, error) {
+			cmd := name + " " + strings.Join(args, " ")
+			if out, ok := commands[cmd]; ok {
+				return out, nil
+			}
+			return "", errors.New("not found")
+		},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	r := fakeResolver(
+		map[string]string{"STRIPE_KEY": "sk_test", "GITHUB_TOKEN": "ghp"},
+		map[string]string{
+			"security find-generic-password -w -s dev-db -a admin": "hunter2\n",
+			"op read --no-newline op://dev/sentry/dsn":             "https://sentry",
+		},
+	)
+
+	tests := []struct {
+		name    string
+		secret  config.SecretConfig
+		want    string
+		wantErr bool
+	}{
+		{"STRIPE_KEY", config.SecretConfig{Source: "env"}, "sk_test", false},
+		{"token", config.SecretConfig{Source: "env", Key: "GITHUB_TOKEN"}, "ghp", false},
+		{"missing", config.SecretConfig{Source: "env"}, "", true},
+		{"db", config.SecretConfig{Source: "keychain", Key: "dev-db/admin"}, "hunter2", false},
+		{"sentry", config.SecretConfig{Source: "1password", Key: "op://dev/sentry/dsn"}, "https://sentry", false},
+		{"vault", config.SecretConfig{Source: "vault"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Resolve(context.Background(), tt.name, tt.secret)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	r := fakeResolver(map[string]string{"STRIPE_KEY": "sk_test", "TLS_KEY": "-----BEGIN-----"}, nil)
+
+	secrets := map[string]config.SecretConfig{
+		"STRIPE_KEY": {Source: "env", Services: []string{"api"}},
+		"tls":        {Source: "env", Key: "TLS_KEY", File: "/run/secrets/tls.key"},
+	}
+
+	doc, err := r.Render(context.Background(), dir, secrets, []string{"api", "web"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	var override struct {
+		Services map[string]struct {
+			EnvFile []string `yaml:"env_file"`
+			Volumes []string `yaml:"volumes"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal([]byte(doc), &override); err != nil {
+		t.Fatalf("failed to parse override: %v", err)
+	}
+
+	api := override.Services["api"]
+	if len(api.EnvFile) != 1 {
+		t.Fatalf("api env_file = %v", api.EnvFile)
+	}
+	data, err := os.ReadFile(api.EnvFile[0])
+	if err != nil {
+		t.Fatalf("failed to read env file: %v", err)
+	}
+	if string(data) != "STRIPE_KEY='sk_test'\n" {
+		t.Errorf("env file = %q", data)
+	}
+
+	if len(override.Services["web"].EnvFile) != 0 {
+		t.Errorf("web should not receive STRIPE_KEY")
+	}
+
+	wantVolume := filepath.Join(dir, "tls") + ":/run/secrets/tls.key:ro"
+	for _, svc := range []string{"api", "web"} {
+		if v := override.Services[svc].Volumes; len(v) != 1 || v[0] != wantVolume {
+			t.Errorf("%s volumes = %v, want [%s]", svc, v, wantVolume)
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "tls"))
+	if err != nil {
+		t.Fatalf("secret file missing: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("secret file mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestPreviewMatchesRender(t *testing.T) {
+	dir := t.TempDir()
+	r := fakeResolver(map[string]string{"STRIPE_KEY": "sk_test", "TLS_KEY": "-----BEGIN-----"}, nil)
+
+	secrets := map[string]config.SecretConfig{
+		"STRIPE_KEY": {Source: "env", Services: []string{"api"}},
+		"tls":        {Source: "env", Key: "TLS_KEY", File: "/run/secrets/tls.key"},
+	}
+	services := []string{"api", "web"}
+
+	doc, err := r.Render(context.Background(), dir, secrets, services)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	data, err := yaml.Marshal(map[string]interface{}{"services": Preview(dir, secrets, services)})
+	if err != nil {
+		t.Fatalf("failed to marshal preview: %v", err)
+	}
+	if string(data) != doc {
+		t.Errorf("Preview() =\n%s\nwant\n%s", data, doc)
+	}
+}
+
+func TestFormatEnvFile(t *testing.T) {
+	got, err := formatEnvFile(map[string]string{
+		"DB_PASSWORD": "pa$$word #1",
+		"QUOTED":      `say "hi"`,
+		"TLS_KEY":     "-----BEGIN KEY-----\nabc\n-----END KEY-----",
+		"PLAIN":       "sk_test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "DB_PASSWORD='pa$$word #1'\n" +
+		"PLAIN='sk_test'\n" +
+		"QUOTED='say \"hi\"'\n" +
+		"TLS_KEY='-----BEGIN KEY-----\nabc\n-----END KEY-----'\n"
+	if got != want {
+		t.Errorf("formatEnvFile() = %q, want %q", got, want)
+	}
+
+	for _, value := range []string{"it's", `C:\dir\`} {
+		if _, err := formatEnvFile(map[string]string{"SECRET": value}); err == nil {
+			t.Errorf("formatEnvFile(%q) succeeded, want a value compose can't represent refused", value)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package statedir locates the state space keeps outside projects: the DNS
+// daemon state, the control socket, the agent log and rendered secrets.
+// Everything is kept per user, so several users can run space on one
+// machine, and SPACE_STATE_DIR moves it all elsewhere.
+package statedir
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// EnvVar overrides the directory global state is kept in
+const EnvVar = "SPACE_STATE_DIR"
+
+// Path returns the path of the global state file name. Under
+// SPACE_STATE_DIR it is <dir>/<name>; otherwise it is ~/.space-<name>, where
+// space has always kept it.
+func Path(name string) string {
+	if dir := os.Getenv(EnvVar); dir != "" {
+		return filepath.Join(dir, name)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".space-"+name)
+	}
+	if dir, err := TempDir(); err == nil {
+		return filepath.Join(dir, name)
+	}
+	return ".space-" + name
+}
+
+// Ensure creates SPACE_STATE_DIR when it is set and missing, and checks that
+// only the user can enter it. Call it before writing a Path; the home
+// directory needs neither.
+func Ensure() error {
+	root := os.Getenv(EnvVar)
+	if root == "" {
+		return nil
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", root, err)
+	}
+	return checkPrivate(root)
+}
+
+// TempDir returns the directory for logs, locks and rendered files that need
+// not survive a reboot: <SPACE_STATE_DIR>/tmp, space in $XDG_RUNTIME_DIR, or
+// space-<uid> in the system temp directory. It is created when missing and
+// refused unless it is a directory only the user can enter, since on a
+// shared machine another user could have created it first.
+func TempDir() (string, error) {
+	dir := TempDirPath()
+	if err := Ensure(); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	if err := checkPrivate(dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// TempDirPath returns where TempDir is without creating or checking it, for
+// dry runs and previews that must not write anything
+func TempDirPath() string {
+	if root := os.Getenv(EnvVar); root != "" {
+		return filepath.Join(root, "tmp")
+	}
+	if xdg := os.Getenv("XDG_RUNTIME_DIR"); xdg != "" {
+		return filepath.Join(xdg, "space")
+	}
+	return filepath.Join(os.TempDir(), "space-"+userID())
+}
+
+// checkPrivate fails unless dir is a real directory owned by the user with
+// mode 0700
+func checkPrivate(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory; remove it and try again", dir)
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && int(st.Uid) != os.Getuid() {
+		return fmt.Errorf("%s is owned by uid %d, not by you; remove it or set %s", dir, st.Uid, EnvVar)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		return fmt.Errorf("%s has mode %v, want 0700; run 'chmod 700 %s'", dir, perm, dir)
+	}
+	return nil
+}
+
+// userID names the current user in paths: the uid, or the user name where
+// there are no uids
+func userID() string {
+	if uid := os.Getuid(); uid >= 0 {
+		return fmt.Sprint(uid)
+	}
+	if u, err := user.Current(); err == nil {
+		return strings.Map(func(r rune) rune {
+			if r == '/' || r == '\\' || r == ':' {
+				return '_'
+			}
+			return r
+		}, u.Username)
+	}
+	return "user"
+}
@@ -0,0 +1,127 @@
+package statedir
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// privateTempDir returns a temp directory only the user can enter, as
+// SPACE_STATE_DIR must be
+func privateTempDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvVar, "")
+
+	// Without SPACE_STATE_DIR state stays where it always was
+	if got, want := Path("dns-daemon.json"), filepath.Join(home, ".space-dns-daemon.json"); runtime.GOOS != "windows" && got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+
+	root := t.TempDir()
+	t.Setenv(EnvVar, root)
+	if got, want := Path("control.sock"), filepath.Join(root, "control.sock"); got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+}
+
+func TestEnsureCreatesStateDir(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "state", "space")
+	t.Setenv(EnvVar, root)
+
+	if err := Ensure(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0700 {
+		t.Errorf("state dir mode = %v, want 0700", info.Mode().Perm())
+	}
+	if err := os.WriteFile(Path("dns-daemon.json"), nil, 0644); err != nil {
+		t.Errorf("writing state after Ensure: %v", err)
+	}
+
+	if err := os.Chmod(root, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Ensure(); err == nil {
+		t.Error("Ensure() accepted a state dir others can enter")
+	}
+}
+
+func TestTempDir(t *testing.T) {
+	t.Setenv(EnvVar, "")
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	t.Setenv("TMPDIR", t.TempDir())
+	dir, err := TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(filepath.Base(dir), "space-") || filepath.Dir(dir) != filepath.Clean(os.TempDir()) {
+		t.Errorf("TempDir() = %q, want a space-<user> directory in %s", dir, os.TempDir())
+	}
+
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	if dir, err := TempDir(); err != nil || dir != filepath.Join(runtimeDir, "space") {
+		t.Errorf("TempDir() = %q, %v, want it below $XDG_RUNTIME_DIR", dir, err)
+	}
+
+	root := privateTempDir(t)
+	t.Setenv(EnvVar, root)
+	dir, err = TempDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir != filepath.Join(root, "tmp") {
+		t.Errorf("TempDir() = %q, want it below %s", dir, root)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0700 {
+		t.Errorf("TempDir() mode = %v, want 0700", info.Mode().Perm())
+	}
+}
+
+func TestTempDirRefusesForeignDirectories(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "")
+
+	// A directory others can enter
+	root := privateTempDir(t)
+	t.Setenv(EnvVar, root)
+	if err := os.Mkdir(filepath.Join(root, "tmp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(root, "tmp"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TempDir(); err == nil || !strings.Contains(err.Error(), "0700") {
+		t.Errorf("TempDir() error = %v, want a mode error", err)
+	}
+
+	// A symlink planted in its place
+	root = privateTempDir(t)
+	t.Setenv(EnvVar, root)
+	target := privateTempDir(t)
+	if err := os.Symlink(target, filepath.Join(root, "tmp")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TempDir(); err == nil || !strings.Contains(err.Error(), "not a directory") {
+		t.Errorf("TempDir() error = %v, want a symlink to be refused", err)
+	}
+}
@@ -0,0 +1,336 @@
+// Package templates fetches project templates (a .space.yaml with hooks,
+// commands and compose snippets) from git repositories or local directories
+// and renders them into a project
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile describes a template's variables. It is not copied.
+const ManifestFile = "space-template.yaml"
+
+// templateSuffix marks files rendered with text/template; the suffix is
+// dropped from the written file. Other files are copied verbatim.
+const templateSuffix = ".tmpl"
+
+// cloneTimeout bounds cloning a template repository
+const cloneTimeout = 5 * time.Minute
+
+// Source is where a template lives: a repository or directory, a
+// subdirectory within it and an optional git ref
+type Source struct {
+	Repo   string
+	Subdir string
+	Ref    string
+}
+
+// String returns the source in the syntax ParseSource accepts
+func (s Source) String() string {
+	out := s.Repo
+	if s.Subdir != "" {
+		out += "//" + s.Subdir
+	}
+	if s.Ref != "" {
+		out += "?ref=" + s.Ref
+	}
+	return out
+}
+
+// ParseSource parses "<repo>[//<subdir>][?ref=<ref>]". The repo is a git URL
+// (git@host:org/repo, https://, ssh://, file://) or a local directory.
+func ParseSource(raw string) (Source, error) {
+	var s Source
+	if raw == "" {
+		return s, fmt.Errorf("empty template source")
+	}
+
+	if i := strings.LastIndex(raw, "?ref="); i >= 0 {
+		s.Ref = raw[i+len("?ref="):]
+		raw = raw[:i]
+		if s.Ref == "" {
+			return s, fmt.Errorf("empty ref in template source")
+		}
+	}
+
+	// Skip the "//" of a URL scheme when looking for the subdirectory
+	searchFrom := 0
+	if i := strings.Index(raw, "://"); i >= 0 {
+		searchFrom = i + len("://")
+	}
+	if i := strings.Index(raw[searchFrom:], "//"); i >= 0 {
+		s.Subdir = strings.Trim(raw[searchFrom+i+2:], "/")
+		raw = raw[:searchFrom+i]
+	}
+	s.Repo = raw
+
+	if s.Subdir != "" {
+		clean := filepath.Clean(s.Subdir)
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+			return s, fmt.Errorf("template subdirectory %q escapes the repository", s.Subdir)
+		}
+		s.Subdir = clean
+	}
+	return s, nil
+}
+
+// isLocal reports whether the repo is a local directory rather than a git URL
+func (s Source) isLocal() bool {
+	if strings.Contains(s.Repo, "://") || strings.HasPrefix(s.Repo, "git@") {
+		return false
+	}
+	info, err := os.Stat(s.Repo)
+	return err == nil && info.IsDir()
+}
+
+// cloneArgs returns the git arguments shallow-cloning the source into dir.
+// The repo follows "--", so one starting with - is not taken for an option
+// such as --upload-pack.
+func cloneArgs(s Source, dir string) []string {
+	args := []string{"clone", "--depth", "1", "--quiet"}
+	if s.Ref != "" {
+		args = append(args, "--branch", s.Ref)
+	}
+	return append(args, "--", s.Repo, dir)
+}
+
+// Fetch makes the template available on disk and returns its directory.
+// Git repositories are shallow-cloned into a temporary directory that
+// cleanup removes.
+func Fetch(ctx context.Context, s Source) (dir string, cleanup func(), err error) {
+	cleanup = func() {}
+
+	root := s.Repo
+	if !s.isLocal() {
+		tmp, err := os.MkdirTemp("", "space-template-*")
+		if err != nil {
+			return "", cleanup, fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		cleanup = func() { os.RemoveAll(tmp) }
+
+		ctx, cancel := context.WithTimeout(ctx, cloneTimeout)
+		defer cancel()
+
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "git", cloneArgs(s, tmp)...)
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			cleanup()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", func() {}, fmt.Errorf("git clone %s did not finish within %s", s.Repo, cloneTimeout)
+			}
+			return "", func() {}, fmt.Errorf("git clone %s: %w: %s", s.Repo, err, strings.TrimSpace(stderr.String()))
+		}
+		root = tmp
+	} else if s.Ref != "" {
+		return "", cleanup, fmt.Errorf("ref %q needs a git repository, %s is a local directory", s.Ref, s.Repo)
+	}
+
+	dir = filepath.Join(root, s.Subdir)
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		cleanup()
+		return "", func() {}, fmt.Errorf("template directory %q not found in %s", s.Subdir, s.Repo)
+	}
+	return dir, cleanup, nil
+}
+
+// Variable is a value asked for when a template is rendered
+type Variable struct {
+	// Name is the key used in templates as {{ .name }}
+	Name string `yaml:"name"`
+
+	// Prompt is the question shown to the user (default: the name)
+	Prompt string `yaml:"prompt,omitempty"`
+
+	// Default is used when the user gives no answer. It may reference
+	// earlier variables, e.g. "{{ .project_name }}_dev".
+	Default string `yaml:"default,omitempty"`
+}
+
+// Manifest is the parsed space-template.yaml
+type Manifest struct {
+	Description string     `yaml:"description,omitempty"`
+	Variables   []Variable `yaml:"variables,omitempty"`
+}
+
+// LoadManifest reads the manifest of the template in dir. A template without
+// one has no variables.
+func LoadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if os.IsNotExist(err) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ManifestFile, err)
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFile, err)
+	}
+	for i, v := range m.Variables {
+		if v.Name == "" {
+			return nil, fmt.Errorf("%s: variable %d has no name", ManifestFile, i+1)
+		}
+	}
+	return &m, nil
+}
+
+// Resolve computes the value of every variable. builtins are available to
+// defaults and templates without being asked for. Values in preset win; for
+// the rest ask is called with the prompt and the rendered default, and an
+// empty answer keeps the default. A nil ask takes every default.
+func (m *Manifest) Resolve(builtins, preset map[string]string, ask func(prompt, def string) (string, error)) (map[string]string, error) {
+	values := make(map[string]string, len(builtins)+len(m.Variables)+len(preset))
+	for k, v := range builtins {
+		values[k] = v
+	}
+	for k, v := range preset {
+		values[k] = v
+	}
+
+	for _, v := range m.Variables {
+		if _, ok := preset[v.Name]; ok {
+			continue
+		}
+
+		def, err := renderString(v.Name+" default", v.Default, values)
+		if err != nil {
+			return nil, err
+		}
+
+		value := def
+		if ask != nil {
+			prompt := v.Prompt
+			if prompt == "" {
+				prompt = v.Name
+			}
+			answer, err := ask(prompt, def)
+			if err != nil {
+				return nil, err
+			}
+			if answer != "" {
+				value = answer
+			}
+		}
+		values[v.Name] = value
+	}
+	return values, nil
+}
+
+// File is a file a template writes
+type File struct {
+	// Path is relative to the destination
+	Path string
+	Mode fs.FileMode
+	Data []byte
+}
+
+// Render renders the template in dir with values. Files ending in .tmpl are
+// executed as text/template with missing keys as errors; the manifest and
+// VCS metadata are skipped.
+func Render(dir string, values map[string]string) ([]File, error) {
+	var files []File
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == ManifestFile || !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(rel, templateSuffix) {
+			rendered, err := renderString(rel, string(data), values)
+			if err != nil {
+				return err
+			}
+			data = []byte(rendered)
+			rel = strings.TrimSuffix(rel, templateSuffix)
+		}
+
+		files = append(files, File{Path: rel, Mode: info.Mode().Perm(), Data: data})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// Conflicts returns the files that already exist in dest
+func Conflicts(dest string, files []File) []string {
+	var existing []string
+	for _, f := range files {
+		if _, err := os.Stat(filepath.Join(dest, f.Path)); err == nil {
+			existing = append(existing, f.Path)
+		}
+	}
+	return existing
+}
+
+// Write writes files into dest, creating directories as needed
+func Write(dest string, files []File) error {
+	for _, f := range files {
+		path := filepath.Join(dest, f.Path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", f.Path, err)
+		}
+		if err := os.WriteFile(path, f.Data, f.Mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.Path, err)
+		}
+		// WriteFile keeps the mode of existing files
+		if err := os.Chmod(path, f.Mode); err != nil {
+			return fmt.Errorf("failed to set mode of %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
+
+// renderString executes text as a template with values
+func renderString(name, text string, values map[string]string) (string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, values); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return out.String(), nil
+}
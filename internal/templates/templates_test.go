@@ -0,0 +1,216 @@
+package templates
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    Source
+		wantErr bool
+	}{
+		{
+			raw:  "git@github.com:org/space-templates//rails-postgres",
+			want: Source{Repo: "git@github.com:org/space-templates", Subdir: "rails-postgres"},
+		},
+		{
+			raw:  "https://github.com/org/space-templates.git//go/api/?ref=v2",
+			want: Source{Repo: "https://github.com/org/space-templates.git", Subdir: "go/api", Ref: "v2"},
+		},
+		{
+			raw:  "https://github.com/org/rails-template",
+			want: Source{Repo: "https://github.com/org/rails-template"},
+		},
+		{
+			raw:  "../templates//node",
+			want: Source{Repo: "../templates", Subdir: "node"},
+		},
+		{raw: "", wantErr: true},
+		{raw: "git@github.com:org/t//../../etc", wantErr: true},
+		{raw: "git@github.com:org/t?ref=", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseSource(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseSource() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeTemplate creates a template with a manifest, a rendered file and a
+// verbatim hook
+func writeTemplate(t *testing.T, dir string) {
+	t.Helper()
+	files := map[string]string{
+		ManifestFile: `description: Rails with Postgres
+variables:
+  - name: project_name
+    prompt: Project name
+    default: "{{ .dir }}"
+  - name: db_name
+    default: "{{ .project_name }}_dev"
+  - name: web_port
+    default: "3000"
+`,
+		".space.yaml.tmpl":               "project:\n  name: {{ .project_name }}\nservices:\n  web:\n    port: {{ .web_port }}\n",
+		"docker-compose.yml.tmpl":        "services:\n  db:\n    environment:\n      POSTGRES_DB: {{ .db_name }}\n      PGDATA: ${PGDATA}\n",
+		".space/hooks/post-up.d/10-seed": "#!/bin/sh\necho {{ not rendered }}\n",
+		".git/HEAD":                      "ref: refs/heads/main\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(dir, ".space/hooks/post-up.d/10-seed"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir)
+
+	manifest, err := LoadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var asked []string
+	ask := func(prompt, def string) (string, error) {
+		asked = append(asked, prompt+"="+def)
+		if prompt == "Project name" {
+			return "shop", nil
+		}
+		return "", nil
+	}
+	values, err := manifest.Resolve(map[string]string{"dir": "checkout"}, map[string]string{"web_port": "8080"}, ask)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantAsked := []string{"Project name=checkout", "db_name=shop_dev"}
+	if len(asked) != len(wantAsked) || asked[0] != wantAsked[0] || asked[1] != wantAsked[1] {
+		t.Errorf("asked %v, want %v", asked, wantAsked)
+	}
+
+	files, err := Render(dir, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]File)
+	for _, f := range files {
+		got[f.Path] = f
+	}
+	if len(got) != 3 {
+		t.Fatalf("Render() wrote %d files, want 3: %v", len(got), files)
+	}
+	if s := string(got[".space.yaml"].Data); s != "project:\n  name: shop\nservices:\n  web:\n    port: 8080\n" {
+		t.Errorf(".space.yaml = %q", s)
+	}
+	if s := string(got["docker-compose.yml"].Data); s != "services:\n  db:\n    environment:\n      POSTGRES_DB: shop_dev\n      PGDATA: ${PGDATA}\n" {
+		t.Errorf("docker-compose.yml = %q", s)
+	}
+	hook := got[filepath.Join(".space", "hooks", "post-up.d", "10-seed")]
+	if string(hook.Data) != "#!/bin/sh\necho {{ not rendered }}\n" || hook.Mode != 0755 {
+		t.Errorf("hook = %q (%v), want verbatim and executable", hook.Data, hook.Mode)
+	}
+
+	dest := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dest, ".space.yaml"), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if conflicts := Conflicts(dest, files); len(conflicts) != 1 || conflicts[0] != ".space.yaml" {
+		t.Errorf("Conflicts() = %v, want [.space.yaml]", conflicts)
+	}
+	if err := Write(dest, files); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(filepath.Join(dest, ".space", "hooks", "post-up.d", "10-seed"))
+	if err != nil || info.Mode().Perm() != 0755 {
+		t.Errorf("written hook mode = %v, %v", info, err)
+	}
+}
+
+func TestRenderMissingVariable(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".space.yaml.tmpl"), []byte("name: {{ .nope }}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Render(dir, map[string]string{}); err == nil {
+		t.Error("Render() with an undefined variable succeeded")
+	}
+}
+
+func TestFetchGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	repo := t.TempDir()
+	writeTemplate(t, filepath.Join(repo, "rails-postgres"))
+	if err := os.RemoveAll(filepath.Join(repo, "rails-postgres", ".git")); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", "main"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "templates"},
+		{"tag", "v1"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	source, err := ParseSource("file://" + repo + "//rails-postgres?ref=v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, cleanup, err := Fetch(context.Background(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	if _, err := os.Stat(filepath.Join(dir, ".space.yaml.tmpl")); err != nil {
+		t.Errorf("fetched template is missing .space.yaml.tmpl: %v", err)
+	}
+
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("cleanup left %s behind", dir)
+	}
+
+	if _, _, err := Fetch(context.Background(), Source{Repo: "file://" + repo, Subdir: "missing"}); err == nil {
+		t.Error("Fetch() of a missing subdirectory succeeded")
+	}
+}
+
+func TestCloneArgs(t *testing.T) {
+	source := Source{Repo: "--upload-pack=touch /tmp/x #://example.com/repo", Ref: "v1"}
+	got := cloneArgs(source, "/tmp/template")
+	want := []string{"clone", "--depth", "1", "--quiet", "--branch", "v1", "--", source.Repo, "/tmp/template"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("cloneArgs() = %q, want %q", got, want)
+	}
+}
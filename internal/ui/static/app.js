@@ -0,0 +1,164 @@
+// Dashboard of space projects, backed by the agent's JSON API
+"use strict";
+
+const state = { project: "", projects: [] };
+const $ = (id) => document.getElementById(id);
+
+async function api(path, options = {}) {
+  const resp = await fetch(path, options);
+  if (!resp.ok) {
+    throw new Error((await resp.text()).trim() || resp.statusText);
+  }
+  return resp;
+}
+
+function showError(err) {
+  $("error").textContent = err ? String(err.message || err) : "";
+  $("error").hidden = !err;
+}
+
+function el(tag, props = {}, children = []) {
+  const node = Object.assign(document.createElement(tag), props);
+  node.append(...children);
+  return node;
+}
+
+async function loadProjects() {
+  state.projects = await (await api("api/projects")).json();
+  if (!state.projects.some((p) => p.name === state.project)) {
+    state.project = state.projects.length ? state.projects[0].name : "";
+  }
+
+  $("projects").replaceChildren(...state.projects.map((p) => {
+    const li = el("li", { className: p.name === state.project ? "selected" : "" }, [
+      p.name,
+      el("small", { textContent: p.containers ? `${p.containers} running` : "stopped" }),
+    ]);
+    li.title = p.work_dir;
+    li.onclick = () => selectProject(p.name);
+    return li;
+  }));
+  if (!state.projects.length) {
+    $("projects").replaceChildren(el("li", { className: "muted", textContent: "No projects running" }));
+  }
+}
+
+async function loadServices() {
+  if (!state.project) {
+    $("project-title").textContent = "Services";
+    $("services").replaceChildren();
+    return;
+  }
+  $("project-title").textContent = state.project;
+
+  const services = await (await api(`api/projects/${encodeURIComponent(state.project)}/services`)).json();
+  $("services").replaceChildren(...services.map(serviceRow));
+
+  const select = $("log-service");
+  const current = select.value;
+  select.replaceChildren(el("option", { value: "", textContent: "all services" }),
+    ...services.map((s) => el("option", { value: s.service, textContent: s.service })));
+  select.value = services.some((s) => s.service === current) ? current : "";
+}
+
+function serviceRow(s) {
+  const url = s.url ? el("a", { href: s.url, target: "_blank", rel: "noopener", textContent: s.url }) : "";
+  const running = s.state === "running";
+  const buttons = running
+    ? [actionButton(s.service, "restart"), actionButton(s.service, "stop")]
+    : [actionButton(s.service, "start")];
+
+  return el("tr", {}, [
+    el("td", { textContent: s.service }),
+    el("td", { className: s.state.replace(/\s.*/, ""), textContent: s.state }),
+    el("td", { className: s.health, textContent: s.health, title: s.error || s.latency || "" }),
+    el("td", {}, [url]),
+    el("td", { className: "actions" }, buttons),
+  ]);
+}
+
+function actionButton(service, action) {
+  const button = el("button", { type: "button", textContent: action });
+  button.onclick = async () => {
+    button.disabled = true;
+    try {
+      await api(`api/projects/${encodeURIComponent(state.project)}/services/${encodeURIComponent(service)}/${action}`, {
+        method: "POST",
+        headers: { "X-Space-UI": "1" },
+      });
+      showError(null);
+    } catch (err) {
+      showError(err);
+    }
+    await refresh();
+  };
+  return button;
+}
+
+async function loadLogs() {
+  if (!state.project) {
+    return;
+  }
+  const params = new URLSearchParams({ tail: "200" });
+  if ($("log-service").value) {
+    params.set("service", $("log-service").value);
+  }
+  try {
+    const text = await (await api(`api/projects/${encodeURIComponent(state.project)}/logs?${params}`)).text();
+    const logs = $("logs");
+    const atBottom = logs.scrollTop + logs.clientHeight >= logs.scrollHeight - 4;
+    logs.className = "";
+    logs.textContent = text || "No logs yet.";
+    if (atBottom) {
+      logs.scrollTop = logs.scrollHeight;
+    }
+  } catch (err) {
+    $("logs").className = "error";
+    $("logs").textContent = err.message;
+  }
+}
+
+async function selectProject(name) {
+  state.project = name;
+  $("log-service").value = "";
+  await refresh();
+}
+
+let refreshing = null;
+
+// refresh reloads everything, coalescing calls made while one is running
+function refresh() {
+  if (!refreshing) {
+    refreshing = (async () => {
+      try {
+        await loadProjects();
+        await loadServices();
+        showError(null);
+      } catch (err) {
+        showError(err);
+      }
+      await loadLogs();
+    })().finally(() => { refreshing = null; });
+  }
+  return refreshing;
+}
+
+function listen() {
+  const source = new EventSource("api/events");
+  let timer = null;
+  source.onopen = () => { $("live").textContent = "live"; };
+  source.onmessage = () => {
+    // Containers come up in bursts; refresh once they settle
+    clearTimeout(timer);
+    timer = setTimeout(refresh, 500);
+  };
+  source.onerror = () => { $("live").textContent = "reconnecting…"; };
+}
+
+$("log-service").onchange = loadLogs;
+$("log-refresh").onclick = loadLogs;
+
+refresh();
+listen();
+// Health isn't an event; poll it as well
+setInterval(refresh, 10000);
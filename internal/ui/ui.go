@@ -0,0 +1,21 @@
+// Package ui holds the web dashboard served by space agentd: a static page
+// that talks to the agent's JSON API.
+package ui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the dashboard's static assets
+func Handler() http.Handler {
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServerFS(assets)
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnmarshalYAML accepts a plain command string or a mapping
+func (c *CustomCommandConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&c.Command)
+	}
+
+	type plain CustomCommandConfig
+	return value.Decode((*plain)(c))
+}
+
+// UnmarshalJSON accepts a plain command string or an object
+func (c *CustomCommandConfig) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &c.Command); err == nil {
+		return nil
+	}
+
+	type plain CustomCommandConfig
+	return json.Unmarshal(data, (*plain)(c))
+}
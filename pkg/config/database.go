@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// databaseImages describes how the official images of each database type
+// take their credentials from the environment
+var databaseImages = map[string]struct {
+	dbType   string
+	user     []string // variables holding the user, in order of preference
+	password []string // variables holding the user's password
+	name     []string // variables holding the database name
+	// defaultUser is used when no user variable is set
+	defaultUser string
+	// defaultName is used when no name variable is set; "" falls back to the user
+	defaultName string
+}{
+	"postgres": {
+		dbType:      "postgres",
+		user:        []string{"POSTGRES_USER"},
+		password:    []string{"POSTGRES_PASSWORD"},
+		name:        []string{"POSTGRES_DB"},
+		defaultUser: "postgres",
+	},
+	"mysql": {
+		dbType:      "mysql",
+		user:        []string{"MYSQL_USER", "MARIADB_USER"},
+		password:    []string{"MYSQL_PASSWORD", "MARIADB_PASSWORD"},
+		name:        []string{"MYSQL_DATABASE", "MARIADB_DATABASE"},
+		defaultUser: "root",
+	},
+	"mongodb": {
+		dbType:      "mongodb",
+		user:        []string{"MONGO_INITDB_ROOT_USERNAME"},
+		password:    []string{"MONGO_INITDB_ROOT_PASSWORD"},
+		name:        []string{"MONGO_INITDB_DATABASE"},
+		defaultName: "admin",
+	},
+	"redis": {
+		dbType:      "redis",
+		password:    []string{"REDIS_PASSWORD"},
+		defaultName: "0",
+	},
+}
+
+// DetectDatabase derives the database a compose service provides from its
+// image and environment (e.g., postgres:16 with POSTGRES_USER and
+// POSTGRES_DB). It returns false for images that are not a known database.
+// Environment values may use ${VAR} and ${VAR:-default} like compose files.
+func DetectDatabase(service, image string, env map[string]string) (DatabaseConfig, bool) {
+	spec, ok := databaseImages[DetectTCPScheme(image)]
+	if !ok {
+		return DatabaseConfig{}, false
+	}
+
+	lookup := func(keys []string) string {
+		for _, key := range keys {
+			if value := InterpolateEnv(env[key]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	db := DatabaseConfig{
+		Service:  service,
+		Type:     spec.dbType,
+		User:     lookup(spec.user),
+		Password: lookup(spec.password),
+		Name:     lookup(spec.name),
+	}
+
+	// MySQL without an application user is used as root
+	if spec.dbType == "mysql" && db.User == "" {
+		db.Password = lookup([]string{"MYSQL_ROOT_PASSWORD", "MARIADB_ROOT_PASSWORD"})
+	}
+	if db.User == "" {
+		db.User = spec.defaultUser
+	}
+	if db.Name == "" {
+		db.Name = spec.defaultName
+	}
+	if db.Name == "" {
+		db.Name = db.User
+	}
+	if db.Name == "" {
+		db.Name = service
+	}
+	return db, true
+}
+
+// InterpolateEnv substitutes ${VAR}, ${VAR:-default} and ${VAR-default}
+// from the process environment
+func InterpolateEnv(value string) string {
+	return os.Expand(value, func(name string) string {
+		if i := strings.Index(name, ":-"); i != -1 {
+			if v := os.Getenv(name[:i]); v != "" {
+				return v
+			}
+			return name[i+2:]
+		}
+		if i := strings.Index(name, "-"); i != -1 {
+			if v, ok := os.LookupEnv(name[:i]); ok {
+				return v
+			}
+			return name[i+1:]
+		}
+		return os.Getenv(name)
+	})
+}
@@ -0,0 +1,71 @@
+package config
+
+import "testing"
+
+func TestDetectDatabase(t *testing.T) {
+	t.Setenv("SHOP_DB_PASSWORD", "from-env")
+
+	tests := []struct {
+		image string
+		env   map[string]string
+		want  DatabaseConfig
+	}{
+		{
+			image: "postgres:16-alpine",
+			env:   map[string]string{"POSTGRES_PASSWORD": "${SHOP_DB_PASSWORD}"},
+			want:  DatabaseConfig{Name: "postgres", Service: "svc", Type: "postgres", User: "postgres", Password: "from-env"},
+		},
+		{
+			image: "mysql:8",
+			env:   map[string]string{"MYSQL_ROOT_PASSWORD": "root-pw", "MYSQL_DATABASE": "shop"},
+			want:  DatabaseConfig{Name: "shop", Service: "svc", Type: "mysql", User: "root", Password: "root-pw"},
+		},
+		{
+			image: "mariadb:11",
+			env:   map[string]string{"MARIADB_USER": "app", "MARIADB_PASSWORD": "${MISSING:-fallback}"},
+			want:  DatabaseConfig{Name: "app", Service: "svc", Type: "mysql", User: "app", Password: "fallback"},
+		},
+		{
+			image: "mongo:7",
+			env:   map[string]string{"MONGO_INITDB_ROOT_USERNAME": "admin"},
+			want:  DatabaseConfig{Name: "admin", Service: "svc", Type: "mongodb", User: "admin"},
+		},
+		{
+			image: "bitnami/redis:7.2",
+			want:  DatabaseConfig{Name: "0", Service: "svc", Type: "redis"},
+		},
+	}
+
+	for _, tt := range tests {
+		got, ok := DetectDatabase("svc", tt.image, tt.env)
+		if !ok || got != tt.want {
+			t.Errorf("DetectDatabase(%s) = %+v, %v; want %+v", tt.image, got, ok, tt.want)
+		}
+	}
+
+	for _, image := range []string{"rabbitmq:3", "nginx", ""} {
+		if _, ok := DetectDatabase("svc", image, nil); ok {
+			t.Errorf("DetectDatabase(%q) detected a database", image)
+		}
+	}
+}
+
+func TestValidateSharedDatabase(t *testing.T) {
+	tests := []struct {
+		db      DatabaseConfig
+		wantErr bool
+	}{
+		{db: DatabaseConfig{Name: "app", Service: "db", Shared: true}},
+		{db: DatabaseConfig{Name: "app", Service: "db", Type: "postgres", Shared: true}},
+		{db: DatabaseConfig{Name: "app", Type: "postgres", Shared: true}, wantErr: true},
+		{db: DatabaseConfig{Name: "app", Service: "db", Type: "mysql", Shared: true}, wantErr: true},
+		{db: DatabaseConfig{Name: "app", Type: "mysql"}},
+	}
+	for _, tt := range tests {
+		cfg := Defaults()
+		cfg.Databases = []DatabaseConfig{tt.db}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.db, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	_ "embed"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaSource is parsed for the field descriptions of the reference
+//
+//go:embed schema.go
+var schemaSource []byte
+
+// FieldDoc documents one configuration key
+type FieldDoc struct {
+	// Key is the dotted YAML path, e.g. "project.naming_strategy".
+	// Map keys are written as <name> and list items as [].
+	Key string
+
+	// Type is a short type name (string, int, bool, duration, list, map)
+	Type string
+
+	// Default is the built-in default, empty if none
+	Default string
+
+	// Description is the field's doc comment
+	Description string
+}
+
+// Section returns the top-level key the field belongs to
+func (f FieldDoc) Section() string {
+	section, _, _ := strings.Cut(f.Key, ".")
+	return strings.TrimSuffix(section, "[]")
+}
+
+// Reference describes every configuration key with its type, default and
+// description, in declaration order
+func Reference() ([]FieldDoc, error) {
+	descriptions, err := fieldDescriptions()
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []FieldDoc
+	walkFields(reflect.TypeOf(Config{}), reflect.ValueOf(*Defaults()), "", descriptions, &docs)
+	return docs, nil
+}
+
+// fieldDescriptions maps "Struct.Field" to the field's doc comment
+func fieldDescriptions() (map[string]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "schema.go", schemaSource, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+
+	descriptions := make(map[string]string)
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok {
+			return true
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+		for _, field := range st.Fields.List {
+			if field.Doc == nil {
+				continue
+			}
+			text := strings.Join(strings.Fields(field.Doc.Text()), " ")
+			for _, name := range field.Names {
+				descriptions[spec.Name.Name+"."+name.Name] = text
+			}
+		}
+		return false
+	})
+	return descriptions, nil
+}
+
+// walkFields appends the documentation of the fields of struct type t.
+// def holds the defaults at this path, or is invalid below maps and lists.
+func walkFields(t reflect.Type, def reflect.Value, prefix string, descriptions map[string]string, docs *[]FieldDoc) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		key := prefix + name
+		doc := FieldDoc{
+			Key:         key,
+			Type:        typeName(field.Type),
+			Description: descriptions[t.Name()+"."+field.Name],
+		}
+
+		var value reflect.Value
+		if def.IsValid() {
+			value = def.Field(i)
+			doc.Default = formatDefault(value)
+		}
+		*docs = append(*docs, doc)
+
+		// Descend into nested settings
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+			if value.IsValid() {
+				if value.IsNil() {
+					value = reflect.Value{}
+				} else {
+					value = value.Elem()
+				}
+			}
+		}
+		switch {
+		case ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}):
+			walkFields(ft, value, key+".", descriptions, docs)
+		case ft.Kind() == reflect.Map && structElem(ft) != nil:
+			walkFields(structElem(ft), reflect.Value{}, key+".<name>.", descriptions, docs)
+		case ft.Kind() == reflect.Slice && structElem(ft) != nil:
+			walkFields(structElem(ft), reflect.Value{}, key+"[].", descriptions, docs)
+		}
+	}
+}
+
+// structElem returns the struct element type of a map or slice, or nil
+func structElem(t reflect.Type) reflect.Type {
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil
+	}
+	return elem
+}
+
+// typeName returns a short, YAML-oriented type name
+func typeName(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return "duration"
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeName(t.Elem())
+	case reflect.Struct:
+		return "object"
+	case reflect.Map:
+		return "map of " + typeName(t.Elem())
+	case reflect.Slice:
+		return "list of " + typeName(t.Elem())
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return "int"
+	default:
+		return t.Kind().String()
+	}
+}
+
+// formatDefault renders a non-zero default value
+func formatDefault(v reflect.Value) string {
+	if !v.IsValid() || v.IsZero() {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Map:
+		return ""
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
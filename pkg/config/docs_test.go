@@ -0,0 +1,43 @@
+package config
+
+import "testing"
+
+func TestReference(t *testing.T) {
+	docs, err := Reference()
+	if err != nil {
+		t.Fatalf("Reference() error = %v", err)
+	}
+
+	byKey := make(map[string]FieldDoc)
+	for _, doc := range docs {
+		byKey[doc.Key] = doc
+	}
+
+	tests := []FieldDoc{
+		{Key: "project.naming_strategy", Type: "string", Default: "git-branch"},
+		{Key: "project.compose_files", Type: "list of string", Default: "[docker-compose.yml]"},
+		{Key: "services", Type: "map of object"},
+		{Key: "services.<name>.port", Type: "int"},
+		{Key: "services.<name>.health_check.timeout", Type: "duration"},
+		{Key: "databases[].name", Type: "string"},
+		{Key: "network.dns_hashing", Type: "bool", Default: "true"},
+		{Key: "provider.orbstack.dns_suffix", Type: "string", Default: ".orb.local"},
+	}
+	for _, want := range tests {
+		got, ok := byKey[want.Key]
+		if !ok {
+			t.Errorf("missing key %s", want.Key)
+			continue
+		}
+		if got.Type != want.Type || got.Default != want.Default {
+			t.Errorf("%s = (%s, %q), want (%s, %q)", want.Key, got.Type, got.Default, want.Type, want.Default)
+		}
+	}
+
+	if got := byKey["services.<name>.port"].Description; got != "Port is the internal port the service listens on" {
+		t.Errorf("port description = %q", got)
+	}
+	if got := byKey["services.<name>.port"].Section(); got != "services" {
+		t.Errorf("Section() = %q, want services", got)
+	}
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultBaseDomain is the DNS base domain used when none is configured
+const DefaultBaseDomain = "space.local"
+
+// Domains is a list of DNS base domains. In YAML and JSON it is written as a
+// single string or a list of strings.
+type Domains []string
+
+// UnmarshalYAML accepts a string or a list of strings
+func (d *Domains) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		var domain string
+		if err := value.Decode(&domain); err != nil {
+			return err
+		}
+		*d = Domains{domain}
+		return nil
+	}
+
+	var domains []string
+	if err := value.Decode(&domains); err != nil {
+		return err
+	}
+	*d = domains
+	return nil
+}
+
+// MarshalYAML writes a single domain as a plain string
+func (d Domains) MarshalYAML() (interface{}, error) {
+	if len(d) == 1 {
+		return d[0], nil
+	}
+	return []string(d), nil
+}
+
+// UnmarshalJSON accepts a string or a list of strings
+func (d *Domains) UnmarshalJSON(data []byte) error {
+	var domain string
+	if err := json.Unmarshal(data, &domain); err == nil {
+		*d = Domains{domain}
+		return nil
+	}
+
+	var domains []string
+	if err := json.Unmarshal(data, &domains); err != nil {
+		return err
+	}
+	*d = domains
+	return nil
+}
+
+// BaseDomains returns the configured base domains, normalized and without
+// duplicates, or the default domain when none is configured
+func (n NetworkConfig) BaseDomains() []string {
+	var domains []string
+	seen := make(map[string]bool)
+	for _, domain := range n.CustomDomain {
+		domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), "."))
+		if domain == "" || seen[domain] {
+			continue
+		}
+		seen[domain] = true
+		domains = append(domains, domain)
+	}
+
+	if len(domains) == 0 {
+		return []string{DefaultBaseDomain}
+	}
+	return domains
+}
+
+// BaseDomain returns the domain URLs are generated with: the first
+// configured base domain
+func (n NetworkConfig) BaseDomain() string {
+	return n.BaseDomains()[0]
+}
+
+// RecordZones returns the parent domains of the static DNS records, such as
+// internal for db.internal, that are not already base domains. These need
+// their own resolver file to reach the daemon.
+func (c *Config) RecordZones() []string {
+	base := make(map[string]bool)
+	for _, domain := range c.Network.BaseDomains() {
+		base[domain] = true
+	}
+
+	var zones []string
+	seen := make(map[string]bool)
+	for name := range c.DNS.Records {
+		name = strings.ToLower(strings.Trim(strings.TrimSpace(name), "."))
+		dot := strings.Index(name, ".")
+		if dot < 0 || underDomains(name, base) {
+			continue
+		}
+		if zone := name[dot+1:]; !seen[zone] {
+			seen[zone] = true
+			zones = append(zones, zone)
+		}
+	}
+	sort.Strings(zones)
+	return zones
+}
+
+// underDomains reports whether name is one of domains or below one of them
+func underDomains(name string, domains map[string]bool) bool {
+	for domain := range domains {
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateRecords checks that static DNS records have a dotted hostname and
+// a target
+func (d DNSConfig) validateRecords() error {
+	names := make([]string, 0, len(d.Records))
+	for name := range d.Records {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		host := strings.Trim(strings.TrimSpace(name), ".")
+		if !strings.Contains(host, ".") || strings.ContainsAny(host, " /:") {
+			return fmt.Errorf("dns.records: %q is not a hostname like db.internal", name)
+		}
+		if strings.TrimSpace(d.Records[name]) == "" {
+			return fmt.Errorf("dns.records: %s needs an IP address or a service", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestDomainsYAML(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"network: {}", []string{"space.local"}},
+		{"network:\n  custom_domain: dev.test", []string{"dev.test"}},
+		{"network:\n  custom_domain: [\".Dev.Test.\", old.local, dev.test]", []string{"dev.test", "old.local"}},
+	}
+
+	for _, tt := range tests {
+		var cfg Config
+		if err := yaml.Unmarshal([]byte(tt.input), &cfg); err != nil {
+			t.Fatalf("Unmarshal(%q) error = %v", tt.input, err)
+		}
+		if got := cfg.Network.BaseDomains(); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("BaseDomains() for %q = %v, want %v", tt.input, got, tt.want)
+		}
+		if got := cfg.Network.BaseDomain(); got != tt.want[0] {
+			t.Errorf("BaseDomain() = %q, want %q", got, tt.want[0])
+		}
+	}
+
+	out, err := yaml.Marshal(NetworkConfig{CustomDomain: Domains{"dev.test"}})
+	if err != nil || string(out) != "custom_domain: dev.test\n" {
+		t.Errorf("Marshal() = %q, %v", out, err)
+	}
+}
+
+func TestRecordZones(t *testing.T) {
+	cfg := &Config{
+		Network: NetworkConfig{CustomDomain: Domains{"dev.test"}},
+		DNS: DNSConfig{Records: map[string]string{
+			"db.internal":       "db",
+			"cache.internal.":   "10.0.0.5",
+			"Legacy.Corp.Local": "api",
+			"api.dev.test":      "api",
+		}},
+	}
+	if got := cfg.RecordZones(); strings.Join(got, ",") != "corp.local,internal" {
+		t.Errorf("RecordZones() = %v", got)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+
+	for _, records := range []map[string]string{{"db": "10.0.0.5"}, {"db.internal": " "}} {
+		cfg.DNS.Records = records
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected %v to be invalid", records)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// AddService adds a service to the YAML of a config file, keeping its
+// comments and layout. It fails if the service is already configured.
+func AddService(data []byte, name string, svc ServiceConfig) ([]byte, error) {
+	doc, root, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	services := mappingValue(root, "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		services = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setMappingValue(root, "services", services)
+	}
+	if mappingValue(services, name) != nil {
+		return nil, fmt.Errorf("service %s is already configured", name)
+	}
+
+	value := &yaml.Node{}
+	if err := value.Encode(svc); err != nil {
+		return nil, fmt.Errorf("failed to encode service: %w", err)
+	}
+	setMappingValue(services, name, value)
+
+	return encodeDocument(doc)
+}
+
+// RemoveService removes a service from the YAML of a config file, along with
+// its membership in groups; groups left empty are removed too. It reports
+// false if the service is not configured.
+func RemoveService(data []byte, name string) ([]byte, bool, error) {
+	doc, root, err := parseDocument(data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	services := mappingValue(root, "services")
+	if services == nil || !deleteMappingKey(services, name) {
+		return data, false, nil
+	}
+
+	if groups := mappingValue(root, "groups"); groups != nil && groups.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(groups.Content); {
+			members := groups.Content[i+1]
+			if members.Kind == yaml.SequenceNode {
+				kept := members.Content[:0]
+				for _, member := range members.Content {
+					if member.Value != name {
+						kept = append(kept, member)
+					}
+				}
+				members.Content = kept
+				if len(kept) == 0 {
+					groups.Content = append(groups.Content[:i], groups.Content[i+2:]...)
+					continue
+				}
+			}
+			i += 2
+		}
+	}
+
+	out, err := encodeDocument(doc)
+	return out, true, err
+}
+
+// SetServicePort sets the port of a configured service in the YAML of a
+// config file, keeping its comments
+func SetServicePort(data []byte, name string, port int) ([]byte, error) {
+	doc, root, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var svc *yaml.Node
+	if services := mappingValue(root, "services"); services != nil {
+		svc = mappingValue(services, name)
+	}
+	if svc == nil || svc.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("service %s is not configured", name)
+	}
+	setMappingValue(svc, "port", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(port)})
+
+	return encodeDocument(doc)
+}
+
+// parseDocument parses YAML into a document node and its root mapping,
+// creating both for empty input
+func parseDocument(data []byte) (*yaml.Node, *yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode}
+	}
+	if len(doc.Content) == 0 {
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("config is not a mapping")
+	}
+	return &doc, root, nil
+}
+
+// encodeDocument writes a document with the two-space indent config files use
+func encodeDocument(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets key in a mapping node, appending it if missing. A
+// replaced scalar keeps its line comment.
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			if old := node.Content[i+1]; old.Kind == yaml.ScalarNode && value.Kind == yaml.ScalarNode && value.LineComment == "" {
+				value.LineComment = old.LineComment
+			}
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// deleteMappingKey removes key from a mapping node, reporting whether it existed
+func deleteMappingKey(node *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
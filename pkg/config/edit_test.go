@@ -0,0 +1,109 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const editInput = `# Project settings
+project:
+  name: shop
+
+services:
+  # The web frontend
+  web:
+    port: 3000
+
+groups:
+  backend: [api, worker]
+  jobs: [worker]
+`
+
+func TestAddService(t *testing.T) {
+	out, err := AddService([]byte(editInput), "api", ServiceConfig{
+		Port:        6060,
+		HealthCheck: &HealthCheckConfig{Enabled: true, Endpoint: "/healthz"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"# Project settings", "# The web frontend", "  api:\n    port: 6060\n    health_check:\n      enabled: true\n      endpoint: /healthz\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(out, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Services["api"].Port != 6060 || cfg.Services["web"].Port != 3000 {
+		t.Errorf("services = %+v", cfg.Services)
+	}
+
+	if _, err := AddService(out, "api", ServiceConfig{}); err == nil {
+		t.Error("expected duplicate service to fail")
+	}
+}
+
+func TestAddServiceEmpty(t *testing.T) {
+	out, err := AddService(nil, "api", ServiceConfig{Port: 8080})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "services:\n  api:\n    port: 8080\n" {
+		t.Errorf("output:\n%s", out)
+	}
+}
+
+func TestRemoveService(t *testing.T) {
+	out, removed, err := RemoveService([]byte(editInput), "worker")
+	if err != nil || removed {
+		t.Fatalf("RemoveService(worker) = %v, %v; want not configured", removed, err)
+	}
+	if string(out) != editInput {
+		t.Error("input changed for a missing service")
+	}
+
+	withWorker, err := AddService([]byte(editInput), "worker", ServiceConfig{Port: 9000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, removed, err = RemoveService(withWorker, "worker")
+	if err != nil || !removed {
+		t.Fatalf("RemoveService(worker) = %v, %v", removed, err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(out, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cfg.Services["worker"]; ok {
+		t.Error("worker still configured")
+	}
+	if got := cfg.Groups["backend"]; len(got) != 1 || got[0] != "api" {
+		t.Errorf("backend = %v, want [api]", got)
+	}
+	if _, ok := cfg.Groups["jobs"]; ok {
+		t.Error("empty group jobs kept")
+	}
+	if !strings.Contains(string(out), "# The web frontend") {
+		t.Errorf("comments lost:\n%s", out)
+	}
+}
+
+func TestSetServicePort(t *testing.T) {
+	out, err := SetServicePort([]byte(editInput), "web", 3001)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "  web:\n    port: 3001\n") {
+		t.Errorf("output:\n%s", out)
+	}
+	if _, err := SetServicePort([]byte(editInput), "api", 8080); err == nil {
+		t.Error("expected unconfigured service to fail")
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExpandGroups replaces group names in names with their members, recursively,
+// keeping the first occurrence of each service. Names that are not groups
+// are returned as they are.
+func (c *Config) ExpandGroups(names []string) ([]string, error) {
+	var out []string
+	seen := make(map[string]bool)
+
+	var expand func(name string, path []string) error
+	expand = func(name string, path []string) error {
+		members, ok := c.Groups[name]
+		if !ok {
+			if !seen[name] {
+				seen[name] = true
+				out = append(out, name)
+			}
+			return nil
+		}
+
+		for _, p := range path {
+			if p == name {
+				return fmt.Errorf("group %s includes itself: %s", name, strings.Join(append(path, name), " → "))
+			}
+		}
+		for _, member := range members {
+			if err := expand(member, append(path, name)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, name := range names {
+		if err := expand(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// validateGroups checks that groups are not empty, do not shadow a service
+// and do not include themselves
+func (c *Config) validateGroups() error {
+	names := make([]string, 0, len(c.Groups))
+	for name := range c.Groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if len(c.Groups[name]) == 0 {
+			return fmt.Errorf("group %s has no services", name)
+		}
+		if _, ok := c.Services[name]; ok {
+			return fmt.Errorf("group %s has the same name as a service", name)
+		}
+		if _, err := c.ExpandGroups([]string{name}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
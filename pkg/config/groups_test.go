@@ -0,0 +1,77 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandGroups(t *testing.T) {
+	cfg := &Config{Groups: map[string][]string{
+		"backend":  {"api", "worker", "postgres"},
+		"frontend": {"web", "vite"},
+		"all":      {"frontend", "backend", "api"},
+		"loop":     {"api", "cycle"},
+		"cycle":    {"loop"},
+	}}
+
+	tests := []struct {
+		names   []string
+		want    []string
+		wantErr bool
+	}{
+		{names: []string{"api"}, want: []string{"api"}},
+		{names: []string{"backend"}, want: []string{"api", "worker", "postgres"}},
+		{names: []string{"web", "backend", "api"}, want: []string{"web", "api", "worker", "postgres"}},
+		{names: []string{"all"}, want: []string{"web", "vite", "api", "worker", "postgres"}},
+		{names: []string{"loop"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := cfg.ExpandGroups(tt.names)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ExpandGroups(%v) error = %v, wantErr %v", tt.names, err, tt.wantErr)
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExpandGroups(%v) = %v, want %v", tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestValidateGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			cfg:  Config{Groups: map[string][]string{"backend": {"api"}}},
+		},
+		{
+			name:    "empty group",
+			cfg:     Config{Groups: map[string][]string{"backend": {}}},
+			wantErr: true,
+		},
+		{
+			name: "shadows a service",
+			cfg: Config{
+				Services: map[string]ServiceConfig{"api": {}},
+				Groups:   map[string][]string{"api": {"worker"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "includes itself",
+			cfg:     Config{Groups: map[string][]string{"a": {"b"}, "b": {"a"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
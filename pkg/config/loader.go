@@ -1,9 +1,18 @@
 package config
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,12 +26,25 @@ const (
 
 	// GlobalConfigDir is the global config directory
 	GlobalConfigDir = ".config/space"
+
+	// StdinConfigFile as a config file path means standard input
+	StdinConfigFile = "-"
+
+	// maxExtendsDepth bounds chains of configs extending each other
+	maxExtendsDepth = 8
+
+	// extendsTimeout bounds fetching a base config from a URL
+	extendsTimeout = 10 * time.Second
 )
 
 // Loader loads and merges configurations from multiple sources
 type Loader struct {
 	workDir string
 	homeDir string
+
+	// configFile and configData replace the discovered project config
+	configFile string
+	configData []byte
 }
 
 // NewLoader creates a new config loader
@@ -45,32 +67,50 @@ func NewLoader(workDir string) (*Loader, error) {
 	}, nil
 }
 
+// UseConfigFile makes Load read the project config from path instead of
+// looking for .space.yaml in the work directory. Unlike a discovered file,
+// it must exist. Relative paths inside it, such as compose files, still
+// resolve against the work directory.
+func (l *Loader) UseConfigFile(path string) {
+	l.configFile = path
+	l.configData = nil
+}
+
+// UseConfigData makes Load parse data as the project config, for configs
+// read from standard input
+func (l *Loader) UseConfigData(data []byte) {
+	l.configFile = StdinConfigFile
+	l.configData = data
+}
+
 // Load loads and merges configurations from all sources
 // Priority (highest to lowest):
-// 1. Project-level config (.space.yaml in workDir)
+// 1. Project-level config (.space.yaml in workDir, or UseConfigFile/UseConfigData)
 // 2. Global config (~/.config/space/config.yaml)
 // 3. Defaults
 func (l *Loader) Load() (*Config, error) {
-	// Start with defaults
-	config := Defaults()
-
 	// Load global config
-	globalConfig, err := l.loadGlobalConfig()
+	globalNode, err := l.loadGlobalConfig()
 	if err != nil {
 		// Global config is optional, just log error
 		// In real implementation, use logger here
-		_ = err
-	} else if globalConfig != nil {
-		config = config.Merge(globalConfig)
+		globalNode = nil
 	}
 
-	// Load project config
-	projectConfig, err := l.loadProjectConfig()
+	// Load project config; it is optional for generic use, but one that
+	// exists must load
+	projectNode, err := l.loadProjectConfig()
 	if err != nil {
-		// Project config is optional for generic use
-		_ = err
-	} else if projectConfig != nil {
-		config = config.Merge(projectConfig)
+		return nil, err
+	}
+
+	// Layer the files and decode them over the defaults, so keys set to
+	// false or null override the layers beneath
+	config := Defaults()
+	if merged := MergeYAML(globalNode, projectNode); merged != nil {
+		if err := merged.Decode(config); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
 	}
 
 	// Validate merged config
@@ -83,53 +123,209 @@ func (l *Loader) Load() (*Config, error) {
 
 // LoadFromFile loads config from a specific file
 func (l *Loader) LoadFromFile(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+	config, _, err := readConfigNode(path)
+	return config, err
+}
+
+// parseConfigNode parses and validates the YAML of a config file, returning
+// its root node for layering too. The node is nil for an empty file.
+func parseConfigNode(data []byte) (*Config, *yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
 	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	root := documentRoot(&doc)
+	if root == nil {
+		return &config, nil, nil
+	}
+	if err := root.Decode(&config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
 	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	return &config, nil
+	return &config, root, nil
 }
 
-// loadGlobalConfig loads the global configuration
-func (l *Loader) loadGlobalConfig() (*Config, error) {
+// readConfigNode reads and validates a config file, returning its parsed
+// form and root node
+func readConfigNode(path string) (*Config, *yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	return parseConfigNode(data)
+}
+
+// loadGlobalConfig loads the YAML of the global configuration
+func (l *Loader) loadGlobalConfig() (*yaml.Node, error) {
 	configPath := filepath.Join(l.homeDir, GlobalConfigDir, "config.yaml")
 
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		return nil, nil // No global config is okay
 	}
 
-	return l.LoadFromFile(configPath)
+	_, node, err := readConfigNode(configPath)
+	return node, err
 }
 
-// loadProjectConfig loads the project-level configuration
-func (l *Loader) loadProjectConfig() (*Config, error) {
-	// Try .space.yaml first
-	configPath := filepath.Join(l.workDir, ConfigFileName)
-	if _, err := os.Stat(configPath); err == nil {
-		return l.LoadFromFile(configPath)
+// loadProjectConfig loads the YAML of the project-level configuration with
+// the configs it extends merged beneath it
+func (l *Loader) loadProjectConfig() (*yaml.Node, error) {
+	if l.configFile == StdinConfigFile {
+		config, node, err := parseConfigNode(l.configData)
+		if err != nil {
+			return nil, err
+		}
+		return l.withBase(config, node, filepath.Join(l.workDir, StdinConfigFile), nil)
 	}
 
-	// Try space.yaml
-	configPath = filepath.Join(l.workDir, AlternateConfigFileName)
-	if _, err := os.Stat(configPath); err == nil {
-		return l.LoadFromFile(configPath)
+	path := l.configFile
+	if path == "" {
+		var err error
+		if path, err = l.FindConfigFile(); err != nil {
+			return nil, nil // No project config is okay
+		}
+	}
+
+	config, node, err := readConfigNode(path)
+	if err != nil {
+		return nil, err
+	}
+	return l.withBase(config, node, path, []string{path})
+}
+
+// withBase merges the config named by config.Extends, and the ones it
+// extends in turn, beneath node, the YAML of config. from is the file or
+// URL config was read from; chain lists the configs already loaded, to
+// detect cycles.
+func (l *Loader) withBase(config *Config, node *yaml.Node, from string, chain []string) (*yaml.Node, error) {
+	if config.Extends == "" {
+		return node, nil
+	}
+
+	ref, err := resolveExtends(config.Extends, from)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(chain, ref) {
+		return nil, fmt.Errorf("extends cycle: %s → %s", strings.Join(chain, " → "), ref)
+	}
+	if len(chain) >= maxExtendsDepth {
+		return nil, fmt.Errorf("extends chain deeper than %d configs at %s", maxExtendsDepth, ref)
+	}
+
+	var data []byte
+	if isURL(ref) {
+		data, err = fetchBaseConfig(ref)
+	} else {
+		data, err = os.ReadFile(ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base config %s: %w", ref, err)
+	}
+
+	base, baseNode, err := parseConfigNode(data)
+	if err != nil {
+		return nil, fmt.Errorf("base config %s: %w", ref, err)
+	}
+	baseNode, err = l.withBase(base, baseNode, ref, append(chain, ref))
+	if err != nil {
+		return nil, err
+	}
+	return MergeYAML(baseNode, node), nil
+}
+
+// resolveExtends resolves an extends reference against the file or URL
+// declaring it
+func resolveExtends(ref, from string) (string, error) {
+	if isURL(ref) {
+		return ref, nil
+	}
+	if isURL(from) {
+		base, err := url.Parse(from)
+		if err != nil {
+			return "", fmt.Errorf("invalid config URL %s: %w", from, err)
+		}
+		rel, err := url.Parse(filepath.ToSlash(ref))
+		if err != nil {
+			return "", fmt.Errorf("invalid extends %q: %w", ref, err)
+		}
+		return base.ResolveReference(rel).String(), nil
+	}
+	if filepath.IsAbs(ref) {
+		return filepath.Clean(ref), nil
+	}
+	return filepath.Join(filepath.Dir(from), ref), nil
+}
+
+// isURL reports whether an extends reference is an http(s) URL
+func isURL(ref string) bool {
+	return strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://")
+}
+
+// fetchBaseConfig downloads a base config. Successful downloads are cached,
+// and the cached copy is used when the URL can't be reached, so projects
+// keep loading offline.
+func fetchBaseConfig(rawURL string) ([]byte, error) {
+	sum := sha256.Sum256([]byte(rawURL))
+	var cachePath string
+	if dir, err := os.UserCacheDir(); err == nil {
+		cachePath = filepath.Join(dir, "space", "extends", hex.EncodeToString(sum[:8])+".yaml")
+	}
+
+	data, err := downloadConfig(rawURL)
+	if err != nil {
+		if cachePath != "" {
+			if cached, cacheErr := os.ReadFile(cachePath); cacheErr == nil {
+				return cached, nil
+			}
+		}
+		return nil, err
 	}
 
-	return nil, nil // No project config is okay
+	if cachePath != "" {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+			_ = os.WriteFile(cachePath, data, 0644)
+		}
+	}
+	return data, nil
+}
+
+// downloadConfig fetches a URL within extendsTimeout
+func downloadConfig(rawURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), extendsTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", rawURL, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
 }
 
 // FindConfigFile finds the config file in the work directory
 func (l *Loader) FindConfigFile() (string, error) {
+	if l.configFile == StdinConfigFile {
+		return "", fmt.Errorf("config was read from standard input")
+	}
+	if l.configFile != "" {
+		return l.configFile, nil
+	}
+
 	// Try .space.yaml first
 	configPath := filepath.Join(l.workDir, ConfigFileName)
 	if _, err := os.Stat(configPath); err == nil {
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoaderExplicitConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, ConfigFileName), []byte("project:\n  name: discovered\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	generated := filepath.Join(t.TempDir(), "ci.yaml")
+	if err := os.WriteFile(generated, []byte("project:\n  name: generated\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader, err := NewLoader(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loader.Load()
+	if err != nil || cfg.Project.Name != "discovered" {
+		t.Fatalf("discovered config: %v, %v", cfg, err)
+	}
+
+	loader.UseConfigFile(generated)
+	if cfg, err := loader.Load(); err != nil || cfg.Project.Name != "generated" {
+		t.Errorf("config file: %v, %v", cfg, err)
+	}
+	if path, err := loader.FindConfigFile(); err != nil || path != generated {
+		t.Errorf("FindConfigFile() = %s, %v", path, err)
+	}
+
+	loader.UseConfigData([]byte("project:\n  name: piped\n"))
+	if cfg, err := loader.Load(); err != nil || cfg.Project.Name != "piped" {
+		t.Errorf("config data: %v, %v", cfg, err)
+	}
+	if _, err := loader.FindConfigFile(); err == nil {
+		t.Error("expected no file for a config read from stdin")
+	}
+
+	// Unlike a discovered file, an explicit one must exist
+	loader.UseConfigFile(filepath.Join(workDir, "missing.yaml"))
+	if _, err := loader.Load(); err == nil {
+		t.Error("expected a missing config file to fail")
+	}
+	loader.UseConfigData([]byte("services: [\n"))
+	if _, err := loader.Load(); err == nil {
+		t.Error("expected invalid config data to fail")
+	}
+}
+
+func TestLoaderExtends(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("shared/org.yaml", "ports:\n  range_start: 20000\n  range_end: 21000\nnetwork:\n  custom_domain: org.test\n")
+	write("shared/.space.base.yaml", "extends: org.yaml\nservices:\n  api:\n    port: 8080\n  db:\n    port: 5432\n")
+	write("app/.space.yaml", "extends: ../shared/.space.base.yaml\nproject:\n  name: app\nservices:\n  api:\n    port: 9090\n")
+
+	loader, err := NewLoader(filepath.Join(root, "app"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Project.Name != "app" || cfg.Services["api"].Port != 9090 || cfg.Services["db"].Port != 5432 {
+		t.Errorf("local config should override its base: %+v", cfg.Services)
+	}
+	if cfg.Ports.RangeStart != 20000 || cfg.Network.BaseDomains()[0] != "org.test" {
+		t.Errorf("settings of the base's base are missing: %+v %+v", cfg.Ports, cfg.Network)
+	}
+
+	write("shared/org.yaml", "extends: .space.base.yaml\n")
+	if _, err := loader.Load(); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected an extends cycle error, got %v", err)
+	}
+
+	write("app/.space.yaml", "extends: ../shared/missing.yaml\n")
+	if _, err := loader.Load(); err == nil {
+		t.Error("expected a missing base config to fail")
+	}
+}
+
+func TestLoaderExtendsURL(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	up := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case !up:
+			http.Error(w, "down", http.StatusServiceUnavailable)
+		case r.URL.Path == "/org/base.yaml":
+			fmt.Fprint(w, "extends: ports.yaml\nagent:\n  ttl: 2h\n")
+		case r.URL.Path == "/org/ports.yaml":
+			fmt.Fprint(w, "ports:\n  range_start: 30000\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, ConfigFileName), []byte("extends: "+server.URL+"/org/base.yaml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loader, err := NewLoader(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Agent.TTL != 2*time.Hour || cfg.Ports.RangeStart != 30000 {
+		t.Errorf("URL bases not merged: %+v %+v", cfg.Agent, cfg.Ports)
+	}
+
+	// Offline, the last downloaded copies are used
+	up = false
+	if cfg, err := loader.Load(); err != nil || cfg.Ports.RangeStart != 30000 {
+		t.Errorf("cached base config: %v, %v", cfg, err)
+	}
+}
@@ -0,0 +1,275 @@
+package config
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Merge merges another config into this one (other takes precedence) and
+// returns the result; neither config is modified. Fields set in other
+// replace those of c. Sections, pointers to sections and maps merge
+// recursively, so a service configured in both keeps the fields only c sets.
+// Lists of named entries (databases, custom hooks) merge by name; other
+// lists are replaced.
+//
+// Zero values in other count as unset, so Merge can't turn a field back to
+// false or empty. Config files are layered with MergeYAML instead, where an
+// explicit false or null does.
+func (c *Config) Merge(other *Config) *Config {
+	if other == nil {
+		return c
+	}
+
+	merged := mergeValue(reflect.ValueOf(*c), reflect.ValueOf(*other)).Interface().(Config)
+	return &merged
+}
+
+// mergeValue returns a copy of dst with src merged over it
+func mergeValue(dst, src reflect.Value) reflect.Value {
+	switch src.Kind() {
+	case reflect.Struct:
+		out := reflect.New(src.Type()).Elem()
+		out.Set(cloneValue(dst))
+		for i := 0; i < src.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(mergeValue(dst.Field(i), src.Field(i)))
+			}
+		}
+		return out
+
+	case reflect.Ptr:
+		if src.IsNil() {
+			return cloneValue(dst)
+		}
+		if dst.IsNil() {
+			return cloneValue(src)
+		}
+		out := reflect.New(src.Type().Elem())
+		out.Elem().Set(mergeValue(dst.Elem(), src.Elem()))
+		return out
+
+	case reflect.Map:
+		if src.Len() == 0 {
+			return cloneValue(dst)
+		}
+		out := cloneValue(dst)
+		if out.IsNil() {
+			out = reflect.MakeMapWithSize(src.Type(), src.Len())
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			if old := out.MapIndex(iter.Key()); old.IsValid() {
+				out.SetMapIndex(iter.Key(), mergeValue(old, iter.Value()))
+			} else {
+				out.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+			}
+		}
+		return out
+
+	case reflect.Slice:
+		if src.Len() == 0 {
+			return cloneValue(dst)
+		}
+		if !hasNameField(src.Type().Elem()) {
+			return cloneValue(src)
+		}
+		out := cloneValue(dst)
+		for i := 0; i < src.Len(); i++ {
+			item := src.Index(i)
+			if j := indexByName(out, item.FieldByName("Name").String()); j >= 0 {
+				out.Index(j).Set(mergeValue(out.Index(j), item))
+			} else {
+				out = reflect.Append(out, cloneValue(item))
+			}
+		}
+		return out
+
+	default:
+		if src.IsZero() {
+			return dst
+		}
+		return src
+	}
+}
+
+// cloneValue deep-copies a value, so merged configs share no maps, slices
+// or pointers with their inputs
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(cloneValue(v.Field(i)))
+			}
+		}
+		return out
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(cloneValue(v.Elem()))
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return out
+
+	default:
+		return v
+	}
+}
+
+// hasNameField reports whether list items of type t are merged by name
+func hasNameField(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	field, ok := t.FieldByName("Name")
+	return ok && field.Type.Kind() == reflect.String
+}
+
+// indexByName returns the index of the item named name in a slice of
+// structs, or -1
+func indexByName(items reflect.Value, name string) int {
+	for i := 0; i < items.Len(); i++ {
+		if items.Index(i).FieldByName("Name").String() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// MergeYAML merges the YAML of over onto base and returns the result;
+// neither node is modified. It layers config files the way Merge layers
+// configs, except that every key present in over counts: an explicit false
+// or 0 replaces the base value, and null clears it. A cleared list, map or
+// optional section ends up empty; other values fall back to their built-in
+// default.
+func MergeYAML(base, over *yaml.Node) *yaml.Node {
+	base, over = documentRoot(base), documentRoot(over)
+	switch {
+	case over == nil:
+		return cloneNode(base)
+	case base == nil, base.Kind != over.Kind:
+		return cloneNode(over)
+	case over.Kind == yaml.MappingNode:
+		out := cloneNode(base)
+		for i := 0; i+1 < len(over.Content); i += 2 {
+			key, value := over.Content[i], over.Content[i+1]
+			j := mappingIndex(out, key.Value)
+			switch {
+			case j < 0:
+				out.Content = append(out.Content, cloneNode(key), cloneNode(value))
+			case isNull(value):
+				out.Content[j+1] = cloneNode(value)
+			default:
+				out.Content[j+1] = MergeYAML(out.Content[j+1], value)
+			}
+		}
+		return out
+	case over.Kind == yaml.SequenceNode && namedSequence(base) && namedSequence(over):
+		out := cloneNode(base)
+		for _, item := range over.Content {
+			if j := sequenceIndexByName(out, mappingValue(item, "name").Value); j >= 0 {
+				out.Content[j] = MergeYAML(out.Content[j], item)
+			} else {
+				out.Content = append(out.Content, cloneNode(item))
+			}
+		}
+		return out
+	default:
+		return cloneNode(over)
+	}
+}
+
+// documentRoot returns the content of a document node, or the node itself;
+// nil for empty documents
+func documentRoot(node *yaml.Node) *yaml.Node {
+	if node == nil || node.Kind == 0 {
+		return nil
+	}
+	if node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return node.Content[0]
+	}
+	return node
+}
+
+// cloneNode deep-copies a YAML node
+func cloneNode(node *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	out := *node
+	out.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		out.Content[i] = cloneNode(child)
+	}
+	return &out
+}
+
+// isNull reports whether a node is an explicit null
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// mappingIndex returns the index of key in a mapping node, or -1
+func mappingIndex(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// namedSequence reports whether every item of a sequence is a mapping with
+// a name, like databases and custom hooks
+func namedSequence(node *yaml.Node) bool {
+	if node.Kind != yaml.SequenceNode || len(node.Content) == 0 {
+		return false
+	}
+	for _, item := range node.Content {
+		if item.Kind != yaml.MappingNode {
+			return false
+		}
+		if name := mappingValue(item, "name"); name == nil || name.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+	return true
+}
+
+// sequenceIndexByName returns the index of the item named name, or -1
+func sequenceIndexByName(node *yaml.Node, name string) int {
+	for i, item := range node.Content {
+		if mappingValue(item, "name").Value == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,227 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fill sets every field of v to a non-zero value
+func fill(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				fill(v.Field(i))
+			}
+		}
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem())
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		key, value := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+		fill(key)
+		fill(value)
+		v.SetMapIndex(key, value)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0))
+	case reflect.String:
+		v.SetString("set")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int64:
+		v.SetInt(7)
+	}
+}
+
+func TestMergeEveryField(t *testing.T) {
+	var other Config
+	fill(reflect.ValueOf(&other).Elem())
+
+	// Every field set in other wins over the defaults
+	if merged := Defaults().Merge(&other); !reflect.DeepEqual(*merged, other) {
+		t.Errorf("Merge dropped fields:\n got %+v\nwant %+v", *merged, other)
+	}
+	// and merging nothing over it changes nothing
+	if merged := other.Merge(&Config{}); !reflect.DeepEqual(*merged, other) {
+		t.Errorf("Merge of an empty config changed fields:\n got %+v\nwant %+v", *merged, other)
+	}
+}
+
+func TestMergeDeep(t *testing.T) {
+	base := &Config{
+		Provider: ProviderConfig{OrbStack: &OrbStackConfig{DNSSuffix: ".orb.local", RemovePortBindings: true}},
+		Services: map[string]ServiceConfig{
+			"api": {Port: 8080, HealthCheck: &HealthCheckConfig{Enabled: true, Endpoint: "/health"}},
+			"db":  {Port: 5432},
+		},
+		Databases: []DatabaseConfig{{Name: "app", Service: "db", User: "postgres"}},
+		Hooks: HooksConfig{Custom: []CustomHookConfig{
+			{Name: "seed", Events: []string{"post-up"}, Command: "make seed"},
+			{Name: "lint", Command: "make lint"},
+		}},
+		Groups: map[string][]string{"backend": {"api", "db"}},
+	}
+	other := &Config{
+		Provider:  ProviderConfig{OrbStack: &OrbStackConfig{UseContainerDNS: true}},
+		Services:  map[string]ServiceConfig{"api": {Port: 9090}, "web": {Port: 3000}},
+		Databases: []DatabaseConfig{{Name: "app", Password: "secret"}, {Name: "cache", Service: "redis"}},
+		Hooks:     HooksConfig{Custom: []CustomHookConfig{{Name: "seed", Command: "make seed-dev"}}},
+		Groups:    map[string][]string{"backend": {"api"}},
+	}
+
+	merged := base.Merge(other)
+
+	if o := merged.Provider.OrbStack; o.DNSSuffix != ".orb.local" || !o.RemovePortBindings || !o.UseContainerDNS {
+		t.Errorf("provider options not merged: %+v", o)
+	}
+	api := merged.Services["api"]
+	if api.Port != 9090 || api.HealthCheck == nil || api.HealthCheck.Endpoint != "/health" {
+		t.Errorf("service fields not merged: %+v", api)
+	}
+	if len(merged.Services) != 3 {
+		t.Errorf("services = %v", merged.Services)
+	}
+	want := []DatabaseConfig{{Name: "app", Service: "db", User: "postgres", Password: "secret"}, {Name: "cache", Service: "redis"}}
+	if !reflect.DeepEqual(merged.Databases, want) {
+		t.Errorf("databases = %+v, want %+v", merged.Databases, want)
+	}
+	if hooks := merged.Hooks.Custom; len(hooks) != 2 || hooks[0].Command != "make seed-dev" || hooks[0].Events[0] != "post-up" {
+		t.Errorf("custom hooks = %+v", hooks)
+	}
+	if groups := merged.Groups["backend"]; !reflect.DeepEqual(groups, []string{"api"}) {
+		t.Errorf("plain lists should be replaced, got %v", groups)
+	}
+
+	// The result shares nothing with its inputs
+	merged.Services["db"] = ServiceConfig{Port: 1}
+	merged.Provider.OrbStack.DNSSuffix = ".changed"
+	merged.Databases[0].User = "changed"
+	if base.Services["db"].Port != 5432 || base.Provider.OrbStack.DNSSuffix != ".orb.local" || base.Databases[0].User != "postgres" {
+		t.Error("Merge result aliases its base")
+	}
+	if base.Merge(nil) != base {
+		t.Error("Merge(nil) should return the config itself")
+	}
+}
+
+func TestMergeYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		over string
+		want string
+	}{
+		{
+			name: "scalars and nested mappings",
+			base: "project:\n  name: base\n  locale: C\n",
+			over: "project:\n  name: app\n",
+			want: "project:\n  name: app\n  locale: C\n",
+		},
+		{
+			name: "explicit false wins",
+			base: "network:\n  dns_hashing: true\n",
+			over: "network:\n  dns_hashing: false\n",
+			want: "network:\n  dns_hashing: false\n",
+		},
+		{
+			name: "null clears",
+			base: "project:\n  compose_files: [a.yml, b.yml]\n",
+			over: "project:\n  compose_files: null\n",
+			want: "project:\n  compose_files: null\n",
+		},
+		{
+			name: "named lists merge by name",
+			base: "databases:\n  - name: app\n    user: postgres\n  - name: cache\n",
+			over: "databases:\n  - name: app\n    password: secret\n  - name: search\n",
+			want: "databases:\n  - name: app\n    user: postgres\n    password: secret\n  - name: cache\n  - name: search\n",
+		},
+		{
+			name: "plain lists are replaced",
+			base: "groups:\n  backend: [api, db]\n",
+			over: "groups:\n  backend: [api]\n",
+			want: "groups:\n  backend: [api]\n",
+		},
+		{
+			name: "empty override",
+			base: "project:\n  name: base\n",
+			over: "",
+			want: "project:\n  name: base\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var base, over, want yaml.Node
+			for node, text := range map[*yaml.Node]string{&base: tt.base, &over: tt.over, &want: tt.want} {
+				if err := yaml.Unmarshal([]byte(text), node); err != nil {
+					t.Fatal(err)
+				}
+			}
+			before, _ := yaml.Marshal(&base)
+
+			var got, expected interface{}
+			if err := MergeYAML(&base, &over).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if err := want.Decode(&expected); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, expected) {
+				t.Errorf("MergeYAML() = %v, want %v", got, expected)
+			}
+			if after, _ := yaml.Marshal(&base); string(after) != string(before) {
+				t.Error("MergeYAML modified its base")
+			}
+		})
+	}
+}
+
+func TestLoaderLayering(t *testing.T) {
+	home, workDir := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+
+	global := filepath.Join(home, GlobalConfigDir, "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(global), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		global:                                 "network:\n  dns_hashing: false\ndns:\n  query_log: true\nports:\n  range_start: 20000\n",
+		filepath.Join(workDir, ConfigFileName): "provider:\n  orbstack:\n    use_container_dns: true\ndns:\n  query_log: false\nproject:\n  compose_files: null\nports:\n  range_start: null\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loader, err := NewLoader(workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loader.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Network.DNSHashing {
+		t.Error("global dns_hashing: false should override the default")
+	}
+	if cfg.DNS.QueryLog {
+		t.Error("project query_log: false should override the global config")
+	}
+	if o := cfg.Provider.OrbStack; o == nil || !o.UseContainerDNS || o.DNSSuffix != ".orb.local" || !o.RemovePortBindings {
+		t.Errorf("orbstack options should merge with the defaults: %+v", o)
+	}
+	if len(cfg.Project.ComposeFiles) != 0 {
+		t.Errorf("compose_files: null should clear the list, got %v", cfg.Project.ComposeFiles)
+	}
+	if cfg.Ports.RangeStart != Defaults().Ports.RangeStart {
+		t.Errorf("range_start: null should restore the default, got %d", cfg.Ports.RangeStart)
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+import "testing"
+
+func TestValidateNamingFallback(t *testing.T) {
+	tests := []struct {
+		fallback []string
+		wantErr  bool
+	}{
+		{fallback: nil},
+		{fallback: []string{NamingDirectory}},
+		{fallback: []string{NamingGitBranch, NamingStatic}},
+		{fallback: []string{"branch"}, wantErr: true},
+		{fallback: []string{NamingDirectory, ""}, wantErr: true},
+	}
+	for _, tt := range tests {
+		cfg := Defaults()
+		cfg.Project.NamingFallback = tt.fallback
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.fallback, err, tt.wantErr)
+		}
+	}
+}
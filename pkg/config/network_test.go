@@ -0,0 +1,24 @@
+package config
+
+import "testing"
+
+func TestValidateSharedNetworks(t *testing.T) {
+	tests := []struct {
+		network string
+		wantErr bool
+	}{
+		{network: "auth"},
+		{network: "auth-stub_2"},
+		{network: "", wantErr: true},
+		{network: "-auth", wantErr: true},
+		{network: "Auth", wantErr: true},
+		{network: "auth stub", wantErr: true},
+	}
+	for _, tt := range tests {
+		cfg := Defaults()
+		cfg.Services = map[string]ServiceConfig{"auth": {SharedNetworks: []string{tt.network}}}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%q) error = %v, wantErr %v", tt.network, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import "strings"
+
+// Provider types of provider.type and the provider sections
+const (
+	ProviderOrbStack = "orbstack"
+	ProviderDocker   = "docker"
+)
+
+// PlatformFor returns the default platform of services on a provider
+// (ProviderOrbStack, ProviderDocker or any other for generic docker): the
+// provider section's, falling back to provider.platform
+func (p ProviderConfig) PlatformFor(provider string) string {
+	switch {
+	case provider == ProviderOrbStack && p.OrbStack != nil && p.OrbStack.Platform != "":
+		return p.OrbStack.Platform
+	case provider == ProviderDocker && p.Docker != nil && p.Docker.Platform != "":
+		return p.Docker.Platform
+	}
+	return p.Platform
+}
+
+// ComposeArgsFor returns the arguments added to "docker compose up" on a
+// provider: provider.compose_args followed by the provider section's
+func (p ProviderConfig) ComposeArgsFor(provider string) []string {
+	args := append([]string(nil), p.ComposeArgs...)
+	switch {
+	case provider == ProviderOrbStack && p.OrbStack != nil:
+		args = append(args, p.OrbStack.ComposeArgs...)
+	case provider == ProviderDocker && p.Docker != nil:
+		args = append(args, p.Docker.ComposeArgs...)
+	}
+	return args
+}
+
+// validPlatform reports whether platform looks like os/arch[/variant]
+func validPlatform(platform string) bool {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return false
+	}
+	for _, part := range parts {
+		if part == "" {
+			return false
+		}
+	}
+	return true
+}
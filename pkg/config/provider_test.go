@@ -0,0 +1,71 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProviderPlatformAndArgs(t *testing.T) {
+	p := ProviderConfig{
+		Platform:    "linux/amd64",
+		ComposeArgs: []string{"--pull", "missing"},
+		OrbStack:    &OrbStackConfig{Platform: "linux/arm64", ComposeArgs: []string{"--wait"}},
+		Docker:      &DockerConfig{},
+	}
+
+	if got := p.PlatformFor(ProviderOrbStack); got != "linux/arm64" {
+		t.Errorf("PlatformFor(orbstack) = %q", got)
+	}
+	if got := p.PlatformFor(ProviderDocker); got != "linux/amd64" {
+		t.Errorf("PlatformFor(docker) = %q, want the provider.platform fallback", got)
+	}
+	if got := p.ComposeArgsFor(ProviderOrbStack); !reflect.DeepEqual(got, []string{"--pull", "missing", "--wait"}) {
+		t.Errorf("ComposeArgsFor(orbstack) = %q", got)
+	}
+	if got := p.ComposeArgsFor(""); !reflect.DeepEqual(got, []string{"--pull", "missing"}) {
+		t.Errorf("ComposeArgsFor(generic) = %q", got)
+	}
+	if p.ComposeArgsFor(ProviderOrbStack); len(p.ComposeArgs) != 2 {
+		t.Errorf("ComposeArgsFor changed provider.compose_args to %q", p.ComposeArgs)
+	}
+}
+
+func TestValidatePlatform(t *testing.T) {
+	for _, platform := range []string{"linux/amd64", "linux/arm/v7"} {
+		cfg := Defaults()
+		cfg.Services = map[string]ServiceConfig{"api": {Platform: platform}}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate(%s) error = %v", platform, err)
+		}
+	}
+
+	cfg := Defaults()
+	cfg.Services = map[string]ServiceConfig{"api": {Platform: "amd64"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "services.api.platform") {
+		t.Errorf("Validate(amd64) error = %v", err)
+	}
+
+	cfg = Defaults()
+	cfg.Provider.Docker = &DockerConfig{Platform: "linux/"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "provider.docker.platform") {
+		t.Errorf("Validate(linux/) error = %v", err)
+	}
+}
+
+func TestValidateParallelism(t *testing.T) {
+	cfg := Defaults()
+	if cfg.Provider.Parallelism != DefaultParallelism {
+		t.Errorf("default parallelism = %d, want %d", cfg.Provider.Parallelism, DefaultParallelism)
+	}
+
+	cfg.Provider.Parallelism = 0
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with no limit error = %v", err)
+	}
+
+	cfg.Provider.Parallelism = -1
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "provider.parallelism") {
+		t.Errorf("Validate() error = %v, want a provider.parallelism error", err)
+	}
+}
@@ -1,15 +1,30 @@
 package config
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // Config represents the complete configuration for space-cli
 type Config struct {
+	// Extends names a base config, a file path or an http(s) URL, merged
+	// beneath this one. Relative paths resolve against the directory of the
+	// file declaring them; bases may extend further bases.
+	Extends string `yaml:"extends,omitempty" json:"extends,omitempty"`
+
 	// Project configuration
 	Project ProjectConfig `yaml:"project" json:"project"`
 
 	// Services configuration
 	Services map[string]ServiceConfig `yaml:"services" json:"services"`
 
+	// Groups name sets of services, usable wherever a service list is
+	// accepted (up, down, restart, logs), e.g. backend: [api, worker,
+	// postgres]. Members may be groups. 'space up' also starts the services
+	// the members depend on.
+	Groups map[string][]string `yaml:"groups,omitempty" json:"groups,omitempty"`
+
 	// Databases configuration
 	Databases []DatabaseConfig `yaml:"databases,omitempty" json:"databases,omitempty"`
 
@@ -25,11 +40,29 @@ type Config struct {
 	// Networking configuration
 	Network NetworkConfig `yaml:"network,omitempty" json:"network,omitempty"`
 
+	// DNS daemon configuration
+	DNS DNSConfig `yaml:"dns,omitempty" json:"dns,omitempty"`
+
+	// Background agent configuration
+	Agent AgentConfig `yaml:"agent,omitempty" json:"agent,omitempty"`
+
+	// Local tracing configuration
+	Observability ObservabilityConfig `yaml:"observability,omitempty" json:"observability,omitempty"`
+
+	// Worktree bootstrap configuration (space worktree add)
+	Worktree WorktreeConfig `yaml:"worktree,omitempty" json:"worktree,omitempty"`
+
+	// Output configuration, usually set in the global config
+	Output OutputConfig `yaml:"output,omitempty" json:"output,omitempty"`
+
 	// Ports configuration
 	Ports PortsConfig `yaml:"ports,omitempty" json:"ports,omitempty"`
 
 	// Hooks configuration
 	Hooks HooksConfig `yaml:"hooks,omitempty" json:"hooks,omitempty"`
+
+	// Secrets resolved at up time and passed to containers, keyed by secret name
+	Secrets map[string]SecretConfig `yaml:"secrets,omitempty" json:"secrets,omitempty"`
 }
 
 // ProjectConfig defines project-level settings
@@ -45,11 +78,49 @@ type ProjectConfig struct {
 	// Options: "git-branch" (default), "directory", "static"
 	NamingStrategy string `yaml:"naming_strategy,omitempty" json:"naming_strategy,omitempty"`
 
+	// NamingFallback lists the strategies tried in order when
+	// naming_strategy can't name the project, as git-branch does outside a
+	// git repository or on a detached HEAD no CI ref names.
+	// Default: ["directory"]
+	NamingFallback []string `yaml:"naming_fallback,omitempty" json:"naming_fallback,omitempty"`
+
 	// ComposeFiles to use (default: ["docker-compose.yml"])
 	ComposeFiles []string `yaml:"compose_files,omitempty" json:"compose_files,omitempty"`
 
-	// WorkDir override (default: current directory)
+	// ComposeProjectDirectory is the directory relative paths inside the
+	// compose files (build contexts, bind mounts, env_file) resolve against,
+	// passed to docker compose as --project-directory. Relative to the
+	// working directory. Default: the directory of the first compose file.
+	ComposeProjectDirectory string `yaml:"compose_project_directory,omitempty" json:"compose_project_directory,omitempty"`
+
+	// IgnoreOverride excludes docker-compose.override.yml (and friends) from
+	// the compose file set. By default the override of a default compose file
+	// (docker-compose.yml, compose.yaml, ...) in the project root is detected
+	// and merged, matching plain docker compose.
+	IgnoreOverride bool `yaml:"ignore_override,omitempty" json:"ignore_override,omitempty"`
+
+	// IgnoreDatabaseImages turns off registering databases for compose
+	// services running postgres, mysql, mongo or redis images. By default a
+	// database is derived from the image and its environment unless one is
+	// configured for the service.
+	IgnoreDatabaseImages bool `yaml:"ignore_database_images,omitempty" json:"ignore_database_images,omitempty"`
+
+	// WorkDir is informational; paths in the config resolve against the
+	// working directory given with --workdir, also for --config files
 	WorkDir string `yaml:"work_dir,omitempty" json:"work_dir,omitempty"`
+
+	// Timezone sets TZ in every service, e.g. "UTC", "Europe/Berlin" or
+	// "host" for the host's timezone. On Linux hosts the matching zone file
+	// is also mounted as /etc/localtime.
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+
+	// Locale sets LANG and LC_ALL in every service, e.g. "en_US.UTF-8"
+	Locale string `yaml:"locale,omitempty" json:"locale,omitempty"`
+
+	// MapUser runs the services that bind-mount host paths, and set no user
+	// of their own, as the user running space, so files they write on Linux
+	// hosts are not owned by root. HOST_UID and HOST_GID are set for them.
+	MapUser bool `yaml:"map_user,omitempty" json:"map_user,omitempty"`
 }
 
 // ServiceConfig defines configuration for a specific service
@@ -65,18 +136,60 @@ type ServiceConfig struct {
 	Shell string `yaml:"shell,omitempty" json:"shell,omitempty"`
 
 	// URL template for generating service URLs
-	// Variables: {host}, {port}, {service}, {project}
+	// Variables: {host}, {port}, {service}, {project}, {internal_port}, {external_port}
 	// Default: "http://{host}:{port}"
+	// Examples: "https://{host}:{port}/app", "http://{host}:8080/{service}"
 	URLTemplate string `yaml:"url_template,omitempty" json:"url_template,omitempty"`
 
+	// Primary marks the service as an entry point opened by "space open"
+	Primary bool `yaml:"primary,omitempty" json:"primary,omitempty"`
+
+	// Protocol spoken by the service: "http" (default), "tcp" or "grpc"
+	// Determines the connection string format and how health is probed
+	Protocol string `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+
+	// Scheme used in connection strings for tcp services (e.g., "postgres", "redis")
+	// Auto-detected from the compose image when not set
+	Scheme string `yaml:"scheme,omitempty" json:"scheme,omitempty"`
+
 	// HealthCheck configuration
 	HealthCheck *HealthCheckConfig `yaml:"health_check,omitempty" json:"health_check,omitempty"`
 
-	// Environment variables to inject
+	// CORS injects the origins of the project's http services into this
+	// service's environment (DNS mode), for backends serving a separate frontend
+	CORS *CORSConfig `yaml:"cors,omitempty" json:"cors,omitempty"`
+
+	// Environment variables to inject at up time (override the compose file)
+	// ${VAR} reads the host environment, ${secret:NAME} resolves a secret
 	Environment map[string]string `yaml:"environment,omitempty" json:"environment,omitempty"`
 
 	// Dependencies that must be running before this service
 	DependsOn []string `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
+
+	// Mail adds a Mailpit mail catcher to the stack and sets SMTP_HOST and
+	// SMTP_PORT on this service, so outgoing email lands in its web UI
+	Mail bool `yaml:"mail,omitempty" json:"mail,omitempty"`
+
+	// Tunnel configures the public URL "space tunnel" opens for the service
+	// through ngrok or cloudflared, e.g. for webhooks
+	Tunnel *TunnelConfig `yaml:"tunnel,omitempty" json:"tunnel,omitempty"`
+
+	// Platform runs the service for another platform, e.g. "linux/amd64" for
+	// an image without an arm64 build on Apple Silicon (emulated, with
+	// Rosetta on OrbStack). Takes precedence over provider.platform and the
+	// compose file
+	Platform string `yaml:"platform,omitempty" json:"platform,omitempty"`
+
+	// MapUser runs the service as the user running space, like
+	// project.map_user, whether or not it bind-mounts host paths
+	MapUser bool `yaml:"map_user,omitempty" json:"map_user,omitempty"`
+
+	// SharedNetworks attaches the service to networks shared by every
+	// worktree and project naming them, where their services reach each
+	// other by hostname (e.g. a shared auth stub). Each is the docker
+	// network space-net-<name>, created on up and removed by the down of
+	// the last project on it
+	SharedNetworks []string `yaml:"shared_networks,omitempty" json:"shared_networks,omitempty"`
 }
 
 // HealthCheckConfig defines health check settings
@@ -85,8 +198,15 @@ type HealthCheckConfig struct {
 	Enabled bool `yaml:"enabled" json:"enabled"`
 
 	// Endpoint to check (e.g., "/health", "/api/health")
+	// For grpc services this is the service name passed to grpc.health.v1
+	// (empty checks overall server health); ignored for tcp services
 	Endpoint string `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
 
+	// Command runs inside the service container (docker exec, sh -c) instead
+	// of a network probe; exit status 0 means healthy (e.g. "pg_isready -U {db_user}")
+	// Variables: {db_name}, {db_user}, {db_password}, {db_host}, {db_port}
+	Command string `yaml:"command,omitempty" json:"command,omitempty"`
+
 	// Timeout for health check
 	Timeout time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 
@@ -97,6 +217,70 @@ type HealthCheckConfig struct {
 	Retries int `yaml:"retries,omitempty" json:"retries,omitempty"`
 }
 
+// CORSConfig defines which allowed-origin variables are injected into a service
+type CORSConfig struct {
+	// Env lists the variables that receive the origins
+	// Default: ["CORS_ALLOWED_ORIGINS"]
+	Env []string `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// Origins are extra origins to allow in addition to the service URLs
+	Origins []string `yaml:"origins,omitempty" json:"origins,omitempty"`
+
+	// Separator joins the origins (default: ",")
+	Separator string `yaml:"separator,omitempty" json:"separator,omitempty"`
+}
+
+// Project naming strategies
+const (
+	NamingGitBranch = "git-branch"
+	NamingDirectory = "directory"
+	NamingStatic    = "static"
+)
+
+// Tunnel providers
+const (
+	TunnelCloudflared = "cloudflared"
+	TunnelNgrok       = "ngrok"
+)
+
+// TunnelConfig defines how "space tunnel" exposes a service publicly
+type TunnelConfig struct {
+	// Provider is "cloudflared" or "ngrok"
+	// Default: whichever is installed, cloudflared first
+	Provider string `yaml:"provider,omitempty" json:"provider,omitempty"`
+
+	// Env is a variable that receives the public URL, in the service
+	// environment, hook scripts and .space/state/tunnels.env
+	// (e.g., "STRIPE_WEBHOOK_URL")
+	Env string `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// Path is appended to the public URL in Env (e.g., "/webhooks/stripe")
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
+}
+
+// SecretConfig defines where a secret comes from and which services receive it.
+// Secret values are never written into the project directory.
+type SecretConfig struct {
+	// Source of the secret: "env", "keychain" (macOS) or "1password"
+	Source string `yaml:"source" json:"source"`
+
+	// Key identifies the secret within its source:
+	// env: variable name (default: secret name)
+	// keychain: "service" or "service/account"
+	// 1password: secret reference (e.g., "op://dev/stripe/api-key")
+	Key string `yaml:"key,omitempty" json:"key,omitempty"`
+
+	// Services that receive the secret (default: all services)
+	Services []string `yaml:"services,omitempty" json:"services,omitempty"`
+
+	// Env is the variable the secret is exposed as (default: secret name)
+	Env string `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// File mounts the secret read-only at this container path instead of
+	// exposing it as an environment variable
+	File string `yaml:"file,omitempty" json:"file,omitempty"`
+}
+
 // DatabaseConfig defines database-specific configuration
 type DatabaseConfig struct {
 	// Name of the database
@@ -123,6 +307,14 @@ type DatabaseConfig struct {
 	// AutoCreate creates the database if it doesn't exist
 	AutoCreate bool `yaml:"auto_create,omitempty" json:"auto_create,omitempty"`
 
+	// Shared runs one container of the database service for all worktrees
+	// of the repository instead of one per worktree. Each worktree gets its
+	// own database on it, named after this one and a hash of the project,
+	// and its service becomes a PgBouncer mapping the name onto it, so
+	// clients connect as before. The database is dropped by
+	// 'space down --volumes' and 'space worktree remove'. Postgres only.
+	Shared bool `yaml:"shared,omitempty" json:"shared,omitempty"`
+
 	// MigrationsPath is the path to migrations
 	// Can be a directory or a Go file to run
 	MigrationsPath string `yaml:"migrations_path,omitempty" json:"migrations_path,omitempty"`
@@ -146,8 +338,32 @@ type CommandsConfig struct {
 	// Variables: {migrations_path}, {db_name}, {db_user}, {db_host}, {db_port}
 	Migrate string `yaml:"migrate,omitempty" json:"migrate,omitempty"`
 
-	// Custom commands (key: command name, value: command template)
-	Custom map[string]string `yaml:"custom,omitempty" json:"custom,omitempty"`
+	// Custom commands run as "space <name>", on the host or in a service
+	// container; a plain string is the command
+	Custom map[string]CustomCommandConfig `yaml:"custom,omitempty" json:"custom,omitempty"`
+
+	// ContextStdin pipes the JSON context into the stdin of 'space run'
+	// commands, as before SPACE_CONTEXT_FILE, instead of forwarding the
+	// terminal's stdin
+	ContextStdin bool `yaml:"context_stdin,omitempty" json:"context_stdin,omitempty"`
+}
+
+// CustomCommandConfig defines a command of commands.custom
+type CustomCommandConfig struct {
+	// Command is run with sh -c; arguments given to "space <name>" are
+	// appended (e.g., "go test ./...")
+	Command string `yaml:"command" json:"command"`
+
+	// Service runs the command inside this service's container with
+	// docker compose exec instead of on the host
+	Service string `yaml:"service,omitempty" json:"service,omitempty"`
+
+	// Up starts the stack with "space up" first when the service, or with
+	// no service the whole stack, is not running
+	Up bool `yaml:"up,omitempty" json:"up,omitempty"`
+
+	// Description is shown in "space --help"
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
 }
 
 // ProviderConfig defines provider-specific settings
@@ -161,8 +377,25 @@ type ProviderConfig struct {
 
 	// Docker-specific configuration
 	Docker *DockerConfig `yaml:"docker,omitempty" json:"docker,omitempty"`
+
+	// Platform is the default platform of services whose compose definition
+	// sets none, e.g. "linux/amd64". The provider sections can set their own
+	Platform string `yaml:"platform,omitempty" json:"platform,omitempty"`
+
+	// ComposeArgs are added to every "docker compose up", e.g.
+	// ["--pull", "missing"]. The provider sections add their own
+	ComposeArgs []string `yaml:"compose_args,omitempty" json:"compose_args,omitempty"`
+
+	// Parallelism is how many 'space up' across all projects may pull,
+	// build and start containers at once; the others queue so the docker
+	// daemon isn't overloaded. 0 turns the limit off. Usually set in the
+	// global config. Default: 2
+	Parallelism int `yaml:"parallelism,omitempty" json:"parallelism,omitempty"`
 }
 
+// DefaultParallelism is the default of provider.parallelism
+const DefaultParallelism = 2
+
 // OrbStackConfig defines OrbStack-specific settings
 type OrbStackConfig struct {
 	// DNSSuffix for OrbStack containers (default: ".orb.local")
@@ -174,6 +407,13 @@ type OrbStackConfig struct {
 	// RemovePortBindings removes port bindings from compose file
 	// Default: true for OrbStack
 	RemovePortBindings bool `yaml:"remove_port_bindings,omitempty" json:"remove_port_bindings,omitempty"`
+
+	// Platform overrides provider.platform while OrbStack is the provider
+	Platform string `yaml:"platform,omitempty" json:"platform,omitempty"`
+
+	// ComposeArgs are added to "docker compose up" after provider.compose_args
+	// while OrbStack is the provider
+	ComposeArgs []string `yaml:"compose_args,omitempty" json:"compose_args,omitempty"`
 }
 
 // DockerConfig defines Docker Desktop specific settings
@@ -184,6 +424,14 @@ type DockerConfig struct {
 	// ComposeCommand to use: "docker compose" or "docker-compose"
 	// Default: auto-detect
 	ComposeCommand string `yaml:"compose_command,omitempty" json:"compose_command,omitempty"`
+
+	// Platform overrides provider.platform while Docker Desktop is the
+	// provider
+	Platform string `yaml:"platform,omitempty" json:"platform,omitempty"`
+
+	// ComposeArgs are added to "docker compose up" after provider.compose_args
+	// while Docker Desktop is the provider
+	ComposeArgs []string `yaml:"compose_args,omitempty" json:"compose_args,omitempty"`
 }
 
 // NetworkConfig defines networking settings
@@ -194,14 +442,118 @@ type NetworkConfig struct {
 	// NetworkMode: "bridge", "host", etc.
 	NetworkMode string `yaml:"network_mode,omitempty" json:"network_mode,omitempty"`
 
-	// CustomDomain for accessing services
-	CustomDomain string `yaml:"custom_domain,omitempty" json:"custom_domain,omitempty"`
+	// CustomDomain is the DNS base domain for accessing services
+	// Default: "space.local". A list serves every domain, with URLs using the
+	// first, so old names keep resolving while a domain is renamed.
+	CustomDomain Domains `yaml:"custom_domain,omitempty" json:"custom_domain,omitempty"`
 
 	// DNSHashing enables directory-based hashing for DNS names to prevent collisions
 	// Default: true (enabled)
 	DNSHashing bool `yaml:"dns_hashing,omitempty" json:"dns_hashing,omitempty"`
 }
 
+// DNS modes
+const (
+	// DNSModeDaemon resolves service hostnames with space-dns-daemon
+	DNSModeDaemon = "daemon"
+
+	// DNSModeHosts writes service hostnames to a block in /etc/hosts
+	DNSModeHosts = "hosts"
+)
+
+// DNSConfig defines DNS daemon settings
+type DNSConfig struct {
+	// Mode selects how service hostnames resolve: "daemon" or "hosts"
+	// Default: "daemon". Use "hosts" where /etc/resolver and systemd-resolved
+	// cannot be changed; records then only refresh on up, restart and down.
+	Mode string `yaml:"mode,omitempty" json:"mode,omitempty"`
+
+	// QueryLog logs every query with its answer and latency to the daemon log
+	QueryLog bool `yaml:"query_log,omitempty" json:"query_log,omitempty"`
+
+	// Records are static records served by the daemon, keyed by hostname
+	// (e.g. db.internal). A value is an IP address, or a service of this
+	// project the name becomes an alias (CNAME) of. Names outside the base
+	// domains need a resolver file; 'space setup' creates one per parent domain.
+	Records map[string]string `yaml:"records,omitempty" json:"records,omitempty"`
+}
+
+// AgentConfig defines what space agentd does for the project
+type AgentConfig struct {
+	// TTL stops the project this long after 'space up' (e.g. "8h"); 0 disables
+	TTL time.Duration `yaml:"ttl,omitempty" json:"ttl,omitempty"`
+
+	// Notify shows desktop notifications for crash loops and auto-shutdowns
+	// instead of only logging them. Read when the agent starts.
+	Notify bool `yaml:"notify,omitempty" json:"notify,omitempty"`
+}
+
+// OutputConfig defines how chatty commands are
+type OutputConfig struct {
+	// Tips prints "Tip:" hints after commands. Default: true. Set to false
+	// in ~/.config/space/config.yaml to turn them off everywhere; --quiet
+	// also drops banners and URL listings for a single command.
+	Tips bool `yaml:"tips,omitempty" json:"tips,omitempty"`
+
+	// Symbols draws status symbols as "emoji", single-width "unicode" or
+	// "ascii" for terminals and logs that render emoji poorly. Default:
+	// emoji, or ascii with TERM=dumb.
+	Symbols string `yaml:"symbols,omitempty" json:"symbols,omitempty"`
+
+	// Theme colors status lines on a terminal: "default", "bright" or
+	// "mono" for none. NO_COLOR and TERM=dumb also turn colors off.
+	Theme string `yaml:"theme,omitempty" json:"theme,omitempty"`
+}
+
+// Tracing backends of the observability preset
+const (
+	TracingJaeger = "jaeger"
+	TracingTempo  = "tempo"
+)
+
+// ObservabilityConfig adds an OpenTelemetry collector and a tracing backend
+// to the stack and points the application services at the collector
+type ObservabilityConfig struct {
+	// Enabled adds the otel-collector and tracing backend services on 'space up'
+	Enabled bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Backend stores and shows the traces: "jaeger" or "tempo" (with Grafana)
+	// Default: jaeger
+	Backend string `yaml:"backend,omitempty" json:"backend,omitempty"`
+
+	// Services receive OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_SERVICE_NAME.
+	// Default: every HTTP service.
+	Services []string `yaml:"services,omitempty" json:"services,omitempty"`
+}
+
+// TracingBackend returns the configured backend, defaulting to Jaeger
+func (o ObservabilityConfig) TracingBackend() string {
+	if o.Backend == "" {
+		return TracingJaeger
+	}
+	return o.Backend
+}
+
+// WorktreeConfig defines how 'space worktree add' prepares a new worktree
+type WorktreeConfig struct {
+	// Copy lists glob patterns, relative to the project directory, of local
+	// files git doesn't track that a worktree needs (e.g. ".env.local").
+	// Files already in the new worktree are not overwritten.
+	// Default: .env, .env.local, .env.*.local
+	Copy []string `yaml:"copy,omitempty" json:"copy,omitempty"`
+}
+
+// DefaultWorktreeCopy are the files copied into new worktrees by default
+var DefaultWorktreeCopy = []string{".env", ".env.local", ".env.*.local"}
+
+// CopyPatterns returns the configured patterns, or DefaultWorktreeCopy
+func (w WorktreeConfig) CopyPatterns() []string {
+	if len(w.Copy) == 0 {
+		return DefaultWorktreeCopy
+	}
+	return w.Copy
+}
+
 // PortsConfig defines port allocation settings
 type PortsConfig struct {
 	// RangeStart is the start of the dynamic port range
@@ -229,15 +581,126 @@ type HooksConfig struct {
 	// Database-specific hooks for database setup
 	Database *DatabaseHooksConfig `yaml:"database,omitempty" json:"database,omitempty"`
 
+	// Messaging creates Kafka topics or NATS streams after services start
+	Messaging *MessagingHooksConfig `yaml:"messaging,omitempty" json:"messaging,omitempty"`
+
+	// Storage creates S3 buckets and SQS queues on MinIO or LocalStack after
+	// services start
+	Storage *StorageHooksConfig `yaml:"storage,omitempty" json:"storage,omitempty"`
+
+	// Requires lists commands the project's hooks need on the host, such as
+	// jq or psql; space up and space doctor check them before hooks run
+	Requires []string `yaml:"requires,omitempty" json:"requires,omitempty"`
+
+	// StartupLogs collects what each service logged while starting into a
+	// file post-up hooks get, so they can react to warnings in it
+	StartupLogs *StartupLogsConfig `yaml:"startup_logs,omitempty" json:"startup_logs,omitempty"`
+
 	// Custom hooks for arbitrary commands
 	Custom []CustomHookConfig `yaml:"custom,omitempty" json:"custom,omitempty"`
 }
 
+// Startup log collection defaults
+const (
+	DefaultStartupLogsWindow   = 10 * time.Second
+	DefaultStartupLogsMaxLines = 1000
+)
+
+// StartupLogsConfig defines which logs count as a service's startup logs
+type StartupLogsConfig struct {
+	// Window is how long after a container starts its output counts as
+	// startup logs; post-up hooks wait for windows still open (default: 10s)
+	Window time.Duration `yaml:"window,omitempty" json:"window,omitempty"`
+
+	// MaxLines bounds the lines kept per container (default: 1000)
+	MaxLines int `yaml:"max_lines,omitempty" json:"max_lines,omitempty"`
+}
+
+// EffectiveWindow returns the startup window, or the default when unset
+func (s StartupLogsConfig) EffectiveWindow() time.Duration {
+	if s.Window <= 0 {
+		return DefaultStartupLogsWindow
+	}
+	return s.Window
+}
+
+// EffectiveMaxLines returns the line limit, or the default when unset
+func (s StartupLogsConfig) EffectiveMaxLines() int {
+	if s.MaxLines <= 0 {
+		return DefaultStartupLogsMaxLines
+	}
+	return s.MaxLines
+}
+
 // DatabaseHooksConfig defines database-specific hook settings
 // Reserved for future use - database hooks are implemented via external scripts
 type DatabaseHooksConfig struct {
 }
 
+// MessagingHooksConfig defines the topics or streams the messaging hook
+// creates once the broker accepts connections after 'space up'
+type MessagingHooksConfig struct {
+	// Service running the broker (default: the service with a kafka or nats
+	// image). Kafka-compatible brokers such as Redpanda are supported.
+	Service string `yaml:"service,omitempty" json:"service,omitempty"`
+
+	// Timeout waiting for the broker to become ready (default: 60s)
+	Timeout time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// Topics are Kafka topics, or JetStream streams for NATS
+	Topics []MessagingTopicConfig `yaml:"topics" json:"topics"`
+}
+
+// MessagingTopicConfig defines a Kafka topic or a NATS JetStream stream
+type MessagingTopicConfig struct {
+	// Name of the topic or stream
+	Name string `yaml:"name" json:"name"`
+
+	// Partitions of a Kafka topic (default: 1)
+	Partitions int `yaml:"partitions,omitempty" json:"partitions,omitempty"`
+
+	// Retention discards messages older than this (default: broker setting)
+	Retention time.Duration `yaml:"retention,omitempty" json:"retention,omitempty"`
+
+	// Subjects a NATS stream stores (default: the stream name)
+	Subjects []string `yaml:"subjects,omitempty" json:"subjects,omitempty"`
+}
+
+// StorageHooksConfig defines the buckets and queues the storage hook creates
+// once MinIO or LocalStack is ready after 'space up'
+type StorageHooksConfig struct {
+	// Service running MinIO or LocalStack (default: the service with a minio
+	// or localstack image)
+	Service string `yaml:"service,omitempty" json:"service,omitempty"`
+
+	// Timeout waiting for the service to become ready (default: 60s)
+	Timeout time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// AccessKey and SecretKey of the service (default: MINIO_ROOT_USER and
+	// MINIO_ROOT_PASSWORD from compose, or the image defaults)
+	AccessKey string `yaml:"access_key,omitempty" json:"access_key,omitempty"`
+	SecretKey string `yaml:"secret_key,omitempty" json:"secret_key,omitempty"`
+
+	// Region exported to applications (default: us-east-1)
+	Region string `yaml:"region,omitempty" json:"region,omitempty"`
+
+	// Buckets to create
+	Buckets []string `yaml:"buckets,omitempty" json:"buckets,omitempty"`
+
+	// Queues are SQS queues to create (LocalStack only)
+	Queues []string `yaml:"queues,omitempty" json:"queues,omitempty"`
+
+	// CORSOrigins are allowed in addition to the project's http service URLs
+	CORSOrigins []string `yaml:"cors_origins,omitempty" json:"cors_origins,omitempty"`
+
+	// NoCORS leaves the CORS policy of the service alone
+	NoCORS bool `yaml:"no_cors,omitempty" json:"no_cors,omitempty"`
+
+	// EnvFile receives the endpoint and credential variables
+	// (default: .space/state/storage.env)
+	EnvFile string `yaml:"env_file,omitempty" json:"env_file,omitempty"`
+}
+
 // ViteHooksConfig defines Vite-specific hook settings
 type ViteHooksConfig struct {
 	// Enabled enables Vite hooks
@@ -351,11 +814,12 @@ type OrbStackVMConfig struct {
 func Defaults() *Config {
 	return &Config{
 		Project: ProjectConfig{
-			NamingStrategy: "git-branch",
+			NamingStrategy: NamingGitBranch,
 			ComposeFiles:   []string{"docker-compose.yml"},
 		},
 		Provider: ProviderConfig{
-			Type: "auto",
+			Type:        "auto",
+			Parallelism: DefaultParallelism,
 			OrbStack: &OrbStackConfig{
 				DNSSuffix:          ".orb.local",
 				UseContainerDNS:    false,
@@ -367,6 +831,9 @@ func Defaults() *Config {
 			NetworkMode:  "bridge",
 			DNSHashing:   true, // Enable hashing by default
 		},
+		Output: OutputConfig{
+			Tips: true,
+		},
 		Ports: PortsConfig{
 			RangeStart:      10000,
 			RangeEnd:        60000,
@@ -388,154 +855,84 @@ func Defaults() *Config {
 	}
 }
 
-// Merge merges another config into this one (other takes precedence)
-func (c *Config) Merge(other *Config) *Config {
-	if other == nil {
-		return c
-	}
-
-	merged := *c
-
-	// Merge project config
-	if other.Project.Name != "" {
-		merged.Project.Name = other.Project.Name
-	}
-	if other.Project.Prefix != "" {
-		merged.Project.Prefix = other.Project.Prefix
-	}
-	if other.Project.NamingStrategy != "" {
-		merged.Project.NamingStrategy = other.Project.NamingStrategy
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	switch c.Observability.TracingBackend() {
+	case TracingJaeger, TracingTempo:
+	default:
+		return fmt.Errorf("observability.backend %q must be %q or %q", c.Observability.Backend, TracingJaeger, TracingTempo)
 	}
-	if len(other.Project.ComposeFiles) > 0 {
-		merged.Project.ComposeFiles = other.Project.ComposeFiles
+	if err := c.DNS.validateRecords(); err != nil {
+		return err
 	}
-	if other.Project.WorkDir != "" {
-		merged.Project.WorkDir = other.Project.WorkDir
+	if c.Provider.Parallelism < 0 {
+		return fmt.Errorf("provider.parallelism %d must not be negative", c.Provider.Parallelism)
 	}
-
-	// Merge services (deep merge)
-	if len(other.Services) > 0 {
-		if merged.Services == nil {
-			merged.Services = make(map[string]ServiceConfig)
-		}
-		for k, v := range other.Services {
-			merged.Services[k] = v
+	for i, strategy := range c.Project.NamingFallback {
+		switch strategy {
+		case NamingGitBranch, NamingDirectory, NamingStatic:
+		default:
+			return fmt.Errorf("project.naming_fallback[%d] %q must be %q, %q or %q", i, strategy, NamingGitBranch, NamingDirectory, NamingStatic)
 		}
 	}
-
-	// Merge databases
-	if len(other.Databases) > 0 {
-		merged.Databases = other.Databases
-	}
-
-	// Merge commands
-	if other.Commands.Seed != "" {
-		merged.Commands.Seed = other.Commands.Seed
-	}
-	if other.Commands.Migrate != "" {
-		merged.Commands.Migrate = other.Commands.Migrate
-	}
-	if len(other.Commands.Custom) > 0 {
-		if merged.Commands.Custom == nil {
-			merged.Commands.Custom = make(map[string]string)
-		}
-		for k, v := range other.Commands.Custom {
-			merged.Commands.Custom[k] = v
+	for name, cmd := range c.Commands.Custom {
+		if strings.TrimSpace(cmd.Command) == "" {
+			return fmt.Errorf("commands.custom.%s.command is required", name)
 		}
 	}
-
-	// Merge provider config
-	if other.Provider.Type != "" {
-		merged.Provider.Type = other.Provider.Type
-	}
-
-	// Merge network config
-	if other.Network.AllowedHosts != "" {
-		merged.Network.AllowedHosts = other.Network.AllowedHosts
-	}
-	if other.Network.NetworkMode != "" {
-		merged.Network.NetworkMode = other.Network.NetworkMode
-	}
-
-	// Merge ports config
-	if other.Ports.RangeStart > 0 {
-		merged.Ports.RangeStart = other.Ports.RangeStart
-	}
-	if other.Ports.RangeEnd > 0 {
-		merged.Ports.RangeEnd = other.Ports.RangeEnd
-	}
-	if other.Ports.PersistenceFile != "" {
-		merged.Ports.PersistenceFile = other.Ports.PersistenceFile
-	}
-	if other.Ports.Strategy != "" {
-		merged.Ports.Strategy = other.Ports.Strategy
-	}
-
-	// Merge VM config
-	if other.VM.Enabled {
-		merged.VM.Enabled = other.VM.Enabled
-	}
-	if other.VM.Provider != "" {
-		merged.VM.Provider = other.VM.Provider
-	}
-	if other.VM.CPUs > 0 {
-		merged.VM.CPUs = other.VM.CPUs
-	}
-	if other.VM.Memory != "" {
-		merged.VM.Memory = other.VM.Memory
-	}
-	if other.VM.Disk != "" {
-		merged.VM.Disk = other.VM.Disk
-	}
-	if other.VM.MountType != "" {
-		merged.VM.MountType = other.VM.MountType
+	platforms := map[string]string{
+		"provider.platform":          c.Provider.Platform,
+		"provider.orbstack.platform": c.Provider.PlatformFor(ProviderOrbStack),
+		"provider.docker.platform":   c.Provider.PlatformFor(ProviderDocker),
 	}
-	if len(other.VM.Dependencies) > 0 {
-		merged.VM.Dependencies = other.VM.Dependencies
+	for name, svc := range c.Services {
+		platforms["services."+name+".platform"] = svc.Platform
 	}
-	if len(other.VM.StartupCommands) > 0 {
-		merged.VM.StartupCommands = other.VM.StartupCommands
+	for field, platform := range platforms {
+		if platform != "" && !validPlatform(platform) {
+			return fmt.Errorf("%s %q must be os/arch, e.g. linux/amd64", field, platform)
+		}
 	}
-
-	// Merge hooks config
-	if other.Hooks.Vite != nil {
-		if merged.Hooks.Vite == nil {
-			merged.Hooks.Vite = &ViteHooksConfig{}
+	for i, db := range c.Databases {
+		if !db.Shared {
+			continue
 		}
-		if other.Hooks.Vite.Enabled {
-			merged.Hooks.Vite.Enabled = other.Hooks.Vite.Enabled
+		if db.Service == "" {
+			return fmt.Errorf("databases[%d].service is required for a shared database", i)
 		}
-		if other.Hooks.Vite.AutoDetect {
-			merged.Hooks.Vite.AutoDetect = other.Hooks.Vite.AutoDetect
+		if db.Type != "" && db.Type != "postgres" {
+			return fmt.Errorf("databases[%d]: only postgres databases can be shared, not %s", i, db.Type)
 		}
-		if other.Hooks.Vite.AllowedHostsPattern != "" {
-			merged.Hooks.Vite.AllowedHostsPattern = other.Hooks.Vite.AllowedHostsPattern
+	}
+	for name, svc := range c.Services {
+		if svc.Tunnel == nil {
+			continue
 		}
-		if len(other.Hooks.Vite.EnvVars) > 0 {
-			if merged.Hooks.Vite.EnvVars == nil {
-				merged.Hooks.Vite.EnvVars = make(map[string]string)
-			}
-			for k, v := range other.Hooks.Vite.EnvVars {
-				merged.Hooks.Vite.EnvVars[k] = v
-			}
+		switch svc.Tunnel.Provider {
+		case "", TunnelCloudflared, TunnelNgrok:
+		default:
+			return fmt.Errorf("services.%s.tunnel.provider %q must be %q or %q", name, svc.Tunnel.Provider, TunnelCloudflared, TunnelNgrok)
 		}
 	}
-	if len(other.Hooks.Custom) > 0 {
-		merged.Hooks.Custom = other.Hooks.Custom
-	}
-
-	// Merge database hooks config (reserved for future use)
-	if other.Hooks.Database != nil {
-		if merged.Hooks.Database == nil {
-			merged.Hooks.Database = &DatabaseHooksConfig{}
+	for name, svc := range c.Services {
+		for _, network := range svc.SharedNetworks {
+			if !validNetworkName(network) {
+				return fmt.Errorf("services.%s.shared_networks: %q must be lowercase letters, digits, '-' and '_'", name, network)
+			}
 		}
 	}
-
-	return &merged
+	return c.validateGroups()
 }
 
-// Validate validates the configuration
-func (c *Config) Validate() error {
-	// Validation logic will be in validator.go
-	return nil
+// validNetworkName reports whether name can name a shared network
+func validNetworkName(name string) bool {
+	if name == "" || name[0] == '-' || name[0] == '_' {
+		return false
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
 }
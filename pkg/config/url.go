@@ -0,0 +1,159 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Service protocols
+const (
+	ProtocolHTTP = "http"
+	ProtocolTCP  = "tcp"
+	ProtocolGRPC = "grpc"
+)
+
+// DefaultURLTemplate is used when an http service does not define url_template
+const DefaultURLTemplate = "http://{host}:{port}"
+
+// URLVars holds the values substituted into a service URL template
+type URLVars struct {
+	// Host is the hostname used to reach the service (DNS name or localhost)
+	Host string
+
+	// Port is the port used to reach the service from the host
+	// (internal port in DNS mode, external port with port bindings)
+	Port int
+
+	// Service is the service name
+	Service string
+
+	// Project is the Docker Compose project name
+	Project string
+
+	// InternalPort is the port the service listens on inside the container
+	InternalPort int
+
+	// ExternalPort is the mapped host port (0 if not mapped)
+	ExternalPort int
+}
+
+// EffectiveProtocol returns the service protocol, defaulting to http
+func (s ServiceConfig) EffectiveProtocol() string {
+	switch strings.ToLower(s.Protocol) {
+	case ProtocolTCP:
+		return ProtocolTCP
+	case ProtocolGRPC:
+		return ProtocolGRPC
+	default:
+		return ProtocolHTTP
+	}
+}
+
+// defaultURLTemplate returns the template used when url_template is not set
+func (s ServiceConfig) defaultURLTemplate() string {
+	switch s.EffectiveProtocol() {
+	case ProtocolTCP:
+		scheme := s.Scheme
+		if scheme == "" {
+			scheme = ProtocolTCP
+		}
+		return scheme + "://{host}:{port}"
+	case ProtocolGRPC:
+		return "grpc://{host}:{port}"
+	default:
+		return DefaultURLTemplate
+	}
+}
+
+// RenderURL renders the service URL (or connection string) from its url_template.
+// Supported variables: {host}, {port}, {service}, {project},
+// {internal_port} and {external_port}.
+func (s ServiceConfig) RenderURL(vars URLVars) string {
+	tmpl := s.URLTemplate
+	if tmpl == "" {
+		tmpl = s.defaultURLTemplate()
+	}
+
+	externalPort := vars.ExternalPort
+	if externalPort == 0 {
+		externalPort = vars.Port
+	}
+
+	replacer := strings.NewReplacer(
+		"{host}", vars.Host,
+		"{port}", strconv.Itoa(vars.Port),
+		"{service}", vars.Service,
+		"{project}", vars.Project,
+		"{internal_port}", strconv.Itoa(vars.InternalPort),
+		"{external_port}", strconv.Itoa(externalPort),
+	)
+
+	return replacer.Replace(tmpl)
+}
+
+// tcpSchemes maps well-known image names to connection string schemes
+var tcpSchemes = []struct {
+	keyword string
+	scheme  string
+}{
+	{"postgis", "postgres"},
+	{"postgres", "postgres"},
+	{"timescale", "postgres"},
+	{"mariadb", "mysql"},
+	{"mysql", "mysql"},
+	{"redis", "redis"},
+	{"valkey", "redis"},
+	{"keydb", "redis"},
+	{"mongo", "mongodb"},
+	{"rabbitmq", "amqp"},
+	{"nats", "nats"},
+	{"memcached", "memcached"},
+	{"kafka", "kafka"},
+	{"redpanda", "kafka"},
+}
+
+// DetectTCPScheme returns the connection string scheme for a well-known
+// TCP service image (e.g., "postgres:16-alpine" -> "postgres"), or "" if unknown
+func DetectTCPScheme(image string) string {
+	name := strings.ToLower(image)
+	// Strip registry/namespace and tag
+	if idx := strings.LastIndex(name, "/"); idx != -1 {
+		name = name[idx+1:]
+	}
+	if idx := strings.Index(name, ":"); idx != -1 {
+		name = name[:idx]
+	}
+
+	for _, s := range tcpSchemes {
+		if strings.Contains(name, s.keyword) {
+			return s.scheme
+		}
+	}
+	return ""
+}
+
+// DatabaseFor returns the database provided by a service, if any
+func (c *Config) DatabaseFor(service string) (DatabaseConfig, bool) {
+	for _, db := range c.Databases {
+		if db.Service == service {
+			return db, true
+		}
+	}
+	return DatabaseConfig{}, false
+}
+
+// ExpandVars substitutes the database variables {db_name}, {db_user},
+// {db_password}, {db_host} and {db_port} in a command template
+func (d DatabaseConfig) ExpandVars(tmpl string) string {
+	port := ""
+	if d.Port > 0 {
+		port = strconv.Itoa(d.Port)
+	}
+	return strings.NewReplacer(
+		"{db_name}", d.Name,
+		"{db_user}", d.User,
+		"{db_password}", d.Password,
+		"{db_host}", d.Host,
+		"{db_port}", port,
+	).Replace(tmpl)
+}
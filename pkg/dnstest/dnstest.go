@@ -0,0 +1,223 @@
+// Package dnstest runs the space DNS server against an in-memory set of
+// containers, so resolution can be tested without Docker.
+//
+// Records map container names to addresses. Hashed names use the
+// service-hash form the server extracts from hostnames like
+// api-a1b2c3.space.local; anything else is looked up by its first label:
+//
+//	srv := dnstest.StartTestServer(t, dnstest.Config{
+//		Records: map[string]string{"api-a1b2c3": "172.18.0.4"},
+//	})
+//	ips, err := srv.Lookup(ctx, "api-a1b2c3.space.local")
+package dnstest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/happy-sdk/space-cli/internal/dns"
+	mdns "github.com/miekg/dns"
+)
+
+// Domain is the base domain served when Config.Domain is empty
+const Domain = "space.local"
+
+// MockDockerClient answers the DNS server's container lookups from a map.
+// It is safe for concurrent use, so records can change while the server runs.
+type MockDockerClient struct {
+	mu      sync.RWMutex
+	records map[string]string
+	lookups int
+}
+
+// NewMockDockerClient returns a client serving a copy of records
+func NewMockDockerClient(records map[string]string) *MockDockerClient {
+	m := &MockDockerClient{records: make(map[string]string, len(records))}
+	for name, ip := range records {
+		m.records[name] = ip
+	}
+	return m
+}
+
+// Set adds or replaces the address of a container
+func (m *MockDockerClient) Set(name, ip string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[name] = ip
+}
+
+// Remove forgets a container, as if it stopped
+func (m *MockDockerClient) Remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, name)
+}
+
+// Lookups returns how many container lookups reached the client, which
+// tells cached answers apart from fresh ones
+func (m *MockDockerClient) Lookups() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lookups
+}
+
+// GetContainerIP returns the address of containerName in any project
+func (m *MockDockerClient) GetContainerIP(_ context.Context, _, containerName string) (string, error) {
+	return m.lookup(containerName)
+}
+
+// GetContainerIPByHash returns the address recorded as serviceName-hash
+func (m *MockDockerClient) GetContainerIPByHash(_ context.Context, serviceName, hash string) (string, error) {
+	return m.lookup(serviceName + "-" + hash)
+}
+
+// ListProjectContainers returns every recorded container
+func (m *MockDockerClient) ListProjectContainers(context.Context, string) (map[string]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	containers := make(map[string]string, len(m.records))
+	for name, ip := range m.records {
+		containers[name] = ip
+	}
+	return containers, nil
+}
+
+func (m *MockDockerClient) lookup(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups++
+	if ip, ok := m.records[name]; ok {
+		return ip, nil
+	}
+	return "", fmt.Errorf("container not found: %s", name)
+}
+
+// Config configures a test server
+type Config struct {
+	Records  map[string]string // Container name to address
+	Domain   string            // Base domain (default: space.local)
+	Domains  []string          // Additional domains answered like Domain
+	Upstream string            // Upstream for other domains (default: the server's)
+	CacheTTL time.Duration     // Answer cache TTL (default: the server's)
+}
+
+// Server is a DNS server listening on an ephemeral loopback port
+type Server struct {
+	// Docker serves the records; change them with Set and Remove
+	Docker *MockDockerClient
+
+	t      testing.TB
+	server *dns.Server
+	addr   string
+}
+
+// StartTestServer starts a DNS server answering from cfg.Records. It is
+// stopped when the test ends.
+func StartTestServer(t testing.TB, cfg Config) *Server {
+	t.Helper()
+
+	if cfg.Domain == "" {
+		cfg.Domain = Domain
+	}
+	docker := NewMockDockerClient(cfg.Records)
+
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		addr, err := freeUDPAddr()
+		if err != nil {
+			t.Fatalf("dnstest: no free port: %v", err)
+		}
+
+		server, err := dns.NewServer(dns.Config{
+			Addr:     addr,
+			Upstream: cfg.Upstream,
+			Domain:   cfg.Domain,
+			Domains:  cfg.Domains,
+			CacheTTL: cfg.CacheTTL,
+			Docker:   docker,
+			Logger:   testLogger{t: t},
+		})
+		if err != nil {
+			t.Fatalf("dnstest: failed to create server: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = server.Start(ctx)
+		cancel()
+		if err != nil {
+			// Another process grabbed the port in between
+			lastErr = err
+			continue
+		}
+
+		s := &Server{Docker: docker, t: t, server: server, addr: addr}
+		t.Cleanup(s.Close)
+		return s
+	}
+
+	t.Fatalf("dnstest: failed to start server: %v", lastErr)
+	return nil
+}
+
+// Addr returns the address the server listens on
+func (s *Server) Addr() string {
+	return s.addr
+}
+
+// Close stops the server; calling it more than once is safe
+func (s *Server) Close() {
+	if err := s.server.Stop(); err != nil {
+		s.t.Logf("dnstest: failed to stop server: %v", err)
+	}
+}
+
+// Lookup queries the server for the A records of hostname
+func (s *Server) Lookup(ctx context.Context, hostname string) ([]string, error) {
+	m := new(mdns.Msg)
+	m.SetQuestion(mdns.Fqdn(hostname), mdns.TypeA)
+
+	c := &mdns.Client{Timeout: 2 * time.Second}
+	resp, _, err := c.ExchangeContext(ctx, m, s.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var ips []string
+	for _, rr := range resp.Answer {
+		if a, ok := rr.(*mdns.A); ok {
+			ips = append(ips, a.A.String())
+		}
+	}
+	return ips, nil
+}
+
+// freeUDPAddr returns a loopback address with a currently unused UDP port
+func freeUDPAddr() (string, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().String(), nil
+}
+
+// testLogger sends server logs to the test log
+type testLogger struct {
+	t testing.TB
+}
+
+func (l testLogger) Info(msg string, fields ...interface{})  { l.log("INFO", msg, fields) }
+func (l testLogger) Warn(msg string, fields ...interface{})  { l.log("WARN", msg, fields) }
+func (l testLogger) Error(msg string, fields ...interface{}) { l.log("ERROR", msg, fields) }
+func (l testLogger) Debug(msg string, fields ...interface{}) {}
+
+func (l testLogger) log(level, msg string, fields []interface{}) {
+	if len(fields) > 0 {
+		msg += fmt.Sprintf(" %v", fields)
+	}
+	l.t.Logf("[dns %s] %s", level, msg)
+}
@@ -0,0 +1,76 @@
+package dnstest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStartTestServer(t *testing.T) {
+	srv := StartTestServer(t, Config{
+		Records: map[string]string{
+			"api-a1b2c3": "172.18.0.4",
+			"db":         "172.18.0.5",
+		},
+		Domains:  []string{"shop.test"},
+		CacheTTL: time.Minute,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lookup := func(hostname string) []string {
+		t.Helper()
+		ips, err := srv.Lookup(ctx, hostname)
+		if err != nil {
+			t.Fatalf("Lookup(%s): %v", hostname, err)
+		}
+		return ips
+	}
+
+	for hostname, want := range map[string]string{
+		"api-a1b2c3.space.local": "172.18.0.4",
+		"api-a1b2c3.shop.test":   "172.18.0.4",
+		"db.space.local":         "172.18.0.5",
+	} {
+		if got := lookup(hostname); !reflect.DeepEqual(got, []string{want}) {
+			t.Errorf("Lookup(%s) = %v, want [%s]", hostname, got, want)
+		}
+	}
+
+	if got := lookup("web-a1b2c3.space.local"); len(got) != 0 {
+		t.Errorf("unknown service resolved to %v", got)
+	}
+
+	// Answers are cached, so a repeated query never reaches the client
+	before := srv.Docker.Lookups()
+	lookup("db.space.local")
+	if srv.Docker.Lookups() != before {
+		t.Errorf("cached answer reached the docker client")
+	}
+
+	srv.Docker.Set("web-a1b2c3", "172.18.0.6")
+	if got := lookup("web-a1b2c3.space.local"); !reflect.DeepEqual(got, []string{"172.18.0.6"}) {
+		t.Errorf("added record resolved to %v", got)
+	}
+}
+
+func TestMockDockerClient(t *testing.T) {
+	records := map[string]string{"api": "172.18.0.4"}
+	docker := NewMockDockerClient(records)
+	records["api"] = "changed"
+
+	ctx := context.Background()
+	if ip, err := docker.GetContainerIP(ctx, "shop", "api"); err != nil || ip != "172.18.0.4" {
+		t.Errorf("GetContainerIP() = %q, %v", ip, err)
+	}
+
+	docker.Remove("api")
+	if _, err := docker.GetContainerIP(ctx, "shop", "api"); err == nil {
+		t.Error("expected removed container to fail")
+	}
+	if docker.Lookups() != 2 {
+		t.Errorf("Lookups() = %d, want 2", docker.Lookups())
+	}
+}
@@ -0,0 +1,65 @@
+// Package hooks is the public API for Go-native space hooks.
+//
+// A project can define hooks in .space/hooks.go instead of shell scripts.
+// The file is interpreted by space at run time and must declare a Hooks
+// function returning the hooks to register:
+//
+//	package space
+//
+//	import (
+//		"context"
+//		"fmt"
+//
+//		"github.com/happy-sdk/space-cli/pkg/hooks"
+//	)
+//
+//	type printURLs struct{}
+//
+//	func (printURLs) Name() string               { return "print-urls" }
+//	func (printURLs) Description() string        { return "Prints service URLs" }
+//	func (printURLs) Events() []hooks.EventType { return []hooks.EventType{hooks.PostUp} }
+//
+//	func (printURLs) Execute(ctx context.Context, event hooks.EventType, hookCtx *hooks.HookContext) error {
+//		for name, svc := range hookCtx.Services {
+//			fmt.Println(name, svc.URL)
+//		}
+//		return nil
+//	}
+//
+//	func Hooks() []hooks.Hook {
+//		return []hooks.Hook{printURLs{}}
+//	}
+//
+// Because the file imports this package, it type-checks with the regular Go
+// tooling (go vet, gopls) when the project's go.mod requires space-cli.
+package hooks
+
+import "github.com/happy-sdk/space-cli/internal/hooks"
+
+// Hook is implemented by Go-native hooks
+type Hook = hooks.Hook
+
+// HookContext is the context passed to hooks
+type HookContext = hooks.HookContext
+
+// ServiceInfo describes a service in the hook context
+type ServiceInfo = hooks.ServiceInfo
+
+// EnvChange describes an environment variable change
+type EnvChange = hooks.EnvChange
+
+// EventType identifies a lifecycle event
+type EventType = hooks.EventType
+
+// Lifecycle events
+const (
+	PreUp            = hooks.PreUp
+	PostUp           = hooks.PostUp
+	PreDown          = hooks.PreDown
+	PostDown         = hooks.PostDown
+	OnDNSReady       = hooks.OnDNSReady
+	OnEnvChange      = hooks.OnEnvChange
+	OnServiceStart   = hooks.OnServiceStart
+	OnServiceStop    = hooks.OnServiceStop
+	OnServiceHealthy = hooks.OnServiceHealthy
+)
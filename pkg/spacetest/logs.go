@@ -0,0 +1,169 @@
+// Package spacetest helps integration tests of projects run with space
+// report what their containers did.
+//
+// Register DumpLogsOnFailure once the environment is up and the compose logs
+// and docker inspect output of every service are attached to the test when
+// it fails:
+//
+//	project, err := spacetest.Discover(ctx, workDir)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	project.DumpLogsOnFailure(t)
+package spacetest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// DefaultTail is the number of log lines collected per service
+const DefaultTail = 200
+
+// collectTimeout bounds collecting logs in DumpAllLogs
+const collectTimeout = 30 * time.Second
+
+// run executes a command in dir and returns its combined output
+var run = func(ctx context.Context, dir, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return out.String(), err
+}
+
+// Project is a running docker compose project
+type Project struct {
+	// Name is the compose project name
+	Name string
+
+	// WorkDir is the project directory
+	WorkDir string
+
+	// Tail limits the log lines per service (DefaultTail if zero)
+	Tail int
+}
+
+// Discover finds the compose project started from workDir
+func Discover(ctx context.Context, workDir string) (Project, error) {
+	out, err := run(ctx, workDir, "docker", "ps", "-a",
+		"--filter", "label=com.docker.compose.project.working_dir="+workDir,
+		"--format", `{{.Label "com.docker.compose.project"}}`)
+	if err != nil {
+		return Project{}, fmt.Errorf("failed to list containers: %w: %s", err, strings.TrimSpace(out))
+	}
+
+	for _, name := range strings.Fields(out) {
+		return Project{Name: name, WorkDir: workDir}, nil
+	}
+	return Project{}, fmt.Errorf("no compose project running from %s", workDir)
+}
+
+// Services lists the services with containers, running or not
+func (p Project) Services(ctx context.Context) ([]string, error) {
+	out, err := run(ctx, p.WorkDir, "docker", "ps", "-a",
+		"--filter", "label=com.docker.compose.project="+p.Name,
+		"--format", `{{.Label "com.docker.compose.service"}}`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w: %s", err, strings.TrimSpace(out))
+	}
+
+	seen := make(map[string]bool)
+	var services []string
+	for _, name := range strings.Fields(out) {
+		if !seen[name] {
+			seen[name] = true
+			services = append(services, name)
+		}
+	}
+	sort.Strings(services)
+	return services, nil
+}
+
+// CollectLogs returns the recent compose logs of a service followed by the
+// docker inspect output of its containers
+func (p Project) CollectLogs(ctx context.Context, service string) (string, error) {
+	tail := p.Tail
+	if tail <= 0 {
+		tail = DefaultTail
+	}
+
+	var b strings.Builder
+	logs, err := run(ctx, p.WorkDir, "docker", "compose", "-p", p.Name,
+		"logs", "--no-color", "--timestamps", "--tail", fmt.Sprint(tail), service)
+	if err != nil {
+		return "", fmt.Errorf("failed to get logs of %s: %w: %s", service, err, strings.TrimSpace(logs))
+	}
+	fmt.Fprintf(&b, "--- logs (last %d lines) ---\n%s", tail, logs)
+
+	ids, err := run(ctx, p.WorkDir, "docker", "ps", "-aq",
+		"--filter", "label=com.docker.compose.project="+p.Name,
+		"--filter", "label=com.docker.compose.service="+service)
+	if err != nil {
+		return b.String(), fmt.Errorf("failed to find containers of %s: %w", service, err)
+	}
+	if containers := strings.Fields(ids); len(containers) > 0 {
+		inspect, err := run(ctx, p.WorkDir, "docker", append([]string{"inspect"}, containers...)...)
+		if err != nil {
+			return b.String(), fmt.Errorf("failed to inspect %s: %w", service, err)
+		}
+		fmt.Fprintf(&b, "--- docker inspect ---\n%s", inspect)
+	}
+
+	return b.String(), nil
+}
+
+// DumpAllLogs collects the logs of every service in parallel and writes them
+// to the test log, one block per service
+func (p Project) DumpAllLogs(t testing.TB) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	services, err := p.Services(ctx)
+	if err != nil {
+		t.Logf("spacetest: %v", err)
+		return
+	}
+
+	logs := make([]string, len(services))
+	var wg sync.WaitGroup
+	for i, service := range services {
+		wg.Add(1)
+		go func(i int, service string) {
+			defer wg.Done()
+			out, err := p.CollectLogs(ctx, service)
+			if err != nil {
+				out += fmt.Sprintf("spacetest: %v\n", err)
+			}
+			logs[i] = out
+		}(i, service)
+	}
+	wg.Wait()
+
+	for i, service := range services {
+		t.Logf("===== %s/%s =====\n%s", p.Name, service, logs[i])
+	}
+}
+
+// DumpLogsOnFailure dumps all logs when the test has failed by the time it
+// finishes. Cleanups run in reverse order, so register it after the cleanup
+// that stops the project to capture the logs before the containers go away.
+func (p Project) DumpLogsOnFailure(t testing.TB) {
+	t.Helper()
+	t.Cleanup(func() {
+		if t.Failed() {
+			p.DumpAllLogs(t)
+		}
+	})
+}
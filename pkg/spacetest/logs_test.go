@@ -0,0 +1,68 @@
+package spacetest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeDocker answers the docker commands used by the package
+func fakeDocker(t *testing.T) {
+	t.Helper()
+	orig := run
+	t.Cleanup(func() { run = orig })
+
+	run = func(_ context.Context, _, name string, args ...string) (string, error) {
+		cmd := name + " " + strings.Join(args, " ")
+		switch {
+		case strings.Contains(cmd, "working_dir=/src/shop"):
+			return "shop-main\nshop-main\n", nil
+		case strings.Contains(cmd, `compose.service"}}`):
+			return "web\napi\nweb\n", nil
+		case strings.Contains(cmd, " logs "):
+			return args[len(args)-1] + " | started\n", nil
+		case strings.HasPrefix(cmd, "docker ps -aq"):
+			if strings.Contains(cmd, "service=api") {
+				return "c1\n", nil
+			}
+			return "", nil
+		case strings.HasPrefix(cmd, "docker inspect c1"):
+			return `[{"Id": "c1"}]` + "\n", nil
+		}
+		return "", fmt.Errorf("unexpected command %q", cmd)
+	}
+}
+
+func TestDiscoverAndCollect(t *testing.T) {
+	fakeDocker(t)
+	ctx := context.Background()
+
+	project, err := Discover(ctx, "/src/shop")
+	if err != nil || project.Name != "shop-main" {
+		t.Fatalf("Discover() = %+v, %v", project, err)
+	}
+	if _, err := Discover(ctx, "/src/other"); err == nil {
+		t.Error("expected Discover to fail without containers")
+	}
+
+	services, err := project.Services(ctx)
+	if err != nil || strings.Join(services, ",") != "api,web" {
+		t.Errorf("Services() = %v, %v", services, err)
+	}
+
+	logs, err := project.CollectLogs(ctx, "api")
+	if err != nil {
+		t.Fatalf("CollectLogs() error = %v", err)
+	}
+	for _, want := range []string{"last 200 lines", "api | started", "--- docker inspect ---", `"Id": "c1"`} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("CollectLogs() missing %q:\n%s", want, logs)
+		}
+	}
+
+	logs, _ = project.CollectLogs(ctx, "web")
+	if strings.Contains(logs, "docker inspect") {
+		t.Errorf("expected no inspect section without containers:\n%s", logs)
+	}
+}
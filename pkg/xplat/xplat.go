@@ -0,0 +1,198 @@
+// Package xplat opens URLs, copies to the clipboard and shows desktop
+// notifications on macOS, Linux and WSL, so commands don't each pick the
+// right tool for the platform.
+//
+//	if err := xplat.Open(url); err != nil {
+//		fmt.Printf("Open %s in your browser\n", url)
+//	}
+package xplat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Platform identifies the desktop environment tools are chosen for
+type Platform string
+
+// Platforms
+const (
+	MacOS   Platform = "darwin"
+	Linux   Platform = "linux"
+	WSL     Platform = "wsl"
+	Windows Platform = "windows"
+)
+
+// ErrUnsupported is returned when no tool for an action is available
+var ErrUnsupported = errors.New("not supported on this platform")
+
+// timeout bounds clipboard and notification commands; the Windows balloon
+// tip stays up for five seconds
+const timeout = 10 * time.Second
+
+// lookPath finds executables; replaced in tests
+var lookPath = exec.LookPath
+
+// Detect returns the platform space runs on. Linux under WSL is reported
+// separately since the desktop belongs to Windows.
+func Detect() Platform {
+	switch runtime.GOOS {
+	case "darwin":
+		return MacOS
+	case "windows":
+		return Windows
+	}
+
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return WSL
+	}
+	if release, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil && strings.Contains(strings.ToLower(string(release)), "microsoft") {
+		return WSL
+	}
+	return Linux
+}
+
+// command is a candidate tool invocation
+type command struct {
+	name string
+	args []string
+}
+
+// Open opens url in the default browser without waiting for it
+func Open(url string) error {
+	cmd, err := pick(openCommands(Detect(), url))
+	if err != nil {
+		return fmt.Errorf("open %s: %w", url, err)
+	}
+	return exec.Command(cmd.name, cmd.args...).Start()
+}
+
+// CopyToClipboard puts text on the system clipboard
+func CopyToClipboard(text string) error {
+	cmd, err := pick(clipboardCommands(Detect(), os.Getenv("WAYLAND_DISPLAY") != ""))
+	if err != nil {
+		return fmt.Errorf("copy to clipboard: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	c := exec.CommandContext(ctx, cmd.name, cmd.args...)
+	c.Stdin = strings.NewReader(text)
+	if output, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// Notify shows a desktop notification
+func Notify(title, body string) error {
+	cmd, err := pick(notifyCommands(Detect(), title, body))
+	if err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if output, err := exec.CommandContext(ctx, cmd.name, cmd.args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd.name, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// pick returns the first candidate whose tool is installed
+func pick(candidates []command) (command, error) {
+	if len(candidates) == 0 {
+		return command{}, ErrUnsupported
+	}
+
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		if _, err := lookPath(c.name); err == nil {
+			return c, nil
+		}
+		names[i] = c.name
+	}
+	return command{}, fmt.Errorf("%w: install one of %s", ErrUnsupported, strings.Join(names, ", "))
+}
+
+// openCommands lists the tools that open a URL, in order of preference
+func openCommands(p Platform, url string) []command {
+	switch p {
+	case MacOS:
+		return []command{{"open", []string{url}}}
+	case WSL:
+		return []command{
+			{"wslview", []string{url}},
+			{"rundll32.exe", []string{"url.dll,FileProtocolHandler", url}},
+		}
+	case Windows:
+		return []command{{"rundll32", []string{"url.dll,FileProtocolHandler", url}}}
+	default:
+		return []command{{"xdg-open", []string{url}}}
+	}
+}
+
+// clipboardCommands lists the tools that read the clipboard content from
+// stdin, in order of preference
+func clipboardCommands(p Platform, wayland bool) []command {
+	switch p {
+	case MacOS:
+		return []command{{"pbcopy", nil}}
+	case WSL:
+		return []command{{"clip.exe", nil}}
+	case Windows:
+		return []command{{"clip", nil}}
+	}
+
+	x11 := []command{
+		{"xclip", []string{"-selection", "clipboard"}},
+		{"xsel", []string{"--clipboard", "--input"}},
+	}
+	if wayland {
+		return append([]command{{"wl-copy", nil}}, x11...)
+	}
+	return x11
+}
+
+// notifyCommands lists the tools that show a notification, in order of
+// preference
+func notifyCommands(p Platform, title, body string) []command {
+	switch p {
+	case MacOS:
+		script := fmt.Sprintf("display notification %q with title %q", body, title)
+		return []command{{"osascript", []string{"-e", script}}}
+	case WSL, Windows:
+		// notify-send works under WSLg; otherwise a Windows balloon tip
+		balloon := command{"powershell.exe", []string{"-NoProfile", "-NonInteractive", "-Command", balloonScript(title, body)}}
+		if p == WSL {
+			return []command{{"notify-send", []string{title, body}}, balloon}
+		}
+		return []command{balloon}
+	default:
+		return []command{{"notify-send", []string{title, body}}}
+	}
+}
+
+// balloonScript renders a PowerShell script showing a tray balloon tip
+func balloonScript(title, body string) string {
+	return fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(5000, %s, %s, 'Info')
+Start-Sleep -Seconds 5
+$n.Dispose()`, psQuote(title), psQuote(body))
+}
+
+// psQuote quotes s as a PowerShell single-quoted string
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,87 @@
+package xplat
+
+import (
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// installed fakes lookPath with a fixed set of tools
+func installed(t *testing.T, tools ...string) {
+	t.Helper()
+	original := lookPath
+	lookPath = func(name string) (string, error) {
+		for _, tool := range tools {
+			if tool == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", exec.ErrNotFound
+	}
+	t.Cleanup(func() { lookPath = original })
+}
+
+func TestPickOpen(t *testing.T) {
+	tests := []struct {
+		platform Platform
+		tools    []string
+		want     []string
+	}{
+		{MacOS, []string{"open"}, []string{"open", "http://x"}},
+		{Linux, []string{"xdg-open"}, []string{"xdg-open", "http://x"}},
+		{WSL, []string{"wslview", "rundll32.exe"}, []string{"wslview", "http://x"}},
+		{WSL, []string{"rundll32.exe"}, []string{"rundll32.exe", "url.dll,FileProtocolHandler", "http://x"}},
+	}
+
+	for _, tt := range tests {
+		installed(t, tt.tools...)
+		cmd, err := pick(openCommands(tt.platform, "http://x"))
+		if err != nil {
+			t.Fatalf("%s: pick() error = %v", tt.platform, err)
+		}
+		if got := append([]string{cmd.name}, cmd.args...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: open = %v, want %v", tt.platform, got, tt.want)
+		}
+	}
+}
+
+func TestPickClipboard(t *testing.T) {
+	installed(t, "wl-copy", "xsel")
+
+	cmd, err := pick(clipboardCommands(Linux, true))
+	if err != nil || cmd.name != "wl-copy" {
+		t.Errorf("wayland clipboard = %v, %v, want wl-copy", cmd.name, err)
+	}
+	cmd, err = pick(clipboardCommands(Linux, false))
+	if err != nil || cmd.name != "xsel" {
+		t.Errorf("x11 clipboard = %v, %v, want xsel", cmd.name, err)
+	}
+
+	_, err = pick(clipboardCommands(MacOS, false))
+	if !errors.Is(err, ErrUnsupported) {
+		t.Errorf("missing pbcopy error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestNotifyCommands(t *testing.T) {
+	installed(t, "powershell.exe")
+
+	cmd, err := pick(notifyCommands(WSL, "space: shop", "it's down"))
+	if err != nil {
+		t.Fatalf("pick() error = %v", err)
+	}
+	if cmd.name != "powershell.exe" {
+		t.Fatalf("WSL without notify-send = %s, want powershell.exe", cmd.name)
+	}
+	script := cmd.args[len(cmd.args)-1]
+	if want := "ShowBalloonTip(5000, 'space: shop', 'it''s down', 'Info')"; !strings.Contains(script, want) {
+		t.Errorf("script = %s, want %s", script, want)
+	}
+
+	mac := notifyCommands(MacOS, "space", `say "hi"`)[0]
+	if want := `display notification "say \"hi\"" with title "space"`; mac.args[1] != want {
+		t.Errorf("osascript = %s, want %s", mac.args[1], want)
+	}
+}